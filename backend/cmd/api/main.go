@@ -7,6 +7,14 @@ import (
 	"github.com/eyuppastirmaci/noesis-forge/internal/server"
 )
 
+// @title NoesisForge API
+// @version 1.0
+// @description Document management, sharing and search API of NoesisForge.
+// @BasePath /
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Access token in the form "Bearer <token>".
 func main() {
 	// Initialize app
 	application, err := app.New()
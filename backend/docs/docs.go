@@ -0,0 +1,11 @@
+// Package docs holds the OpenAPI spec built from the swag annotations on the handlers.
+//
+// Regenerate it after changing an annotated handler or one of its DTOs:
+//
+//	swag init -g cmd/api/main.go -o docs --outputTypes json
+package docs
+
+import _ "embed"
+
+//go:embed swagger.json
+var SwaggerJSON []byte
@@ -2,10 +2,13 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sync"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/config"
 	"github.com/eyuppastirmaci/noesis-forge/internal/database"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/queue"
 	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
 	"github.com/eyuppastirmaci/noesis-forge/internal/repositories/interfaces"
@@ -36,6 +39,15 @@ type App struct {
 	AuthService     *services.AuthService
 	DocumentService *services.DocumentService
 	MinIOService    *services.MinIOService
+	DocumentCounter *services.DocumentCounter
+
+	QueuePublisher *queue.Publisher
+
+	// Stops background workers
+	cancelWorkers context.CancelFunc
+
+	closeOnce sync.Once
+	closeErr  error
 }
 
 func New() (*App, error) {
@@ -91,8 +103,63 @@ func New() (*App, error) {
 		rawRedisClient = customRedisClient.Client
 	}
 
-	authService := services.NewAuthService(db, cfg, rawRedisClient, minioService)
-	userShareService := services.NewUserShareService(db, customRedisClient)
+	// Email is optional, shares still work without notifications
+	var emailService services.EmailService
+	if cfg.Email.SMTPHost != "" {
+		emailService = services.NewEmailService(services.NewSMTPSender(&cfg.Email))
+	} else {
+		logrus.Info("SMTP_HOST not set, share and verification emails disabled")
+	}
+
+	authService, err := services.NewAuthService(db, cfg, rawRedisClient, minioService, emailService)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT configuration: %w", err)
+	}
+
+	userShareService := services.NewUserShareService(db, customRedisClient, emailService, cfg.Email.AppURL)
+
+	// Detect external converters once, dependent features are disabled if missing
+	imageMagick := services.DetectImageMagick(cfg.Processing.ImageMagickPath)
+	libreOffice := services.DetectLibreOffice(cfg.Processing.LibreOfficePath)
+	services.WarnMissingThumbnailTools(imageMagick, libreOffice)
+	clamAV := services.NewClamAV(cfg.Scan)
+	fileTypes, err := models.NewFileTypePolicy(cfg.Uploads.AllowedFileTypes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ALLOWED_FILE_TYPES: %w", err)
+	}
+	tagPolicy, err := models.NewTagPolicy(cfg.Tags.MaxCount, cfg.Tags.AllowedSymbols, cfg.Tags.AllowUnicode)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag configuration: %w", err)
+	}
+	thumbnailPresets, err := services.NewThumbnailPresets(cfg.Preview.ThumbnailSmall, cfg.Preview.ThumbnailMedium, cfg.Preview.ThumbnailLarge, cfg.Preview.ThumbnailQuality)
+	if err != nil {
+		return nil, fmt.Errorf("invalid thumbnail configuration: %w", err)
+	}
+	tesseract := services.DetectTesseract(cfg.Processing.TesseractPath)
+
+	// Files of processing runs that died before cleaning up are swept once at startup
+	scratchDir, err := services.NewScratchDir(cfg.Processing.TempDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROCESSING_TEMP_DIR: %w", err)
+	}
+	if removed, err := scratchDir.Sweep(cfg.Processing.TempMaxAge); err != nil {
+		logrus.Warnf("Failed to sweep temp dir: %v", err)
+	} else if removed > 0 {
+		logrus.Infof("Removed %d stale entries from %s", removed, scratchDir.Path())
+	}
+
+	textExtractor := services.NewTextExtractor(cfg.Processing.PdfToTextPath, libreOffice, imageMagick, tesseract, scratchDir, cfg.Processing.MaxContentTextLength, cfg.Processing.OCRMaxPages)
+
+	queuePublisher, err := queue.NewPublisher(cfg.RabbitMQ.URL)
+	if err != nil {
+		log.Fatal("Failed to initialize queue publisher:", err)
+	}
+
+	// Buffers view and download counts, flushed periodically and on shutdown
+	documentCounter := services.NewDocumentCounter(documentRepo, customRedisClient, cfg.Counters.FlushInterval, cfg.Counters.ViewDedupWindow)
+
+	// Shared so admin changes to documents invalidate cached results too
+	searchCache := services.NewSearchCache(customRedisClient, cfg.Search.CacheTTL)
 
 	// Initialize Document service with dependencies
 	documentService := services.NewDocumentService(
@@ -100,14 +167,24 @@ func New() (*App, error) {
 		documentSearchRepo,
 		minioService,
 		userShareService,
+		imageMagick,
+		libreOffice,
+		clamAV,
+		textExtractor,
+		scratchDir,
+		fileTypes,
+		tagPolicy,
+		thumbnailPresets,
+		queuePublisher,
+		documentCounter,
+		searchCache,
+		cfg.Preview,
+		cfg.Revisions,
+		cfg.Integrity,
+		cfg.Scan,
 		db,
 	)
 
-	queuePublisher, err := queue.NewPublisher(cfg.RabbitMQ.URL)
-	if err != nil {
-		log.Fatal("Failed to initialize queue publisher:", err)
-	}
-
 	// Initialize Qdrant client for vector search
 	qdrantClient, err := vectordb.NewQdrantClient(cfg.Qdrant.Host, cfg.Qdrant.GrpcPort, cfg.Qdrant.UseTLS)
 	if err != nil {
@@ -127,6 +204,11 @@ func New() (*App, error) {
 		logrus.Info("Search service initialized with Qdrant support")
 	}
 
+	adminService := services.NewAdminService(db, customRedisClient, minioService, searchCache, cfg.BlindIndex.Key)
+
+	// Fans logged activities out to user webhooks
+	webhookService := services.NewWebhookService(db)
+
 	// Initialize WebSocket server
 	webSocketServer := websocket.NewServer()
 	webSocketServer.SetupHandlers()
@@ -134,8 +216,33 @@ func New() (*App, error) {
 	// Initialize ProcessingTaskService with WebSocket server
 	processingTaskService := services.NewProcessingTaskService(db, webSocketServer)
 
+	// Start background workers
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	documentService.StartTrashSweeper(workerCtx, services.TrashSweepInterval, services.TrashRetentionPeriod)
+	documentService.StartContentBackfill(workerCtx)
+	documentService.StartStuckDocumentHealer(workerCtx, customRedisClient, cfg.Processing.HealInterval, cfg.Processing.StuckThreshold)
+	documentService.StartExpirySweeper(workerCtx, customRedisClient, cfg.Expiry)
+	documentCounter.Start()
+	userShareService.StartExpirySweeper(workerCtx, services.ShareExpirySweepInterval, services.ShareExpiryGracePeriod)
+	adminService.ResumeDocumentTransfers(workerCtx)
+	adminService.ResumeSearchReindexJobs(workerCtx)
+	webhookService.Start(workerCtx)
+
+	// Duplicate deliveries are only detected with Redis
+	var processedStore queue.ProcessedStore
+	if customRedisClient != nil {
+		processedStore = customRedisClient
+	}
+	retryPolicy := queue.RetryPolicy{
+		MaxRetries: cfg.RabbitMQ.MaxRetries,
+		BaseDelay:  cfg.RabbitMQ.RetryBaseDelay,
+		MaxDelay:   cfg.RabbitMQ.RetryMaxDelay,
+	}
+	previewConsumer := queue.NewConsumer(cfg.RabbitMQ.URL, cfg.RabbitMQ.PrefetchCount, cfg.RabbitMQ.ReconnectDelay, retryPolicy, processedStore, cfg.RabbitMQ.DedupWindow)
+	previewConsumer.Consume(workerCtx, queue.DocumentPreviewQueue, documentService.HandlePreviewMessage, documentService.HandlePreviewDeadLetter)
+
 	// Initialize router with services
-	r := router.New(cfg, db, documentService, authService, userShareService, minioService, queuePublisher, processingTaskService, searchService)
+	r := router.New(cfg, db, documentService, authService, userShareService, minioService, queuePublisher, processingTaskService, searchService, adminService, webhookService, imageMagick, libreOffice, clamAV)
 	r.SetupRoutes(db)
 
 	// Add WebSocket endpoint to router
@@ -156,12 +263,52 @@ func New() (*App, error) {
 		AuthService:        authService,
 		DocumentService:    documentService,
 		MinIOService:       minioService,
+		DocumentCounter:    documentCounter,
+		QueuePublisher:     queuePublisher,
+		cancelWorkers:      cancelWorkers,
 	}, nil
 }
 
+// Close releases the app resources. The HTTP server has to be shut down first so no
+// request is still using them. Safe to call more than once.
 func (a *App) Close() error {
+	a.closeOnce.Do(func() {
+		a.closeErr = a.close()
+	})
+	return a.closeErr
+}
+
+func (a *App) close() error {
 	logrus.Info("Shutting down application...")
 
+	// Stop background workers
+	if a.cancelWorkers != nil {
+		a.cancelWorkers()
+	}
+
+	if a.WebSocketServer != nil {
+		if err := a.WebSocketServer.Close(); err != nil {
+			logrus.Errorf("Failed to close WebSocket server: %v", err)
+		}
+	}
+
+	// Write buffered counts before Redis and the database are closed
+	if a.DocumentCounter != nil {
+		if err := a.DocumentCounter.Close(); err != nil {
+			logrus.Errorf("Failed to flush document counters: %v", err)
+		}
+	}
+
+	if a.QueuePublisher != nil {
+		a.QueuePublisher.Close()
+	}
+
+	if a.Router != nil {
+		if err := a.Router.Close(); err != nil {
+			logrus.Errorf("Failed to close router Redis connection: %v", err)
+		}
+	}
+
 	// Close Redis connection if exists
 	if a.Redis != nil {
 		if err := a.Redis.Close(); err != nil {
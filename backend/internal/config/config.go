@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -11,19 +14,45 @@ import (
 type Config struct {
 	Environment string `envconfig:"ENVIRONMENT" default:"development"`
 
-	Server   ServerConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	MinIO    MinIOConfig
-	Redis    RedisConfig
-	RabbitMQ RabbitMQConfig
-	Qdrant   QdrantConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	Lockout    LockoutConfig
+	BlindIndex BlindIndexConfig
+	ShareLinks SharedLinkConfig
+	MinIO      MinIOConfig
+	Redis      RedisConfig
+	RabbitMQ   RabbitMQConfig
+	Qdrant     QdrantConfig
+	Quota      QuotaConfig
+	Processing ProcessingConfig
+	Uploads    UploadConfig
+	Scan       ScanConfig
+	Tags       TagConfig
+	Preview    PreviewConfig
+	Revisions  RevisionConfig
+	Expiry     ExpiryConfig
+	Integrity  IntegrityConfig
+	Counters   CounterConfig
+	Search     SearchConfig
+	Embed      EmbedConfig
+	Metrics    MetricsConfig
+	RateLimit  RateLimitConfig
+	Bulk       BulkConfig
+	Avatar     AvatarConfig
+	Moderation ModerationConfig
+	Comments   CommentLimitConfig
+	Email      EmailConfig
+	CORS       CORSConfig
+	Cookies    CookieConfig
 }
 
 type ServerConfig struct {
 	Port    string `envconfig:"PORT" default:"8000"`
 	Mode    string `envconfig:"GIN_MODE" default:"debug"`
 	BaseURL string `envconfig:"API_BASE_URL" default:"http://localhost:8000"`
+	// How long in-flight requests, bulk operations included, get to finish on shutdown
+	ShutdownTimeout time.Duration `envconfig:"SERVER_SHUTDOWN_TIMEOUT" default:"30s"`
 }
 
 type DatabaseConfig struct {
@@ -45,12 +74,70 @@ func (d DatabaseConfig) DSN() string {
 		d.User, d.Password, d.Host, d.Port, d.Database, d.SSLMode)
 }
 
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+)
+
 type JWTConfig struct {
-	Secret           string        `envconfig:"JWT_SECRET" required:"true"`
+	// HS256 signs and verifies with Secret. RS256 signs with the private key and publishes the
+	// public key as a JWKS, so other services can verify tokens without sharing a secret.
+	Algorithm        string        `envconfig:"JWT_ALGORITHM" default:"HS256"`
+	Secret           string        `envconfig:"JWT_SECRET"`
 	ExpiresIn        time.Duration `envconfig:"JWT_EXPIRES_IN" default:"24h"`
 	RefreshExpiresIn time.Duration `envconfig:"JWT_REFRESH_EXPIRES_IN" default:"168h"`
 	Issuer           string        `envconfig:"JWT_ISSUER" default:"noesis-forge"`
 	Audience         string        `envconfig:"JWT_AUDIENCE" default:"noesis-forge-api"`
+	// PEM encoded RSA keys for RS256. The public key is derived from the private one when
+	// omitted, and must match it otherwise.
+	PrivateKeyFile string `envconfig:"JWT_PRIVATE_KEY_FILE"`
+	PublicKeyFile  string `envconfig:"JWT_PUBLIC_KEY_FILE"`
+	// kid of issued tokens and of the published key, the key's thumbprint when empty
+	KeyID string `envconfig:"JWT_KEY_ID"`
+}
+
+func (c JWTConfig) Validate() error {
+	switch c.Algorithm {
+	case JWTAlgorithmHS256:
+		if c.Secret == "" {
+			return fmt.Errorf("JWT_SECRET is required with %s", JWTAlgorithmHS256)
+		}
+	case JWTAlgorithmRS256:
+		if c.PrivateKeyFile == "" {
+			return fmt.Errorf("JWT_PRIVATE_KEY_FILE is required with %s", JWTAlgorithmRS256)
+		}
+	default:
+		return fmt.Errorf("JWT_ALGORITHM must be %q or %q, got %q", JWTAlgorithmHS256, JWTAlgorithmRS256, c.Algorithm)
+	}
+	return nil
+}
+
+type LockoutConfig struct {
+	// Consecutive failed logins before the account is locked, zero disables the lockout
+	MaxFailedAttempts int           `envconfig:"LOCKOUT_MAX_FAILED_ATTEMPTS" default:"5"`
+	Duration          time.Duration `envconfig:"LOCKOUT_DURATION" default:"15m"`
+}
+
+type BlindIndexConfig struct {
+	// HMAC key for the lookup indexes of encrypted profile fields, lookups are disabled when empty.
+	// Changing it makes existing indexes unmatchable until users save their profile again.
+	Key string `envconfig:"BLIND_INDEX_KEY"`
+}
+
+// Shortest shared link secret accepted, in bytes
+const minSharedLinkSecretLength = 32
+
+type SharedLinkConfig struct {
+	// HMAC key for the access tokens issued when a password protected link is unlocked.
+	// Changing it ends every unlocked session.
+	Secret string `envconfig:"SHARED_LINK_SECRET" required:"true"`
+}
+
+func (c SharedLinkConfig) Validate() error {
+	if len(c.Secret) < minSharedLinkSecretLength {
+		return fmt.Errorf("SHARED_LINK_SECRET must be at least %d characters", minSharedLinkSecretLength)
+	}
+	return nil
 }
 
 type MinIOConfig struct {
@@ -73,6 +160,319 @@ type RedisConfig struct {
 	WriteTimeout time.Duration `envconfig:"REDIS_WRITE_TIMEOUT" default:"3s"`
 }
 
+type QuotaConfig struct {
+	PlanName        string        `envconfig:"QUOTA_PLAN_NAME" default:"free"`
+	MaxStorageBytes int64         `envconfig:"QUOTA_MAX_STORAGE_BYTES" default:"1073741824"`
+	MaxDocuments    int64         `envconfig:"QUOTA_MAX_DOCUMENTS" default:"1000"`
+	CacheTTL        time.Duration `envconfig:"QUOTA_CACHE_TTL" default:"60s"`
+}
+
+type ProcessingConfig struct {
+	// Explicit ImageMagick binary, auto-detected from PATH when empty
+	ImageMagickPath string `envconfig:"IMAGEMAGICK_PATH"`
+	// Explicit LibreOffice binary, auto-detected from PATH when empty
+	LibreOfficePath string `envconfig:"LIBREOFFICE_PATH"`
+	// Explicit pdftotext binary, auto-detected from PATH when empty
+	PdfToTextPath string `envconfig:"PDFTOTEXT_PATH"`
+	// Explicit tesseract binary, auto-detected from PATH when empty
+	TesseractPath string `envconfig:"TESSERACT_PATH"`
+	// Pages of a scanned PDF run through OCR, later pages stay unsearchable. 0 turns OCR off.
+	OCRMaxPages int `envconfig:"OCR_MAX_PAGES" default:"20"`
+	// Extracted text is cut to this many characters before it is indexed
+	MaxContentTextLength int `envconfig:"MAX_CONTENT_TEXT_LENGTH" default:"200000"`
+	// Documents processing for longer are re-enqueued once, then marked failed
+	StuckThreshold time.Duration `envconfig:"PROCESSING_STUCK_THRESHOLD" default:"15m"`
+	// How often stuck documents are looked for
+	HealInterval time.Duration `envconfig:"PROCESSING_HEAL_INTERVAL" default:"5m"`
+	// Base of the directory intermediate files are written to, os.TempDir() when empty
+	TempDir string `envconfig:"PROCESSING_TEMP_DIR"`
+	// Temp files older than this are treated as leftovers of a crashed run and removed at startup
+	TempMaxAge time.Duration `envconfig:"PROCESSING_TEMP_MAX_AGE" default:"1h"`
+}
+
+type UploadConfig struct {
+	// Extensions accepted for upload, see models.FileTypePolicy for the known ones
+	AllowedFileTypes []string `envconfig:"ALLOWED_FILE_TYPES" default:"pdf,doc,docx,odt,rtf,txt,md,xls,xlsx,ods,ppt,pptx,odp"`
+	// How long upload responses are kept for retries with the same Idempotency-Key, zero ignores the header
+	IdempotencyTTL time.Duration `envconfig:"UPLOAD_IDEMPOTENCY_TTL" default:"24h"`
+}
+
+// Scan modes, whether new files can be used before their virus scan is done
+const (
+	ScanModeBlock      = "block"
+	ScanModeQuarantine = "quarantine"
+)
+
+type ScanConfig struct {
+	// host:port of a clamd daemon new files are scanned with, empty turns scanning off
+	ClamAVAddress string `envconfig:"CLAMAV_ADDRESS"`
+	// Longest a single scan may take, the worker retries the document after that
+	ClamAVTimeout time.Duration `envconfig:"CLAMAV_TIMEOUT" default:"2m"`
+	// block withholds files until they are scanned clean, quarantine serves them right away
+	// and only removes them once found infected
+	Mode string `envconfig:"CLAMAV_SCAN_MODE" default:"block"`
+}
+
+func (c ScanConfig) Validate() error {
+	if c.Mode != ScanModeBlock && c.Mode != ScanModeQuarantine {
+		return fmt.Errorf("CLAMAV_SCAN_MODE must be %q or %q, got %q", ScanModeBlock, ScanModeQuarantine, c.Mode)
+	}
+	if c.ClamAVAddress != "" && c.ClamAVTimeout <= 0 {
+		return fmt.Errorf("CLAMAV_TIMEOUT must be positive, got %s", c.ClamAVTimeout)
+	}
+	return nil
+}
+
+type TagConfig struct {
+	// Most tags a document can have
+	MaxCount int `envconfig:"TAGS_MAX_COUNT" default:"10"`
+	// Characters allowed in tags besides letters, digits and spaces, commas can't be allowed
+	AllowedSymbols string `envconfig:"TAGS_ALLOWED_SYMBOLS" default:"-_"`
+	// Accept letters and digits of any script instead of ASCII only
+	AllowUnicode bool `envconfig:"TAGS_ALLOW_UNICODE" default:"false"`
+}
+
+type PreviewConfig struct {
+	// Strategies tried in order, documents none of them handle are offered as a download
+	Strategies []string      `envconfig:"PREVIEW_STRATEGIES" default:"pdf,office,image,text"`
+	URLExpiry  time.Duration `envconfig:"PREVIEW_URL_EXPIRY" default:"1h"`
+	// Thumbnail sizes as WIDTHxHEIGHT, medium is rendered while processing and the others on
+	// first request
+	ThumbnailSmall   string `envconfig:"THUMBNAIL_SIZE_SMALL" default:"150x200"`
+	ThumbnailMedium  string `envconfig:"THUMBNAIL_SIZE_MEDIUM" default:"300x400"`
+	ThumbnailLarge   string `envconfig:"THUMBNAIL_SIZE_LARGE" default:"600x800"`
+	ThumbnailQuality int    `envconfig:"THUMBNAIL_QUALITY" default:"85"`
+	// Lifetime of thumbnail URLs that let the browser fetch thumbnails straight from storage
+	ThumbnailURLExpiry time.Duration `envconfig:"THUMBNAIL_URL_EXPIRY" default:"15m"`
+	// Bytes of a text or Markdown document rendered to HTML, the rest is cut off
+	RenderMaxBytes int64 `envconfig:"PREVIEW_RENDER_MAX_BYTES" default:"1048576"`
+}
+
+type RevisionConfig struct {
+	// Revisions kept per document, older ones are pruned along with their files. Zero keeps all.
+	MaxPerDocument int `envconfig:"MAX_REVISIONS_PER_DOCUMENT" default:"20"`
+}
+
+// Expiry actions, what happens to a document once its expiration time has passed
+const (
+	ExpiryActionTrash  = "trash"
+	ExpiryActionDelete = "delete"
+)
+
+type ExpiryConfig struct {
+	// trash moves expired documents to the trash, delete removes them with their files right away
+	Action string `envconfig:"DOCUMENT_EXPIRY_ACTION" default:"trash"`
+	// How often expired documents are looked for
+	SweepInterval time.Duration `envconfig:"DOCUMENT_EXPIRY_SWEEP_INTERVAL" default:"15m"`
+	// Owners are notified this long before their documents expire, zero sends no notice
+	Notice time.Duration `envconfig:"DOCUMENT_EXPIRY_NOTICE" default:"24h"`
+}
+
+func (c ExpiryConfig) Validate() error {
+	if c.Action != ExpiryActionTrash && c.Action != ExpiryActionDelete {
+		return fmt.Errorf("DOCUMENT_EXPIRY_ACTION must be %q or %q, got %q", ExpiryActionTrash, ExpiryActionDelete, c.Action)
+	}
+	if c.SweepInterval < time.Minute {
+		return fmt.Errorf("DOCUMENT_EXPIRY_SWEEP_INTERVAL must be at least 1m, got %s", c.SweepInterval)
+	}
+	if c.Notice < 0 {
+		return fmt.Errorf("DOCUMENT_EXPIRY_NOTICE must not be negative, got %s", c.Notice)
+	}
+	return nil
+}
+
+type IntegrityConfig struct {
+	// Downloads up to this size are checked against the hash recorded at upload, larger ones
+	// only when asked with verify=true. Zero checks only when asked.
+	VerifyDownloadMaxBytes int64 `envconfig:"DOWNLOAD_VERIFY_MAX_BYTES" default:"52428800"`
+}
+
+type AvatarConfig struct {
+	// Style of avatars generated for users without an upload: initials or identicon
+	Style    string        `envconfig:"AVATAR_STYLE" default:"initials"`
+	CacheTTL time.Duration `envconfig:"AVATAR_CACHE_TTL" default:"24h"`
+}
+
+type CounterConfig struct {
+	// How often buffered view and download counts are written to the database, zero writes through
+	FlushInterval time.Duration `envconfig:"COUNTER_FLUSH_INTERVAL" default:"30s"`
+	// Repeated views of a document by the same user within this window count once, zero counts every view
+	ViewDedupWindow time.Duration `envconfig:"COUNTER_VIEW_DEDUP_WINDOW" default:"30m"`
+}
+
+type SearchConfig struct {
+	// How long search and listing results are cached per user, zero disables the cache.
+	// Changes to the user's documents drop their cached results right away.
+	CacheTTL time.Duration `envconfig:"SEARCH_CACHE_TTL" default:"1m"`
+}
+
+type EmbedConfig struct {
+	// Lifetime of embed tokens minted without an explicit expiry
+	DefaultTTL time.Duration `envconfig:"EMBED_TOKEN_DEFAULT_TTL" default:"720h"`
+	// Longest lifetime an owner can ask for
+	MaxTTL time.Duration `envconfig:"EMBED_TOKEN_MAX_TTL" default:"8760h"`
+}
+
+func (c EmbedConfig) Validate() error {
+	if c.DefaultTTL <= 0 {
+		return fmt.Errorf("EMBED_TOKEN_DEFAULT_TTL must be positive, got %s", c.DefaultTTL)
+	}
+	if c.MaxTTL < c.DefaultTTL {
+		return fmt.Errorf("EMBED_TOKEN_MAX_TTL must be at least EMBED_TOKEN_DEFAULT_TTL, got %s", c.MaxTTL)
+	}
+	return nil
+}
+
+type RateLimitConfig struct {
+	// Per user limits for document transfers, zero disables the limit
+	UploadLimit    int           `envconfig:"RATE_LIMIT_UPLOAD" default:"30"`
+	UploadWindow   time.Duration `envconfig:"RATE_LIMIT_UPLOAD_WINDOW" default:"1m"`
+	DownloadLimit  int           `envconfig:"RATE_LIMIT_DOWNLOAD" default:"120"`
+	DownloadWindow time.Duration `envconfig:"RATE_LIMIT_DOWNLOAD_WINDOW" default:"1m"`
+}
+
+// Limits of the bulk document endpoints. Concurrency is how many documents of one request are
+// worked on at a time, Max how many one request may carry and Timeout how long it may take.
+type BulkConfig struct {
+	UploadConcurrency int           `envconfig:"BULK_UPLOAD_CONCURRENCY" default:"10"`
+	UploadMaxFiles    int           `envconfig:"BULK_UPLOAD_MAX_FILES" default:"20"`
+	UploadMaxBytes    int64         `envconfig:"BULK_UPLOAD_MAX_BYTES" default:"524288000"` // Whole form
+	UploadTimeout     time.Duration `envconfig:"BULK_UPLOAD_TIMEOUT" default:"10m"`
+
+	DeleteConcurrency int           `envconfig:"BULK_DELETE_CONCURRENCY" default:"10"`
+	DeleteMax         int           `envconfig:"BULK_DELETE_MAX" default:"100"`
+	DeleteTimeout     time.Duration `envconfig:"BULK_DELETE_TIMEOUT" default:"2m"`
+
+	UpdateConcurrency int           `envconfig:"BULK_UPDATE_CONCURRENCY" default:"10"`
+	UpdateMax         int           `envconfig:"BULK_UPDATE_MAX" default:"100"`
+	UpdateTimeout     time.Duration `envconfig:"BULK_UPDATE_TIMEOUT" default:"2m"`
+
+	DownloadConcurrency int           `envconfig:"BULK_DOWNLOAD_CONCURRENCY" default:"5"`
+	DownloadMax         int           `envconfig:"BULK_DOWNLOAD_MAX" default:"50"`
+	DownloadTimeout     time.Duration `envconfig:"BULK_DOWNLOAD_TIMEOUT" default:"5m"`
+
+	PresignMax int `envconfig:"BULK_PRESIGN_MAX" default:"100"`
+}
+
+// Upper bound of every bulk concurrency setting, each unit is a goroutine holding a connection
+const maxBulkConcurrency = 100
+
+func (c BulkConfig) Validate() error {
+	for name, value := range map[string]int{
+		"BULK_UPLOAD_CONCURRENCY":   c.UploadConcurrency,
+		"BULK_DELETE_CONCURRENCY":   c.DeleteConcurrency,
+		"BULK_UPDATE_CONCURRENCY":   c.UpdateConcurrency,
+		"BULK_DOWNLOAD_CONCURRENCY": c.DownloadConcurrency,
+	} {
+		if value < 1 || value > maxBulkConcurrency {
+			return fmt.Errorf("%s must be between 1 and %d, got %d", name, maxBulkConcurrency, value)
+		}
+	}
+	for name, value := range map[string]int{
+		"BULK_UPLOAD_MAX_FILES": c.UploadMaxFiles,
+		"BULK_DELETE_MAX":       c.DeleteMax,
+		"BULK_UPDATE_MAX":       c.UpdateMax,
+		"BULK_DOWNLOAD_MAX":     c.DownloadMax,
+		"BULK_PRESIGN_MAX":      c.PresignMax,
+	} {
+		if value < 1 {
+			return fmt.Errorf("%s must be at least 1, got %d", name, value)
+		}
+	}
+	if c.UploadMaxBytes < 1<<20 {
+		return fmt.Errorf("BULK_UPLOAD_MAX_BYTES must be at least 1MB, got %d", c.UploadMaxBytes)
+	}
+	for name, value := range map[string]time.Duration{
+		"BULK_UPLOAD_TIMEOUT":   c.UploadTimeout,
+		"BULK_DELETE_TIMEOUT":   c.DeleteTimeout,
+		"BULK_UPDATE_TIMEOUT":   c.UpdateTimeout,
+		"BULK_DOWNLOAD_TIMEOUT": c.DownloadTimeout,
+	} {
+		if value < time.Second {
+			return fmt.Errorf("%s must be at least 1s, got %s", name, value)
+		}
+	}
+	return nil
+}
+
+type ModerationConfig struct {
+	// Turns comment moderation off, for deployments that moderate elsewhere
+	Enabled bool `envconfig:"COMMENT_MODERATION_ENABLED" default:"true"`
+	// Rejects links and email addresses whose domain is not in AllowedDomains
+	BlockLinks bool `envconfig:"COMMENT_BLOCK_LINKS" default:"false"`
+	// Domains links may point to when BlockLinks is on, subdomains included
+	AllowedDomains []string `envconfig:"COMMENT_ALLOWED_DOMAINS"`
+	// Whole words rejected as inappropriate language, case insensitive
+	BlockedWords []string `envconfig:"COMMENT_BLOCKED_WORDS" default:"spam,scam"`
+}
+
+// Hard ceilings on the comments a document accumulates, on top of the rate limits. 0 turns a
+// limit off.
+type CommentLimitConfig struct {
+	// Comments and replies of any type on a single document
+	MaxPerDocument int `envconfig:"COMMENT_MAX_PER_DOCUMENT" default:"1000"`
+	// Annotations pinned to the same page of a document
+	MaxAnnotationsPerPage int `envconfig:"COMMENT_MAX_ANNOTATIONS_PER_PAGE" default:"100"`
+}
+
+// Origins, methods and headers browsers may use against the API
+type CORSConfig struct {
+	AllowedOrigins   []string      `envconfig:"CORS_ALLOWED_ORIGINS" default:"http://localhost:3000,http://localhost:3001,http://127.0.0.1:3000,http://127.0.0.1:3001"`
+	AllowedMethods   []string      `envconfig:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS"`
+	AllowedHeaders   []string      `envconfig:"CORS_ALLOWED_HEADERS" default:"Origin,Content-Length,Content-Type,Accept,Authorization,X-Requested-With,X-CSRF-Token,X-Request-ID,Range,Idempotency-Key"`
+	ExposedHeaders   []string      `envconfig:"CORS_EXPOSED_HEADERS" default:"Content-Length,Content-Type,Content-Disposition,Content-Range,Accept-Ranges,X-Request-ID,Idempotent-Replayed"`
+	AllowCredentials bool          `envconfig:"CORS_ALLOW_CREDENTIALS" default:"true"`
+	MaxAge           time.Duration `envconfig:"CORS_MAX_AGE" default:"12h"`
+}
+
+func (c CORSConfig) Validate() error {
+	if len(c.AllowedOrigins) == 0 {
+		return fmt.Errorf("CORS_ALLOWED_ORIGINS must list at least one origin")
+	}
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" && c.AllowCredentials {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS cannot be * while CORS_ALLOW_CREDENTIALS is on")
+		}
+	}
+	return nil
+}
+
+// Attributes of the auth and share link cookies
+type CookieConfig struct {
+	// Empty scopes cookies to the API host, set e.g. .example.com to share them with subdomains
+	Domain string `envconfig:"COOKIE_DOMAIN"`
+	// Only send cookies over HTTPS, required when SameSite is none and in production. Turn off
+	// for local development over plain HTTP.
+	Secure bool `envconfig:"COOKIE_SECURE" default:"true"`
+	// lax, strict or none. none is needed when the frontend is served from another site.
+	SameSite string `envconfig:"COOKIE_SAMESITE" default:"lax"`
+}
+
+func (c CookieConfig) Validate() error {
+	switch strings.ToLower(c.SameSite) {
+	case "lax", "strict":
+	case "none":
+		if !c.Secure {
+			return fmt.Errorf("COOKIE_SAMESITE=none requires COOKIE_SECURE=true")
+		}
+	default:
+		return fmt.Errorf("invalid COOKIE_SAMESITE %q, expected lax, strict or none", c.SameSite)
+	}
+	return nil
+}
+
+// SameSiteMode returns the configured SameSite attribute, Lax for unknown values
+func (c CookieConfig) SameSiteMode() http.SameSite {
+	switch strings.ToLower(c.SameSite) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
 // Future configuration structs
 
 type RabbitMQConfig struct {
@@ -84,6 +484,12 @@ type RabbitMQConfig struct {
 	AutoDelete     bool          `envconfig:"RABBITMQ_AUTO_DELETE" default:"false"`
 	ReconnectDelay time.Duration `envconfig:"RABBITMQ_RECONNECT_DELAY" default:"5s"`
 	PrefetchCount  int           `envconfig:"RABBITMQ_PREFETCH_COUNT" default:"10"`
+	// Failed messages are retried with exponential backoff, then moved to the dead-letter queue
+	MaxRetries     int           `envconfig:"RABBITMQ_MAX_RETRIES" default:"5"`
+	RetryBaseDelay time.Duration `envconfig:"RABBITMQ_RETRY_BASE_DELAY" default:"5s"`
+	RetryMaxDelay  time.Duration `envconfig:"RABBITMQ_RETRY_MAX_DELAY" default:"5m"`
+	// How long handled message IDs are remembered to skip duplicate deliveries
+	DedupWindow time.Duration `envconfig:"RABBITMQ_DEDUP_WINDOW" default:"24h"`
 }
 
 type QdrantConfig struct {
@@ -111,6 +517,14 @@ type EmailConfig struct {
 	From     string `envconfig:"EMAIL_FROM" default:"noreply@yourdomain.com"`
 	FromName string `envconfig:"EMAIL_FROM_NAME" default:"NoesisForge"`
 
+	// Frontend base URL used for links in emails
+	AppURL string `envconfig:"APP_URL" default:"http://localhost:3000"`
+
+	// How long email verification links stay valid
+	VerificationTokenTTL time.Duration `envconfig:"EMAIL_VERIFICATION_TTL" default:"24h"`
+	// How long password reset links stay valid
+	PasswordResetTokenTTL time.Duration `envconfig:"PASSWORD_RESET_TTL" default:"1h"`
+
 	// SMTP settings
 	SMTPHost       string `envconfig:"SMTP_HOST"`
 	SMTPPort       int    `envconfig:"SMTP_PORT" default:"587"`
@@ -123,20 +537,13 @@ type EmailConfig struct {
 	AWSRegion      string `envconfig:"AWS_REGION" default:"us-east-1"`
 }
 
-type CORSConfig struct {
-	AllowedOrigins   []string      `envconfig:"CORS_ALLOWED_ORIGINS" default:"http://localhost:3000,http://127.0.0.1:3000"`
-	AllowedMethods   []string      `envconfig:"CORS_ALLOWED_METHODS" default:"GET,POST,PUT,DELETE,OPTIONS"`
-	AllowedHeaders   []string      `envconfig:"CORS_ALLOWED_HEADERS" default:"Origin,Content-Type,Accept,Authorization"`
-	ExposedHeaders   []string      `envconfig:"CORS_EXPOSED_HEADERS" default:"Content-Length"`
-	AllowCredentials bool          `envconfig:"CORS_ALLOW_CREDENTIALS" default:"true"`
-	MaxAge           time.Duration `envconfig:"CORS_MAX_AGE" default:"12h"`
-}
-
+// Prometheus metrics are served on their own port so the endpoint is not reachable through
+// the public API
 type MetricsConfig struct {
 	Enabled   bool   `envconfig:"METRICS_ENABLED" default:"true"`
 	Port      string `envconfig:"METRICS_PORT" default:"9090"`
 	Path      string `envconfig:"METRICS_PATH" default:"/metrics"`
-	Namespace string `envconfig:"METRICS_NAMESPACE" default:"noesis_forge"`
+	Namespace string `envconfig:"METRICS_NAMESPACE" default:"noesis_forge"` // Prefix of every metric name
 }
 
 type LoggingConfig struct {
@@ -169,5 +576,59 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.JWT.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid JWT configuration: %w", err)
+	}
+	if err := cfg.ShareLinks.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid shared link configuration: %w", err)
+	}
+	if err := cfg.CORS.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid CORS configuration: %w", err)
+	}
+	if err := cfg.Cookies.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cookie configuration: %w", err)
+	}
+	if err := cfg.Bulk.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid bulk configuration: %w", err)
+	}
+	if err := cfg.Expiry.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid expiry configuration: %w", err)
+	}
+	if err := cfg.Embed.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid embed token configuration: %w", err)
+	}
+	if err := cfg.Scan.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid virus scan configuration: %w", err)
+	}
+	if cfg.Environment == "production" {
+		if err := cfg.validateProduction(); err != nil {
+			return nil, err
+		}
+	}
+
 	return &cfg, nil
 }
+
+// Rejects development settings that are unsafe once deployed
+func (c *Config) validateProduction() error {
+	if !c.Cookies.Secure {
+		return fmt.Errorf("invalid cookie configuration: COOKIE_SECURE must be true in production")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if isLocalOrigin(origin) {
+			return fmt.Errorf("invalid CORS configuration: CORS_ALLOWED_ORIGINS must not list %s in production", origin)
+		}
+	}
+	return nil
+}
+
+// Reports whether an origin points at the machine it is opened on
+func isLocalOrigin(origin string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	return host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+		host == "127.0.0.1" || host == "::1" || host == "0.0.0.0"
+}
@@ -68,18 +68,33 @@ func RunMigrations(db *gorm.DB, dbName string) error {
 		&models.RefreshToken{},
 		&models.EmailVerificationToken{},
 		&models.PasswordResetToken{},
+		&models.Folder{},
 		&models.Document{},
 		&models.Favorite{},
+		&models.DocumentPin{},
+		&models.EmbedToken{},
+		&models.SavedSearch{},
 		&models.DocumentRevision{},
+		&models.Webhook{},
+		&models.WebhookDeadLetter{},
 		&models.SharedLink{},
 		&models.ShareAuditLog{},
 		&models.UserShare{},
 		&models.ShareNotification{},
 		&models.UserShareAuditLog{},
 		&models.ShareInvitation{},
+		&models.Group{},
+		&models.GroupMember{},
+		&models.GroupShare{},
 		&models.DocumentComment{},
+		&models.CommentReaction{},
 		&models.DocumentActivity{},
 		&models.ProcessingTask{},
+		&models.CustomFieldDefinition{},
+		&models.DocumentTransfer{},
+		&models.SearchReindexJob{},
+		&models.Tag{},
+		&models.DocumentTag{},
 	)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to run migrations")
@@ -92,6 +107,24 @@ func RunMigrations(db *gorm.DB, dbName string) error {
 		return err
 	}
 
+	// Index custom metadata for containment filters
+	if err := migrations.AddCustomMetadataIndex(db); err != nil {
+		logrus.WithError(err).Error("Failed to add custom metadata index")
+		return err
+	}
+
+	// Keep folder names unique within their parent
+	if err := migrations.AddFolderNameIndex(db); err != nil {
+		logrus.WithError(err).Error("Failed to add folder name index")
+		return err
+	}
+
+	// Move comma-separated tags into the tag tables
+	if err := migrations.MigrateDocumentTags(db); err != nil {
+		logrus.WithError(err).Error("Failed to migrate document tags")
+		return err
+	}
+
 	// Add E2EE encrypted fields to users table
 	if err := migrations.MigrateE2EEFields(db); err != nil {
 		logrus.WithError(err).Error("Failed to add E2EE fields")
@@ -3,8 +3,6 @@ package fts
 import (
 	"context"
 	"fmt"
-	"regexp"
-	"strings"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/types"
@@ -15,15 +13,13 @@ import (
 type ExactFTSStrategy struct {
 	db *gorm.DB
 	// Configuration options
-	minTokenLength int
-	maxTokens      int
+	maxTokens int
 }
 
 func NewExactFTSStrategy(db *gorm.DB) types.SearchStrategy {
 	return &ExactFTSStrategy{
-		db:             db,
-		minTokenLength: 2,  // Minimum token length
-		maxTokens:      10, // Maximum tokens to prevent query explosion
+		db:        db,
+		maxTokens: 10, // Maximum tokens to prevent query explosion
 	}
 }
 
@@ -33,18 +29,15 @@ func (s *ExactFTSStrategy) Name() string {
 
 // Decides if this strategy should handle the request.
 // This strategy is now more specific and only handles queries that are explicitly "exact",
-// such as phrase searches (in quotes) or queries with boolean operators.
+// such as phrase searches (in quotes), field scoped terms or queries with boolean operators.
+// Queries it cannot parse are left to the fuzzy strategy, see query_parser.go for the syntax.
 func (s *ExactFTSStrategy) CanHandle(req *types.SearchRequest) bool {
-	query := strings.TrimSpace(req.Query)
-
-	// 1. Check for phrase search
-	isPhraseSearch := strings.HasPrefix(query, `"`) && strings.HasSuffix(query, `"`)
-
-	// 2. Check for advanced FTS operators
-	hasOperators := s.hasSpecialOperators(query)
+	if !hasQueryOperators(req.RawQuery) {
+		return false
+	}
 
-	// This strategy should only run if the user has provided an rxact query.
-	return isPhraseSearch || hasOperators
+	_, err := compileQuery(req.RawQuery, s.maxTokens)
+	return err == nil
 }
 
 // Search executes the PostgreSQL full-text search with enhanced features
@@ -59,23 +52,18 @@ func (s *ExactFTSStrategy) Search(
 		Where("user_id = ?", req.UserID)
 	baseQuery = filters(baseQuery)
 
-	// Enhanced query building
-	ftsQuery, usePhrase := s.buildFTSQuery(req)
-	if ftsQuery == "" {
-		return &types.SearchResult{}, nil
+	// Compile the operators into tsquery syntax, phrases use the same followed-by
+	// operator phraseto_tsquery produces
+	ftsQuery, err := compileQuery(req.RawQuery, s.maxTokens)
+	if err != nil {
+		return &types.SearchResult{}, err
 	}
 
-	// Build the search query with different strategies based on input
-	var searchQuery *gorm.DB
-	if usePhrase {
-		// For phrase searches, use phraseto_tsquery for exact phrase matching
-		searchQuery = baseQuery.Session(&gorm.Session{}).
-			Where("search_vector @@ phraseto_tsquery('english', ?)", ftsQuery)
-	} else {
-		// For regular searches with operators, use websearch_to_tsquery
-		searchQuery = baseQuery.Session(&gorm.Session{}).
-			Where("search_vector @@ websearch_to_tsquery('english', ?)", ftsQuery)
-	}
+	// Match against the configuration the search vectors were built with
+	configExpr, configArgs := searchConfigExpr(req)
+
+	searchQuery := baseQuery.Session(&gorm.Session{}).
+		Where("search_vector @@ to_tsquery("+configExpr+", ?)", withConfigArgs(configArgs, ftsQuery)...)
 
 	// Count total matches
 	var total int64
@@ -95,7 +83,7 @@ func (s *ExactFTSStrategy) Search(
 	}
 
 	// Build the final query with ranking and highlights
-	finalQuery := s.buildFinalQuery(searchQuery, req, ftsQuery, usePhrase)
+	finalQuery := s.buildFinalQuery(searchQuery, req, ftsQuery)
 
 	// Create a struct to scan all results including highlights
 	type documentWithHighlights struct {
@@ -130,69 +118,21 @@ func (s *ExactFTSStrategy) Search(
 	}, nil
 }
 
-// Creates an optimized FTS query from the search request
-func (s *ExactFTSStrategy) buildFTSQuery(req *types.SearchRequest) (string, bool) {
-	query := strings.TrimSpace(req.Query)
-
-	// Check if this is a phrase search (enclosed in quotes)
-	if strings.HasPrefix(query, `"`) && strings.HasSuffix(query, `"`) {
-		// Remove quotes and return as phrase
-		phrase := strings.Trim(query, `"`)
-		return phrase, true
-	}
-
-	// Check for special operators in the query
-	if s.hasSpecialOperators(query) {
-		// If user is using advanced syntax, pass it through
-		return query, false
-	}
-
-	// Build query from tokens with smart filtering
-	var queryParts []string
-	tokenCount := 0
-
-	for _, token := range req.Tokens {
-		// Skip if we've hit max tokens
-		if tokenCount >= s.maxTokens {
-			break
-		}
-
-		// More sophisticated token filtering
-		if s.isValidToken(token) {
-			// Escape special characters for safety
-			escapedToken := s.escapeToken(token)
-			queryParts = append(queryParts, escapedToken)
-			tokenCount++
-		}
-	}
-
-	if len(queryParts) == 0 {
-		return "", false
-	}
-
-	// Join with AND operator for exact matching
-	return strings.Join(queryParts, " & "), false
-}
-
 // Constructs the final query with all features including highlights
 func (s *ExactFTSStrategy) buildFinalQuery(
 	baseQuery *gorm.DB,
 	req *types.SearchRequest,
 	ftsQuery string,
-	usePhrase bool,
 ) *gorm.DB {
-	queryFunc := "websearch_to_tsquery"
-	if usePhrase {
-		queryFunc = "phraseto_tsquery"
-	}
+	configExpr, configArgs := searchConfigExpr(req)
 
 	// Build comprehensive select
 	selectStatement := fmt.Sprintf(`
 		documents.*,
-		ts_rank_cd(search_vector, %s('english', ?), 32) as search_score,
-	`, queryFunc)
+		ts_rank_cd(search_vector, to_tsquery(%s, ?), 32) as search_score
+	`, configExpr)
 
-	query := baseQuery.Select(selectStatement, ftsQuery, ftsQuery, ftsQuery)
+	query := baseQuery.Select(selectStatement, withConfigArgs(configArgs, ftsQuery)...)
 
 	// Apply sorting based on request
 	switch req.SortBy {
@@ -212,49 +152,6 @@ func (s *ExactFTSStrategy) buildFinalQuery(
 	return query
 }
 
-// Checks if a token should be included in the search
-func (s *ExactFTSStrategy) isValidToken(token string) bool {
-	// Check minimum length
-	if len(token) < s.minTokenLength {
-		return false
-	}
-
-	// Skip common stop words that PostgreSQL FTS doesn't handle well
-	stopWords := map[string]bool{
-		"the": true, "and": true, "or": true, "but": true,
-		"in": true, "on": true, "at": true, "to": true,
-		"a": true, "an": true, "is": true, "it": true,
-	}
-
-	if stopWords[strings.ToLower(token)] {
-		return false
-	}
-
-	// Check if token is not just numbers or special characters
-	if matched, _ := regexp.MatchString(`^[\d\W]+$`, token); matched {
-		return false
-	}
-
-	return true
-}
-
-// Escapes special characters in tokens
-func (s *ExactFTSStrategy) escapeToken(token string) string {
-	// Escape special characters that have meaning in tsquery
-	specialChars := []string{"&", "|", "!", "(", ")", ":", "*"}
-	escaped := token
-	for _, char := range specialChars {
-		escaped = strings.ReplaceAll(escaped, char, "\\"+char)
-	}
-	return escaped
-}
-
-// Checks if query contains FTS operators
-func (s *ExactFTSStrategy) hasSpecialOperators(query string) bool {
-	// Regex is more reliable for catching operators
-	return regexp.MustCompile(`(&|\||!|:\*|\s(AND|OR|NOT)\s)`).MatchString(strings.ToUpper(query))
-}
-
 // Provides additional configuration for searches
 type SearchOptions struct {
 	Filters        func(*gorm.DB) *gorm.DB
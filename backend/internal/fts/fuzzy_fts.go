@@ -52,13 +52,16 @@ func (s *FuzzyFTSStrategy) Search(
 		return &types.SearchResult{}, nil
 	}
 
+	// Rank with the configuration the search vectors were built with
+	configExpr, configArgs := searchConfigExpr(req)
+
 	// Apply ranking, highlighting, and ordering to the fuzzy-matched documents.
 	dataQuery := baseQuery.
 		Select(`
 			documents.*,
-			ts_rank_cd(search_vector, plainto_tsquery('english', ?)) * 0.6 +
-			GREATEST(similarity(title, ?), similarity(description, ?)) * 0.4 AS search_score,
-		`, req.Query, req.Query, req.Query, req.Query, req.Query).
+			ts_rank_cd(search_vector, plainto_tsquery(`+configExpr+`, ?)) * 0.6 +
+			GREATEST(similarity(title, ?), similarity(description, ?)) * 0.4 AS search_score
+		`, withConfigArgs(configArgs, req.Query, req.Query, req.Query)...).
 		Order("search_score DESC, created_at DESC")
 
 	var docs []models.Document
@@ -0,0 +1,20 @@
+package fts
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+)
+
+// Returns the SQL expression selecting the text search configuration for a query.
+// An explicit request language is used as-is, otherwise each document is matched
+// with the configuration its search vector was built with.
+func searchConfigExpr(req *types.SearchRequest) (string, []interface{}) {
+	if req.Language != "" {
+		return "document_search_config(?)", []interface{}{req.Language}
+	}
+	return "document_search_config(documents.language)", nil
+}
+
+// Prepends configuration args to the remaining query args
+func withConfigArgs(configArgs []interface{}, args ...interface{}) []interface{} {
+	return append(append([]interface{}{}, configArgs...), args...)
+}
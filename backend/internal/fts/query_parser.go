@@ -0,0 +1,254 @@
+package fts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Search operators recognized by the exact strategy:
+//
+//	invoice report      both terms (AND is implied, the AND keyword is accepted too)
+//	"quarterly report"  the words as an adjacent phrase
+//	invoice OR receipt  either term
+//	NOT draft, -draft   documents without the term
+//	title:invoice       the term in the title
+//	tag:finance         the term in the tags, tags: is accepted as well
+//	title:"q3 report"   a phrase in the title
+//
+// Operators are case sensitive so lowercase "or" and "not" stay search words. Anything else,
+// such as parentheses, unknown fields or unbalanced quotes, is rejected and the query falls
+// through to the fuzzy strategy.
+
+var errUnsupportedQuery = errors.New("unsupported search syntax")
+
+// Search vector weight each field prefix is restricted to, see documents_search_vector_update()
+var queryFieldWeights = map[string]string{
+	"title": "A",
+	"tag":   "C",
+	"tags":  "C",
+}
+
+type queryTokenKind int
+
+const (
+	tokenWord queryTokenKind = iota
+	tokenPhrase
+	tokenOr
+	tokenAnd
+	tokenNot
+)
+
+type queryToken struct {
+	kind  queryTokenKind
+	field string   // weight label, empty for all fields
+	words []string // the word or phrase words
+}
+
+// Reports whether the raw query uses any operator syntax the exact strategy handles
+func hasQueryOperators(raw string) bool {
+	tokens, err := tokenizeQuery(raw)
+	if err != nil {
+		return false
+	}
+	for _, token := range tokens {
+		if token.kind != tokenWord || token.field != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Compiles a raw search query into to_tsquery input. Every lexeme is quoted, so the result
+// is always valid tsquery syntax. maxTerms caps the number of words to keep queries cheap.
+func compileQuery(raw string, maxTerms int) (string, error) {
+	tokens, err := tokenizeQuery(raw)
+	if err != nil {
+		return "", err
+	}
+
+	var orGroups []string
+	var andTerms []string
+	negate := false
+	terms := 0
+	expectTerm := true // nothing to combine with yet
+
+	for _, token := range tokens {
+		switch token.kind {
+		case tokenOr:
+			if expectTerm || negate {
+				return "", errUnsupportedQuery
+			}
+			orGroups = append(orGroups, strings.Join(andTerms, " & "))
+			andTerms = nil
+			expectTerm = true
+		case tokenAnd:
+			if expectTerm || negate {
+				return "", errUnsupportedQuery
+			}
+			expectTerm = true
+		case tokenNot:
+			if negate {
+				return "", errUnsupportedQuery
+			}
+			negate = true
+		default:
+			terms += len(token.words)
+			if terms > maxTerms {
+				return "", fmt.Errorf("%w: more than %d terms", errUnsupportedQuery, maxTerms)
+			}
+
+			term := compileTerm(token)
+			if negate {
+				term = "!" + term
+				negate = false
+			}
+			andTerms = append(andTerms, term)
+			expectTerm = false
+		}
+	}
+
+	// Dangling operators at the end
+	if expectTerm || negate {
+		return "", errUnsupportedQuery
+	}
+	orGroups = append(orGroups, strings.Join(andTerms, " & "))
+
+	if len(orGroups) == 1 {
+		return orGroups[0], nil
+	}
+	for i, group := range orGroups {
+		orGroups[i] = "(" + group + ")"
+	}
+	return strings.Join(orGroups, " | "), nil
+}
+
+// Quotes each word as a tsquery lexeme and chains phrase words with the followed-by operator
+func compileTerm(token queryToken) string {
+	lexemes := make([]string, len(token.words))
+	for i, word := range token.words {
+		word = strings.ReplaceAll(word, `\`, `\\`)
+		word = strings.ReplaceAll(word, `'`, `''`)
+		lexemes[i] = "'" + word + "'"
+		if token.field != "" {
+			lexemes[i] += ":" + token.field
+		}
+	}
+
+	if len(lexemes) == 1 {
+		return lexemes[0]
+	}
+	return "(" + strings.Join(lexemes, " <-> ") + ")"
+}
+
+// Splits the raw query into words, phrases, field scoped terms and operators
+func tokenizeQuery(raw string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(strings.TrimSpace(raw))
+
+	for i := 0; i < len(runes); {
+		if isQuerySpace(runes[i]) {
+			i++
+			continue
+		}
+
+		// Leading minus negates the next term
+		if runes[i] == '-' {
+			if i+1 >= len(runes) || isQuerySpace(runes[i+1]) {
+				return nil, errUnsupportedQuery
+			}
+			tokens = append(tokens, queryToken{kind: tokenNot})
+			i++
+			continue
+		}
+
+		// Optional field prefix
+		field := ""
+		if colon := fieldPrefixEnd(runes, i); colon > 0 {
+			weight, ok := queryFieldWeights[strings.ToLower(string(runes[i:colon]))]
+			if !ok {
+				return nil, errUnsupportedQuery
+			}
+			field = weight
+			i = colon + 1
+			if i >= len(runes) || isQuerySpace(runes[i]) {
+				return nil, errUnsupportedQuery
+			}
+		}
+
+		if runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, errUnsupportedQuery
+			}
+			words := queryWords(string(runes[i+1 : end]))
+			if len(words) == 0 {
+				return nil, errUnsupportedQuery
+			}
+			tokens = append(tokens, queryToken{kind: tokenPhrase, field: field, words: words})
+			i = end + 1
+			continue
+		}
+
+		start := i
+		for i < len(runes) && !isQuerySpace(runes[i]) {
+			// Grouping is not supported
+			if runes[i] == '"' || runes[i] == '(' || runes[i] == ')' {
+				return nil, errUnsupportedQuery
+			}
+			i++
+		}
+		word := string(runes[start:i])
+
+		if field == "" {
+			switch word {
+			case "OR":
+				tokens = append(tokens, queryToken{kind: tokenOr})
+				continue
+			case "AND":
+				tokens = append(tokens, queryToken{kind: tokenAnd})
+				continue
+			case "NOT":
+				tokens = append(tokens, queryToken{kind: tokenNot})
+				continue
+			}
+		}
+
+		tokens = append(tokens, queryToken{kind: tokenWord, field: field, words: []string{word}})
+	}
+
+	if len(tokens) == 0 {
+		return nil, errUnsupportedQuery
+	}
+	return tokens, nil
+}
+
+// Returns the index of the colon ending a field prefix at start, or -1
+func fieldPrefixEnd(runes []rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		switch {
+		case runes[i] == ':':
+			if i == start {
+				return -1
+			}
+			return i
+		case !unicode.IsLetter(runes[i]):
+			return -1
+		}
+	}
+	return -1
+}
+
+// Splits text into words. Punctuation is left in place, the lexemes are quoted when compiled.
+func queryWords(text string) []string {
+	return strings.FieldsFunc(text, isQuerySpace)
+}
+
+// Control characters separate words like whitespace, Postgres rejects some of them in text
+func isQuerySpace(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsControl(r)
+}
@@ -0,0 +1,133 @@
+package fts
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompileQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"implied and", "invoice report", `'invoice' & 'report'`},
+		{"explicit and", "invoice AND report", `'invoice' & 'report'`},
+		{"phrase", `"quarterly report"`, `('quarterly' <-> 'report')`},
+		{"or", "invoice OR receipt", `('invoice') | ('receipt')`},
+		{"and binds tighter than or", "paid invoice OR receipt", `('paid' & 'invoice') | ('receipt')`},
+		{"not keyword", "invoice NOT draft", `'invoice' & !'draft'`},
+		{"minus", "invoice -draft", `'invoice' & !'draft'`},
+		{"negated phrase", `NOT "draft copy"`, `!('draft' <-> 'copy')`},
+		{"negated field", "-tag:archive invoice", `!'archive':C & 'invoice'`},
+		{"title", "title:invoice", `'invoice':A`},
+		{"field is case insensitive", "Title:invoice", `'invoice':A`},
+		{"tag", "tag:finance", `'finance':C`},
+		{"tags", "tags:finance", `'finance':C`},
+		{"field phrase", `title:"q3 report"`, `('q3':A <-> 'report':A)`},
+		{"operator word in field", "title:OR", `'OR':A`},
+		{"lowercase operators are words", "invoice or not receipt", `'invoice' & 'or' & 'not' & 'receipt'`},
+		{"quote in word", "o'brien", `'o''brien'`},
+		{"backslash in word", `back\slash`, `'back\\slash'`},
+		{"unicode", `日本語 "Ärger café"`, `'日本語' & ('Ärger' <-> 'café')`},
+		{"control characters separate words", "invoice\x00report", `'invoice' & 'report'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compileQuery(tt.query, 10)
+			if err != nil {
+				t.Fatalf("compileQuery(%q) failed: %v", tt.query, err)
+			}
+			if got != tt.want {
+				t.Fatalf("compileQuery(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileQueryRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"empty", ""},
+		{"only spaces", "   "},
+
+		// Unbalanced quotes
+		{"unclosed phrase", `"quarterly report`},
+		{"unclosed field phrase", `title:"q3 report`},
+		{"quote inside word", `quarterly" report`},
+		{"empty phrase", `""`},
+		{"blank phrase", `"   "`},
+
+		// Dangling operators
+		{"only or", "OR"},
+		{"leading or", "OR invoice"},
+		{"trailing or", "invoice OR"},
+		{"double or", "invoice OR OR receipt"},
+		{"trailing and", "invoice AND"},
+		{"and before or", "invoice AND OR receipt"},
+		{"only not", "NOT"},
+		{"trailing not", "invoice NOT"},
+		{"not before or", "NOT OR receipt"},
+		{"double not", "NOT NOT draft"},
+		{"not and minus", "NOT -draft"},
+		{"lone minus", "invoice -"},
+		{"minus before space", "- draft"},
+
+		// Empty field prefixes
+		{"empty title", "title:"},
+		{"title before space", "title: invoice"},
+		{"empty title phrase", `title:""`},
+		{"unknown field", "author:smith"},
+		{"unknown unicode field", "título:informe"},
+
+		// Grouping is not supported
+		{"parentheses", "(invoice OR receipt) draft"},
+		{"nested parentheses", "invoice ((draft))"},
+		{"field group", "title:(invoice)"},
+		{"closing parenthesis", "invoice)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := compileQuery(tt.query, 10)
+			if !errors.Is(err, errUnsupportedQuery) {
+				t.Fatalf("compileQuery(%q) = %q, %v, want errUnsupportedQuery", tt.query, got, err)
+			}
+		})
+	}
+}
+
+func TestCompileQueryTermLimit(t *testing.T) {
+	if _, err := compileQuery(`alpha "beta gamma"`, 3); err != nil {
+		t.Fatalf("3 terms rejected with a limit of 3: %v", err)
+	}
+	if _, err := compileQuery(`alpha "beta gamma" delta`, 3); !errors.Is(err, errUnsupportedQuery) {
+		t.Fatalf("4 terms with a limit of 3: err = %v, want errUnsupportedQuery", err)
+	}
+}
+
+func TestHasQueryOperators(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"invoice report", false},
+		{"invoice or receipt", false},
+		{`"quarterly report"`, true},
+		{"invoice OR receipt", true},
+		{"-draft", true},
+		{"title:invoice", true},
+		// Left to the fuzzy strategy
+		{`"quarterly report`, false},
+		{"(invoice)", false},
+	}
+
+	for _, tt := range tests {
+		if got := hasQueryOperators(tt.query); got != tt.want {
+			t.Errorf("hasQueryOperators(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
@@ -1,23 +1,41 @@
 package handlers
 
 import (
+	"encoding/csv"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
 	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// Rows written between flushes of a streamed export
+const activityExportFlushRows = 500
+
+// Default and maximum number of days covered by document analytics
+const (
+	defaultAnalyticsDays = 30
+	maxAnalyticsDays     = 365
+)
+
 type ActivityHandler struct {
-	db *gorm.DB
+	db              *gorm.DB
+	activityService *services.ActivityService
 }
 
 func NewActivityHandler(db *gorm.DB) *ActivityHandler {
-	return &ActivityHandler{db: db}
+	return &ActivityHandler{
+		db:              db,
+		activityService: services.NewActivityService(db),
+	}
 }
 
 // Response DTOs
@@ -85,6 +103,88 @@ type UserActivitySummary struct {
 	LastActivity  string    `json:"lastActivity"`
 }
 
+// GetDocumentAnalytics godoc
+// @Summary Get access analytics of a document
+// @Description Views and downloads per day, unique viewers and the most recent accesses with IP address and user agent. Only the owner can see them, the owner's own accesses are left out.
+// @Tags activities
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param days query int false "Number of days covered, at most 365" default(30)
+// @Success 200 {object} utils.ApiResponse{data=types.DocumentAnalyticsResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_DOCUMENT_ID or INVALID_DAYS"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/analytics [get]
+func (h *ActivityHandler) GetDocumentAnalytics(c *gin.Context) {
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_DOCUMENT_ID", "Invalid document ID", err.Error())
+		return
+	}
+
+	days := defaultAnalyticsDays
+	if daysStr := c.Query("days"); daysStr != "" {
+		d, err := strconv.Atoi(daysStr)
+		if err != nil || d < 1 || d > maxAnalyticsDays {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_DAYS", fmt.Sprintf("days must be between 1 and %d", maxAnalyticsDays))
+			return
+		}
+		days = d
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.UnauthorizedResponse(c, "USER_NOT_AUTHENTICATED", "User not authenticated")
+		return
+	}
+
+	var document models.Document
+	if err := h.db.Where("id = ?", documentID).First(&document).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get document", err.Error())
+		return
+	}
+
+	// Accesses carry IP addresses and user agents, only the owner sees them
+	if document.UserID != userID.(uuid.UUID) {
+		utils.ForbiddenResponse(c, "ACCESS_DENIED", "Only the document owner can view its analytics")
+		return
+	}
+
+	analytics, err := h.activityService.GetDocumentAnalytics(c.Request.Context(), &document, days)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get document analytics", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, analytics, "Document analytics retrieved successfully")
+}
+
+// GetDocumentActivities godoc
+// @Summary List activities of a document
+// @Tags activities
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Param activity_type query string false "Activity type"
+// @Param from_date query string false "Start date (YYYY-MM-DD)"
+// @Param to_date query string false "End date (YYYY-MM-DD)"
+// @Param user_id query string false "Filter by user" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.ActivitiesListResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_DOCUMENT_ID"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/activities [get]
 func (h *ActivityHandler) GetDocumentActivities(c *gin.Context) {
 	documentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -140,29 +240,7 @@ func (h *ActivityHandler) GetDocumentActivities(c *gin.Context) {
 		Where("document_id = ?", documentID).
 		Preload("User").
 		Preload("Document")
-
-	// Apply filters
-	if activityType := c.Query("activity_type"); activityType != "" {
-		query = query.Where("activity_type = ?", activityType)
-	}
-
-	if filterUserID := c.Query("user_id"); filterUserID != "" {
-		if userUUID, err := uuid.Parse(filterUserID); err == nil {
-			query = query.Where("user_id = ?", userUUID)
-		}
-	}
-
-	if fromDate := c.Query("from_date"); fromDate != "" {
-		if parsedDate, err := time.Parse(time.RFC3339, fromDate); err == nil {
-			query = query.Where("created_at >= ?", parsedDate)
-		}
-	}
-
-	if toDate := c.Query("to_date"); toDate != "" {
-		if parsedDate, err := time.Parse(time.RFC3339, toDate); err == nil {
-			query = query.Where("created_at <= ?", parsedDate)
-		}
-	}
+	query = applyActivityFilters(c, query)
 
 	// Get total count
 	var total int64
@@ -195,6 +273,21 @@ func (h *ActivityHandler) GetDocumentActivities(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, response, "Activities retrieved successfully")
 }
 
+// GetUserActivities godoc
+// @Summary List activities of the current user
+// @Tags activities
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Param activity_type query string false "Activity type"
+// @Param from_date query string false "Start date (YYYY-MM-DD)"
+// @Param to_date query string false "End date (YYYY-MM-DD)"
+// @Param document_id query string false "Filter by document" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.ActivitiesListResponse}
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/activities [get]
 func (h *ActivityHandler) GetUserActivities(c *gin.Context) {
 	// Get current user
 	userID, exists := c.Get("userID")
@@ -223,29 +316,7 @@ func (h *ActivityHandler) GetUserActivities(c *gin.Context) {
 		Where("user_id = ?", userID.(uuid.UUID)).
 		Preload("User").
 		Preload("Document")
-
-	// Apply filters
-	if activityType := c.Query("activity_type"); activityType != "" {
-		query = query.Where("activity_type = ?", activityType)
-	}
-
-	if documentID := c.Query("document_id"); documentID != "" {
-		if docUUID, err := uuid.Parse(documentID); err == nil {
-			query = query.Where("document_id = ?", docUUID)
-		}
-	}
-
-	if fromDate := c.Query("from_date"); fromDate != "" {
-		if parsedDate, err := time.Parse(time.RFC3339, fromDate); err == nil {
-			query = query.Where("created_at >= ?", parsedDate)
-		}
-	}
-
-	if toDate := c.Query("to_date"); toDate != "" {
-		if parsedDate, err := time.Parse(time.RFC3339, toDate); err == nil {
-			query = query.Where("created_at <= ?", parsedDate)
-		}
-	}
+	query = applyActivityFilters(c, query)
 
 	// Get total count
 	var total int64
@@ -278,6 +349,16 @@ func (h *ActivityHandler) GetUserActivities(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, response, "User activities retrieved successfully")
 }
 
+// GetActivityStats godoc
+// @Summary Get activity statistics
+// @Tags activities
+// @Produce json
+// @Param document_id query string false "Limit the statistics to a document" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.ActivityStatsResponse}
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/activities/stats [get]
 func (h *ActivityHandler) GetActivityStats(c *gin.Context) {
 	// Get current user
 	userID, exists := c.Get("userID")
@@ -414,6 +495,157 @@ func (h *ActivityHandler) GetActivityStats(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, response, "Activity statistics retrieved successfully")
 }
 
+// ExportActivities godoc
+// @Summary Export activities as CSV
+// @Description Streams every activity in the range without pagination. Admins export the activities of all users, or of one user with user_id, everyone else only their own. Dates are RFC 3339 timestamps or YYYY-MM-DD, a plain end date includes the whole day.
+// @Tags activities
+// @Produce text/csv
+// @Param format query string false "Export format, only csv" default(csv)
+// @Param from query string false "Start date, alias of from_date"
+// @Param to query string false "End date, alias of to_date"
+// @Param activity_type query string false "Activity type"
+// @Param document_id query string false "Filter by document" format(uuid)
+// @Param user_id query string false "Filter by user, other users are only visible to admins" format(uuid)
+// @Success 200 {string} string "CSV file"
+// @Failure 400 {object} utils.ApiResponse "INVALID_FORMAT"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/activities/export [get]
+func (h *ActivityHandler) ExportActivities(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.UnauthorizedResponse(c, "USER_NOT_AUTHENTICATED", "User not authenticated")
+		return
+	}
+
+	if format := strings.ToLower(c.DefaultQuery("format", "csv")); format != "csv" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_FORMAT", "format must be csv")
+		return
+	}
+
+	query := h.db.Model(&models.DocumentActivity{}).
+		Select("document_activities.activity_type, document_activities.description, document_activities.document_id, " +
+			"documents.title AS document_title, users.username, document_activities.ip_address, " +
+			"document_activities.user_agent, document_activities.created_at").
+		Joins("LEFT JOIN documents ON documents.id = document_activities.document_id").
+		Joins("LEFT JOIN users ON users.id = document_activities.user_id")
+
+	// Admins export everyone's activities, other users only their own
+	if !middleware.HasPermission(c, models.PermissionAdminAccess) {
+		query = query.Where("document_activities.user_id = ?", userID.(uuid.UUID))
+	}
+	query = applyActivityFilters(c, query)
+
+	rows, err := query.Order("document_activities.created_at ASC").Rows()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to export activities", err.Error())
+		return
+	}
+	defer rows.Close()
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"activities-%s.csv\"", time.Now().Format("20060102")))
+	c.Status(http.StatusOK)
+
+	// Rows are written as they are read, the export is never held in memory
+	writer := csv.NewWriter(c.Writer)
+	_ = writer.Write([]string{"type", "description", "document_id", "document_title", "user", "ip_address", "user_agent", "timestamp"})
+
+	written := 0
+	for rows.Next() {
+		var row struct {
+			ActivityType  models.ActivityType
+			Description   string
+			DocumentID    uuid.UUID
+			DocumentTitle *string
+			Username      *string
+			IPAddress     string
+			UserAgent     string
+			CreatedAt     time.Time
+		}
+		if err := h.db.ScanRows(rows, &row); err != nil {
+			utils.RequestLogger(c).WithError(err).Error("Failed to read activity for export")
+			break
+		}
+
+		record := []string{
+			string(row.ActivityType),
+			row.Description,
+			row.DocumentID.String(),
+			stringValue(row.DocumentTitle),
+			stringValue(row.Username),
+			row.IPAddress,
+			row.UserAgent,
+			row.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := writer.Write(record); err != nil {
+			// The client went away, nothing left to write to
+			utils.RequestLogger(c).WithError(err).Warn("Activity export aborted")
+			return
+		}
+
+		written++
+		if written%activityExportFlushRows == 0 {
+			writer.Flush()
+			c.Writer.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		utils.RequestLogger(c).WithError(err).Error("Activity export ended early")
+	}
+
+	writer.Flush()
+}
+
+// Applies the activity_type, document_id, user_id and date range query filters of the activity
+// lists. Dates are RFC 3339 timestamps or plain dates, a plain end date includes the whole day.
+// from and to are accepted as short forms of from_date and to_date.
+func applyActivityFilters(c *gin.Context, query *gorm.DB) *gorm.DB {
+	if activityType := c.Query("activity_type"); activityType != "" {
+		query = query.Where("document_activities.activity_type = ?", activityType)
+	}
+
+	if documentID := c.Query("document_id"); documentID != "" {
+		if docUUID, err := uuid.Parse(documentID); err == nil {
+			query = query.Where("document_activities.document_id = ?", docUUID)
+		}
+	}
+
+	if filterUserID := c.Query("user_id"); filterUserID != "" {
+		if userUUID, err := uuid.Parse(filterUserID); err == nil {
+			query = query.Where("document_activities.user_id = ?", userUUID)
+		}
+	}
+
+	fromDate := c.DefaultQuery("from_date", c.Query("from"))
+	if fromDate != "" {
+		if parsedDate, err := time.Parse(time.RFC3339, fromDate); err == nil {
+			query = query.Where("document_activities.created_at >= ?", parsedDate)
+		} else if parsedDate, err := time.Parse(time.DateOnly, fromDate); err == nil {
+			query = query.Where("document_activities.created_at >= ?", parsedDate)
+		}
+	}
+
+	toDate := c.DefaultQuery("to_date", c.Query("to"))
+	if toDate != "" {
+		if parsedDate, err := time.Parse(time.RFC3339, toDate); err == nil {
+			query = query.Where("document_activities.created_at <= ?", parsedDate)
+		} else if parsedDate, err := time.Parse(time.DateOnly, toDate); err == nil {
+			query = query.Where("document_activities.created_at < ?", parsedDate.AddDate(0, 0, 1))
+		}
+	}
+
+	return query
+}
+
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
 // Helper function to transform activity to response
 func (h *ActivityHandler) transformActivityToResponse(activity models.DocumentActivity) ActivityResponse {
 	response := ActivityResponse{
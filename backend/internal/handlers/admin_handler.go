@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+
+	defaultAdminPageSize = 20
+	maxAdminPageSize     = 100
+)
+
+type AdminHandler struct {
+	adminService *services.AdminService
+}
+
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// GetStats returns platform-wide usage metrics for the requested number of days
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	days := defaultStatsDays
+	if raw := c.Query("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxStatsDays {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_DAYS", "days must be between 1 and 365")
+			return
+		}
+		days = parsed
+	}
+
+	stats, err := h.adminService.GetPlatformStats(c.Request.Context(), days)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "STATS_FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"stats": stats,
+	}, "Platform statistics retrieved successfully")
+}
+
+// TransferDocuments starts reassigning all documents of a user to another user
+func (h *AdminHandler) TransferDocuments(c *gin.Context) {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	sourceUserID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_USER_ID", "Invalid user ID format")
+		return
+	}
+
+	var req services.TransferDocumentsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", err.Error())
+		return
+	}
+	targetUserID, _ := uuid.Parse(req.TargetUserID)
+
+	transfer, created, err := h.adminService.StartDocumentTransfer(c.Request.Context(), adminID, sourceUserID, targetUserID)
+	if err != nil {
+		switch err.Error() {
+		case "source user not found", "target user not found":
+			utils.NotFoundResponse(c, "USER_NOT_FOUND", err.Error())
+		case "source and target users must differ":
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_TARGET_USER", err.Error())
+		case "another transfer is already in progress for this user":
+			utils.ConflictResponse(c, "TRANSFER_IN_PROGRESS", err.Error())
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "TRANSFER_FAILED", err.Error())
+		}
+		return
+	}
+
+	if !created {
+		utils.SuccessResponse(c, http.StatusOK, gin.H{"transfer": transfer}, "Transfer already in progress")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, gin.H{"transfer": transfer}, "Document transfer started")
+}
+
+// GetTransfer returns the progress of a document transfer
+func (h *AdminHandler) GetTransfer(c *gin.Context) {
+	transferID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_TRANSFER_ID", "Invalid transfer ID format")
+		return
+	}
+
+	transfer, err := h.adminService.GetDocumentTransfer(c.Request.Context(), transferID)
+	if err != nil {
+		if err.Error() == "transfer not found" {
+			utils.NotFoundResponse(c, "TRANSFER_NOT_FOUND", "Transfer not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "TRANSFER_FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"transfer": transfer}, "Transfer retrieved successfully")
+}
+
+// LookupUsers finds users by email or phone, including encrypted alternate emails and phones
+func (h *AdminHandler) LookupUsers(c *gin.Context) {
+	email := strings.TrimSpace(c.Query("email"))
+	phone := strings.TrimSpace(c.Query("phone"))
+	if email == "" && phone == "" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "LOOKUP_VALUE_REQUIRED", "email or phone is required")
+		return
+	}
+
+	users, err := h.adminService.LookupUsers(c.Request.Context(), email, phone)
+	if err != nil {
+		if err.Error() == "user lookup is not configured" {
+			utils.ServiceUnavailableResponse(c, "User lookup is not configured, set BLIND_INDEX_KEY to enable it")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "USER_LOOKUP_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"users": users}, "Users retrieved successfully")
+}
+
+// ReindexDocument recomputes the search vector of one document and returns it for inspection
+func (h *AdminHandler) ReindexDocument(c *gin.Context) {
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_DOCUMENT_ID", "Invalid document ID format")
+		return
+	}
+
+	result, err := h.adminService.ReindexDocument(c.Request.Context(), documentID)
+	if err != nil {
+		switch err.Error() {
+		case "document not found":
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+		case "search vector trigger is not installed":
+			utils.ErrorResponse(c, http.StatusConflict, "SEARCH_NOT_CONFIGURED", err.Error())
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "REINDEX_FAILED", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"reindex": result}, "Document search vector recomputed")
+}
+
+// StartSearchReindex recomputes search vectors of all documents, or those of one user or
+// created in a date range, in the background
+func (h *AdminHandler) StartSearchReindex(c *gin.Context) {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// An empty body reindexes every document
+	var req services.StartSearchReindexRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", err.Error())
+			return
+		}
+	}
+
+	job, created, err := h.adminService.StartSearchReindex(c.Request.Context(), adminID, &req)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			utils.NotFoundResponse(c, "USER_NOT_FOUND", err.Error())
+		case "createdFrom must not be after createdTo":
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_DATE_RANGE", err.Error())
+		case "search vector trigger is not installed":
+			utils.ErrorResponse(c, http.StatusConflict, "SEARCH_NOT_CONFIGURED", err.Error())
+		case "another reindex is already in progress":
+			utils.ConflictResponse(c, "REINDEX_IN_PROGRESS", err.Error())
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "REINDEX_FAILED", err.Error())
+		}
+		return
+	}
+
+	if !created {
+		utils.SuccessResponse(c, http.StatusOK, gin.H{"reindex": job}, "Reindex already in progress")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, gin.H{"reindex": job}, "Search reindex started")
+}
+
+// GetSearchReindex returns the progress of a search reindex
+func (h *AdminHandler) GetSearchReindex(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_REINDEX_ID", "Invalid reindex ID format")
+		return
+	}
+
+	job, err := h.adminService.GetSearchReindex(c.Request.Context(), jobID)
+	if err != nil {
+		if err.Error() == "reindex job not found" {
+			utils.NotFoundResponse(c, "REINDEX_NOT_FOUND", "Reindex job not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "REINDEX_FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"reindex": job}, "Reindex retrieved successfully")
+}
+
+// ListDocuments lists documents of all users with their owners
+func (h *AdminHandler) ListDocuments(c *gin.Context) {
+	page, limit, ok := parseAdminPagination(c)
+	if !ok {
+		return
+	}
+
+	req := &types.AdminDocumentListRequest{
+		Page:     page,
+		Limit:    limit,
+		Search:   strings.TrimSpace(c.Query("search")),
+		FileType: c.Query("fileType"),
+		Status:   c.Query("status"),
+	}
+	if raw := c.Query("ownerId"); raw != "" {
+		ownerID, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_USER_ID", "Invalid owner ID format")
+			return
+		}
+		req.OwnerID = &ownerID
+	}
+
+	result, err := h.adminService.ListDocuments(c.Request.Context(), req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "DOCUMENTS_FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result, "Documents retrieved successfully")
+}
+
+// ListUsers lists users with their status and usage
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	page, limit, ok := parseAdminPagination(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.adminService.ListUsers(c.Request.Context(), &types.AdminUserListRequest{
+		Page:   page,
+		Limit:  limit,
+		Search: strings.TrimSpace(c.Query("search")),
+		Status: c.Query("status"),
+	})
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "USERS_FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, result, "Users retrieved successfully")
+}
+
+// UpdateUserStatus activates or suspends a user
+func (h *AdminHandler) UpdateUserStatus(c *gin.Context) {
+	adminID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_USER_ID", "Invalid user ID format")
+		return
+	}
+
+	var req services.UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", "status must be active or suspended", err.Error())
+		return
+	}
+
+	user, err := h.adminService.SetUserStatus(c.Request.Context(), adminID, userID, req.Status)
+	if err != nil {
+		switch err.Error() {
+		case "user not found":
+			utils.NotFoundResponse(c, "USER_NOT_FOUND", "User not found")
+		case "cannot change your own status":
+			utils.ErrorResponse(c, http.StatusBadRequest, "CANNOT_CHANGE_OWN_STATUS", err.Error())
+		case "invalid status":
+			utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", "status must be active or suspended")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "STATUS_UPDATE_FAILED", err.Error())
+		}
+		return
+	}
+
+	message := "User activated successfully"
+	if user.Status == models.StatusSuspended {
+		message = "User suspended successfully"
+	}
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"user": user}, message)
+}
+
+// Reads page and limit, writing a 400 response and returning false when they are invalid
+func parseAdminPagination(c *gin.Context) (int, int, bool) {
+	page := 1
+	if raw := c.Query("page"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_PAGE", "page must be a positive number")
+			return 0, 0, false
+		}
+		page = parsed
+	}
+
+	limit := defaultAdminPageSize
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxAdminPageSize {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_LIMIT", "limit must be between 1 and 100")
+			return 0, 0, false
+		}
+		limit = parsed
+	}
+
+	return page, limit, true
+}
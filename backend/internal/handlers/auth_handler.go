@@ -21,6 +21,16 @@ func NewAuthHandler(authService *services.AuthService) *AuthHandler {
 	}
 }
 
+// Register godoc
+// @Summary Register a new account
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.RegisterRequest true "Account details"
+// @Success 201 {object} utils.ApiResponse{data=handlers.UserEnvelope}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR, REGISTRATION_FAILED"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Router /api/v1/auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
 
 	// Get validated request from context
@@ -64,6 +74,16 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, data, "Registration successful")
 }
 
+// Login godoc
+// @Summary Log in with email or username
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.LoginRequest true "Credentials"
+// @Success 200 {object} utils.ApiResponse{data=handlers.LoginResponse}
+// @Failure 401 {object} utils.ApiResponse "LOGIN_FAILED"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Router /api/v1/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	// Get validated login request from context
 	value, exists := c.Get("validatedLogin")
@@ -80,7 +100,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, tokens, err := h.authService.Login(c.Request.Context(), req)
+	user, tokens, err := h.authService.Login(c.Request.Context(), req, c.ClientIP(), c.GetHeader("User-Agent"))
 	if err != nil {
 		// Always return the same error message for all login failures
 		// This prevents user enumeration attacks
@@ -89,7 +109,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Generate avatar URL
-	avatarURL, _ := h.authService.GetAvatarURL(c.Request.Context(), user.Avatar)
+	avatarURL, _ := h.authService.GetAvatarURL(c.Request.Context(), user)
 
 	data := gin.H{
 		"user": gin.H{
@@ -122,6 +142,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, data, "Login successful")
 }
 
+// RefreshToken godoc
+// @Summary Exchange a refresh token for a new token pair
+// @Description Refresh tokens are single use. Presenting a token that was already exchanged revokes every token issued from the same login, all of those sessions have to log in again.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} utils.ApiResponse{data=handlers.TokensResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_REQUEST"
+// @Failure 401 {object} utils.ApiResponse "SESSION_EXPIRED"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Router /api/v1/auth/refresh [post]
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	// Get refresh token from request body
 	var req services.RefreshTokenRequest
@@ -130,7 +162,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken)
+	tokens, err := h.authService.RefreshToken(c.Request.Context(), req.RefreshToken, c.ClientIP(), c.GetHeader("User-Agent"))
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusUnauthorized, "SESSION_EXPIRED", "Your session has expired. Please login again.")
 		return
@@ -147,11 +179,18 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, data, "Token refreshed successfully")
 }
 
+// Logout godoc
+// @Summary Log out and revoke the refresh token
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body handlers.LogoutRequest false "Refresh token to revoke"
+// @Success 200 {object} utils.ApiResponse
+// @Failure 500 {object} utils.ApiResponse "LOGOUT_FAILED"
+// @Router /api/v1/auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Get refresh token from request body (optional)
-	var req struct {
-		RefreshToken string `json:"refreshToken"`
-	}
+	var req LogoutRequest
 
 	// Try to get refresh token from request body
 	if err := c.ShouldBindJSON(&req); err == nil && req.RefreshToken != "" {
@@ -164,6 +203,109 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, nil, "Logout successful")
 }
 
+// GetSessions godoc
+// @Summary List the active sessions of the current user
+// @Description Every login is a session, refreshing its tokens keeps it. The session of the request is marked current when its refresh token cookie is sent.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.ApiResponse{data=handlers.SessionsResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/auth/sessions [get]
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	currentRefreshToken, _ := c.Cookie("refresh_token")
+	sessions, err := h.authService.ListSessions(c.Request.Context(), userID, currentRefreshToken)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to fetch sessions")
+		return
+	}
+
+	data := gin.H{
+		"sessions": sessions,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Sessions retrieved successfully")
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description The session can no longer be refreshed. Access tokens it already holds stay valid until they expire.
+// @Tags auth
+// @Produce json
+// @Param id path string true "Session ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_SESSION_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "SESSION_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "REVOKE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_SESSION_ID", "Invalid session ID format")
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), userID, sessionID); err != nil {
+		if err.Error() == "session not found" {
+			utils.NotFoundResponse(c, "SESSION_NOT_FOUND", "Session not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "REVOKE_FAILED", "Failed to revoke session")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Session revoked")
+}
+
+// LogoutAll godoc
+// @Summary Log out of every session
+// @Description Revokes all refresh tokens of the user. Access tokens issued so far are rejected as well when Redis is available, otherwise they stay valid until they expire.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.ApiResponse
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "LOGOUT_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	if err := h.authService.LogoutAll(c.Request.Context(), userID, middleware.AccessTokenFromRequest(c)); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "LOGOUT_FAILED", "Failed to log out of all sessions")
+		return
+	}
+
+	h.authService.ClearAuthCookies(c)
+	utils.SuccessResponse(c, http.StatusOK, nil, "Logged out of all sessions")
+}
+
+// GetProfile godoc
+// @Summary Get the profile of the current user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.ApiResponse{data=handlers.ProfileResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "USER_NOT_FOUND"
+// @Security BearerAuth
+// @Router /api/v1/auth/profile [get]
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -178,7 +320,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	}
 
 	// Generate avatar URL if avatar path exists
-	avatarURL, _ := h.authService.GetAvatarURL(c.Request.Context(), user.Avatar)
+	avatarURL, _ := h.authService.GetAvatarURL(c.Request.Context(), user)
 
 	// Add encrypted fields if they exist
 	encryptedFields := make(map[string]interface{})
@@ -259,6 +401,19 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, data, "Profile retrieved successfully")
 }
 
+// UpdateProfile godoc
+// @Summary Update the profile of the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.UpdateProfileRequest true "Fields to change"
+// @Success 200 {object} utils.ApiResponse{data=handlers.UserEnvelope}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR, UPDATE_FAILED"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 409 {object} utils.ApiResponse "USERNAME_ALREADY_EXISTS"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Security BearerAuth
+// @Router /api/v1/auth/profile [put]
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -297,6 +452,18 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, data, "Profile updated successfully")
 }
 
+// UploadAvatar godoc
+// @Summary Upload an avatar image
+// @Tags auth
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} utils.ApiResponse{data=handlers.AvatarResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_FILE"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "UPLOAD_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/auth/profile/avatar [post]
 func (h *AuthHandler) UploadAvatar(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -324,6 +491,18 @@ func (h *AuthHandler) UploadAvatar(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, data, "Avatar updated successfully")
 }
 
+// ChangePassword godoc
+// @Summary Change the password of the current user
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.ChangePasswordRequest true "Old and new password"
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR, PASSWORD_CHANGE_FAILED"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED, INVALID_OLD_PASSWORD"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Security BearerAuth
+// @Router /api/v1/auth/change-password [put]
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -359,11 +538,170 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, nil, "Password changed successfully")
 }
 
+// SendVerificationEmail godoc
+// @Summary Send an email verification link
+// @Description The response is the same whether or not the address belongs to an unverified account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.SendVerificationRequest true "Email address to verify"
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_REQUEST"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Failure 500 {object} utils.ApiResponse "VERIFICATION_EMAIL_FAILED"
+// @Failure 503 {object} utils.ApiResponse "EMAIL_DISABLED"
+// @Router /api/v1/auth/verify/send [post]
+func (h *AuthHandler) SendVerificationEmail(c *gin.Context) {
+	var req services.SendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_REQUEST", "A valid email is required")
+		return
+	}
+
+	if err := h.authService.SendVerificationEmail(c.Request.Context(), req.Email); err != nil {
+		if err.Error() == "email delivery is not configured" {
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "EMAIL_DISABLED", "Email delivery is not available")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "VERIFICATION_EMAIL_FAILED", "Failed to send verification email")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "If the address belongs to an unverified account, a verification email has been sent")
+}
+
+// VerifyEmail godoc
+// @Summary Verify an email address with the emailed token
+// @Tags auth
+// @Produce json
+// @Param token path string true "Verification token"
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_TOKEN, TOKEN_EXPIRED"
+// @Failure 409 {object} utils.ApiResponse "TOKEN_ALREADY_USED"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Failure 500 {object} utils.ApiResponse "VERIFICATION_FAILED"
+// @Router /api/v1/auth/verify/{token} [get]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	if err := h.authService.VerifyEmail(c.Request.Context(), c.Param("token")); err != nil {
+		switch err.Error() {
+		case "invalid verification token":
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_TOKEN", "Verification link is invalid")
+		case "verification token expired":
+			utils.ErrorResponse(c, http.StatusBadRequest, "TOKEN_EXPIRED", "Verification link has expired, request a new one")
+		case "verification token already used":
+			utils.ErrorResponse(c, http.StatusConflict, "TOKEN_ALREADY_USED", "Verification link was already used")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "VERIFICATION_FAILED", "Failed to verify email")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Email verified successfully")
+}
+
+// ForgotPassword godoc
+// @Summary Email a password reset link
+// @Description The response is the same whether or not the address belongs to an account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_REQUEST"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Failure 500 {object} utils.ApiResponse "PASSWORD_RESET_FAILED"
+// @Failure 503 {object} utils.ApiResponse "EMAIL_DISABLED"
+// @Router /api/v1/auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req services.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_REQUEST", "A valid email is required")
+		return
+	}
+
+	if err := h.authService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		if err.Error() == "email delivery is not configured" {
+			utils.ErrorResponse(c, http.StatusServiceUnavailable, "EMAIL_DISABLED", "Email delivery is not available")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "PASSWORD_RESET_FAILED", "Failed to start password reset")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "If the address belongs to an account, a password reset email has been sent")
+}
+
+// ResetPassword godoc
+// @Summary Set a new password with a reset token
+// @Description Signs out every session of the account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR, INVALID_TOKEN"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Failure 500 {object} utils.ApiResponse "PASSWORD_RESET_FAILED"
+// @Router /api/v1/auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	value, exists := c.Get("validatedPasswordReset")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	req, ok := value.(*services.ResetPasswordRequest)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Invalid validated data type")
+		return
+	}
+
+	if err := h.authService.ResetPassword(c.Request.Context(), req); err != nil {
+		if err.Error() == "invalid or expired reset token" {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_TOKEN", "Reset link is invalid or has expired")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "PASSWORD_RESET_FAILED", "Failed to reset password")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Password reset successfully, please log in again")
+}
+
+// GetJWKS godoc
+// @Summary Keys for verifying access tokens
+// @Description JSON Web Key Set of the RS256 signing key, so other services can verify access tokens without the API. Only served when JWT_ALGORITHM is RS256, the response is the bare key set rather than the API envelope.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} services.JWKS
+// @Failure 404 {object} utils.ApiResponse "JWKS_NOT_AVAILABLE"
+// @Router /.well-known/jwks.json [get]
+func (h *AuthHandler) GetJWKS(c *gin.Context) {
+	jwks, ok := h.authService.JWKS()
+	if !ok {
+		utils.NotFoundResponse(c, "JWKS_NOT_AVAILABLE", "Tokens are not signed with a public key")
+		return
+	}
+
+	// Verifiers cache the keys, a rotated key is picked up within the hour
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, jwks)
+}
+
+// ValidateToken godoc
+// @Summary Check a refresh token without consuming it
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body services.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} utils.ApiResponse{data=handlers.TokenValidResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_REQUEST"
+// @Failure 401 {object} utils.ApiResponse "INVALID_TOKEN"
+// @Failure 500 {object} utils.ApiResponse "VALIDATION_ERROR"
+// @Router /api/v1/auth/validate [post]
 func (h *AuthHandler) ValidateToken(c *gin.Context) {
 	// Get refresh token from request body
-	var req struct {
-		RefreshToken string `json:"refreshToken" binding:"required"`
-	}
+	var req services.RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
 		return
@@ -384,6 +722,52 @@ func (h *AuthHandler) ValidateToken(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, gin.H{"valid": true}, "Token is valid")
 }
 
+// GetUserAvatar serves the generated avatar for users who have not uploaded one
+// @Summary Get the generated avatar of a user
+// @Tags auth
+// @Produce image/svg+xml
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {file} binary "SVG avatar"
+// @Success 304 "Not modified"
+// @Failure 400 {object} utils.ApiResponse "INVALID_USER_ID"
+// @Failure 404 {object} utils.ApiResponse "USER_NOT_FOUND"
+// @Router /api/v1/auth/users/{id}/avatar [get]
+func (h *AuthHandler) GetUserAvatar(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_USER_ID", "Invalid user ID format")
+		return
+	}
+
+	svg, version, err := h.authService.GetGeneratedAvatar(c.Request.Context(), userID)
+	if err != nil {
+		utils.NotFoundResponse(c, "USER_NOT_FOUND", err.Error())
+		return
+	}
+
+	etag := `"` + version + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=86400")
+	// SVG can carry scripts, never let the browser execute anything from it
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "image/svg+xml", svg)
+}
+
+// DeleteAvatar godoc
+// @Summary Remove the uploaded avatar
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.ApiResponse{data=handlers.AvatarResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "DELETE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/auth/profile/avatar [delete]
 func (h *AuthHandler) DeleteAvatar(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -400,6 +784,16 @@ func (h *AuthHandler) DeleteAvatar(c *gin.Context) {
 }
 
 // GetFullNameByID returns only the full name of a user by their ID.
+// @Summary Get the full name of a user
+// @Tags auth
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.FullNameResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "USER_NOT_FOUND"
+// @Security BearerAuth
+// @Router /api/v1/auth/users/{id}/full-name [get]
 func (h *AuthHandler) GetFullNameByID(c *gin.Context) {
 	idParam := c.Param("id")
 	userID, err := uuid.Parse(idParam)
@@ -418,6 +812,14 @@ func (h *AuthHandler) GetFullNameByID(c *gin.Context) {
 }
 
 // GetMyFullName returns the full name of the currently authenticated user.
+// @Summary Get the full name of the current user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} utils.ApiResponse{data=handlers.FullNameResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "USER_NOT_FOUND"
+// @Security BearerAuth
+// @Router /api/v1/auth/profile/full-name [get]
 func (h *AuthHandler) GetMyFullName(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
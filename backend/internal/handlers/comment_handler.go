@@ -1,27 +1,47 @@
 package handlers
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/services"
 	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
 	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Longest excerpt of a reply quoted in the notification to the parent comment's author
+const replySnippetMaxBytes = 140
+
 type CommentHandler struct {
-	db          *gorm.DB
-	authService *services.AuthService
+	db              *gorm.DB
+	authService     *services.AuthService
+	activityService *services.ActivityService
+	limits          config.CommentLimitConfig
 }
 
-func NewCommentHandler(db *gorm.DB, authService *services.AuthService) *CommentHandler {
+func NewCommentHandler(db *gorm.DB, authService *services.AuthService, limits config.CommentLimitConfig) *CommentHandler {
 	return &CommentHandler{
-		db:          db,
-		authService: authService,
+		db:              db,
+		authService:     authService,
+		activityService: services.NewActivityService(db),
+		limits:          limits,
 	}
 }
 
@@ -51,12 +71,26 @@ type CommentResponse struct {
 	IsEdited        bool                    `json:"isEdited"`
 	EditedAt        *string                 `json:"editedAt,omitempty"`
 	User            UserResponse            `json:"user"`
+	Mentions        []uuid.UUID             `json:"mentions"`
 	ReplyCount      int                     `json:"replyCount"`
+	Reactions       []ReactionSummary       `json:"reactions"`
 	Replies         []CommentResponse       `json:"replies,omitempty"`
 	CreatedAt       string                  `json:"createdAt"`
 	UpdatedAt       string                  `json:"updatedAt"`
 }
 
+// Represents how many users reacted to a comment with an emoji
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"` // Whether the current user is one of them
+}
+
+type CommentReactionsResponse struct {
+	CommentID uuid.UUID         `json:"commentID"`
+	Reactions []ReactionSummary `json:"reactions"`
+}
+
 type UserResponse struct {
 	ID       uuid.UUID `json:"id"`
 	Username string    `json:"username"`
@@ -73,6 +107,56 @@ type CommentsListResponse struct {
 	HasNext  bool              `json:"hasNext"`
 }
 
+type AnnotationAuthor struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Name     string    `json:"name"`
+}
+
+type AnnotationExport struct {
+	ID         uuid.UUID               `json:"id"`
+	Content    string                  `json:"content"`
+	Position   *models.CommentPosition `json:"position,omitempty"`
+	Author     AnnotationAuthor        `json:"author"`
+	IsResolved bool                    `json:"isResolved"`
+	ResolvedBy *uuid.UUID              `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time              `json:"resolvedAt,omitempty"`
+	CreatedAt  time.Time               `json:"createdAt"`
+	UpdatedAt  time.Time               `json:"updatedAt"`
+}
+
+type AnnotationExportResponse struct {
+	DocumentID  uuid.UUID          `json:"documentID"`
+	Title       string             `json:"title"`
+	Annotations []AnnotationExport `json:"annotations"`
+}
+
+// Replies embedded with each top-level comment in the list, the rest is fetched per thread
+const latestRepliesPerComment = 3
+
+// US Letter in PDF points, used for XFDF when the page size is not given
+const (
+	defaultPDFPageWidth  = 612.0
+	defaultPDFPageHeight = 792.0
+)
+
+// GetDocumentComments godoc
+// @Summary List comments of a document
+// @Description Top-level comments with their total replyCount and the latest 3 replies, older replies are paged through the replies endpoint.
+// @Tags comments
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Param resolved query bool false "Filter by resolved state"
+// @Success 200 {object} utils.ApiResponse{data=handlers.CommentsListResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_DOCUMENT_ID, VALIDATION_FAILED"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/comments [get]
 func (h *CommentHandler) GetDocumentComments(c *gin.Context) {
 	documentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -115,13 +199,10 @@ func (h *CommentHandler) GetDocumentComments(c *gin.Context) {
 		}
 	}
 
-	// Build query for top-level comments (not replies)
+	// Build query for top-level comments (not replies), replies are loaded in one batch below
 	query := h.db.Model(&models.DocumentComment{}).
 		Where("document_id = ? AND parent_comment_id IS NULL", documentID).
-		Preload("User").
-		Preload("Replies", func(db *gorm.DB) *gorm.DB {
-			return db.Preload("User").Order("created_at ASC")
-		})
+		Preload("User")
 
 	if listReq.Resolved != nil {
 		query = query.Where("is_resolved = ?", *listReq.Resolved)
@@ -151,13 +232,290 @@ func (h *CommentHandler) GetDocumentComments(c *gin.Context) {
 		HasNext:  int64(listReq.Page*listReq.Limit) < total,
 	}
 
+	replyCounts, err := h.attachLatestReplies(comments)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get replies", err.Error())
+		return
+	}
+
 	for i, comment := range comments {
 		response.Comments[i] = h.transformCommentToResponse(comment)
+		response.Comments[i].ReplyCount = replyCounts[comment.ID]
+	}
+
+	if err := h.attachReactions(userID.(uuid.UUID), response.Comments); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get reactions", err.Error())
+		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, response, "Comments retrieved successfully")
 }
 
+// GetCommentReplies godoc
+// @Summary List replies of a comment
+// @Description Newest replies first, so the first page matches the replies embedded in the comment list.
+// @Tags comments
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param commentId path string true "Comment ID" format(uuid)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size" default(20)
+// @Param resolved query bool false "Filter by resolved state"
+// @Success 200 {object} utils.ApiResponse{data=handlers.CommentsListResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_DOCUMENT_ID, INVALID_COMMENT_ID, VALIDATION_FAILED"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND, COMMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/comments/{commentId}/replies [get]
+func (h *CommentHandler) GetCommentReplies(c *gin.Context) {
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_DOCUMENT_ID", "Invalid document ID", err.Error())
+		return
+	}
+
+	commentID, err := uuid.Parse(c.Param("commentId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_COMMENT_ID", "Invalid comment ID", err.Error())
+		return
+	}
+
+	listReq, ok := validations.GetValidatedCommentList(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_FAILED", "Failed to get validated request")
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.UnauthorizedResponse(c, "USER_NOT_AUTHENTICATED", "User not authenticated")
+		return
+	}
+
+	// Check if document exists and user has access
+	var document models.Document
+	if err := h.db.Where("id = ?", documentID).First(&document).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get document", err.Error())
+		return
+	}
+
+	if document.UserID != userID.(uuid.UUID) && !document.IsPublic {
+		var userShare models.UserShare
+		if err := h.db.Where("document_id = ? AND shared_with_user_id = ? AND is_revoked = false", documentID, userID).First(&userShare).Error; err != nil {
+			utils.ForbiddenResponse(c, "ACCESS_DENIED", "Access denied")
+			return
+		}
+	}
+
+	var parent models.DocumentComment
+	if err := h.db.Select("id").Where("id = ? AND document_id = ?", commentID, documentID).First(&parent).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "COMMENT_NOT_FOUND", "Comment not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get comment", err.Error())
+		return
+	}
+
+	query := h.db.Model(&models.DocumentComment{}).Where("parent_comment_id = ?", commentID)
+	if listReq.Resolved != nil {
+		query = query.Where("is_resolved = ?", *listReq.Resolved)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to count replies", err.Error())
+		return
+	}
+
+	var replies []models.DocumentComment
+	offset := (listReq.Page - 1) * listReq.Limit
+	if err := query.Preload("User").Offset(offset).Limit(listReq.Limit).Order("created_at DESC").Find(&replies).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get replies", err.Error())
+		return
+	}
+
+	response := CommentsListResponse{
+		Comments: make([]CommentResponse, len(replies)),
+		Total:    total,
+		Page:     listReq.Page,
+		Limit:    listReq.Limit,
+		HasNext:  int64(listReq.Page*listReq.Limit) < total,
+	}
+
+	for i, reply := range replies {
+		response.Comments[i] = h.transformCommentToResponse(reply)
+	}
+
+	if err := h.attachReactions(userID.(uuid.UUID), response.Comments); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get reactions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response, "Replies retrieved successfully")
+}
+
+// ExportAnnotations godoc
+// @Summary Export the annotations of a document
+// @Description Annotation comments with their position, author and resolved state. The xfdf format is only available for PDFs and can be imported into PDF readers, positions are converted to points using the given page size.
+// @Tags comments
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.adobe.xfdf
+// @Param id path string true "Document ID" format(uuid)
+// @Param format query string false "json, csv or xfdf" default(json)
+// @Param resolved query bool false "Only resolved or only unresolved annotations"
+// @Param pageWidth query number false "PDF page width in points for xfdf" default(612)
+// @Param pageHeight query number false "PDF page height in points for xfdf" default(792)
+// @Success 200 {object} utils.ApiResponse{data=handlers.AnnotationExportResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_DOCUMENT_ID, INVALID_FORMAT, INVALID_RESOLVED, INVALID_PAGE_SIZE"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/annotations/export [get]
+func (h *CommentHandler) ExportAnnotations(c *gin.Context) {
+	documentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_DOCUMENT_ID", "Invalid document ID", err.Error())
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "csv" && format != "xfdf" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_FORMAT", "format must be json, csv or xfdf")
+		return
+	}
+
+	var resolved *bool
+	if raw := c.Query("resolved"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_RESOLVED", "resolved must be true or false")
+			return
+		}
+		resolved = &value
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.UnauthorizedResponse(c, "USER_NOT_AUTHENTICATED", "User not authenticated")
+		return
+	}
+
+	// Check if document exists and user has access
+	var document models.Document
+	if err := h.db.Where("id = ?", documentID).First(&document).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get document", err.Error())
+		return
+	}
+
+	if document.UserID != userID.(uuid.UUID) && !document.IsPublic {
+		var userShare models.UserShare
+		if err := h.db.Where("document_id = ? AND shared_with_user_id = ? AND is_revoked = false", documentID, userID).First(&userShare).Error; err != nil {
+			utils.ForbiddenResponse(c, "ACCESS_DENIED", "Access denied")
+			return
+		}
+	}
+
+	if format == "xfdf" && document.FileType != models.DocumentTypePDF {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_FORMAT", "xfdf export is only available for PDF documents")
+		return
+	}
+
+	query := h.db.Where("document_id = ? AND comment_type = ?", documentID, models.CommentTypeAnnotation).
+		Preload("User").
+		Order("pos_page ASC NULLS LAST, created_at ASC")
+	if resolved != nil {
+		query = query.Where("is_resolved = ?", *resolved)
+	}
+
+	var comments []models.DocumentComment
+	if err := query.Find(&comments).Error; err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get annotations", err.Error())
+		return
+	}
+
+	annotations := make([]AnnotationExport, len(comments))
+	for i, comment := range comments {
+		annotations[i] = AnnotationExport{
+			ID:       comment.ID,
+			Content:  comment.Content,
+			Position: comment.Position,
+			Author: AnnotationAuthor{
+				ID:       comment.User.ID,
+				Username: comment.User.Username,
+				Name:     comment.User.Name,
+			},
+			IsResolved: comment.IsResolved,
+			ResolvedBy: comment.ResolvedBy,
+			ResolvedAt: comment.ResolvedAt,
+			CreatedAt:  comment.CreatedAt,
+			UpdatedAt:  comment.UpdatedAt,
+		}
+	}
+
+	baseName := fmt.Sprintf("annotations-%s", documentID)
+	switch format {
+	case "csv":
+		data, err := annotationsToCSV(annotations)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to export annotations", err.Error())
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", baseName))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+	case "xfdf":
+		pageWidth, widthErr := parsePageSize(c.Query("pageWidth"), defaultPDFPageWidth)
+		pageHeight, heightErr := parsePageSize(c.Query("pageHeight"), defaultPDFPageHeight)
+		if widthErr != nil || heightErr != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_PAGE_SIZE", "pageWidth and pageHeight must be positive numbers of points")
+			return
+		}
+		data, err := annotationsToXFDF(annotations, document.OriginalFileName, pageWidth, pageHeight)
+		if err != nil {
+			utils.InternalServerErrorResponse(c, "Failed to export annotations", err.Error())
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.xfdf\"", baseName))
+		c.Data(http.StatusOK, "application/vnd.adobe.xfdf", data)
+	default:
+		utils.SuccessResponse(c, http.StatusOK, AnnotationExportResponse{
+			DocumentID:  document.ID,
+			Title:       document.Title,
+			Annotations: annotations,
+		}, "Annotations exported successfully")
+	}
+}
+
+// CreateComment godoc
+// @Summary Add a comment or reply to a document
+// @Description Each @username in the content notifies that user when they can open the document, the resolved user IDs are returned in mentions. A reply notifies the author of the parent comment on the same terms. Documents hold a limited number of comments, and of annotations per page.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param request body validations.CreateCommentRequest true "Comment"
+// @Success 201 {object} utils.ApiResponse{data=handlers.CommentResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_DOCUMENT_ID, INVALID_REQUEST, VALIDATION_FAILED, PARENT_COMMENT_NOT_FOUND"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "COMMENT_LIMIT_REACHED"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/comments [post]
 func (h *CommentHandler) CreateComment(c *gin.Context) {
 	documentID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
@@ -201,8 +559,8 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 	}
 
 	// Validate parent comment if it's a reply
+	var parentComment models.DocumentComment
 	if req.ParentCommentID != nil {
-		var parentComment models.DocumentComment
 		if err := h.db.Where("id = ? AND document_id = ?", *req.ParentCommentID, documentID).First(&parentComment).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				utils.ErrorResponse(c, http.StatusBadRequest, "PARENT_COMMENT_NOT_FOUND", "Parent comment not found", err.Error())
@@ -214,6 +572,12 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		// Don't override comment type - user can reply with any comment type
 	}
 
+	mentions, err := h.resolveMentions(&document, userID.(uuid.UUID), req.Content)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to resolve mentions", err.Error())
+		return
+	}
+
 	// Create comment
 	comment := models.DocumentComment{
 		DocumentID:      documentID,
@@ -222,12 +586,35 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		CommentType:     req.CommentType,
 		Position:        req.Position,
 		ParentCommentID: req.ParentCommentID,
+		Mentions:        mentions,
 	}
 
-	if err := h.db.Create(&comment).Error; err != nil {
+	// Locking the document row serializes concurrent creates, so the limits hold as a hard ceiling
+	var limitMessage string
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", documentID).First(&models.Document{}).Error; err != nil {
+			return err
+		}
+
+		message, err := h.commentLimitMessage(tx, &comment)
+		if err != nil {
+			return err
+		}
+		if message != "" {
+			limitMessage = message
+			return nil
+		}
+
+		return tx.Create(&comment).Error
+	})
+	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to create comment", err.Error())
 		return
 	}
+	if limitMessage != "" {
+		utils.ErrorResponse(c, http.StatusConflict, "COMMENT_LIMIT_REACHED", limitMessage)
+		return
+	}
 
 	// Load relations
 	if err := h.db.Preload("User").Preload("Replies", func(db *gorm.DB) *gorm.DB {
@@ -237,10 +624,30 @@ func (h *CommentHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
+	h.notifyMentions(c, &document, &comment)
+	if comment.IsReply() {
+		h.notifyReply(c, &document, &parentComment, &comment)
+	}
+
 	response := h.transformCommentToResponse(comment)
 	utils.SuccessResponse(c, http.StatusCreated, response, "Comment created successfully")
 }
 
+// UpdateComment godoc
+// @Summary Edit a comment
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID" format(uuid)
+// @Param request body validations.UpdateCommentRequest true "New content"
+// @Success 200 {object} utils.ApiResponse{data=handlers.CommentResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_REQUEST, VALIDATION_FAILED"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "EDIT_FORBIDDEN"
+// @Failure 404 {object} utils.ApiResponse "COMMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/comments/{id} [put]
 func (h *CommentHandler) UpdateComment(c *gin.Context) {
 	// Get validated comment ID
 	commentID, ok := validations.GetValidatedCommentID(c)
@@ -297,10 +704,28 @@ func (h *CommentHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	response := h.transformCommentToResponse(comment)
-	utils.SuccessResponse(c, http.StatusOK, response, "Comment updated successfully")
+	responses := []CommentResponse{h.transformCommentToResponse(comment)}
+	if err := h.attachReactions(userID.(uuid.UUID), responses); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get reactions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, responses[0], "Comment updated successfully")
 }
 
+// DeleteComment godoc
+// @Summary Delete a comment
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_FAILED"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "DELETE_FORBIDDEN"
+// @Failure 404 {object} utils.ApiResponse "COMMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/comments/{id} [delete]
 func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	// Get validated comment ID
 	commentID, ok := validations.GetValidatedCommentID(c)
@@ -342,6 +767,19 @@ func (h *CommentHandler) DeleteComment(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, nil, "Comment deleted successfully")
 }
 
+// ResolveComment godoc
+// @Summary Resolve a comment
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.CommentResponse}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_FAILED"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "RESOLVE_FORBIDDEN"
+// @Failure 404 {object} utils.ApiResponse "COMMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/comments/{id}/resolve [post]
 func (h *CommentHandler) ResolveComment(c *gin.Context) {
 	// Get validated comment ID
 	commentID, ok := validations.GetValidatedCommentID(c)
@@ -390,10 +828,28 @@ func (h *CommentHandler) ResolveComment(c *gin.Context) {
 		return
 	}
 
-	response := h.transformCommentToResponse(comment)
-	utils.SuccessResponse(c, http.StatusOK, response, "Comment resolved successfully")
+	responses := []CommentResponse{h.transformCommentToResponse(comment)}
+	if err := h.attachReactions(userID.(uuid.UUID), responses); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get reactions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, responses[0], "Comment resolved successfully")
 }
 
+// UnresolveComment godoc
+// @Summary Reopen a comment
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.CommentResponse}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_FAILED"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "UNRESOLVE_FORBIDDEN"
+// @Failure 404 {object} utils.ApiResponse "COMMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/comments/{id}/unresolve [post]
 func (h *CommentHandler) UnresolveComment(c *gin.Context) {
 	// Get validated comment ID
 	commentID, ok := validations.GetValidatedCommentID(c)
@@ -444,8 +900,343 @@ func (h *CommentHandler) UnresolveComment(c *gin.Context) {
 
 	// TODO: Create activity log
 
-	response := h.transformCommentToResponse(comment)
-	utils.SuccessResponse(c, http.StatusOK, response, "Comment unresolved successfully")
+	responses := []CommentResponse{h.transformCommentToResponse(comment)}
+	if err := h.attachReactions(userID.(uuid.UUID), responses); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get reactions", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, responses[0], "Comment unresolved successfully")
+}
+
+// AddReaction godoc
+// @Summary React to a comment
+// @Description Adding a reaction the user already has is a no-op. Only users who can open the document can react.
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID" format(uuid)
+// @Param request body validations.CommentReactionRequest true "Emoji, one of 👍 👎 ❤️ 🎉 😄 😕 👀 🚀"
+// @Success 200 {object} utils.ApiResponse{data=handlers.CommentReactionsResponse} "Already reacted"
+// @Success 201 {object} utils.ApiResponse{data=handlers.CommentReactionsResponse}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_FAILED, INVALID_COMMENT_ID"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "COMMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/comments/{id}/reactions [post]
+func (h *CommentHandler) AddReaction(c *gin.Context) {
+	commentID, emoji, userID, ok := h.reactionRequest(c)
+	if !ok {
+		return
+	}
+
+	reaction := models.CommentReaction{
+		CommentID: commentID,
+		UserID:    userID,
+		Emoji:     emoji,
+	}
+	result := h.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&reaction)
+	if result.Error != nil {
+		utils.InternalServerErrorResponse(c, "Failed to add reaction", result.Error.Error())
+		return
+	}
+
+	reactions, err := h.loadReactions(userID, []uuid.UUID{commentID})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get reactions", err.Error())
+		return
+	}
+
+	status := http.StatusCreated
+	if result.RowsAffected == 0 {
+		status = http.StatusOK
+	}
+	response := CommentReactionsResponse{
+		CommentID: commentID,
+		Reactions: reactions[commentID],
+	}
+	utils.SuccessResponse(c, status, response, "Reaction added successfully")
+}
+
+// RemoveReaction godoc
+// @Summary Remove a reaction from a comment
+// @Tags comments
+// @Produce json
+// @Param id path string true "Comment ID" format(uuid)
+// @Param emoji path string true "Emoji of the reaction, URL encoded"
+// @Success 200 {object} utils.ApiResponse{data=handlers.CommentReactionsResponse}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_FAILED, INVALID_COMMENT_ID"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "COMMENT_NOT_FOUND, REACTION_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/comments/{id}/reactions/{emoji} [delete]
+func (h *CommentHandler) RemoveReaction(c *gin.Context) {
+	commentID, emoji, userID, ok := h.reactionRequest(c)
+	if !ok {
+		return
+	}
+
+	result := h.db.Where("comment_id = ? AND user_id = ? AND emoji = ?", commentID, userID, emoji).Delete(&models.CommentReaction{})
+	if result.Error != nil {
+		utils.InternalServerErrorResponse(c, "Failed to remove reaction", result.Error.Error())
+		return
+	}
+	if result.RowsAffected == 0 {
+		utils.NotFoundResponse(c, "REACTION_NOT_FOUND", "You haven't reacted with this emoji")
+		return
+	}
+
+	reactions, err := h.loadReactions(userID, []uuid.UUID{commentID})
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get reactions", err.Error())
+		return
+	}
+
+	response := CommentReactionsResponse{
+		CommentID: commentID,
+		Reactions: reactions[commentID],
+	}
+	utils.SuccessResponse(c, http.StatusOK, response, "Reaction removed successfully")
+}
+
+// Reads the validated comment and emoji of a reaction request and checks the user can open the
+// comment's document. Responds and returns false when the request can't go on.
+func (h *CommentHandler) reactionRequest(c *gin.Context) (uuid.UUID, string, uuid.UUID, bool) {
+	commentID, ok := validations.GetValidatedCommentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_FAILED", "Failed to get validated comment ID")
+		return uuid.Nil, "", uuid.Nil, false
+	}
+
+	emoji, ok := validations.GetValidatedReaction(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_FAILED", "Failed to get validated reaction")
+		return uuid.Nil, "", uuid.Nil, false
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.UnauthorizedResponse(c, "USER_NOT_AUTHENTICATED", "User not authenticated")
+		return uuid.Nil, "", uuid.Nil, false
+	}
+
+	var comment models.DocumentComment
+	if err := h.db.Preload("Document").Where("id = ?", commentID).First(&comment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			utils.NotFoundResponse(c, "COMMENT_NOT_FOUND", "Comment not found")
+			return uuid.Nil, "", uuid.Nil, false
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get comment", err.Error())
+		return uuid.Nil, "", uuid.Nil, false
+	}
+
+	hasAccess, err := h.hasDocumentAccess(&comment.Document, userID.(uuid.UUID))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to check document access", err.Error())
+		return uuid.Nil, "", uuid.Nil, false
+	}
+	if !hasAccess {
+		utils.ForbiddenResponse(c, "ACCESS_DENIED", "Access denied")
+		return uuid.Nil, "", uuid.Nil, false
+	}
+
+	return commentID, emoji, userID.(uuid.UUID), true
+}
+
+// Resolves a selected set of comments, possibly spanning several documents
+// @Summary Resolve several comments
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body validations.BulkCommentRequest true "Comment IDs"
+// @Success 200 {object} utils.ApiResponse{data=handlers.BulkCommentResponse}
+// @Success 206 {object} utils.ApiResponse{data=handlers.BulkCommentResponse} "Some comments could not be changed"
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_FAILED, ALL_RESOLVES_FAILED"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Security BearerAuth
+// @Router /api/v1/comments/bulk/resolve [post]
+func (h *CommentHandler) BulkResolveComments(c *gin.Context) {
+	h.bulkSetResolved(c, true)
+}
+
+// Reopens a selected set of comments, possibly spanning several documents
+// @Summary Reopen several comments
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param request body validations.BulkCommentRequest true "Comment IDs"
+// @Success 200 {object} utils.ApiResponse{data=handlers.BulkCommentResponse}
+// @Success 206 {object} utils.ApiResponse{data=handlers.BulkCommentResponse} "Some comments could not be changed"
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_FAILED, ALL_UNRESOLVES_FAILED"
+// @Failure 401 {object} utils.ApiResponse "USER_NOT_AUTHENTICATED"
+// @Security BearerAuth
+// @Router /api/v1/comments/bulk/unresolve [post]
+func (h *CommentHandler) BulkUnresolveComments(c *gin.Context) {
+	h.bulkSetResolved(c, false)
+}
+
+// bulkSetResolved checks every requested comment concurrently, then applies
+// the resolve state to all permitted comments in a single transaction.
+func (h *CommentHandler) bulkSetResolved(c *gin.Context, resolve bool) {
+	req, ok := validations.GetValidatedBulkComment(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_FAILED", "Failed to get validated request")
+		return
+	}
+
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.UnauthorizedResponse(c, "USER_NOT_AUTHENTICATED", "User not authenticated")
+		return
+	}
+	currentUserID := userID.(uuid.UUID)
+
+	action := "resolve"
+	if !resolve {
+		action = "unresolve"
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Minute)
+	defer cancel()
+
+	type checkResult struct {
+		commentID string
+		comment   *models.DocumentComment
+		err       error
+	}
+
+	resultChan := make(chan checkResult, len(req.CommentIDs))
+	semaphore := make(chan struct{}, 10) // Limit concurrent lookups to 10
+	var wg sync.WaitGroup
+
+	for _, commentID := range req.CommentIDs {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			var comment models.DocumentComment
+			if err := h.db.WithContext(ctx).Preload("Document").Where("id = ?", id).First(&comment).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					resultChan <- checkResult{commentID: id, err: fmt.Errorf("comment not found")}
+					return
+				}
+				resultChan <- checkResult{commentID: id, err: fmt.Errorf("failed to get comment")}
+				return
+			}
+
+			if !comment.CanResolve(currentUserID, comment.Document.UserID) {
+				resultChan <- checkResult{commentID: id, err: fmt.Errorf("not allowed to %s this comment", action)}
+				return
+			}
+
+			resultChan <- checkResult{commentID: id, comment: &comment}
+		}(commentID)
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	results := make(map[string]error, len(req.CommentIDs))
+	var changed []*models.DocumentComment
+	for result := range resultChan {
+		results[result.commentID] = result.err
+		if result.err != nil {
+			continue
+		}
+		// Comments already in the requested state are reported as successful
+		// but neither rewritten nor logged again.
+		if result.comment.IsResolved != resolve {
+			changed = append(changed, result.comment)
+		}
+	}
+
+	if len(changed) > 0 {
+		txErr := h.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			for _, comment := range changed {
+				if resolve {
+					comment.Resolve(currentUserID)
+				} else {
+					comment.Unresolve()
+				}
+
+				if err := tx.Model(&models.DocumentComment{}).Where("id = ?", comment.ID).Updates(map[string]interface{}{
+					"is_resolved": comment.IsResolved,
+					"resolved_by": comment.ResolvedBy,
+					"resolved_at": comment.ResolvedAt,
+				}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if txErr != nil {
+			utils.RequestLogger(c).WithError(txErr).Errorf("Failed to bulk %s comments", action)
+			for _, comment := range changed {
+				results[comment.ID.String()] = fmt.Errorf("failed to %s comment", action)
+			}
+			changed = nil
+		}
+	}
+
+	// Log activities per affected comment once the transaction has committed
+	for _, comment := range changed {
+		activityCtx := h.activityService.CreateActivityContext(c, comment.DocumentID)
+		var logErr error
+		if resolve {
+			logErr = h.activityService.LogResolveComment(activityCtx, &comment.Document, comment)
+		} else {
+			logErr = h.activityService.LogUnresolveComment(activityCtx, &comment.Document, comment)
+		}
+		if logErr != nil {
+			utils.RequestLogger(c).WithError(logErr).WithField("comment_id", comment.ID).Warnf("Failed to log %s activity", action)
+		}
+	}
+
+	// Build per-item results in request order
+	succeeded := 0
+	items := make([]map[string]interface{}, 0, len(req.CommentIDs))
+	for _, commentID := range req.CommentIDs {
+		item := map[string]interface{}{
+			"id":      commentID,
+			"success": results[commentID] == nil,
+		}
+		if err := results[commentID]; err != nil {
+			item["error"] = err.Error()
+		} else {
+			succeeded++
+		}
+		items = append(items, item)
+	}
+
+	failed := len(req.CommentIDs) - succeeded
+	response := gin.H{
+		"successful":     succeeded,
+		"failed":         failed,
+		"total_comments": len(req.CommentIDs),
+		"results":        items,
+	}
+
+	if succeeded == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ALL_"+strings.ToUpper(action)+"S_FAILED",
+			fmt.Sprintf("Failed to %s all comments", action), items[0]["error"].(string))
+		return
+	} else if failed > 0 {
+		utils.SuccessResponse(c, http.StatusPartialContent, response,
+			fmt.Sprintf("Processed %d out of %d comments successfully", succeeded, len(req.CommentIDs)))
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response,
+		fmt.Sprintf("All %d comments %sd successfully", len(req.CommentIDs), action))
 }
 
 // Helper function to transform comment to response
@@ -461,6 +1252,8 @@ func (h *CommentHandler) transformCommentToResponse(comment models.DocumentComme
 		IsResolved:      comment.IsResolved,
 		ResolvedBy:      comment.ResolvedBy,
 		IsEdited:        comment.IsEdited,
+		Mentions:        []uuid.UUID{},
+		Reactions:       []ReactionSummary{},
 		User: UserResponse{
 			ID:       comment.User.ID,
 			Username: comment.User.Username,
@@ -472,11 +1265,9 @@ func (h *CommentHandler) transformCommentToResponse(comment models.DocumentComme
 		UpdatedAt:  comment.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
-	// Set avatar URL if avatar path exists
-	if comment.User.Avatar != "" {
-		if avatarURL, err := h.authService.GetAvatarURL(context.Background(), comment.User.Avatar); err == nil && avatarURL != "" {
-			response.User.Avatar = &avatarURL
-		}
+	// Uploaded avatar, or a generated one for users without
+	if avatarURL, err := h.authService.GetAvatarURL(context.Background(), &comment.User); err == nil && avatarURL != "" {
+		response.User.Avatar = &avatarURL
 	}
 
 	if comment.ResolvedAt != nil {
@@ -484,6 +1275,10 @@ func (h *CommentHandler) transformCommentToResponse(comment models.DocumentComme
 		response.ResolvedAt = &resolvedAt
 	}
 
+	if len(comment.Mentions) > 0 {
+		response.Mentions = comment.Mentions
+	}
+
 	if comment.EditedAt != nil {
 		editedAt := comment.EditedAt.Format("2006-01-02T15:04:05Z07:00")
 		response.EditedAt = &editedAt
@@ -499,3 +1294,481 @@ func (h *CommentHandler) transformCommentToResponse(comment models.DocumentComme
 
 	return response
 }
+
+// Loads the latest replies of each comment with two queries for the whole page instead of
+// one per thread. Replies are attached oldest first, the returned map holds the total reply
+// count per comment.
+func (h *CommentHandler) attachLatestReplies(comments []models.DocumentComment) (map[uuid.UUID]int, error) {
+	counts := make(map[uuid.UUID]int, len(comments))
+	if len(comments) == 0 {
+		return counts, nil
+	}
+
+	parentIDs := make([]uuid.UUID, len(comments))
+	for i, comment := range comments {
+		parentIDs[i] = comment.ID
+	}
+
+	var rows []struct {
+		ParentCommentID uuid.UUID
+		Count           int
+	}
+	if err := h.db.Model(&models.DocumentComment{}).
+		Select("parent_comment_id, COUNT(*) AS count").
+		Where("parent_comment_id IN ?", parentIDs).
+		Group("parent_comment_id").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.ParentCommentID] = row.Count
+	}
+
+	ranked := h.db.Model(&models.DocumentComment{}).
+		Select("id, ROW_NUMBER() OVER (PARTITION BY parent_comment_id ORDER BY created_at DESC) AS reply_rank").
+		Where("parent_comment_id IN ?", parentIDs)
+
+	var replies []models.DocumentComment
+	if err := h.db.Where("id IN (?)", h.db.Table("(?) AS ranked", ranked).Select("id").Where("reply_rank <= ?", latestRepliesPerComment)).
+		Preload("User").
+		Order("created_at ASC").
+		Find(&replies).Error; err != nil {
+		return nil, err
+	}
+
+	byParent := make(map[uuid.UUID][]models.DocumentComment, len(comments))
+	for _, reply := range replies {
+		byParent[*reply.ParentCommentID] = append(byParent[*reply.ParentCommentID], reply)
+	}
+	for i := range comments {
+		comments[i].Replies = byParent[comments[i].ID]
+	}
+
+	return counts, nil
+}
+
+// Fills in the reactions of the comments and their embedded replies
+func (h *CommentHandler) attachReactions(userID uuid.UUID, responses []CommentResponse) error {
+	var commentIDs []uuid.UUID
+	var collect func(responses []CommentResponse)
+	collect = func(responses []CommentResponse) {
+		for _, response := range responses {
+			commentIDs = append(commentIDs, response.ID)
+			collect(response.Replies)
+		}
+	}
+	collect(responses)
+
+	reactions, err := h.loadReactions(userID, commentIDs)
+	if err != nil {
+		return err
+	}
+
+	var fill func(responses []CommentResponse)
+	fill = func(responses []CommentResponse) {
+		for i := range responses {
+			if summary, ok := reactions[responses[i].ID]; ok {
+				responses[i].Reactions = summary
+			}
+			fill(responses[i].Replies)
+		}
+	}
+	fill(responses)
+
+	return nil
+}
+
+// Counts the reactions of the comments per emoji with one query, the summaries follow the order
+// of models.CommentReactionEmojis. Comments without reactions get an empty list.
+func (h *CommentHandler) loadReactions(userID uuid.UUID, commentIDs []uuid.UUID) (map[uuid.UUID][]ReactionSummary, error) {
+	reactions := make(map[uuid.UUID][]ReactionSummary, len(commentIDs))
+	if len(commentIDs) == 0 {
+		return reactions, nil
+	}
+
+	var rows []struct {
+		CommentID uuid.UUID
+		Emoji     string
+		Count     int
+		Reacted   bool
+	}
+	if err := h.db.Model(&models.CommentReaction{}).
+		Select("comment_id, emoji, COUNT(*) AS count, BOOL_OR(user_id = ?) AS reacted", userID).
+		Where("comment_id IN ?", commentIDs).
+		Group("comment_id, emoji").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uuid.UUID]map[string]ReactionSummary, len(commentIDs))
+	for _, row := range rows {
+		if counts[row.CommentID] == nil {
+			counts[row.CommentID] = make(map[string]ReactionSummary)
+		}
+		counts[row.CommentID][row.Emoji] = ReactionSummary{Emoji: row.Emoji, Count: row.Count, Reacted: row.Reacted}
+	}
+
+	for _, commentID := range commentIDs {
+		summary := []ReactionSummary{}
+		for _, emoji := range models.CommentReactionEmojis {
+			if reaction, ok := counts[commentID][emoji]; ok {
+				summary = append(summary, reaction)
+			}
+		}
+		reactions[commentID] = summary
+	}
+
+	return reactions, nil
+}
+
+// Reports whether the user can open the document: they own it, it is public, or it is shared
+// with them directly or through one of their groups
+func (h *CommentHandler) hasDocumentAccess(document *models.Document, userID uuid.UUID) (bool, error) {
+	if document.UserID == userID || document.IsPublic {
+		return true, nil
+	}
+
+	now := time.Now()
+	var shares int64
+	if err := h.db.Model(&models.UserShare{}).
+		Where("document_id = ? AND shared_with_user_id = ? AND is_revoked = false", document.ID, userID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Count(&shares).Error; err != nil {
+		return false, err
+	}
+	if shares > 0 {
+		return true, nil
+	}
+
+	if err := h.db.Model(&models.GroupShare{}).
+		Joins("JOIN group_members ON group_members.group_id = group_shares.group_id").
+		Where("group_shares.document_id = ? AND group_members.user_id = ? AND group_shares.is_revoked = false", document.ID, userID).
+		Where("group_shares.expires_at IS NULL OR group_shares.expires_at > ?", now).
+		Count(&shares).Error; err != nil {
+		return false, err
+	}
+	return shares > 0, nil
+}
+
+// Returns why the comment can't be added to its document, empty when it is within the limits
+func (h *CommentHandler) commentLimitMessage(tx *gorm.DB, comment *models.DocumentComment) (string, error) {
+	if h.limits.MaxPerDocument > 0 {
+		var total int64
+		if err := tx.Model(&models.DocumentComment{}).Where("document_id = ?", comment.DocumentID).Count(&total).Error; err != nil {
+			return "", fmt.Errorf("failed to count comments: %w", err)
+		}
+		if total >= int64(h.limits.MaxPerDocument) {
+			return fmt.Sprintf("This document has reached the limit of %d comments", h.limits.MaxPerDocument), nil
+		}
+	}
+
+	if h.limits.MaxAnnotationsPerPage > 0 && comment.CommentType == models.CommentTypeAnnotation &&
+		comment.Position != nil && comment.Position.Page != nil {
+		var onPage int64
+		if err := tx.Model(&models.DocumentComment{}).
+			Where("document_id = ? AND comment_type = ? AND pos_page = ?", comment.DocumentID, models.CommentTypeAnnotation, *comment.Position.Page).
+			Count(&onPage).Error; err != nil {
+			return "", fmt.Errorf("failed to count annotations: %w", err)
+		}
+		if onPage >= int64(h.limits.MaxAnnotationsPerPage) {
+			return fmt.Sprintf("Page %d has reached the limit of %d annotations", *comment.Position.Page, h.limits.MaxAnnotationsPerPage), nil
+		}
+	}
+
+	return "", nil
+}
+
+// Resolves the @username mentions in content to users who can open the document. Unknown
+// usernames, users without access and the author are left out.
+func (h *CommentHandler) resolveMentions(document *models.Document, authorID uuid.UUID, content string) (models.CommentMentions, error) {
+	mentions := models.CommentMentions{}
+
+	usernames := utils.ExtractMentions(content)
+	if len(usernames) == 0 {
+		return mentions, nil
+	}
+
+	query := h.db.Model(&models.User{}).
+		Select("id", "username").
+		Where("username IN ? AND id <> ?", usernames, authorID)
+	if !document.IsPublic {
+		sharedWith := h.db.Model(&models.UserShare{}).
+			Select("shared_with_user_id").
+			Where("document_id = ? AND is_revoked = false AND (expires_at IS NULL OR expires_at > ?)", document.ID, time.Now())
+		query = query.Where("id = ? OR id IN (?)", document.UserID, sharedWith)
+	}
+
+	var users []models.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	userIDs := make(map[string]uuid.UUID, len(users))
+	for _, user := range users {
+		userIDs[user.Username] = user.ID
+	}
+
+	// Keep the order the users were mentioned in
+	for _, username := range usernames {
+		if id, ok := userIDs[username]; ok {
+			mentions = append(mentions, id)
+		}
+	}
+	return mentions, nil
+}
+
+// Sends a mention notification to every user the comment mentions. Failures are logged,
+// the comment itself is already saved.
+func (h *CommentHandler) notifyMentions(c *gin.Context, document *models.Document, comment *models.DocumentComment) {
+	if len(comment.Mentions) == 0 {
+		return
+	}
+
+	metadata, _ := json.Marshal(map[string]string{"commentID": comment.ID.String()})
+
+	author := comment.User.Name
+	if author == "" {
+		author = comment.User.Username
+	}
+
+	for _, mentionedID := range comment.Mentions {
+		notification := models.ShareNotification{
+			Type:       "comment_mention",
+			Title:      "You were mentioned in a comment",
+			Message:    fmt.Sprintf("%s mentioned you in a comment on '%s'", author, document.Title),
+			DocumentID: document.ID,
+			FromUserID: comment.UserID,
+			ToUserID:   mentionedID,
+			Metadata:   string(metadata),
+		}
+		if err := h.db.Create(&notification).Error; err != nil {
+			utils.RequestLogger(c).WithError(err).WithFields(logrus.Fields{
+				"comment_id":        comment.ID,
+				"mentioned_user_id": mentionedID,
+			}).Warn("Failed to create mention notification")
+		}
+	}
+}
+
+// Tells the author of the parent comment about a reply, unless they wrote it, were already
+// notified of a mention in it or can no longer open the document. Failures are logged, the
+// reply itself is already saved.
+func (h *CommentHandler) notifyReply(c *gin.Context, document *models.Document, parent, reply *models.DocumentComment) {
+	if parent.UserID == reply.UserID || slices.Contains(reply.Mentions, parent.UserID) {
+		return
+	}
+
+	logger := utils.RequestLogger(c).WithFields(logrus.Fields{
+		"comment_id":       reply.ID,
+		"parent_author_id": parent.UserID,
+	})
+
+	canOpen, err := h.canOpenDocument(document, parent.UserID)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to check document access for reply notification")
+		return
+	}
+	if !canOpen {
+		return
+	}
+
+	metadata, _ := json.Marshal(map[string]string{
+		"commentID":       reply.ID.String(),
+		"parentCommentID": parent.ID.String(),
+	})
+
+	author := reply.User.Name
+	if author == "" {
+		author = reply.User.Username
+	}
+
+	snippet := strings.Join(strings.Fields(reply.Content), " ")
+	if truncated := utils.TruncateUTF8(snippet, replySnippetMaxBytes); truncated != snippet {
+		snippet = truncated + "…"
+	}
+
+	notification := models.ShareNotification{
+		Type:       "comment_reply",
+		Title:      "New reply to your comment",
+		Message:    fmt.Sprintf("%s replied to your comment on '%s': %s", author, document.Title, snippet),
+		DocumentID: document.ID,
+		FromUserID: reply.UserID,
+		ToUserID:   parent.UserID,
+		Metadata:   string(metadata),
+	}
+	if err := h.db.Create(&notification).Error; err != nil {
+		logger.WithError(err).Warn("Failed to create reply notification")
+	}
+}
+
+// Reports whether the user can open the document, with the same rules mentions follow
+func (h *CommentHandler) canOpenDocument(document *models.Document, userID uuid.UUID) (bool, error) {
+	if document.IsPublic || document.UserID == userID {
+		return true, nil
+	}
+
+	var count int64
+	err := h.db.Model(&models.UserShare{}).
+		Where("document_id = ? AND shared_with_user_id = ? AND is_revoked = false AND (expires_at IS NULL OR expires_at > ?)", document.ID, userID, time.Now()).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Writes one row per annotation, position fields are empty when not set
+func annotationsToCSV(annotations []AnnotationExport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"id", "author", "username", "content", "quotedText", "page", "x", "y", "width", "height",
+		"textStart", "textEnd", "resolved", "resolvedAt", "createdAt"}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, annotation := range annotations {
+		position := annotation.Position
+		if position == nil {
+			position = &models.CommentPosition{}
+		}
+		resolvedAt := ""
+		if annotation.ResolvedAt != nil {
+			resolvedAt = annotation.ResolvedAt.UTC().Format(time.RFC3339)
+		}
+		quotedText := ""
+		if position.QuotedText != nil {
+			quotedText = *position.QuotedText
+		}
+
+		row := []string{
+			annotation.ID.String(),
+			annotation.Author.Name,
+			annotation.Author.Username,
+			annotation.Content,
+			quotedText,
+			formatOptionalInt(position.Page),
+			formatOptionalFloat(position.X),
+			formatOptionalFloat(position.Y),
+			formatOptionalFloat(position.Width),
+			formatOptionalFloat(position.Height),
+			formatOptionalInt(position.TextStart),
+			formatOptionalInt(position.TextEnd),
+			strconv.FormatBool(annotation.IsResolved),
+			resolvedAt,
+			annotation.CreatedAt.UTC().Format(time.RFC3339),
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+type xfdfDocument struct {
+	XMLName xml.Name         `xml:"xfdf"`
+	Xmlns   string           `xml:"xmlns,attr"`
+	Space   string           `xml:"xml:space,attr"`
+	File    xfdfFile         `xml:"f"`
+	Annots  []xfdfAnnotation `xml:"annots>any"`
+}
+
+type xfdfFile struct {
+	Href string `xml:"href,attr"`
+}
+
+type xfdfAnnotation struct {
+	XMLName  xml.Name
+	Name     string `xml:"name,attr"`
+	Page     int    `xml:"page,attr"`
+	Rect     string `xml:"rect,attr"`
+	Title    string `xml:"title,attr"`
+	Subject  string `xml:"subject,attr,omitempty"`
+	Date     string `xml:"date,attr"`
+	Contents string `xml:"contents"`
+}
+
+// Converts annotations placed on a page to XFDF. Positions are stored as percentages from
+// the top left corner, XFDF uses points from the bottom left. Point annotations become
+// sticky notes and areas become squares, annotations without a page are left out.
+func annotationsToXFDF(annotations []AnnotationExport, fileName string, pageWidth, pageHeight float64) ([]byte, error) {
+	document := xfdfDocument{
+		Xmlns: "http://ns.adobe.com/xfdf/",
+		Space: "preserve",
+		File:  xfdfFile{Href: fileName},
+	}
+
+	for _, annotation := range annotations {
+		position := annotation.Position
+		if position == nil || position.Page == nil || position.X == nil || position.Y == nil {
+			continue
+		}
+
+		left := *position.X / 100 * pageWidth
+		top := pageHeight - *position.Y/100*pageHeight
+		width, height := 0.0, 0.0
+		if position.Width != nil {
+			width = *position.Width / 100 * pageWidth
+		}
+		if position.Height != nil {
+			height = *position.Height / 100 * pageHeight
+		}
+
+		kind := "square"
+		if width <= 0 || height <= 0 {
+			// Sticky notes get the usual icon size
+			kind, width, height = "text", 20, 20
+		}
+
+		subject := ""
+		if annotation.IsResolved {
+			subject = "Resolved"
+		}
+		contents := annotation.Content
+		if position.QuotedText != nil && *position.QuotedText != "" {
+			contents = fmt.Sprintf("\"%s\"\n\n%s", *position.QuotedText, annotation.Content)
+		}
+
+		document.Annots = append(document.Annots, xfdfAnnotation{
+			XMLName:  xml.Name{Local: kind},
+			Name:     annotation.ID.String(),
+			Page:     *position.Page - 1,
+			Rect:     fmt.Sprintf("%.2f,%.2f,%.2f,%.2f", left, top-height, left+width, top),
+			Title:    annotation.Author.Name,
+			Subject:  subject,
+			Date:     annotation.CreatedAt.UTC().Format("D:20060102150405Z"),
+			Contents: contents,
+		})
+	}
+
+	data, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+func parsePageSize(raw string, fallback float64) (float64, error) {
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid page size")
+	}
+	return value, nil
+}
+
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
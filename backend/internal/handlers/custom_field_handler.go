@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CustomFieldHandler struct {
+	customFieldService *services.CustomFieldService
+}
+
+func NewCustomFieldHandler(customFieldService *services.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		customFieldService: customFieldService,
+	}
+}
+
+// Returns the custom metadata schema so clients can render document forms
+func (h *CustomFieldHandler) GetCustomFields(c *gin.Context) {
+	fields, err := h.customFieldService.ListFields(c.Request.Context())
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch custom fields", err.Error())
+		return
+	}
+
+	data := gin.H{
+		"fields": fields,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Custom fields retrieved successfully")
+}
+
+func (h *CustomFieldHandler) CreateCustomField(c *gin.Context) {
+	var req services.CreateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", err.Error())
+		return
+	}
+
+	field, err := h.customFieldService.CreateField(c.Request.Context(), &req)
+	if err != nil {
+		if err.Error() == "custom field name already exists" {
+			utils.ConflictResponse(c, "CUSTOM_FIELD_EXISTS", err.Error())
+		} else {
+			utils.ErrorResponse(c, http.StatusBadRequest, "CREATION_FAILED", err.Error())
+		}
+		return
+	}
+
+	data := gin.H{
+		"field": field,
+	}
+	utils.SuccessResponse(c, http.StatusCreated, data, "Custom field created successfully")
+}
+
+func (h *CustomFieldHandler) UpdateCustomField(c *gin.Context) {
+	fieldID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid custom field ID format")
+		return
+	}
+
+	var req services.UpdateCustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", err.Error())
+		return
+	}
+
+	field, err := h.customFieldService.UpdateField(c.Request.Context(), fieldID, &req)
+	if err != nil {
+		if err.Error() == "custom field not found" {
+			utils.NotFoundResponse(c, "CUSTOM_FIELD_NOT_FOUND", err.Error())
+		} else {
+			utils.ErrorResponse(c, http.StatusBadRequest, "UPDATE_FAILED", err.Error())
+		}
+		return
+	}
+
+	data := gin.H{
+		"field": field,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Custom field updated successfully")
+}
+
+func (h *CustomFieldHandler) DeleteCustomField(c *gin.Context) {
+	fieldID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid custom field ID format")
+		return
+	}
+
+	if err := h.customFieldService.DeleteField(c.Request.Context(), fieldID); err != nil {
+		if err.Error() == "custom field not found" {
+			utils.NotFoundResponse(c, "CUSTOM_FIELD_NOT_FOUND", err.Error())
+		} else {
+			utils.ErrorResponse(c, http.StatusBadRequest, "DELETION_FAILED", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Custom field deleted successfully")
+}
@@ -4,6 +4,8 @@ import (
 	"archive/zip"
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -14,6 +16,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/metrics"
 	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/queue"
@@ -26,27 +30,55 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// Cache lifetime of preview redirects to URLs that do not expire
+	previewRedirectMaxAge = 50 * time.Minute
+	// Cached redirects expire this long before the signed URL they point at
+	previewRedirectMargin = 10 * time.Minute
+
+	// Bounds for the ?expiresIn= lifetime of preview URLs
+	previewMinURLExpiry = time.Minute
+	previewMaxURLExpiry = 24 * time.Hour
+)
+
 type DocumentHandler struct {
 	documentService       *services.DocumentService
-	minioService          *services.MinIOService
+	minioService          services.Uploader
 	userShareService      *services.UserShareService
 	processingTaskService *services.ProcessingTaskService
 	queuePublisher        *queue.Publisher
+	bulk                  config.BulkConfig
 }
 
 // Represents the result of a document download operation
 type documentResult struct {
-	document *models.Document
-	content  []byte
-	error    error
+	documentID string
+	document   *models.Document
+	content    []byte
+	error      error
+}
+
+// Describes an item of a bulk request that failed. Documents are identified by ID, uploaded
+// files by name. Code and Status are what the single document endpoint would have answered.
+type BulkFailure struct {
+	ID          string `json:"id,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	Code        string `json:"code"`
+	Status      int    `json:"status"`
+	Error       string `json:"error"`
+	DuplicateOf string `json:"duplicateOf,omitempty"` // Set for DUPLICATE_DOCUMENT
 }
 
+// Name of the entry listing the documents left out of a bulk download ZIP
+const bulkDownloadFailuresEntry = "_failures.json"
+
 func NewDocumentHandler(
 	documentService *services.DocumentService,
-	minioService *services.MinIOService,
+	minioService services.Uploader,
 	userShareService *services.UserShareService,
 	processingTaskService *services.ProcessingTaskService,
 	queuePublisher *queue.Publisher,
+	bulk config.BulkConfig,
 ) *DocumentHandler {
 	return &DocumentHandler{
 		documentService:       documentService,
@@ -54,10 +86,36 @@ func NewDocumentHandler(
 		userShareService:      userShareService,
 		processingTaskService: processingTaskService,
 		queuePublisher:        queuePublisher,
+		bulk:                  bulk,
 	}
 }
 
 // Handles single document upload
+// @Summary Upload a document
+// @Tags documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param Idempotency-Key header string false "Retries with the same key within UPLOAD_IDEMPOTENCY_TTL get the first response back"
+// @Param file formData file true "Document file"
+// @Param title formData string true "Title"
+// @Param description formData string false "Description"
+// @Param tags formData string false "Comma separated tags"
+// @Param isPublic formData bool false "Visible to everyone"
+// @Param language formData string false "Document language, defaults to english"
+// @Param customMetadata formData string false "JSON object of custom field values"
+// @Param allowDuplicate formData bool false "Store the file even if you already uploaded the same content"
+// @Param runOcr formData bool false "OCR the pages of a PDF without a text layer so scans become searchable"
+// @Param ocrLanguage formData string false "Tesseract languages to OCR in, e.g. deu+eng, defaults to the document language"
+// @Param expiresAt formData string false "RFC 3339 time in the future after which the document is removed"
+// @Success 201 {object} utils.ApiResponse{data=handlers.DocumentEnvelope}
+// @Failure 400 {object} utils.ApiResponse "FILE_REQUIRED, VALIDATION_ERROR, INVALID_FILE_TYPE, FILE_TOO_LARGE, INVALID_CUSTOM_METADATA, OCR_UNAVAILABLE, INVALID_IDEMPOTENCY_KEY"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 409 {object} utils.ApiResponse{data=handlers.DuplicateDocumentResponse} "DUPLICATE_DOCUMENT, IDEMPOTENCY_KEY_IN_USE"
+// @Failure 422 {object} utils.ApiResponse "IDEMPOTENCY_KEY_REUSED"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Failure 500 {object} utils.ApiResponse "STORAGE_ERROR, DATABASE_ERROR, INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/upload [post]
 func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 	fmt.Println("1...")
 
@@ -82,37 +140,58 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 	}
 
 	uploadReq := &types.UploadDocumentRequest{
-		Title:       req.Title,
-		Description: req.Description,
-		Tags:        req.Tags,
-		IsPublic:    req.IsPublic,
+		Title:          req.Title,
+		Description:    req.Description,
+		Tags:           req.Tags,
+		IsPublic:       req.IsPublic,
+		Language:       req.Language,
+		CustomMetadata: req.CustomMetadata,
+		AllowDuplicate: req.AllowDuplicate,
+		RunOCR:         req.RunOCR,
+		OCRLanguage:    req.OCRLanguage,
+		ExpiresAt:      req.ExpiresAt,
 	}
 
 	// Delegate business logic to service
 	document, err := h.documentService.UploadDocument(c.Request.Context(), userID, file, uploadReq)
 	if err != nil {
+		var duplicateErr *services.DuplicateDocumentError
+		if errors.As(err, &duplicateErr) {
+			existing := duplicateErr.Existing
+			utils.ErrorResponseWithData(c, http.StatusConflict, "DUPLICATE_DOCUMENT",
+				"You already uploaded this file, send allowDuplicate=true to upload it anyway",
+				gin.H{"duplicate": types.DuplicateDocument{
+					DocumentID: existing.ID,
+					Title:      existing.Title,
+					CreatedAt:  existing.CreatedAt,
+				}})
+			return
+		}
+
 		// Map service errors to HTTP status codes
 		status, code := h.mapServiceErrorToHTTP(err)
 		utils.ErrorResponse(c, status, code, err.Error())
 		return
 	}
 
+	logger := utils.RequestLogger(c).WithField("document_id", document.ID)
+
 	// Create processing tasks for the document
 	if err := h.processingTaskService.CreateProcessingTasks(document.ID); err != nil {
-		logrus.Errorf("Failed to create processing tasks for document %s: %v", document.ID.String(), err)
+		logger.WithError(err).Error("Failed to create processing tasks")
 	} else {
-		logrus.Infof("Created processing tasks for document %s", document.ID.String())
+		logger.Info("Created processing tasks")
 	}
 
 	if h.queuePublisher != nil {
-		logrus.Infof("Publishing document %s to processing queue with storage path", document.ID.String())
-		if err := h.queuePublisher.PublishDocumentForProcessing(document.ID.String(), document.StoragePath); err != nil {
-			logrus.Errorf("Failed to queue document for processing: %v", err)
+		logger.Info("Publishing document to processing queue")
+		if err := h.queuePublisher.PublishDocumentForProcessing(document.ID.String(), document.StoragePath, queue.DocumentMessageKey(document.ID.String(), document.StoragePath)); err != nil {
+			logger.WithError(err).Error("Failed to queue document for processing")
 		} else {
-			logrus.Infof("Successfully queued document %s for processing", document.ID.String())
+			logger.Info("Queued document for processing")
 		}
 	} else {
-		logrus.Warn("Queue publisher is nil, skipping document processing")
+		logger.Warn("Queue publisher is nil, skipping document processing")
 	}
 
 	data := gin.H{
@@ -122,6 +201,28 @@ func (h *DocumentHandler) UploadDocument(c *gin.Context) {
 }
 
 // Handles document updates
+// @Summary Update a document and optionally replace its file
+// @Tags documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param title formData string true "Title"
+// @Param description formData string false "Description"
+// @Param tags formData string false "Comma separated tags"
+// @Param isPublic formData bool false "Visible to everyone"
+// @Param hasNewFile formData bool false "Replace the stored file"
+// @Param file formData file false "Replacement file, required when hasNewFile is true"
+// @Param customMetadata formData string false "JSON object of custom field values"
+// @Param expiresAt formData string false "RFC 3339 time in the future, empty removes the expiration. Owner only"
+// @Success 200 {object} utils.ApiResponse{data=handlers.DocumentEnvelope}
+// @Failure 400 {object} utils.ApiResponse "FILE_REQUIRED, VALIDATION_ERROR, INVALID_FILE_TYPE, FILE_TOO_LARGE, INVALID_CUSTOM_METADATA"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "STORAGE_ERROR, DATABASE_ERROR, INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id} [put]
 func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -155,11 +256,14 @@ func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 
 	// Convert fts.UpdateDocumentRequest to services.UpdateDocumentRequest
 	updateReq := &types.UpdateDocumentRequest{
-		Title:       req.Title,
-		Description: req.Description,
-		Tags:        req.Tags,
-		IsPublic:    req.IsPublic,
-		HasNewFile:  req.HasNewFile,
+		Title:          req.Title,
+		Description:    req.Description,
+		Tags:           req.Tags,
+		IsPublic:       req.IsPublic,
+		HasNewFile:     req.HasNewFile,
+		CustomMetadata: req.CustomMetadata,
+		ExpiresAt:      req.ExpiresAt,
+		ClearExpiresAt: req.ClearExpiresAt,
 	}
 
 	// Delegate to service
@@ -177,6 +281,34 @@ func (h *DocumentHandler) UpdateDocument(c *gin.Context) {
 }
 
 // Handles document listing with search
+// @Summary List and search documents
+// @Description Pages are numbered by default, which also reports the total count and is needed for searching and sorting by other fields.
+// @Description Passing cursor, empty for the first page, switches to cursor mode: documents are sorted by date, each page returns the nextCursor to pass for the following one and totals are not computed.
+// @Description Cursor mode stays fast on deep pages and does not skip or repeat documents while the library changes, so prefer it for scrolling through large libraries.
+// @Tags documents
+// @Produce json
+// @Param page query int false "Page number, not combined with cursor" default(1)
+// @Param cursor query string false "Opaque cursor from the previous page's nextCursor, empty for the first page"
+// @Param folderId query string false "Folder ID, or root for documents not filed in a folder"
+// @Param includeSubfolders query bool false "Also list documents in the folders below folderId"
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Param search query string false "Full text search query. Supports quoted phrases, title: and tag: prefixes, OR, NOT and -term"
+// @Param fileType query string false "File type" Enums(pdf, docx, txt, xlsx, pptx, other)
+// @Param status query string false "Processing status" Enums(processing, ready, failed, deleted)
+// @Param tags query string false "Comma separated tags"
+// @Param language query string false "Document language"
+// @Param sortBy query string false "Sort field" Enums(relevance, date, size, views, downloads, title) default(date)
+// @Param sortDir query string false "Sort direction" Enums(asc, desc) default(desc)
+// @Param debug query bool false "Include which search strategy answered and how many candidates each one matched. Admins only in production, ignored in cursor mode"
+// @Param Cache-Control header string false "no-cache skips results cached for SEARCH_CACHE_TTL"
+// @Success 200 {object} utils.ApiResponse{data=types.DocumentListResponse}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR, INVALID_CUSTOM_METADATA"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "SEARCH_DEBUG_FORBIDDEN"
+// @Failure 404 {object} utils.ApiResponse "FOLDER_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents [get]
 func (h *DocumentHandler) GetDocuments(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -191,20 +323,44 @@ func (h *DocumentHandler) GetDocuments(c *gin.Context) {
 		return
 	}
 
+	// Strategy details are diagnostic, production only shows them to admins
+	if req.Debug && gin.Mode() == gin.ReleaseMode && c.GetString("roleName") != "admin" {
+		utils.ForbiddenResponse(c, "SEARCH_DEBUG_FORBIDDEN", "Search debugging is limited to administrators")
+		return
+	}
+
 	listReq := &types.DocumentListRequest{
-		Page:     req.Page,
-		Limit:    req.Limit,
-		Search:   req.Search,
-		FileType: req.FileType,
-		Status:   req.Status,
-		Tags:     req.Tags,
-		SortBy:   req.SortBy,
-		SortDir:  req.SortDir,
+		Page:         req.Page,
+		Limit:        req.Limit,
+		Search:       req.Search,
+		FileType:     req.FileType,
+		Status:       req.Status,
+		Tags:         req.Tags,
+		Language:     req.Language,
+		CustomFields: req.CustomFields,
+		SortBy:       req.SortBy,
+		SortDir:      req.SortDir,
+		UseCursor:    req.UseCursor,
+		Cursor:       req.Cursor,
+
+		FolderID:          req.FolderID,
+		Unfiled:           req.Unfiled,
+		IncludeSubfolders: req.IncludeSubfolders,
+		NoCache:           strings.Contains(c.GetHeader("Cache-Control"), "no-cache"),
+		Debug:             req.Debug,
 	}
 
 	// Delegate to service (service handles search logic)
 	documents, err := h.documentService.GetDocuments(c.Request.Context(), userID, listReq)
 	if err != nil {
+		if strings.Contains(err.Error(), "invalid custom metadata") {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_CUSTOM_METADATA", err.Error())
+			return
+		}
+		if strings.Contains(err.Error(), "folder not found") {
+			utils.NotFoundResponse(c, "FOLDER_NOT_FOUND", "Folder not found")
+			return
+		}
 		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
 		return
 	}
@@ -213,6 +369,17 @@ func (h *DocumentHandler) GetDocuments(c *gin.Context) {
 }
 
 // Handles single document retrieval
+// @Summary Get a document
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.DocumentEnvelope}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id} [get]
 func (h *DocumentHandler) GetDocument(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -228,8 +395,11 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 	}
 
 	// Delegate to service
-	document, err := h.documentService.GetDocument(c.Request.Context(), userID, documentID)
+	document, err := h.documentService.GetDocument(c.Request.Context(), userID, documentID, c.ClientIP(), c.GetHeader("User-Agent"))
 	if err != nil {
+		if documentExpired(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "document not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
 			return
@@ -245,6 +415,17 @@ func (h *DocumentHandler) GetDocument(c *gin.Context) {
 }
 
 // Handles document title retrieval
+// @Summary Get the title of a document
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.DocumentTitleResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/title [get]
 func (h *DocumentHandler) GetDocumentTitle(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -262,6 +443,9 @@ func (h *DocumentHandler) GetDocumentTitle(c *gin.Context) {
 	// Delegate to service
 	title, err := h.documentService.GetDocumentTitle(c.Request.Context(), userID, documentID)
 	if err != nil {
+		if documentExpired(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "document not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
 			return
@@ -277,6 +461,17 @@ func (h *DocumentHandler) GetDocumentTitle(c *gin.Context) {
 }
 
 // Handles document deletion
+// @Summary Move a document to the trash
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "FORBIDDEN"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "DELETE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id} [delete]
 func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -302,28 +497,301 @@ func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, nil, "Document deleted successfully")
+	utils.SuccessResponse(c, http.StatusOK, nil, "Document moved to trash")
+}
+
+// Handles trash listing
+// @Summary List documents in the trash
+// @Tags documents
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} utils.ApiResponse{data=types.DocumentListResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/trash [get]
+func (h *DocumentHandler) GetTrash(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	documents, err := h.documentService.GetTrash(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, documents, "Trash retrieved successfully")
+}
+
+// Handles listing documents with unresolved comments awaiting the caller's review
+// @Summary List documents with unresolved comments
+// @Tags documents
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} utils.ApiResponse{data=types.ReviewQueueResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/review-queue [get]
+func (h *DocumentHandler) GetReviewQueue(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	page := 1
+	if pageStr := c.Query("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+
+	limit := 20
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	reviewQueue, err := h.documentService.GetReviewQueue(c.Request.Context(), userID, page, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, reviewQueue, "Review queue retrieved successfully")
+}
+
+// Handles listing the documents the caller viewed or downloaded most recently
+// @Summary List recently accessed documents
+// @Description One entry per document, most recent first, with whether it was last viewed or downloaded. Documents the caller can no longer access are left out.
+// @Tags documents
+// @Produce json
+// @Param limit query int false "Number of documents, at most 50" default(10)
+// @Success 200 {object} utils.ApiResponse{data=types.RecentDocumentsResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/recent [get]
+func (h *DocumentHandler) GetRecentDocuments(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	limit := 10
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 50 {
+			limit = l
+		}
+	}
+
+	recent, err := h.documentService.GetRecentDocuments(c.Request.Context(), userID, limit)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, recent, "Recent documents retrieved successfully")
+}
+
+// Handles restoring a document from the trash
+// @Summary Restore a document from the trash
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.DocumentEnvelope}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "RESTORE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/restore [post]
+func (h *DocumentHandler) RestoreDocument(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Get validated document ID from context
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	document, err := h.documentService.RestoreDocument(c.Request.Context(), userID, documentID)
+	if err != nil {
+		if strings.Contains(err.Error(), "document not found") {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found in trash")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "RESTORE_FAILED", err.Error())
+		return
+	}
+
+	data := gin.H{
+		"document": document,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Document restored successfully")
+}
+
+// Handles queueing a failed document for processing again
+// @Summary Reprocess a failed document
+// @Description Queues page count, thumbnail and text extraction again for a document whose processing failed.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 202 {object} utils.ApiResponse{data=handlers.DocumentEnvelope}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "DOCUMENT_NOT_FAILED"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_QUARANTINED"
+// @Failure 503 {object} utils.ApiResponse "SERVICE_UNAVAILABLE"
+// @Failure 500 {object} utils.ApiResponse "REPROCESS_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/reprocess [post]
+func (h *DocumentHandler) ReprocessDocument(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	document, err := h.documentService.ReprocessDocument(c.Request.Context(), userID, documentID)
+	if err != nil {
+		if documentWithheld(c, err) {
+			return
+		}
+		switch {
+		case strings.Contains(err.Error(), "document not found"):
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or edit access denied")
+		case err.Error() == "document is not in failed state":
+			utils.ConflictResponse(c, "DOCUMENT_NOT_FAILED", "Only documents whose processing failed can be reprocessed")
+		case err.Error() == "document processing is not available":
+			utils.ServiceUnavailableResponse(c, "Document processing is not available")
+		default:
+			utils.RequestLogger(c).WithError(err).WithField("document_id", documentID).Error("Failed to reprocess document")
+			utils.ErrorResponse(c, http.StatusInternalServerError, "REPROCESS_FAILED", "Failed to queue document for processing")
+		}
+		return
+	}
+
+	data := gin.H{
+		"document": document,
+	}
+	utils.SuccessResponse(c, http.StatusAccepted, data, "Document queued for processing")
+}
+
+// Handles permanent document deletion including stored files
+// @Summary Permanently delete a document and its files
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "FORBIDDEN"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "DELETE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/permanent [delete]
+func (h *DocumentHandler) PermanentlyDeleteDocument(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Get validated document ID from context
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	err = h.documentService.PermanentlyDeleteDocument(c.Request.Context(), userID, documentID)
+	if err != nil {
+		if strings.Contains(err.Error(), "document not found") || strings.Contains(err.Error(), "access denied") {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "DELETE_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Document permanently deleted")
 }
 
 // Handles document download
+// @Summary Download the original file
+// @Description Files up to DOWNLOAD_VERIFY_MAX_BYTES are checked against the hash recorded at upload, larger ones only with verify=true.
+// @Description A single byte range can be requested with the Range header, ranged downloads are not checked.
+// @Description Downloads through a user share count against the share's download limit once, when the file is read from its first byte. Ranged requests at a later offset are not counted but are refused once the share has no downloads left, downloads that fail before any of the file is sent are given back.
+// @Tags documents
+// @Produce octet-stream
+// @Param id path string true "Document ID" format(uuid)
+// @Param verify query bool false "Check the file against its recorded hash regardless of its size"
+// @Param Range header string false "Single byte range, e.g. bytes=0-1023"
+// @Success 200 {file} binary "File content"
+// @Success 206 {file} binary "Requested range of the file"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "DOWNLOAD_LIMIT_REACHED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "SCAN_PENDING"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED, DOCUMENT_QUARANTINED"
+// @Failure 416 {object} utils.ApiResponse "RANGE_NOT_SATISFIABLE"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Failure 500 {object} utils.ApiResponse "DOWNLOAD_FAILED, INTEGRITY_CHECK_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/download [get]
 func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
-		logrus.Errorf("[DOWNLOAD_HANDLER] Auth error: %v", err)
+		utils.RequestLogger(c).WithError(err).Error("Download rejected, no authenticated user")
 		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
 		return
 	}
 
 	documentID, ok := validations.GetValidatedDocumentID(c)
 	if !ok {
-		logrus.Error("[DOWNLOAD_HANDLER] Failed to get validated document ID")
+		utils.RequestLogger(c).Error("Download failed, no validated document ID")
 		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
 		return
 	}
 
-	document, err := h.documentService.DownloadDocument(c.Request.Context(), userID, documentID)
+	document, err := h.documentService.DownloadDocument(c.Request.Context(), userID, documentID, c.ClientIP(), c.GetHeader("User-Agent"))
 	if err != nil {
-		logrus.Errorf("[DOWNLOAD_HANDLER] Service error: %v", err)
+		utils.RequestLogger(c).WithError(err).Error("Download failed")
+		if documentExpired(c, err) || documentWithheld(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "document not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or download access denied")
 			return
@@ -332,22 +800,58 @@ func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
 		return
 	}
 
-	// Get file from MinIO
-	fileReader, err := h.minioService.DownloadFile(c.Request.Context(), document.StoragePath)
+	start, end, partial, err := utils.ParseByteRange(c.GetHeader("Range"), document.FileSize)
 	if err != nil {
-		logrus.Errorf("[DOWNLOAD_HANDLER] MinIO download error: %v", err)
-		utils.ErrorResponse(c, http.StatusInternalServerError, "DOWNLOAD_FAILED", "Failed to retrieve file")
+		c.Header("Content-Range", fmt.Sprintf("bytes */%d", document.FileSize))
+		utils.ErrorResponse(c, http.StatusRequestedRangeNotSatisfiable, "RANGE_NOT_SATISFIABLE", "Requested range is outside the file")
+		return
+	}
+
+	// A download through a share is counted once, when it starts at the first byte. Later ranges
+	// are not counted but still need a download left on the share.
+	chargedShare := uuid.Nil
+	if start == 0 {
+		chargedShare, err = h.documentService.ChargeShareDownload(c.Request.Context(), userID, document)
+	} else {
+		err = h.documentService.CheckShareDownload(c.Request.Context(), userID, document)
+	}
+	if err != nil {
+		utils.RequestLogger(c).WithError(err).Error("Download rejected by share limit")
+		if err.Error() == "share download limit reached" {
+			utils.ForbiddenResponse(c, "DOWNLOAD_LIMIT_REACHED", "The download limit of your share of this document has been reached")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "DOWNLOAD_FAILED", "Failed to check download limit")
 		return
 	}
-	defer fileReader.Close()
 
-	// Read file content to avoid header conflicts
-	fileContent, err := io.ReadAll(fileReader)
+	// Get file from MinIO, ranged requests only fetch the bytes asked for
+	var fileReader io.ReadCloser
+	if partial {
+		fileReader, err = h.minioService.DownloadFileRange(c.Request.Context(), document.StoragePath, start, end)
+	} else {
+		// Never hand out a file that no longer matches what was uploaded
+		if h.documentService.ShouldVerifyDownload(document, c.Query("verify") == "true") {
+			if err := h.documentService.VerifyStoredFile(c.Request.Context(), document); err != nil {
+				utils.RequestLogger(c).WithError(err).Error("Download failed integrity check")
+				h.documentService.ReleaseShareDownload(c.Request.Context(), chargedShare)
+				if err.Error() == "content hash mismatch" {
+					utils.ErrorResponse(c, http.StatusInternalServerError, "INTEGRITY_CHECK_FAILED", "The stored file is corrupted")
+				} else {
+					utils.ErrorResponse(c, http.StatusInternalServerError, "DOWNLOAD_FAILED", "Failed to retrieve file")
+				}
+				return
+			}
+		}
+		fileReader, err = h.minioService.DownloadFile(c.Request.Context(), document.StoragePath)
+	}
 	if err != nil {
-		logrus.Errorf("[DOWNLOAD_HANDLER] Failed to read file content: %v", err)
-		utils.ErrorResponse(c, http.StatusInternalServerError, "DOWNLOAD_FAILED", "Failed to read file content")
+		utils.RequestLogger(c).WithError(err).Error("Download failed to fetch file from storage")
+		h.documentService.ReleaseShareDownload(c.Request.Context(), chargedShare)
+		utils.ErrorResponse(c, http.StatusInternalServerError, "DOWNLOAD_FAILED", "Failed to retrieve file")
 		return
 	}
+	defer fileReader.Close()
 
 	// Safely escape filename for Content-Disposition header
 	safeFilename := strings.ReplaceAll(document.OriginalFileName, "\"", "\\\"")
@@ -357,66 +861,89 @@ func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
 	c.Header("Content-Transfer-Encoding", "binary")
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", safeFilename))
 	c.Header("Content-Type", document.MimeType)
-	c.Header("Content-Length", fmt.Sprintf("%d", len(fileContent)))
+	c.Header("Accept-Ranges", "bytes")
 	c.Header("Cache-Control", "no-cache")
 
-	// Send file data
-	c.Data(http.StatusOK, document.MimeType, fileContent)
+	status := http.StatusOK
+	length := document.FileSize
+	if partial {
+		status = http.StatusPartialContent
+		length = end - start + 1
+		c.Header("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, document.FileSize))
+	}
+	c.Header("Content-Length", strconv.FormatInt(length, 10))
+	c.Status(status)
+
+	// Stream the file, the headers are already sent so failures can only be logged
+	written, err := io.Copy(c.Writer, fileReader)
+	metrics.DocumentDownloadBytes.Add(float64(written))
+	if err != nil {
+		utils.RequestLogger(c).WithError(err).Warn("Download interrupted while streaming file")
+	}
 }
 
-// Handles document preview URL generation
-func (h *DocumentHandler) GetDocumentPreview(c *gin.Context) {
+// Handles checking a document's stored file for corruption
+// @Summary Verify the stored file of a document
+// @Description Re-reads the whole file from storage and compares its SHA-256 hash and size with the ones recorded at upload.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=types.DocumentIntegrityResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "VERIFY_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/verify [get]
+func (h *DocumentHandler) VerifyDocument(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
 		return
 	}
 
-	// Get validated document ID from context
 	documentID, ok := validations.GetValidatedDocumentID(c)
 	if !ok {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
 		return
 	}
 
-	// Get document model via service to verify access
-	var document models.Document
-	err = h.documentService.GetDocumentModel(c.Request.Context(), userID, documentID, &document)
+	result, err := h.documentService.VerifyDocument(c.Request.Context(), userID, documentID)
 	if err != nil {
-		if strings.Contains(err.Error(), "document not found") {
-			// Try shared access through service
-			_, err = h.documentService.GetDocument(c.Request.Context(), userID, documentID)
-			if err != nil {
-				utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or preview access denied")
-				return
-			}
-
-			// If service call succeeded, we need to get the document model differently
-			// This is a limitation of the current design - we could improve this
-			utils.ErrorResponse(c, http.StatusInternalServerError, "PREVIEW_FAILED", "Failed to get document details")
+		if documentExpired(c, err) {
 			return
 		}
-		utils.ErrorResponse(c, http.StatusInternalServerError, "PREVIEW_FAILED", "Failed to get document details")
-		return
-	}
-
-	// Generate presigned URL for preview (valid for 1 hour)
-	url, err := h.minioService.GeneratePresignedURL(c.Request.Context(), document.StoragePath, 3600*time.Second)
-	if err != nil {
-		logrus.Errorf("[PREVIEW] Failed to generate presigned URL for document %s: %v", documentID, err)
-		utils.ErrorResponse(c, http.StatusInternalServerError, "PREVIEW_FAILED", "Failed to generate preview URL")
+		if strings.Contains(err.Error(), "document not found") || strings.Contains(err.Error(), "access denied") {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or download access denied")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "VERIFY_FAILED", err.Error())
 		return
 	}
 
-	data := gin.H{
-		"url": url,
-	}
-	utils.SuccessResponse(c, http.StatusOK, data, "Preview URL generated successfully")
+	utils.SuccessResponse(c, http.StatusOK, result, "Document verified")
 }
 
-// Serves thumbnail image for a document
-func (h *DocumentHandler) GetDocumentThumbnail(c *gin.Context) {
-	userID, err := middleware.GetUserIDFromContext(c)
+// Handles document preview URL generation
+// @Summary Get a preview URL for a document
+// @Description Also served under /api/v1/embed/documents for requests carrying an embed token of the document, as a bearer token or the token query parameter.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param expiresIn query int false "Lifetime of presigned URLs in seconds, clamped to 1 minute - 24 hours"
+// @Param redirect query bool false "Redirect to the preview URL instead of returning it"
+// @Success 200 {object} utils.ApiResponse{data=types.DocumentPreviewResponse}
+// @Success 302 "Redirect to the preview URL"
+// @Failure 400 {object} utils.ApiResponse "INVALID_EXPIRES_IN"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "SCAN_PENDING"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED, DOCUMENT_QUARANTINED"
+// @Failure 500 {object} utils.ApiResponse "PREVIEW_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/preview [get]
+func (h *DocumentHandler) GetDocumentPreview(c *gin.Context) {
+	userID, err := middleware.GetViewerIDFromContext(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
 		return
@@ -429,97 +956,590 @@ func (h *DocumentHandler) GetDocumentThumbnail(c *gin.Context) {
 		return
 	}
 
-	// Get document model via service to verify access
+	// Owners, users the document is shared with at any access level, and the owner's embed tokens
 	var document models.Document
 	err = h.documentService.GetDocumentModel(c.Request.Context(), userID, documentID, &document)
 	if err != nil {
+		if documentExpired(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "document not found") {
-			// Try shared access - get document via service
-			_, err = h.documentService.GetDocument(c.Request.Context(), userID, documentID)
-			if err != nil {
-				utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or access denied")
-				return
-			}
-
-			// Similar issue as preview - need to refactor this
-			utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to get document details")
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or preview access denied")
 			return
 		}
-		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
+		utils.ErrorResponse(c, http.StatusInternalServerError, "PREVIEW_FAILED", "Failed to get document details")
 		return
 	}
 
-	// Check if document has thumbnail
-	if !document.HasThumbnail || document.ThumbnailPath == "" {
-		utils.NotFoundResponse(c, "THUMBNAIL_NOT_FOUND", "Thumbnail not available for this document")
-		return
+	// Optional URL lifetime in seconds, clamped to the allowed range
+	var urlExpiry time.Duration
+	if expiresInStr := c.Query("expiresIn"); expiresInStr != "" {
+		seconds, err := strconv.Atoi(expiresInStr)
+		if err != nil || seconds <= 0 {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_EXPIRES_IN", "expiresIn must be a positive number of seconds")
+			return
+		}
+		// Clamp before converting so huge values cannot overflow the duration
+		switch {
+		case seconds < int(previewMinURLExpiry.Seconds()):
+			urlExpiry = previewMinURLExpiry
+		case seconds > int(previewMaxURLExpiry.Seconds()):
+			urlExpiry = previewMaxURLExpiry
+		default:
+			urlExpiry = time.Duration(seconds) * time.Second
+		}
 	}
 
-	// Get thumbnail from MinIO
-	thumbnailReader, err := h.minioService.DownloadFile(c.Request.Context(), document.ThumbnailPath)
+	// Pick the preview that suits the content type
+	preview, err := h.documentService.GetDocumentPreview(c.Request.Context(), &document, urlExpiry)
 	if err != nil {
-		logrus.Errorf("Failed to download thumbnail from storage: %v", err)
-		utils.ErrorResponse(c, http.StatusInternalServerError, "THUMBNAIL_DOWNLOAD_FAILED", "Failed to download thumbnail")
+		if documentWithheld(c, err) {
+			return
+		}
+		utils.RequestLogger(c).WithError(err).WithField("document_id", documentID).Error("Failed to resolve preview")
+		utils.ErrorResponse(c, http.StatusInternalServerError, "PREVIEW_FAILED", "Failed to generate preview URL")
 		return
 	}
-	defer thumbnailReader.Close()
 
-	// Read thumbnail data
-	thumbnailData, err := io.ReadAll(thumbnailReader)
-	if err != nil {
-		logrus.Errorf("Failed to read thumbnail data: %v", err)
-		utils.ErrorResponse(c, http.StatusInternalServerError, "THUMBNAIL_READ_FAILED", "Failed to read thumbnail data")
+	// Browsers may follow and cache the redirect, but never beyond the lifetime of the signed URL
+	if c.Query("redirect") == "true" {
+		maxAge := previewRedirectMaxAge
+		if preview.ExpiresAt != nil {
+			maxAge = time.Until(*preview.ExpiresAt) - previewRedirectMargin
+		}
+		if maxAge > 0 {
+			c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds())))
+		} else {
+			c.Header("Cache-Control", "no-store")
+		}
+		c.Redirect(http.StatusFound, preview.URL)
 		return
 	}
 
-	// Set appropriate headers for image
-	c.Header("Content-Type", "image/jpeg")
-	c.Header("Content-Length", fmt.Sprintf("%d", len(thumbnailData)))
-	c.Header("Cache-Control", "public, max-age=3600") // Cache for 1 hour
-
-	// Serve thumbnail data
-	c.Data(http.StatusOK, "image/jpeg", thumbnailData)
+	utils.SuccessResponse(c, http.StatusOK, preview, "Preview URL generated successfully")
 }
 
-// Retrieves user document statistics
-func (h *DocumentHandler) GetUserStats(c *gin.Context) {
+// Renders text and Markdown documents to sanitized HTML for inline display
+// @Summary Get a text or Markdown document rendered as HTML
+// @Description Markdown is rendered server-side and plain text is escaped into a pre block. Raw HTML in the source is shown as text and only http, https, mailto and relative links are kept. Documents longer than PREVIEW_RENDER_MAX_BYTES are cut off and flagged as truncated. Other types return rendered false with their regular preview in fallback.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=types.RenderedPreviewResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "SCAN_PENDING"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED, DOCUMENT_QUARANTINED"
+// @Failure 500 {object} utils.ApiResponse "PREVIEW_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/preview/rendered [get]
+func (h *DocumentHandler) GetRenderedPreview(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
 		return
 	}
 
-	// Delegate to service
-	stats, err := h.documentService.GetUserStats(c.Request.Context(), userID)
+	// Get validated document ID from context
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	rendered, err := h.documentService.GetRenderedPreview(c.Request.Context(), userID, documentID)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "STATS_FETCH_FAILED", err.Error())
+		if documentExpired(c, err) || documentWithheld(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "document not found") || strings.Contains(err.Error(), "access denied") {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or preview access denied")
+			return
+		}
+		utils.RequestLogger(c).WithError(err).WithField("document_id", documentID).Error("Failed to render preview")
+		utils.ErrorResponse(c, http.StatusInternalServerError, "PREVIEW_FAILED", "Failed to render preview")
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, stats, "User stats retrieved successfully")
+	c.Header("Cache-Control", "private, no-cache")
+	utils.SuccessResponse(c, http.StatusOK, rendered, "Preview rendered successfully")
 }
 
-// Handles multiple document uploads concurrently
-func (h *DocumentHandler) BulkUploadDocuments(c *gin.Context) {
-	fmt.Println("Bulk Upload Here...")
-
+// Serves text documents inline as UTF-8 plain text for the text preview
+// @Summary Get a text document as plain text
+// @Tags documents
+// @Produce plain
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {string} string "Document text"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "SCAN_PENDING"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED, DOCUMENT_QUARANTINED"
+// @Failure 415 {object} utils.ApiResponse "UNSUPPORTED_PREVIEW"
+// @Failure 500 {object} utils.ApiResponse "PREVIEW_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/text [get]
+func (h *DocumentHandler) GetDocumentText(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
 		return
 	}
 
-	// Get validated request from context
-	req, ok := validations.GetValidatedBulkDocumentUpload(c)
+	// Get validated document ID from context
+	documentID, ok := validations.GetValidatedDocumentID(c)
 	if !ok {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
 		return
 	}
 
-	// Create context with timeout for the entire bulk operation
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Minute)
-	defer cancel()
-
+	var document models.Document
+	if err := h.documentService.GetDocumentModel(c.Request.Context(), userID, documentID, &document); err != nil {
+		if documentExpired(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "document not found") {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or preview access denied")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "PREVIEW_FAILED", "Failed to get document details")
+		return
+	}
+
+	if !services.IsTextDocument(&document) {
+		utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "UNSUPPORTED_PREVIEW", "Document is not a text document")
+		return
+	}
+	if err := h.documentService.CheckScanned(&document); err != nil {
+		documentWithheld(c, err)
+		return
+	}
+
+	reader, err := h.minioService.DownloadFile(c.Request.Context(), document.StoragePath)
+	if err != nil {
+		utils.RequestLogger(c).WithError(err).WithField("document_id", documentID).Error("Failed to download document for preview")
+		utils.ErrorResponse(c, http.StatusInternalServerError, "PREVIEW_FAILED", "Failed to read document")
+		return
+	}
+	defer reader.Close()
+
+	c.Header("Content-Disposition", "inline")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-cache")
+	c.DataFromReader(http.StatusOK, document.FileSize, "text/plain; charset=utf-8", reader, nil)
+}
+
+// Serves the text extracted from a document, as plain text or JSON with page offsets
+// @Summary Get the extracted text of a document
+// @Description Returns the text indexed for search. format=json wraps it with its length and, for paginated formats, the offset each page starts at. 202 is returned while the document is still being processed.
+// @Tags documents
+// @Produce plain,json
+// @Param id path string true "Document ID" format(uuid)
+// @Param format query string false "Response format" Enums(plain, json) default(plain)
+// @Success 200 {object} utils.ApiResponse{data=types.DocumentContentResponse} "Extracted text (format=json), plain text otherwise"
+// @Success 202 {object} utils.ApiResponse{data=handlers.DocumentStatusResponse} "Text is not extracted yet"
+// @Failure 400 {object} utils.ApiResponse "INVALID_FORMAT"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 415 {object} utils.ApiResponse "CONTENT_NOT_AVAILABLE"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/content [get]
+func (h *DocumentHandler) GetDocumentContent(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Get validated document ID from context
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	format := c.DefaultQuery("format", "plain")
+	if format != "plain" && format != "json" {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_FORMAT", "format must be plain or json")
+		return
+	}
+
+	content, err := h.documentService.GetDocumentContent(c.Request.Context(), userID, documentID)
+	if err != nil {
+		switch {
+		case err.Error() == "document expired":
+			utils.ErrorResponse(c, http.StatusGone, "DOCUMENT_EXPIRED", "Document has expired")
+		case strings.Contains(err.Error(), "document not found"):
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+		case err.Error() == "document content is not processed yet":
+			utils.SuccessResponse(c, http.StatusAccepted, DocumentStatusResponse{Status: models.DocumentStatusProcessing}, "Document text is still being extracted")
+		case err.Error() == "document content is not available":
+			utils.ErrorResponse(c, http.StatusUnsupportedMediaType, "CONTENT_NOT_AVAILABLE", "No text is available for this document")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to get document content")
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-cache")
+	if format == "json" {
+		utils.SuccessResponse(c, http.StatusOK, content, "Document content retrieved successfully")
+		return
+	}
+
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(content.Content))
+}
+
+// Serves thumbnail image for a document
+// @Summary Get the thumbnail of a document
+// @Description Served as WebP when the Accept header lists image/webp and the server can write it, JPEG otherwise.
+// @Description Small and large thumbnails are rendered on their first request.
+// @Description Also served under /api/v1/embed/documents for requests carrying an embed token of the document, as a bearer token or the token query parameter.
+// @Tags documents
+// @Produce jpeg
+// @Produce image/webp
+// @Param id path string true "Document ID" format(uuid)
+// @Param size query string false "Thumbnail size" Enums(small, medium, large) default(medium)
+// @Success 200 {file} binary "JPEG or WebP thumbnail"
+// @Failure 400 {object} utils.ApiResponse "INVALID_SIZE"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND, THUMBNAIL_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED, THUMBNAIL_DOWNLOAD_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/thumbnail [get]
+func (h *DocumentHandler) GetDocumentThumbnail(c *gin.Context) {
+	userID, err := middleware.GetViewerIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Get validated document ID from context
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	// Owners, users the document is shared with at any access level, and the owner's embed tokens
+	var document models.Document
+	err = h.documentService.GetDocumentModel(c.Request.Context(), userID, documentID, &document)
+	if err != nil {
+		if documentExpired(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "document not found") {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or access denied")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
+		return
+	}
+
+	size, ok := services.ParseThumbnailSize(c.Query("size"))
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_SIZE", "size must be small, medium or large")
+		return
+	}
+
+	// Check if document has thumbnail
+	if !document.HasThumbnail || document.ThumbnailPath == "" {
+		utils.NotFoundResponse(c, "THUMBNAIL_NOT_FOUND", "Thumbnail not available for this document")
+		return
+	}
+
+	thumbnailPath, format, err := h.documentService.ResolveThumbnail(c.Request.Context(), &document, size, services.NegotiateThumbnailFormat(c.GetHeader("Accept")))
+	if err != nil {
+		utils.RequestLogger(c).WithError(err).Error("Failed to resolve thumbnail variant")
+		utils.ErrorResponse(c, http.StatusInternalServerError, "THUMBNAIL_DOWNLOAD_FAILED", "Failed to render thumbnail")
+		return
+	}
+
+	// The thumbnail is rewritten whenever the document changes, so its path and the update time identify it
+	etag := utils.StrongETag(thumbnailPath, strconv.FormatInt(document.UpdatedAt.UnixNano(), 10))
+	c.Header("ETag", etag)
+	c.Header("Vary", "Accept")
+	c.Header("Cache-Control", "public, max-age=3600") // Cache for 1 hour
+
+	if utils.ETagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	// Get thumbnail from MinIO
+	thumbnailReader, err := h.minioService.DownloadFile(c.Request.Context(), thumbnailPath)
+	if err != nil {
+		utils.RequestLogger(c).WithError(err).Error("Failed to download thumbnail from storage")
+		utils.ErrorResponse(c, http.StatusInternalServerError, "THUMBNAIL_DOWNLOAD_FAILED", "Failed to download thumbnail")
+		return
+	}
+	defer thumbnailReader.Close()
+
+	// Streamed like downloads, the size isn't known without another storage call so the
+	// response goes out chunked
+	c.DataFromReader(http.StatusOK, -1, format.ContentType(), thumbnailReader, nil)
+}
+
+// Returns a short-lived storage URL of the thumbnail
+// @Summary Get a presigned URL of the thumbnail of a document
+// @Description Lets the browser fetch the thumbnail straight from storage instead of through the API.
+// @Description The format defaults to WebP when the Accept header lists image/webp and the server can write it, JPEG otherwise.
+// @Description Also served under /api/v1/embed/documents for requests carrying an embed token of the document, as a bearer token or the token query parameter.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param size query string false "Thumbnail size" Enums(small, medium, large) default(medium)
+// @Param format query string false "Thumbnail format" Enums(jpeg, webp)
+// @Success 200 {object} utils.ApiResponse{data=types.ThumbnailURLResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_SIZE, INVALID_FORMAT"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND, THUMBNAIL_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED, THUMBNAIL_URL_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/thumbnail/url [get]
+func (h *DocumentHandler) GetDocumentThumbnailURL(c *gin.Context) {
+	userID, err := middleware.GetViewerIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Get validated document ID from context
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	size, ok := services.ParseThumbnailSize(c.Query("size"))
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_SIZE", "size must be small, medium or large")
+		return
+	}
+
+	// The URL is fetched by the browser directly, so the Accept header of this request says nothing
+	// about the image formats it takes and the format can be asked for explicitly
+	format := services.NegotiateThumbnailFormat(c.GetHeader("Accept"))
+	if formatStr := c.Query("format"); formatStr != "" {
+		if format, ok = services.ParseThumbnailFormat(formatStr); !ok {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_FORMAT", "format must be jpeg or webp")
+			return
+		}
+	}
+
+	// Same access check as the proxied thumbnail
+	var document models.Document
+	err = h.documentService.GetDocumentModel(c.Request.Context(), userID, documentID, &document)
+	if err != nil {
+		if documentExpired(c, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "document not found") {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found or access denied")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
+		return
+	}
+
+	if !document.HasThumbnail || document.ThumbnailPath == "" {
+		utils.NotFoundResponse(c, "THUMBNAIL_NOT_FOUND", "Thumbnail not available for this document")
+		return
+	}
+
+	thumbnailURL, err := h.documentService.GetThumbnailURL(c.Request.Context(), &document, size, format)
+	if err != nil {
+		utils.RequestLogger(c).WithError(err).Error("Failed to generate thumbnail URL")
+		utils.ErrorResponse(c, http.StatusInternalServerError, "THUMBNAIL_URL_FAILED", "Failed to generate thumbnail URL")
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	utils.SuccessResponse(c, http.StatusOK, thumbnailURL, "Thumbnail URL generated successfully")
+}
+
+// Returns the presigned thumbnail and preview URLs of several documents at once
+// @Summary Get presigned URLs of several documents
+// @Description Saves a grid one thumbnail URL request per document. Documents the user can't view are listed in skipped instead of failing the request.
+// @Description The thumbnail format defaults to WebP when the Accept header lists image/webp and the server can write it, JPEG otherwise.
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param request body types.PresignedURLsRequest true "Document IDs, at most BULK_PRESIGN_MAX, and the thumbnail variant"
+// @Success 200 {object} utils.ApiResponse{data=types.PresignedURLsResponse}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR, INVALID_SIZE, INVALID_FORMAT"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "PRESIGN_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/presigned-urls [post]
+func (h *DocumentHandler) GetPresignedURLs(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	req, ok := validations.GetValidatedPresignedURLs(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated request")
+		return
+	}
+
+	size, ok := services.ParseThumbnailSize(req.Size)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_SIZE", "size must be small, medium or large")
+		return
+	}
+	format := services.NegotiateThumbnailFormat(c.GetHeader("Accept"))
+	if req.Format != "" {
+		if format, ok = services.ParseThumbnailFormat(req.Format); !ok {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_FORMAT", "format must be jpeg or webp")
+			return
+		}
+	}
+
+	documentIDs := make([]uuid.UUID, len(req.DocumentIDs))
+	for i, id := range req.DocumentIDs {
+		documentIDs[i] = uuid.MustParse(id)
+	}
+
+	urls, err := h.documentService.GetPresignedURLs(c.Request.Context(), userID, documentIDs, size, format, req.IncludePreview)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "PRESIGN_FAILED", err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	utils.SuccessResponse(c, http.StatusOK, urls, "Presigned URLs generated successfully")
+}
+
+// Retrieves user document statistics
+// @Summary Get document statistics of the current user
+// @Tags documents
+// @Produce json
+// @Success 200 {object} utils.ApiResponse{data=types.UserStatsResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "STATS_FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/stats [get]
+func (h *DocumentHandler) GetUserStats(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Delegate to service
+	stats, err := h.documentService.GetUserStats(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "STATS_FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, stats, "User stats retrieved successfully")
+}
+
+// Breaks the user's storage down by file type
+// @Summary Get the storage breakdown of the current user
+// @Description Usage, document count and average size per file type plus uploads per month over the last 12 months. Documents in trash are not included.
+// @Tags documents
+// @Produce json
+// @Success 200 {object} utils.ApiResponse{data=types.StorageBreakdownResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "STATS_FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/stats/breakdown [get]
+func (h *DocumentHandler) GetStorageBreakdown(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	breakdown, err := h.documentService.GetStorageBreakdown(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "STATS_FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, breakdown, "Storage breakdown retrieved successfully")
+}
+
+// Lists the user's tags with document counts
+// @Summary List tags with document counts
+// @Tags documents
+// @Produce json
+// @Success 200 {object} utils.ApiResponse{data=handlers.TagsResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "TAGS_FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/tags [get]
+func (h *DocumentHandler) GetTags(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	tags, err := h.documentService.GetUserTags(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "TAGS_FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"tags": tags}, "Tags retrieved successfully")
+}
+
+// Handles multiple document uploads concurrently
+// @Summary Upload several documents
+// @Description Metadata of each file is sent as files[i].title, files[i].description, files[i].tags, files[i].isPublic, files[i].language, files[i].customMetadata, files[i].allowDuplicate, files[i].runOcr and files[i].ocrLanguage. Files whose content you already uploaded fail with duplicateOf set to the existing document.
+// @Tags documents
+// @Accept multipart/form-data
+// @Produce json
+// @Param Idempotency-Key header string false "Retries with the same key within UPLOAD_IDEMPOTENCY_TTL get the first response back"
+// @Param files formData file true "Document files"
+// @Param allowDuplicate formData bool false "Default for files without files[i].allowDuplicate"
+// @Param runOcr formData bool false "Default for files without files[i].runOcr"
+// @Param ocrLanguage formData string false "Default for files without files[i].ocrLanguage"
+// @Param expiresAt formData string false "Default for files without files[i].expiresAt"
+// @Success 201 {object} utils.ApiResponse{data=handlers.BulkUploadResponse}
+// @Success 206 {object} utils.ApiResponse{data=handlers.BulkUploadResponse} "Some uploads failed"
+// @Failure 400 {object} utils.ApiResponse{data=handlers.BulkUploadResponse} "VALIDATION_ERROR, ALL_UPLOADS_FAILED, INVALID_IDEMPOTENCY_KEY"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 409 {object} utils.ApiResponse "IDEMPOTENCY_KEY_IN_USE"
+// @Failure 422 {object} utils.ApiResponse "IDEMPOTENCY_KEY_REUSED"
+// @Failure 413 {object} utils.ApiResponse "REQUEST_TOO_LARGE"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Security BearerAuth
+// @Router /api/v1/documents/bulk-upload [post]
+func (h *DocumentHandler) BulkUploadDocuments(c *gin.Context) {
+	metrics.BulkOperationsActive.Inc("upload")
+	defer metrics.BulkOperationsActive.Dec("upload")
+
+	fmt.Println("Bulk Upload Here...")
+
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Get validated request from context
+	req, ok := validations.GetValidatedBulkDocumentUpload(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	// Create context with timeout for the entire bulk operation
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.bulk.UploadTimeout)
+	defer cancel()
+
 	// Channel to collect results
 	type uploadResult struct {
 		index    int
@@ -528,7 +1548,9 @@ func (h *DocumentHandler) BulkUploadDocuments(c *gin.Context) {
 	}
 
 	resultChan := make(chan uploadResult, len(req.Files))
+	semaphore := make(chan struct{}, h.bulk.UploadConcurrency)
 	var wg sync.WaitGroup
+	logger := utils.RequestLogger(c)
 
 	// Process each file concurrently
 	for i, file := range req.Files {
@@ -536,31 +1558,44 @@ func (h *DocumentHandler) BulkUploadDocuments(c *gin.Context) {
 		go func(idx int, f *multipart.FileHeader, meta validations.FileMetadata) {
 			defer wg.Done()
 
+			// Acquire semaphore
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
 			uploadReq := &types.UploadDocumentRequest{
-				Title:       meta.Title,
-				Description: meta.Description,
-				Tags:        meta.Tags,
-				IsPublic:    meta.IsPublic,
+				Title:          meta.Title,
+				Description:    meta.Description,
+				Tags:           meta.Tags,
+				IsPublic:       meta.IsPublic,
+				Language:       meta.Language,
+				CustomMetadata: meta.CustomMetadata,
+				AllowDuplicate: meta.AllowDuplicate,
+				RunOCR:         meta.RunOCR,
+				OCRLanguage:    meta.OCRLanguage,
+				ExpiresAt:      meta.ExpiresAt,
 			}
 
 			document, uploadErr := h.documentService.UploadDocument(ctx, userID, f, uploadReq)
 
 			if uploadErr == nil && document != nil {
 				// Create processing tasks for the document
+				docLogger := logger.WithField("document_id", document.ID)
 				if err := h.processingTaskService.CreateProcessingTasks(document.ID); err != nil {
-					logrus.Errorf("Failed to create processing tasks for document %s: %v", document.ID.String(), err)
+					docLogger.WithError(err).Error("Failed to create processing tasks")
 				} else {
-					logrus.Infof("Created processing tasks for document %s", document.ID.String())
+					docLogger.Info("Created processing tasks")
 				}
-			}
 
-			if uploadErr == nil && document != nil && h.queuePublisher != nil {
-				logrus.Infof("Publishing document %s to processing queue", document.ID)
-				if err := h.queuePublisher.PublishDocumentForProcessing(document.ID.String(), document.StoragePath); err != nil {
-					logrus.Errorf("Failed to queue document %s for processing: %v", document.ID, err)
-				} else {
-					logrus.Infof("Successfully queued document %s for processing", document.ID)
+				if h.queuePublisher != nil {
+					docLogger.Info("Publishing document to processing queue")
+					if err := h.queuePublisher.PublishDocumentForProcessing(document.ID.String(), document.StoragePath, queue.DocumentMessageKey(document.ID.String(), document.StoragePath)); err != nil {
+						docLogger.WithError(err).Error("Failed to queue document for processing")
+					} else {
+						docLogger.Info("Queued document for processing")
+					}
 				}
+			} else if uploadErr != nil {
+				logger.WithError(uploadErr).WithField("filename", f.Filename).Warn("Bulk upload of file failed")
 			}
 
 			resultChan <- uploadResult{
@@ -568,7 +1603,131 @@ func (h *DocumentHandler) BulkUploadDocuments(c *gin.Context) {
 				document: document,
 				err:      uploadErr,
 			}
-		}(i, file, req.Metadata[i])
+		}(i, file, req.Metadata[i])
+	}
+
+	// Close result channel when all goroutines complete
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	// Collect all results
+	results := make([]uploadResult, len(req.Files))
+	successfulUploads := []*types.DocumentResponse{}
+	failedUploads := []BulkFailure{}
+
+	for result := range resultChan {
+		results[result.index] = result
+
+		if result.err != nil {
+			failedUploads = append(failedUploads, h.newBulkFailure("", req.Files[result.index].Filename, result.err))
+		} else {
+			successfulUploads = append(successfulUploads, result.document)
+		}
+	}
+
+	// Prepare response
+	response := gin.H{
+		"successful_uploads": len(successfulUploads),
+		"failed_uploads":     len(failedUploads),
+		"total_files":        len(req.Files),
+		"documents":          successfulUploads,
+	}
+
+	// Add failures if any
+	if len(failedUploads) > 0 {
+		response["failures"] = failedUploads
+	}
+
+	// Determine response status based on results
+	if len(successfulUploads) == 0 {
+		utils.ErrorResponseWithData(c, http.StatusBadRequest, "ALL_UPLOADS_FAILED", "All file uploads failed", response)
+		return
+	} else if len(failedUploads) > 0 {
+		utils.SuccessResponse(c, http.StatusPartialContent, response,
+			fmt.Sprintf("Uploaded %d out of %d files successfully", len(successfulUploads), len(req.Files)))
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, response,
+		fmt.Sprintf("All %d files uploaded successfully", len(req.Files)))
+}
+
+// Handles multiple document deletions concurrently
+// @Summary Move several documents to the trash
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param request body validations.BulkOperationRequest true "Document IDs"
+// @Success 200 {object} utils.ApiResponse{data=handlers.BulkDeleteResponse}
+// @Success 206 {object} utils.ApiResponse{data=handlers.BulkDeleteResponse} "Some deletions failed"
+// @Failure 400 {object} utils.ApiResponse{data=handlers.BulkDeleteResponse} "VALIDATION_ERROR, ALL_DELETES_FAILED"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "FORBIDDEN"
+// @Security BearerAuth
+// @Router /api/v1/documents/bulk-delete [post]
+func (h *DocumentHandler) BulkDeleteDocuments(c *gin.Context) {
+	metrics.BulkOperationsActive.Inc("delete")
+	defer metrics.BulkOperationsActive.Dec("delete")
+
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Get validated request from context
+	req, ok := validations.GetValidatedBulkDelete(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.bulk.DeleteTimeout)
+	defer cancel()
+
+	// Channel to collect results
+	type deleteResult struct {
+		documentID string
+		success    bool
+		error      error
+	}
+
+	resultChan := make(chan deleteResult, len(req.DocumentIDs))
+	semaphore := make(chan struct{}, h.bulk.DeleteConcurrency)
+	var wg sync.WaitGroup
+
+	// Process each document concurrently
+	for _, documentID := range req.DocumentIDs {
+		wg.Add(1)
+		go func(docID string) {
+			defer wg.Done()
+
+			// Acquire semaphore
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			// Parse UUID
+			docUUID, parseErr := uuid.Parse(docID)
+			if parseErr != nil {
+				resultChan <- deleteResult{
+					documentID: docID,
+					success:    false,
+					error:      fmt.Errorf("invalid document ID format"),
+				}
+				return
+			}
+
+			// Delegate to service
+			deleteErr := h.documentService.DeleteDocument(ctx, userID, docUUID)
+			resultChan <- deleteResult{
+				documentID: docID,
+				success:    deleteErr == nil,
+				error:      deleteErr,
+			}
+		}(documentID)
 	}
 
 	// Close result channel when all goroutines complete
@@ -578,52 +1737,61 @@ func (h *DocumentHandler) BulkUploadDocuments(c *gin.Context) {
 	}()
 
 	// Collect all results
-	results := make([]uploadResult, len(req.Files))
-	successfulUploads := []*types.DocumentResponse{}
-	failedUploads := []map[string]interface{}{}
+	successfulDeletes := 0
+	failedDeletes := 0
+	failures := []BulkFailure{}
 
 	for result := range resultChan {
-		results[result.index] = result
-
-		if result.err != nil {
-			failedUploads = append(failedUploads, map[string]interface{}{
-				"filename": req.Files[result.index].Filename,
-				"error":    result.err.Error(),
-			})
+		if result.success {
+			successfulDeletes++
 		} else {
-			successfulUploads = append(successfulUploads, result.document)
+			failedDeletes++
+			failures = append(failures, h.newBulkFailure(result.documentID, "", result.error))
 		}
 	}
 
 	// Prepare response
 	response := gin.H{
-		"successful_uploads": len(successfulUploads),
-		"failed_uploads":     len(failedUploads),
-		"total_files":        len(req.Files),
-		"documents":          successfulUploads,
+		"successful_deletes": successfulDeletes,
+		"failed_deletes":     failedDeletes,
+		"total_documents":    len(req.DocumentIDs),
 	}
 
 	// Add failures if any
-	if len(failedUploads) > 0 {
-		response["failures"] = failedUploads
+	if len(failures) > 0 {
+		response["failures"] = failures
 	}
 
-	// Determine response status based on results
-	if len(successfulUploads) == 0 {
-		utils.ErrorResponse(c, http.StatusBadRequest, "ALL_UPLOADS_FAILED", "All file uploads failed")
+	// Determine response status
+	if successfulDeletes == 0 {
+		utils.ErrorResponseWithData(c, http.StatusBadRequest, "ALL_DELETES_FAILED", "All document deletions failed", response)
 		return
-	} else if len(failedUploads) > 0 {
+	} else if failedDeletes > 0 {
 		utils.SuccessResponse(c, http.StatusPartialContent, response,
-			fmt.Sprintf("Uploaded %d out of %d files successfully", len(successfulUploads), len(req.Files)))
+			fmt.Sprintf("Deleted %d out of %d documents successfully", successfulDeletes, len(req.DocumentIDs)))
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusCreated, response,
-		fmt.Sprintf("All %d files uploaded successfully", len(req.Files)))
+	utils.SuccessResponse(c, http.StatusOK, response,
+		fmt.Sprintf("All %d documents deleted successfully", len(req.DocumentIDs)))
 }
 
-// Handles multiple document deletions concurrently
-func (h *DocumentHandler) BulkDeleteDocuments(c *gin.Context) {
+// Handles tag and visibility changes on multiple documents concurrently
+// @Summary Change tags and visibility of several documents
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param request body types.BulkUpdateDocumentsRequest true "Documents and changes"
+// @Success 200 {object} utils.ApiResponse{data=handlers.BulkUpdateResponse}
+// @Success 206 {object} utils.ApiResponse{data=handlers.BulkUpdateResponse} "Some updates failed"
+// @Failure 400 {object} utils.ApiResponse{data=handlers.BulkUpdateResponse} "VALIDATION_ERROR, ALL_UPDATES_FAILED"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Security BearerAuth
+// @Router /api/v1/documents/bulk [patch]
+func (h *DocumentHandler) BulkUpdateDocuments(c *gin.Context) {
+	metrics.BulkOperationsActive.Inc("update")
+	defer metrics.BulkOperationsActive.Dec("update")
+
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
@@ -631,25 +1799,27 @@ func (h *DocumentHandler) BulkDeleteDocuments(c *gin.Context) {
 	}
 
 	// Get validated request from context
-	req, ok := validations.GetValidatedBulkDelete(c)
+	req, ok := validations.GetValidatedBulkUpdate(c)
 	if !ok {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
 		return
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.bulk.UpdateTimeout)
 	defer cancel()
 
+	clientIP := c.ClientIP()
+	userAgent := c.GetHeader("User-Agent")
+
 	// Channel to collect results
-	type deleteResult struct {
+	type updateResult struct {
 		documentID string
-		success    bool
 		error      error
 	}
 
-	resultChan := make(chan deleteResult, len(req.DocumentIDs))
-	semaphore := make(chan struct{}, 10) // Limit concurrent operations to 10
+	resultChan := make(chan updateResult, len(req.DocumentIDs))
+	semaphore := make(chan struct{}, h.bulk.UpdateConcurrency)
 	var wg sync.WaitGroup
 
 	// Process each document concurrently
@@ -665,21 +1835,13 @@ func (h *DocumentHandler) BulkDeleteDocuments(c *gin.Context) {
 			// Parse UUID
 			docUUID, parseErr := uuid.Parse(docID)
 			if parseErr != nil {
-				resultChan <- deleteResult{
-					documentID: docID,
-					success:    false,
-					error:      fmt.Errorf("invalid document ID format"),
-				}
+				resultChan <- updateResult{documentID: docID, error: fmt.Errorf("invalid document ID format")}
 				return
 			}
 
 			// Delegate to service
-			deleteErr := h.documentService.DeleteDocument(ctx, userID, docUUID)
-			resultChan <- deleteResult{
-				documentID: docID,
-				success:    deleteErr == nil,
-				error:      deleteErr,
-			}
+			updateErr := h.documentService.BulkUpdateDocument(ctx, userID, docUUID, req, clientIP, userAgent)
+			resultChan <- updateResult{documentID: docID, error: updateErr}
 		}(documentID)
 	}
 
@@ -690,26 +1852,23 @@ func (h *DocumentHandler) BulkDeleteDocuments(c *gin.Context) {
 	}()
 
 	// Collect all results
-	successfulDeletes := 0
-	failedDeletes := 0
-	failures := []map[string]interface{}{}
+	successfulUpdates := 0
+	failedUpdates := 0
+	failures := []BulkFailure{}
 
 	for result := range resultChan {
-		if result.success {
-			successfulDeletes++
+		if result.error == nil {
+			successfulUpdates++
 		} else {
-			failedDeletes++
-			failures = append(failures, map[string]interface{}{
-				"id":    result.documentID,
-				"error": result.error.Error(),
-			})
+			failedUpdates++
+			failures = append(failures, h.newBulkFailure(result.documentID, "", result.error))
 		}
 	}
 
 	// Prepare response
 	response := gin.H{
-		"successful_deletes": successfulDeletes,
-		"failed_deletes":     failedDeletes,
+		"successful_updates": successfulUpdates,
+		"failed_updates":     failedUpdates,
 		"total_documents":    len(req.DocumentIDs),
 	}
 
@@ -719,21 +1878,36 @@ func (h *DocumentHandler) BulkDeleteDocuments(c *gin.Context) {
 	}
 
 	// Determine response status
-	if successfulDeletes == 0 {
-		utils.ErrorResponse(c, http.StatusBadRequest, "ALL_DELETES_FAILED", "All document deletions failed")
+	if successfulUpdates == 0 {
+		utils.ErrorResponseWithData(c, http.StatusBadRequest, "ALL_UPDATES_FAILED", "All document updates failed", response)
 		return
-	} else if failedDeletes > 0 {
+	} else if failedUpdates > 0 {
 		utils.SuccessResponse(c, http.StatusPartialContent, response,
-			fmt.Sprintf("Deleted %d out of %d documents successfully", successfulDeletes, len(req.DocumentIDs)))
+			fmt.Sprintf("Updated %d out of %d documents successfully", successfulUpdates, len(req.DocumentIDs)))
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, response,
-		fmt.Sprintf("All %d documents deleted successfully", len(req.DocumentIDs)))
+		fmt.Sprintf("All %d documents updated successfully", len(req.DocumentIDs)))
 }
 
 // Creates a ZIP file with multiple documents
+// @Summary Download several documents as a ZIP archive
+// @Description Documents that could not be added are listed with their error code in a _failures.json entry of the archive.
+// @Tags documents
+// @Accept json
+// @Produce application/zip
+// @Param request body validations.BulkOperationRequest true "Document IDs"
+// @Success 200 {file} binary "ZIP archive"
+// @Failure 400 {object} utils.ApiResponse{data=handlers.BulkDownloadFailures} "VALIDATION_ERROR, NO_FILES_DOWNLOADED"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Security BearerAuth
+// @Router /api/v1/documents/bulk-download [post]
 func (h *DocumentHandler) BulkDownloadDocuments(c *gin.Context) {
+	metrics.BulkOperationsActive.Inc("download")
+	defer metrics.BulkOperationsActive.Dec("download")
+
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
 		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
@@ -748,13 +1922,14 @@ func (h *DocumentHandler) BulkDownloadDocuments(c *gin.Context) {
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Minute)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.bulk.DownloadTimeout)
 	defer cancel()
 
 	// Channel to collect document fetch results
 	resultChan := make(chan documentResult, len(req.DocumentIDs))
-	semaphore := make(chan struct{}, 5) // Limit concurrent downloads to 5
+	semaphore := make(chan struct{}, h.bulk.DownloadConcurrency)
 	var wg sync.WaitGroup
+	clientIP, userAgent := c.ClientIP(), c.GetHeader("User-Agent")
 
 	// Fetch documents and their content concurrently
 	for _, documentID := range req.DocumentIDs {
@@ -770,16 +1945,27 @@ func (h *DocumentHandler) BulkDownloadDocuments(c *gin.Context) {
 			docUUID, parseErr := uuid.Parse(docID)
 			if parseErr != nil {
 				resultChan <- documentResult{
-					error: fmt.Errorf("invalid document ID format: %s", docID),
+					documentID: docID,
+					error:      fmt.Errorf("invalid document ID format"),
 				}
 				return
 			}
 
 			// Get document via service (handles access control)
-			document, fetchErr := h.documentService.DownloadDocument(ctx, userID, docUUID)
+			document, fetchErr := h.documentService.DownloadDocument(ctx, userID, docUUID, clientIP, userAgent)
 			if fetchErr != nil {
 				resultChan <- documentResult{
-					error: fmt.Errorf("failed to fetch document %s: %w", docID, fetchErr),
+					documentID: docID,
+					error:      fetchErr,
+				}
+				return
+			}
+
+			chargedShare, chargeErr := h.documentService.ChargeShareDownload(ctx, userID, document)
+			if chargeErr != nil {
+				resultChan <- documentResult{
+					documentID: docID,
+					error:      chargeErr,
 				}
 				return
 			}
@@ -787,8 +1973,10 @@ func (h *DocumentHandler) BulkDownloadDocuments(c *gin.Context) {
 			// Download file content from MinIO
 			fileReader, downloadErr := h.minioService.DownloadFile(ctx, document.StoragePath)
 			if downloadErr != nil {
+				h.documentService.ReleaseShareDownload(ctx, chargedShare)
 				resultChan <- documentResult{
-					error: fmt.Errorf("failed to download file for document %s: %w", docID, downloadErr),
+					documentID: docID,
+					error:      fmt.Errorf("failed to download file from storage: %w", downloadErr),
 				}
 				return
 			}
@@ -797,16 +1985,18 @@ func (h *DocumentHandler) BulkDownloadDocuments(c *gin.Context) {
 			// Read file content
 			content, readErr := io.ReadAll(fileReader)
 			if readErr != nil {
+				h.documentService.ReleaseShareDownload(ctx, chargedShare)
 				resultChan <- documentResult{
-					error: fmt.Errorf("failed to read file content for document %s: %w", docID, readErr),
+					documentID: docID,
+					error:      fmt.Errorf("failed to read file from storage: %w", readErr),
 				}
 				return
 			}
 
 			resultChan <- documentResult{
-				document: document,
-				content:  content,
-				error:    nil,
+				documentID: docID,
+				document:   document,
+				content:    content,
 			}
 		}(documentID)
 	}
@@ -818,10 +2008,11 @@ func (h *DocumentHandler) BulkDownloadDocuments(c *gin.Context) {
 	}()
 
 	// Create ZIP and collect results
-	zipBuffer, successfulDownloads, _ := h.createZipFromResults(resultChan, req.DocumentIDs)
+	zipBuffer, successfulDownloads, failures := h.createZipFromResults(utils.RequestLogger(c), resultChan, req.DocumentIDs)
 
 	if successfulDownloads == 0 {
-		utils.ErrorResponse(c, http.StatusBadRequest, "NO_FILES_DOWNLOADED", "No files could be downloaded")
+		utils.ErrorResponseWithData(c, http.StatusBadRequest, "NO_FILES_DOWNLOADED", "No files could be downloaded",
+			gin.H{"failures": failures})
 		return
 	}
 
@@ -838,10 +2029,22 @@ func (h *DocumentHandler) BulkDownloadDocuments(c *gin.Context) {
 	c.Header("Cache-Control", "no-cache")
 
 	// Send ZIP file data
+	metrics.DocumentDownloadBytes.Add(float64(zipBuffer.Len()))
 	c.Data(http.StatusOK, "application/zip", zipBuffer.Bytes())
 }
 
 // Retrieves document version history
+// @Summary List the revisions of a document
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.RevisionsResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/revisions [get]
 func (h *DocumentHandler) GetDocumentRevisions(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -859,6 +2062,9 @@ func (h *DocumentHandler) GetDocumentRevisions(c *gin.Context) {
 	// Delegate to service
 	revisions, err := h.documentService.GetDocumentRevisions(c.Request.Context(), userID, documentID)
 	if err != nil {
+		if documentExpired(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "document not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
 			return
@@ -873,12 +2079,364 @@ func (h *DocumentHandler) GetDocumentRevisions(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, data, "Revisions retrieved successfully")
 }
 
+// Handles comparing two versions of a document
+// @Summary Compare two versions of a document
+// @Description Returns a unified diff of the extracted text and the metadata that changed between two revisions, or a revision and the current version when to is omitted.
+// @Description Versions without extracted text, such as binary files, compare by metadata only.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param from query string true "Revision ID to compare from" format(uuid)
+// @Param to query string false "Revision ID to compare to, the current version when omitted" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=types.RevisionDiffResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_REVISION_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND, REVISION_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/revisions/diff [get]
+func (h *DocumentHandler) GetRevisionDiff(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	fromID, err := uuid.Parse(c.Query("from"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_REVISION_ID", "from must be a revision ID")
+		return
+	}
+	var toID *uuid.UUID
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := uuid.Parse(raw)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_REVISION_ID", "to must be a revision ID")
+			return
+		}
+		toID = &parsed
+	}
+
+	diff, err := h.documentService.GetRevisionDiff(c.Request.Context(), userID, documentID, fromID, toID)
+	if err != nil {
+		switch {
+		case err.Error() == "document expired":
+			utils.ErrorResponse(c, http.StatusGone, "DOCUMENT_EXPIRED", "Document has expired")
+		case strings.Contains(err.Error(), "document not found") || strings.Contains(err.Error(), "access denied"):
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+		case err.Error() == "revision not found":
+			utils.NotFoundResponse(c, "REVISION_NOT_FOUND", "Revision not found")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to compare revisions")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, diff, "Revisions compared successfully")
+}
+
+// Makes an earlier revision the current version of the document
+// @Summary Restore an earlier revision
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param revisionId path string true "Revision ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.DocumentEnvelope}
+// @Failure 400 {object} utils.ApiResponse "INVALID_REVISION_ID, REVISION_NOT_RESTORABLE"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "ACCESS_DENIED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND, REVISION_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "REVISION_FILE_GONE"
+// @Failure 500 {object} utils.ApiResponse "STORAGE_ERROR, DATABASE_ERROR, INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/revisions/{revisionId}/restore [post]
+func (h *DocumentHandler) RestoreRevision(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	// Get validated document ID from context
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	revisionID, err := uuid.Parse(c.Param("revisionId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_REVISION_ID", "Invalid revision ID format")
+		return
+	}
+
+	document, err := h.documentService.RestoreRevision(c.Request.Context(), userID, documentID, revisionID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "revision not found":
+			utils.NotFoundResponse(c, "REVISION_NOT_FOUND", "Revision not found")
+		case "revision has no stored file":
+			utils.ErrorResponse(c, http.StatusBadRequest, "REVISION_NOT_RESTORABLE", "Revision has no stored file to restore")
+		case "revision file no longer available":
+			utils.ErrorResponse(c, http.StatusGone, "REVISION_FILE_GONE", "The file of this revision is no longer available")
+		default:
+			status, code := h.mapServiceErrorToHTTP(err)
+			utils.ErrorResponse(c, status, code, err.Error())
+		}
+		return
+	}
+
+	data := gin.H{
+		"document": document,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Revision restored successfully")
+}
+
+// Handles filing a document in a folder
+// @Summary Move a document to a folder
+// @Tags documents
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param body body types.MoveDocumentRequest true "Destination folder, null for the root"
+// @Success 200 {object} utils.ApiResponse{data=handlers.DocumentEnvelope}
+// @Failure 400 {object} utils.ApiResponse "INVALID_BODY"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND, FOLDER_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "MOVE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/move [post]
+func (h *DocumentHandler) MoveDocument(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	var req types.MoveDocumentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_BODY", "Body must be a JSON object with a folderId")
+		return
+	}
+
+	document, err := h.documentService.MoveDocument(c.Request.Context(), userID, documentID, req.FolderID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "folder not found"):
+			utils.NotFoundResponse(c, "FOLDER_NOT_FOUND", "Folder not found")
+		case strings.Contains(err.Error(), "document not found"):
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "MOVE_FAILED", err.Error())
+		}
+		return
+	}
+
+	data := gin.H{
+		"document": document,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Document moved successfully")
+}
+
+// Handles pinning a document to the top of the user's listing
+// @Summary Pin a document
+// @Description Pinned documents are listed before all others, each group in the requested sort order. Pins are per user and work for documents shared with the user.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "ALREADY_PINNED"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/pin [post]
+func (h *DocumentHandler) PinDocument(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	if err := h.documentService.PinDocument(c.Request.Context(), userID, documentID); err != nil {
+		if err.Error() == "document is already pinned" {
+			utils.ConflictResponse(c, "ALREADY_PINNED", "Document is already pinned")
+			return
+		}
+		status, code := h.mapServiceErrorToHTTP(err)
+		utils.ErrorResponse(c, status, code, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Document pinned successfully")
+}
+
+// Handles removing the user's pin from a document
+// @Summary Unpin a document
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "PIN_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/pin [delete]
+func (h *DocumentHandler) UnpinDocument(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	if err := h.documentService.UnpinDocument(c.Request.Context(), userID, documentID); err != nil {
+		if err.Error() == "document is not pinned" {
+			utils.NotFoundResponse(c, "PIN_NOT_FOUND", "Document is not pinned")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Document unpinned successfully")
+}
+
+// Handles copying a document into a new document owned by the user
+// @Summary Copy a document
+// @Description Requires download access to the source. The copy is titled "<title> (copy)", starts at version 1 and is not shared.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 201 {object} utils.ApiResponse{data=handlers.DocumentEnvelope}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "STORAGE_ERROR, COPY_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/copy [post]
+func (h *DocumentHandler) CopyDocument(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	document, err := h.documentService.CopyDocument(c.Request.Context(), userID, documentID, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch {
+		case err.Error() == "document expired":
+			utils.ErrorResponse(c, http.StatusGone, "DOCUMENT_EXPIRED", "Document has expired")
+		case strings.Contains(err.Error(), "document not found"):
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+		case strings.Contains(err.Error(), "in storage"):
+			utils.ErrorResponse(c, http.StatusInternalServerError, "STORAGE_ERROR", "The document's files could not be copied, no copy was created")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "COPY_FAILED", err.Error())
+		}
+		return
+	}
+
+	data := gin.H{
+		"document": document,
+	}
+	utils.SuccessResponse(c, http.StatusCreated, data, "Document copied successfully")
+}
+
 // Helper methods for HTTP layer
 
+// Describes a failed item of a bulk request with the code its error maps to
+func (h *DocumentHandler) newBulkFailure(documentID, filename string, err error) BulkFailure {
+	status, code := h.mapServiceErrorToHTTP(err)
+	failure := BulkFailure{
+		ID:       documentID,
+		Filename: filename,
+		Code:     code,
+		Status:   status,
+		Error:    err.Error(),
+	}
+
+	var duplicateErr *services.DuplicateDocumentError
+	if errors.As(err, &duplicateErr) {
+		failure.DuplicateOf = duplicateErr.Existing.ID.String()
+	}
+	return failure
+}
+
 // mapServiceErrorToHTTP maps service layer errors to appropriate HTTP status codes
+// Responds 410 when the document is past its expiration time, reports whether it did
+func documentExpired(c *gin.Context, err error) bool {
+	if err.Error() != "document expired" {
+		return false
+	}
+	utils.ErrorResponse(c, http.StatusGone, "DOCUMENT_EXPIRED", "Document has expired")
+	return true
+}
+
+// Responds when the virus scan withholds the document's file, 409 while the scan is pending
+// and 410 once the file was removed as infected. Reports whether it did.
+func documentWithheld(c *gin.Context, err error) bool {
+	switch err.Error() {
+	case "document is awaiting virus scan":
+		utils.ConflictResponse(c, "SCAN_PENDING", "Document is still being scanned for viruses, try again shortly")
+	case "document is quarantined":
+		utils.ErrorResponse(c, http.StatusGone, "DOCUMENT_QUARANTINED", "Document file was removed because it is infected")
+	default:
+		return false
+	}
+	return true
+}
+
 func (h *DocumentHandler) mapServiceErrorToHTTP(err error) (int, string) {
 	errorMsg := err.Error()
 
+	var duplicateErr *services.DuplicateDocumentError
+	if errors.As(err, &duplicateErr) {
+		return http.StatusConflict, "DUPLICATE_DOCUMENT"
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return http.StatusGatewayTimeout, "TIMEOUT"
+	}
+
+	// Request validation errors
+	if strings.Contains(errorMsg, "invalid document ID") {
+		return http.StatusBadRequest, "INVALID_DOCUMENT_ID"
+	}
+	if strings.Contains(errorMsg, "would exceed") {
+		return http.StatusBadRequest, "VALIDATION_ERROR"
+	}
+
 	// File validation errors
 	if strings.Contains(errorMsg, "file type not supported") {
 		return http.StatusBadRequest, "INVALID_FILE_TYPE"
@@ -886,17 +2444,29 @@ func (h *DocumentHandler) mapServiceErrorToHTTP(err error) (int, string) {
 	if strings.Contains(errorMsg, "file size too large") {
 		return http.StatusBadRequest, "FILE_TOO_LARGE"
 	}
+	if strings.Contains(errorMsg, "invalid custom metadata") {
+		return http.StatusBadRequest, "INVALID_CUSTOM_METADATA"
+	}
+	if strings.Contains(errorMsg, "OCR is not available") {
+		return http.StatusBadRequest, "OCR_UNAVAILABLE"
+	}
 
 	// Access control errors
+	if errorMsg == "document expired" {
+		return http.StatusGone, "DOCUMENT_EXPIRED"
+	}
 	if strings.Contains(errorMsg, "document not found") || strings.Contains(errorMsg, "access denied") {
 		return http.StatusNotFound, "DOCUMENT_NOT_FOUND"
 	}
 	if strings.Contains(errorMsg, "insufficient access") {
 		return http.StatusForbidden, "ACCESS_DENIED"
 	}
+	if errorMsg == "share download limit reached" {
+		return http.StatusForbidden, "DOWNLOAD_LIMIT_REACHED"
+	}
 
 	// Storage errors
-	if strings.Contains(errorMsg, "failed to upload") || strings.Contains(errorMsg, "storage") {
+	if strings.Contains(errorMsg, "failed to upload") || strings.Contains(errorMsg, "storage") || strings.Contains(errorMsg, "archive") {
 		return http.StatusInternalServerError, "STORAGE_ERROR"
 	}
 
@@ -909,19 +2479,20 @@ func (h *DocumentHandler) mapServiceErrorToHTTP(err error) (int, string) {
 	return http.StatusInternalServerError, "INTERNAL_ERROR"
 }
 
-// Creates ZIP file from document results
-func (h *DocumentHandler) createZipFromResults(resultChan chan documentResult, documentIDs []string) (*bytes.Buffer, int, int) {
+// Creates ZIP file from document results. Documents that could not be added are listed in a
+// _failures.json entry of the ZIP and returned.
+func (h *DocumentHandler) createZipFromResults(logger *logrus.Entry, resultChan chan documentResult, documentIDs []string) (*bytes.Buffer, int, []BulkFailure) {
 	var zipBuffer bytes.Buffer
 	zipWriter := zip.NewWriter(&zipBuffer)
 
 	successfulDownloads := 0
-	failedDownloads := 0
-	usedFilenames := make(map[string]bool)
+	failures := []BulkFailure{}
+	usedFilenames := map[string]bool{bulkDownloadFailuresEntry: true}
 
 	for result := range resultChan {
 		if result.error != nil {
-			failedDownloads++
-			logrus.Errorf("[BULK_DOWNLOAD] Error: %v", result.error)
+			failures = append(failures, h.newBulkFailure(result.documentID, "", result.error))
+			logger.WithError(result.error).WithField("document_id", result.documentID).Error("Bulk download skipped a document")
 			continue
 		}
 
@@ -941,29 +2512,49 @@ func (h *DocumentHandler) createZipFromResults(resultChan chan documentResult, d
 		// Create file entry in ZIP
 		fileWriter, err := zipWriter.Create(filename)
 		if err != nil {
-			failedDownloads++
-			logrus.Errorf("[BULK_DOWNLOAD] Failed to create ZIP entry for %s: %v", filename, err)
+			failures = append(failures, h.newBulkFailure(result.documentID, filename, fmt.Errorf("failed to create archive entry: %w", err)))
+			logger.WithError(err).WithField("filename", filename).Error("Bulk download failed to create ZIP entry")
 			continue
 		}
 
 		// Write file content
 		_, err = fileWriter.Write(result.content)
 		if err != nil {
-			failedDownloads++
-			logrus.Errorf("[BULK_DOWNLOAD] Failed to write file content to ZIP for %s: %v", filename, err)
+			failures = append(failures, h.newBulkFailure(result.documentID, filename, fmt.Errorf("failed to write archive entry: %w", err)))
+			logger.WithError(err).WithField("filename", filename).Error("Bulk download failed to write ZIP entry")
 			continue
 		}
 
 		successfulDownloads++
 	}
 
+	if len(failures) > 0 {
+		if data, err := json.MarshalIndent(failures, "", "  "); err != nil {
+			logger.WithError(err).Error("Bulk download failed to encode failures")
+		} else if fileWriter, err := zipWriter.Create(bulkDownloadFailuresEntry); err != nil {
+			logger.WithError(err).Error("Bulk download failed to create failures entry")
+		} else if _, err := fileWriter.Write(data); err != nil {
+			logger.WithError(err).Error("Bulk download failed to write failures entry")
+		}
+	}
+
 	// Close ZIP writer
 	zipWriter.Close()
 
-	return &zipBuffer, successfulDownloads, failedDownloads
+	return &zipBuffer, successfulDownloads, failures
 }
 
 // GetUserProcessingQueue handles retrieving user's processing queue
+// @Summary List processing tasks of the current user
+// @Tags documents
+// @Produce json
+// @Param limit query int false "Page size, at most 50" default(10)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} utils.ApiResponse{data=handlers.ProcessingQueueResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/processing-queue [get]
 func (h *DocumentHandler) GetUserProcessingQueue(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -1043,6 +2634,18 @@ func (h *DocumentHandler) GetUserProcessingQueue(c *gin.Context) {
 }
 
 // GetDocumentProcessingStatus handles retrieving processing status for a specific document
+// @Summary Get the processing progress of a document
+// @Description Besides the task progress, status, scan_status, processing_error and file_withheld report the document's own state, file_withheld is true while the virus scan keeps the file from being served.
+// @Tags documents
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=object}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/processing-status [get]
 func (h *DocumentHandler) GetDocumentProcessingStatus(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -1058,8 +2661,11 @@ func (h *DocumentHandler) GetDocumentProcessingStatus(c *gin.Context) {
 	}
 
 	// Verify user owns the document
-	_, err = h.documentService.GetDocument(c.Request.Context(), userID, documentID)
+	document, err := h.documentService.GetDocument(c.Request.Context(), userID, documentID, c.ClientIP(), c.GetHeader("User-Agent"))
 	if err != nil {
+		if documentExpired(c, err) {
+			return
+		}
 		if strings.Contains(err.Error(), "document not found") || strings.Contains(err.Error(), "access denied") {
 			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
 			return
@@ -1075,5 +2681,11 @@ func (h *DocumentHandler) GetDocumentProcessingStatus(c *gin.Context) {
 		return
 	}
 
+	// The worker's own steps, the virus scan and previews, are tracked on the document
+	progress["status"] = document.Status
+	progress["scan_status"] = document.ScanStatus
+	progress["processing_error"] = document.ProcessingError
+	progress["file_withheld"] = document.FileWithheld
+
 	utils.SuccessResponse(c, http.StatusOK, progress, "Document processing status retrieved successfully")
 }
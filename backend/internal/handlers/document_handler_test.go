@@ -0,0 +1,136 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/repositories/postgres"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/testutil"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestDownloadDocumentShareLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := testutil.NewPostgresDB(t,
+		&models.Permission{}, &models.Role{}, &models.User{}, &models.Folder{}, &models.Document{},
+		&models.UserShare{}, &models.Group{}, &models.GroupMember{}, &models.GroupShare{},
+		&models.DocumentActivity{},
+	)
+	owner := testutil.CreateUser(t, db, "owner")
+	recipient := testutil.CreateUser(t, db, "recipient")
+
+	content := []byte("quarterly numbers")
+	document := &models.Document{
+		Title:            "Report",
+		FileName:         "report.txt",
+		OriginalFileName: "report.txt",
+		FileSize:         int64(len(content)),
+		FileType:         models.DocumentTypeTXT,
+		MimeType:         "text/plain",
+		Status:           models.DocumentStatusReady,
+		StoragePath:      "users/" + owner.ID.String() + "/documents/report.txt",
+		StorageBucket:    "documents",
+		UserID:           owner.ID,
+	}
+	if err := db.Create(document).Error; err != nil {
+		t.Fatalf("failed to create document: %v", err)
+	}
+	uploader := testutil.NewMockUploader()
+	uploader.Put(document.StoragePath, content, document.MimeType)
+
+	maxDownloads := 1
+	share := &models.UserShare{
+		DocumentID:       document.ID,
+		OwnerID:          owner.ID,
+		SharedWithEmail:  recipient.Email,
+		SharedWithUserID: &recipient.ID,
+		AccessLevel:      models.AccessLevelDownload,
+		MaxDownloads:     &maxDownloads,
+	}
+	if err := db.Create(share).Error; err != nil {
+		t.Fatalf("failed to create share: %v", err)
+	}
+
+	documentRepo := postgres.NewDocumentRepository(db)
+	userShareService := services.NewUserShareService(db, nil, nil, "")
+	documentService := services.NewDocumentService(
+		documentRepo, nil, uploader, userShareService, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		services.NewDocumentCounter(documentRepo, nil, 0, 0), nil,
+		config.PreviewConfig{}, config.RevisionConfig{}, config.IntegrityConfig{}, config.ScanConfig{},
+		db,
+	)
+	handler := handlers.NewDocumentHandler(documentService, uploader, userShareService, nil, nil, config.BulkConfig{})
+
+	download := func(userID uuid.UUID, byteRange string) (int, string) {
+		t.Helper()
+		router := gin.New()
+		router.GET("/documents/:id/download", func(c *gin.Context) {
+			c.Set("userID", userID)
+		}, validations.ValidateDocumentID(), handler.DownloadDocument)
+
+		req := httptest.NewRequest(http.MethodGet, "/documents/"+document.ID.String()+"/download", nil)
+		if byteRange != "" {
+			req.Header.Set("Range", byteRange)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var response utils.ApiResponse
+		if recorder.Code >= http.StatusBadRequest {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+				t.Fatalf("unreadable error response %q: %v", recorder.Body.String(), err)
+			}
+			return recorder.Code, response.Error.Code
+		}
+		return recorder.Code, ""
+	}
+	downloadCount := func() int {
+		t.Helper()
+		var stored models.UserShare
+		if err := db.First(&stored, "id = ?", share.ID).Error; err != nil {
+			t.Fatalf("failed to load share: %v", err)
+		}
+		return stored.DownloadCount
+	}
+
+	// A later range with a download left is served without using it up
+	if status, code := download(recipient.ID, "bytes=1-"); status != http.StatusPartialContent {
+		t.Fatalf("ranged download with a download left: status %d %s, want 206", status, code)
+	}
+	if count := downloadCount(); count != 0 {
+		t.Fatalf("download count after a later range = %d, want 0", count)
+	}
+
+	// Reading from the first byte uses up the only download
+	if status, code := download(recipient.ID, ""); status != http.StatusOK {
+		t.Fatalf("full download: status %d %s, want 200", status, code)
+	}
+	if count := downloadCount(); count != 1 {
+		t.Fatalf("download count after a full download = %d, want 1", count)
+	}
+
+	// With the share used up every request is refused, ranged ones included
+	for _, byteRange := range []string{"", "bytes=0-", "bytes=1-", "bytes=5-9"} {
+		if status, code := download(recipient.ID, byteRange); status != http.StatusForbidden || code != "DOWNLOAD_LIMIT_REACHED" {
+			t.Fatalf("download of exhausted share with range %q: status %d %s, want 403 DOWNLOAD_LIMIT_REACHED", byteRange, status, code)
+		}
+	}
+	if count := downloadCount(); count != 1 {
+		t.Fatalf("download count after refused downloads = %d, want 1", count)
+	}
+
+	// The owner is never limited
+	if status, code := download(owner.ID, "bytes=1-"); status != http.StatusPartialContent {
+		t.Fatalf("owner ranged download: status %d %s, want 206", status, code)
+	}
+}
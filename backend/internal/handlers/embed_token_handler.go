@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type EmbedTokenHandler struct {
+	embedTokenService *services.EmbedTokenService
+}
+
+func NewEmbedTokenHandler(embedTokenService *services.EmbedTokenService) *EmbedTokenHandler {
+	return &EmbedTokenHandler{embedTokenService: embedTokenService}
+}
+
+// Handles minting an embed token for a document
+// @Summary Create an embed token for a document
+// @Description Only the owner can create tokens. The token grants view-only access to the preview and thumbnail endpoints under /api/v1/embed, sent as a bearer token or the token query parameter. It is returned only in this response.
+// @Tags embed
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param request body types.CreateEmbedTokenRequest false "Token name and lifetime in seconds"
+// @Success 201 {object} utils.ApiResponse{data=types.EmbedTokenCreatedResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_BODY, INVALID_EXPIRES_IN"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "DOCUMENT_EXPIRED"
+// @Failure 500 {object} utils.ApiResponse "CREATE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/embed-token [post]
+func (h *EmbedTokenHandler) CreateEmbedToken(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	// The body is optional, every field has a default
+	var req types.CreateEmbedTokenRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_BODY", "Body must be a JSON object with an optional name of at most 100 characters and expiresIn in seconds")
+			return
+		}
+	}
+
+	embedToken, token, err := h.embedTokenService.CreateToken(c.Request.Context(), userID, documentID, req.Name, time.Duration(req.ExpiresIn)*time.Second)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "exceeds the maximum"):
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_EXPIRES_IN", err.Error())
+		case err.Error() == "document not found":
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+		case err.Error() == "document expired":
+			utils.ErrorResponse(c, http.StatusGone, "DOCUMENT_EXPIRED", "Document has expired")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "CREATE_FAILED", err.Error())
+		}
+		return
+	}
+
+	data := types.EmbedTokenCreatedResponse{Token: token, EmbedToken: *embedToken}
+	utils.SuccessResponse(c, http.StatusCreated, data, "Embed token created")
+}
+
+// Handles listing the embed tokens of a document
+// @Summary List the active embed tokens of a document
+// @Description Tokens that are revoked or expired are left out. The tokens themselves are never returned again.
+// @Tags embed
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.EmbedTokensResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/embed-tokens [get]
+func (h *EmbedTokenHandler) GetEmbedTokens(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	tokens, err := h.embedTokenService.ListTokens(c.Request.Context(), userID, documentID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"embedTokens": tokens}, "Embed tokens retrieved successfully")
+}
+
+// Handles revoking an embed token
+// @Summary Revoke an embed token
+// @Tags embed
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param tokenId path string true "Embed token ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_TOKEN_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "EMBED_TOKEN_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "REVOKE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/embed-tokens/{tokenId} [delete]
+func (h *EmbedTokenHandler) RevokeEmbedToken(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	tokenID, err := uuid.Parse(c.Param("tokenId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_TOKEN_ID", "invalid embed token id")
+		return
+	}
+
+	if err := h.embedTokenService.RevokeToken(c.Request.Context(), userID, documentID, tokenID); err != nil {
+		if err.Error() == "embed token not found" {
+			utils.NotFoundResponse(c, "EMBED_TOKEN_NOT_FOUND", "Embed token not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "REVOKE_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Embed token revoked successfully")
+}
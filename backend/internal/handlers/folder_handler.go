@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+)
+
+type FolderHandler struct {
+	folderService *services.FolderService
+}
+
+func NewFolderHandler(folderService *services.FolderService) *FolderHandler {
+	return &FolderHandler{
+		folderService: folderService,
+	}
+}
+
+// Lists the folders inside a folder or at the root
+// @Summary List folders
+// @Description Documents of a folder are listed through GET /documents with folderId.
+// @Tags folders
+// @Produce json
+// @Param parentId query string false "Parent folder ID, omitted or root for the top level folders"
+// @Success 200 {object} utils.ApiResponse{data=handlers.FoldersResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_FOLDER_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "FOLDER_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/folders [get]
+func (h *FolderHandler) GetFolders(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	parentID, ok := validations.GetValidatedFolderParent(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated parent folder")
+		return
+	}
+
+	folders, err := h.folderService.ListFolders(c.Request.Context(), userID, parentID)
+	if err != nil {
+		if err.Error() == "folder not found" {
+			utils.NotFoundResponse(c, "FOLDER_NOT_FOUND", "Folder not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to fetch folders")
+		return
+	}
+
+	data := gin.H{
+		"folders": folders,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Folders retrieved successfully")
+}
+
+// @Summary Get a folder
+// @Tags folders
+// @Produce json
+// @Param id path string true "Folder ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.FolderEnvelope}
+// @Failure 400 {object} utils.ApiResponse "INVALID_FOLDER_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "FOLDER_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/folders/{id} [get]
+func (h *FolderHandler) GetFolder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	folderID, ok := validations.GetValidatedFolderID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated folder ID")
+		return
+	}
+
+	folder, err := h.folderService.GetFolder(c.Request.Context(), userID, folderID)
+	if err != nil {
+		if err.Error() == "folder not found" {
+			utils.NotFoundResponse(c, "FOLDER_NOT_FOUND", "Folder not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to fetch folder")
+		return
+	}
+
+	data := gin.H{
+		"folder": folder,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Folder retrieved successfully")
+}
+
+// Creates a folder at the root or inside another folder
+// @Summary Create a folder
+// @Description Folder names are unique within their parent, ignoring case.
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param body body types.CreateFolderRequest true "Name and parent folder"
+// @Success 201 {object} utils.ApiResponse{data=handlers.FolderEnvelope}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "PARENT_FOLDER_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "FOLDER_EXISTS"
+// @Failure 500 {object} utils.ApiResponse "CREATION_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/folders [post]
+func (h *FolderHandler) CreateFolder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	req, ok := validations.GetValidatedFolderCreate(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	folder, err := h.folderService.CreateFolder(c.Request.Context(), userID, req)
+	if err != nil {
+		switch err.Error() {
+		case "parent folder not found":
+			utils.NotFoundResponse(c, "PARENT_FOLDER_NOT_FOUND", "Parent folder not found")
+		case "folder name already exists":
+			utils.ConflictResponse(c, "FOLDER_EXISTS", "A folder with this name already exists here")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "CREATION_FAILED", "Failed to create folder")
+		}
+		return
+	}
+
+	data := gin.H{
+		"folder": folder,
+	}
+	utils.SuccessResponse(c, http.StatusCreated, data, "Folder created successfully")
+}
+
+// Renames a folder or moves it under another folder
+// @Summary Rename or move a folder
+// @Description A folder can't be moved into itself or one of its subfolders.
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID" format(uuid)
+// @Param body body types.UpdateFolderRequest true "New name and/or parent"
+// @Success 200 {object} utils.ApiResponse{data=handlers.FolderEnvelope}
+// @Failure 400 {object} utils.ApiResponse "INVALID_FOLDER_ID, VALIDATION_ERROR, FOLDER_CYCLE"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "FOLDER_NOT_FOUND, PARENT_FOLDER_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "FOLDER_EXISTS"
+// @Failure 500 {object} utils.ApiResponse "UPDATE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/folders/{id} [patch]
+func (h *FolderHandler) UpdateFolder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	folderID, ok := validations.GetValidatedFolderID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated folder ID")
+		return
+	}
+
+	req, ok := validations.GetValidatedFolderUpdate(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	folder, err := h.folderService.UpdateFolder(c.Request.Context(), userID, folderID, req)
+	if err != nil {
+		switch err.Error() {
+		case "folder not found":
+			utils.NotFoundResponse(c, "FOLDER_NOT_FOUND", "Folder not found")
+		case "parent folder not found":
+			utils.NotFoundResponse(c, "PARENT_FOLDER_NOT_FOUND", "Parent folder not found")
+		case "folder can't be moved into itself or its subfolders":
+			utils.ErrorResponse(c, http.StatusBadRequest, "FOLDER_CYCLE", "A folder can't be moved into itself or one of its subfolders")
+		case "folder name already exists":
+			utils.ConflictResponse(c, "FOLDER_EXISTS", "A folder with this name already exists here")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update folder")
+		}
+		return
+	}
+
+	data := gin.H{
+		"folder": folder,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Folder updated successfully")
+}
+
+// Deletes an empty folder
+// @Summary Delete a folder
+// @Description Only empty folders can be deleted, move or delete their documents and subfolders first.
+// @Tags folders
+// @Produce json
+// @Param id path string true "Folder ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_FOLDER_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "FOLDER_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "FOLDER_NOT_EMPTY"
+// @Failure 500 {object} utils.ApiResponse "DELETE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/folders/{id} [delete]
+func (h *FolderHandler) DeleteFolder(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	folderID, ok := validations.GetValidatedFolderID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated folder ID")
+		return
+	}
+
+	if err := h.folderService.DeleteFolder(c.Request.Context(), userID, folderID); err != nil {
+		switch err.Error() {
+		case "folder not found":
+			utils.NotFoundResponse(c, "FOLDER_NOT_FOUND", "Folder not found")
+		case "folder is not empty":
+			utils.ConflictResponse(c, "FOLDER_NOT_EMPTY", "Folder still holds documents or subfolders")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "DELETE_FAILED", "Failed to delete folder")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Folder deleted successfully")
+}
@@ -0,0 +1,513 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+)
+
+type GroupHandler struct {
+	groupService *services.GroupService
+}
+
+func NewGroupHandler(groupService *services.GroupService) *GroupHandler {
+	return &GroupHandler{
+		groupService: groupService,
+	}
+}
+
+// Lists the groups the user owns or belongs to
+// @Summary List groups
+// @Tags groups
+// @Produce json
+// @Success 200 {object} utils.ApiResponse{data=handlers.GroupsResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/groups [get]
+func (h *GroupHandler) GetGroups(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	groups, err := h.groupService.ListGroups(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to fetch groups")
+		return
+	}
+
+	data := gin.H{
+		"groups": groups,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Groups retrieved successfully")
+}
+
+// Returns a group with its members
+// @Summary Get a group
+// @Description Only members of the group can see it.
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.GroupEnvelope}
+// @Failure 400 {object} utils.ApiResponse "INVALID_GROUP_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "GROUP_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/groups/{id} [get]
+func (h *GroupHandler) GetGroup(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	groupID, ok := validations.GetValidatedGroupID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated group ID")
+		return
+	}
+
+	group, err := h.groupService.GetGroup(c.Request.Context(), userID, groupID)
+	if err != nil {
+		if err.Error() == "group not found" {
+			utils.NotFoundResponse(c, "GROUP_NOT_FOUND", "Group not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to fetch group")
+		return
+	}
+
+	data := gin.H{
+		"group": group,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Group retrieved successfully")
+}
+
+// Creates a group owned by the user
+// @Summary Create a group
+// @Description The owner becomes the first member. Group names are unique per owner, ignoring case.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param body body types.CreateGroupRequest true "Name and description"
+// @Success 201 {object} utils.ApiResponse{data=handlers.GroupEnvelope}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 409 {object} utils.ApiResponse "GROUP_EXISTS"
+// @Failure 500 {object} utils.ApiResponse "CREATION_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/groups [post]
+func (h *GroupHandler) CreateGroup(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	req, ok := validations.GetValidatedGroupCreate(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	group, err := h.groupService.CreateGroup(c.Request.Context(), userID, req)
+	if err != nil {
+		if err.Error() == "group name already exists" {
+			utils.ConflictResponse(c, "GROUP_EXISTS", "You already have a group with this name")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "CREATION_FAILED", "Failed to create group")
+		return
+	}
+
+	data := gin.H{
+		"group": group,
+	}
+	utils.SuccessResponse(c, http.StatusCreated, data, "Group created successfully")
+}
+
+// Renames a group or changes its description
+// @Summary Update a group
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Param body body types.UpdateGroupRequest true "New name and/or description"
+// @Success 200 {object} utils.ApiResponse{data=handlers.GroupEnvelope}
+// @Failure 400 {object} utils.ApiResponse "INVALID_GROUP_ID, VALIDATION_ERROR"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "NOT_GROUP_OWNER"
+// @Failure 404 {object} utils.ApiResponse "GROUP_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "GROUP_EXISTS"
+// @Failure 500 {object} utils.ApiResponse "UPDATE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/groups/{id} [patch]
+func (h *GroupHandler) UpdateGroup(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	groupID, ok := validations.GetValidatedGroupID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated group ID")
+		return
+	}
+
+	req, ok := validations.GetValidatedGroupUpdate(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	group, err := h.groupService.UpdateGroup(c.Request.Context(), userID, groupID, req)
+	if err != nil {
+		if !groupError(c, err) {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update group")
+		}
+		return
+	}
+
+	data := gin.H{
+		"group": group,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Group updated successfully")
+}
+
+// Deletes a group and its document shares
+// @Summary Delete a group
+// @Description Members lose every access they had through the group.
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_GROUP_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "NOT_GROUP_OWNER"
+// @Failure 404 {object} utils.ApiResponse "GROUP_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "DELETE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/groups/{id} [delete]
+func (h *GroupHandler) DeleteGroup(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	groupID, ok := validations.GetValidatedGroupID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated group ID")
+		return
+	}
+
+	if err := h.groupService.DeleteGroup(c.Request.Context(), userID, groupID); err != nil {
+		if !groupError(c, err) {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "DELETE_FAILED", "Failed to delete group")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Group deleted successfully")
+}
+
+// Adds a registered user to a group
+// @Summary Add a group member
+// @Description Only the owner can add members, the user is found by email or username.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Param body body types.AddGroupMemberRequest true "Email or username"
+// @Success 201 {object} utils.ApiResponse{data=handlers.GroupMemberEnvelope}
+// @Failure 400 {object} utils.ApiResponse "INVALID_GROUP_ID, VALIDATION_ERROR"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "NOT_GROUP_OWNER"
+// @Failure 404 {object} utils.ApiResponse "GROUP_NOT_FOUND, USER_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "ALREADY_MEMBER"
+// @Failure 500 {object} utils.ApiResponse "ADD_MEMBER_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/groups/{id}/members [post]
+func (h *GroupHandler) AddMember(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	groupID, ok := validations.GetValidatedGroupID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated group ID")
+		return
+	}
+
+	req, ok := validations.GetValidatedGroupMember(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	member, err := h.groupService.AddMember(c.Request.Context(), userID, groupID, req.Identifier)
+	if err != nil {
+		if !groupError(c, err) {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "ADD_MEMBER_FAILED", "Failed to add group member")
+		}
+		return
+	}
+
+	data := gin.H{
+		"member": member,
+	}
+	utils.SuccessResponse(c, http.StatusCreated, data, "Group member added successfully")
+}
+
+// Removes a member from a group, or lets a member leave it
+// @Summary Remove a group member
+// @Description The owner can remove any other member, members can remove themselves. The owner can't leave their own group.
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Param userId path string true "User ID of the member" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_GROUP_ID, INVALID_USER_ID, OWNER_NOT_REMOVABLE"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} utils.ApiResponse "NOT_GROUP_OWNER"
+// @Failure 404 {object} utils.ApiResponse "GROUP_NOT_FOUND, MEMBER_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "REMOVE_MEMBER_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/groups/{id}/members/{userId} [delete]
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	groupID, ok := validations.GetValidatedGroupID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated group ID")
+		return
+	}
+
+	memberID, ok := validations.GetValidatedGroupMemberID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated user ID")
+		return
+	}
+
+	if err := h.groupService.RemoveMember(c.Request.Context(), userID, groupID, memberID); err != nil {
+		if !groupError(c, err) {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "REMOVE_MEMBER_FAILED", "Failed to remove group member")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Group member removed successfully")
+}
+
+// Lists the documents shared with a group
+// @Summary List group documents
+// @Description Active shares with the group, with their documents. Only members can list them.
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.GroupSharesResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_GROUP_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "GROUP_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/groups/{id}/documents [get]
+func (h *GroupHandler) GetGroupDocuments(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	groupID, ok := validations.GetValidatedGroupID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated group ID")
+		return
+	}
+
+	shares, err := h.groupService.ListGroupDocuments(c.Request.Context(), userID, groupID)
+	if err != nil {
+		if !groupError(c, err) {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to fetch group documents")
+		}
+		return
+	}
+
+	data := gin.H{
+		"shares": shares,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Group documents retrieved successfully")
+}
+
+// Shares a document with every member of a group
+// @Summary Share a document with a group
+// @Description The document owner must belong to the group. Members added later gain access too, sharing again with the same group updates the existing share.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param body body types.ShareWithGroupRequest true "Group, access level and expiration"
+// @Success 201 {object} utils.ApiResponse{data=handlers.GroupShareEnvelope}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND, GROUP_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "SHARE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/share/groups [post]
+func (h *GroupHandler) ShareDocument(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	req, ok := validations.GetValidatedGroupShare(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	share, err := h.groupService.ShareDocument(c.Request.Context(), userID, documentID, req)
+	if err != nil {
+		if !groupError(c, err) {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "SHARE_FAILED", "Failed to share document with group")
+		}
+		return
+	}
+
+	data := gin.H{
+		"share": share,
+	}
+	utils.SuccessResponse(c, http.StatusCreated, data, "Document shared with group")
+}
+
+// Lists the groups a document is shared with
+// @Summary List group shares of a document
+// @Tags groups
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.GroupSharesResponse}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "DOCUMENT_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/share/groups [get]
+func (h *GroupHandler) GetDocumentGroupShares(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	shares, err := h.groupService.ListDocumentGroupShares(c.Request.Context(), userID, documentID)
+	if err != nil {
+		if !groupError(c, err) {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to fetch group shares")
+		}
+		return
+	}
+
+	data := gin.H{
+		"shares": shares,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Group shares retrieved successfully")
+}
+
+// Revokes a document's share with a group
+// @Summary Revoke a group share
+// @Description Every member loses the access they had through the group at once.
+// @Tags groups
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param groupId path string true "Group ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR, INVALID_GROUP_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "GROUP_SHARE_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "REVOKE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/share/groups/{groupId} [delete]
+func (h *GroupHandler) RevokeGroupShare(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	documentID, ok := validations.GetValidatedDocumentID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated document ID")
+		return
+	}
+
+	groupID, ok := validations.GetValidatedSharedGroupID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated group ID")
+		return
+	}
+
+	if err := h.groupService.RevokeGroupShare(c.Request.Context(), userID, documentID, groupID); err != nil {
+		if !groupError(c, err) {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "REVOKE_FAILED", "Failed to revoke group share")
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Group share revoked successfully")
+}
+
+// Responds to the errors shared by the group endpoints, reports whether it did
+func groupError(c *gin.Context, err error) bool {
+	switch err.Error() {
+	case "group not found":
+		utils.NotFoundResponse(c, "GROUP_NOT_FOUND", "Group not found")
+	case "only the group owner can manage the group":
+		utils.ForbiddenResponse(c, "NOT_GROUP_OWNER", "Only the group owner can do this")
+	case "group name already exists":
+		utils.ConflictResponse(c, "GROUP_EXISTS", "You already have a group with this name")
+	case "user not found":
+		utils.NotFoundResponse(c, "USER_NOT_FOUND", "No user with this email or username")
+	case "user is already a member":
+		utils.ConflictResponse(c, "ALREADY_MEMBER", "User is already a member of the group")
+	case "member not found":
+		utils.NotFoundResponse(c, "MEMBER_NOT_FOUND", "User is not a member of the group")
+	case "group owner can't be removed":
+		utils.ErrorResponse(c, http.StatusBadRequest, "OWNER_NOT_REMOVABLE", "The group owner can't be removed, delete the group instead")
+	case "document not found or not owned by user":
+		utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+	case "group share not found":
+		utils.NotFoundResponse(c, "GROUP_SHARE_NOT_FOUND", "Document is not shared with this group")
+	case "invalid access level":
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_ACCESS_LEVEL", "Access level must be 'view', 'download', or 'edit'")
+	default:
+		return false
+	}
+	return true
+}
@@ -1,20 +1,53 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
 	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+// Upper bounds for the dependency probes so a hung dependency can't stall the check.
+// Readiness is polled by the orchestrator and has to answer well within its probe timeout.
+const (
+	dependencyCheckTimeout = 3 * time.Second
+	readinessCheckTimeout  = time.Second
+)
+
+type dependencyProbe func(ctx context.Context) gin.H
+
 type HealthHandler struct {
-	db *gorm.DB
+	db           *gorm.DB
+	redisClient  *redis.Client // nil when Redis was unreachable at startup
+	minioService *services.MinIOService
+	imageMagick  *services.ImageMagick // nil when ImageMagick is not installed
+	libreOffice  *services.LibreOffice // nil when LibreOffice is not installed
+	clamAV       *services.ClamAV      // nil when virus scanning is off
 }
 
-func NewHealthHandler(db *gorm.DB) *HealthHandler {
-	return &HealthHandler{db: db}
+func NewHealthHandler(
+	db *gorm.DB,
+	redisClient *redis.Client,
+	minioService *services.MinIOService,
+	imageMagick *services.ImageMagick,
+	libreOffice *services.LibreOffice,
+	clamAV *services.ClamAV,
+) *HealthHandler {
+	return &HealthHandler{
+		db:           db,
+		redisClient:  redisClient,
+		minioService: minioService,
+		imageMagick:  imageMagick,
+		libreOffice:  libreOffice,
+		clamAV:       clamAV,
+	}
 }
 
 // HealthCheck performs a comprehensive health check
@@ -40,22 +73,38 @@ func (h *HealthHandler) HealthCheck(c *gin.Context) {
 	utils.SuccessResponse(c, statusCode, data)
 }
 
-// ReadinessCheck checks if the service is ready to serve requests
+// ReadinessCheck checks if the service is ready to serve requests. Postgres, Redis and
+// MinIO are probed concurrently and all of them have to answer, otherwise it is 503 so the
+// orchestrator keeps traffic away until the connections are up.
 func (h *HealthHandler) ReadinessCheck(c *gin.Context) {
-	// Check if all dependencies are ready
-	dbStatus := h.checkDatabase()
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+	defer cancel()
 
-	if dbStatus["status"] == "up" {
-		data := gin.H{
-			"status": "ready",
+	checks := runDependencyProbes(ctx, map[string]dependencyProbe{
+		"postgres": h.checkPostgres,
+		"redis":    h.checkRedis,
+		"minio":    h.checkMinIO,
+	})
+
+	for _, check := range checks {
+		if check["status"] != "up" {
+			utils.ErrorResponseWithData(c, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", "Service is not ready", gin.H{
+				"status": "not_ready",
+				"checks": checks,
+			})
+			return
 		}
-		utils.SuccessResponse(c, http.StatusOK, data, "Service is ready")
-	} else {
-		utils.ServiceUnavailableResponse(c, "Service is not ready")
 	}
+
+	data := gin.H{
+		"status": "ready",
+		"checks": checks,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Service is ready")
 }
 
-// LivenessCheck checks if the service is alive
+// LivenessCheck checks if the service is alive. It never touches dependencies, an outage
+// elsewhere must not get the process restarted.
 func (h *HealthHandler) LivenessCheck(c *gin.Context) {
 	data := gin.H{
 		"status": "alive",
@@ -97,3 +146,160 @@ func (h *HealthHandler) checkDatabase() map[string]interface{} {
 		},
 	}
 }
+
+// DependencyCheck reports the status and version of every external dependency.
+// Postgres and MinIO are required and answer 503 when down. Redis, ImageMagick, LibreOffice
+// and ClamAV only disable features, the service is reported as degraded without them.
+// ClamAV is left out when scanning is not configured.
+func (h *HealthHandler) DependencyCheck(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), dependencyCheckTimeout)
+	defer cancel()
+
+	probes := map[string]dependencyProbe{
+		"postgres": h.checkPostgres,
+		"minio":    h.checkMinIO,
+		"redis":    h.checkRedis,
+	}
+	if h.clamAV != nil {
+		probes["clamav"] = h.checkClamAV
+	}
+	dependencies := runDependencyProbes(ctx, probes)
+	dependencies["imagemagick"] = toolStatus(h.imageMagick.Path(), h.imageMagick.Version())
+	dependencies["libreoffice"] = toolStatus(h.libreOffice.Path(), h.libreOffice.Version())
+
+	status := "healthy"
+	statusCode := http.StatusOK
+	for _, check := range dependencies {
+		if check["status"] == "up" {
+			continue
+		}
+		if check["required"] == true {
+			status = "unhealthy"
+			statusCode = http.StatusServiceUnavailable
+			break
+		}
+		status = "degraded"
+	}
+
+	data := gin.H{
+		"status":       status,
+		"dependencies": dependencies,
+	}
+
+	utils.SuccessResponse(c, statusCode, data)
+}
+
+// Runs the probes concurrently, each one is bounded by the context deadline
+func runDependencyProbes(ctx context.Context, probes map[string]dependencyProbe) map[string]gin.H {
+	results := make(map[string]gin.H, len(probes))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, probe := range probes {
+		wg.Add(1)
+		go func(name string, probe dependencyProbe) {
+			defer wg.Done()
+			result := probe(ctx)
+
+			mu.Lock()
+			results[name] = result
+			mu.Unlock()
+		}(name, probe)
+	}
+
+	wg.Wait()
+	return results
+}
+
+func (h *HealthHandler) checkPostgres(ctx context.Context) gin.H {
+	start := time.Now()
+	result := gin.H{"required": true}
+
+	var version string
+	if err := h.db.WithContext(ctx).Raw("SHOW server_version").Scan(&version).Error; err != nil {
+		result["status"] = "down"
+		result["error"] = "database query failed"
+	} else {
+		result["status"] = "up"
+		result["version"] = version
+	}
+
+	result["response_time"] = time.Since(start).Milliseconds()
+	return result
+}
+
+func (h *HealthHandler) checkMinIO(ctx context.Context) gin.H {
+	start := time.Now()
+	result := gin.H{"required": true}
+
+	if h.minioService == nil {
+		result["status"] = "down"
+		result["error"] = "minio is not configured"
+	} else if err := h.minioService.HealthCheck(ctx); err != nil {
+		result["status"] = "down"
+		result["error"] = err.Error()
+	} else {
+		result["status"] = "up"
+	}
+
+	result["response_time"] = time.Since(start).Milliseconds()
+	return result
+}
+
+func (h *HealthHandler) checkRedis(ctx context.Context) gin.H {
+	start := time.Now()
+	result := gin.H{"required": false}
+
+	if h.redisClient == nil {
+		result["status"] = "down"
+		result["error"] = "redis was unreachable at startup"
+		return result
+	}
+
+	info, err := h.redisClient.Client.Info(ctx, "server").Result()
+	if err != nil {
+		result["status"] = "down"
+		result["error"] = "redis ping failed"
+	} else {
+		result["status"] = "up"
+		result["version"] = redisVersion(info)
+	}
+
+	result["response_time"] = time.Since(start).Milliseconds()
+	return result
+}
+
+// Uploads queue up unscanned while clamd is down, they are scanned once it is back
+func (h *HealthHandler) checkClamAV(ctx context.Context) gin.H {
+	start := time.Now()
+	result := gin.H{"required": false}
+
+	if err := h.clamAV.Ping(ctx); err != nil {
+		result["status"] = "down"
+		result["error"] = "clamav ping failed"
+	} else {
+		result["status"] = "up"
+		result["version"] = h.clamAV.Version()
+	}
+
+	result["response_time"] = time.Since(start).Milliseconds()
+	return result
+}
+
+// External binaries are resolved once at startup, a restart is needed after installing them
+func toolStatus(path, version string) gin.H {
+	if path == "" {
+		return gin.H{"status": "missing", "required": false}
+	}
+	return gin.H{"status": "up", "required": false, "path": path, "version": version}
+}
+
+// Picks redis_version out of the INFO server section
+func redisVersion(info string) string {
+	for _, line := range strings.Split(info, "\n") {
+		if version, ok := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); ok {
+			return version
+		}
+	}
+	return ""
+}
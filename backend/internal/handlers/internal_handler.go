@@ -80,6 +80,7 @@ func (h *InternalHandler) UpdateExtractedText(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update document")
 		return
 	}
+	h.documentService.InvalidateSearchCache(document.UserID)
 
 	utils.SuccessResponse(c, http.StatusOK, gin.H{
 		"document_id": documentID,
@@ -103,11 +104,19 @@ func (h *InternalHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
+	// Find document, the owner's cached search results show its status
+	var document models.Document
+	if err := h.db.Where("id = ?", documentID).First(&document).Error; err != nil {
+		utils.ErrorResponse(c, http.StatusNotFound, "DOCUMENT_NOT_FOUND", "Document not found")
+		return
+	}
+
 	// Update status
-	if err := h.db.Model(&models.Document{}).Where("id = ?", documentID).Update("status", req.Status).Error; err != nil {
+	if err := h.db.Model(&document).Update("status", req.Status).Error; err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update document status")
 		return
 	}
+	h.documentService.InvalidateSearchCache(document.UserID)
 
 	utils.SuccessResponse(c, http.StatusOK, gin.H{
 		"document_id": documentID,
@@ -147,6 +156,7 @@ func (h *InternalHandler) UpdateSummary(c *gin.Context) {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update document summary")
 		return
 	}
+	h.documentService.InvalidateSearchCache(document.UserID)
 
 	utils.SuccessResponse(c, http.StatusOK, gin.H{
 		"document_id": documentID,
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type QuotaHandler struct {
+	quotaService *services.QuotaService
+}
+
+func NewQuotaHandler(quotaService *services.QuotaService) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: quotaService,
+	}
+}
+
+// GetMyQuota returns the current user's usage, limits and plan status
+func (h *QuotaHandler) GetMyQuota(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	quota, err := h.quotaService.GetUserQuota(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "QUOTA_FETCH_FAILED", err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"quota": quota,
+	}, "Quota retrieved successfully")
+}
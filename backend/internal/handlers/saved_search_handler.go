@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+)
+
+type SavedSearchHandler struct {
+	savedSearchService *services.SavedSearchService
+}
+
+func NewSavedSearchHandler(savedSearchService *services.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		savedSearchService: savedSearchService,
+	}
+}
+
+// Lists the user's saved searches
+// @Summary List saved searches
+// @Tags searches
+// @Produce json
+// @Success 200 {object} utils.ApiResponse{data=handlers.SavedSearchesResponse}
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "INTERNAL_SERVER_ERROR"
+// @Security BearerAuth
+// @Router /api/v1/searches [get]
+func (h *SavedSearchHandler) GetSavedSearches(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	searches, err := h.savedSearchService.ListSavedSearches(c.Request.Context(), userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch saved searches", err.Error())
+		return
+	}
+
+	data := gin.H{
+		"searches": searches,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Saved searches retrieved successfully")
+}
+
+// Saves the given document list filters under a name
+// @Summary Save a search
+// @Description Filters follow the document list rules. A user can keep up to 25 saved searches.
+// @Tags searches
+// @Accept json
+// @Produce json
+// @Param body body types.CreateSavedSearchRequest true "Name and filters"
+// @Success 201 {object} utils.ApiResponse{data=handlers.SavedSearchEnvelope}
+// @Failure 400 {object} utils.ApiResponse "VALIDATION_ERROR"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 409 {object} utils.ApiResponse "SAVED_SEARCH_EXISTS, SAVED_SEARCH_LIMIT_REACHED"
+// @Failure 500 {object} utils.ApiResponse "CREATION_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/searches [post]
+func (h *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	req, ok := validations.GetValidatedSavedSearchCreate(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	search, err := h.savedSearchService.CreateSavedSearch(c.Request.Context(), userID, req)
+	if err != nil {
+		switch err.Error() {
+		case "saved search name already exists":
+			utils.ConflictResponse(c, "SAVED_SEARCH_EXISTS", "A saved search with this name already exists")
+		case "saved search limit reached":
+			utils.ConflictResponse(c, "SAVED_SEARCH_LIMIT_REACHED", "Saved search limit reached, delete one to save another")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "CREATION_FAILED", "Failed to save search")
+		}
+		return
+	}
+
+	data := gin.H{
+		"search": search,
+	}
+	utils.SuccessResponse(c, http.StatusCreated, data, "Search saved successfully")
+}
+
+// @Summary Delete a saved search
+// @Tags searches
+// @Produce json
+// @Param id path string true "Saved search ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_SEARCH_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "SAVED_SEARCH_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "DELETE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/searches/{id} [delete]
+func (h *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	searchID, ok := validations.GetValidatedSavedSearchID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated saved search ID")
+		return
+	}
+
+	if err := h.savedSearchService.DeleteSavedSearch(c.Request.Context(), userID, searchID); err != nil {
+		if err.Error() == "saved search not found" {
+			utils.NotFoundResponse(c, "SAVED_SEARCH_NOT_FOUND", "Saved search not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "DELETE_FAILED", "Failed to delete saved search")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Saved search deleted successfully")
+}
+
+// Runs a saved search and returns the matching documents
+// @Summary Run a saved search
+// @Tags searches
+// @Produce json
+// @Param id path string true "Saved search ID" format(uuid)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Page size, at most 100" default(20)
+// @Success 200 {object} utils.ApiResponse{data=types.DocumentListResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_SEARCH_ID, VALIDATION_ERROR"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 404 {object} utils.ApiResponse "SAVED_SEARCH_NOT_FOUND"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/searches/{id}/run [get]
+func (h *SavedSearchHandler) RunSavedSearch(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	searchID, ok := validations.GetValidatedSavedSearchID(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated saved search ID")
+		return
+	}
+
+	req, ok := validations.GetValidatedSavedSearchRun(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	documents, err := h.savedSearchService.RunSavedSearch(c.Request.Context(), userID, searchID, req.Page, req.Limit)
+	if err != nil {
+		if err.Error() == "saved search not found" {
+			utils.NotFoundResponse(c, "SAVED_SEARCH_NOT_FOUND", "Saved search not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", "Failed to run saved search")
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, documents, "Saved search results retrieved successfully")
+}
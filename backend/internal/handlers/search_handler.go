@@ -5,9 +5,9 @@ import (
 	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	"github.com/sirupsen/logrus"
 )
 
 type SearchHandler struct {
@@ -105,7 +105,7 @@ func (h *SearchHandler) SimilaritySearch(c *gin.Context) {
 	// Perform search
 	results, err := h.searchService.SimilaritySearch(c.Request.Context(), req.Query, thresholdFloat, req.Limit, req.Collection, userUUID.String())
 	if err != nil {
-		logrus.Errorf("[SimilaritySearch] Search failed: %v", err)
+		utils.RequestLogger(c).WithError(err).Error("Similarity search failed")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to perform search", "details": err.Error()})
 		return
 	}
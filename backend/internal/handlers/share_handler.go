@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/metrics"
 	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
 	"github.com/eyuppastirmaci/noesis-forge/internal/services"
 	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
@@ -11,6 +14,14 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	// Lifetime of presigned URLs handed out for public link previews
+	sharedLinkPreviewExpiry = 15 * time.Minute
+
+	// Holds the access token of an unlocked password protected link
+	sharedLinkAccessCookie = "share_access"
+)
+
 type ShareHandler struct {
 	shareService *services.ShareService
 	minioService *services.MinIOService
@@ -26,6 +37,20 @@ func NewShareHandler(shareService *services.ShareService, minioService *services
 }
 
 // CreateShare handles
+// @Summary Create a public share link
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param X-CSRF-Token header string true "CSRF token"
+// @Param request body handlers.CreateShareRequest true "Link options"
+// @Success 201 {object} utils.ApiResponse{data=handlers.ShareLinkResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_ID, INVALID_BODY"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 403 {object} map[string]string "CSRF token missing"
+// @Failure 500 {object} utils.ApiResponse "CREATE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/share [post]
 func (h *ShareHandler) CreateShare(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -40,37 +65,81 @@ func (h *ShareHandler) CreateShare(c *gin.Context) {
 		return
 	}
 
-	// Simple body struct
-	var body struct {
-		ExpiresInDays int  `json:"expiresInDays"`
-		MaxDownloads  *int `json:"maxDownloads"`
-	}
+	var body CreateShareRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_BODY", err.Error())
 		return
 	}
 
-	link, err := h.shareService.CreatePublicShare(c.Request.Context(), userID, docID, body.ExpiresInDays, body.MaxDownloads)
+	link, err := h.shareService.CreatePublicShare(c.Request.Context(), userID, docID, body.ExpiresInDays, body.MaxDownloads, body.Password)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "CREATE_FAILED", err.Error())
 		return
 	}
 
 	data := gin.H{
-		"shareURL": h.config.Server.BaseURL + "/share/" + link.Token,
+		"shareURL":            h.config.Server.BaseURL + "/share/" + link.Token,
+		"isPasswordProtected": link.PasswordHash != "",
 	}
 	utils.SuccessResponse(c, http.StatusCreated, data, "Share link created")
 }
 
-// DownloadShared handles
+// DownloadShared handles public link access, streaming the file or with ?preview=true returning a presigned URL
+// @Summary Open a public share link
+// @Description Streams the shared file inline, or with preview=true returns a presigned URL as handlers.SharedPreviewResponse.
+// @Description Password protected links need the access cookie set by the unlock endpoint.
+// @Tags shares
+// @Produce octet-stream,json
+// @Param token path string true "Share token"
+// @Param preview query bool false "Return a presigned preview URL instead of the file"
+// @Success 200 {file} binary "File content"
+// @Failure 401 {object} utils.ApiResponse "PASSWORD_REQUIRED"
+// @Failure 404 {object} utils.ApiResponse "LINK_NOT_FOUND"
+// @Failure 409 {object} utils.ApiResponse "SCAN_PENDING"
+// @Failure 410 {object} utils.ApiResponse "LINK_UNAVAILABLE"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Failure 500 {object} utils.ApiResponse "SHARE_ACCESS_FAILED, STORAGE_ERROR"
+// @Router /share/{token} [get]
 func (h *ShareHandler) DownloadShared(c *gin.Context) {
 	token := c.Param("token")
 	clientIP := c.ClientIP()
 	ua := c.GetHeader("User-Agent")
+	preview := c.Query("preview") == "true"
+
+	// A missing cookie is fine, unprotected links ignore it
+	accessToken, _ := c.Cookie(sharedLinkAccessCookie)
 
-	doc, err := h.shareService.ValidateToken(c.Request.Context(), token, clientIP, ua)
+	doc, err := h.shareService.ValidateToken(c.Request.Context(), token, accessToken, clientIP, ua, preview)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusForbidden, "TOKEN_INVALID", err.Error())
+		switch err.Error() {
+		case "invalid or expired link":
+			utils.NotFoundResponse(c, "LINK_NOT_FOUND", "Share link not found")
+		case "password required":
+			utils.UnauthorizedResponse(c, "PASSWORD_REQUIRED", "This link is password protected")
+		case "link revoked", "link expired", "download limit reached", "document no longer available":
+			utils.ErrorResponse(c, http.StatusGone, "LINK_UNAVAILABLE", err.Error())
+		case "document is awaiting virus scan":
+			utils.ConflictResponse(c, "SCAN_PENDING", "The file is still being scanned for viruses, try again shortly")
+		case "too many share access attempts from your IP":
+			utils.TooManyRequestsResponse(c, err.Error())
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "SHARE_ACCESS_FAILED", err.Error())
+		}
+		return
+	}
+
+	if preview {
+		url, err := h.minioService.GeneratePresignedURL(c.Request.Context(), doc.StoragePath, sharedLinkPreviewExpiry)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "STORAGE_ERROR", "Failed to generate preview URL")
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, gin.H{
+			"url":       url,
+			"expiresAt": time.Now().Add(sharedLinkPreviewExpiry),
+			"fileName":  doc.OriginalFileName,
+			"mimeType":  doc.MimeType,
+		}, "Preview URL generated successfully")
 		return
 	}
 
@@ -83,14 +152,83 @@ func (h *ShareHandler) DownloadShared(c *gin.Context) {
 	defer reader.Close()
 
 	// Set appropriate headers for inline viewing (not download)
-	c.Header("Content-Disposition", "inline; filename=\""+doc.OriginalFileName+"\"")
+	c.Header("Content-Disposition", "inline; filename=\""+strings.ReplaceAll(doc.OriginalFileName, "\"", "\\\"")+"\"")
 	c.Header("Content-Type", doc.MimeType)
 
 	// Stream the file
-	c.DataFromReader(http.StatusOK, -1, doc.MimeType, reader, nil)
+	metrics.DocumentDownloadBytes.Add(float64(doc.FileSize))
+	c.DataFromReader(http.StatusOK, doc.FileSize, doc.MimeType, reader, nil)
+}
+
+// UnlockShared checks the password of a protected public link and stores a short-lived
+// access token in a cookie scoped to that link
+// @Summary Unlock a password protected share link
+// @Tags shares
+// @Accept json
+// @Produce json
+// @Param token path string true "Share token"
+// @Param request body handlers.UnlockShareRequest true "Link password"
+// @Success 200 {object} utils.ApiResponse{data=handlers.UnlockShareResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_BODY, NOT_PASSWORD_PROTECTED"
+// @Failure 401 {object} utils.ApiResponse "INVALID_PASSWORD"
+// @Failure 404 {object} utils.ApiResponse "LINK_NOT_FOUND"
+// @Failure 410 {object} utils.ApiResponse "LINK_UNAVAILABLE"
+// @Failure 429 {object} utils.ApiResponse "TOO_MANY_REQUESTS"
+// @Failure 500 {object} utils.ApiResponse "UNLOCK_FAILED"
+// @Router /share/{token}/unlock [post]
+func (h *ShareHandler) UnlockShared(c *gin.Context) {
+	token := c.Param("token")
+
+	var body UnlockShareRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_BODY", err.Error())
+		return
+	}
+
+	accessToken, expiresAt, err := h.shareService.UnlockLink(c.Request.Context(), token, body.Password, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		switch err.Error() {
+		case "invalid or expired link":
+			utils.NotFoundResponse(c, "LINK_NOT_FOUND", "Share link not found")
+		case "link revoked", "link expired", "download limit reached", "document no longer available":
+			utils.ErrorResponse(c, http.StatusGone, "LINK_UNAVAILABLE", err.Error())
+		case "link is not password protected":
+			utils.ErrorResponse(c, http.StatusBadRequest, "NOT_PASSWORD_PROTECTED", err.Error())
+		case "invalid password":
+			utils.UnauthorizedResponse(c, "INVALID_PASSWORD", "Invalid password")
+		case "too many password attempts":
+			utils.TooManyRequestsResponse(c, "Too many password attempts, try again later")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "UNLOCK_FAILED", err.Error())
+		}
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		sharedLinkAccessCookie,
+		accessToken,
+		int(services.SharedLinkAccessTTL.Seconds()),
+		"/share/"+token,
+		h.config.Cookies.Domain,
+		h.config.Cookies.Secure,
+		true,
+	)
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"expiresAt": expiresAt}, "Share link unlocked")
 }
 
 // GetDocumentShares handles
+// @Summary List public share links of a document
+// @Tags shares
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse{data=handlers.SharesResponse}
+// @Failure 400 {object} utils.ApiResponse "INVALID_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "FETCH_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/shares [get]
 func (h *ShareHandler) GetDocumentShares(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -115,6 +253,17 @@ func (h *ShareHandler) GetDocumentShares(c *gin.Context) {
 }
 
 // RevokeShare handles
+// @Summary Revoke a public share link
+// @Tags shares
+// @Produce json
+// @Param id path string true "Document ID" format(uuid)
+// @Param shareId path string true "Share ID" format(uuid)
+// @Success 200 {object} utils.ApiResponse
+// @Failure 400 {object} utils.ApiResponse "INVALID_SHARE_ID"
+// @Failure 401 {object} utils.ApiResponse "UNAUTHORIZED"
+// @Failure 500 {object} utils.ApiResponse "REVOKE_FAILED"
+// @Security BearerAuth
+// @Router /api/v1/documents/{id}/shares/{shareId} [delete]
 func (h *ShareHandler) RevokeShare(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
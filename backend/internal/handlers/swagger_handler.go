@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/eyuppastirmaci/noesis-forge/docs"
+	"github.com/gin-gonic/gin"
+)
+
+// Swagger UI is loaded from a CDN so the bundle does not have to be vendored
+const swaggerUIVersion = "5.17.14"
+
+var swaggerUIPage = []byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>NoesisForge API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@` + swaggerUIVersion + `/swagger-ui-bundle.js"></script>
+  <script src="/swagger/init.js"></script>
+</body>
+</html>`)
+
+var swaggerUIInit = []byte(`window.ui = SwaggerUIBundle({
+  url: "/swagger/doc.json",
+  dom_id: "#swagger-ui",
+  persistAuthorization: true
+});`)
+
+type SwaggerHandler struct{}
+
+func NewSwaggerHandler() *SwaggerHandler {
+	return &SwaggerHandler{}
+}
+
+// GetSpec serves the generated OpenAPI spec
+func (h *SwaggerHandler) GetSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", docs.SwaggerJSON)
+}
+
+// GetUI serves the Swagger UI page for the spec
+func (h *SwaggerHandler) GetUI(c *gin.Context) {
+	// The global policy only allows same-origin scripts and styles
+	c.Header("Content-Security-Policy", "default-src 'self'; script-src 'self' https://unpkg.com; style-src 'self' https://unpkg.com; img-src 'self' data: https:; connect-src 'self'")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerUIPage)
+}
+
+// GetUIInit serves the script that boots Swagger UI, kept out of the page so no inline script is needed
+func (h *SwaggerHandler) GetUIInit(c *gin.Context) {
+	c.Data(http.StatusOK, "application/javascript; charset=utf-8", swaggerUIInit)
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/google/uuid"
+)
+
+// Payload shapes of handlers that build their response with gin.H or bind a small body.
+// They are referenced from the swagger annotations so the spec describes the real payloads.
+
+// Auth
+
+type RoleSummary struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+	Permissions []string  `json:"permissions"`
+}
+
+type AuthUser struct {
+	ID             uuid.UUID   `json:"id"`
+	Email          string      `json:"email"`
+	Username       string      `json:"username"`
+	Name           string      `json:"name"`
+	Avatar         string      `json:"avatar"`
+	AvatarURL      string      `json:"avatarUrl"`
+	EmailVerified  bool        `json:"emailVerified"`
+	Status         string      `json:"status"`
+	EncryptionSalt string      `json:"encryptionSalt"`
+	Role           RoleSummary `json:"role"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	UpdatedAt      time.Time   `json:"updatedAt"`
+}
+
+type EncryptedField struct {
+	Encrypted string `json:"encrypted"`
+	IV        string `json:"iv"`
+}
+
+type ProfileUser struct {
+	AuthUser
+	Bio             string                    `json:"bio"`
+	AlternateEmail  string                    `json:"alternateEmail"`
+	Phone           string                    `json:"phone"`
+	Department      string                    `json:"department"`
+	EncryptedFields map[string]EncryptedField `json:"encryptedFields,omitempty"`
+}
+
+type LoginResponse struct {
+	User   AuthUser         `json:"user"`
+	Tokens models.TokenPair `json:"tokens"`
+}
+
+type TokensResponse struct {
+	Tokens models.TokenPair `json:"tokens"`
+}
+
+type ProfileResponse struct {
+	User ProfileUser `json:"user"`
+}
+
+type UserEnvelope struct {
+	User models.User `json:"user"`
+}
+
+type SessionsResponse struct {
+	Sessions []services.Session `json:"sessions"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type TokenValidResponse struct {
+	Valid bool `json:"valid"`
+}
+
+type AvatarResponse struct {
+	Avatar    string `json:"avatar"`
+	AvatarURL string `json:"avatarUrl,omitempty"`
+}
+
+type FullNameResponse struct {
+	FullName string `json:"fullName"`
+}
+
+// Documents
+
+type DocumentEnvelope struct {
+	Document types.DocumentResponse `json:"document"`
+}
+
+type DocumentTitleResponse struct {
+	Title string `json:"title"`
+}
+
+type DocumentStatusResponse struct {
+	Status models.DocumentStatus `json:"status"`
+}
+
+type TagsResponse struct {
+	Tags []types.TagCount `json:"tags"`
+}
+
+type RevisionsResponse struct {
+	Revisions []models.DocumentRevision `json:"revisions"`
+}
+
+type DuplicateDocumentResponse struct {
+	Duplicate types.DuplicateDocument `json:"duplicate"`
+}
+
+type BulkUploadResponse struct {
+	SuccessfulUploads int                      `json:"successful_uploads"`
+	FailedUploads     int                      `json:"failed_uploads"`
+	TotalFiles        int                      `json:"total_files"`
+	Documents         []types.DocumentResponse `json:"documents"`
+	Failures          []BulkFailure            `json:"failures,omitempty"`
+}
+
+type BulkDeleteResponse struct {
+	SuccessfulDeletes int           `json:"successful_deletes"`
+	FailedDeletes     int           `json:"failed_deletes"`
+	TotalDocuments    int           `json:"total_documents"`
+	Failures          []BulkFailure `json:"failures,omitempty"`
+}
+
+type BulkUpdateResponse struct {
+	SuccessfulUpdates int           `json:"successful_updates"`
+	FailedUpdates     int           `json:"failed_updates"`
+	TotalDocuments    int           `json:"total_documents"`
+	Failures          []BulkFailure `json:"failures,omitempty"`
+}
+
+type QueuePagination struct {
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"has_more"`
+}
+
+type ProcessingQueueResponse struct {
+	QueueItems    []map[string]interface{} `json:"queue_items"`
+	TotalCount    int64                    `json:"total_count"`
+	ReturnedCount int                      `json:"returned_count"`
+	Pagination    QueuePagination          `json:"pagination"`
+}
+
+// Saved searches
+
+type SavedSearchesResponse struct {
+	Searches []models.SavedSearch `json:"searches"`
+}
+
+type SavedSearchEnvelope struct {
+	Search models.SavedSearch `json:"search"`
+}
+
+// Folders
+
+type FoldersResponse struct {
+	Folders []models.Folder `json:"folders"`
+}
+
+type FolderEnvelope struct {
+	Folder models.Folder `json:"folder"`
+}
+
+// Groups
+
+type GroupsResponse struct {
+	Groups []models.Group `json:"groups"`
+}
+
+type GroupEnvelope struct {
+	Group models.Group `json:"group"`
+}
+
+type GroupMemberEnvelope struct {
+	Member models.GroupMember `json:"member"`
+}
+
+type GroupSharesResponse struct {
+	Shares []models.GroupShare `json:"shares"`
+}
+
+type GroupShareEnvelope struct {
+	Share models.GroupShare `json:"share"`
+}
+
+// Embed tokens
+
+type EmbedTokensResponse struct {
+	EmbedTokens []models.EmbedToken `json:"embedTokens"`
+}
+
+// Shares
+
+type CreateShareRequest struct {
+	ExpiresInDays int    `json:"expiresInDays"`
+	MaxDownloads  *int   `json:"maxDownloads"`
+	Password      string `json:"password" binding:"omitempty,min=4,max=128"`
+}
+
+type ShareLinkResponse struct {
+	ShareURL            string `json:"shareURL"`
+	IsPasswordProtected bool   `json:"isPasswordProtected"`
+}
+
+type UnlockShareRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type UnlockShareResponse struct {
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type SharedPreviewResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	FileName  string    `json:"fileName"`
+	MimeType  string    `json:"mimeType"`
+}
+
+type SharesResponse struct {
+	Shares []models.SharedLink `json:"shares"`
+}
+
+// Comments
+
+type BulkCommentResult struct {
+	ID      string `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+type BulkCommentResponse struct {
+	Successful    int                 `json:"successful"`
+	Failed        int                 `json:"failed"`
+	TotalComments int                 `json:"total_comments"`
+	Results       []BulkCommentResult `json:"results"`
+}
+
+type BulkDownloadFailures struct {
+	Failures []BulkFailure `json:"failures"`
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/config"
@@ -11,6 +13,7 @@ import (
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/services"
 	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -47,6 +50,7 @@ func (h *UserShareHandler) CreateUserShare(c *gin.Context) {
 		Emails        []string           `json:"emails" binding:"required"`
 		AccessLevel   models.AccessLevel `json:"accessLevel" binding:"required"`
 		ExpiresInDays int                `json:"expiresInDays"`
+		MaxDownloads  *int               `json:"maxDownloads" binding:"omitempty,min=1"` // Unlimited when omitted
 		Message       string             `json:"message"`
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
@@ -74,6 +78,7 @@ func (h *UserShareHandler) CreateUserShare(c *gin.Context) {
 			email,
 			body.AccessLevel,
 			body.ExpiresInDays,
+			body.MaxDownloads,
 			body.Message,
 		)
 		if err != nil {
@@ -99,7 +104,122 @@ func (h *UserShareHandler) CreateUserShare(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, response, "User shares created")
 }
 
-// GetSharedWithMe handles
+// BulkCreateUserShares shares several documents with several emails, one share per
+// (document, email) pair. Each pair is reported on its own so one failure doesn't undo the rest.
+func (h *UserShareHandler) BulkCreateUserShares(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	req, ok := validations.GetValidatedBulkShare(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	documentIDs := make([]uuid.UUID, len(req.DocumentIDs))
+	for i, id := range req.DocumentIDs {
+		documentIDs[i] = uuid.MustParse(id) // Checked by the validator
+	}
+
+	// Verify ownership once per document instead of once per pair
+	owned, err := h.userShareService.OwnedDocumentIDs(ctx, userID, documentIDs)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "SHARE_FAILED", "Failed to verify document ownership")
+		return
+	}
+
+	type shareResult struct {
+		DocumentID string            `json:"documentId"`
+		Email      string            `json:"email"`
+		Success    bool              `json:"success"`
+		Share      *models.UserShare `json:"share,omitempty"`
+		Error      string            `json:"error,omitempty"`
+	}
+
+	totalPairs := len(documentIDs) * len(req.Emails)
+	resultChan := make(chan shareResult, totalPairs)
+	semaphore := make(chan struct{}, 10) // Limit concurrent operations to 10
+	var wg sync.WaitGroup
+
+	for _, docID := range documentIDs {
+		for _, email := range req.Emails {
+			if !owned[docID] {
+				resultChan <- shareResult{DocumentID: docID.String(), Email: email, Error: "document not found or not owned by user"}
+				continue
+			}
+
+			wg.Add(1)
+			go func(docID uuid.UUID, email string) {
+				defer wg.Done()
+
+				// Acquire semaphore
+				semaphore <- struct{}{}
+				defer func() { <-semaphore }()
+
+				share, shareErr := h.userShareService.CreateUserShare(
+					ctx,
+					userID,
+					docID,
+					email,
+					models.AccessLevel(req.AccessLevel),
+					req.ExpiresInDays,
+					req.MaxDownloads,
+					req.Message,
+				)
+				result := shareResult{DocumentID: docID.String(), Email: email, Success: shareErr == nil, Share: share}
+				if shareErr != nil {
+					result.Error = shareErr.Error()
+				}
+				resultChan <- result
+			}(docID, email)
+		}
+	}
+
+	// Close result channel when all goroutines complete
+	go func() {
+		wg.Wait()
+		close(resultChan)
+	}()
+
+	// Collect all results
+	successfulShares := 0
+	results := make([]shareResult, 0, totalPairs)
+	for result := range resultChan {
+		if result.Success {
+			successfulShares++
+		}
+		results = append(results, result)
+	}
+
+	response := gin.H{
+		"successful_shares": successfulShares,
+		"failed_shares":     totalPairs - successfulShares,
+		"total_shares":      totalPairs,
+		"results":           results,
+	}
+
+	// Determine response status
+	if successfulShares == 0 {
+		utils.ErrorResponse(c, http.StatusBadRequest, "ALL_SHARES_FAILED", "All shares failed")
+		return
+	} else if successfulShares < totalPairs {
+		utils.SuccessResponse(c, http.StatusPartialContent, response,
+			fmt.Sprintf("Created %d out of %d shares successfully", successfulShares, totalPairs))
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, response,
+		fmt.Sprintf("All %d shares created successfully", totalPairs))
+}
+
+// GetSharedWithMe lists documents shared with the user, searchable by title and paginated
 func (h *UserShareHandler) GetSharedWithMe(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -114,7 +234,13 @@ func (h *UserShareHandler) GetSharedWithMe(c *gin.Context) {
 		return
 	}
 
-	shares, err := h.userShareService.GetSharedWithMe(c.Request.Context(), userID, user.Email)
+	req, ok := validations.GetValidatedSharedWithMeList(c)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get validated data")
+		return
+	}
+
+	shares, total, err := h.userShareService.GetSharedWithMe(c.Request.Context(), userID, user.Email, req)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
 		return
@@ -142,17 +268,20 @@ func (h *UserShareHandler) GetSharedWithMe(c *gin.Context) {
 			Avatar *string `json:"avatar"`
 		} `json:"sharedBy"`
 		Share struct {
-			ID             string  `json:"id"`
-			AccessLevel    string  `json:"accessLevel"`
-			SharedAt       string  `json:"sharedAt"`
-			ExpiresAt      *string `json:"expiresAt"`
-			IsRevoked      bool    `json:"isRevoked"`
-			AcceptedAt     *string `json:"acceptedAt"`
-			LastAccessedAt *string `json:"lastAccessedAt"`
+			ID                 string  `json:"id"`
+			AccessLevel        string  `json:"accessLevel"`
+			SharedAt           string  `json:"sharedAt"`
+			ExpiresAt          *string `json:"expiresAt"`
+			IsRevoked          bool    `json:"isRevoked"`
+			AcceptedAt         *string `json:"acceptedAt"`
+			LastAccessedAt     *string `json:"lastAccessedAt"`
+			MaxDownloads       *int    `json:"maxDownloads"`
+			DownloadCount      int     `json:"downloadCount"`
+			RemainingDownloads *int    `json:"remainingDownloads"` // null when downloads are unlimited
 		} `json:"share"`
 	}
 
-	var response []SharedWithMeResponse
+	response := make([]SharedWithMeResponse, 0, len(shares))
 	for _, share := range shares {
 		item := SharedWithMeResponse{
 			ID: share.ID.String(),
@@ -188,24 +317,30 @@ func (h *UserShareHandler) GetSharedWithMe(c *gin.Context) {
 				ID:     share.Owner.ID.String(),
 				Name:   share.Owner.Name,
 				Email:  share.Owner.Email,
-				Avatar: &share.Owner.Avatar,
+				Avatar: h.avatarFor(share.Owner),
 			},
 			Share: struct {
-				ID             string  `json:"id"`
-				AccessLevel    string  `json:"accessLevel"`
-				SharedAt       string  `json:"sharedAt"`
-				ExpiresAt      *string `json:"expiresAt"`
-				IsRevoked      bool    `json:"isRevoked"`
-				AcceptedAt     *string `json:"acceptedAt"`
-				LastAccessedAt *string `json:"lastAccessedAt"`
+				ID                 string  `json:"id"`
+				AccessLevel        string  `json:"accessLevel"`
+				SharedAt           string  `json:"sharedAt"`
+				ExpiresAt          *string `json:"expiresAt"`
+				IsRevoked          bool    `json:"isRevoked"`
+				AcceptedAt         *string `json:"acceptedAt"`
+				LastAccessedAt     *string `json:"lastAccessedAt"`
+				MaxDownloads       *int    `json:"maxDownloads"`
+				DownloadCount      int     `json:"downloadCount"`
+				RemainingDownloads *int    `json:"remainingDownloads"`
 			}{
-				ID:             share.ID.String(),
-				AccessLevel:    string(share.AccessLevel),
-				SharedAt:       share.CreatedAt.Format(time.RFC3339),
-				ExpiresAt:      nil,
-				IsRevoked:      share.IsRevoked,
-				AcceptedAt:     nil,
-				LastAccessedAt: nil,
+				ID:                 share.ID.String(),
+				AccessLevel:        string(share.AccessLevel),
+				SharedAt:           share.CreatedAt.Format(time.RFC3339),
+				ExpiresAt:          nil,
+				IsRevoked:          share.IsRevoked,
+				AcceptedAt:         nil,
+				LastAccessedAt:     nil,
+				MaxDownloads:       share.MaxDownloads,
+				DownloadCount:      share.DownloadCount,
+				RemainingDownloads: share.RemainingDownloads(),
 			},
 		}
 
@@ -226,7 +361,13 @@ func (h *UserShareHandler) GetSharedWithMe(c *gin.Context) {
 		response = append(response, item)
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, gin.H{"shares": response}, "Shared documents retrieved")
+	utils.SuccessResponse(c, http.StatusOK, gin.H{
+		"shares":     response,
+		"total":      total,
+		"page":       req.Page,
+		"limit":      req.Limit,
+		"totalPages": int((total + int64(req.Limit) - 1) / int64(req.Limit)),
+	}, "Shared documents retrieved")
 }
 
 // GetSharedByMe handles
@@ -237,7 +378,13 @@ func (h *UserShareHandler) GetSharedByMe(c *gin.Context) {
 		return
 	}
 
-	shares, err := h.userShareService.GetSharedByMe(c.Request.Context(), userID)
+	status := models.ShareStatus(c.Query("status"))
+	if status != "" && status != models.ShareStatusActive && status != models.ShareStatusExpired {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_STATUS", "status must be 'active' or 'expired'")
+		return
+	}
+
+	shares, err := h.userShareService.GetSharedByMe(c.Request.Context(), userID, status)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
 		return
@@ -277,6 +424,7 @@ func (h *UserShareHandler) GetSharedByMe(c *gin.Context) {
 			SharedAt       string  `json:"sharedAt"`
 			ExpiresAt      *string `json:"expiresAt"`
 			IsRevoked      bool    `json:"isRevoked"`
+			Status         string  `json:"status"`
 			AcceptedAt     *string `json:"acceptedAt"`
 			LastAccessedAt *string `json:"lastAccessedAt"`
 		} `json:"shares"`
@@ -322,7 +470,7 @@ func (h *UserShareHandler) GetSharedByMe(c *gin.Context) {
 
 		// Add individual shares
 		for _, share := range docShares {
-			if !share.IsRevoked {
+			if share.GetStatus() == models.ShareStatusActive {
 				item.ActiveShares++
 			}
 
@@ -338,6 +486,7 @@ func (h *UserShareHandler) GetSharedByMe(c *gin.Context) {
 				SharedAt       string  `json:"sharedAt"`
 				ExpiresAt      *string `json:"expiresAt"`
 				IsRevoked      bool    `json:"isRevoked"`
+				Status         string  `json:"status"`
 				AcceptedAt     *string `json:"acceptedAt"`
 				LastAccessedAt *string `json:"lastAccessedAt"`
 			}{
@@ -357,6 +506,7 @@ func (h *UserShareHandler) GetSharedByMe(c *gin.Context) {
 				SharedAt:       share.CreatedAt.Format(time.RFC3339),
 				ExpiresAt:      nil,
 				IsRevoked:      share.IsRevoked,
+				Status:         string(share.GetStatus()),
 				AcceptedAt:     nil,
 				LastAccessedAt: nil,
 			}
@@ -365,7 +515,7 @@ func (h *UserShareHandler) GetSharedByMe(c *gin.Context) {
 			if share.SharedWithUser != nil {
 				userID := share.SharedWithUser.ID.String()
 				userName := share.SharedWithUser.Name
-				userAvatar := &share.SharedWithUser.Avatar
+				userAvatar := h.avatarFor(share.SharedWithUser)
 				shareItem.SharedWith.ID = &userID
 				shareItem.SharedWith.Name = &userName
 				shareItem.SharedWith.Avatar = userAvatar
@@ -394,6 +544,96 @@ func (h *UserShareHandler) GetSharedByMe(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, gin.H{"shares": response}, "Shared documents retrieved")
 }
 
+// GetUserShare handles returning a single share with its audit history
+func (h *UserShareHandler) GetUserShare(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	shareID, err := uuid.Parse(c.Param("shareId"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_SHARE_ID", "invalid share id")
+		return
+	}
+
+	share, auditLogs, err := h.userShareService.GetShareByID(c.Request.Context(), userID, shareID)
+	if err != nil {
+		if err.Error() == "share not found" {
+			utils.NotFoundResponse(c, "SHARE_NOT_FOUND", "Share not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
+		return
+	}
+
+	isOwner := share.OwnerID == userID
+
+	response := gin.H{
+		"id":              share.ID.String(),
+		"accessLevel":     share.AccessLevel,
+		"status":          share.GetStatus(),
+		"message":         share.Message,
+		"expiresAt":       share.ExpiresAt,
+		"acceptedAt":      share.AcceptedAt,
+		"lastAccessedAt":  share.LastAccessedAt,
+		"sharedWithEmail": share.SharedWithEmail,
+		"createdAt":       share.CreatedAt.Format(time.RFC3339),
+		"isOwner":         isOwner,
+	}
+	if share.Document != nil {
+		response["document"] = gin.H{
+			"id":           share.Document.ID.String(),
+			"title":        share.Document.Title,
+			"fileType":     share.Document.FileType,
+			"fileSize":     share.Document.FileSize,
+			"hasThumbnail": share.Document.HasThumbnail,
+		}
+	}
+	if share.Owner != nil {
+		response["owner"] = gin.H{
+			"id":     share.Owner.ID.String(),
+			"name":   share.Owner.Name,
+			"email":  share.Owner.Email,
+			"avatar": h.avatarFor(share.Owner),
+		}
+	}
+	if share.SharedWithUser != nil {
+		response["sharedWith"] = gin.H{
+			"id":     share.SharedWithUser.ID.String(),
+			"name":   share.SharedWithUser.Name,
+			"email":  share.SharedWithUser.Email,
+			"avatar": h.avatarFor(share.SharedWithUser),
+		}
+	}
+
+	history := make([]gin.H, 0, len(auditLogs))
+	for _, entry := range auditLogs {
+		item := gin.H{
+			"id":        entry.ID.String(),
+			"action":    entry.Action,
+			"details":   entry.Details,
+			"createdAt": entry.CreatedAt.Format(time.RFC3339),
+		}
+		if entry.User != nil {
+			item["user"] = gin.H{
+				"id":   entry.User.ID.String(),
+				"name": entry.User.Name,
+			}
+		}
+		// Network details of the other party stay private to the owner
+		if isOwner {
+			item["ipAddress"] = entry.IPAddress
+			item["userAgent"] = entry.UserAgent
+		}
+		history = append(history, item)
+	}
+	response["auditLog"] = history
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"share": response}, "Share retrieved")
+}
+
 // RevokeUserShare handles
 func (h *UserShareHandler) RevokeUserShare(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
@@ -418,7 +658,7 @@ func (h *UserShareHandler) RevokeUserShare(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, nil, "User share revoked successfully")
 }
 
-// UpdateUserShareAccess handles
+// UpdateUserShareAccess changes the access level of a share the user owns
 func (h *UserShareHandler) UpdateUserShareAccess(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -435,23 +675,35 @@ func (h *UserShareHandler) UpdateUserShareAccess(c *gin.Context) {
 
 	var body struct {
 		AccessLevel models.AccessLevel `json:"accessLevel" binding:"required"`
+		Notify      bool               `json:"notify"` // Tell the recipient their access changed
 	}
 	if err := c.ShouldBindJSON(&body); err != nil {
 		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_BODY", err.Error())
 		return
 	}
 
-	// Validate access level
-	if body.AccessLevel != models.AccessLevelView &&
-		body.AccessLevel != models.AccessLevelDownload &&
-		body.AccessLevel != models.AccessLevelEdit {
+	// Validate access level, edit is the highest level a share can grant
+	if !body.AccessLevel.IsValid() {
 		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_ACCESS_LEVEL", "access level must be 'view', 'download', or 'edit'")
 		return
 	}
 
-	err = h.userShareService.UpdateUserShareAccess(c.Request.Context(), userID, shareID, body.AccessLevel)
+	err = h.userShareService.UpdateUserShareAccess(c.Request.Context(), userID, shareID, body.AccessLevel, body.Notify, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "UPDATE_FAILED", err.Error())
+		switch err.Error() {
+		case "share not found or not owned by user":
+			utils.NotFoundResponse(c, "SHARE_NOT_FOUND", err.Error())
+		case "invalid access level":
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_ACCESS_LEVEL", err.Error())
+		case "access level is unchanged":
+			utils.ConflictResponse(c, "ACCESS_LEVEL_UNCHANGED", err.Error())
+		case "share is revoked":
+			utils.ConflictResponse(c, "SHARE_REVOKED", err.Error())
+		case "share was changed concurrently":
+			utils.ConflictResponse(c, "SHARE_CHANGED", err.Error())
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "UPDATE_FAILED", err.Error())
+		}
 		return
 	}
 
@@ -626,16 +878,17 @@ func (h *UserShareHandler) GetPublicLinks(c *gin.Context) {
 
 	// Transform to frontend format
 	type PublicLinkResponse struct {
-		ID               string      `json:"id"`
-		Document         interface{} `json:"document"`
-		ShareUrl         string      `json:"shareUrl"`
-		Token            string      `json:"token"`
-		CreatedAt        string      `json:"createdAt"`
-		ExpiresAt        *string     `json:"expiresAt"`
-		MaxDownloads     *int        `json:"maxDownloads"`
-		CurrentDownloads int         `json:"currentDownloads"`
-		IsRevoked        bool        `json:"isRevoked"`
-		Status           string      `json:"status"`
+		ID                  string      `json:"id"`
+		Document            interface{} `json:"document"`
+		ShareUrl            string      `json:"shareUrl"`
+		Token               string      `json:"token"`
+		CreatedAt           string      `json:"createdAt"`
+		ExpiresAt           *string     `json:"expiresAt"`
+		MaxDownloads        *int        `json:"maxDownloads"`
+		CurrentDownloads    int         `json:"currentDownloads"`
+		IsRevoked           bool        `json:"isRevoked"`
+		IsPasswordProtected bool        `json:"isPasswordProtected"`
+		Status              string      `json:"status"`
 	}
 
 	var transformedLinks []PublicLinkResponse
@@ -659,16 +912,17 @@ func (h *UserShareHandler) GetPublicLinks(c *gin.Context) {
 		}
 
 		transformedLinks = append(transformedLinks, PublicLinkResponse{
-			ID:               link.ID.String(),
-			Document:         link.Document,
-			ShareUrl:         shareUrl,
-			Token:            link.Token,
-			CreatedAt:        link.CreatedAt.Format(time.RFC3339),
-			ExpiresAt:        expiresAtStr,
-			MaxDownloads:     link.MaxDownloads,
-			CurrentDownloads: link.DownloadCount,
-			IsRevoked:        link.IsRevoked,
-			Status:           status,
+			ID:                  link.ID.String(),
+			Document:            link.Document,
+			ShareUrl:            shareUrl,
+			Token:               link.Token,
+			CreatedAt:           link.CreatedAt.Format(time.RFC3339),
+			ExpiresAt:           expiresAtStr,
+			MaxDownloads:        link.MaxDownloads,
+			CurrentDownloads:    link.DownloadCount,
+			IsRevoked:           link.IsRevoked,
+			IsPasswordProtected: link.PasswordHash != "",
+			Status:              status,
 		})
 	}
 
@@ -685,6 +939,91 @@ func (h *UserShareHandler) GetPublicLinks(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, response, "Public links retrieved")
 }
 
+// GetInvitation handles returning the details of a share invitation
+func (h *UserShareHandler) GetInvitation(c *gin.Context) {
+	invitation, err := h.userShareService.GetInvitation(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		if err.Error() == "invitation not found" {
+			utils.NotFoundResponse(c, "INVITATION_NOT_FOUND", "Invitation not found")
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "FETCH_FAILED", err.Error())
+		return
+	}
+
+	status := "pending"
+	if invitation.IsAccepted {
+		status = "accepted"
+	} else if invitation.IsExpired() {
+		status = "expired"
+	}
+
+	response := gin.H{
+		"id":          invitation.ID.String(),
+		"email":       invitation.Email,
+		"accessLevel": invitation.AccessLevel,
+		"message":     invitation.Message,
+		"expiresAt":   invitation.ExpiresAt,
+		"acceptedAt":  invitation.AcceptedAt,
+		"status":      status,
+		"createdAt":   invitation.CreatedAt.Format(time.RFC3339),
+	}
+	if invitation.Document != nil {
+		response["document"] = gin.H{
+			"id":       invitation.Document.ID.String(),
+			"title":    invitation.Document.Title,
+			"fileType": invitation.Document.FileType,
+		}
+	}
+	if invitation.Owner != nil {
+		response["invitedBy"] = gin.H{
+			"name":  invitation.Owner.Name,
+			"email": invitation.Owner.Email,
+		}
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"invitation": response}, "Invitation retrieved")
+}
+
+// AcceptInvitation handles turning an invitation into a share for the current user
+func (h *UserShareHandler) AcceptInvitation(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	share, err := h.userShareService.AcceptInvitation(c.Request.Context(), userID, c.Param("token"))
+	if err != nil {
+		switch err.Error() {
+		case "invitation not found", "document not found":
+			utils.NotFoundResponse(c, "INVITATION_NOT_FOUND", "Invitation not found")
+		case "invitation already used":
+			utils.ConflictResponse(c, "INVITATION_ALREADY_USED", "Invitation has already been accepted")
+		case "invitation expired":
+			utils.ErrorResponse(c, http.StatusGone, "INVITATION_EXPIRED", "Invitation has expired")
+		case "share has been revoked":
+			utils.ErrorResponse(c, http.StatusGone, "SHARE_REVOKED", "The owner has revoked this share")
+		case "invitation was sent to a different email":
+			utils.ForbiddenResponse(c, "EMAIL_MISMATCH", "Invitation was sent to a different email address")
+		default:
+			utils.ErrorResponse(c, http.StatusInternalServerError, "ACCEPT_FAILED", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, gin.H{"share": share}, "Invitation accepted")
+}
+
+// Returns the uploaded avatar path, or the generated avatar URL for users without one
+func (h *UserShareHandler) avatarFor(user *models.User) *string {
+	if user.Avatar != "" {
+		return &user.Avatar
+	}
+	url := utils.GeneratedAvatarURL(h.config.Server.BaseURL, user.ID.String(), utils.NormalizeAvatarStyle(h.config.Avatar.Style), user.Name)
+	return &url
+}
+
 // Helper method to get database instance
 func (h *UserShareHandler) GetDB() *gorm.DB {
 	return h.userShareService.GetDB()
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	webhooks, err := h.webhookService.ListWebhooks(c.Request.Context(), userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch webhooks", err.Error())
+		return
+	}
+
+	data := gin.H{
+		"webhooks": webhooks,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Webhooks retrieved successfully")
+}
+
+// Creates a webhook, the response carries the signing secret which is not returned again
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	var req services.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", err.Error())
+		return
+	}
+
+	webhook, secret, err := h.webhookService.CreateWebhook(c.Request.Context(), userID, &req)
+	if err != nil {
+		if err.Error() == "webhook limit reached" {
+			utils.ConflictResponse(c, "WEBHOOK_LIMIT_REACHED", err.Error())
+		} else {
+			utils.ErrorResponse(c, http.StatusBadRequest, "CREATION_FAILED", err.Error())
+		}
+		return
+	}
+
+	data := gin.H{
+		"webhook": webhook,
+		"secret":  secret,
+	}
+	utils.SuccessResponse(c, http.StatusCreated, data, "Webhook created successfully")
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid webhook ID format")
+		return
+	}
+
+	var req services.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", err.Error())
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(c.Request.Context(), userID, webhookID, &req)
+	if err != nil {
+		if err.Error() == "webhook not found" {
+			utils.NotFoundResponse(c, "WEBHOOK_NOT_FOUND", err.Error())
+		} else {
+			utils.ErrorResponse(c, http.StatusBadRequest, "UPDATE_FAILED", err.Error())
+		}
+		return
+	}
+
+	data := gin.H{
+		"webhook": webhook,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Webhook updated successfully")
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid webhook ID format")
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(c.Request.Context(), userID, webhookID); err != nil {
+		if err.Error() == "webhook not found" {
+			utils.NotFoundResponse(c, "WEBHOOK_NOT_FOUND", err.Error())
+		} else {
+			utils.InternalServerErrorResponse(c, "Failed to delete webhook", err.Error())
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, nil, "Webhook deleted successfully")
+}
+
+// Lists deliveries that failed after all retries
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		utils.UnauthorizedResponse(c, "UNAUTHORIZED", err.Error())
+		return
+	}
+
+	webhookID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_ID", "Invalid webhook ID format")
+		return
+	}
+
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 200 {
+			limit = l
+		}
+	}
+
+	deadLetters, err := h.webhookService.GetDeadLetters(c.Request.Context(), userID, webhookID, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "webhook not found") {
+			utils.NotFoundResponse(c, "WEBHOOK_NOT_FOUND", err.Error())
+		} else {
+			utils.InternalServerErrorResponse(c, "Failed to fetch failed deliveries", err.Error())
+		}
+		return
+	}
+
+	data := gin.H{
+		"failures": deadLetters,
+	}
+	utils.SuccessResponse(c, http.StatusOK, data, "Failed deliveries retrieved successfully")
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+var (
+	// HTTPRequestDuration times requests by route template, so IDs in paths don't split series
+	HTTPRequestDuration = NewHistogramVec(
+		"http_request_duration_seconds",
+		"Time spent serving HTTP requests.",
+		DefaultLatencyBuckets,
+		"method", "route", "status",
+	)
+
+	DocumentUploadBytes = NewCounterVec(
+		"document_upload_bytes_total",
+		"Bytes of document files stored through uploads and file replacements.",
+	)
+
+	DocumentDownloadBytes = NewCounterVec(
+		"document_download_bytes_total",
+		"Bytes of document files sent to clients.",
+	)
+
+	// SearchStrategyHits counts which strategy answered a search. "cache" is a cached result,
+	// "listing" a request without a query and "none" a query no strategy found results for.
+	SearchStrategyHits = NewCounterVec(
+		"search_strategy_hits_total",
+		"Searches answered, by the strategy that produced the result.",
+		"strategy",
+	)
+
+	// DocumentScans counts virus scans by result, "error" when clamd could not scan the file
+	DocumentScans = NewCounterVec(
+		"document_scans_total",
+		"Virus scans of stored files, by result.",
+		"result",
+	)
+
+	BulkOperationsActive = NewGaugeVec(
+		"bulk_operations_active",
+		"Bulk operations currently running.",
+		"operation",
+	)
+
+	StorageOperationDuration = NewHistogramVec(
+		"storage_operation_duration_seconds",
+		"Time spent in MinIO operations.",
+		DefaultLatencyBuckets,
+		"operation", "result",
+	)
+)
+
+// ObserveHTTPRequest records a served request. Requests that matched no route share one series.
+func ObserveHTTPRequest(method, route string, status int, elapsed time.Duration) {
+	if route == "" {
+		route = "unmatched"
+	}
+	HTTPRequestDuration.Observe(elapsed.Seconds(), method, route, strconv.Itoa(status))
+}
+
+// ObserveStorageOperation records a MinIO call started at start, err is its outcome
+func ObserveStorageOperation(operation string, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	StorageOperationDuration.Observe(time.Since(start).Seconds(), operation, result)
+}
@@ -0,0 +1,282 @@
+// Package metrics keeps process-wide counters, gauges and histograms and serves them in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Bucket upper bounds in seconds, matching the Prometheus client defaults
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type collector interface {
+	name() string
+	write(w io.Writer, prefix string)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []collector
+)
+
+func register(c collector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, existing := range registry {
+		if existing.name() == c.name() {
+			panic(fmt.Sprintf("metric %s registered twice", c.name()))
+		}
+	}
+	registry = append(registry, c)
+}
+
+// Handler serves every registered metric in the Prometheus text format, names prefixed with
+// the namespace unless it is empty
+func Handler(namespace string) http.Handler {
+	prefix := ""
+	if namespace != "" {
+		prefix = namespace + "_"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteAll(w, prefix)
+	})
+}
+
+// WriteAll writes every registered metric in the Prometheus text format
+func WriteAll(w io.Writer, prefix string) {
+	registryMu.Lock()
+	collectors := append([]collector(nil), registry...)
+	registryMu.Unlock()
+
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].name() < collectors[j].name() })
+	for _, c := range collectors {
+		c.write(w, prefix)
+	}
+}
+
+// Series of a metric, keyed by their joined label values
+type series[T any] struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*T
+	keys   map[string][]string // label values of each key
+}
+
+func newSeries[T any](name, help, kind string, labels []string) series[T] {
+	return series[T]{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		values: make(map[string]*T),
+		keys:   make(map[string][]string),
+	}
+}
+
+// Returns the value for the label values, creating it on first use. Callers hold mu.
+func (s *series[T]) get(labelValues []string, create func() *T) *T {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("metric %s takes %d label values, got %d", s.name, len(s.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	value, ok := s.values[key]
+	if !ok {
+		value = create()
+		s.values[key] = value
+		s.keys[key] = append([]string(nil), labelValues...)
+	}
+	return value
+}
+
+// Writes the HELP and TYPE header and calls fn for each series in a stable order
+func (s *series[T]) each(w io.Writer, name string, fn func(labels string, value *T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, s.help, name, s.kind)
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fn(formatLabels(s.labels, s.keys[key]), s.values[key])
+	}
+}
+
+// CounterVec is a monotonically increasing value per label combination
+type CounterVec struct {
+	series[float64]
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{series: newSeries[float64](name, help, "counter", labels)}
+	if len(labels) == 0 {
+		// A counter without labels is reported as 0 before its first increment
+		c.get(nil, func() *float64 { return new(float64) })
+	}
+	register(c)
+	return c
+}
+
+func (c *CounterVec) name() string { return c.series.name }
+
+// Inc adds one to the counter of the label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds a non-negative value to the counter of the label values
+func (c *CounterVec) Add(value float64, labelValues ...string) {
+	if value < 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.get(labelValues, func() *float64 { return new(float64) }) += value
+}
+
+func (c *CounterVec) write(w io.Writer, prefix string) {
+	name := prefix + c.series.name
+	c.each(w, name, func(labels string, value *float64) {
+		fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(*value))
+	})
+}
+
+// GaugeVec is a value that goes up and down per label combination
+type GaugeVec struct {
+	series[float64]
+}
+
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{series: newSeries[float64](name, help, "gauge", labels)}
+	register(g)
+	return g
+}
+
+func (g *GaugeVec) name() string { return g.series.name }
+
+// Inc adds one to the gauge of the label values
+func (g *GaugeVec) Inc(labelValues ...string) {
+	g.Add(1, labelValues...)
+}
+
+// Dec subtracts one from the gauge of the label values
+func (g *GaugeVec) Dec(labelValues ...string) {
+	g.Add(-1, labelValues...)
+}
+
+// Add adds value, which may be negative, to the gauge of the label values
+func (g *GaugeVec) Add(value float64, labelValues ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	*g.get(labelValues, func() *float64 { return new(float64) }) += value
+}
+
+func (g *GaugeVec) write(w io.Writer, prefix string) {
+	name := prefix + g.series.name
+	g.each(w, name, func(labels string, value *float64) {
+		fmt.Fprintf(w, "%s%s %s\n", name, labels, formatFloat(*value))
+	})
+}
+
+type histogramValue struct {
+	counts []uint64 // per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec counts observations into buckets per label combination
+type HistogramVec struct {
+	series[histogramValue]
+	buckets []float64
+}
+
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		series:  newSeries[histogramValue](name, help, "histogram", labels),
+		buckets: append([]float64(nil), buckets...),
+	}
+	sort.Float64s(h.buckets)
+	register(h)
+	return h
+}
+
+func (h *HistogramVec) name() string { return h.series.name }
+
+// Observe records a value for the label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hv := h.get(labelValues, func() *histogramValue {
+		return &histogramValue{counts: make([]uint64, len(h.buckets))}
+	})
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		hv.counts[i]++
+	}
+	hv.count++
+	hv.sum += value
+}
+
+func (h *HistogramVec) write(w io.Writer, prefix string) {
+	name := prefix + h.series.name
+	h.each(w, name, func(labels string, hv *histogramValue) {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += hv.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(labels, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, withLabel(labels, "le", "+Inf"), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, labels, formatFloat(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, labels, hv.count)
+	})
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabelValue(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Appends a label to an already formatted label set
+func withLabel(labels, name, value string) string {
+	pair := name + `="` + value + `"`
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
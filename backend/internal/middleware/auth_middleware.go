@@ -3,6 +3,7 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/services"
@@ -11,29 +12,33 @@ import (
 	"github.com/google/uuid"
 )
 
-func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var token string
-
-		// First try Authorization header (preferred method)
-		authHeader := c.GetHeader("Authorization")
-		if authHeader != "" {
-			// Extract token from "Bearer <token>" format
-			parts := strings.Split(authHeader, " ")
-			if len(parts) == 2 && parts[0] == "Bearer" {
-				token = parts[1]
-			}
+// Returns the access token sent with the request, empty when there is none
+func AccessTokenFromRequest(c *gin.Context) string {
+	// First try Authorization header (preferred method)
+	authHeader := c.GetHeader("Authorization")
+	if authHeader != "" {
+		// Extract token from "Bearer <token>" format
+		parts := strings.Split(authHeader, " ")
+		if len(parts) == 2 && parts[0] == "Bearer" {
+			return parts[1]
 		}
+	}
 
-		// If no Authorization header, try cookie (fallback)
+	// If no Authorization header, try cookie (fallback)
+	token, err := c.Cookie("access_token")
+	if err != nil {
+		return ""
+	}
+	return token
+}
+
+func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := AccessTokenFromRequest(c)
 		if token == "" {
-			var err error
-			token, err = c.Cookie("access_token")
-			if err != nil || token == "" {
-				utils.ErrorResponse(c, http.StatusUnauthorized, "MISSING_TOKEN", "Authentication token is required")
-				c.Abort()
-				return
-			}
+			utils.ErrorResponse(c, http.StatusUnauthorized, "MISSING_TOKEN", "Authentication token is required")
+			c.Abort()
+			return
 		}
 
 		// Validate token
@@ -44,7 +49,7 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 				refreshToken, refreshErr := c.Cookie("refresh_token")
 				if refreshErr == nil && refreshToken != "" {
 					// Try to refresh the token
-					newTokens, refreshErr := authService.RefreshToken(c.Request.Context(), refreshToken)
+					newTokens, refreshErr := authService.RefreshToken(c.Request.Context(), refreshToken, c.ClientIP(), c.GetHeader("User-Agent"))
 					if refreshErr == nil {
 						// Set new tokens in cookies
 						authService.SetAuthCookies(c, newTokens)
@@ -58,6 +63,7 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 							c.Set("username", claims.Username)
 							c.Set("roleID", claims.RoleID)
 							c.Set("roleName", claims.RoleName)
+							c.Set("permissions", claims.Permissions)
 							c.Next()
 							return
 						}
@@ -76,6 +82,7 @@ func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 		c.Set("username", claims.Username)
 		c.Set("roleID", claims.RoleID)
 		c.Set("roleName", claims.RoleName)
+		c.Set("permissions", claims.Permissions)
 		c.Next()
 	}
 }
@@ -106,6 +113,121 @@ func RequireRole(roleName string) gin.HandlerFunc {
 	}
 }
 
+// RequirePermission middleware that rejects users whose role lacks the permission
+func RequirePermission(authService *services.AuthService, permission string) gin.HandlerFunc {
+	return RequireAllPermissions(authService, permission)
+}
+
+// RequireAllPermissions middleware that rejects users whose role lacks any of the permissions
+func RequireAllPermissions(authService *services.AuthService, permissions ...string) gin.HandlerFunc {
+	return requirePermissions(authService, permissions, ContainsAllPermissions)
+}
+
+// RequireAnyPermission middleware that rejects users whose role has none of the permissions
+func RequireAnyPermission(authService *services.AuthService, permissions ...string) gin.HandlerFunc {
+	return requirePermissions(authService, permissions, ContainsAnyPermission)
+}
+
+// Checks the permissions of the user's current role rather than the ones copied into the
+// token at login, so a role change applies to tokens that are already out
+func requirePermissions(authService *services.AuthService, required []string, check func(granted []string, required ...string) bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		granted, err := resolvePermissions(c, authService)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    "UNAUTHORIZED",
+				"message": "User permissions not found",
+			})
+			c.Abort()
+			return
+		}
+
+		if !check(granted, required...) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    "FORBIDDEN",
+				"message": "Insufficient permissions",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Loads the permissions of the user's role once per request and stores them in the context
+// so HasPermission and later checks see the same list
+func resolvePermissions(c *gin.Context, authService *services.AuthService) ([]string, error) {
+	if resolved, exists := c.Get("rolePermissionsResolved"); exists && resolved.(bool) {
+		permissions, _ := c.Get("permissions")
+		return permissions.([]string), nil
+	}
+
+	value, exists := c.Get("roleID")
+	if !exists {
+		return nil, fmt.Errorf("role not found in context")
+	}
+	roleID, ok := value.(uuid.UUID)
+	if !ok {
+		return nil, fmt.Errorf("invalid role ID format in context")
+	}
+
+	permissions, err := authService.GetRolePermissions(c.Request.Context(), roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Set("permissions", permissions)
+	c.Set("rolePermissionsResolved", true)
+	return permissions, nil
+}
+
+// HasPermission reports whether the user's permissions include the permission. They come from
+// the token claims unless a permission middleware already resolved the current role.
+func HasPermission(c *gin.Context, permission string) bool {
+	return HasAllPermissions(c, permission)
+}
+
+// HasAllPermissions reports whether the user's permissions include every one of the permissions
+func HasAllPermissions(c *gin.Context, permissions ...string) bool {
+	value, exists := c.Get("permissions")
+	if !exists {
+		return false
+	}
+	granted, _ := value.([]string)
+	return ContainsAllPermissions(granted, permissions...)
+}
+
+// HasAnyPermission reports whether the user's permissions include at least one of the permissions
+func HasAnyPermission(c *gin.Context, permissions ...string) bool {
+	value, exists := c.Get("permissions")
+	if !exists {
+		return false
+	}
+	granted, _ := value.([]string)
+	return ContainsAnyPermission(granted, permissions...)
+}
+
+// ContainsAllPermissions reports whether granted includes every required permission
+func ContainsAllPermissions(granted []string, required ...string) bool {
+	for _, permission := range required {
+		if !slices.Contains(granted, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAnyPermission reports whether granted includes at least one required permission
+func ContainsAnyPermission(granted []string, required ...string) bool {
+	for _, permission := range required {
+		if slices.Contains(granted, permission) {
+			return true
+		}
+	}
+	return false
+}
+
 // RequireAdmin middleware that checks if user is admin
 func RequireAdmin() gin.HandlerFunc {
 	return RequireRole("admin")
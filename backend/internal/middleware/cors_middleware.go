@@ -1,87 +1,19 @@
 package middleware
 
 import (
-	"time"
-
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
 
-// CORS returns CORS middleware based on environment
-func CORS(environment string, allowedOrigins []string) gin.HandlerFunc {
-	if environment == "production" {
-		return CORSForProduction(allowedOrigins)
-	}
-	return CORSForDevelopment()
-}
-
-// CORSForDevelopment returns permissive CORS middleware for development
-func CORSForDevelopment() gin.HandlerFunc {
-	return cors.New(cors.Config{
-		AllowOrigins: []string{
-			"http://localhost:3000",
-			"http://localhost:3001",
-			"http://127.0.0.1:3000",
-			"http://127.0.0.1:3001",
-		},
-		AllowMethods: []string{
-			"GET",
-			"POST",
-			"PUT",
-			"PATCH",
-			"DELETE",
-			"HEAD",
-			"OPTIONS",
-		},
-		AllowHeaders: []string{
-			"Origin",
-			"Content-Length",
-			"Content-Type",
-			"Accept",
-			"Authorization",
-			"X-Requested-With",
-			"X-CSRF-Token",
-		},
-		ExposeHeaders: []string{
-			"Content-Length",
-			"Content-Type",
-			"Content-Disposition",
-		},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	})
-}
-
-// CORSForProduction returns strict CORS middleware for production environment
-func CORSForProduction(allowedOrigins []string) gin.HandlerFunc {
-	// Default to secure origins if none provided
-	if len(allowedOrigins) == 0 {
-		allowedOrigins = []string{"https://yourdomain.com"}
-	}
-
+// CORS returns CORS middleware allowing the configured origins, methods and headers
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 	return cors.New(cors.Config{
-		AllowOrigins: allowedOrigins,
-		AllowMethods: []string{
-			"GET",
-			"POST",
-			"PUT",
-			"PATCH",
-			"DELETE",
-			"OPTIONS",
-		},
-		AllowHeaders: []string{
-			"Origin",
-			"Content-Type",
-			"Authorization",
-			"Accept",
-			"X-Requested-With",
-		},
-		ExposeHeaders: []string{
-			"Content-Length",
-			"Content-Type",
-			"Content-Disposition",
-		},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
+		AllowOrigins:     cfg.AllowedOrigins,
+		AllowMethods:     cfg.AllowedMethods,
+		AllowHeaders:     cfg.AllowedHeaders,
+		ExposeHeaders:    cfg.ExposedHeaders,
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
 	})
 }
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Context key of the embed token a request was authenticated with
+const embedTokenKey = "embedToken"
+
+// EmbedTokenMiddleware authenticates requests with a document embed token instead of a user
+// session. The token is read from the Authorization header or, for iframes that can't set
+// headers, the token query parameter. It only admits requests for the document in the :id
+// path and sets no user, so routes behind it can't act on anything else.
+func EmbedTokenMiddleware(embedTokenService *services.EmbedTokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+			if bearer, ok := strings.CutPrefix(authHeader, "Bearer "); ok {
+				token = bearer
+			}
+		}
+		if token == "" {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "MISSING_TOKEN", "Embed token is required")
+			c.Abort()
+			return
+		}
+
+		embedToken, err := embedTokenService.ValidateToken(c.Request.Context(), token)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "INVALID_EMBED_TOKEN", err.Error())
+			c.Abort()
+			return
+		}
+
+		// Tokens of other documents are treated like a document that doesn't exist
+		if documentID, err := uuid.Parse(c.Param("id")); err != nil || documentID != embedToken.DocumentID {
+			utils.NotFoundResponse(c, "DOCUMENT_NOT_FOUND", "Document not found")
+			c.Abort()
+			return
+		}
+
+		// Embeds are framed by other sites, unlike the rest of the API
+		c.Writer.Header().Del("X-Frame-Options")
+
+		c.Set(embedTokenKey, embedToken)
+		c.Next()
+	}
+}
+
+// GetEmbedTokenFromContext returns the embed token the request was authenticated with
+func GetEmbedTokenFromContext(c *gin.Context) (*models.EmbedToken, bool) {
+	value, exists := c.Get(embedTokenKey)
+	if !exists {
+		return nil, false
+	}
+	embedToken, ok := value.(*models.EmbedToken)
+	return embedToken, ok
+}
+
+// GetViewerIDFromContext returns whose access a view-only request is served with: the user,
+// or the document owner for requests authenticated with an embed token
+func GetViewerIDFromContext(c *gin.Context) (uuid.UUID, error) {
+	if embedToken, ok := GetEmbedTokenFromContext(c); ok {
+		return embedToken.OwnerID, nil
+	}
+	return GetUserIDFromContext(c)
+}
@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyInFlightExpiry = 15 * time.Minute // Frees the key of a request whose server went away
+)
+
+// What is stored under an idempotency key, first while the request runs and then its response
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Captures the response body next to writing it
+type recordingResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes requests carrying an Idempotency-Key header safe to retry. The response of
+// the first request is stored per user for ttl and replayed with the same status and body to
+// retries with the same key, whatever their body. A key reused on another endpoint is rejected,
+// and a retry arriving while the first request still runs gets a conflict. Server errors and
+// rate limited requests are not stored so they can be retried. Like the rate limiter, requests
+// are let through unchanged when Redis is missing or unreachable.
+func Idempotency(ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || ttl <= 0 {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY",
+				fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+			c.Abort()
+			return
+		}
+
+		userID, err := GetUserIDFromContext(c)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		value, exists := c.Get("redisClient")
+		if !exists {
+			c.Next()
+			return
+		}
+		client, ok := value.(*redis.Client)
+		if !ok || client == nil {
+			c.Next()
+			return
+		}
+
+		owner := userID.String()
+		fingerprint := c.Request.Method + " " + c.FullPath()
+
+		inFlight, _ := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+		reserved, err := client.ReserveIdempotencyKey(owner, key, inFlight, idempotencyInFlightExpiry)
+		if err != nil {
+			logrus.Warnf("Failed to reserve idempotency key: %v", err)
+			c.Next()
+			return
+		}
+
+		if !reserved {
+			replayIdempotentResponse(c, client, owner, key, fingerprint)
+			return
+		}
+
+		writer := &recordingResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			if err := client.ReleaseIdempotencyKey(owner, key); err != nil {
+				logrus.Warnf("Failed to release idempotency key: %v", err)
+			}
+			return
+		}
+
+		record, err := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Completed:   true,
+			Status:      status,
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		})
+		if err == nil {
+			err = client.SetIdempotencyRecord(owner, key, record, ttl)
+		}
+		if err != nil {
+			logrus.Warnf("Failed to store idempotent response: %v", err)
+		}
+	}
+}
+
+// Answers a request whose idempotency key is already taken
+func replayIdempotentResponse(c *gin.Context, client *redis.Client, owner, key, fingerprint string) {
+	data, err := client.GetIdempotencyRecord(owner, key)
+	if err != nil || data == nil {
+		// Released or expired in between, the retry may run again
+		utils.ErrorResponse(c, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE",
+			"A request with this idempotency key is being processed, please retry")
+		c.Abort()
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to read the stored response")
+		c.Abort()
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		utils.ErrorResponse(c, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+			"This idempotency key was used for a different request")
+		c.Abort()
+		return
+	}
+
+	if !record.Completed {
+		c.Header("Retry-After", "1")
+		utils.ErrorResponse(c, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE",
+			"A request with this idempotency key is being processed, please retry")
+		c.Abort()
+		return
+	}
+
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(record.Status, record.ContentType, record.Body)
+	c.Abort()
+}
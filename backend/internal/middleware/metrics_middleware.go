@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics times every request into the HTTP request histogram, labelled by route template
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		metrics.ObserveHTTPRequest(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start))
+	}
+}
@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -44,3 +47,54 @@ func RateLimitRedis(client *redis.Client, limit int, window time.Duration) gin.H
 		c.Next()
 	}
 }
+
+// RateLimitMiddleware limits how often an authenticated user may perform action within window.
+// The Redis client is read from the context like the comment limiter, and requests are allowed
+// through when it is missing or unreachable.
+func RateLimitMiddleware(action string, limit int, window time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		userID, err := GetUserIDFromContext(c)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		value, exists := c.Get("redisClient")
+		if !exists {
+			c.Next()
+			return
+		}
+		client, ok := value.(*redis.Client)
+		if !ok || client == nil {
+			c.Next()
+			return
+		}
+
+		key := fmt.Sprintf("rate_limit:user:%s:%s", userID.String(), action)
+		exceeded, count, err := client.CheckRateLimit(key, int64(limit), window)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		if exceeded {
+			retryAfter := window
+			if ttl, err := client.GetTTL(key); err == nil && ttl > 0 {
+				retryAfter = ttl
+			}
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			utils.ErrorResponse(c, http.StatusTooManyRequests, "RATE_LIMIT_EXCEEDED",
+				"Too many requests. Please try again later.",
+				fmt.Sprintf("%s rate limit exceeded: %d of %d requests allowed per %v", action, count, limit, window))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// Incoming IDs are honored only when they are short and safe to put in a log line
+var requestIDPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,128}$`)
+
+// RequestLogger assigns every request an ID, honoring the one set by a proxy or client,
+// puts a log entry tagged with it on the request context and logs the request on completion
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID = uuid.New().String()
+		}
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		entry := logrus.WithField("request_id", requestID)
+		c.Request = c.Request.WithContext(utils.ContextWithLogger(c.Request.Context(), entry))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		fields := logrus.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"status":     status,
+			"latency_ms": time.Since(start).Milliseconds(),
+			"client_ip":  c.ClientIP(),
+		}
+		if len(c.Errors) > 0 {
+			fields["errors"] = c.Errors.String()
+		}
+
+		completed := utils.RequestLogger(c).WithFields(fields)
+		switch {
+		case status >= 500:
+			completed.Error("Request completed")
+		case status >= 400:
+			completed.Warn("Request completed")
+		default:
+			completed.Info("Request completed")
+		}
+	}
+}
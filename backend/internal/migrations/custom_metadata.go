@@ -0,0 +1,16 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// AddCustomMetadataIndex adds a GIN index so documents can be filtered by custom field values
+func AddCustomMetadataIndex(db *gorm.DB) error {
+	sql := `CREATE INDEX IF NOT EXISTS idx_documents_custom_metadata ON documents USING GIN (custom_metadata jsonb_path_ops)`
+	if err := db.Exec(sql).Error; err != nil {
+		return fmt.Errorf("failed to create custom metadata index: %w", err)
+	}
+	return nil
+}
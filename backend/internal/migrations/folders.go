@@ -0,0 +1,18 @@
+package migrations
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// AddFolderNameIndex keeps folder names unique per parent regardless of case. Root folders
+// have no parent, NULLs never collide in a unique index so they are compared as the nil UUID.
+func AddFolderNameIndex(db *gorm.DB) error {
+	sql := `CREATE UNIQUE INDEX IF NOT EXISTS idx_folders_user_parent_name ON folders
+		(user_id, COALESCE(parent_folder_id, '00000000-0000-0000-0000-000000000000'::uuid), LOWER(name))`
+	if err := db.Exec(sql).Error; err != nil {
+		return fmt.Errorf("failed to create folder name index: %w", err)
+	}
+	return nil
+}
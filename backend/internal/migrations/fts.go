@@ -20,37 +20,47 @@ func AddFullTextSearchToDocuments(db *gorm.DB, dbName string) error {
 		return fmt.Errorf("failed to create custom search config: %w", err)
 	}
 
-	// Step 3: Create search vector update function
+	// Step 3: Add per-document language column and backfill it
+	if err := addDocumentLanguageColumn(db); err != nil {
+		return fmt.Errorf("failed to add document language column: %w", err)
+	}
+
+	// Step 4: Create regconfig lookup with safe fallback
+	if err := createSearchConfigFunction(db); err != nil {
+		return fmt.Errorf("failed to create search config function: %w", err)
+	}
+
+	// Step 5: Create search vector update function
 	if err := createSearchVectorFunction(db); err != nil {
 		return fmt.Errorf("failed to create search function: %w", err)
 	}
 
-	// Step 4: Create trigger for auto-updating search vector
+	// Step 6: Create trigger for auto-updating search vector
 	if err := createSearchVectorTrigger(db); err != nil {
 		return fmt.Errorf("failed to create search trigger: %w", err)
 	}
 
-	// Step 5: Update existing documents
+	// Step 7: Update existing documents
 	if err := updateExistingDocuments(db); err != nil {
 		return fmt.Errorf("failed to update existing documents: %w", err)
 	}
 
-	// Step 6: Create all necessary indexes
+	// Step 8: Create all necessary indexes
 	if err := createSearchIndexes(db); err != nil {
 		return fmt.Errorf("failed to create search indexes: %w", err)
 	}
 
-	// Step 7: Configure trigram settings
+	// Step 9: Configure trigram settings
 	if err := configureTrigramSettings(db, dbName); err != nil {
 		return fmt.Errorf("failed to configure trigram settings: %w", err)
 	}
 
-	// Step 8: Create additional search helper functions
+	// Step 10: Create additional search helper functions
 	if err := createSearchHelperFunctions(db); err != nil {
 		return fmt.Errorf("failed to create helper functions: %w", err)
 	}
 
-	// Step 9: Verify setup
+	// Step 11: Verify setup
 	if err := verifySetup(db); err != nil {
 		logrus.Warnf("Setup verification had issues: %v", err)
 	}
@@ -58,11 +68,12 @@ func AddFullTextSearchToDocuments(db *gorm.DB, dbName string) error {
 	return nil
 }
 
-// Adds search_vector and highlight columns
+// Adds search_vector, extracted content and highlight columns
 func addSearchColumns(db *gorm.DB) error {
 	if err := db.Exec(`
 		ALTER TABLE documents 
 		ADD COLUMN IF NOT EXISTS search_vector tsvector,
+		ADD COLUMN IF NOT EXISTS content_text text,
 		ADD COLUMN IF NOT EXISTS title_highlight text,
 		ADD COLUMN IF NOT EXISTS description_highlight text;
 	`).Error; err != nil {
@@ -111,17 +122,70 @@ func createCustomSearchConfig(db *gorm.DB) error {
 	return nil
 }
 
+// Adds the language column and backfills rows that have no usable value
+func addDocumentLanguageColumn(db *gorm.DB) error {
+	if err := db.Exec(`
+		ALTER TABLE documents
+		ADD COLUMN IF NOT EXISTS language varchar(32) NOT NULL DEFAULT 'english';
+	`).Error; err != nil {
+		return fmt.Errorf("failed to add language column: %w", err)
+	}
+
+	// Unknown configurations would break to_tsvector, so fall back to english
+	result := db.Exec(`
+		UPDATE documents SET language = 'english'
+		WHERE language IS NULL
+			OR language = ''
+			OR NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = documents.language);
+	`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill document languages: %w", result.Error)
+	}
+
+	logrus.Infof("Successfully added language column (%d rows backfilled)", result.RowsAffected)
+	return nil
+}
+
+// Creates a function that maps a language name to a regconfig, falling back to english
+func createSearchConfigFunction(db *gorm.DB) error {
+	if err := db.Exec(`
+		CREATE OR REPLACE FUNCTION document_search_config(lang text) RETURNS regconfig AS $$
+		DECLARE
+			cfg regconfig;
+		BEGIN
+			SELECT c.oid::regconfig INTO cfg
+			FROM pg_ts_config c
+			JOIN pg_namespace n ON n.oid = c.cfgnamespace
+			WHERE n.nspname = 'pg_catalog' AND c.cfgname = lower(COALESCE(lang, ''));
+
+			IF cfg IS NULL THEN
+				RETURN 'english'::regconfig;
+			END IF;
+			RETURN cfg;
+		END;
+		$$ LANGUAGE plpgsql STABLE;
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create search config function: %w", err)
+	}
+
+	logrus.Info("Successfully created search config lookup function")
+	return nil
+}
+
 // Creates the enhanced trigger function for updating search vectors
 func createSearchVectorFunction(db *gorm.DB) error {
 	if err := db.Exec(`
 		CREATE OR REPLACE FUNCTION documents_search_vector_update() RETURNS trigger AS $$
+		DECLARE
+			cfg regconfig := document_search_config(NEW.language);
 		BEGIN
-			-- Update tsvector with weighted content using custom configuration
+			-- Update tsvector with weighted content using the document's language
 			NEW.search_vector :=
-				setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A') ||
-				setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'B') ||
-				setweight(to_tsvector('english', COALESCE(NEW.tags, '')), 'C') ||
-				setweight(to_tsvector('english', COALESCE(NEW.original_file_name, '')), 'D');
+				setweight(to_tsvector(cfg, COALESCE(NEW.title, '')), 'A') ||
+				setweight(to_tsvector(cfg, COALESCE(NEW.description, '')), 'B') ||
+				setweight(to_tsvector(cfg, COALESCE(NEW.tags, '')), 'C') ||
+				setweight(to_tsvector(cfg, COALESCE(NEW.original_file_name, '')), 'D') ||
+				setweight(to_tsvector(cfg, COALESCE(NEW.content_text, '')), 'D');
 			
 			-- Clear highlight columns on update (they'll be populated during search)
 			NEW.title_highlight := NULL;
@@ -143,7 +207,7 @@ func createSearchVectorTrigger(db *gorm.DB) error {
 	if err := db.Exec(`
 		DROP TRIGGER IF EXISTS documents_search_vector_trigger ON documents;
 		CREATE TRIGGER documents_search_vector_trigger
-		BEFORE INSERT OR UPDATE OF title, description, tags, original_file_name ON documents
+		BEFORE INSERT OR UPDATE OF title, description, tags, original_file_name, content_text, language ON documents
 		FOR EACH ROW EXECUTE FUNCTION documents_search_vector_update();
 	`).Error; err != nil {
 		return fmt.Errorf("failed to create search vector trigger: %w", err)
@@ -158,10 +222,11 @@ func updateExistingDocuments(db *gorm.DB) error {
 	result := db.Exec(`
 		UPDATE documents SET 
 		search_vector =
-			setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
-			setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
-			setweight(to_tsvector('english', COALESCE(tags, '')), 'C') ||
-			setweight(to_tsvector('english', COALESCE(original_file_name, '')), 'D')
+			setweight(to_tsvector(document_search_config(language), COALESCE(title, '')), 'A') ||
+			setweight(to_tsvector(document_search_config(language), COALESCE(description, '')), 'B') ||
+			setweight(to_tsvector(document_search_config(language), COALESCE(tags, '')), 'C') ||
+			setweight(to_tsvector(document_search_config(language), COALESCE(original_file_name, '')), 'D') ||
+			setweight(to_tsvector(document_search_config(language), COALESCE(content_text, '')), 'D')
 		WHERE search_vector IS NULL;
 	`)
 
@@ -229,6 +294,12 @@ func createSearchIndexes(db *gorm.DB) error {
 			description: "Composite index for filtering",
 			critical:    false,
 		},
+		{
+			name:        "idx_documents_language",
+			query:       `CREATE INDEX IF NOT EXISTS idx_documents_language ON documents(language);`,
+			description: "Index for language filtering",
+			critical:    false,
+		},
 		{
 			name:        "idx_documents_tags_btree",
 			query:       `CREATE INDEX IF NOT EXISTS idx_documents_tags_btree ON documents USING btree(LOWER(tags));`,
@@ -449,6 +520,10 @@ func RollbackCompleteFullTextSearch(db *gorm.DB) error {
 		return err
 	}
 
+	if err := db.Exec(`DROP FUNCTION IF EXISTS document_search_config(TEXT);`).Error; err != nil {
+		logrus.Warnf("Failed to drop search config function: %v", err)
+	}
+
 	// Drop indexes
 	indexes := []string{
 		"idx_documents_search_vector",
@@ -459,6 +534,7 @@ func RollbackCompleteFullTextSearch(db *gorm.DB) error {
 		"idx_documents_description_btree",
 		"idx_documents_user_created",
 		"idx_documents_status_type",
+		"idx_documents_language",
 	}
 
 	for _, index := range indexes {
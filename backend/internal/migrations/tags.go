@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// MigrateDocumentTags copies the legacy comma-separated tags of documents that have no
+// document_tags rows yet into the normalized tag tables
+func MigrateDocumentTags(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			CREATE TEMP TABLE legacy_document_tags ON COMMIT DROP AS
+			SELECT DISTINCT d.id AS document_id, lower(trim(tag)) AS name
+			FROM documents d
+			CROSS JOIN LATERAL unnest(string_to_array(d.tags, ',')) AS tag
+			WHERE COALESCE(d.tags, '') <> ''
+				AND trim(tag) <> ''
+				AND NOT EXISTS (SELECT 1 FROM document_tags dt WHERE dt.document_id = d.id)
+		`).Error; err != nil {
+			return fmt.Errorf("failed to collect legacy tags: %w", err)
+		}
+
+		if err := tx.Exec(`
+			INSERT INTO tags (id, name, created_at)
+			SELECT gen_random_uuid(), name, NOW()
+			FROM (SELECT DISTINCT name FROM legacy_document_tags) AS names
+			ON CONFLICT (name) DO NOTHING
+		`).Error; err != nil {
+			return fmt.Errorf("failed to create tags: %w", err)
+		}
+
+		result := tx.Exec(`
+			INSERT INTO document_tags (document_id, tag_id, created_at)
+			SELECT l.document_id, t.id, NOW()
+			FROM legacy_document_tags l
+			JOIN tags t ON t.name = l.name
+			ON CONFLICT DO NOTHING
+		`)
+		if result.Error != nil {
+			return fmt.Errorf("failed to link document tags: %w", result.Error)
+		}
+
+		if result.RowsAffected > 0 {
+			logrus.Infof("Migrated %d legacy document tags", result.RowsAffected)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Emojis users can react to comments with, in the order reactions are listed
+var CommentReactionEmojis = []string{"👍", "👎", "❤️", "🎉", "😄", "😕", "👀", "🚀"}
+
+// CommentReaction is a user's emoji reaction to a comment, a user reacts once per emoji
+type CommentReaction struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	CommentID uuid.UUID `json:"commentID" gorm:"type:uuid;not null;uniqueIndex:idx_comment_reactions_comment_user_emoji"`
+	UserID    uuid.UUID `json:"userID" gorm:"type:uuid;not null;uniqueIndex:idx_comment_reactions_comment_user_emoji"`
+	Emoji     string    `json:"emoji" gorm:"size:32;not null;uniqueIndex:idx_comment_reactions_comment_user_emoji"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	Comment DocumentComment `json:"-" gorm:"foreignKey:CommentID;constraint:OnDelete:CASCADE"`
+	User    User            `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (r *CommentReaction) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Reports whether the emoji is one of CommentReactionEmojis
+func IsCommentReactionEmoji(emoji string) bool {
+	for _, allowed := range CommentReactionEmojis {
+		if emoji == allowed {
+			return true
+		}
+	}
+	return false
+}
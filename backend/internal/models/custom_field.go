@@ -0,0 +1,88 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CustomFieldType defines the value type accepted by a custom metadata field
+type CustomFieldType string
+
+const (
+	CustomFieldTypeText    CustomFieldType = "text"
+	CustomFieldTypeNumber  CustomFieldType = "number"
+	CustomFieldTypeBoolean CustomFieldType = "boolean"
+	CustomFieldTypeDate    CustomFieldType = "date" // YYYY-MM-DD
+)
+
+// IsValid reports whether the type is one of the supported field types
+func (t CustomFieldType) IsValid() bool {
+	switch t {
+	case CustomFieldTypeText, CustomFieldTypeNumber, CustomFieldTypeBoolean, CustomFieldTypeDate:
+		return true
+	}
+	return false
+}
+
+// CustomFieldDefinition is an admin-defined field that documents can carry in their custom metadata
+type CustomFieldDefinition struct {
+	ID          uuid.UUID       `json:"id" gorm:"type:uuid;primary_key"`
+	Name        string          `json:"name" gorm:"uniqueIndex;not null"` // Key used in custom metadata
+	Label       string          `json:"label" gorm:"not null"`            // Display name for forms
+	Description string          `json:"description"`
+	Type        CustomFieldType `json:"type" gorm:"type:varchar(20);not null"`
+	Required    bool            `json:"required" gorm:"default:false"`
+	SortOrder   int             `json:"sortOrder" gorm:"default:0"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+func (f *CustomFieldDefinition) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// CustomMetadata holds custom field values keyed by field name, stored as JSONB
+type CustomMetadata map[string]interface{}
+
+func (m CustomMetadata) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (m *CustomMetadata) Scan(value interface{}) error {
+	if value == nil {
+		*m = CustomMetadata{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported custom metadata type: %T", value)
+	}
+
+	result := CustomMetadata{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*m = result
+	return nil
+}
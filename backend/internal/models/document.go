@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -9,6 +12,7 @@ import (
 
 type DocumentStatus string
 type DocumentType string
+type ScanStatus string
 
 const (
 	DocumentStatusProcessing DocumentStatus = "processing"
@@ -17,6 +21,14 @@ const (
 	DocumentStatusDeleted    DocumentStatus = "deleted"
 )
 
+// Virus scan states, empty for files stored while scanning was off
+const (
+	ScanStatusNone     ScanStatus = ""
+	ScanStatusPending  ScanStatus = "pending" // Waiting for the processing worker
+	ScanStatusClean    ScanStatus = "clean"
+	ScanStatusInfected ScanStatus = "infected" // The file was deleted from storage
+)
+
 const (
 	DocumentTypePDF   DocumentType = "pdf"
 	DocumentTypeDOCX  DocumentType = "docx"
@@ -26,6 +38,44 @@ const (
 	DocumentTypeOther DocumentType = "other"
 )
 
+// Default PostgreSQL text search configuration used for documents
+const DefaultDocumentLanguage = "english"
+
+// Text search configurations that documents can be indexed with
+var SupportedDocumentLanguages = []string{
+	"simple", "arabic", "danish", "dutch", "english", "finnish", "french",
+	"german", "hungarian", "italian", "norwegian", "portuguese", "romanian",
+	"russian", "spanish", "swedish", "turkish",
+}
+
+// IsSupportedDocumentLanguage reports whether lang is a known search configuration
+func IsSupportedDocumentLanguage(lang string) bool {
+	for _, supported := range SupportedDocumentLanguages {
+		if supported == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// Tesseract language data matching the search configurations, used when an upload asks for
+// OCR without naming its languages
+var documentOCRLanguages = map[string]string{
+	"arabic": "ara", "danish": "dan", "dutch": "nld", "english": "eng", "finnish": "fin",
+	"french": "fra", "german": "deu", "hungarian": "hun", "italian": "ita", "norwegian": "nor",
+	"portuguese": "por", "romanian": "ron", "russian": "rus", "spanish": "spa", "swedish": "swe",
+	"turkish": "tur",
+}
+
+// DefaultOCRLanguage returns the Tesseract language for a search configuration, English for
+// configurations without one
+func DefaultOCRLanguage(lang string) string {
+	if code, ok := documentOCRLanguages[lang]; ok {
+		return code
+	}
+	return "eng"
+}
+
 type Document struct {
 	ID               uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
 	Title            string         `json:"title" gorm:"not null"`
@@ -38,17 +88,31 @@ type Document struct {
 	Status           DocumentStatus `json:"status" gorm:"default:'processing'"`
 
 	// MinIO storage info
-	StoragePath   string `json:"-" gorm:"not null"` // MinIO object path
-	StorageBucket string `json:"-" gorm:"not null"` // MinIO bucket name
+	StoragePath   string `json:"-" gorm:"not null"`                          // MinIO object path
+	StorageBucket string `json:"-" gorm:"not null"`                          // MinIO bucket name
+	ContentHash   string `json:"contentHash,omitempty" gorm:"size:64;index"` // SHA-256 of the file, used to spot duplicates
 
 	// Thumbnail info (server-generated thumbnails)
 	ThumbnailPath string `json:"-" gorm:""`                         // Path to thumbnail file in storage
 	HasThumbnail  bool   `json:"hasThumbnail" gorm:"default:false"` // Whether thumbnail exists
 
+	// PDF rendition of Office documents, used for in-browser preview
+	PreviewPath string `json:"-" gorm:""`
+
 	// Processing info
-	ExtractedText string     `json:"-" gorm:"type:text"`       // Extracted text content
-	Summary       string     `json:"summary" gorm:"type:text"` // AI-generated document summary
-	ProcessedAt   *time.Time `json:"processedAt,omitempty"`
+	ExtractedText   string      `json:"-" gorm:"type:text"`       // Extracted text content
+	ContentText     *string     `json:"-" gorm:"type:text"`       // Plain text indexed for search, nil until extracted
+	ContentPages    PageOffsets `json:"-" gorm:"type:jsonb"`      // Offsets into ContentText where each page starts
+	Summary         string      `json:"summary" gorm:"type:text"` // AI-generated document summary
+	ProcessedAt     *time.Time  `json:"processedAt,omitempty"`
+	ProcessingError string      `json:"processingError,omitempty" gorm:"type:text"` // Why preview generation failed
+	OCRLanguage     string      `json:"-" gorm:"type:varchar(64)"`                  // Tesseract languages scanned PDFs are OCR'd in, empty when not requested
+	OCRApplied      bool        `json:"ocrApplied" gorm:"default:false"`            // ContentText was recognized from page images
+
+	// Virus scan of the current file
+	ScanStatus    ScanStatus `json:"scanStatus,omitempty" gorm:"type:varchar(16);not null;default:''"`
+	ScanSignature string     `json:"scanSignature,omitempty" gorm:"type:varchar(255)"` // Malware found in an infected file
+	ScannedAt     *time.Time `json:"scannedAt,omitempty"`
 
 	// Versioning
 	Version  int        `json:"version" gorm:"default:1"`
@@ -56,16 +120,26 @@ type Document struct {
 	Parent   *Document  `json:"parent,omitempty" gorm:"foreignKey:ParentID"`
 
 	// Metadata
-	Tags          string `json:"tags"` // Comma-separated tags
-	IsPublic      bool   `json:"isPublic" gorm:"default:false"`
-	ViewCount     int64  `json:"viewCount" gorm:"default:0"`
-	DownloadCount int64  `json:"downloadCount" gorm:"default:0"`
-	PageCount     *int   `json:"pageCount,omitempty"` // Number of pages (for PDF documents)
+	Tags           string         `json:"tags"` // Comma-separated copy of document_tags, kept for full-text search
+	IsPublic       bool           `json:"isPublic" gorm:"default:false"`
+	ViewCount      int64          `json:"viewCount" gorm:"default:0"`
+	DownloadCount  int64          `json:"downloadCount" gorm:"default:0"`
+	PageCount      *int           `json:"pageCount,omitempty"`                                         // Number of pages (for PDF documents)
+	Language       string         `json:"language" gorm:"type:varchar(32);not null;default:'english'"` // Full-text search configuration
+	CustomMetadata CustomMetadata `json:"customMetadata" gorm:"type:jsonb;not null;default:'{}'"`      // Values for admin-defined custom fields
 
 	// Relations
 	UserID uuid.UUID `json:"userID" gorm:"type:uuid;not null"`
 	User   User      `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 
+	// Folder the document is filed in, nil at the root
+	FolderID *uuid.UUID `json:"folderID,omitempty" gorm:"type:uuid;index"`
+	Folder   *Folder    `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+
+	// Expiration, nil keeps the document indefinitely
+	ExpiresAt        *time.Time `json:"expiresAt,omitempty" gorm:"index"`
+	ExpiryNotifiedAt *time.Time `json:"-"` // When the owner was warned about the upcoming expiry
+
 	// Timestamps
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
@@ -76,6 +150,9 @@ func (d *Document) BeforeCreate(tx *gorm.DB) error {
 	if d.ID == uuid.Nil {
 		d.ID = uuid.New()
 	}
+	if d.Language == "" {
+		d.Language = DefaultDocumentLanguage
+	}
 	return nil
 }
 
@@ -89,12 +166,55 @@ func (d *Document) GetFileExtension() string {
 	return ""
 }
 
+// IsExpired reports whether the document's expiration time has passed
+func (d *Document) IsExpired() bool {
+	return d.ExpiresAt != nil && !d.ExpiresAt.After(time.Now())
+}
+
 // GetPublicURL returns public accessible URL (if needed)
 func (d *Document) GetPublicURL() string {
 	// This feature will be implemented in the future.
 	return ""
 }
 
+// PageOffsets lists where each page starts in the extracted text, stored as JSONB
+type PageOffsets []int
+
+func (o PageOffsets) Value() (driver.Value, error) {
+	if o == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (o *PageOffsets) Scan(value interface{}) error {
+	if value == nil {
+		*o = nil
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported page offsets type: %T", value)
+	}
+
+	var result PageOffsets
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*o = result
+	return nil
+}
+
 type DocumentCollection struct {
 	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
 	DocumentID   uuid.UUID `json:"documentID" gorm:"type:uuid;not null"`
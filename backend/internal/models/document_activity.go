@@ -31,13 +31,29 @@ const (
 	ActivityTypeMove             ActivityType = "move"              // Document moved to collection
 	ActivityTypeTagUpdate        ActivityType = "tag_update"        // Tags updated
 	ActivityTypePermissionChange ActivityType = "permission_change" // Permissions changed
+	ActivityTypeRevisionRestore  ActivityType = "revision_restore"  // Earlier file version restored
+	ActivityTypeCopy             ActivityType = "copy"              // Document copied into a new document
 )
 
+// IsValid reports whether the type is one of the known activity types
+func (t ActivityType) IsValid() bool {
+	switch t {
+	case ActivityTypeUpload, ActivityTypeView, ActivityTypeDownload, ActivityTypeUpdate, ActivityTypeDelete,
+		ActivityTypeShare, ActivityTypeUnshare, ActivityTypeComment, ActivityTypeEditComment,
+		ActivityTypeDeleteComment, ActivityTypeResolveComment, ActivityTypeUnresolveComment,
+		ActivityTypeFavorite, ActivityTypeUnfavorite, ActivityTypePreview, ActivityTypeRename,
+		ActivityTypeMove, ActivityTypeTagUpdate, ActivityTypePermissionChange, ActivityTypeRevisionRestore,
+		ActivityTypeCopy:
+		return true
+	}
+	return false
+}
+
 // DocumentActivity represents an activity or action performed on a document
 type DocumentActivity struct {
 	ID           uuid.UUID    `json:"id" gorm:"type:uuid;primary_key"`
 	DocumentID   uuid.UUID    `json:"documentID" gorm:"type:uuid;not null;index"`
-	UserID       uuid.UUID    `json:"userID" gorm:"type:uuid;not null"`
+	UserID       uuid.UUID    `json:"userID" gorm:"type:uuid;not null;index"`
 	ActivityType ActivityType `json:"activityType" gorm:"not null;index"`
 
 	// Activity description and metadata
@@ -157,6 +173,10 @@ func (da *DocumentActivity) GetActivityIcon() string {
 		return "tag"
 	case ActivityTypePermissionChange:
 		return "shield"
+	case ActivityTypeRevisionRestore:
+		return "history"
+	case ActivityTypeCopy:
+		return "copy"
 	default:
 		return "activity"
 	}
@@ -171,7 +191,7 @@ func (da *DocumentActivity) GetActivityColor() string {
 		return "blue"
 	case ActivityTypeDownload:
 		return "indigo"
-	case ActivityTypeUpdate, ActivityTypeRename, ActivityTypeEditComment:
+	case ActivityTypeUpdate, ActivityTypeRename, ActivityTypeEditComment, ActivityTypeRevisionRestore:
 		return "yellow"
 	case ActivityTypeDelete, ActivityTypeDeleteComment:
 		return "red"
@@ -185,7 +205,7 @@ func (da *DocumentActivity) GetActivityColor() string {
 		return "orange"
 	case ActivityTypeFavorite:
 		return "pink"
-	case ActivityTypeMove:
+	case ActivityTypeMove, ActivityTypeCopy:
 		return "teal"
 	case ActivityTypeTagUpdate:
 		return "cyan"
@@ -204,6 +224,7 @@ func (da *DocumentActivity) IsImportant() bool {
 		ActivityTypeShare,
 		ActivityTypeUpdate,
 		ActivityTypePermissionChange,
+		ActivityTypeRevisionRestore,
 	}
 
 	for _, t := range importantTypes {
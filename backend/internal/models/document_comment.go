@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -40,6 +43,9 @@ type DocumentComment struct {
 	IsEdited bool       `json:"isEdited" gorm:"default:false"`
 	EditedAt *time.Time `json:"editedAt,omitempty"`
 
+	// Users notified by an @username in the content
+	Mentions CommentMentions `json:"mentions" gorm:"type:jsonb;not null;default:'[]'"`
+
 	// Relations
 	Document Document          `json:"document,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
 	User     User              `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
@@ -68,6 +74,44 @@ type CommentPosition struct {
 	QuotedText *string `json:"quotedText,omitempty"` // Text that was selected/quoted
 }
 
+// CommentMentions lists the IDs of the users a comment mentions, stored as JSONB
+type CommentMentions []uuid.UUID
+
+func (m CommentMentions) Value() (driver.Value, error) {
+	if m == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (m *CommentMentions) Scan(value interface{}) error {
+	if value == nil {
+		*m = CommentMentions{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported comment mentions type: %T", value)
+	}
+
+	result := CommentMentions{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*m = result
+	return nil
+}
+
 func (dc *DocumentComment) BeforeCreate(tx *gorm.DB) error {
 	if dc.ID == uuid.Nil {
 		dc.ID = uuid.New()
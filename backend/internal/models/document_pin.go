@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DocumentPin keeps a document at the top of one user's listing. Pins are per user, so a
+// document shared with several users can be pinned by each of them independently.
+type DocumentPin struct {
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	UserID     uuid.UUID `json:"userID" gorm:"type:uuid;not null;index:idx_document_pins_user_document,unique"`
+	DocumentID uuid.UUID `json:"documentID" gorm:"type:uuid;not null;index:idx_document_pins_user_document,unique;index"`
+	CreatedAt  time.Time `json:"createdAt"`
+
+	// Relations
+	User     User     `json:"user,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Document Document `json:"document,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (p *DocumentPin) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+func (DocumentPin) TableName() string {
+	return "document_pins"
+}
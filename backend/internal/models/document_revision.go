@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,13 +11,42 @@ import (
 )
 
 // DocumentRevision keeps a lightweight audit record for every time a document is updated.
+// Revisions created when the file is replaced keep the previous object so it can be restored.
 type DocumentRevision struct {
-	ID            uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
-	DocumentID    uuid.UUID `json:"documentID" gorm:"type:uuid;not null;index"`
-	Version       int       `json:"version" gorm:"not null"`
-	ChangedBy     uuid.UUID `json:"changedBy" gorm:"type:uuid;not null"`
-	ChangeSummary string    `json:"changeSummary" gorm:"type:text"`
-	CreatedAt     time.Time `json:"createdAt"`
+	ID            uuid.UUID     `json:"id" gorm:"type:uuid;primary_key"`
+	DocumentID    uuid.UUID     `json:"documentID" gorm:"type:uuid;not null;index"`
+	Version       int           `json:"version" gorm:"not null"`
+	ChangedBy     uuid.UUID     `json:"changedBy" gorm:"type:uuid;not null"`
+	ChangeSummary string        `json:"changeSummary" gorm:"type:text"`
+	ChangedFields ChangedFields `json:"changedFields" gorm:"type:jsonb;not null;default:'[]'"`
+
+	// File of this version, empty for metadata only revisions
+	StoragePath      string       `json:"-"`
+	FileName         string       `json:"-"`
+	OriginalFileName string       `json:"originalFileName"`
+	FileSize         int64        `json:"fileSize"`
+	FileType         DocumentType `json:"fileType"`
+	MimeType         string       `json:"mimeType"`
+	ContentHash      string       `json:"-" gorm:"size:64"`
+
+	// Metadata and extracted text of this version, used to compare revisions. Title is empty
+	// for revisions recorded before they were kept.
+	Title       string  `json:"title"`
+	Description string  `json:"-" gorm:"type:text"`
+	Tags        string  `json:"-"`
+	ContentText *string `json:"-" gorm:"type:text"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// HasFile reports whether the revision kept a restorable file
+func (dr *DocumentRevision) HasFile() bool {
+	return dr.StoragePath != ""
+}
+
+// HasSnapshot reports whether the revision kept its metadata and text for comparison
+func (dr *DocumentRevision) HasSnapshot() bool {
+	return dr.Title != ""
 }
 
 func (dr *DocumentRevision) BeforeCreate(tx *gorm.DB) error {
@@ -23,3 +55,41 @@ func (dr *DocumentRevision) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// ChangedFields lists the document fields changed by a revision, stored as JSONB
+type ChangedFields []string
+
+func (f ChangedFields) Value() (driver.Value, error) {
+	if f == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (f *ChangedFields) Scan(value interface{}) error {
+	if value == nil {
+		*f = ChangedFields{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported changed fields type: %T", value)
+	}
+
+	result := ChangedFields{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*f = result
+	return nil
+}
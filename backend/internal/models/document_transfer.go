@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DocumentTransferStatus defines the state of an ownership transfer job
+type DocumentTransferStatus string
+
+const (
+	DocumentTransferStatusPending   DocumentTransferStatus = "pending"
+	DocumentTransferStatusRunning   DocumentTransferStatus = "running"
+	DocumentTransferStatusCompleted DocumentTransferStatus = "completed"
+	DocumentTransferStatusFailed    DocumentTransferStatus = "failed"
+)
+
+// DocumentTransfer tracks the reassignment of all documents from one user to another
+type DocumentTransfer struct {
+	ID           uuid.UUID              `json:"id" gorm:"type:uuid;primary_key"`
+	SourceUserID uuid.UUID              `json:"sourceUserID" gorm:"type:uuid;not null;index"`
+	TargetUserID uuid.UUID              `json:"targetUserID" gorm:"type:uuid;not null"`
+	RequestedBy  uuid.UUID              `json:"requestedBy" gorm:"type:uuid;not null"`
+	Status       DocumentTransferStatus `json:"status" gorm:"default:'pending';index"`
+
+	// Progress
+	TotalDocuments       int    `json:"totalDocuments" gorm:"default:0"`
+	TransferredDocuments int    `json:"transferredDocuments" gorm:"default:0"`
+	FailedDocuments      int    `json:"failedDocuments" gorm:"default:0"`
+	LastError            string `json:"lastError,omitempty" gorm:"type:text"`
+
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (dt *DocumentTransfer) BeforeCreate(tx *gorm.DB) error {
+	if dt.ID == uuid.Nil {
+		dt.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive checks if the transfer is still queued or running
+func (dt *DocumentTransfer) IsActive() bool {
+	return dt.Status == DocumentTransferStatusPending || dt.Status == DocumentTransferStatusRunning
+}
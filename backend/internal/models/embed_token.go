@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EmbedToken lets third-party apps show the preview and thumbnail of one document without a
+// user session. Only the SHA-256 of the token is stored, the token itself is shown once.
+type EmbedToken struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	DocumentID uuid.UUID  `json:"documentID" gorm:"type:uuid;not null;index"`
+	OwnerID    uuid.UUID  `json:"ownerID" gorm:"type:uuid;not null;index"`
+	TokenHash  string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	Name       string     `json:"name" gorm:"size:100"` // Where the token is used, for the owner's list
+	ExpiresAt  time.Time  `json:"expiresAt" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+
+	// Relations
+	Document Document `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Owner    User     `json:"-" gorm:"foreignKey:OwnerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (t *EmbedToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+func (t *EmbedToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+func (t *EmbedToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+)
+
+// A file type the server knows how to store and classify
+type FileTypeSpec struct {
+	Extension    string       // lowercase, with the leading dot
+	DocumentType DocumentType // drives previews and text extraction
+	MIMETypes    []string     // detected content types accepted for the extension
+}
+
+// Every file type that can be enabled, in the order they are listed to users. OpenDocument
+// and RTF files map to the Office type of the same family, LibreOffice handles them alike.
+var knownFileTypes = []FileTypeSpec{
+	{Extension: ".pdf", DocumentType: DocumentTypePDF, MIMETypes: []string{"application/pdf"}},
+	{Extension: ".doc", DocumentType: DocumentTypeDOCX, MIMETypes: []string{utils.MIMETypeDOC}},
+	{Extension: ".docx", DocumentType: DocumentTypeDOCX, MIMETypes: []string{utils.MIMETypeDOCX}},
+	{Extension: ".odt", DocumentType: DocumentTypeDOCX, MIMETypes: []string{"application/vnd.oasis.opendocument.text"}},
+	{Extension: ".rtf", DocumentType: DocumentTypeDOCX, MIMETypes: []string{utils.MIMETypeRTF}},
+	{Extension: ".txt", DocumentType: DocumentTypeTXT, MIMETypes: []string{"text/plain"}},
+	{Extension: ".md", DocumentType: DocumentTypeTXT, MIMETypes: []string{"text/plain"}},
+	{Extension: ".xls", DocumentType: DocumentTypeXLSX, MIMETypes: []string{utils.MIMETypeXLS}},
+	{Extension: ".xlsx", DocumentType: DocumentTypeXLSX, MIMETypes: []string{utils.MIMETypeXLSX}},
+	{Extension: ".ods", DocumentType: DocumentTypeXLSX, MIMETypes: []string{"application/vnd.oasis.opendocument.spreadsheet"}},
+	{Extension: ".ppt", DocumentType: DocumentTypePPTX, MIMETypes: []string{utils.MIMETypePPT}},
+	{Extension: ".pptx", DocumentType: DocumentTypePPTX, MIMETypes: []string{utils.MIMETypePPTX}},
+	{Extension: ".odp", DocumentType: DocumentTypePPTX, MIMETypes: []string{"application/vnd.oasis.opendocument.presentation"}},
+}
+
+// DocumentTypeForFile classifies a file by its extension. Types disabled for uploads are
+// still classified so documents stored before they were disabled keep their type.
+func DocumentTypeForFile(filename string) DocumentType {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, spec := range knownFileTypes {
+		if spec.Extension == ext {
+			return spec.DocumentType
+		}
+	}
+	return DocumentTypeOther
+}
+
+// FileTypePolicy is the set of file types accepted for upload. The validation and service
+// layers share one instance so they can't disagree.
+type FileTypePolicy struct {
+	allowed map[string]FileTypeSpec
+}
+
+// Builds the policy from extensions such as "pdf" or ".pdf". Unknown extensions are an error
+// so a typo in the configuration does not silently disable a type.
+func NewFileTypePolicy(extensions []string) (*FileTypePolicy, error) {
+	policy := &FileTypePolicy{allowed: make(map[string]FileTypeSpec)}
+
+	for _, extension := range extensions {
+		extension = strings.ToLower(strings.TrimSpace(extension))
+		if extension == "" {
+			continue
+		}
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+
+		index := slices.IndexFunc(knownFileTypes, func(spec FileTypeSpec) bool {
+			return spec.Extension == extension
+		})
+		if index < 0 {
+			return nil, fmt.Errorf("unknown file type %q", extension)
+		}
+		policy.allowed[extension] = knownFileTypes[index]
+	}
+
+	if len(policy.allowed) == 0 {
+		return nil, fmt.Errorf("no file types are allowed")
+	}
+	return policy, nil
+}
+
+// Returns the spec of an allowed file, false when its extension is not allowed
+func (p *FileTypePolicy) Lookup(filename string) (FileTypeSpec, bool) {
+	spec, ok := p.allowed[strings.ToLower(filepath.Ext(filename))]
+	return spec, ok
+}
+
+// Reports whether the file's extension is allowed
+func (p *FileTypePolicy) IsAllowed(filename string) bool {
+	_, ok := p.Lookup(filename)
+	return ok
+}
+
+// Reports whether the detected content type matches the file's allowed extension
+func (p *FileTypePolicy) AllowsContentType(filename, contentType string) bool {
+	spec, ok := p.Lookup(filename)
+	return ok && slices.Contains(spec.MIMETypes, contentType)
+}
+
+// Lists the allowed extensions in display order
+func (p *FileTypePolicy) Extensions() []string {
+	extensions := make([]string, 0, len(p.allowed))
+	for _, spec := range knownFileTypes {
+		if _, ok := p.allowed[spec.Extension]; ok {
+			extensions = append(extensions, spec.Extension)
+		}
+	}
+	return extensions
+}
+
+// Lists the allowed formats for messages, e.g. "PDF, DOCX, TXT"
+func (p *FileTypePolicy) Describe() string {
+	extensions := p.Extensions()
+	for i, extension := range extensions {
+		extensions[i] = strings.ToUpper(strings.TrimPrefix(extension, "."))
+	}
+	return strings.Join(extensions, ", ")
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Folder groups a user's documents, folders nest under a parent folder or the root
+type Folder struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	UserID         uuid.UUID  `json:"userID" gorm:"type:uuid;not null;index"`
+	ParentFolderID *uuid.UUID `json:"parentFolderID" gorm:"type:uuid;index"` // nil at the root
+	Name           string     `json:"name" gorm:"size:255;not null"`         // Unique within the parent, ignoring case
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+
+	User   User    `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Parent *Folder `json:"-" gorm:"foreignKey:ParentFolderID;constraint:OnDelete:CASCADE"`
+}
+
+func (f *Folder) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
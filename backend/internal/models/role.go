@@ -52,6 +52,23 @@ func (p *Permission) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// Permission names checked by route middleware
+const (
+	PermissionAdminAccess    = "admin:access"
+	PermissionUserManage     = "user:manage"
+	PermissionRoleManage     = "role:manage"
+	PermissionDocumentDelete = "document:delete"
+)
+
+// PermissionNames returns the names of the role's loaded permissions
+func (r *Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, permission := range r.Permissions {
+		names = append(names, permission.Name)
+	}
+	return names
+}
+
 // Default permissions
 var DefaultPermissions = []Permission{
 	{Name: "document:create", DisplayName: "Create Documents", Description: "Create new documents", Category: "document", IsSystem: true},
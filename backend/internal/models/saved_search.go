@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SavedSearch is a named set of document list filters a user can re-run
+type SavedSearch struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	UserID    uuid.UUID `json:"userID" gorm:"type:uuid;not null;uniqueIndex:idx_saved_searches_user_name"`
+	Name      string    `json:"name" gorm:"size:100;not null;uniqueIndex:idx_saved_searches_user_name"`
+	Query     string    `json:"query" gorm:"size:255"`
+	FileType  string    `json:"fileType" gorm:"size:20"`
+	Tags      string    `json:"tags" gorm:"size:255"`
+	SortBy    string    `json:"sortBy" gorm:"size:20;not null"`
+	SortDir   string    `json:"sortDir" gorm:"size:4;not null"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	User User `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (s *SavedSearch) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SearchReindexStatus defines the state of a search reindex job
+type SearchReindexStatus string
+
+const (
+	SearchReindexStatusPending   SearchReindexStatus = "pending"
+	SearchReindexStatusRunning   SearchReindexStatus = "running"
+	SearchReindexStatusCompleted SearchReindexStatus = "completed"
+	SearchReindexStatusFailed    SearchReindexStatus = "failed"
+)
+
+// SearchReindexJob tracks the recomputation of search vectors for a set of documents
+type SearchReindexJob struct {
+	ID          uuid.UUID           `json:"id" gorm:"type:uuid;primary_key"`
+	RequestedBy uuid.UUID           `json:"requestedBy" gorm:"type:uuid;not null"`
+	Status      SearchReindexStatus `json:"status" gorm:"default:'pending';index"`
+
+	// Scope, unset fields match every document
+	UserID      *uuid.UUID `json:"userID,omitempty" gorm:"type:uuid"`
+	CreatedFrom *time.Time `json:"createdFrom,omitempty"`
+	CreatedTo   *time.Time `json:"createdTo,omitempty"`
+
+	// Progress. Documents are walked in ID order, LastDocumentID lets an interrupted job resume.
+	TotalDocuments     int        `json:"totalDocuments" gorm:"default:0"`
+	ReindexedDocuments int        `json:"reindexedDocuments" gorm:"default:0"`
+	LastDocumentID     *uuid.UUID `json:"-" gorm:"type:uuid"`
+	LastError          string     `json:"lastError,omitempty" gorm:"type:text"`
+
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (j *SearchReindexJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive checks if the job is still queued or running
+func (j *SearchReindexJob) IsActive() bool {
+	return j.Status == SearchReindexStatusPending || j.Status == SearchReindexStatusRunning
+}
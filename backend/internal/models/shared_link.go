@@ -8,21 +8,22 @@ import (
 )
 
 type SharedLink struct {
-	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
-	DocumentID    uuid.UUID  `json:"documentID" gorm:"type:uuid;not null;index"`
-	OwnerID       uuid.UUID  `json:"ownerID" gorm:"type:uuid;not null;index"`
-	Token         string     `json:"token" gorm:"uniqueIndex;size:64;not null"`
-	ExpiresAt     *time.Time `json:"expiresAt"`
-	MaxDownloads  *int       `json:"maxDownloads"`
-	DownloadCount int        `json:"downloadCount" gorm:"default:0"`
-	IsRevoked     bool       `json:"isRevoked" gorm:"default:false"`
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	DocumentID     uuid.UUID  `json:"documentID" gorm:"type:uuid;not null;index"`
+	OwnerID        uuid.UUID  `json:"ownerID" gorm:"type:uuid;not null;index"`
+	Token          string     `json:"token" gorm:"uniqueIndex;size:64;not null"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+	MaxDownloads   *int       `json:"maxDownloads"`
+	DownloadCount  int        `json:"downloadCount" gorm:"default:0"`
+	IsRevoked      bool       `json:"isRevoked" gorm:"default:false"`
+	LastAccessedAt *time.Time `json:"lastAccessedAt"`
 
 	// Relations
 	Document *Document `json:"document,omitempty" gorm:"foreignKey:DocumentID"`
 
 	// Future-proof fields
 	RequireAuth  bool   `json:"requireAuth" gorm:"default:false"`
-	PasswordHash string `json:"passwordHash"`
+	PasswordHash string `json:"-"`
 	AllowedIPs   string `json:"allowedIPs"` // comma-separated list – future use
 
 	CreatedAt time.Time      `json:"createdAt"`
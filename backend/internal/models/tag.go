@@ -0,0 +1,139 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tag is a normalized label shared by all documents using the same name
+type Tag struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	Name      string    `json:"name" gorm:"type:varchar(50);not null;uniqueIndex"` // Lowercase
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// DocumentTag links a document to one of its tags
+type DocumentTag struct {
+	DocumentID uuid.UUID `json:"documentID" gorm:"type:uuid;primaryKey"`
+	TagID      uuid.UUID `json:"tagID" gorm:"type:uuid;primaryKey;index"`
+	CreatedAt  time.Time `json:"createdAt"`
+
+	// Relations
+	Document Document `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Tag      Tag      `json:"-" gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (DocumentTag) TableName() string {
+	return "document_tags"
+}
+
+// NormalizeTags parses a comma-separated tag list into trimmed, lowercase, unique names in input order
+func NormalizeTags(raw string) []string {
+	seen := make(map[string]bool)
+	tags := make([]string, 0)
+	for _, tag := range strings.Split(raw, ",") {
+		name := strings.ToLower(strings.TrimSpace(tag))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		tags = append(tags, name)
+	}
+	return tags
+}
+
+// Length bounds of a single tag in characters, the upper one is the size of Tag.Name
+const (
+	TagMinLength = 2
+	TagMaxLength = 50
+)
+
+// TagPolicy decides how many tags a document can have and which characters a tag may
+// contain. The validation and service layers share one instance so they can't disagree.
+type TagPolicy struct {
+	maxCount     int
+	symbols      string // allowed besides letters, digits and spaces
+	allowUnicode bool   // letters and digits of any script, otherwise ASCII only
+}
+
+// Builds the policy. Commas separate tags so they can't be among the allowed symbols.
+func NewTagPolicy(maxCount int, symbols string, allowUnicode bool) (*TagPolicy, error) {
+	if maxCount < 1 {
+		return nil, fmt.Errorf("max tag count must be at least 1, got %d", maxCount)
+	}
+	for _, r := range symbols {
+		switch {
+		case r == ',':
+			return nil, fmt.Errorf("commas separate tags and can't be allowed in them")
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) || unicode.IsControl(r):
+			return nil, fmt.Errorf("%q is not a symbol", r)
+		}
+	}
+	return &TagPolicy{maxCount: maxCount, symbols: symbols, allowUnicode: allowUnicode}, nil
+}
+
+// Returns how many tags a document can have
+func (p *TagPolicy) MaxCount() int {
+	return p.maxCount
+}
+
+// Validate checks a comma-separated tag list as NormalizeTags parses it, so duplicates and
+// empty entries don't count. Returns a message for the user, empty when the list is valid.
+func (p *TagPolicy) Validate(raw string) string {
+	tags := NormalizeTags(raw)
+	if len(tags) > p.maxCount {
+		return fmt.Sprintf("Maximum %d tags allowed", p.maxCount)
+	}
+
+	for _, tag := range tags {
+		length := utf8.RuneCountInString(tag)
+		if length < TagMinLength {
+			return fmt.Sprintf("Each tag must be at least %d characters", TagMinLength)
+		}
+		if length > TagMaxLength {
+			return fmt.Sprintf("Each tag must be at most %d characters", TagMaxLength)
+		}
+		for _, r := range tag {
+			if !p.allowsRune(r) {
+				return p.describeCharacters()
+			}
+		}
+	}
+
+	return ""
+}
+
+func (p *TagPolicy) allowsRune(r rune) bool {
+	switch {
+	case r == ' ' || strings.ContainsRune(p.symbols, r):
+		return true
+	case r < utf8.RuneSelf:
+		return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+	default:
+		return p.allowUnicode && (unicode.IsLetter(r) || unicode.IsDigit(r))
+	}
+}
+
+func (p *TagPolicy) describeCharacters() string {
+	letters := "letters"
+	if !p.allowUnicode {
+		letters = "unaccented Latin letters"
+	}
+	if p.symbols == "" {
+		return fmt.Sprintf("Tags can only contain %s, numbers and spaces", letters)
+	}
+	return fmt.Sprintf("Tags can only contain %s, numbers, spaces and the characters %s", letters, p.symbols)
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Joins n distinct valid tags
+func tagList(n int) string {
+	tags := make([]string, n)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag%02d", i)
+	}
+	return strings.Join(tags, ",")
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want []string
+	}{
+		{"", []string{}},
+		{" , ,", []string{}},
+		{"Go, go ,GO", []string{"go"}},
+		{"b,a,b", []string{"b", "a"}},
+		{"ÄRGER, ärger,Café", []string{"ärger", "café"}},
+		{"日本語, 日本語", []string{"日本語"}},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeTags(tt.raw); !slices.Equal(got, tt.want) {
+			t.Errorf("NormalizeTags(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestTagPolicyCount(t *testing.T) {
+	const maxCount = 5
+	policy, err := NewTagPolicy(maxCount, "-_", false)
+	if err != nil {
+		t.Fatalf("NewTagPolicy failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		raw   string
+		valid bool
+	}{
+		{"one below max", tagList(maxCount - 1), true},
+		{"at max", tagList(maxCount), true},
+		{"one above max", tagList(maxCount + 1), false},
+		// Duplicates and empty entries are dropped before counting
+		{"duplicates at max", tagList(maxCount) + ",TAG00, tag01", true},
+		{"empty entries at max", tagList(maxCount) + ",, ,", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := policy.Validate(tt.raw)
+			if tt.valid && msg != "" {
+				t.Fatalf("Validate(%q) = %q, want valid", tt.raw, msg)
+			}
+			if !tt.valid && msg != fmt.Sprintf("Maximum %d tags allowed", maxCount) {
+				t.Fatalf("Validate(%q) = %q, want the count message", tt.raw, msg)
+			}
+		})
+	}
+}
+
+func TestTagPolicyLength(t *testing.T) {
+	policy, err := NewTagPolicy(10, "", true)
+	if err != nil {
+		t.Fatalf("NewTagPolicy failed: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		raw   string
+		valid bool
+	}{
+		{"below min", "a", false},
+		{"at min", "ab", true},
+		{"at max", strings.Repeat("a", TagMaxLength), true},
+		{"above max", strings.Repeat("a", TagMaxLength+1), false},
+		// Lengths are in characters, not bytes
+		{"multibyte at min", "語", false},
+		{"multibyte pair", "日本", true},
+		{"multibyte at max", strings.Repeat("é", TagMaxLength), true},
+		{"multibyte above max", strings.Repeat("é", TagMaxLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if msg := policy.Validate(tt.raw); (msg == "") != tt.valid {
+				t.Fatalf("Validate(%q) = %q, want valid %v", tt.raw, msg, tt.valid)
+			}
+		})
+	}
+}
+
+func TestTagPolicyCharacters(t *testing.T) {
+	tests := []struct {
+		name         string
+		raw          string
+		symbols      string
+		allowUnicode bool
+		valid        bool
+	}{
+		{"ascii", "report 2024", "", false, true},
+		{"allowed symbols", "to-do_list", "-_", false, true},
+		{"symbol not allowed", "to-do", "_", false, false},
+		{"accented letter in ascii mode", "café", "-_", false, false},
+		{"accented letter", "café", "-_", true, true},
+		{"german", "Ärger über", "", true, true},
+		{"cyrillic", "документы", "", true, true},
+		{"cjk", "日本語", "", true, true},
+		{"arabic digits", "تقرير ٢٠٢٤", "", true, true},
+		{"emoji", "rocket 🚀", "", true, false},
+		{"unicode punctuation", "a—b", "-", true, false},
+		{"ascii symbols allowed", "c++ or c#", "+#", false, true},
+		{"non ascii symbol allowed", "a·b", "·", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewTagPolicy(10, tt.symbols, tt.allowUnicode)
+			if err != nil {
+				t.Fatalf("NewTagPolicy failed: %v", err)
+			}
+			if msg := policy.Validate(tt.raw); (msg == "") != tt.valid {
+				t.Fatalf("Validate(%q) = %q, want valid %v", tt.raw, msg, tt.valid)
+			}
+		})
+	}
+}
+
+func TestNewTagPolicyRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxCount int
+		symbols  string
+	}{
+		{"zero count", 0, "-"},
+		{"comma", 10, "-,"},
+		{"letter", 10, "-a"},
+		{"digit", 10, "1"},
+		{"space", 10, " "},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewTagPolicy(tt.maxCount, tt.symbols, false); err == nil {
+			t.Errorf("%s: NewTagPolicy(%d, %q) succeeded, want an error", tt.name, tt.maxCount, tt.symbols)
+		}
+	}
+}
@@ -8,11 +8,18 @@ import (
 	"gorm.io/gorm"
 )
 
+// Refresh tokens descending from one login share a FamilyID. A token is retired with RotatedAt
+// once it has been exchanged, the row is kept so a replay of it can be recognized.
 type RefreshToken struct {
-	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
-	Token     string         `json:"-" gorm:"uniqueIndex;not null"`
-	UserID    uuid.UUID      `json:"userID" gorm:"type:uuid;not null"`
-	ExpiresAt time.Time      `json:"expiresAt" gorm:"not null"`
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	Token     string     `json:"-" gorm:"uniqueIndex;not null"`
+	UserID    uuid.UUID  `json:"userID" gorm:"type:uuid;not null"`
+	FamilyID  uuid.UUID  `json:"familyID" gorm:"type:uuid;index"`
+	ExpiresAt time.Time  `json:"expiresAt" gorm:"not null"`
+	RotatedAt *time.Time `json:"rotatedAt,omitempty"`
+	// Client the token was issued to, shown in the session list
+	UserAgent string         `json:"userAgent" gorm:"size:512"`
+	IPAddress string         `json:"ipAddress" gorm:"size:45"`
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
@@ -32,6 +39,11 @@ func (rt *RefreshToken) IsExpired() bool {
 	return time.Now().After(rt.ExpiresAt)
 }
 
+// Reports whether the token was already exchanged for a new pair
+func (rt *RefreshToken) IsRotated() bool {
+	return rt.RotatedAt != nil
+}
+
 type EmailVerificationToken struct {
 	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primary_key"`
 	Token     string         `json:"-" gorm:"uniqueIndex;not null"`
@@ -101,9 +113,10 @@ type TokenPair struct {
 // Token claims for JWT validation
 type TokenClaims struct {
 	jwt.RegisteredClaims
-	UserID   uuid.UUID `json:"userID"`
-	Email    string    `json:"email"`
-	Username string    `json:"username"`
-	RoleID   uuid.UUID `json:"roleID"`
-	RoleName string    `json:"roleName"`
+	UserID      uuid.UUID `json:"userID"`
+	Email       string    `json:"email"`
+	Username    string    `json:"username"`
+	RoleID      uuid.UUID `json:"roleID"`
+	RoleName    string    `json:"roleName"`
+	Permissions []string  `json:"permissions"`
 }
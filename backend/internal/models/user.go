@@ -50,6 +50,11 @@ type User struct {
 	EncryptedBio        string `json:"-" gorm:"type:text"`
 	EncryptedBioIV      string `json:"-" gorm:"type:text"`
 
+	// Blind indexes, keyed hashes of the normalized values so admins can look users up by them
+	// while the fields themselves stay encrypted. See AuthService.profileBlindIndexes.
+	AltEmailIndex string `json:"-" gorm:"size:64;index"`
+	PhoneIndex    string `json:"-" gorm:"size:64;index"`
+
 	// Relations
 	RoleID uuid.UUID `json:"roleID" gorm:"type:uuid;not null"`
 	Role   Role      `json:"role,omitempty" gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
@@ -75,3 +80,24 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 func (u *User) IsLocked() bool {
 	return u.LockedUntil != nil && u.LockedUntil.After(time.Now())
 }
+
+// Counts a failed login and locks the account for lockDuration once maxAttempts consecutive
+// failures are reached, zero maxAttempts never locks. Failures during a lock don't extend it
+// and an expired lock starts a fresh count. Reports whether this failure locked the account.
+func (u *User) RegisterFailedLogin(now time.Time, maxAttempts int, lockDuration time.Duration) bool {
+	if u.LockedUntil != nil {
+		if u.LockedUntil.After(now) {
+			return false
+		}
+		u.LockedUntil = nil
+		u.FailedAttempts = 0
+	}
+
+	u.FailedAttempts++
+	if maxAttempts > 0 && u.FailedAttempts >= maxAttempts {
+		lockedUntil := now.Add(lockDuration)
+		u.LockedUntil = &lockedUntil
+		return true
+	}
+	return false
+}
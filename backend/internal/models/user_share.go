@@ -15,6 +15,25 @@ const (
 	AccessLevelEdit     AccessLevel = "edit"
 )
 
+// Reports whether the level can be granted through a user share, edit is the highest
+func (a AccessLevel) IsValid() bool {
+	return a == AccessLevelView || a == AccessLevelDownload || a == AccessLevelEdit
+}
+
+// Orders levels so the highest of several grants can be picked, 0 for an unknown level
+func (a AccessLevel) Rank() int {
+	switch a {
+	case AccessLevelView:
+		return 1
+	case AccessLevelDownload:
+		return 2
+	case AccessLevelEdit:
+		return 3
+	default:
+		return 0
+	}
+}
+
 type ShareStatus string
 
 const (
@@ -36,6 +55,8 @@ type UserShare struct {
 	AcceptedAt       *time.Time  `json:"acceptedAt"`
 	LastAccessedAt   *time.Time  `json:"lastAccessedAt"`
 	Message          string      `json:"message,omitempty"` // Optional message from sharer
+	MaxDownloads     *int        `json:"maxDownloads"`      // nil allows unlimited downloads
+	DownloadCount    int         `json:"downloadCount" gorm:"not null;default:0"`
 
 	// Relations
 	Document       *Document `json:"document,omitempty" gorm:"foreignKey:DocumentID"`
@@ -62,6 +83,16 @@ func (us *UserShare) IsExpired() bool {
 	return us.ExpiresAt.Before(time.Now())
 }
 
+// RemainingDownloads returns how many more times the file can be downloaded through the
+// share, nil when the share has no download limit
+func (us *UserShare) RemainingDownloads() *int {
+	if us.MaxDownloads == nil {
+		return nil
+	}
+	remaining := max(*us.MaxDownloads-us.DownloadCount, 0)
+	return &remaining
+}
+
 // GetStatus returns the current status of the share
 func (us *UserShare) GetStatus() ShareStatus {
 	if us.IsRevoked {
@@ -76,7 +107,7 @@ func (us *UserShare) GetStatus() ShareStatus {
 // ShareNotification represents notifications for sharing events
 type ShareNotification struct {
 	ID         uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
-	Type       string    `json:"type" gorm:"not null"` // document_shared, access_granted, access_revoked, document_updated
+	Type       string    `json:"type" gorm:"not null"` // document_shared, access_granted, access_changed, access_revoked, document_updated, comment_mention, comment_reply, document_expiring, document_quarantined
 	Title      string    `json:"title" gorm:"not null"`
 	Message    string    `json:"message" gorm:"not null"`
 	DocumentID uuid.UUID `json:"documentID" gorm:"type:uuid;not null;index"`
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegisterFailedLoginLocksAtThreshold(t *testing.T) {
+	const maxAttempts = 3
+	lockDuration := 30 * time.Minute
+	now := time.Now()
+	user := &User{}
+
+	for attempt := 1; attempt < maxAttempts; attempt++ {
+		if user.RegisterFailedLogin(now, maxAttempts, lockDuration) {
+			t.Fatalf("attempt %d locked the account, want a lock at %d", attempt, maxAttempts)
+		}
+		if user.FailedAttempts != attempt || user.LockedUntil != nil {
+			t.Fatalf("after attempt %d: FailedAttempts = %d, LockedUntil = %v", attempt, user.FailedAttempts, user.LockedUntil)
+		}
+	}
+
+	if !user.RegisterFailedLogin(now, maxAttempts, lockDuration) {
+		t.Fatalf("attempt %d did not lock the account", maxAttempts)
+	}
+	if user.LockedUntil == nil || !user.LockedUntil.Equal(now.Add(lockDuration)) {
+		t.Fatalf("LockedUntil = %v, want %v", user.LockedUntil, now.Add(lockDuration))
+	}
+	if !user.IsLocked() {
+		t.Fatal("IsLocked() = false right after locking")
+	}
+}
+
+func TestRegisterFailedLoginDuringLock(t *testing.T) {
+	now := time.Now()
+	lockedUntil := now.Add(10 * time.Minute)
+	user := &User{FailedAttempts: 3, LockedUntil: &lockedUntil}
+
+	// Guessing on while locked neither counts nor extends the lock
+	if user.RegisterFailedLogin(now.Add(time.Minute), 3, time.Hour) {
+		t.Fatal("failure during a lock reported a new lock")
+	}
+	if user.FailedAttempts != 3 {
+		t.Fatalf("FailedAttempts = %d, want 3", user.FailedAttempts)
+	}
+	if !user.LockedUntil.Equal(lockedUntil) {
+		t.Fatalf("LockedUntil = %v, want it kept at %v", user.LockedUntil, lockedUntil)
+	}
+}
+
+func TestRegisterFailedLoginAfterLockExpires(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Second)
+	user := &User{FailedAttempts: 3, LockedUntil: &expired}
+
+	if user.IsLocked() {
+		t.Fatal("IsLocked() = true for an expired lock")
+	}
+
+	// The expired lock is cleared and counting starts over
+	if user.RegisterFailedLogin(now, 3, time.Hour) {
+		t.Fatal("first failure after expiry locked the account")
+	}
+	if user.FailedAttempts != 1 || user.LockedUntil != nil {
+		t.Fatalf("FailedAttempts = %d, LockedUntil = %v, want 1 and unlocked", user.FailedAttempts, user.LockedUntil)
+	}
+
+	// A lock ending exactly now has expired as well
+	user = &User{FailedAttempts: 3, LockedUntil: &now}
+	if !user.RegisterFailedLogin(now, 1, time.Hour) {
+		t.Fatal("failure at the moment the lock ended did not lock again with a limit of 1")
+	}
+	if user.FailedAttempts != 1 || !user.LockedUntil.Equal(now.Add(time.Hour)) {
+		t.Fatalf("FailedAttempts = %d, LockedUntil = %v, want a fresh lock", user.FailedAttempts, user.LockedUntil)
+	}
+}
+
+func TestRegisterFailedLoginWithoutLimit(t *testing.T) {
+	user := &User{}
+	for i := 0; i < 100; i++ {
+		if user.RegisterFailedLogin(time.Now(), 0, time.Hour) {
+			t.Fatal("account locked with lockout disabled")
+		}
+	}
+	if user.FailedAttempts != 100 || user.LockedUntil != nil {
+		t.Fatalf("FailedAttempts = %d, LockedUntil = %v", user.FailedAttempts, user.LockedUntil)
+	}
+}
+
+func TestIsLocked(t *testing.T) {
+	future := time.Now().Add(time.Minute)
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		lockedUntil *time.Time
+		want        bool
+	}{
+		{"never locked", nil, false},
+		{"locked", &future, true},
+		{"lock expired", &past, false},
+	}
+
+	for _, tt := range tests {
+		user := &User{LockedUntil: tt.lockedUntil}
+		if got := user.IsLocked(); got != tt.want {
+			t.Errorf("%s: IsLocked() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
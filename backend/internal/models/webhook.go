@@ -0,0 +1,101 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook posts the document activities of its owner to an external URL
+type Webhook struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;primary_key"`
+	UserID uuid.UUID `json:"userID" gorm:"type:uuid;not null;index"`
+	URL    string    `json:"url" gorm:"size:2048;not null"`
+	// Signs payloads, only revealed when the webhook is created
+	Secret     string            `json:"-" gorm:"not null"`
+	EventTypes WebhookEventTypes `json:"eventTypes" gorm:"type:jsonb;not null;default:'[]'"` // Empty subscribes to all events
+	Active     bool              `json:"active" gorm:"default:true"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+}
+
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// Subscribes reports whether the webhook wants events of the given activity type
+func (w *Webhook) Subscribes(activityType ActivityType) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, eventType := range w.EventTypes {
+		if eventType == string(activityType) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeadLetter keeps a delivery that still failed after all retries
+type WebhookDeadLetter struct {
+	ID         uuid.UUID    `json:"id" gorm:"type:uuid;primary_key"`
+	WebhookID  uuid.UUID    `json:"webhookID" gorm:"type:uuid;not null;index"`
+	EventType  ActivityType `json:"eventType" gorm:"not null"`
+	Payload    string       `json:"payload" gorm:"type:text"`
+	Attempts   int          `json:"attempts"`
+	StatusCode int          `json:"statusCode"` // Zero when no response was received
+	LastError  string       `json:"lastError" gorm:"type:text"`
+	CreatedAt  time.Time    `json:"createdAt"`
+}
+
+func (l *WebhookDeadLetter) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// WebhookEventTypes lists the activity types a webhook subscribes to, stored as JSONB
+type WebhookEventTypes []string
+
+func (t WebhookEventTypes) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func (t *WebhookEventTypes) Scan(value interface{}) error {
+	if value == nil {
+		*t = WebhookEventTypes{}
+		return nil
+	}
+
+	var data []byte
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported webhook event types type: %T", value)
+	}
+
+	result := WebhookEventTypes{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return err
+	}
+	*t = result
+	return nil
+}
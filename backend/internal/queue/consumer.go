@@ -0,0 +1,318 @@
+// backend/internal/queue/consumer.go
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/streadway/amqp"
+)
+
+// Queue consumed by the backend itself for thumbnail and page count generation
+const DocumentPreviewQueue = "document.preview"
+
+// Suffixes of the queues declared next to every consumed queue, see doc.go
+const (
+	RetryQueueSuffix      = ".retry"
+	DeadLetterQueueSuffix = ".dlq"
+)
+
+// Headers the consumer keeps on retried and dead-lettered messages
+const (
+	headerRetryCount    = "x-retry-count"
+	headerLastError     = "x-last-error"
+	headerOriginalQueue = "x-original-queue"
+	headerFailedAt      = "x-failed-at"
+)
+
+// Keeps error headers small, they travel with every retry
+const maxErrorHeaderLength = 1000
+
+const processedKeyPrefix = "queue:processed:"
+
+// Handler processes a single message body. Returning an error retries the message with
+// backoff, returning an error wrapped with Permanent dead-letters it right away.
+type Handler func(ctx context.Context, body []byte) error
+
+// DeadLetterHandler is called once a message is moved to the dead-letter queue, with the
+// error of the last attempt
+type DeadLetterHandler func(ctx context.Context, body []byte, cause error)
+
+// Remembers the message IDs that were handled, so duplicate deliveries are skipped.
+// Satisfied by the redis client.
+type ProcessedStore interface {
+	Exists(key string) (bool, error)
+	SetWithExpiry(key string, value interface{}, expiry time.Duration) error
+}
+
+// How often and how far apart failed messages are retried before they are dead-lettered
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// Returns the delay before the given retry, doubling from BaseDelay up to MaxDelay
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a handler error that retrying can't fix, such as a malformed message
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+type Consumer struct {
+	url            string
+	prefetchCount  int
+	reconnectDelay time.Duration
+	retry          RetryPolicy
+	processed      ProcessedStore // nil disables duplicate detection
+	dedupWindow    time.Duration
+}
+
+func NewConsumer(url string, prefetchCount int, reconnectDelay time.Duration, retry RetryPolicy, processed ProcessedStore, dedupWindow time.Duration) *Consumer {
+	if reconnectDelay <= 0 {
+		reconnectDelay = 5 * time.Second
+	}
+	if retry.BaseDelay <= 0 {
+		retry.BaseDelay = time.Second
+	}
+
+	return &Consumer{
+		url:            url,
+		prefetchCount:  prefetchCount,
+		reconnectDelay: reconnectDelay,
+		retry:          retry,
+		processed:      processed,
+		dedupWindow:    dedupWindow,
+	}
+}
+
+// Consume handles messages from queueName in the background until ctx is cancelled,
+// reconnecting whenever the connection drops. onDeadLetter may be nil.
+func (c *Consumer) Consume(ctx context.Context, queueName string, handler Handler, onDeadLetter DeadLetterHandler) {
+	go func() {
+		for {
+			err := c.consume(ctx, queueName, handler, onDeadLetter)
+			if ctx.Err() != nil {
+				logrus.Infof("[CONSUMER] Stopped consuming %s", queueName)
+				return
+			}
+
+			logrus.Errorf("[CONSUMER] Consumer for %s stopped: %v. Reconnecting in %s", queueName, err, c.reconnectDelay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.reconnectDelay):
+			}
+		}
+	}()
+}
+
+func (c *Consumer) consume(ctx context.Context, queueName string, handler Handler, onDeadLetter DeadLetterHandler) error {
+	conn, err := amqp.Dial(c.url)
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := declareConsumerTopology(ch, queueName); err != nil {
+		return err
+	}
+
+	if c.prefetchCount > 0 {
+		if err := ch.Qos(c.prefetchCount, 0, false); err != nil {
+			return fmt.Errorf("failed to set prefetch count: %w", err)
+		}
+	}
+
+	msgs, err := ch.Consume(
+		queueName, // queue
+		"",        // consumer tag
+		false,     // auto-ack
+		false,     // exclusive
+		false,     // no-local
+		false,     // no-wait
+		nil,       // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to consume from %s: %w", queueName, err)
+	}
+
+	logrus.Infof("[CONSUMER] Consuming %s", queueName)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-msgs:
+			if !ok {
+				return fmt.Errorf("delivery channel closed")
+			}
+
+			c.handle(ctx, ch, queueName, msg, handler, onDeadLetter)
+		}
+	}
+}
+
+// Declares the queue with its retry and dead-letter queues. Expired retry messages are
+// routed back to the queue by the default exchange.
+func declareConsumerTopology(ch *amqp.Channel, queueName string) error {
+	queues := []struct {
+		name string
+		args amqp.Table
+	}{
+		{name: queueName},
+		{name: queueName + RetryQueueSuffix, args: amqp.Table{
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queueName,
+		}},
+		{name: queueName + DeadLetterQueueSuffix},
+	}
+
+	for _, queue := range queues {
+		if _, err := ch.QueueDeclare(queue.name, true, false, false, false, queue.args); err != nil {
+			return fmt.Errorf("failed to declare queue %s: %w", queue.name, err)
+		}
+	}
+	return nil
+}
+
+// Runs the handler on a delivery, then acks it, schedules a retry or dead-letters it
+func (c *Consumer) handle(ctx context.Context, ch *amqp.Channel, queueName string, msg amqp.Delivery, handler Handler, onDeadLetter DeadLetterHandler) {
+	processedKey := ""
+	if c.processed != nil && msg.MessageId != "" {
+		processedKey = processedKeyPrefix + queueName + ":" + msg.MessageId
+		if done, err := c.processed.Exists(processedKey); err != nil {
+			logrus.Warnf("[CONSUMER] Failed to check message %s on %s for duplicates: %v", msg.MessageId, queueName, err)
+		} else if done {
+			logrus.Infof("[CONSUMER] Skipping duplicate message %s on %s", msg.MessageId, queueName)
+			msg.Ack(false)
+			return
+		}
+	}
+
+	err := handler(ctx, msg.Body)
+	if err == nil {
+		if processedKey != "" {
+			if err := c.processed.SetWithExpiry(processedKey, "1", c.dedupWindow); err != nil {
+				logrus.Warnf("[CONSUMER] Failed to record message %s on %s as processed: %v", msg.MessageId, queueName, err)
+			}
+		}
+		msg.Ack(false)
+		return
+	}
+
+	// Shutting down, leave the message to the next consumer
+	if ctx.Err() != nil {
+		msg.Nack(false, true)
+		return
+	}
+
+	retries := retryCount(msg.Headers)
+	var permanent *permanentError
+	if !errors.As(err, &permanent) && retries < c.retry.MaxRetries {
+		delay := c.retry.Backoff(retries + 1)
+		headers := copyHeaders(msg.Headers)
+		headers[headerRetryCount] = int32(retries + 1)
+		headers[headerLastError] = truncateError(err)
+
+		publishing := republishing(msg, headers)
+		publishing.Expiration = strconv.FormatInt(delay.Milliseconds(), 10)
+		if pubErr := ch.Publish("", queueName+RetryQueueSuffix, false, false, publishing); pubErr != nil {
+			logrus.Errorf("[CONSUMER] Failed to schedule retry for message from %s, requeueing: %v", queueName, pubErr)
+			msg.Nack(false, true)
+			return
+		}
+
+		logrus.Warnf("[CONSUMER] Failed to handle message from %s, retry %d/%d in %s: %v", queueName, retries+1, c.retry.MaxRetries, delay, err)
+		msg.Ack(false)
+		return
+	}
+
+	headers := copyHeaders(msg.Headers)
+	headers[headerLastError] = truncateError(err)
+	headers[headerOriginalQueue] = queueName
+	headers[headerFailedAt] = time.Now().UTC().Format(time.RFC3339)
+	if pubErr := ch.Publish("", queueName+DeadLetterQueueSuffix, false, false, republishing(msg, headers)); pubErr != nil {
+		logrus.Errorf("[CONSUMER] Failed to dead-letter message from %s, requeueing: %v", queueName, pubErr)
+		msg.Nack(false, true)
+		return
+	}
+
+	logrus.Errorf("[CONSUMER] Message from %s dead-lettered after %d retries: %v", queueName, retries, err)
+	if onDeadLetter != nil {
+		onDeadLetter(ctx, msg.Body, err)
+	}
+	msg.Ack(false)
+}
+
+// Copies a delivery into a new persistent message with the given headers
+func republishing(msg amqp.Delivery, headers amqp.Table) amqp.Publishing {
+	return amqp.Publishing{
+		ContentType:  msg.ContentType,
+		MessageId:    msg.MessageId,
+		Timestamp:    msg.Timestamp,
+		Headers:      headers,
+		Body:         msg.Body,
+		DeliveryMode: amqp.Persistent,
+	}
+}
+
+func copyHeaders(headers amqp.Table) amqp.Table {
+	copied := make(amqp.Table, len(headers)+4)
+	for key, value := range headers {
+		copied[key] = value
+	}
+	return copied
+}
+
+// Reads the retry count header, its integer type depends on who published the message
+func retryCount(headers amqp.Table) int {
+	switch value := headers[headerRetryCount].(type) {
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case int:
+		return value
+	default:
+		return 0
+	}
+}
+
+func truncateError(err error) string {
+	message := err.Error()
+	if len(message) > maxErrorHeaderLength {
+		return message[:maxErrorHeaderLength]
+	}
+	return message
+}
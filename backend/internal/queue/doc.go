@@ -0,0 +1,27 @@
+// Package queue publishes work to RabbitMQ and consumes the queues the backend handles itself.
+//
+// Queue topology, all queues are durable and bound to the default exchange:
+//
+//	document.text.embedding   text embedding, consumed by the workers
+//	document.image.embedding  image embedding, consumed by the workers
+//	document.summarization    summaries, consumed by the workers
+//	document.extraction       declared for the workers, not published to by the backend
+//	document.preview          page count, thumbnail and search text, consumed by the backend
+//	query.embedding           search query embedding requests, consumed by the workers
+//	query.embedding.reply     embedding replies, consumed by the publisher
+//
+// Every queue consumed through Consumer gets two companions:
+//
+//	<queue>.retry  failed messages wait here for their backoff delay (per-message expiration),
+//	               then the broker dead-letters them back to <queue>
+//	<queue>.dlq    messages that failed permanently or ran out of retries, kept for inspection
+//
+// The attempt number travels in the x-retry-count header and the last error in x-last-error.
+// Dead-lettered messages also carry x-original-queue and x-failed-at. Retry delays double from
+// RABBITMQ_RETRY_BASE_DELAY up to RABBITMQ_RETRY_MAX_DELAY. The retry queue is FIFO, so a
+// message with a short delay can wait behind one with a longer delay, it is never lost.
+//
+// Document messages carry an idempotency key as the AMQP message ID, see DocumentMessageKey.
+// When Redis is available the consumer records the IDs it handled and acks duplicates
+// without running the handler again.
+package queue
@@ -66,6 +66,7 @@ func (p *Publisher) connect() error {
 		"document.text.embedding",
 		"document.image.embedding",
 		"document.summarization",
+		DocumentPreviewQueue,
 		"query.embedding",
 		"query.embedding.reply",
 	}
@@ -96,8 +97,16 @@ func (p *Publisher) ensureConnection() error {
 	return nil
 }
 
-// PublishDocumentForProcessing now sends storage path instead of presigned URL
-func (p *Publisher) PublishDocumentForProcessing(documentID, storagePath string) error {
+// DocumentMessageKey is the idempotency key of a document's processing run. Every upload
+// gets its own storage path, so the key changes with the file and duplicate publishes of
+// the same file share it.
+func DocumentMessageKey(documentID, storagePath string) string {
+	return documentID + ":" + storagePath
+}
+
+// PublishDocumentForProcessing now sends storage path instead of presigned URL.
+// idempotencyKey is sent as the message ID so consumers can skip duplicate deliveries.
+func (p *Publisher) PublishDocumentForProcessing(documentID, storagePath, idempotencyKey string) error {
 	if err := p.ensureConnection(); err != nil {
 		return fmt.Errorf("failed to ensure RabbitMQ connection: %w", err)
 	}
@@ -129,6 +138,7 @@ func (p *Publisher) PublishDocumentForProcessing(documentID, storagePath string)
 			false,     // immediate
 			amqp.Publishing{
 				ContentType:  "application/json",
+				MessageId:    idempotencyKey,
 				Body:         body,
 				DeliveryMode: amqp.Persistent,
 			},
@@ -141,7 +151,7 @@ func (p *Publisher) PublishDocumentForProcessing(documentID, storagePath string)
 			}
 			err = p.channel.Publish(
 				"", queueName, false, false,
-				amqp.Publishing{ContentType: "application/json", Body: body, DeliveryMode: amqp.Persistent},
+				amqp.Publishing{ContentType: "application/json", MessageId: idempotencyKey, Body: body, DeliveryMode: amqp.Persistent},
 			)
 		}
 
@@ -153,6 +163,62 @@ func (p *Publisher) PublishDocumentForProcessing(documentID, storagePath string)
 	return nil
 }
 
+// DocumentPreviewMessage asks the backend to generate page count and thumbnail for a stored document
+type DocumentPreviewMessage struct {
+	DocumentID  string `json:"document_id"`
+	StoragePath string `json:"storage_path"`
+	Timestamp   int64  `json:"timestamp"`
+}
+
+// PublishDocumentForPreview queues page count and thumbnail generation for a document.
+// The consumer handles a message once per idempotencyKey.
+func (p *Publisher) PublishDocumentForPreview(documentID, storagePath, idempotencyKey string) error {
+	if err := p.ensureConnection(); err != nil {
+		return fmt.Errorf("failed to ensure RabbitMQ connection: %w", err)
+	}
+
+	message := DocumentPreviewMessage{
+		DocumentID:  documentID,
+		StoragePath: storagePath,
+		Timestamp:   time.Now().Unix(),
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	err = p.channel.Publish(
+		"",                   // exchange
+		DocumentPreviewQueue, // routing key (queue name)
+		false,                // mandatory
+		false,                // immediate
+		amqp.Publishing{
+			ContentType:  "application/json",
+			MessageId:    idempotencyKey,
+			Body:         body,
+			DeliveryMode: amqp.Persistent,
+		},
+	)
+
+	if errors.Is(err, amqp.ErrClosed) {
+		logrus.Warnf("Publish to %s failed due to closed channel. Retrying after reconnect...", DocumentPreviewQueue)
+		if reconErr := p.ensureConnection(); reconErr != nil {
+			return fmt.Errorf("failed to reconnect for retrying publish: %w", reconErr)
+		}
+		err = p.channel.Publish(
+			"", DocumentPreviewQueue, false, false,
+			amqp.Publishing{ContentType: "application/json", MessageId: idempotencyKey, Body: body, DeliveryMode: amqp.Persistent},
+		)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to publish preview message after retry: %w", err)
+	}
+
+	return nil
+}
+
 // QueryEmbeddingRequest represents a request for query embedding
 type QueryEmbeddingRequest struct {
 	RequestID  string `json:"request_id"`
@@ -15,6 +15,11 @@ func (r *Client) HGetAll(key string) (map[string]string, error) {
 	return r.Client.HGetAll(r.ctx, key).Result()
 }
 
+// HIncrBy increments a hash field by the given amount
+func (r *Client) HIncrBy(key, field string, incr int64) (int64, error) {
+	return r.Client.HIncrBy(r.ctx, key, field, incr).Result()
+}
+
 // HDel deletes hash fields
 func (r *Client) HDel(key string, fields ...string) error {
 	return r.Client.HDel(r.ctx, key, fields...).Err()
@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Keys are hashed so client supplied values of any length map to a bounded Redis key
+func idempotencyKey(userID, key string) string {
+	return fmt.Sprintf("idempotency:%s:%x", userID, sha256.Sum256([]byte(key)))
+}
+
+// ReserveIdempotencyKey claims an idempotency key of a user for a request in flight. Returns false
+// when the key is already claimed or holds a stored response.
+func (r *Client) ReserveIdempotencyKey(userID, key string, record []byte, ttl time.Duration) (bool, error) {
+	return r.SetNX(idempotencyKey(userID, key), record, ttl)
+}
+
+// GetIdempotencyRecord returns what is stored under an idempotency key of a user, nil when nothing is
+func (r *Client) GetIdempotencyRecord(userID, key string) ([]byte, error) {
+	val, err := r.Client.Get(r.ctx, idempotencyKey(userID, key)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return val, nil
+}
+
+// SetIdempotencyRecord stores the response of a completed request under an idempotency key of a user
+func (r *Client) SetIdempotencyRecord(userID, key string, record []byte, ttl time.Duration) error {
+	return r.SetWithExpiry(idempotencyKey(userID, key), record, ttl)
+}
+
+// ReleaseIdempotencyKey frees an idempotency key of a user so the request can be retried
+func (r *Client) ReleaseIdempotencyKey(userID, key string) error {
+	return r.Delete(idempotencyKey(userID, key))
+}
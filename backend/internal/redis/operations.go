@@ -37,6 +37,16 @@ func (r *Client) IncrementWithExpiry(key string, expiry time.Duration) (int64, e
 	return incrCmd.Val(), nil
 }
 
+// SetNX sets a key with expiration only if it does not exist
+func (r *Client) SetNX(key string, value interface{}, expiry time.Duration) (bool, error) {
+	return r.Client.SetNX(r.ctx, key, value, expiry).Result()
+}
+
+// Rename renames a key, replacing the destination if it exists
+func (r *Client) Rename(key, newKey string) error {
+	return r.Client.Rename(r.ctx, key, newKey).Err()
+}
+
 // Exists checks if a key exists
 func (r *Client) Exists(key string) (bool, error) {
 	result, err := r.Client.Exists(r.ctx, key).Result()
@@ -32,3 +32,27 @@ func (r *Client) IncrementShareAttempt(clientIP string, window time.Duration) (i
 	key := fmt.Sprintf("share_attempt:%s", clientIP)
 	return r.IncrementWithExpiry(key, window)
 }
+
+// GetShareUnlockFailures gets the failed password attempts for a shared link
+func (r *Client) GetShareUnlockFailures(token string) (int64, error) {
+	key := fmt.Sprintf("share_unlock:%s", token)
+	val, err := r.Client.Get(r.ctx, key).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// IncrementShareUnlockFailure records a failed password attempt for a shared link
+func (r *Client) IncrementShareUnlockFailure(token string, window time.Duration) (int64, error) {
+	key := fmt.Sprintf("share_unlock:%s", token)
+	return r.IncrementWithExpiry(key, window)
+}
+
+// ClearShareUnlockFailures resets failed password attempts after a successful unlock
+func (r *Client) ClearShareUnlockFailures(token string) error {
+	return r.Delete(fmt.Sprintf("share_unlock:%s", token))
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/types"
@@ -13,20 +14,58 @@ type DocumentRepository interface {
 	Create(ctx context.Context, document *models.Document) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error)
 	GetByIDAndUserID(ctx context.Context, id, userID uuid.UUID) (*models.Document, error)
+	GetByContentHash(ctx context.Context, userID uuid.UUID, contentHash string) (*models.Document, error)
 	Update(ctx context.Context, document *models.Document) error
+	UpdateWithRevision(ctx context.Context, document *models.Document, revision *models.DocumentRevision) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	MoveToFolder(ctx context.Context, id uuid.UUID, folderID *uuid.UUID) error
+
+	// Processing
+	UpdateProcessingResult(ctx context.Context, id uuid.UUID, storagePath string, fields map[string]interface{}) error
+	ListMissingContentText(ctx context.Context, fileTypes []models.DocumentType, afterID uuid.UUID, limit int) ([]models.Document, error)
+	ListStuckProcessing(ctx context.Context, updatedBefore time.Time, limit int) ([]models.Document, error)
+
+	// Trash
+	Trash(ctx context.Context, id uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	Purge(ctx context.Context, id uuid.UUID) error
+	GetByIDAndUserIDWithTrashed(ctx context.Context, id, userID uuid.UUID) (*models.Document, error)
+	GetTrashedByIDAndUserID(ctx context.Context, id, userID uuid.UUID) (*models.Document, error)
+	ListTrashed(ctx context.Context, userID uuid.UUID, page, limit int) ([]models.Document, int64, error)
+	ListTrashedBefore(ctx context.Context, cutoff time.Time, afterID uuid.UUID, limit int) ([]models.Document, error)
+
+	// Expiration
+	ListExpired(ctx context.Context, now time.Time, afterID uuid.UUID, limit int) ([]models.Document, error)
+	ListExpiringUnnotified(ctx context.Context, before time.Time, limit int) ([]models.Document, error)
+	MarkExpiryNotified(ctx context.Context, id uuid.UUID) error
+
+	// Tags
+	ListUserTags(ctx context.Context, userID uuid.UUID) ([]types.TagCount, error)
+
+	// Review
+	ListPendingReview(ctx context.Context, userID uuid.UUID, page, limit int) ([]types.PendingReview, int64, error)
+
+	// Recently viewed or downloaded
+	ListRecentlyAccessed(ctx context.Context, userID uuid.UUID, limit int) ([]types.RecentAccess, error)
 
 	// Stats
 	GetUserStats(ctx context.Context, userID uuid.UUID) (*types.UserStatsResponse, error)
+	GetStorageBreakdown(ctx context.Context, userID uuid.UUID, since time.Time) (*types.StorageBreakdownResponse, error)
 	GetRevisions(ctx context.Context, documentID uuid.UUID) ([]models.DocumentRevision, error)
+	GetRevision(ctx context.Context, documentID, revisionID uuid.UUID) (*models.DocumentRevision, error)
+	PruneRevisions(ctx context.Context, documentID uuid.UUID, keep int) ([]models.DocumentRevision, error)
 
 	// Count operations
 	IncrementViewCount(ctx context.Context, id uuid.UUID) error
 	IncrementDownloadCount(ctx context.Context, id uuid.UUID) error
+	AddCounts(ctx context.Context, id uuid.UUID, views, downloads int64) error
 }
 
 type DocumentSearchRepository interface {
 	// Search operations
 	SearchDocuments(ctx context.Context, req *types.SearchRequest) (*types.SearchResult, error)
 	CountSearchResults(ctx context.Context, req *types.SearchRequest) (int64, error)
+
+	// Keyset pagination by creation time, limit documents following the cursor (nil for the first page)
+	ListAfterCursor(ctx context.Context, req *types.SearchRequest, after *types.DocumentCursor, limit int) ([]models.Document, error)
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/eyuppastirmaci/noesis-forge/internal/types"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type documentRepository struct {
@@ -21,7 +22,12 @@ func NewDocumentRepository(db *gorm.DB) interfaces.DocumentRepository {
 }
 
 func (r *documentRepository) Create(ctx context.Context, document *models.Document) error {
-	return r.db.WithContext(ctx).Create(document).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(document).Error; err != nil {
+			return err
+		}
+		return replaceDocumentTags(tx, document.ID, models.NormalizeTags(document.Tags))
+	})
 }
 
 func (r *documentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error) {
@@ -46,14 +52,468 @@ func (r *documentRepository) GetByIDAndUserID(ctx context.Context, id, userID uu
 	return &document, nil
 }
 
+// Returns the user's oldest document with the given content, trashed documents are ignored
+func (r *documentRepository) GetByContentHash(ctx context.Context, userID uuid.UUID, contentHash string) (*models.Document, error) {
+	var document models.Document
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND content_hash = ?", userID, contentHash).
+		Order("created_at ASC").
+		First(&document).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("document not found")
+		}
+		return nil, fmt.Errorf("failed to fetch document: %w", err)
+	}
+	return &document, nil
+}
+
 func (r *documentRepository) Update(ctx context.Context, document *models.Document) error {
-	return r.db.WithContext(ctx).Save(document).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(document).Error; err != nil {
+			return err
+		}
+		return replaceDocumentTags(tx, document.ID, models.NormalizeTags(document.Tags))
+	})
+}
+
+// Files the document in a folder, nil moves it to the root
+func (r *documentRepository) MoveToFolder(ctx context.Context, id uuid.UUID, folderID *uuid.UUID) error {
+	if err := r.db.WithContext(ctx).Model(&models.Document{}).Where("id = ?", id).Update("folder_id", folderID).Error; err != nil {
+		return fmt.Errorf("failed to move document: %w", err)
+	}
+	return nil
+}
+
+// Saves the document and records the revision it replaces atomically
+func (r *documentRepository) UpdateWithRevision(ctx context.Context, document *models.Document, revision *models.DocumentRevision) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return fmt.Errorf("failed to create revision: %w", err)
+		}
+		if err := tx.Save(document).Error; err != nil {
+			return err
+		}
+		return replaceDocumentTags(tx, document.ID, models.NormalizeTags(document.Tags))
+	})
+}
+
+// Points the document at exactly the given tags, creating tags that do not exist yet
+func replaceDocumentTags(tx *gorm.DB, documentID uuid.UUID, names []string) error {
+	if err := tx.Where("document_id = ?", documentID).Delete(&models.DocumentTag{}).Error; err != nil {
+		return fmt.Errorf("failed to clear document tags: %w", err)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, models.Tag{Name: name})
+	}
+	if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).
+		Create(&tags).Error; err != nil {
+		return fmt.Errorf("failed to create tags: %w", err)
+	}
+
+	// Conflicting rows keep their generated IDs, so read back the stored ones
+	var stored []models.Tag
+	if err := tx.Where("name IN ?", names).Find(&stored).Error; err != nil {
+		return fmt.Errorf("failed to fetch tags: %w", err)
+	}
+
+	links := make([]models.DocumentTag, 0, len(stored))
+	for _, tag := range stored {
+		links = append(links, models.DocumentTag{DocumentID: documentID, TagID: tag.ID})
+	}
+	if err := tx.Create(&links).Error; err != nil {
+		return fmt.Errorf("failed to link document tags: %w", err)
+	}
+	return nil
+}
+
+// Returns the distinct tags of the user's documents with how many documents use each
+func (r *documentRepository) ListUserTags(ctx context.Context, userID uuid.UUID) ([]types.TagCount, error) {
+	var tags []types.TagCount
+	err := r.db.WithContext(ctx).
+		Table("tags t").
+		Select("t.name AS name, COUNT(*) AS document_count").
+		Joins("JOIN document_tags dt ON dt.tag_id = t.id").
+		Joins("JOIN documents d ON d.id = dt.document_id AND d.deleted_at IS NULL").
+		Where("d.user_id = ?", userID).
+		Group("t.name").
+		Order("document_count DESC, t.name ASC").
+		Scan(&tags).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tags: %w", err)
+	}
+	return tags, nil
 }
 
 func (r *documentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Delete(&models.Document{}, id).Error
 }
 
+// Applies background processing output, skipped when the file was replaced in the meantime
+func (r *documentRepository) UpdateProcessingResult(ctx context.Context, id uuid.UUID, storagePath string, fields map[string]interface{}) error {
+	result := r.db.WithContext(ctx).Model(&models.Document{}).
+		Where("id = ? AND storage_path = ?", id, storagePath).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("document not found")
+	}
+	return nil
+}
+
+// Lists documents that have been processing since before updatedBefore, oldest first
+func (r *documentRepository) ListStuckProcessing(ctx context.Context, updatedBefore time.Time, limit int) ([]models.Document, error) {
+	var documents []models.Document
+	if err := r.db.WithContext(ctx).
+		Where("status = ? AND updated_at < ?", models.DocumentStatusProcessing, updatedBefore).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&documents).Error; err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// Lists ready documents whose text has not been extracted yet, in id order after afterID
+func (r *documentRepository) ListMissingContentText(ctx context.Context, fileTypes []models.DocumentType, afterID uuid.UUID, limit int) ([]models.Document, error) {
+	var documents []models.Document
+	if err := r.db.WithContext(ctx).
+		Where("content_text IS NULL AND status = ? AND file_type IN ? AND id > ?", models.DocumentStatusReady, fileTypes, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch documents without text: %w", err)
+	}
+	return documents, nil
+}
+
+// Moves a document to the trash without touching its stored files
+func (r *documentRepository) Trash(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Model(&models.Document{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     models.DocumentStatusDeleted,
+			"deleted_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("document not found")
+	}
+	return nil
+}
+
+// Brings a trashed document back, re-assigning title so the search vector trigger re-indexes it
+func (r *documentRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Unscoped().Model(&models.Document{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Updates(map[string]interface{}{
+			"status":     models.DocumentStatusReady,
+			"deleted_at": nil,
+			"title":      gorm.Expr("title"),
+			// Documents trashed on expiry come back without the expiration that sent them there
+			"expiry_notified_at": gorm.Expr("CASE WHEN expires_at <= NOW() THEN NULL ELSE expiry_notified_at END"),
+			"expires_at":         gorm.Expr("CASE WHEN expires_at <= NOW() THEN NULL ELSE expires_at END"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("document not found in trash")
+	}
+	return nil
+}
+
+// Permanently removes the document record together with its revision history and every
+// share of it. Revoked shares are only soft deleted, their rows still reference the document.
+func (r *documentRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ?", id).Delete(&models.DocumentRevision{}).Error; err != nil {
+			return err
+		}
+
+		// Audit logs reference the shares, not the document
+		userShares := tx.Unscoped().Model(&models.UserShare{}).Select("id").Where("document_id = ?", id)
+		if err := tx.Where("user_share_id IN (?)", userShares).Delete(&models.UserShareAuditLog{}).Error; err != nil {
+			return fmt.Errorf("failed to delete user share audit logs: %w", err)
+		}
+		sharedLinks := tx.Unscoped().Model(&models.SharedLink{}).Select("id").Where("document_id = ?", id)
+		if err := tx.Where("shared_link_id IN (?)", sharedLinks).Delete(&models.ShareAuditLog{}).Error; err != nil {
+			return fmt.Errorf("failed to delete shared link audit logs: %w", err)
+		}
+
+		for _, share := range []interface{}{
+			&models.ShareNotification{},
+			&models.ShareInvitation{},
+			&models.GroupShare{},
+			&models.UserShare{},
+			&models.SharedLink{},
+		} {
+			if err := tx.Unscoped().Where("document_id = ?", id).Delete(share).Error; err != nil {
+				return fmt.Errorf("failed to delete shares: %w", err)
+			}
+		}
+
+		return tx.Unscoped().Delete(&models.Document{}, id).Error
+	})
+}
+
+func (r *documentRepository) GetByIDAndUserIDWithTrashed(ctx context.Context, id, userID uuid.UUID) (*models.Document, error) {
+	var document models.Document
+	if err := r.db.WithContext(ctx).Unscoped().Where("id = ? AND user_id = ?", id, userID).First(&document).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("document not found")
+		}
+		return nil, fmt.Errorf("failed to fetch document: %w", err)
+	}
+	return &document, nil
+}
+
+func (r *documentRepository) GetTrashedByIDAndUserID(ctx context.Context, id, userID uuid.UUID) (*models.Document, error) {
+	var document models.Document
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", id, userID).
+		First(&document).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("document not found in trash")
+		}
+		return nil, fmt.Errorf("failed to fetch document: %w", err)
+	}
+	return &document, nil
+}
+
+func (r *documentRepository) ListTrashed(ctx context.Context, userID uuid.UUID, page, limit int) ([]models.Document, int64, error) {
+	query := r.db.WithContext(ctx).Unscoped().Model(&models.Document{}).
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count trashed documents: %w", err)
+	}
+
+	var documents []models.Document
+	if err := query.Order("deleted_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&documents).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch trashed documents: %w", err)
+	}
+
+	return documents, total, nil
+}
+
+// Lists documents trashed before cutoff, in id order after afterID
+func (r *documentRepository) ListTrashedBefore(ctx context.Context, cutoff time.Time, afterID uuid.UUID, limit int) ([]models.Document, error) {
+	var documents []models.Document
+	if err := r.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ? AND id > ?", cutoff, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch expired trash: %w", err)
+	}
+	return documents, nil
+}
+
+// Lists documents whose expiration time has passed, in id order after afterID
+func (r *documentRepository) ListExpired(ctx context.Context, now time.Time, afterID uuid.UUID, limit int) ([]models.Document, error) {
+	var documents []models.Document
+	if err := r.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at <= ? AND id > ?", now, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch expired documents: %w", err)
+	}
+	return documents, nil
+}
+
+// Lists documents expiring before the given time whose owner has not been warned yet
+func (r *documentRepository) ListExpiringUnnotified(ctx context.Context, before time.Time, limit int) ([]models.Document, error) {
+	var documents []models.Document
+	if err := r.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at > ? AND expires_at <= ? AND expiry_notified_at IS NULL", time.Now(), before).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch expiring documents: %w", err)
+	}
+	return documents, nil
+}
+
+// Records that the owner was warned about the document's expiry
+func (r *documentRepository) MarkExpiryNotified(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.Document{}).
+		Where("id = ?", id).
+		UpdateColumn("expiry_notified_at", time.Now()).Error
+}
+
+// Lists documents the user owns or can edit, directly or through a group, that have unresolved
+// top-level comments, oldest unresolved comment first
+func (r *documentRepository) ListPendingReview(ctx context.Context, userID uuid.UUID, page, limit int) ([]types.PendingReview, int64, error) {
+	unresolved := r.db.Model(&models.DocumentComment{}).
+		Select("document_id, COUNT(*) AS unresolved_count, MIN(created_at) AS oldest_unresolved_at").
+		Where("is_resolved = false AND parent_comment_id IS NULL").
+		Group("document_id")
+
+	query := r.db.WithContext(ctx).Table("documents").
+		Joins("JOIN (?) AS unresolved ON unresolved.document_id = documents.id", unresolved).
+		Where("documents.deleted_at IS NULL").
+		Where("documents.expires_at IS NULL OR documents.expires_at > ?", time.Now()).
+		Where(`(documents.user_id = ? OR EXISTS (
+			SELECT 1 FROM user_shares
+			WHERE user_shares.document_id = documents.id
+				AND user_shares.shared_with_user_id = ?
+				AND user_shares.access_level = ?
+				AND user_shares.is_revoked = false
+				AND (user_shares.expires_at IS NULL OR user_shares.expires_at > ?)
+				AND user_shares.deleted_at IS NULL
+		) OR EXISTS (
+			SELECT 1 FROM group_shares
+			JOIN group_members ON group_members.group_id = group_shares.group_id
+			WHERE group_shares.document_id = documents.id
+				AND group_members.user_id = ?
+				AND group_shares.access_level = ?
+				AND group_shares.is_revoked = false
+				AND (group_shares.expires_at IS NULL OR group_shares.expires_at > ?)
+		))`, userID, userID, models.AccessLevelEdit, time.Now(), userID, models.AccessLevelEdit, time.Now())
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count documents pending review: %w", err)
+	}
+
+	var rows []struct {
+		DocumentID         uuid.UUID
+		UnresolvedCount    int64
+		OldestUnresolvedAt time.Time
+	}
+	if err := query.Select("documents.id AS document_id, unresolved.unresolved_count, unresolved.oldest_unresolved_at").
+		Order("unresolved.oldest_unresolved_at ASC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch documents pending review: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return []types.PendingReview{}, total, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.DocumentID)
+	}
+
+	var documents []models.Document
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&documents).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch documents pending review: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]models.Document, len(documents))
+	for _, document := range documents {
+		byID[document.ID] = document
+	}
+
+	// Keep the ordering of the aggregate query
+	reviews := make([]types.PendingReview, 0, len(rows))
+	for _, row := range rows {
+		document, ok := byID[row.DocumentID]
+		if !ok {
+			continue
+		}
+		reviews = append(reviews, types.PendingReview{
+			Document:           document,
+			UnresolvedCount:    row.UnresolvedCount,
+			OldestUnresolvedAt: row.OldestUnresolvedAt,
+		})
+	}
+
+	return reviews, total, nil
+}
+
+// Returns the documents the user last viewed or downloaded, newest first. Only documents the
+// user owns or holds an active share of are listed.
+func (r *documentRepository) ListRecentlyAccessed(ctx context.Context, userID uuid.UUID, limit int) ([]types.RecentAccess, error) {
+	latest := r.db.Model(&models.DocumentActivity{}).
+		Select("DISTINCT ON (document_id) document_id, activity_type, created_at").
+		Where("user_id = ? AND activity_type IN ?", userID, []models.ActivityType{models.ActivityTypeView, models.ActivityTypeDownload}).
+		Order("document_id, created_at DESC")
+
+	var rows []struct {
+		DocumentID   uuid.UUID
+		ActivityType models.ActivityType
+		AccessedAt   time.Time
+	}
+	if err := r.db.WithContext(ctx).Table("documents").
+		Joins("JOIN (?) AS recent ON recent.document_id = documents.id", latest).
+		Where("documents.deleted_at IS NULL").
+		Where("documents.expires_at IS NULL OR documents.expires_at > ?", time.Now()).
+		Where(`(documents.user_id = ? OR EXISTS (
+			SELECT 1 FROM user_shares
+			WHERE user_shares.document_id = documents.id
+				AND user_shares.shared_with_user_id = ?
+				AND user_shares.is_revoked = false
+				AND (user_shares.expires_at IS NULL OR user_shares.expires_at > ?)
+				AND user_shares.deleted_at IS NULL
+		) OR EXISTS (
+			SELECT 1 FROM group_shares
+			JOIN group_members ON group_members.group_id = group_shares.group_id
+			WHERE group_shares.document_id = documents.id
+				AND group_members.user_id = ?
+				AND group_shares.is_revoked = false
+				AND (group_shares.expires_at IS NULL OR group_shares.expires_at > ?)
+		))`, userID, userID, time.Now(), userID, time.Now()).
+		Select("documents.id AS document_id, recent.activity_type, recent.created_at AS accessed_at").
+		Order("recent.created_at DESC").
+		Limit(limit).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch recent documents: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return []types.RecentAccess{}, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.DocumentID)
+	}
+
+	var documents []models.Document
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch recent documents: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]models.Document, len(documents))
+	for _, document := range documents {
+		byID[document.ID] = document
+	}
+
+	// Keep the ordering of the activity query
+	recent := make([]types.RecentAccess, 0, len(rows))
+	for _, row := range rows {
+		document, ok := byID[row.DocumentID]
+		if !ok {
+			continue
+		}
+		recent = append(recent, types.RecentAccess{
+			Document:   document,
+			AccessType: row.ActivityType,
+			AccessedAt: row.AccessedAt,
+		})
+	}
+
+	return recent, nil
+}
+
 func (r *documentRepository) IncrementViewCount(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Model(&models.Document{}).
 		Where("id = ?", id).
@@ -66,6 +526,16 @@ func (r *documentRepository) IncrementDownloadCount(ctx context.Context, id uuid
 		UpdateColumn("download_count", gorm.Expr("download_count + 1")).Error
 }
 
+// Applies accumulated counter deltas in a single atomic update
+func (r *documentRepository) AddCounts(ctx context.Context, id uuid.UUID, views, downloads int64) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Document{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + ?", views),
+			"download_count": gorm.Expr("download_count + ?", downloads),
+		}).Error
+}
+
 func (r *documentRepository) GetUserStats(ctx context.Context, userID uuid.UUID) (*types.UserStatsResponse, error) {
 	var stats types.UserStatsResponse
 
@@ -88,11 +558,80 @@ func (r *documentRepository) GetUserStats(ctx context.Context, userID uuid.UUID)
 	return &stats, nil
 }
 
+// Aggregates storage per file type, the timeline only holds months since the given time that had uploads
+func (r *documentRepository) GetStorageBreakdown(ctx context.Context, userID uuid.UUID, since time.Time) (*types.StorageBreakdownResponse, error) {
+	breakdown := &types.StorageBreakdownResponse{
+		ByFileType: []types.StorageByType{},
+		Timeline:   []types.MonthlyUploads{},
+	}
+
+	if err := r.db.WithContext(ctx).Model(&models.Document{}).
+		Select("file_type, COUNT(*) AS count, COALESCE(SUM(file_size), 0) AS total_bytes, COALESCE(ROUND(AVG(file_size)), 0) AS average_size").
+		Where("user_id = ?", userID).
+		Group("file_type").
+		Order("total_bytes DESC").
+		Scan(&breakdown.ByFileType).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate storage by file type: %w", err)
+	}
+
+	for _, stat := range breakdown.ByFileType {
+		breakdown.TotalDocuments += stat.Count
+		breakdown.TotalBytes += stat.TotalBytes
+	}
+	if breakdown.TotalDocuments > 0 {
+		breakdown.AverageSize = breakdown.TotalBytes / breakdown.TotalDocuments
+	}
+
+	if err := r.db.WithContext(ctx).Model(&models.Document{}).
+		Select("TO_CHAR(DATE_TRUNC('month', created_at AT TIME ZONE 'UTC'), 'YYYY-MM') AS month, COUNT(*) AS count, COALESCE(SUM(file_size), 0) AS total_bytes").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Group("month").
+		Order("month").
+		Scan(&breakdown.Timeline).Error; err != nil {
+		return nil, fmt.Errorf("failed to aggregate monthly uploads: %w", err)
+	}
+
+	return breakdown, nil
+}
+
 func (r *documentRepository) GetRevisions(ctx context.Context, documentID uuid.UUID) ([]models.DocumentRevision, error) {
 	var revisions []models.DocumentRevision
-	if err := r.db.WithContext(ctx).Where("document_id = ?", documentID).
+	// The text snapshots are only needed to compare two revisions
+	if err := r.db.WithContext(ctx).Omit("content_text").Where("document_id = ?", documentID).
 		Order("version DESC").Find(&revisions).Error; err != nil {
 		return nil, fmt.Errorf("failed to fetch revisions: %w", err)
 	}
 	return revisions, nil
 }
+
+func (r *documentRepository) GetRevision(ctx context.Context, documentID, revisionID uuid.UUID) (*models.DocumentRevision, error) {
+	var revision models.DocumentRevision
+	if err := r.db.WithContext(ctx).Where("id = ? AND document_id = ?", revisionID, documentID).First(&revision).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("revision not found")
+		}
+		return nil, fmt.Errorf("failed to fetch revision: %w", err)
+	}
+	return &revision, nil
+}
+
+// Deletes all but the newest keep revisions and returns the deleted ones
+func (r *documentRepository) PruneRevisions(ctx context.Context, documentID uuid.UUID, keep int) ([]models.DocumentRevision, error) {
+	var pruned []models.DocumentRevision
+	if err := r.db.WithContext(ctx).Where("document_id = ?", documentID).
+		Order("version DESC, created_at DESC").Offset(keep).Find(&pruned).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch revisions to prune: %w", err)
+	}
+	if len(pruned) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(pruned))
+	for i, revision := range pruned {
+		ids[i] = revision.ID
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&models.DocumentRevision{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to prune revisions: %w", err)
+	}
+	return pruned, nil
+}
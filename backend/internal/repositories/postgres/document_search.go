@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/types"
@@ -71,22 +73,63 @@ func (r *documentSearchRepository) CountSearchResults(ctx context.Context, req *
 	return total, nil
 }
 
+// Lists documents ordered by creation time and ID in the request's direction. Unlike offset
+// pagination the cost does not grow with the depth of the page, since the (user_id, created_at)
+// index seeks straight to the cursor.
+func (r *documentSearchRepository) ListAfterCursor(ctx context.Context, req *types.SearchRequest, after *types.DocumentCursor, limit int) ([]models.Document, error) {
+	query := r.applyFilters(r.db.WithContext(ctx).Model(&models.Document{}).Where("user_id = ?", req.UserID), req)
+
+	comparison, dir := "<", "DESC"
+	if strings.ToLower(req.SortDir) == "asc" {
+		comparison, dir = ">", "ASC"
+	}
+
+	if after != nil {
+		query = query.Where("(created_at, id) "+comparison+" (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var documents []models.Document
+	if err := query.
+		Order("created_at " + dir).
+		Order("id " + dir).
+		Limit(limit).
+		Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	return documents, nil
+}
+
 func (r *documentSearchRepository) applyFilters(q *gorm.DB, req *types.SearchRequest) *gorm.DB {
+	// Expired documents are gone for the user even before the sweep removes them
+	q = q.Where("expires_at IS NULL OR expires_at > ?", time.Now())
 	if req.FileType != "" && req.FileType != "all" {
 		q = q.Where("file_type = ?", req.FileType)
 	}
 	if req.Status != "" && req.Status != "all" {
 		q = q.Where("status = ?", req.Status)
 	}
-	if req.Tags != "" {
-		tags := strings.Split(req.Tags, ",")
-		for _, tag := range tags {
-			trimmedTag := strings.TrimSpace(tag)
-			if trimmedTag != "" {
-				q = q.Where("tags ILIKE ?", "%"+trimmedTag+"%")
-			}
+	if req.Language != "" {
+		q = q.Where("language = ?", req.Language)
+	}
+	if len(req.FolderIDs) > 0 {
+		q = q.Where("folder_id IN ?", req.FolderIDs)
+	}
+	if req.Unfiled {
+		q = q.Where("folder_id IS NULL")
+	}
+	if len(req.CustomMetadata) > 0 {
+		if criteria, err := json.Marshal(req.CustomMetadata); err == nil {
+			q = q.Where("custom_metadata @> CAST(? AS jsonb)", string(criteria))
 		}
 	}
+	// Every requested tag must be attached, matched exactly rather than as a substring
+	for _, tag := range models.NormalizeTags(req.Tags) {
+		q = q.Where(`EXISTS (
+			SELECT 1 FROM document_tags dt JOIN tags t ON t.id = dt.tag_id
+			WHERE dt.document_id = documents.id AND t.name = ?
+		)`, tag)
+	}
 	return q
 }
 
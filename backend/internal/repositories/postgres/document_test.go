@@ -0,0 +1,85 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/repositories/postgres"
+	"github.com/eyuppastirmaci/noesis-forge/internal/testutil"
+	"github.com/google/uuid"
+)
+
+func TestPurgeRemovesRevokedShares(t *testing.T) {
+	db := testutil.NewPostgresDB(t,
+		&models.Permission{}, &models.Role{}, &models.User{}, &models.Folder{}, &models.Document{},
+		&models.DocumentRevision{}, &models.SharedLink{}, &models.ShareAuditLog{}, &models.UserShare{},
+		&models.ShareNotification{}, &models.UserShareAuditLog{}, &models.ShareInvitation{},
+		&models.Group{}, &models.GroupMember{}, &models.GroupShare{},
+	)
+	owner := testutil.CreateUser(t, db, "owner")
+	recipient := testutil.CreateUser(t, db, "recipient")
+
+	document := &models.Document{
+		Title:            "Report",
+		FileName:         "report.txt",
+		OriginalFileName: "report.txt",
+		FileSize:         5,
+		FileType:         models.DocumentTypeTXT,
+		MimeType:         "text/plain",
+		StoragePath:      "users/" + owner.ID.String() + "/documents/report.txt",
+		StorageBucket:    "documents",
+		UserID:           owner.ID,
+	}
+	if err := db.Create(document).Error; err != nil {
+		t.Fatalf("failed to create document: %v", err)
+	}
+
+	userShare := &models.UserShare{DocumentID: document.ID, OwnerID: owner.ID, SharedWithEmail: recipient.Email, SharedWithUserID: &recipient.ID, AccessLevel: models.AccessLevelView}
+	sharedLink := &models.SharedLink{DocumentID: document.ID, OwnerID: owner.ID, Token: uuid.NewString()}
+	invitation := &models.ShareInvitation{DocumentID: document.ID, OwnerID: owner.ID, Email: "guest@example.com", AccessLevel: models.AccessLevelView, Token: uuid.NewString()}
+	group := &models.Group{OwnerID: owner.ID, Name: "Team"}
+	for _, record := range []interface{}{userShare, sharedLink, invitation, group} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", record, err)
+		}
+	}
+	for _, record := range []interface{}{
+		&models.GroupShare{DocumentID: document.ID, OwnerID: owner.ID, GroupID: group.ID, AccessLevel: models.AccessLevelView},
+		&models.ShareNotification{Type: "document_shared", Title: "Shared", Message: "Report", DocumentID: document.ID, FromUserID: owner.ID, ToUserID: recipient.ID},
+		&models.UserShareAuditLog{UserShareID: userShare.ID, UserID: owner.ID, Action: "revoked"},
+		&models.ShareAuditLog{SharedLinkID: sharedLink.ID, Action: "created"},
+	} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", record, err)
+		}
+	}
+
+	// Revoked shares are soft deleted and keep referencing the document
+	for _, record := range []interface{}{userShare, sharedLink, invitation} {
+		if err := db.Delete(record).Error; err != nil {
+			t.Fatalf("failed to revoke %T: %v", record, err)
+		}
+	}
+
+	repo := postgres.NewDocumentRepository(db)
+	if err := repo.Purge(context.Background(), document.ID); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+
+	var remaining int64
+	if err := db.Unscoped().Model(&models.Document{}).Where("id = ?", document.ID).Count(&remaining).Error; err != nil {
+		t.Fatalf("failed to count documents: %v", err)
+	}
+	if remaining != 0 {
+		t.Fatal("document is still stored")
+	}
+	for _, model := range []interface{}{&models.UserShare{}, &models.SharedLink{}, &models.ShareInvitation{}, &models.GroupShare{}, &models.ShareNotification{}} {
+		if err := db.Unscoped().Model(model).Where("document_id = ?", document.ID).Count(&remaining).Error; err != nil {
+			t.Fatalf("failed to count %T: %v", model, err)
+		}
+		if remaining != 0 {
+			t.Fatalf("%d %T rows of the purged document are left", remaining, model)
+		}
+	}
+}
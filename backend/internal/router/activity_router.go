@@ -18,11 +18,18 @@ func RegisterActivityRoutes(router *gin.RouterGroup, db *gorm.DB, authService *s
 		documents.GET("", activityHandler.GetDocumentActivities)
 	}
 
+	analytics := router.Group("/documents/:id/analytics")
+	analytics.Use(middleware.AuthMiddleware(authService))
+	{
+		analytics.GET("", activityHandler.GetDocumentAnalytics)
+	}
+
 	// User activity routes
 	activities := router.Group("/activities")
 	activities.Use(middleware.AuthMiddleware(authService))
 	{
 		activities.GET("", activityHandler.GetUserActivities)
 		activities.GET("/stats", activityHandler.GetActivityStats)
+		activities.GET("/export", activityHandler.ExportActivities)
 	}
 }
@@ -0,0 +1,37 @@
+package router
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAdminRoutes(r *gin.RouterGroup, adminService *services.AdminService, authService *services.AuthService) {
+	adminHandler := handlers.NewAdminHandler(adminService)
+
+	admin := r.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(authService))
+	admin.Use(middleware.RequirePermission(authService, models.PermissionAdminAccess))
+	{
+		admin.GET("/stats", adminHandler.GetStats)
+
+		// Moderation
+		admin.GET("/documents", adminHandler.ListDocuments)
+		admin.GET("/users", middleware.RequirePermission(authService, models.PermissionUserManage), adminHandler.ListUsers)
+		admin.PATCH("/users/:id/status", middleware.RequirePermission(authService, models.PermissionUserManage), adminHandler.UpdateUserStatus)
+
+		// Search diagnostics
+		admin.POST("/documents/:id/reindex", adminHandler.ReindexDocument)
+		admin.POST("/search/reindex", adminHandler.StartSearchReindex)
+		admin.GET("/search/reindex/:id", adminHandler.GetSearchReindex)
+
+		// Finds users by email or phone without decrypting profile fields
+		admin.GET("/users/lookup", middleware.RequirePermission(authService, models.PermissionUserManage), adminHandler.LookupUsers)
+
+		// Offboarding
+		admin.POST("/users/:id/transfer-documents", middleware.RequirePermission(authService, models.PermissionUserManage), adminHandler.TransferDocuments)
+		admin.GET("/transfers/:id", middleware.RequirePermission(authService, models.PermissionUserManage), adminHandler.GetTransfer)
+	}
+}
@@ -46,6 +46,28 @@ func RegisterAuthRoutes(r *gin.RouterGroup, authService *services.AuthService, r
 			middleware.RateLimitRedis(redisClient, 10, time.Minute),
 			authHandler.Logout)
 
+		// Email verification, resending is strictly limited since it sends mail
+		auth.POST("/verify/send",
+			middleware.RateLimitRedis(redisClient, 3, time.Minute),
+			authHandler.SendVerificationEmail)
+
+		auth.GET("/verify/:token",
+			middleware.RateLimitRedis(redisClient, 10, time.Minute),
+			authHandler.VerifyEmail)
+
+		// Password reset, limited per client here and per address in the service
+		auth.POST("/forgot-password",
+			middleware.RateLimitRedis(redisClient, 3, time.Minute),
+			authHandler.ForgotPassword)
+
+		auth.POST("/reset-password",
+			middleware.RateLimitRedis(redisClient, 5, time.Minute),
+			validations.ValidateResetPassword(),
+			authHandler.ResetPassword)
+
+		// Generated avatars are loaded by <img> tags, which cannot send the bearer token
+		auth.GET("/users/:id/avatar", authHandler.GetUserAvatar)
+
 		// Protected routes
 		protected := auth.Group("")
 		protected.Use(middleware.AuthMiddleware(authService))
@@ -59,6 +81,11 @@ func RegisterAuthRoutes(r *gin.RouterGroup, authService *services.AuthService, r
 				middleware.RateLimitRedis(redisClient, 30, time.Minute),
 				validations.ValidateUpdateProfile(),
 				authHandler.UpdateProfile)
+			protected.GET("/sessions", authHandler.GetSessions)
+			protected.DELETE("/sessions/:id", authHandler.RevokeSession)
+			protected.POST("/logout-all",
+				middleware.RateLimitRedis(redisClient, 10, time.Minute),
+				authHandler.LogoutAll)
 			protected.PUT("/change-password",
 				middleware.RateLimitRedis(redisClient, 3, time.Minute),
 				validations.ValidateChangePassword(),
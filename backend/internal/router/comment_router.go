@@ -1,6 +1,7 @@
 package router
 
 import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
 	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
 	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
 	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
@@ -10,8 +11,8 @@ import (
 	"gorm.io/gorm"
 )
 
-func RegisterCommentRoutes(router *gin.RouterGroup, db *gorm.DB, authService *services.AuthService, redisClient *redis.Client) {
-	commentHandler := handlers.NewCommentHandler(db, authService)
+func RegisterCommentRoutes(router *gin.RouterGroup, db *gorm.DB, authService *services.AuthService, redisClient *redis.Client, moderator validations.ContentModerator, limits config.CommentLimitConfig) {
+	commentHandler := handlers.NewCommentHandler(db, authService, limits)
 
 	// Middleware to inject Redis client into context
 	redisMiddleware := func(c *gin.Context) {
@@ -27,7 +28,15 @@ func RegisterCommentRoutes(router *gin.RouterGroup, db *gorm.DB, authService *se
 	documents.Use(redisMiddleware)
 	{
 		documents.GET("", validations.ValidateCommentList(), commentHandler.GetDocumentComments)
-		documents.POST("", validations.ValidateCommentCreate(), commentHandler.CreateComment)
+		documents.POST("", validations.ValidateCommentCreate(moderator), commentHandler.CreateComment)
+		documents.GET("/:commentId/replies", validations.ValidateCommentList(), commentHandler.GetCommentReplies)
+	}
+
+	// Annotation export
+	annotations := router.Group("/documents/:id/annotations")
+	annotations.Use(middleware.AuthMiddleware(authService))
+	{
+		annotations.GET("/export", commentHandler.ExportAnnotations)
 	}
 
 	// Comment management routes
@@ -35,9 +44,13 @@ func RegisterCommentRoutes(router *gin.RouterGroup, db *gorm.DB, authService *se
 	comments.Use(middleware.AuthMiddleware(authService))
 	comments.Use(redisMiddleware)
 	{
-		comments.PUT("/:id", validations.ValidateCommentID(), validations.ValidateCommentUpdate(), commentHandler.UpdateComment)
+		comments.POST("/bulk/resolve", validations.ValidateBulkCommentRequest(), commentHandler.BulkResolveComments)
+		comments.POST("/bulk/unresolve", validations.ValidateBulkCommentRequest(), commentHandler.BulkUnresolveComments)
+		comments.PUT("/:id", validations.ValidateCommentID(), validations.ValidateCommentUpdate(moderator), commentHandler.UpdateComment)
 		comments.DELETE("/:id", validations.ValidateCommentID(), commentHandler.DeleteComment)
 		comments.POST("/:id/resolve", validations.ValidateCommentID(), commentHandler.ResolveComment)
 		comments.POST("/:id/unresolve", validations.ValidateCommentID(), commentHandler.UnresolveComment)
+		comments.POST("/:id/reactions", validations.ValidateCommentID(), validations.ValidateCommentReaction(), commentHandler.AddReaction)
+		comments.DELETE("/:id/reactions/:emoji", validations.ValidateCommentID(), validations.ValidateCommentReaction(), commentHandler.RemoveReaction)
 	}
 }
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCustomFieldRoutes(r *gin.RouterGroup, customFieldService *services.CustomFieldService, authService *services.AuthService) {
+	customFieldHandler := handlers.NewCustomFieldHandler(customFieldService)
+
+	fields := r.Group("/custom-fields")
+	fields.Use(middleware.AuthMiddleware(authService))
+	{
+		// Schema for document forms
+		fields.GET("", customFieldHandler.GetCustomFields)
+
+		// Schema management (admin only)
+		fields.POST("", middleware.RequirePermission(authService, models.PermissionAdminAccess), customFieldHandler.CreateCustomField)
+		fields.PUT("/:id", middleware.RequirePermission(authService, models.PermissionAdminAccess), customFieldHandler.UpdateCustomField)
+		fields.DELETE("/:id", middleware.RequirePermission(authService, models.PermissionAdminAccess), customFieldHandler.DeleteCustomField)
+	}
+}
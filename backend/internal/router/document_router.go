@@ -1,9 +1,12 @@
 package router
 
 import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
 	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
 	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/queue"
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
 	"github.com/eyuppastirmaci/noesis-forge/internal/services"
 	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
 	"github.com/gin-gonic/gin"
@@ -15,36 +18,89 @@ func RegisterDocumentRoutes(
 	minioService *services.MinIOService,
 	authService *services.AuthService,
 	userShareService *services.UserShareService,
+	embedTokenService *services.EmbedTokenService,
 	processingTaskService *services.ProcessingTaskService,
 	queuePublisher *queue.Publisher,
+	redisClient *redis.Client,
+	rateLimits config.RateLimitConfig,
+	uploads config.UploadConfig,
+	bulk config.BulkConfig,
 ) {
-	documentHandler := handlers.NewDocumentHandler(documentService, minioService, userShareService, processingTaskService, queuePublisher)
+	documentHandler := handlers.NewDocumentHandler(documentService, minioService, userShareService, processingTaskService, queuePublisher, bulk)
+
+	idempotent := middleware.Idempotency(uploads.IdempotencyTTL)
+	uploadLimit := middleware.RateLimitMiddleware("upload", rateLimits.UploadLimit, rateLimits.UploadWindow)
+	downloadLimit := middleware.RateLimitMiddleware("download", rateLimits.DownloadLimit, rateLimits.DownloadWindow)
+	canDelete := middleware.RequirePermission(authService, models.PermissionDocumentDelete)
 
 	documents := r.Group("/documents")
 	documents.Use(middleware.AuthMiddleware(authService))
+	documents.Use(func(c *gin.Context) {
+		if redisClient != nil {
+			c.Set("redisClient", redisClient)
+		}
+		c.Next()
+	})
 	{
 		// Document CRUD operations with validation middleware
-		documents.POST("/upload", validations.ValidateDocumentUpload(), documentHandler.UploadDocument)
-		documents.POST("/bulk-upload", validations.ValidateBulkDocumentUpload(), documentHandler.BulkUploadDocuments)
+		documents.POST("/upload", idempotent, uploadLimit, validations.ValidateDocumentUpload(documentService.FileTypes(), documentService.Tags()), documentHandler.UploadDocument)
+		documents.POST("/bulk-upload", idempotent, uploadLimit, validations.ValidateBulkDocumentUpload(documentService.FileTypes(), documentService.Tags(), bulk.UploadMaxFiles, bulk.UploadMaxBytes), documentHandler.BulkUploadDocuments)
 		documents.GET("", validations.ValidateDocumentList(), documentHandler.GetDocuments)
 		documents.GET("/stats", documentHandler.GetUserStats)
+		documents.GET("/stats/breakdown", documentHandler.GetStorageBreakdown)
+		documents.GET("/review-queue", documentHandler.GetReviewQueue)
+		documents.GET("/recent", documentHandler.GetRecentDocuments)
+		documents.POST("/presigned-urls", validations.ValidatePresignedURLs(bulk.PresignMax), documentHandler.GetPresignedURLs)
 		documents.GET("/:id", validations.ValidateDocumentID(), documentHandler.GetDocument)
 		documents.GET("/:id/title", validations.ValidateDocumentID(), documentHandler.GetDocumentTitle)
-		documents.PUT("/:id", validations.ValidateDocumentID(), validations.ValidateDocumentUpdate(), documentHandler.UpdateDocument)
-		documents.DELETE("/:id", validations.ValidateDocumentID(), documentHandler.DeleteDocument)
+		documents.PUT("/:id", validations.ValidateDocumentID(), validations.ValidateDocumentUpdate(documentService.FileTypes(), documentService.Tags()), documentHandler.UpdateDocument)
+		documents.DELETE("/:id", canDelete, validations.ValidateDocumentID(), documentHandler.DeleteDocument)
+		documents.POST("/:id/move", validations.ValidateDocumentID(), documentHandler.MoveDocument)
+		documents.POST("/:id/copy", validations.ValidateDocumentID(), documentHandler.CopyDocument)
+		documents.POST("/:id/pin", validations.ValidateDocumentID(), documentHandler.PinDocument)
+		documents.DELETE("/:id/pin", validations.ValidateDocumentID(), documentHandler.UnpinDocument)
+
+		// Trash operations
+		documents.GET("/trash", documentHandler.GetTrash)
+		documents.POST("/:id/restore", validations.ValidateDocumentID(), documentHandler.RestoreDocument)
+		documents.DELETE("/:id/permanent", canDelete, validations.ValidateDocumentID(), documentHandler.PermanentlyDeleteDocument)
 
 		// Bulk operations
-		documents.POST("/bulk-delete", validations.ValidateBulkDelete(), documentHandler.BulkDeleteDocuments)
-		documents.POST("/bulk-download", validations.ValidateBulkDownload(), documentHandler.BulkDownloadDocuments)
+		documents.POST("/bulk-delete", canDelete, validations.ValidateBulkDelete(bulk.DeleteMax), documentHandler.BulkDeleteDocuments)
+		documents.PATCH("/bulk", validations.ValidateBulkUpdate(documentService.Tags(), bulk.UpdateMax), documentHandler.BulkUpdateDocuments)
+		documents.POST("/bulk-download", downloadLimit, validations.ValidateBulkDownload(bulk.DownloadMax), documentHandler.BulkDownloadDocuments)
 
 		// File operations with validation middleware
-		documents.GET("/:id/download", validations.ValidateDocumentID(), documentHandler.DownloadDocument)
+		documents.GET("/:id/download", validations.ValidateDocumentID(), downloadLimit, documentHandler.DownloadDocument)
+		documents.GET("/:id/verify", validations.ValidateDocumentID(), downloadLimit, documentHandler.VerifyDocument)
 		documents.GET("/:id/preview", validations.ValidateDocumentID(), documentHandler.GetDocumentPreview)
+		documents.GET("/:id/preview/rendered", validations.ValidateDocumentID(), documentHandler.GetRenderedPreview)
+		documents.GET("/:id/text", validations.ValidateDocumentID(), documentHandler.GetDocumentText)
+		documents.GET("/:id/content", validations.ValidateDocumentID(), documentHandler.GetDocumentContent)
 		documents.GET("/:id/thumbnail", validations.ValidateDocumentID(), documentHandler.GetDocumentThumbnail)
+		documents.GET("/:id/thumbnail/url", validations.ValidateDocumentID(), documentHandler.GetDocumentThumbnailURL)
 		documents.GET("/:id/revisions", validations.ValidateDocumentID(), documentHandler.GetDocumentRevisions)
+		documents.GET("/:id/revisions/diff", validations.ValidateDocumentID(), documentHandler.GetRevisionDiff)
+		documents.POST("/:id/revisions/:revisionId/restore", validations.ValidateDocumentID(), documentHandler.RestoreRevision)
 
 		// Processing queue and status operations
 		documents.GET("/processing-queue", documentHandler.GetUserProcessingQueue)
 		documents.GET("/:id/processing-status", validations.ValidateDocumentID(), documentHandler.GetDocumentProcessingStatus)
+		documents.POST("/:id/reprocess", validations.ValidateDocumentID(), documentHandler.ReprocessDocument)
+	}
+
+	// Previews embedded in other apps, authenticated with a document embed token instead of a session
+	embed := r.Group("/embed/documents")
+	embed.Use(middleware.EmbedTokenMiddleware(embedTokenService))
+	{
+		embed.GET("/:id/preview", validations.ValidateDocumentID(), documentHandler.GetDocumentPreview)
+		embed.GET("/:id/thumbnail", validations.ValidateDocumentID(), documentHandler.GetDocumentThumbnail)
+		embed.GET("/:id/thumbnail/url", validations.ValidateDocumentID(), documentHandler.GetDocumentThumbnailURL)
+	}
+
+	tags := r.Group("/tags")
+	tags.Use(middleware.AuthMiddleware(authService))
+	{
+		tags.GET("", documentHandler.GetTags)
 	}
 }
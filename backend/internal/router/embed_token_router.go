@@ -0,0 +1,20 @@
+package router
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterEmbedTokenRoutes(api *gin.RouterGroup, embedTokenService *services.EmbedTokenService, authService *services.AuthService) {
+	h := handlers.NewEmbedTokenHandler(embedTokenService)
+
+	// Owners manage the embed tokens of their documents
+	docs := api.Group("/documents")
+	docs.Use(middleware.AuthMiddleware(authService))
+	docs.POST("/:id/embed-token", validations.ValidateDocumentID(), h.CreateEmbedToken)
+	docs.GET("/:id/embed-tokens", validations.ValidateDocumentID(), h.GetEmbedTokens)
+	docs.DELETE("/:id/embed-tokens/:tokenId", validations.ValidateDocumentID(), h.RevokeEmbedToken)
+}
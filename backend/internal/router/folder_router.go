@@ -0,0 +1,23 @@
+package router
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterFolderRoutes(r *gin.RouterGroup, folderService *services.FolderService, authService *services.AuthService) {
+	folderHandler := handlers.NewFolderHandler(folderService)
+
+	folders := r.Group("/folders")
+	folders.Use(middleware.AuthMiddleware(authService))
+	{
+		folders.GET("", validations.ValidateFolderParent(), folderHandler.GetFolders)
+		folders.POST("", validations.ValidateFolderCreate(), folderHandler.CreateFolder)
+		folders.GET("/:id", validations.ValidateFolderID(), folderHandler.GetFolder)
+		folders.PATCH("/:id", validations.ValidateFolderID(), validations.ValidateFolderUpdate(), folderHandler.UpdateFolder)
+		folders.DELETE("/:id", validations.ValidateFolderID(), folderHandler.DeleteFolder)
+	}
+}
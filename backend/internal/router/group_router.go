@@ -0,0 +1,35 @@
+package router
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterGroupRoutes(r *gin.RouterGroup, groupService *services.GroupService, authService *services.AuthService) {
+	groupHandler := handlers.NewGroupHandler(groupService)
+
+	groups := r.Group("/groups")
+	groups.Use(middleware.AuthMiddleware(authService))
+	{
+		groups.GET("", groupHandler.GetGroups)
+		groups.POST("", validations.ValidateGroupCreate(), groupHandler.CreateGroup)
+		groups.GET("/:id", validations.ValidateGroupID(), groupHandler.GetGroup)
+		groups.PATCH("/:id", validations.ValidateGroupID(), validations.ValidateGroupUpdate(), groupHandler.UpdateGroup)
+		groups.DELETE("/:id", validations.ValidateGroupID(), groupHandler.DeleteGroup)
+		groups.POST("/:id/members", validations.ValidateGroupID(), validations.ValidateGroupMemberAdd(), groupHandler.AddMember)
+		groups.DELETE("/:id/members/:userId", validations.ValidateGroupID(), validations.ValidateGroupMemberID(), groupHandler.RemoveMember)
+		groups.GET("/:id/documents", validations.ValidateGroupID(), groupHandler.GetGroupDocuments)
+	}
+
+	// Sharing a document with groups, next to the per-user shares under /documents/:id/share/users
+	documents := r.Group("/documents")
+	documents.Use(middleware.AuthMiddleware(authService))
+	{
+		documents.GET("/:id/share/groups", validations.ValidateDocumentID(), groupHandler.GetDocumentGroupShares)
+		documents.POST("/:id/share/groups", validations.ValidateDocumentID(), validations.ValidateGroupShare(), groupHandler.ShareDocument)
+		documents.DELETE("/:id/share/groups/:groupId", validations.ValidateDocumentID(), validations.ValidateSharedGroupID(), groupHandler.RevokeGroupShare)
+	}
+}
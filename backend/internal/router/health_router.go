@@ -3,17 +3,21 @@ package router
 import (
 	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-func RegisterHealthRoutes(r *gin.RouterGroup, db *gorm.DB) {
-	// Initialize handler
-	healthHandler := handlers.NewHealthHandler(db)
-
+func RegisterHealthRoutes(r *gin.RouterGroup, healthHandler *handlers.HealthHandler) {
 	health := r.Group("/health")
 	{
 		health.GET("", healthHandler.HealthCheck)
 		health.GET("/ready", healthHandler.ReadinessCheck)
 		health.GET("/live", healthHandler.LivenessCheck)
+		health.GET("/dependencies", healthHandler.DependencyCheck)
 	}
 }
+
+// Registers the orchestrator probes at the root. They are added before the global
+// middleware so polling is neither logged nor rate limited.
+func RegisterProbeRoutes(r *gin.Engine, healthHandler *handlers.HealthHandler) {
+	r.GET("/healthz", healthHandler.LivenessCheck)
+	r.GET("/readyz", healthHandler.ReadinessCheck)
+}
@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterQuotaRoutes(r *gin.RouterGroup, quotaService *services.QuotaService, authService *services.AuthService) {
+	quotaHandler := handlers.NewQuotaHandler(quotaService)
+
+	users := r.Group("/users/me")
+	users.Use(middleware.AuthMiddleware(authService))
+	{
+		users.GET("/quota", quotaHandler.GetMyQuota)
+	}
+}
@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
 	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -13,19 +14,20 @@ func RegisterRoleRoutes(r *gin.RouterGroup, roleService *services.RoleService, a
 
 	roles := r.Group("/roles")
 	roles.Use(middleware.AuthMiddleware(authService))
+	roles.Use(middleware.RequirePermission(authService, models.PermissionRoleManage))
 	{
-		// Role management (admin only)
-		roles.GET("", middleware.RequireAdmin(), roleHandler.GetRoles)
-		roles.GET("/:id", middleware.RequireAdmin(), roleHandler.GetRoleByID)
-		roles.POST("", middleware.RequireAdmin(), roleHandler.CreateRole)
-		roles.PUT("/:id", middleware.RequireAdmin(), roleHandler.UpdateRole)
-		roles.DELETE("/:id", middleware.RequireAdmin(), roleHandler.DeleteRole)
+		// Role management
+		roles.GET("", roleHandler.GetRoles)
+		roles.GET("/:id", roleHandler.GetRoleByID)
+		roles.POST("", roleHandler.CreateRole)
+		roles.PUT("/:id", roleHandler.UpdateRole)
+		roles.DELETE("/:id", roleHandler.DeleteRole)
 
 		// Permission management
-		roles.GET("/permissions", middleware.RequireAdmin(), roleHandler.GetPermissions)
-		roles.GET("/permissions/categories/:category", middleware.RequireAdmin(), roleHandler.GetPermissionsByCategory)
+		roles.GET("/permissions", roleHandler.GetPermissions)
+		roles.GET("/permissions/categories/:category", roleHandler.GetPermissionsByCategory)
 
 		// User role assignment
-		roles.POST("/assign", middleware.RequireAdmin(), roleHandler.AssignRole)
+		roles.POST("/assign", roleHandler.AssignRole)
 	}
 }
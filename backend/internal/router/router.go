@@ -9,6 +9,7 @@ import (
 	"github.com/eyuppastirmaci/noesis-forge/internal/queue"
 	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
 	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
 	"github.com/eyuppastirmaci/noesis-forge/internal/websocket"
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -22,13 +23,24 @@ type Router struct {
 	roleService           *services.RoleService
 	documentService       *services.DocumentService
 	favoriteService       *services.FavoriteService
+	savedSearchService    *services.SavedSearchService
+	folderService         *services.FolderService
+	groupService          *services.GroupService
+	quotaService          *services.QuotaService
+	customFieldService    *services.CustomFieldService
+	adminService          *services.AdminService
 	minioService          *services.MinIOService
+	imageMagick           *services.ImageMagick // nil when ImageMagick is not installed
+	libreOffice           *services.LibreOffice // nil when LibreOffice is not installed
+	clamAV                *services.ClamAV      // nil when virus scanning is off
 	redisClient           *redis.Client
 	shareService          *services.ShareService
+	embedTokenService     *services.EmbedTokenService
 	userShareService      *services.UserShareService
 	processingTaskService *services.ProcessingTaskService
 	queuePublisher        *queue.Publisher
 	searchService         *services.SearchService
+	webhookService        *services.WebhookService
 }
 
 func New(
@@ -41,6 +53,11 @@ func New(
 	queuePublisher *queue.Publisher,
 	processingTaskService *services.ProcessingTaskService,
 	searchService *services.SearchService,
+	adminService *services.AdminService,
+	webhookService *services.WebhookService,
+	imageMagick *services.ImageMagick,
+	libreOffice *services.LibreOffice,
+	clamAV *services.ClamAV,
 ) *Router {
 	// Setup Gin mode
 	if cfg.Environment == "production" {
@@ -60,9 +77,15 @@ func New(
 	}
 
 	// Initialize other services
-	roleService := services.NewRoleService(db)
-	shareService := services.NewShareService(db, redisClient)
+	roleService := services.NewRoleService(db, authService)
+	shareService := services.NewShareService(db, redisClient, cfg.ShareLinks.Secret, documentService.HoldsUnscanned())
+	embedTokenService := services.NewEmbedTokenService(db, cfg.Embed)
 	favoriteService := services.NewFavoriteService(db)
+	savedSearchService := services.NewSavedSearchService(db, documentService)
+	folderService := services.NewFolderService(db)
+	groupService := services.NewGroupService(db)
+	quotaService := services.NewQuotaService(db, redisClient, &cfg.Quota)
+	customFieldService := services.NewCustomFieldService(db)
 
 	return &Router{
 		engine:                engine,
@@ -71,30 +94,45 @@ func New(
 		roleService:           roleService,
 		documentService:       documentService,
 		favoriteService:       favoriteService,
+		savedSearchService:    savedSearchService,
+		folderService:         folderService,
+		groupService:          groupService,
+		quotaService:          quotaService,
+		customFieldService:    customFieldService,
+		adminService:          adminService,
 		minioService:          minioService,
+		imageMagick:           imageMagick,
+		libreOffice:           libreOffice,
+		clamAV:                clamAV,
 		redisClient:           redisClient,
 		shareService:          shareService,
+		embedTokenService:     embedTokenService,
 		userShareService:      userShareService,
 		processingTaskService: processingTaskService,
 		queuePublisher:        queuePublisher,
 		searchService:         searchService,
+		webhookService:        webhookService,
 	}
 }
 
 func (r *Router) SetupRoutes(db *gorm.DB) {
+	healthHandler := handlers.NewHealthHandler(db, r.redisClient, r.minioService, r.imageMagick, r.libreOffice, r.clamAV)
+
+	// Liveness and readiness probes
+	RegisterProbeRoutes(r.engine, healthHandler)
+
 	// Global middleware
-	r.engine.Use(gin.Logger())
+	r.engine.Use(middleware.RequestLogger())
+	if r.config.Metrics.Enabled {
+		r.engine.Use(middleware.Metrics())
+	}
 	r.engine.Use(gin.Recovery())
 
 	// Security headers
 	r.engine.Use(middleware.SecurityHeaders(r.config.Environment))
 
-	// CORS with environment-specific settings
-	var allowedOrigins []string
-	if r.config.Environment == "production" {
-		allowedOrigins = []string{"https://yourdomain.com"}
-	}
-	r.engine.Use(middleware.CORS(r.config.Environment, allowedOrigins))
+	// CORS for the configured frontend origins
+	r.engine.Use(middleware.CORS(r.config.CORS))
 
 	// Rate limiter using our Redis client
 	r.engine.Use(middleware.RateLimitRedis(r.redisClient, 100, time.Minute))
@@ -109,23 +147,40 @@ func (r *Router) SetupRoutes(db *gorm.DB) {
 		})
 	})
 
+	// OpenAPI spec and Swagger UI
+	RegisterSwaggerRoutes(r.engine)
+
 	// API routes with security headers
 	api := r.engine.Group("/api/v1")
 	api.Use(middleware.APISecurityHeaders())
 
 	// Register routes
-	RegisterHealthRoutes(api, db)
+	RegisterHealthRoutes(api, healthHandler)
 	RegisterAuthRoutes(api, r.authService, r.redisClient)
 	RegisterRoleRoutes(api, r.roleService, r.authService)
-	RegisterDocumentRoutes(api, r.documentService, r.minioService, r.authService, r.userShareService, r.processingTaskService, r.queuePublisher)
+	RegisterDocumentRoutes(api, r.documentService, r.minioService, r.authService, r.userShareService, r.embedTokenService, r.processingTaskService, r.queuePublisher, r.redisClient, r.config.RateLimit, r.config.Uploads, r.config.Bulk)
 	RegisterFavoriteRoutes(api, r.favoriteService, r.authService)
-	RegisterCommentRoutes(api, db, r.authService, r.redisClient)
+	RegisterSavedSearchRoutes(api, r.savedSearchService, r.authService)
+	RegisterFolderRoutes(api, r.folderService, r.authService)
+	RegisterGroupRoutes(api, r.groupService, r.authService)
+	RegisterQuotaRoutes(api, r.quotaService, r.authService)
+	RegisterCustomFieldRoutes(api, r.customFieldService, r.authService)
+	RegisterAdminRoutes(api, r.adminService, r.authService)
+	RegisterCommentRoutes(api, db, r.authService, r.redisClient, validations.NewContentModerator(r.config.Moderation), r.config.Comments)
 	RegisterActivityRoutes(api, db, r.authService)
+	RegisterWebhookRoutes(api, r.webhookService, r.authService)
+
+	// Public keys of RS256 access tokens, at the well-known path verifiers look for
+	if _, ok := r.authService.JWKS(); ok {
+		r.engine.GET("/.well-known/jwks.json", handlers.NewAuthHandler(r.authService).GetJWKS)
+	}
 
 	// Share routes
 	shareHandler := handlers.NewShareHandler(r.shareService, r.minioService, r.config)
 	r.engine.GET("/share/:token", shareHandler.DownloadShared)
+	r.engine.POST("/share/:token/unlock", shareHandler.UnlockShared)
 	RegisterShareRoutes(api, r.shareService, r.minioService, r.authService, r.config)
+	RegisterEmbedTokenRoutes(api, r.embedTokenService, r.authService)
 
 	// User Share routes
 	userShareHandler := handlers.NewUserShareHandler(r.userShareService, r.config)
@@ -146,6 +201,14 @@ func (r *Router) GetEngine() *gin.Engine {
 	return r.engine
 }
 
+// Close releases the connections the router opened itself
+func (r *Router) Close() error {
+	if r.redisClient != nil {
+		return r.redisClient.Close()
+	}
+	return nil
+}
+
 func (r *Router) SetupWebSocket(wsServer *websocket.Server) {
 	// Add WebSocket endpoint
 	r.engine.GET("/socket.io/*any", gin.WrapH(wsServer.GetServer()))
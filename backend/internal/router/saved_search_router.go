@@ -0,0 +1,22 @@
+package router
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSavedSearchRoutes(r *gin.RouterGroup, savedSearchService *services.SavedSearchService, authService *services.AuthService) {
+	savedSearchHandler := handlers.NewSavedSearchHandler(savedSearchService)
+
+	searches := r.Group("/searches")
+	searches.Use(middleware.AuthMiddleware(authService))
+	{
+		searches.GET("", savedSearchHandler.GetSavedSearches)
+		searches.POST("", validations.ValidateSavedSearchCreate(), savedSearchHandler.CreateSavedSearch)
+		searches.DELETE("/:id", validations.ValidateSavedSearchID(), savedSearchHandler.DeleteSavedSearch)
+		searches.GET("/:id/run", validations.ValidateSavedSearchID(), validations.ValidateSavedSearchRun(), savedSearchHandler.RunSavedSearch)
+	}
+}
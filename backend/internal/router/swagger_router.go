@@ -0,0 +1,22 @@
+package router
+
+import (
+	"net/http"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSwaggerRoutes(r *gin.Engine) {
+	swaggerHandler := handlers.NewSwaggerHandler()
+
+	swagger := r.Group("/swagger")
+	{
+		swagger.GET("", func(c *gin.Context) {
+			c.Redirect(http.StatusMovedPermanently, "/swagger/index.html")
+		})
+		swagger.GET("/doc.json", swaggerHandler.GetSpec)
+		swagger.GET("/index.html", swaggerHandler.GetUI)
+		swagger.GET("/init.js", swaggerHandler.GetUIInit)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
 	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
 	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,15 +13,24 @@ func RegisterUserShareRoutes(api *gin.RouterGroup, userShareHandler *handlers.Us
 	shareRoutes := api.Group("/shares")
 	shareRoutes.Use(middleware.AuthMiddleware(authService))
 	{
-		shareRoutes.GET("/with-me", userShareHandler.GetSharedWithMe)
+		shareRoutes.GET("/with-me", validations.ValidateSharedWithMeList(), userShareHandler.GetSharedWithMe)
 		shareRoutes.GET("/by-me", userShareHandler.GetSharedByMe)
 		shareRoutes.GET("/public-links", userShareHandler.GetPublicLinks)
+		shareRoutes.POST("/bulk", validations.ValidateBulkShare(), userShareHandler.BulkCreateUserShares)
+		shareRoutes.GET("/:shareId", userShareHandler.GetUserShare)
 		shareRoutes.DELETE("/:shareId", userShareHandler.RevokeUserShare)
 		shareRoutes.PUT("/:shareId/access", userShareHandler.UpdateUserShareAccess)
 		shareRoutes.GET("/notifications", userShareHandler.GetShareNotifications)
 		shareRoutes.PUT("/notifications/:id/read", userShareHandler.MarkNotificationAsRead)
 	}
 
+	// Invitation routes, details are public so invitees can see them before signing in
+	invitationRoutes := api.Group("/invitations")
+	{
+		invitationRoutes.GET("/:token", userShareHandler.GetInvitation)
+		invitationRoutes.POST("/:token/accept", middleware.AuthMiddleware(authService), userShareHandler.AcceptInvitation)
+	}
+
 	// Document-specific user share routes
 	documentRoutes := api.Group("/documents")
 	documentRoutes.Use(middleware.AuthMiddleware(authService))
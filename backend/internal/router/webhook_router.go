@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/handlers"
+	"github.com/eyuppastirmaci/noesis-forge/internal/middleware"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterWebhookRoutes(r *gin.RouterGroup, webhookService *services.WebhookService, authService *services.AuthService) {
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+
+	webhooks := r.Group("/webhooks")
+	webhooks.Use(middleware.AuthMiddleware(authService))
+	{
+		webhooks.GET("", webhookHandler.GetWebhooks)
+		webhooks.POST("", webhookHandler.CreateWebhook)
+		webhooks.PUT("/:id", webhookHandler.UpdateWebhook)
+		webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+
+		// Dead letter log
+		webhooks.GET("/:id/failures", webhookHandler.GetDeadLetters)
+	}
+}
@@ -2,7 +2,9 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,12 +12,14 @@ import (
 	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/app"
+	"github.com/eyuppastirmaci/noesis-forge/internal/metrics"
 	"github.com/sirupsen/logrus"
 )
 
 type Server struct {
-	app        *app.App
-	httpServer *http.Server
+	app           *app.App
+	httpServer    *http.Server
+	metricsServer *http.Server // nil when metrics are disabled
 }
 
 func New(app *app.App) *Server {
@@ -24,13 +28,24 @@ func New(app *app.App) *Server {
 	}
 }
 
+// Run serves until SIGINT or SIGTERM, then stops accepting connections and waits for the
+// in-flight requests up to the shutdown timeout. Bulk handlers wait for their goroutines
+// before responding, so they are drained as well. Requests still running after the grace
+// period get their context cancelled. App resources are closed last.
 func (s *Server) Run() {
+	// Parent of every request context, cancelled when the grace period runs out
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	s.httpServer = &http.Server{
 		Addr:         fmt.Sprintf(":%s", s.app.Config.Server.Port),
 		Handler:      s.app.Router.GetEngine(),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return requestCtx
+		},
 	}
 
 	// Start server
@@ -44,19 +59,58 @@ func (s *Server) Run() {
 		}
 	}()
 
+	// Metrics get their own listener so scrapes never go through the public API
+	metricsConfig := s.app.Config.Metrics
+	if metricsConfig.Enabled {
+		mux := http.NewServeMux()
+		mux.Handle(metricsConfig.Path, metrics.Handler(metricsConfig.Namespace))
+		s.metricsServer = &http.Server{
+			Addr:              fmt.Sprintf(":%s", metricsConfig.Port),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+
+		go func() {
+			logrus.Infof("Metrics available on port %s at %s", metricsConfig.Port, metricsConfig.Path)
+			if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logrus.Errorf("Metrics server stopped: %v", err)
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logrus.Info("Shutting down server...")
+	// A second signal kills the process right away
+	signal.Stop(quit)
+
+	shutdownTimeout := s.app.Config.Server.ShutdownTimeout
+	logrus.Infof("Shutting down server, waiting up to %s for in-flight requests...", shutdownTimeout)
 
 	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
 	if err := s.httpServer.Shutdown(ctx); err != nil {
-		logrus.Error("Server forced to shutdown:", err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			logrus.Warn("Shutdown grace period expired, cancelling in-flight requests")
+		} else {
+			logrus.Error("Server forced to shutdown:", err)
+		}
+
+		// Abort what is still running and drop the remaining connections
+		cancelRequests()
+		if err := s.httpServer.Close(); err != nil {
+			logrus.Error("Failed to close server:", err)
+		}
+	}
+
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Close(); err != nil {
+			logrus.Error("Failed to close metrics server:", err)
+		}
 	}
 
 	// Close app resources
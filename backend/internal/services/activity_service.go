@@ -1,12 +1,15 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
@@ -17,6 +20,32 @@ type ActivityService struct {
 	db *gorm.DB
 }
 
+// ActivityListener is notified after an activity has been stored. Implementations must not block.
+type ActivityListener interface {
+	OnActivity(activity *models.DocumentActivity)
+}
+
+// Activity services are created per component, so listeners are shared across all of them
+var (
+	activityListenersMu sync.RWMutex
+	activityListeners   []ActivityListener
+)
+
+// AddActivityListener subscribes listener to every activity logged from now on
+func AddActivityListener(listener ActivityListener) {
+	activityListenersMu.Lock()
+	defer activityListenersMu.Unlock()
+	activityListeners = append(activityListeners, listener)
+}
+
+func notifyActivityListeners(activity *models.DocumentActivity) {
+	activityListenersMu.RLock()
+	defer activityListenersMu.RUnlock()
+	for _, listener := range activityListeners {
+		listener.OnActivity(activity)
+	}
+}
+
 func NewActivityService(db *gorm.DB) *ActivityService {
 	return &ActivityService{
 		db: db,
@@ -85,6 +114,8 @@ func (s *ActivityService) LogActivity(ctx *ActivityContext, activityType models.
 		"description":   description,
 	}).Debug("Activity logged successfully")
 
+	notifyActivityListeners(&activity)
+
 	return nil
 }
 
@@ -289,6 +320,24 @@ func (s *ActivityService) LogPreview(ctx *ActivityContext, document *models.Docu
 	return s.LogActivity(ctx, models.ActivityTypePreview, description, metadata)
 }
 
+// Public Link Access Activity, recorded on behalf of the link owner
+func (s *ActivityService) LogSharedLinkAccess(ctx *ActivityContext, document *models.Document, preview bool) error {
+	shareType := "link"
+	metadata := models.ActivityMetadata{
+		FileSize:  &document.FileSize,
+		FileType:  (*string)(&document.FileType),
+		FileName:  &document.OriginalFileName,
+		ShareType: &shareType,
+	}
+
+	if preview {
+		description := fmt.Sprintf("Document '%s' previewed via public link", document.Title)
+		return s.LogActivity(ctx, models.ActivityTypePreview, description, metadata)
+	}
+	description := fmt.Sprintf("Document '%s' downloaded via public link", document.Title)
+	return s.LogActivity(ctx, models.ActivityTypeDownload, description, metadata)
+}
+
 // Rename Activity
 func (s *ActivityService) LogRename(ctx *ActivityContext, document *models.Document, oldTitle string) error {
 	metadata := models.ActivityMetadata{
@@ -300,6 +349,21 @@ func (s *ActivityService) LogRename(ctx *ActivityContext, document *models.Docum
 	return s.LogActivity(ctx, models.ActivityTypeRename, description, metadata)
 }
 
+// Move Activity, folder names are empty for the root
+func (s *ActivityService) LogMove(ctx *ActivityContext, document *models.Document, oldFolderID *uuid.UUID, oldFolder string, newFolder string) error {
+	metadata := models.ActivityMetadata{
+		OldValues: map[string]interface{}{"folderID": oldFolderID, "folder": oldFolder},
+		NewValues: map[string]interface{}{"folderID": document.FolderID, "folder": newFolder},
+	}
+
+	destination := "the root"
+	if newFolder != "" {
+		destination = fmt.Sprintf("folder '%s'", newFolder)
+	}
+	description := fmt.Sprintf("Moved document '%s' to %s", document.Title, destination)
+	return s.LogActivity(ctx, models.ActivityTypeMove, description, metadata)
+}
+
 // Tag Update Activity
 func (s *ActivityService) LogTagUpdate(ctx *ActivityContext, document *models.Document, oldTags, newTags []string) error {
 	metadata := models.ActivityMetadata{
@@ -322,6 +386,31 @@ func (s *ActivityService) LogPermissionChange(ctx *ActivityContext, document *mo
 	return s.LogActivity(ctx, models.ActivityTypePermissionChange, description, metadata)
 }
 
+// Revision Restore Activity
+func (s *ActivityService) LogRevisionRestore(ctx *ActivityContext, document *models.Document, restoredVersion int) error {
+	metadata := models.ActivityMetadata{
+		FileName:  &document.OriginalFileName,
+		FileSize:  &document.FileSize,
+		OldValues: map[string]interface{}{"restoredVersion": restoredVersion},
+		NewValues: map[string]interface{}{"version": document.Version},
+	}
+
+	description := fmt.Sprintf("Restored version %d of document '%s'", restoredVersion, document.Title)
+	return s.LogActivity(ctx, models.ActivityTypeRevisionRestore, description, metadata)
+}
+
+// Copy Activity, logged on the source document
+func (s *ActivityService) LogCopy(ctx *ActivityContext, source, copy *models.Document) error {
+	metadata := models.ActivityMetadata{
+		FileName:  &source.OriginalFileName,
+		FileSize:  &source.FileSize,
+		NewValues: map[string]interface{}{"copyID": copy.ID, "title": copy.Title},
+	}
+
+	description := fmt.Sprintf("Copied document '%s' as '%s'", source.Title, copy.Title)
+	return s.LogActivity(ctx, models.ActivityTypeCopy, description, metadata)
+}
+
 // Error Activity (for failed operations)
 func (s *ActivityService) LogError(ctx *ActivityContext, activityType models.ActivityType, errorMsg, errorCode string) error {
 	metadata := models.ActivityMetadata{
@@ -444,6 +533,114 @@ func (s *ActivityService) GetActivityStats(userID *uuid.UUID, documentID *uuid.U
 	}, nil
 }
 
+// Number of accesses listed in document analytics
+const documentAnalyticsRecentLimit = 50
+
+// Share audit actions that are not accesses of the shared document
+var shareManagementActions = []string{"created", "updated", "revoked", "accepted"}
+
+// Computes how a document was accessed over the last given number of days. The owner's own
+// views and downloads are left out, accesses through a public link are logged under the owner
+// and are kept.
+func (s *ActivityService) GetDocumentAnalytics(ctx context.Context, document *models.Document, days int) (*types.DocumentAnalyticsResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(days - 1))
+
+	analytics := &types.DocumentAnalyticsResponse{
+		DocumentID:     document.ID,
+		TotalViews:     document.ViewCount,
+		TotalDownloads: document.DownloadCount,
+		Days:           days,
+		From:           from,
+	}
+
+	accesses := func() *gorm.DB {
+		return db.Model(&models.DocumentActivity{}).
+			Where("document_id = ? AND created_at >= ?", document.ID, from).
+			Where("activity_type IN ?", []models.ActivityType{models.ActivityTypeView, models.ActivityTypePreview, models.ActivityTypeDownload}).
+			Where("user_id <> ? OR metadata->>'source' = 'public_link'", document.UserID)
+	}
+
+	var perDay []struct {
+		Day       time.Time
+		Views     int64
+		Downloads int64
+	}
+	if err := accesses().
+		Select("DATE(created_at AT TIME ZONE 'UTC') AS day, "+
+			"COUNT(*) FILTER (WHERE activity_type IN ?) AS views, "+
+			"COUNT(*) FILTER (WHERE activity_type = ?) AS downloads",
+			[]models.ActivityType{models.ActivityTypeView, models.ActivityTypePreview}, models.ActivityTypeDownload).
+		Group("day").
+		Scan(&perDay).Error; err != nil {
+		return nil, fmt.Errorf("failed to count document accesses: %w", err)
+	}
+
+	counts := make(map[string]types.DailyAccessCount, len(perDay))
+	for _, row := range perDay {
+		analytics.Views += row.Views
+		analytics.Downloads += row.Downloads
+		counts[row.Day.Format("2006-01-02")] = types.DailyAccessCount{Views: row.Views, Downloads: row.Downloads}
+	}
+	analytics.PerDay = make([]types.DailyAccessCount, 0, days)
+	for i := 0; i < days; i++ {
+		date := from.AddDate(0, 0, i).Format("2006-01-02")
+		count := counts[date]
+		count.Date = date
+		analytics.PerDay = append(analytics.PerDay, count)
+	}
+
+	// Signed-in users other than the owner, whether they opened the document in the app or recorded
+	// an access through their share
+	if err := db.Raw(`
+		SELECT COUNT(DISTINCT user_id) FROM (
+			SELECT user_id FROM document_activities
+			WHERE document_id = ? AND created_at >= ? AND activity_type IN ? AND user_id <> ?
+			UNION
+			SELECT l.user_id FROM user_share_audit_logs l
+			JOIN user_shares s ON s.id = l.user_share_id
+			WHERE s.document_id = ? AND l.created_at >= ? AND l.action NOT IN ? AND l.user_id <> ?
+		) viewers`,
+		document.ID, from, []models.ActivityType{models.ActivityTypeView, models.ActivityTypePreview}, document.UserID,
+		document.ID, from, shareManagementActions, document.UserID,
+	).Scan(&analytics.UniqueViewers).Error; err != nil {
+		return nil, fmt.Errorf("failed to count unique viewers: %w", err)
+	}
+
+	analytics.RecentAccesses = make([]types.DocumentAccessEntry, 0)
+	if err := db.Raw(`
+		SELECT a.action, a.channel, a.user_id,
+			COALESCE(u.username, '') AS username, COALESCE(u.name, '') AS name, COALESCE(u.email, '') AS email,
+			COALESCE(a.ip_address, '') AS ip_address, COALESCE(a.user_agent, '') AS user_agent, a.accessed_at
+		FROM (
+			SELECT activity_type AS action,
+				CASE WHEN metadata->>'source' = 'public_link' THEN 'public_link' ELSE 'app' END AS channel,
+				CASE WHEN metadata->>'source' = 'public_link' THEN NULL ELSE user_id END AS user_id,
+				ip_address, user_agent, created_at AS accessed_at
+			FROM document_activities
+			WHERE document_id = ? AND created_at >= ? AND activity_type IN ?
+				AND (user_id <> ? OR metadata->>'source' = 'public_link')
+			UNION ALL
+			SELECT l.action, 'share' AS channel, l.user_id, l.ip_address, l.user_agent, l.created_at AS accessed_at
+			FROM user_share_audit_logs l
+			JOIN user_shares s ON s.id = l.user_share_id
+			WHERE s.document_id = ? AND l.created_at >= ? AND l.action NOT IN ? AND l.user_id <> ?
+		) a
+		LEFT JOIN users u ON u.id = a.user_id
+		ORDER BY a.accessed_at DESC
+		LIMIT ?`,
+		document.ID, from, []models.ActivityType{models.ActivityTypeView, models.ActivityTypePreview, models.ActivityTypeDownload}, document.UserID,
+		document.ID, from, shareManagementActions, document.UserID,
+		documentAnalyticsRecentLimit,
+	).Scan(&analytics.RecentAccesses).Error; err != nil {
+		return nil, fmt.Errorf("failed to list recent accesses: %w", err)
+	}
+
+	return analytics, nil
+}
+
 // Removes activities older than the specified duration
 func (s *ActivityService) CleanupOldActivities(olderThan time.Duration) error {
 	cutoff := time.Now().Add(-olderThan)
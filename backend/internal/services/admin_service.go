@@ -0,0 +1,995 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// How long platform statistics are served from cache
+	PlatformStatsCacheTTL = 5 * time.Minute
+
+	topFileTypesLimit = 10
+
+	// Documents moved per batch before progress is saved
+	documentTransferBatchSize = 50
+
+	// Upper bound on users returned by a lookup, values are expected to be nearly unique
+	userLookupLimit = 20
+
+	// Documents whose search vectors are recomputed per statement, small enough to keep row locks short
+	searchReindexBatchSize = 200
+	// Pause between reindex batches so a full reindex does not crowd out regular queries
+	searchReindexBatchPause = 100 * time.Millisecond
+)
+
+type AdminService struct {
+	db            *gorm.DB
+	redisClient   *redis.Client
+	minioService  *MinIOService
+	searchCache   *SearchCache // nil when search results are not cached
+	blindIndexKey string       // empty when the profile lookup is disabled
+}
+
+func NewAdminService(db *gorm.DB, redisClient *redis.Client, minioService *MinIOService, searchCache *SearchCache, blindIndexKey string) *AdminService {
+	return &AdminService{
+		db:            db,
+		redisClient:   redisClient,
+		minioService:  minioService,
+		searchCache:   searchCache,
+		blindIndexKey: blindIndexKey,
+	}
+}
+
+// Request types
+type TransferDocumentsRequest struct {
+	TargetUserID string `json:"targetUserId" binding:"required,uuid"`
+}
+
+type StartSearchReindexRequest struct {
+	UserID      string     `json:"userId" binding:"omitempty,uuid"`
+	CreatedFrom *time.Time `json:"createdFrom"`
+	CreatedTo   *time.Time `json:"createdTo"`
+}
+
+type UpdateUserStatusRequest struct {
+	Status models.UserStatus `json:"status" binding:"required,oneof=active suspended"`
+}
+
+// Finds users by email or phone. Alternate emails and phones are matched through their blind
+// indexes, so encrypted profile fields are found without being decrypted.
+func (s *AdminService) LookupUsers(ctx context.Context, email, phone string) ([]models.User, error) {
+	if s.blindIndexKey == "" {
+		return nil, fmt.Errorf("user lookup is not configured")
+	}
+
+	query := s.db.WithContext(ctx).Preload("Role")
+	email = utils.NormalizeEmail(email)
+	phone = utils.NormalizePhone(phone)
+	switch {
+	case email != "" && phone != "":
+		query = query.Where("LOWER(email) = ? OR alt_email_index = ? OR phone_index = ?",
+			email, utils.BlindIndex(s.blindIndexKey, email), utils.BlindIndex(s.blindIndexKey, phone))
+	case email != "":
+		query = query.Where("LOWER(email) = ? OR alt_email_index = ?", email, utils.BlindIndex(s.blindIndexKey, email))
+	case phone != "":
+		query = query.Where("phone_index = ?", utils.BlindIndex(s.blindIndexKey, phone))
+	default:
+		return nil, fmt.Errorf("email or phone is required")
+	}
+
+	var users []models.User
+	if err := query.Order("created_at ASC").Limit(userLookupLimit).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up users: %w", err)
+	}
+
+	for i := range users {
+		users[i].Password = ""
+	}
+	return users, nil
+}
+
+// Lists documents of all users with their owners, newest first
+func (s *AdminService) ListDocuments(ctx context.Context, req *types.AdminDocumentListRequest) (*types.AdminDocumentListResponse, error) {
+	query := s.db.WithContext(ctx).Model(&models.Document{})
+	if req.Search != "" {
+		pattern := "%" + escapeLikePattern(req.Search) + "%"
+		query = query.Where("documents.title ILIKE ? OR documents.original_file_name ILIKE ?", pattern, pattern)
+	}
+	if req.OwnerID != nil {
+		query = query.Where("documents.user_id = ?", *req.OwnerID)
+	}
+	if req.FileType != "" {
+		query = query.Where("documents.file_type = ?", req.FileType)
+	}
+	if req.Status != "" {
+		query = query.Where("documents.status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	var documents []models.Document
+	if err := query.Joins("User").
+		Order("documents.created_at DESC").
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Find(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to list documents: %w", err)
+	}
+
+	items := make([]types.AdminDocument, len(documents))
+	for i, document := range documents {
+		items[i] = types.AdminDocument{
+			ID:               document.ID,
+			Title:            document.Title,
+			OriginalFileName: document.OriginalFileName,
+			FileType:         document.FileType,
+			FileSize:         document.FileSize,
+			Status:           document.Status,
+			IsPublic:         document.IsPublic,
+			ViewCount:        document.ViewCount,
+			DownloadCount:    document.DownloadCount,
+			Owner: types.AdminDocumentOwner{
+				ID:       document.User.ID,
+				Username: document.User.Username,
+				Name:     document.User.Name,
+				Email:    document.User.Email,
+				Status:   document.User.Status,
+			},
+			CreatedAt: document.CreatedAt,
+			UpdatedAt: document.UpdatedAt,
+		}
+	}
+
+	return &types.AdminDocumentListResponse{
+		Documents:  items,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalPages: int((total + int64(req.Limit) - 1) / int64(req.Limit)),
+	}, nil
+}
+
+// Lists users with their account state and document usage, newest first
+func (s *AdminService) ListUsers(ctx context.Context, req *types.AdminUserListRequest) (*types.AdminUserListResponse, error) {
+	query := s.db.WithContext(ctx).Model(&models.User{})
+	if req.Search != "" {
+		pattern := "%" + escapeLikePattern(req.Search) + "%"
+		query = query.Where("email ILIKE ? OR username ILIKE ? OR name ILIKE ?", pattern, pattern, pattern)
+	}
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	var users []models.User
+	if err := query.Preload("Role").
+		Order("created_at DESC").
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	// Usage of the whole page in one query, trashed documents still take up storage
+	userIDs := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+	var usageRows []struct {
+		UserID        uuid.UUID
+		DocumentCount int64
+		StorageBytes  int64
+	}
+	if len(userIDs) > 0 {
+		if err := s.db.WithContext(ctx).Unscoped().Model(&models.Document{}).
+			Select("user_id, COUNT(*) FILTER (WHERE deleted_at IS NULL) AS document_count, COALESCE(SUM(file_size), 0) AS storage_bytes").
+			Where("user_id IN ?", userIDs).
+			Group("user_id").
+			Scan(&usageRows).Error; err != nil {
+			return nil, fmt.Errorf("failed to get user usage: %w", err)
+		}
+	}
+	usage := make(map[uuid.UUID]int, len(usageRows))
+	for i, row := range usageRows {
+		usage[row.UserID] = i
+	}
+
+	items := make([]types.AdminUser, len(users))
+	for i, user := range users {
+		items[i] = types.AdminUser{
+			ID:            user.ID,
+			Email:         user.Email,
+			Username:      user.Username,
+			Name:          user.Name,
+			Status:        user.Status,
+			EmailVerified: user.EmailVerified,
+			Locked:        user.IsLocked(),
+			Role:          user.Role.Name,
+			LastLogin:     user.LastLogin,
+			CreatedAt:     user.CreatedAt,
+		}
+		if row, ok := usage[user.ID]; ok {
+			items[i].DocumentCount = usageRows[row].DocumentCount
+			items[i].StorageBytes = usageRows[row].StorageBytes
+		}
+	}
+
+	return &types.AdminUserListResponse{
+		Users:      items,
+		Total:      total,
+		Page:       req.Page,
+		Limit:      req.Limit,
+		TotalPages: int((total + int64(req.Limit) - 1) / int64(req.Limit)),
+	}, nil
+}
+
+// Activates or suspends a user. Suspending also revokes the user's refresh tokens, so their
+// sessions end once the current access tokens expire and logging in again is refused.
+func (s *AdminService) SetUserStatus(ctx context.Context, adminID, userID uuid.UUID, status models.UserStatus) (*models.User, error) {
+	if status != models.StatusActive && status != models.StatusSuspended {
+		return nil, fmt.Errorf("invalid status")
+	}
+	if adminID == userID {
+		return nil, fmt.Errorf("cannot change your own status")
+	}
+
+	var user models.User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Role").First(&user, "id = ?", userID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("user not found")
+			}
+			return fmt.Errorf("failed to get user: %w", err)
+		}
+
+		if err := tx.Model(&user).Update("status", status).Error; err != nil {
+			return fmt.Errorf("failed to update user status: %w", err)
+		}
+
+		if status == models.StatusSuspended {
+			if err := tx.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error; err != nil {
+				return fmt.Errorf("failed to revoke sessions: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"admin_id": adminID,
+		"user_id":  userID,
+		"status":   status,
+	}).Info("User status changed")
+
+	user.Password = ""
+	return &user, nil
+}
+
+// Escapes the LIKE wildcards so search input matches literally
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// Computes platform-wide statistics over the last given number of days, cached briefly in Redis
+func (s *AdminService) GetPlatformStats(ctx context.Context, days int) (*types.PlatformStatsResponse, error) {
+	cacheKey := fmt.Sprintf("admin:stats:%d", days)
+
+	if s.redisClient != nil {
+		if cached, err := s.redisClient.Get(cacheKey); err == nil && cached != "" {
+			var stats types.PlatformStatsResponse
+			if err := json.Unmarshal([]byte(cached), &stats); err == nil {
+				return &stats, nil
+			}
+		}
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -(days - 1))
+	db := s.db.WithContext(ctx)
+
+	stats := &types.PlatformStatsResponse{
+		Days:        days,
+		From:        from,
+		GeneratedAt: now,
+	}
+
+	var users struct {
+		TotalUsers  int64
+		ActiveUsers int64
+	}
+	if err := db.Model(&models.User{}).
+		Select("COUNT(*) AS total_users, COUNT(*) FILTER (WHERE last_login >= ?) AS active_users", from).
+		Scan(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+	stats.TotalUsers = users.TotalUsers
+	stats.ActiveUsers = users.ActiveUsers
+
+	// Trashed documents still occupy storage until they are purged
+	var documents struct {
+		TotalDocuments int64
+		StorageUsed    int64
+	}
+	if err := db.Unscoped().Model(&models.Document{}).
+		Select("COUNT(*) FILTER (WHERE deleted_at IS NULL) AS total_documents, COALESCE(SUM(file_size), 0) AS storage_used").
+		Scan(&documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to count documents: %w", err)
+	}
+	stats.TotalDocuments = documents.TotalDocuments
+	stats.TotalStorageBytes = documents.StorageUsed
+
+	var uploads []struct {
+		Day   time.Time
+		Count int64
+	}
+	if err := db.Unscoped().Model(&models.Document{}).
+		Select("DATE(created_at AT TIME ZONE 'UTC') AS day, COUNT(*) AS count").
+		Where("created_at >= ?", from).
+		Group("day").
+		Order("day").
+		Scan(&uploads).Error; err != nil {
+		return nil, fmt.Errorf("failed to count uploads per day: %w", err)
+	}
+	stats.UploadsPerDay = fillDailyCounts(from, days, uploads)
+
+	if err := db.Model(&models.Document{}).
+		Select("file_type, COUNT(*) AS count, COALESCE(SUM(file_size), 0) AS total_bytes").
+		Group("file_type").
+		Order("count DESC").
+		Limit(topFileTypesLimit).
+		Scan(&stats.TopFileTypes).Error; err != nil {
+		return nil, fmt.Errorf("failed to count file types: %w", err)
+	}
+
+	if s.redisClient != nil {
+		if data, err := json.Marshal(stats); err == nil {
+			if err := s.redisClient.SetWithExpiry(cacheKey, data, PlatformStatsCacheTTL); err != nil {
+				logrus.Warnf("Failed to cache platform stats: %v", err)
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// Returns one entry per day in the range, including days without uploads
+func fillDailyCounts(from time.Time, days int, rows []struct {
+	Day   time.Time
+	Count int64
+}) []types.DailyCount {
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Day.Format("2006-01-02")] = row.Count
+	}
+
+	result := make([]types.DailyCount, 0, days)
+	for i := 0; i < days; i++ {
+		date := from.AddDate(0, 0, i).Format("2006-01-02")
+		result = append(result, types.DailyCount{Date: date, Count: counts[date]})
+	}
+	return result
+}
+
+// Starts moving every document owned by the source user to the target user in the background.
+// Requesting the same transfer again returns the active job instead of starting a second one,
+// and documents already moved are skipped, so an interrupted transfer can simply be requested again.
+func (s *AdminService) StartDocumentTransfer(ctx context.Context, requestedBy, sourceUserID, targetUserID uuid.UUID) (*models.DocumentTransfer, bool, error) {
+	if sourceUserID == targetUserID {
+		return nil, false, fmt.Errorf("source and target users must differ")
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", sourceUserID).Count(&count).Error; err != nil || count == 0 {
+		return nil, false, fmt.Errorf("source user not found")
+	}
+	if err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", targetUserID).Count(&count).Error; err != nil || count == 0 {
+		return nil, false, fmt.Errorf("target user not found")
+	}
+
+	var active models.DocumentTransfer
+	err := s.db.WithContext(ctx).
+		Where("source_user_id = ? AND status IN ?", sourceUserID, []models.DocumentTransferStatus{
+			models.DocumentTransferStatusPending,
+			models.DocumentTransferStatusRunning,
+		}).
+		First(&active).Error
+	if err == nil {
+		if active.TargetUserID != targetUserID {
+			return nil, false, fmt.Errorf("another transfer is already in progress for this user")
+		}
+		return &active, false, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, false, fmt.Errorf("failed to check active transfers: %w", err)
+	}
+
+	var total int64
+	if err := s.db.WithContext(ctx).Unscoped().Model(&models.Document{}).
+		Where("user_id = ?", sourceUserID).
+		Count(&total).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	transfer := &models.DocumentTransfer{
+		SourceUserID:   sourceUserID,
+		TargetUserID:   targetUserID,
+		RequestedBy:    requestedBy,
+		Status:         models.DocumentTransferStatusPending,
+		TotalDocuments: int(total),
+	}
+	if err := s.db.WithContext(ctx).Create(transfer).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to create transfer: %w", err)
+	}
+
+	logrus.Infof("[TRANSFER] Transfer %s created by %s: %d documents from %s to %s", transfer.ID, requestedBy, total, sourceUserID, targetUserID)
+
+	// The worker updates its own copy so the returned job is safe to read
+	job := *transfer
+	go s.runDocumentTransfer(context.Background(), &job)
+
+	return transfer, true, nil
+}
+
+// Recomputes the search vector of a single document and returns it for inspection. The update
+// goes through the search vector trigger so the result matches what regular edits produce.
+func (s *AdminService) ReindexDocument(ctx context.Context, documentID uuid.UUID) (*types.DocumentReindexResponse, error) {
+	var before struct {
+		Language     string
+		SearchVector *string
+	}
+	err := s.db.WithContext(ctx).Raw(
+		`SELECT language, search_vector::text AS search_vector FROM documents WHERE id = ?`, documentID,
+	).Scan(&before).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch document: %w", err)
+	}
+	if before.Language == "" {
+		return nil, fmt.Errorf("document not found")
+	}
+
+	if err := s.checkSearchVectorTrigger(ctx); err != nil {
+		return nil, err
+	}
+
+	// Assigning a watched column fires the trigger even though the value does not change
+	if err := s.db.WithContext(ctx).Exec(`UPDATE documents SET title = title WHERE id = ?`, documentID).Error; err != nil {
+		return nil, fmt.Errorf("failed to recompute search vector: %w", err)
+	}
+
+	var after struct {
+		SearchVector string
+		LexemeCount  int
+		SearchConfig string
+	}
+	err = s.db.WithContext(ctx).Raw(`
+		SELECT COALESCE(search_vector::text, '') AS search_vector,
+			COALESCE(length(search_vector), 0) AS lexeme_count,
+			document_search_config(language)::text AS search_config
+		FROM documents WHERE id = ?
+	`, documentID).Scan(&after).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search vector: %w", err)
+	}
+
+	previous := ""
+	if before.SearchVector != nil {
+		previous = *before.SearchVector
+	}
+
+	response := &types.DocumentReindexResponse{
+		DocumentID:     documentID.String(),
+		Language:       before.Language,
+		SearchConfig:   after.SearchConfig,
+		PreviousVector: previous,
+		SearchVector:   after.SearchVector,
+		LexemeCount:    after.LexemeCount,
+		Changed:        previous != after.SearchVector,
+		ReindexedAt:    time.Now().UTC(),
+	}
+
+	logrus.Infof("[ADMIN] Reindexed document %s (changed: %t, lexemes: %d)", documentID, response.Changed, response.LexemeCount)
+	return response, nil
+}
+
+// Without the trigger the vector is no longer maintained and needs the full search migration
+func (s *AdminService) checkSearchVectorTrigger(ctx context.Context) error {
+	var triggerInstalled bool
+	if err := s.db.WithContext(ctx).Raw(`
+		SELECT EXISTS (
+			SELECT 1 FROM pg_trigger
+			WHERE tgname = 'documents_search_vector_trigger' AND tgrelid = 'documents'::regclass AND tgenabled <> 'D'
+		)
+	`).Scan(&triggerInstalled).Error; err != nil {
+		return fmt.Errorf("failed to check search vector trigger: %w", err)
+	}
+	if !triggerInstalled {
+		return fmt.Errorf("search vector trigger is not installed")
+	}
+	return nil
+}
+
+// Starts recomputing the search vectors of every document in scope in the background, e.g. after
+// the search configuration or text extraction changed. Vectors go through the search vector
+// trigger in small batches, so regular traffic keeps being served. Requesting the same scope
+// again returns the active job instead of starting a second one.
+func (s *AdminService) StartSearchReindex(ctx context.Context, requestedBy uuid.UUID, req *StartSearchReindexRequest) (*models.SearchReindexJob, bool, error) {
+	if req.CreatedFrom != nil && req.CreatedTo != nil && req.CreatedFrom.After(*req.CreatedTo) {
+		return nil, false, fmt.Errorf("createdFrom must not be after createdTo")
+	}
+	if err := s.checkSearchVectorTrigger(ctx); err != nil {
+		return nil, false, err
+	}
+
+	job := &models.SearchReindexJob{
+		RequestedBy: requestedBy,
+		Status:      models.SearchReindexStatusPending,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+	}
+	if req.UserID != "" {
+		userID, _ := uuid.Parse(req.UserID)
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Count(&count).Error; err != nil || count == 0 {
+			return nil, false, fmt.Errorf("user not found")
+		}
+		job.UserID = &userID
+	}
+
+	// One job at a time, concurrent jobs would only lock the same rows twice
+	var active models.SearchReindexJob
+	err := s.db.WithContext(ctx).
+		Where("status IN ?", []models.SearchReindexStatus{
+			models.SearchReindexStatusPending,
+			models.SearchReindexStatusRunning,
+		}).
+		First(&active).Error
+	if err == nil {
+		if !sameReindexScope(&active, job) {
+			return nil, false, fmt.Errorf("another reindex is already in progress")
+		}
+		return &active, false, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, false, fmt.Errorf("failed to check active reindex jobs: %w", err)
+	}
+
+	var total int64
+	if err := s.reindexScope(ctx, job).Count(&total).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to count documents: %w", err)
+	}
+	job.TotalDocuments = int(total)
+
+	if err := s.db.WithContext(ctx).Create(job).Error; err != nil {
+		return nil, false, fmt.Errorf("failed to create reindex job: %w", err)
+	}
+
+	logrus.Infof("[REINDEX] Job %s created by %s: %d documents", job.ID, requestedBy, total)
+
+	// The worker updates its own copy so the returned job is safe to read
+	worker := *job
+	go s.runSearchReindex(context.Background(), &worker)
+
+	return job, true, nil
+}
+
+// Returns a reindex job with its progress
+func (s *AdminService) GetSearchReindex(ctx context.Context, jobID uuid.UUID) (*models.SearchReindexJob, error) {
+	var job models.SearchReindexJob
+	if err := s.db.WithContext(ctx).Where("id = ?", jobID).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("reindex job not found")
+		}
+		return nil, fmt.Errorf("failed to fetch reindex job: %w", err)
+	}
+	return &job, nil
+}
+
+// Restarts reindex jobs that were interrupted, e.g. by a shutdown, from the last saved batch
+func (s *AdminService) ResumeSearchReindexJobs(ctx context.Context) {
+	var jobs []models.SearchReindexJob
+	if err := s.db.WithContext(ctx).
+		Where("status IN ?", []models.SearchReindexStatus{
+			models.SearchReindexStatusPending,
+			models.SearchReindexStatusRunning,
+		}).
+		Find(&jobs).Error; err != nil {
+		logrus.Errorf("[REINDEX] Failed to load interrupted reindex jobs: %v", err)
+		return
+	}
+
+	for i := range jobs {
+		logrus.Infof("[REINDEX] Resuming job %s", jobs[i].ID)
+		go s.runSearchReindex(ctx, &jobs[i])
+	}
+}
+
+// Documents in the job's scope, trashed ones included so they are current when restored
+func (s *AdminService) reindexScope(ctx context.Context, job *models.SearchReindexJob) *gorm.DB {
+	query := s.db.WithContext(ctx).Unscoped().Model(&models.Document{})
+	if job.UserID != nil {
+		query = query.Where("user_id = ?", *job.UserID)
+	}
+	if job.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *job.CreatedFrom)
+	}
+	if job.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *job.CreatedTo)
+	}
+	return query
+}
+
+func sameReindexScope(a, b *models.SearchReindexJob) bool {
+	sameTime := func(x, y *time.Time) bool {
+		return (x == nil && y == nil) || (x != nil && y != nil && x.Equal(*y))
+	}
+	sameUser := (a.UserID == nil && b.UserID == nil) || (a.UserID != nil && b.UserID != nil && *a.UserID == *b.UserID)
+	return sameUser && sameTime(a.CreatedFrom, b.CreatedFrom) && sameTime(a.CreatedTo, b.CreatedTo)
+}
+
+func (s *AdminService) runSearchReindex(ctx context.Context, job *models.SearchReindexJob) {
+	now := time.Now()
+	if job.StartedAt == nil {
+		job.StartedAt = &now
+	}
+	if err := s.db.WithContext(ctx).Model(job).Updates(map[string]interface{}{
+		"status":     models.SearchReindexStatusRunning,
+		"started_at": job.StartedAt,
+	}).Error; err != nil {
+		logrus.Errorf("[REINDEX] Failed to start job %s: %v", job.ID, err)
+		return
+	}
+
+	lastID := uuid.Nil
+	if job.LastDocumentID != nil {
+		lastID = *job.LastDocumentID
+	}
+	for {
+		var ids []uuid.UUID
+		if err := s.reindexScope(ctx, job).
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(searchReindexBatchSize).
+			Pluck("id", &ids).Error; err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.finishSearchReindex(ctx, job, fmt.Sprintf("failed to fetch documents: %v", err))
+			return
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		// Assigning a watched column fires the trigger even though the value does not change
+		if err := s.db.WithContext(ctx).Exec(`UPDATE documents SET title = title WHERE id IN ?`, ids).Error; err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.finishSearchReindex(ctx, job, fmt.Sprintf("failed to recompute search vectors: %v", err))
+			return
+		}
+		lastID = ids[len(ids)-1]
+		job.LastDocumentID = &lastID
+		job.ReindexedDocuments += len(ids)
+
+		if err := s.db.WithContext(ctx).Model(job).Updates(map[string]interface{}{
+			"reindexed_documents": job.ReindexedDocuments,
+			"last_document_id":    lastID,
+		}).Error; err != nil {
+			logrus.Warnf("[REINDEX] Failed to save progress of job %s: %v", job.ID, err)
+		}
+		logrus.Infof("[REINDEX] Job %s: %d/%d documents reindexed", job.ID, job.ReindexedDocuments, job.TotalDocuments)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(searchReindexBatchPause):
+		}
+	}
+
+	s.finishSearchReindex(ctx, job, "")
+}
+
+func (s *AdminService) finishSearchReindex(ctx context.Context, job *models.SearchReindexJob, lastError string) {
+	status := models.SearchReindexStatusCompleted
+	if lastError != "" {
+		status = models.SearchReindexStatusFailed
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(job).Updates(map[string]interface{}{
+		"status":              status,
+		"reindexed_documents": job.ReindexedDocuments,
+		"last_error":          lastError,
+		"completed_at":        now,
+	}).Error; err != nil {
+		logrus.Errorf("[REINDEX] Failed to complete job %s: %v", job.ID, err)
+	}
+
+	logrus.Infof("[REINDEX] Job %s %s: %d of %d documents reindexed", job.ID, status, job.ReindexedDocuments, job.TotalDocuments)
+}
+
+// Returns a transfer job with its progress
+func (s *AdminService) GetDocumentTransfer(ctx context.Context, transferID uuid.UUID) (*models.DocumentTransfer, error) {
+	var transfer models.DocumentTransfer
+	if err := s.db.WithContext(ctx).Where("id = ?", transferID).First(&transfer).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("transfer not found")
+		}
+		return nil, fmt.Errorf("failed to fetch transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+// Restarts transfers that were interrupted, e.g. by a shutdown
+func (s *AdminService) ResumeDocumentTransfers(ctx context.Context) {
+	var transfers []models.DocumentTransfer
+	if err := s.db.WithContext(ctx).
+		Where("status IN ?", []models.DocumentTransferStatus{
+			models.DocumentTransferStatusPending,
+			models.DocumentTransferStatusRunning,
+		}).
+		Find(&transfers).Error; err != nil {
+		logrus.Errorf("[TRANSFER] Failed to load interrupted transfers: %v", err)
+		return
+	}
+
+	for i := range transfers {
+		logrus.Infof("[TRANSFER] Resuming transfer %s", transfers[i].ID)
+		go s.runDocumentTransfer(ctx, &transfers[i])
+	}
+}
+
+func (s *AdminService) runDocumentTransfer(ctx context.Context, transfer *models.DocumentTransfer) {
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(transfer).Updates(map[string]interface{}{
+		"status":     models.DocumentTransferStatusRunning,
+		"started_at": now,
+	}).Error; err != nil {
+		logrus.Errorf("[TRANSFER] Failed to start transfer %s: %v", transfer.ID, err)
+		return
+	}
+
+	// Documents that fail stay with the source user, the cursor keeps them from being retried in this run
+	lastID := uuid.Nil
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var documents []models.Document
+		if err := s.db.WithContext(ctx).Unscoped().
+			Where("user_id = ? AND id > ?", transfer.SourceUserID, lastID).
+			Order("id ASC").
+			Limit(documentTransferBatchSize).
+			Find(&documents).Error; err != nil {
+			s.finishDocumentTransfer(ctx, transfer, fmt.Sprintf("failed to fetch documents: %v", err))
+			return
+		}
+		if len(documents) == 0 {
+			break
+		}
+
+		for i := range documents {
+			if err := s.transferDocument(ctx, &documents[i], transfer.TargetUserID); err != nil {
+				logrus.Errorf("[TRANSFER] Transfer %s: failed to move document %s: %v", transfer.ID, documents[i].ID, err)
+				transfer.FailedDocuments++
+				transfer.LastError = err.Error()
+				continue
+			}
+			transfer.TransferredDocuments++
+			logrus.Infof("[TRANSFER] Transfer %s: document %s moved from %s to %s", transfer.ID, documents[i].ID, transfer.SourceUserID, transfer.TargetUserID)
+		}
+		lastID = documents[len(documents)-1].ID
+
+		if err := s.db.WithContext(ctx).Model(transfer).Updates(map[string]interface{}{
+			"transferred_documents": transfer.TransferredDocuments,
+			"failed_documents":      transfer.FailedDocuments,
+			"last_error":            transfer.LastError,
+		}).Error; err != nil {
+			logrus.Warnf("[TRANSFER] Failed to save progress of transfer %s: %v", transfer.ID, err)
+		}
+	}
+
+	lastError := ""
+	if transfer.FailedDocuments > 0 {
+		lastError = transfer.LastError
+	}
+	s.finishDocumentTransfer(ctx, transfer, lastError)
+}
+
+func (s *AdminService) finishDocumentTransfer(ctx context.Context, transfer *models.DocumentTransfer, lastError string) {
+	status := models.DocumentTransferStatusCompleted
+	if lastError != "" {
+		status = models.DocumentTransferStatusFailed
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).Model(transfer).Updates(map[string]interface{}{
+		"status":                status,
+		"transferred_documents": transfer.TransferredDocuments,
+		"failed_documents":      transfer.FailedDocuments,
+		"last_error":            lastError,
+		"completed_at":          now,
+	}).Error; err != nil {
+		logrus.Errorf("[TRANSFER] Failed to complete transfer %s: %v", transfer.ID, err)
+	}
+
+	// Storage usage of both users changed
+	if s.redisClient != nil {
+		s.redisClient.Delete(quotaCacheKey(transfer.SourceUserID))
+		s.redisClient.Delete(quotaCacheKey(transfer.TargetUserID))
+	}
+
+	logrus.Infof("[TRANSFER] Transfer %s %s: %d transferred, %d failed", transfer.ID, status, transfer.TransferredDocuments, transfer.FailedDocuments)
+}
+
+// Moves a document's objects, including the files kept for its revisions, under the target
+// user's prefix and reassigns it along with its shares. Comments, favorites and activity are
+// keyed by document and stay untouched.
+func (s *AdminService) transferDocument(ctx context.Context, document *models.Document, targetUserID uuid.UUID) error {
+	sourceUserID := document.UserID
+	storagePath := rekeyUserPath(document.StoragePath, sourceUserID, targetUserID)
+	thumbnailPath := rekeyUserPath(document.ThumbnailPath, sourceUserID, targetUserID)
+	previewPath := rekeyUserPath(document.PreviewPath, sourceUserID, targetUserID)
+
+	// Older versions have files of their own, the current file is usually shared with the latest revision
+	var revisionPaths []string
+	if err := s.db.WithContext(ctx).Model(&models.DocumentRevision{}).
+		Where("document_id = ? AND storage_path <> '' AND storage_path <> ?", document.ID, document.StoragePath).
+		Distinct("storage_path").
+		Pluck("storage_path", &revisionPaths).Error; err != nil {
+		return fmt.Errorf("failed to fetch revision files: %w", err)
+	}
+	movedRevisionPaths := make(map[string]string, len(revisionPaths))
+	for _, path := range revisionPaths {
+		if rekeyed := rekeyUserPath(path, sourceUserID, targetUserID); rekeyed != path {
+			movedRevisionPaths[path] = rekeyed
+		}
+	}
+
+	// Copy first so the document is never left pointing at a missing object
+	if storagePath != document.StoragePath {
+		if err := s.minioService.CopyFile(ctx, document.StoragePath, storagePath); err != nil {
+			return err
+		}
+	}
+	if thumbnailPath != document.ThumbnailPath {
+		if err := s.minioService.CopyFile(ctx, document.ThumbnailPath, thumbnailPath); err != nil {
+			return err
+		}
+	}
+	if previewPath != document.PreviewPath {
+		if err := s.minioService.CopyFile(ctx, document.PreviewPath, previewPath); err != nil {
+			return err
+		}
+	}
+	for path, rekeyed := range movedRevisionPaths {
+		if err := s.minioService.CopyFile(ctx, path, rekeyed); err != nil {
+			return err
+		}
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&models.Document{}).
+			Where("id = ? AND user_id = ?", document.ID, sourceUserID).
+			Updates(map[string]interface{}{
+				"user_id":        targetUserID,
+				"storage_path":   storagePath,
+				"thumbnail_path": thumbnailPath,
+				"preview_path":   previewPath,
+				// Folders stay with the source user, the document lands in the new owner's root
+				"folder_id": nil,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to reassign document: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("document was modified during transfer")
+		}
+
+		// Revisions must keep pointing at files that exist once the old objects are deleted
+		if storagePath != document.StoragePath {
+			if err := tx.Model(&models.DocumentRevision{}).
+				Where("document_id = ? AND storage_path = ?", document.ID, document.StoragePath).
+				Update("storage_path", storagePath).Error; err != nil {
+				return fmt.Errorf("failed to reassign revision files: %w", err)
+			}
+		}
+		for path, rekeyed := range movedRevisionPaths {
+			if err := tx.Model(&models.DocumentRevision{}).
+				Where("document_id = ? AND storage_path = ?", document.ID, path).
+				Update("storage_path", rekeyed).Error; err != nil {
+				return fmt.Errorf("failed to reassign revision files: %w", err)
+			}
+		}
+
+		// The new owner no longer needs a share of their own document
+		if err := tx.Where("document_id = ? AND shared_with_user_id = ?", document.ID, targetUserID).
+			Delete(&models.UserShare{}).Error; err != nil {
+			return fmt.Errorf("failed to remove shares with new owner: %w", err)
+		}
+		if err := tx.Model(&models.UserShare{}).
+			Where("document_id = ? AND owner_id = ?", document.ID, sourceUserID).
+			Update("owner_id", targetUserID).Error; err != nil {
+			return fmt.Errorf("failed to reassign user shares: %w", err)
+		}
+		if err := tx.Model(&models.SharedLink{}).
+			Where("document_id = ? AND owner_id = ?", document.ID, sourceUserID).
+			Update("owner_id", targetUserID).Error; err != nil {
+			return fmt.Errorf("failed to reassign shared links: %w", err)
+		}
+		if err := tx.Model(&models.ShareInvitation{}).
+			Where("document_id = ? AND owner_id = ?", document.ID, sourceUserID).
+			Update("owner_id", targetUserID).Error; err != nil {
+			return fmt.Errorf("failed to reassign invitations: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The document leaves the source user's results and joins the target user's
+	s.searchCache.Invalidate(sourceUserID)
+	s.searchCache.Invalidate(targetUserID)
+
+	// Old objects are only removed once the database points at the copies
+	if storagePath != document.StoragePath {
+		if err := s.minioService.DeleteFile(ctx, document.StoragePath); err != nil {
+			logrus.Warnf("[TRANSFER] Failed to delete old object %s: %v", document.StoragePath, err)
+		}
+	}
+	if thumbnailPath != document.ThumbnailPath {
+		if err := s.minioService.DeleteFile(ctx, document.ThumbnailPath); err != nil {
+			logrus.Warnf("[TRANSFER] Failed to delete old thumbnail %s: %v", document.ThumbnailPath, err)
+		}
+		// Variants are not moved, they are rendered again on request
+		for _, variant := range thumbnailVariantPaths(document.ThumbnailPath) {
+			if err := s.minioService.DeleteFile(ctx, variant); err != nil {
+				logrus.Warnf("[TRANSFER] Failed to delete old thumbnail variant %s: %v", variant, err)
+			}
+		}
+	}
+	if previewPath != document.PreviewPath {
+		if err := s.minioService.DeleteFile(ctx, document.PreviewPath); err != nil {
+			logrus.Warnf("[TRANSFER] Failed to delete old preview %s: %v", document.PreviewPath, err)
+		}
+	}
+	for path := range movedRevisionPaths {
+		if err := s.minioService.DeleteFile(ctx, path); err != nil {
+			logrus.Warnf("[TRANSFER] Failed to delete old revision file %s: %v", path, err)
+		}
+	}
+
+	return nil
+}
+
+// Replaces the owner segment of an object path, paths outside the user prefix are kept as is
+func rekeyUserPath(path string, sourceUserID, targetUserID uuid.UUID) string {
+	if path == "" {
+		return path
+	}
+	return strings.Replace(path, "users/"+sourceUserID.String()+"/", "users/"+targetUserID.String()+"/", 1)
+}
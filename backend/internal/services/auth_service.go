@@ -4,10 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"mime/multipart"
-	"net/http"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/config"
@@ -19,33 +18,44 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// Uploader defines the interface for file storage operations.
-// This decouples AuthService from a specific implementation like Minio.
-type Uploader interface {
-	UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string) error
-	GetFileUrl(bucketName, objectName string) string
-	DeleteFile(ctx context.Context, objectName string) error
-	GeneratePresignedURL(ctx context.Context, objectName string, expiry time.Duration) (string, error)
-}
+const (
+	// Minimum time between two verification emails to the same user
+	verificationResendCooldown = time.Minute
+	verificationEmailTimeout   = 30 * time.Second
+
+	// Minimum time between two password reset emails to the same user
+	passwordResetCooldown = time.Minute
+)
 
 type AuthService struct {
-	db       *gorm.DB
-	config   *config.Config
-	redis    *redis.Client
-	uploader Uploader
-	logger   *logrus.Entry
+	db           *gorm.DB
+	config       *config.Config
+	redis        *redis.Client
+	uploader     Uploader
+	emailService EmailService // nil when email is not configured
+	signer       *tokenSigner
+	logger       *logrus.Entry
 }
 
-func NewAuthService(db *gorm.DB, cfg *config.Config, redisClient *redis.Client, uploader Uploader) *AuthService {
-	return &AuthService{
-		db:       db,
-		config:   cfg,
-		redis:    redisClient,
-		uploader: uploader,
-		logger:   logrus.WithField("service", "auth"),
+// Fails when the RS256 keys can't be loaded
+func NewAuthService(db *gorm.DB, cfg *config.Config, redisClient *redis.Client, uploader Uploader, emailService EmailService) (*AuthService, error) {
+	signer, err := newTokenSigner(cfg.JWT)
+	if err != nil {
+		return nil, err
 	}
+
+	return &AuthService{
+		db:           db,
+		config:       cfg,
+		redis:        redisClient,
+		uploader:     uploader,
+		emailService: emailService,
+		signer:       signer,
+		logger:       logrus.WithField("service", "auth"),
+	}, nil
 }
 
 // Request/Response types
@@ -57,6 +67,19 @@ type RegisterRequest struct {
 	PasswordConfirm string `json:"passwordConfirm" binding:"required,eqfield=Password" example:"SecurePass123!"`
 }
 
+type SendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email" example:"user@example.com"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"newPassword" binding:"required,min=8,password_strength" example:"SecurePass123!"`
+}
+
 type LoginRequest struct {
 	Email    string `json:"email,omitempty"`
 	Username string `json:"username,omitempty"`
@@ -68,6 +91,17 @@ type RefreshTokenRequest struct {
 	RefreshToken string `json:"refreshToken" binding:"required"`
 }
 
+// Session is a login and the refresh tokens it was rotated through, identified by their family
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	StartedAt    time.Time `json:"startedAt"`
+	LastActiveAt time.Time `json:"lastActiveAt"` // When the current refresh token was issued
+	ExpiresAt    time.Time `json:"expiresAt"`
+	UserAgent    string    `json:"userAgent"`
+	IPAddress    string    `json:"ipAddress"`
+	Current      bool      `json:"current"` // The session of the request
+}
+
 type ChangePasswordRequest struct {
 	OldPassword string `json:"oldPassword" binding:"required"`
 	NewPassword string `json:"newPassword" binding:"required,min=8"`
@@ -143,10 +177,124 @@ func (s *AuthService) Register(ctx context.Context, req *RegisterRequest) (*mode
 	user.Password = "" // Don't return password
 
 	s.logger.Infof("User registered: %s", user.Email)
+
+	// Send the first verification link right away, the user can request another one
+	if s.emailService != nil {
+		go func(email string) {
+			ctx, cancel := context.WithTimeout(context.Background(), verificationEmailTimeout)
+			defer cancel()
+			if err := s.SendVerificationEmail(ctx, email); err != nil {
+				s.logger.WithError(err).Warnf("Failed to send verification email to %s", email)
+			}
+		}(user.Email)
+	}
+
 	return user, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*models.User, *models.TokenPair, error) {
+// Issues a new verification token and emails its link. Unknown and already verified addresses
+// are silently ignored and the email goes out in the background, so neither the response nor
+// its timing tells which emails have accounts.
+func (s *AuthService) SendVerificationEmail(ctx context.Context, email string) error {
+	if s.emailService == nil {
+		return fmt.Errorf("email delivery is not configured")
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil || user.EmailVerified {
+		return nil
+	}
+
+	// Resending is limited per user on top of the per client rate limit of the route
+	var latest models.EmailVerificationToken
+	if err := s.db.WithContext(ctx).Where("user_id = ?", user.ID).Order("created_at DESC").First(&latest).Error; err == nil &&
+		time.Since(latest.CreatedAt) < verificationResendCooldown {
+		s.logger.Infof("Verification email to %s skipped, last one was sent %s ago", user.Email, time.Since(latest.CreatedAt).Round(time.Second))
+		return nil
+	}
+
+	// Only the hash is stored, the plain token exists only in the email
+	rawToken := utils.GenerateSecureToken(32)
+	token := &models.EmailVerificationToken{
+		UserID:    user.ID,
+		Token:     utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(s.config.Email.VerificationTokenTTL),
+	}
+
+	// Only the newest link works
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.EmailVerificationToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create verification token: %w", err)
+	}
+
+	verificationEmail := VerificationEmail{
+		To:        user.Email,
+		Name:      user.Name,
+		Link:      fmt.Sprintf("%s/api/v1/auth/verify/%s", strings.TrimRight(s.config.Server.BaseURL, "/"), rawToken),
+		ExpiresAt: token.ExpiresAt,
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), verificationEmailTimeout)
+		defer cancel()
+		if err := s.emailService.SendVerificationEmail(ctx, verificationEmail); err != nil {
+			s.logger.WithError(err).Warnf("Failed to send verification email to %s", verificationEmail.To)
+			return
+		}
+		s.logger.Infof("Verification email sent to %s", verificationEmail.To)
+	}()
+
+	return nil
+}
+
+// Consumes a verification token, marks the email verified and activates pending accounts
+func (s *AuthService) VerifyEmail(ctx context.Context, tokenString string) error {
+	var token models.EmailVerificationToken
+	if err := s.db.WithContext(ctx).Where("token = ?", utils.HashToken(tokenString)).First(&token).Error; err != nil {
+		return fmt.Errorf("invalid verification token")
+	}
+	if token.IsUsed() {
+		return fmt.Errorf("verification token already used")
+	}
+	if token.IsExpired() {
+		return fmt.Errorf("verification token expired")
+	}
+
+	now := time.Now()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Claim the token, a concurrent request using the same link loses here
+		result := tx.Model(&token).Where("used_at IS NULL").Update("used_at", &now)
+		if result.Error != nil {
+			return fmt.Errorf("failed to verify email: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("verification token already used")
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", token.UserID).Updates(map[string]interface{}{
+			"email_verified":    true,
+			"email_verified_at": &now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to verify email: %w", err)
+		}
+
+		// Suspended accounts stay suspended
+		if err := tx.Model(&models.User{}).
+			Where("id = ? AND status = ?", token.UserID, models.StatusPending).
+			Update("status", models.StatusActive).Error; err != nil {
+			return fmt.Errorf("failed to activate account: %w", err)
+		}
+
+		s.logger.Infof("Email verified for user %s", token.UserID)
+		return nil
+	})
+}
+
+func (s *AuthService) Login(ctx context.Context, req *LoginRequest, ipAddress, userAgent string) (*models.User, *models.TokenPair, error) {
 	var user models.User
 
 	// Define a standard error message for all login failures
@@ -172,8 +320,8 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*models.Use
 	if userFound {
 		// Check actual password for existing user
 		if !utils.CheckPasswordHash(req.Password, user.Password) {
-			// Increment failed attempts (but don't reveal this to user)
-			s.db.Model(&user).Update("failed_attempts", gorm.Expr("failed_attempts + 1"))
+			// Count the failure and lock the account if needed (but don't reveal this to user)
+			s.recordFailedLogin(user.ID)
 			// Don't reveal if password is wrong - return standard error
 			return nil, nil, errors.New(standardError)
 		}
@@ -186,7 +334,7 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*models.Use
 	}
 
 	// Additional checks for valid user
-	if user.IsLocked() {
+	if user.IsLocked() || user.Status == models.StatusSuspended {
 		// For security, return standard error instead of revealing account status
 		return nil, nil, errors.New(standardError)
 	}
@@ -217,7 +365,7 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*models.Use
 	}
 
 	// Generate tokens
-	tokens, err := s.generateTokenPair(&user)
+	tokens, err := s.generateTokenPair(&user, uuid.New(), ipAddress, userAgent)
 	if err != nil {
 		// This is a server error, log it but return standard error to user
 		s.logger.WithError(err).Error("Failed to generate tokens during login")
@@ -229,6 +377,7 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*models.Use
 	s.db.Model(&user).Updates(map[string]interface{}{
 		"last_login":      &now,
 		"failed_attempts": 0,
+		"locked_until":    nil,
 	})
 
 	user.Password = "" // Don't return password
@@ -236,10 +385,49 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*models.Use
 	return &user, tokens, nil
 }
 
-func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*models.TokenPair, error) {
-	// Find refresh token in database
+// Updates the failed login count under a row lock so concurrent attempts are all counted
+func (s *AuthService) recordFailedLogin(userID uuid.UUID) {
+	lockout := s.config.Lockout
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "email", "failed_attempts", "locked_until").
+			First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		if user.RegisterFailedLogin(time.Now(), lockout.MaxFailedAttempts, lockout.Duration) {
+			s.logger.Warnf("Account locked until %s after %d failed logins: %s",
+				user.LockedUntil.Format(time.RFC3339), user.FailedAttempts, user.Email)
+		}
+
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"failed_attempts": user.FailedAttempts,
+			"locked_until":    user.LockedUntil,
+		}).Error
+	})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to record failed login")
+	}
+}
+
+// Refresh tokens are single use. Every exchange retires the presented token and issues the
+// next one in the same family. A retired token coming back means it was copied, so the whole
+// family is revoked and the user has to log in again on every device holding it.
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken, ipAddress, userAgent string) (*models.TokenPair, error) {
+	// Find refresh token in database, retired tokens included
 	var token models.RefreshToken
-	if err := s.db.Preload("User.Role.Permissions").Where("token = ?", refreshToken).First(&token).Error; err != nil {
+	if err := s.db.Unscoped().Preload("User.Role.Permissions").Where("token = ?", refreshToken).First(&token).Error; err != nil {
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+
+	// Logged out tokens are simply invalid, rotated ones still within their lifetime are a replay
+	if token.DeletedAt.Valid {
+		if token.IsRotated() && !token.IsExpired() {
+			s.revokeTokenFamily(&token)
+			return nil, fmt.Errorf("refresh token reused")
+		}
 		return nil, fmt.Errorf("invalid refresh token")
 	}
 
@@ -249,18 +437,57 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*m
 		return nil, fmt.Errorf("refresh token expired")
 	}
 
+	// Tokens issued before families existed start their own
+	if token.FamilyID == uuid.Nil {
+		token.FamilyID = token.ID
+	}
+
+	// Retire the old token first, only one of two concurrent exchanges can win
+	now := time.Now()
+	result := s.db.Model(&token).Where("rotated_at IS NULL").Updates(map[string]interface{}{
+		"family_id":  token.FamilyID,
+		"rotated_at": &now,
+		"deleted_at": &now,
+	})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		s.revokeTokenFamily(&token)
+		return nil, fmt.Errorf("refresh token reused")
+	}
+
 	// Generate new token pair
-	newTokens, err := s.generateTokenPair(&token.User)
+	newTokens, err := s.generateTokenPair(&token.User, token.FamilyID, ipAddress, userAgent)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate new tokens: %w", err)
 	}
 
-	// Delete old refresh token
-	s.db.Delete(&token)
-
 	return newTokens, nil
 }
 
+// Revokes every live token of the family a replayed token belongs to
+func (s *AuthService) revokeTokenFamily(token *models.RefreshToken) {
+	familyID := token.FamilyID
+	if familyID == uuid.Nil {
+		familyID = token.ID
+	}
+
+	result := s.db.Where("family_id = ?", familyID).Delete(&models.RefreshToken{})
+	if result.Error != nil {
+		s.logger.WithError(result.Error).Error("Failed to revoke refresh token family")
+		return
+	}
+
+	s.logger.WithFields(logrus.Fields{
+		"event":    "refresh_token_reuse",
+		"user_id":  token.UserID,
+		"family":   familyID,
+		"token_id": token.ID,
+		"revoked":  result.RowsAffected,
+	}).Warn("Rotated refresh token was presented again, revoked its token family")
+}
+
 func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
 	// Add token to blacklist if Redis is available
 	if s.redis != nil {
@@ -281,6 +508,115 @@ func (s *AuthService) Logout(ctx context.Context, refreshToken string) error {
 	return nil
 }
 
+// Lists the sessions of a user that can still be refreshed, most recently active first.
+// currentRefreshToken marks the session of the request, it may be empty.
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID, currentRefreshToken string) ([]Session, error) {
+	var tokens []models.RefreshToken
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+
+	// A session started with the first token of its family, retired ones included
+	familyIDs := make([]uuid.UUID, 0, len(tokens))
+	for _, token := range tokens {
+		if token.FamilyID != uuid.Nil {
+			familyIDs = append(familyIDs, token.FamilyID)
+		}
+	}
+	var starts []struct {
+		FamilyID  uuid.UUID
+		StartedAt time.Time
+	}
+	if len(familyIDs) > 0 {
+		if err := s.db.WithContext(ctx).Unscoped().Model(&models.RefreshToken{}).
+			Select("family_id, MIN(created_at) AS started_at").
+			Where("user_id = ? AND family_id IN ?", userID, familyIDs).
+			Group("family_id").
+			Scan(&starts).Error; err != nil {
+			return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+		}
+	}
+	startedAt := make(map[uuid.UUID]time.Time, len(starts))
+	for _, start := range starts {
+		startedAt[start.FamilyID] = start.StartedAt
+	}
+
+	sessions := make([]Session, 0, len(tokens))
+	for _, token := range tokens {
+		session := Session{
+			ID:           sessionID(&token),
+			StartedAt:    token.CreatedAt,
+			LastActiveAt: token.CreatedAt,
+			ExpiresAt:    token.ExpiresAt,
+			UserAgent:    token.UserAgent,
+			IPAddress:    token.IPAddress,
+			Current:      currentRefreshToken != "" && token.Token == currentRefreshToken,
+		}
+		if started, ok := startedAt[token.FamilyID]; ok {
+			session.StartedAt = started
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+// Revokes the refresh tokens of one session. Access tokens already issued to it stay valid
+// until they expire.
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	// Tokens issued before families existed are their own session
+	result := s.db.WithContext(ctx).
+		Where("user_id = ? AND (family_id = ? OR (id = ? AND family_id = ?))", userID, sessionID, sessionID, uuid.Nil).
+		Delete(&models.RefreshToken{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke session: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	s.logger.WithFields(logrus.Fields{"user_id": userID, "session_id": sessionID}).Info("Session revoked")
+	return nil
+}
+
+// Revokes every refresh token of the user and, when Redis is available, every access token
+// issued so far. accessToken, the one the request was made with, is blacklisted as well.
+func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID, accessToken string) error {
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RefreshToken{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", result.Error)
+	}
+
+	if s.redis != nil {
+		// Access tokens can't be listed, ValidateToken rejects those issued before this instant
+		if err := s.redis.Set(ctx, tokensRevokedKey(userID), time.Now().Unix(), s.config.JWT.ExpiresIn).Err(); err != nil {
+			s.logger.WithError(err).Error("Failed to revoke access tokens")
+		}
+		if accessToken != "" {
+			if err := s.BlacklistToken(ctx, accessToken, s.config.JWT.ExpiresIn); err != nil {
+				s.logger.WithError(err).Error("Failed to blacklist access token")
+			}
+		}
+	}
+
+	s.logger.WithFields(logrus.Fields{"user_id": userID, "revoked": result.RowsAffected}).Info("User logged out everywhere")
+	return nil
+}
+
+func sessionID(token *models.RefreshToken) uuid.UUID {
+	if token.FamilyID == uuid.Nil {
+		return token.ID
+	}
+	return token.FamilyID
+}
+
+func tokensRevokedKey(userID uuid.UUID) string {
+	return "tokens_revoked:" + userID.String()
+}
+
 func (s *AuthService) GetProfile(ctx context.Context, userID uuid.UUID) (*models.User, error) {
 	var user models.User
 	if err := s.db.Preload("Role.Permissions").Where("id = ?", userID).First(&user).Error; err != nil {
@@ -359,6 +695,8 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, req *
 		updates["email"] = "" // Clear plaintext
 	}
 
+	s.profileBlindIndexes(req, updates)
+
 	if len(updates) > 0 {
 		if err := s.db.Model(&user).Updates(updates).Error; err != nil {
 			return nil, fmt.Errorf("failed to update profile: %w", err)
@@ -374,6 +712,37 @@ func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, req *
 	return &user, nil
 }
 
+// Keeps the lookup indexes of alternate email and phone in step with the profile. The index
+// is computed from the plaintext in the request, which is only hashed and not stored when the
+// encrypted value is sent along with it. An encrypted value without plaintext clears the index
+// since the server cannot tell what changed.
+//
+// Threat model: the index is an HMAC under BLIND_INDEX_KEY, so a database dump alone does not
+// reveal the values. Whoever holds the key can still confirm a guessed value, and users sharing
+// a value share an index. Clients that want the fields hidden from the server entirely send only
+// the ciphertext and are then not findable by the admin lookup.
+func (s *AuthService) profileBlindIndexes(req *UpdateProfileRequest, updates map[string]interface{}) {
+	if req.AlternateEmail != nil {
+		updates["alt_email_index"] = s.blindIndex(utils.NormalizeEmail(*req.AlternateEmail))
+	} else if req.EncryptedAltEmail != nil && req.EncryptedAltEmailIV != nil {
+		updates["alt_email_index"] = ""
+	}
+
+	if req.Phone != nil {
+		updates["phone_index"] = s.blindIndex(utils.NormalizePhone(*req.Phone))
+	} else if req.EncryptedPhone != nil && req.EncryptedPhoneIV != nil {
+		updates["phone_index"] = ""
+	}
+}
+
+// Returns the blind index of a normalized value, empty when the value or the key is empty
+func (s *AuthService) blindIndex(normalized string) string {
+	if normalized == "" || s.config.BlindIndex.Key == "" {
+		return ""
+	}
+	return utils.BlindIndex(s.config.BlindIndex.Key, normalized)
+}
+
 func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req *ChangePasswordRequest) error {
 	var user models.User
 	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
@@ -400,6 +769,105 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req
 	return nil
 }
 
+// Emails a password reset link. Unknown addresses are ignored and the email goes out in the
+// background, so neither the response nor its timing tells whether the address has an account.
+func (s *AuthService) ForgotPassword(ctx context.Context, email string) error {
+	if s.emailService == nil {
+		return fmt.Errorf("email delivery is not configured")
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil
+	}
+
+	// Limited per address on top of the per client rate limit of the route
+	var latest models.PasswordResetToken
+	if err := s.db.WithContext(ctx).Where("user_id = ?", user.ID).Order("created_at DESC").First(&latest).Error; err == nil &&
+		time.Since(latest.CreatedAt) < passwordResetCooldown {
+		s.logger.Infof("Password reset email to %s skipped, last one was sent %s ago", user.Email, time.Since(latest.CreatedAt).Round(time.Second))
+		return nil
+	}
+
+	// Only the hash is stored, the plain token exists only in the email
+	rawToken := utils.GenerateSecureToken(32)
+	token := &models.PasswordResetToken{
+		UserID:    user.ID,
+		Token:     utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(s.config.Email.PasswordResetTokenTTL),
+	}
+
+	// Only the newest link works
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	resetEmail := PasswordResetEmail{
+		To:        user.Email,
+		Name:      user.Name,
+		Link:      fmt.Sprintf("%s/auth/reset-password?token=%s", strings.TrimRight(s.config.Email.AppURL, "/"), rawToken),
+		ExpiresAt: token.ExpiresAt,
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), verificationEmailTimeout)
+		defer cancel()
+		if err := s.emailService.SendPasswordResetEmail(ctx, resetEmail); err != nil {
+			s.logger.WithError(err).Warnf("Failed to send password reset email to %s", resetEmail.To)
+			return
+		}
+		s.logger.Infof("Password reset email sent to %s", resetEmail.To)
+	}()
+
+	return nil
+}
+
+// Sets a new password with a reset token. The token is consumed and every session of the
+// user is signed out, a failed lockout is cleared as well.
+func (s *AuthService) ResetPassword(ctx context.Context, req *ResetPasswordRequest) error {
+	var token models.PasswordResetToken
+	if err := s.db.WithContext(ctx).Where("token = ?", utils.HashToken(req.Token)).First(&token).Error; err != nil ||
+		token.IsExpired() || token.IsUsed() {
+		return fmt.Errorf("invalid or expired reset token")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Consume the token, a concurrent request with the same token loses here
+		result := tx.Unscoped().Where("id = ?", token.ID).Delete(&models.PasswordResetToken{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to reset password: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("invalid or expired reset token")
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", token.UserID).Updates(map[string]interface{}{
+			"password":        hashedPassword,
+			"failed_attempts": 0,
+			"locked_until":    nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to reset password: %w", err)
+		}
+
+		if err := tx.Where("user_id = ?", token.UserID).Delete(&models.RefreshToken{}).Error; err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+
+		s.logger.Infof("Password reset for user %s", token.UserID)
+		return nil
+	})
+}
+
 func (s *AuthService) DeleteAvatar(ctx context.Context, userID uuid.UUID) error {
 	var user models.User
 	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
@@ -426,12 +894,7 @@ func (s *AuthService) ValidateToken(tokenString string) (*models.TokenClaims, er
 		}
 	}
 
-	token, err := jwt.ParseWithClaims(tokenString, jwt.MapClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.JWT.Secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, jwt.MapClaims{}, s.signer.keyFunc)
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -463,34 +926,60 @@ func (s *AuthService) ValidateToken(tokenString string) (*models.TokenClaims, er
 		username, _ := mapClaims["username"].(string)
 		roleName, _ := mapClaims["role"].(string)
 
+		// Tokens issued before the user logged out everywhere
+		if s.redis != nil {
+			revokedAt, err := s.redis.Get(context.Background(), tokensRevokedKey(userID)).Int64()
+			if err == nil {
+				if issuedAt, ok := mapClaims["iat"].(float64); ok && int64(issuedAt) < revokedAt {
+					return nil, fmt.Errorf("token is revoked")
+				}
+			}
+		}
+
+		var permissions []string
+		if rawPermissions, ok := mapClaims["permissions"].([]interface{}); ok {
+			for _, raw := range rawPermissions {
+				if permission, ok := raw.(string); ok {
+					permissions = append(permissions, permission)
+				}
+			}
+		}
+
 		return &models.TokenClaims{
-			UserID:   userID,
-			Email:    email,
-			Username: username,
-			RoleID:   roleID,
-			RoleName: roleName,
+			UserID:      userID,
+			Email:       email,
+			Username:    username,
+			RoleID:      roleID,
+			RoleName:    roleName,
+			Permissions: permissions,
 		}, nil
 	}
 
 	return nil, fmt.Errorf("invalid token claims")
 }
 
+// Returns the keys access tokens can be verified with, false with HS256 which has none to publish
+func (s *AuthService) JWKS() (*JWKS, bool) {
+	jwks := s.signer.jwks()
+	return jwks, jwks != nil
+}
+
 // Helper methods
-func (s *AuthService) generateTokenPair(user *models.User) (*models.TokenPair, error) {
+func (s *AuthService) generateTokenPair(user *models.User, familyID uuid.UUID, ipAddress, userAgent string) (*models.TokenPair, error) {
 	// Access token claims
 	claims := jwt.MapClaims{
-		"sub":      user.ID.String(),
-		"email":    user.Email,
-		"username": user.Username,
-		"roleID":   user.RoleID.String(),
-		"role":     user.Role.Name,
-		"exp":      time.Now().Add(s.config.JWT.ExpiresIn).Unix(),
-		"iat":      time.Now().Unix(),
+		"sub":         user.ID.String(),
+		"email":       user.Email,
+		"username":    user.Username,
+		"roleID":      user.RoleID.String(),
+		"role":        user.Role.Name,
+		"permissions": user.Role.PermissionNames(),
+		"exp":         time.Now().Add(s.config.JWT.ExpiresIn).Unix(),
+		"iat":         time.Now().Unix(),
 	}
 
 	// Create access token
-	accessToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	accessTokenString, err := accessToken.SignedString([]byte(s.config.JWT.Secret))
+	accessTokenString, err := s.signer.sign(claims)
 	if err != nil {
 		return nil, err
 	}
@@ -502,7 +991,10 @@ func (s *AuthService) generateTokenPair(user *models.User) (*models.TokenPair, e
 	refreshToken := &models.RefreshToken{
 		UserID:    user.ID,
 		Token:     refreshTokenString,
+		FamilyID:  familyID,
 		ExpiresAt: time.Now().Add(s.config.JWT.RefreshExpiresIn),
+		UserAgent: utils.TruncateUTF8(userAgent, 512),
+		IPAddress: ipAddress,
 	}
 
 	if err := s.db.Create(refreshToken).Error; err != nil {
@@ -521,12 +1013,8 @@ func (s *AuthService) generateTokenPair(user *models.User) (*models.TokenPair, e
 func (s *AuthService) SetAuthCookies(c *gin.Context, tokens *models.TokenPair) {
 	s.logger.Infof("Setting auth cookies - Access token length: %d, Refresh token length: %d", len(tokens.AccessToken), len(tokens.RefreshToken))
 
-	// For development, use None to allow cross-origin cookies
-	if s.config.Environment != "production" {
-		c.SetSameSite(http.SameSiteNoneMode)
-	} else {
-		c.SetSameSite(http.SameSiteStrictMode)
-	}
+	cookies := s.config.Cookies
+	c.SetSameSite(cookies.SameSiteMode())
 
 	// Set access token in HTTP-only cookie
 	c.SetCookie(
@@ -534,28 +1022,32 @@ func (s *AuthService) SetAuthCookies(c *gin.Context, tokens *models.TokenPair) {
 		tokens.AccessToken,
 		int(tokens.ExpiresIn),
 		"/",
-		"",    // Empty domain - will use current origin
-		false, // secure = false for development (required for SameSite=None in dev)
-		true,  // httpOnly
+		cookies.Domain,
+		cookies.Secure,
+		true, // httpOnly
 	)
 
 	// Set refresh token in HTTP-only cookie
 	c.SetCookie(
 		"refresh_token",
 		tokens.RefreshToken,
-		7*24*3600, // 7 days
+		int(s.config.JWT.RefreshExpiresIn.Seconds()),
 		"/",
-		"",    // Empty domain - will use current origin
-		false, // secure = false for development
-		true,  // httpOnly
+		cookies.Domain,
+		cookies.Secure,
+		true, // httpOnly
 	)
 
-	s.logger.Infof("Auth cookies set successfully with SameSite=None, Secure=false for development")
+	s.logger.Infof("Auth cookies set successfully with SameSite=%s, Secure=%t", cookies.SameSite, cookies.Secure)
 }
 
+// ClearAuthCookies expires the auth cookies, with the same attributes they were set with so
+// browsers match and drop them
 func (s *AuthService) ClearAuthCookies(c *gin.Context) {
-	c.SetCookie("access_token", "", -1, "/", "", false, true)
-	c.SetCookie("refresh_token", "", -1, "/", "", false, true)
+	cookies := s.config.Cookies
+	c.SetSameSite(cookies.SameSiteMode())
+	c.SetCookie("access_token", "", -1, "/", cookies.Domain, cookies.Secure, true)
+	c.SetCookie("refresh_token", "", -1, "/", cookies.Domain, cookies.Secure, true)
 }
 
 // BlacklistToken adds a token to the blacklist
@@ -648,10 +1140,44 @@ func (s *AuthService) GetFullNameByID(ctx context.Context, userID uuid.UUID) (st
 	return user.Name, nil
 }
 
-// GetAvatarURL returns a presigned URL for the stored avatar path.
-func (s *AuthService) GetAvatarURL(ctx context.Context, avatarPath string) (string, error) {
-	if avatarPath == "" {
-		return "", nil
+// GetAvatarURL returns a presigned URL for the uploaded avatar, or the generated avatar URL
+// for users without one.
+func (s *AuthService) GetAvatarURL(ctx context.Context, user *models.User) (string, error) {
+	if user.Avatar == "" {
+		return utils.GeneratedAvatarURL(s.config.Server.BaseURL, user.ID.String(), s.avatarStyle(), user.Name), nil
+	}
+	return s.uploader.GeneratePresignedURL(ctx, user.Avatar, 7*24*time.Hour)
+}
+
+// GetGeneratedAvatar returns the generated SVG avatar for a user and its version for use as an ETag.
+// Rendered avatars are cached in Redis, keyed by version so name or style changes are picked up.
+func (s *AuthService) GetGeneratedAvatar(ctx context.Context, userID uuid.UUID) ([]byte, string, error) {
+	var user models.User
+	if err := s.db.Select("id", "name").Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, "", fmt.Errorf("user not found")
 	}
-	return s.uploader.GeneratePresignedURL(ctx, avatarPath, 7*24*time.Hour)
+
+	style := s.avatarStyle()
+	version := utils.GeneratedAvatarVersion(style, user.Name)
+	cacheKey := fmt.Sprintf("avatar:generated:%s:%s", userID, version)
+
+	if s.redis != nil {
+		if cached, err := s.redis.Get(ctx, cacheKey).Bytes(); err == nil && len(cached) > 0 {
+			return cached, version, nil
+		}
+	}
+
+	svg := utils.GenerateAvatarSVG(style, user.ID.String(), user.Name)
+
+	if s.redis != nil {
+		if err := s.redis.Set(ctx, cacheKey, svg, s.config.Avatar.CacheTTL).Err(); err != nil {
+			s.logger.Warnf("Failed to cache generated avatar: %v", err)
+		}
+	}
+
+	return svg, version, nil
+}
+
+func (s *AuthService) avatarStyle() string {
+	return utils.NormalizeAvatarStyle(s.config.Avatar.Style)
 }
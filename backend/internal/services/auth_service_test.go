@@ -0,0 +1,99 @@
+package services_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/testutil"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Stores a live refresh token for user that starts a family of its own
+func newRefreshSession(t *testing.T, db *gorm.DB, user *models.User, token string) {
+	t.Helper()
+	if err := db.Create(&models.RefreshToken{
+		UserID:    user.ID,
+		Token:     token,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}).Error; err != nil {
+		t.Fatalf("failed to create refresh token: %v", err)
+	}
+}
+
+func TestRefreshTokenReplayRevokesFamily(t *testing.T) {
+	db := testutil.NewPostgresDB(t, &models.Permission{}, &models.Role{}, &models.User{}, &models.RefreshToken{})
+
+	cfg := &config.Config{JWT: config.JWTConfig{
+		Algorithm:        config.JWTAlgorithmHS256,
+		Secret:           "test-secret",
+		ExpiresIn:        time.Minute,
+		RefreshExpiresIn: time.Hour,
+	}}
+	authService, err := services.NewAuthService(db, cfg, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("NewAuthService failed: %v", err)
+	}
+
+	suffix := uuid.NewString()[:8]
+	role := &models.Role{ID: uuid.New(), Name: "replay-" + suffix, DisplayName: "Replay"}
+	if err := db.Create(role).Error; err != nil {
+		t.Fatalf("failed to create role: %v", err)
+	}
+	user := &models.User{
+		Email:    "replay-" + suffix + "@example.com",
+		Username: "replay" + suffix,
+		Name:     "Replay",
+		Password: strings.Repeat("x", 60), // long enough to be taken as already hashed
+		Status:   models.StatusActive,
+		RoleID:   role.ID,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	ctx := context.Background()
+	newRefreshSession(t, db, user, "first-"+suffix)
+	newRefreshSession(t, db, user, "other-device-"+suffix)
+
+	// Two regular rotations
+	second, err := authService.RefreshToken(ctx, "first-"+suffix, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatalf("first rotation failed: %v", err)
+	}
+	third, err := authService.RefreshToken(ctx, second.RefreshToken, "127.0.0.1", "test")
+	if err != nil {
+		t.Fatalf("second rotation failed: %v", err)
+	}
+
+	// Presenting the first token again is a replay
+	if _, err := authService.RefreshToken(ctx, "first-"+suffix, "127.0.0.1", "test"); err == nil || err.Error() != "refresh token reused" {
+		t.Fatalf("replayed token: err = %v, want refresh token reused", err)
+	}
+
+	// The latest token of the family is revoked along with it
+	if _, err := authService.RefreshToken(ctx, third.RefreshToken, "127.0.0.1", "test"); err == nil {
+		t.Fatal("token issued after the replayed one still works")
+	}
+	var first models.RefreshToken
+	if err := db.Unscoped().Where("token = ?", "first-"+suffix).First(&first).Error; err != nil {
+		t.Fatalf("failed to load replayed token: %v", err)
+	}
+	var live int64
+	if err := db.Model(&models.RefreshToken{}).Where("family_id = ?", first.FamilyID).Count(&live).Error; err != nil {
+		t.Fatalf("failed to count family tokens: %v", err)
+	}
+	if live != 0 {
+		t.Fatalf("%d tokens of the replayed family are still live", live)
+	}
+
+	// Sessions on other devices are separate families and keep working
+	if _, err := authService.RefreshToken(ctx, "other-device-"+suffix, "127.0.0.1", "test"); err != nil {
+		t.Fatalf("token of another family was revoked: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Bytes sent per INSTREAM chunk, well below clamd's default StreamMaxLength
+	clamAVChunkSize = 64 * 1024
+	// Startup check and dial timeout, scans themselves use the configured timeout
+	clamAVDialTimeout = 5 * time.Second
+)
+
+// Result of scanning one file
+type ScanResult struct {
+	Infected  bool
+	Signature string // Name of the detected malware, empty when clean
+}
+
+// Scans files with a clamd daemon over TCP, streaming them with the INSTREAM command so the
+// daemon needs no access to the backend's files
+type ClamAV struct {
+	address string
+	timeout time.Duration
+	version string // empty when the daemon did not report one
+}
+
+// Returns nil, scanning disabled, when no address is configured. A daemon that is down at
+// startup is only logged, scans fail and are retried by the worker until it comes up.
+func NewClamAV(cfg config.ScanConfig) *ClamAV {
+	if cfg.ClamAVAddress == "" {
+		return nil
+	}
+
+	c := &ClamAV{address: cfg.ClamAVAddress, timeout: cfg.ClamAVTimeout}
+
+	ctx, cancel := context.WithTimeout(context.Background(), clamAVDialTimeout)
+	defer cancel()
+	version, err := c.command(ctx, "VERSION")
+	if err != nil {
+		logrus.Warnf("ClamAV at %s is not reachable, uploads stay unscanned until it is: %v", c.address, err)
+		return c
+	}
+
+	c.version = version
+	logrus.Infof("ClamAV detected at %s (%s)", c.address, c.version)
+	return c
+}
+
+// Returns the version the daemon reported at startup
+func (c *ClamAV) Version() string {
+	return c.version
+}
+
+// Checks that the daemon answers
+func (c *ClamAV) Ping(ctx context.Context) error {
+	reply, err := c.command(ctx, "PING")
+	if err != nil {
+		return err
+	}
+	if reply != "PONG" {
+		return fmt.Errorf("unexpected ClamAV ping reply %q", reply)
+	}
+	return nil
+}
+
+// Scans a local file. Errors mean the file could not be scanned, not that it is infected.
+func (c *ClamAV) Scan(ctx context.Context, path string) (*ScanResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file for scanning: %w", err)
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to start ClamAV scan: %w", err)
+	}
+
+	// Each chunk is prefixed with its length, a zero length ends the stream
+	buf := make([]byte, 4+clamAVChunkSize)
+	for {
+		n, readErr := file.Read(buf[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(buf[:4], uint32(n))
+			if _, err := conn.Write(buf[:4+n]); err != nil {
+				return nil, fmt.Errorf("failed to stream file to ClamAV: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read file for scanning: %w", readErr)
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return nil, fmt.Errorf("failed to finish ClamAV scan: %w", err)
+	}
+
+	reply, err := readClamAVReply(conn)
+	if err != nil {
+		return nil, err
+	}
+	return parseClamAVScanReply(reply)
+}
+
+// Sends a command without a payload and returns the reply
+func (c *ClamAV) command(ctx context.Context, command string) (string, error) {
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("z" + command + "\x00")); err != nil {
+		return "", fmt.Errorf("failed to send ClamAV %s: %w", command, err)
+	}
+	return readClamAVReply(conn)
+}
+
+func (c *ClamAV) dial(ctx context.Context) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: clamAVDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ClamAV at %s: %w", c.address, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	return conn, nil
+}
+
+// Reads one null-terminated reply, as sent for z-prefixed commands
+func readClamAVReply(conn net.Conn) (string, error) {
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !(err == io.EOF && reply != "") {
+		return "", fmt.Errorf("failed to read ClamAV reply: %w", err)
+	}
+	return strings.TrimSpace(strings.TrimRight(reply, "\x00")), nil
+}
+
+// Parses "stream: OK", "stream: <signature> FOUND" or "<message> ERROR"
+func parseClamAVScanReply(reply string) (*ScanResult, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return &ScanResult{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return &ScanResult{Infected: true, Signature: strings.TrimSuffix(result, " FOUND")}, nil
+	case strings.HasSuffix(result, " ERROR"):
+		return nil, fmt.Errorf("ClamAV failed to scan file: %s", strings.TrimSuffix(result, " ERROR"))
+	default:
+		return nil, fmt.Errorf("unexpected ClamAV reply %q", reply)
+	}
+}
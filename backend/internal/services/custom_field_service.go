@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	customFieldTextMaxLength = 1000
+	customFieldDateLayout    = "2006-01-02"
+)
+
+// Field names are used as JSON keys and query parameters
+var customFieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+type CustomFieldService struct {
+	db     *gorm.DB
+	logger *logrus.Entry
+}
+
+func NewCustomFieldService(db *gorm.DB) *CustomFieldService {
+	return &CustomFieldService{
+		db:     db,
+		logger: logrus.WithField("service", "custom_field"),
+	}
+}
+
+// Request types
+type CreateCustomFieldRequest struct {
+	Name        string                 `json:"name" binding:"required"`
+	Label       string                 `json:"label" binding:"required,min=1,max=100"`
+	Description string                 `json:"description" binding:"max=500"`
+	Type        models.CustomFieldType `json:"type" binding:"required"`
+	Required    bool                   `json:"required"`
+	SortOrder   int                    `json:"sortOrder"`
+}
+
+// Name and type are fixed once created, changing them would orphan stored values
+type UpdateCustomFieldRequest struct {
+	Label       *string `json:"label,omitempty" binding:"omitempty,min=1,max=100"`
+	Description *string `json:"description,omitempty" binding:"omitempty,max=500"`
+	Required    *bool   `json:"required,omitempty"`
+	SortOrder   *int    `json:"sortOrder,omitempty"`
+}
+
+// CustomMetadataError lists the custom fields that failed validation
+type CustomMetadataError struct {
+	Fields map[string]string
+}
+
+func (e *CustomMetadataError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("%s: %s", name, e.Fields[name]))
+	}
+	return "invalid custom metadata: " + strings.Join(messages, "; ")
+}
+
+// Returns the field schema in display order
+func (s *CustomFieldService) ListFields(ctx context.Context) ([]models.CustomFieldDefinition, error) {
+	var fields []models.CustomFieldDefinition
+	if err := s.db.WithContext(ctx).Order("sort_order ASC, name ASC").Find(&fields).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch custom fields: %w", err)
+	}
+	return fields, nil
+}
+
+func (s *CustomFieldService) CreateField(ctx context.Context, req *CreateCustomFieldRequest) (*models.CustomFieldDefinition, error) {
+	name := strings.TrimSpace(req.Name)
+	if !customFieldNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid field name: use lowercase letters, digits and underscores, starting with a letter")
+	}
+	if !req.Type.IsValid() {
+		return nil, fmt.Errorf("invalid field type: %s", req.Type)
+	}
+
+	var existing models.CustomFieldDefinition
+	if err := s.db.WithContext(ctx).Where("name = ?", name).First(&existing).Error; err == nil {
+		return nil, fmt.Errorf("custom field name already exists")
+	}
+
+	field := &models.CustomFieldDefinition{
+		Name:        name,
+		Label:       strings.TrimSpace(req.Label),
+		Description: strings.TrimSpace(req.Description),
+		Type:        req.Type,
+		Required:    req.Required,
+		SortOrder:   req.SortOrder,
+	}
+
+	if err := s.db.WithContext(ctx).Create(field).Error; err != nil {
+		return nil, fmt.Errorf("failed to create custom field: %w", err)
+	}
+
+	s.logger.Infof("Custom field created: %s (%s)", field.Name, field.Type)
+	return field, nil
+}
+
+func (s *CustomFieldService) UpdateField(ctx context.Context, fieldID uuid.UUID, req *UpdateCustomFieldRequest) (*models.CustomFieldDefinition, error) {
+	var field models.CustomFieldDefinition
+	if err := s.db.WithContext(ctx).Where("id = ?", fieldID).First(&field).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("custom field not found")
+		}
+		return nil, fmt.Errorf("failed to fetch custom field: %w", err)
+	}
+
+	updates := make(map[string]interface{})
+	if req.Label != nil {
+		updates["label"] = strings.TrimSpace(*req.Label)
+	}
+	if req.Description != nil {
+		updates["description"] = strings.TrimSpace(*req.Description)
+	}
+	if req.Required != nil {
+		updates["required"] = *req.Required
+	}
+	if req.SortOrder != nil {
+		updates["sort_order"] = *req.SortOrder
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.WithContext(ctx).Model(&field).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update custom field: %w", err)
+		}
+	}
+
+	s.logger.Infof("Custom field updated: %s", field.Name)
+	return &field, nil
+}
+
+// Removes a field from the schema, values already stored on documents are left untouched
+func (s *CustomFieldService) DeleteField(ctx context.Context, fieldID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("id = ?", fieldID).Delete(&models.CustomFieldDefinition{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete custom field: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("custom field not found")
+	}
+
+	s.logger.Infof("Custom field deleted: %s", fieldID)
+	return nil
+}
+
+// Checks submitted values against the schema and returns them normalized to their field types
+func (s *CustomFieldService) ValidateMetadata(ctx context.Context, values map[string]interface{}) (models.CustomMetadata, error) {
+	fields, err := s.ListFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := make(map[string]models.CustomFieldDefinition, len(fields))
+	for _, field := range fields {
+		schema[field.Name] = field
+	}
+
+	fieldErrors := make(map[string]string)
+	metadata := models.CustomMetadata{}
+
+	for name, raw := range values {
+		field, ok := schema[name]
+		if !ok {
+			fieldErrors[name] = "unknown field"
+			continue
+		}
+		if isEmptyCustomValue(raw) {
+			continue
+		}
+
+		value, err := normalizeCustomValue(field.Type, raw)
+		if err != nil {
+			fieldErrors[name] = err.Error()
+			continue
+		}
+		metadata[name] = value
+	}
+
+	for _, field := range fields {
+		if _, ok := metadata[field.Name]; !ok && field.Required {
+			if _, failed := fieldErrors[field.Name]; !failed {
+				fieldErrors[field.Name] = "field is required"
+			}
+		}
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &CustomMetadataError{Fields: fieldErrors}
+	}
+
+	return metadata, nil
+}
+
+// Converts query string filters into typed values matching how they are stored
+func (s *CustomFieldService) ParseFilters(ctx context.Context, filters map[string]string) (models.CustomMetadata, error) {
+	if len(filters) == 0 {
+		return nil, nil
+	}
+
+	fields, err := s.ListFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := make(map[string]models.CustomFieldDefinition, len(fields))
+	for _, field := range fields {
+		schema[field.Name] = field
+	}
+
+	fieldErrors := make(map[string]string)
+	criteria := models.CustomMetadata{}
+
+	for name, raw := range filters {
+		field, ok := schema[name]
+		if !ok {
+			fieldErrors[name] = "unknown field"
+			continue
+		}
+
+		var value interface{} = raw
+		switch field.Type {
+		case models.CustomFieldTypeNumber:
+			number, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				fieldErrors[name] = "must be a number"
+				continue
+			}
+			value = number
+		case models.CustomFieldTypeBoolean:
+			flag, err := strconv.ParseBool(raw)
+			if err != nil {
+				fieldErrors[name] = "must be true or false"
+				continue
+			}
+			value = flag
+		}
+
+		normalized, err := normalizeCustomValue(field.Type, value)
+		if err != nil {
+			fieldErrors[name] = err.Error()
+			continue
+		}
+		criteria[name] = normalized
+	}
+
+	if len(fieldErrors) > 0 {
+		return nil, &CustomMetadataError{Fields: fieldErrors}
+	}
+
+	return criteria, nil
+}
+
+func isEmptyCustomValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	if str, ok := value.(string); ok {
+		return strings.TrimSpace(str) == ""
+	}
+	return false
+}
+
+func normalizeCustomValue(fieldType models.CustomFieldType, value interface{}) (interface{}, error) {
+	switch fieldType {
+	case models.CustomFieldTypeText:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string")
+		}
+		str = strings.TrimSpace(str)
+		if len(str) > customFieldTextMaxLength {
+			return nil, fmt.Errorf("must be at most %d characters", customFieldTextMaxLength)
+		}
+		return str, nil
+
+	case models.CustomFieldTypeNumber:
+		number, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return number, nil
+
+	case models.CustomFieldTypeBoolean:
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("must be true or false")
+		}
+		return flag, nil
+
+	case models.CustomFieldTypeDate:
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a date in YYYY-MM-DD format")
+		}
+		date, err := time.Parse(customFieldDateLayout, strings.TrimSpace(str))
+		if err != nil {
+			return nil, fmt.Errorf("must be a date in YYYY-MM-DD format")
+		}
+		return date.Format(customFieldDateLayout), nil
+	}
+
+	return nil, fmt.Errorf("unsupported field type: %s", fieldType)
+}
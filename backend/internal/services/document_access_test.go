@@ -0,0 +1,38 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+)
+
+func TestHasRequiredAccess(t *testing.T) {
+	tests := []struct {
+		userAccess string
+		required   models.AccessLevel
+		want       bool
+	}{
+		{"view", models.AccessLevelView, true},
+		{"view", models.AccessLevelDownload, false},
+		{"view", models.AccessLevelEdit, false},
+		{"download", models.AccessLevelView, true},
+		{"download", models.AccessLevelDownload, true},
+		{"download", models.AccessLevelEdit, false},
+		{"edit", models.AccessLevelView, true},
+		{"edit", models.AccessLevelDownload, true},
+		{"edit", models.AccessLevelEdit, true},
+		{"owner", models.AccessLevelView, true},
+		{"owner", models.AccessLevelDownload, true},
+		{"owner", models.AccessLevelEdit, true},
+		{"", models.AccessLevelView, false},
+		{"admin", models.AccessLevelView, false},
+		{"", models.AccessLevel("unknown"), false},
+	}
+
+	service := &DocumentService{}
+	for _, tt := range tests {
+		if got := service.hasRequiredAccess(tt.userAccess, tt.required); got != tt.want {
+			t.Errorf("hasRequiredAccess(%q, %q) = %v, want %v", tt.userAccess, tt.required, got, tt.want)
+		}
+	}
+}
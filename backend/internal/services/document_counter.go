@@ -0,0 +1,338 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/repositories/interfaces"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Hash of pending increments, fields are "<documentID>:<counter>"
+	counterPendingKey = "document:counters:pending"
+	// Pending increments claimed by the instance currently flushing
+	counterFlushingKey = "document:counters:flushing"
+	counterFlushLock   = "document:counters:flush_lock"
+	counterLockTTL     = time.Minute
+
+	counterView     = "view"
+	counterDownload = "download"
+
+	// Marks a user's view of a document as counted until the dedup window passes
+	counterViewSeenPrefix = "document:views:seen:"
+	// In-memory view marks are pruned once there are this many
+	counterViewSeenPruneSize = 10000
+
+	// Upper bound for the final flush on shutdown
+	counterCloseTimeout = 10 * time.Second
+)
+
+type counterDelta struct {
+	views     int64
+	downloads int64
+}
+
+// DocumentCounter buffers view and download increments and periodically writes them to
+// Postgres, so hot documents are updated once per interval instead of once per request.
+// Increments are buffered in Redis when available so they survive across instances,
+// otherwise in memory. Repeated views of a document by the same user within the dedup
+// window are counted once.
+type DocumentCounter struct {
+	documentRepo    interfaces.DocumentRepository
+	redisClient     *redis.Client
+	flushInterval   time.Duration
+	viewDedupWindow time.Duration
+
+	mu      sync.Mutex
+	pending map[uuid.UUID]*counterDelta
+
+	// View marks used when Redis is not available, keyed like the Redis marks
+	seenMu    sync.Mutex
+	viewsSeen map[string]time.Time
+
+	stop      chan struct{}
+	done      chan struct{}
+	started   bool
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+func NewDocumentCounter(documentRepo interfaces.DocumentRepository, redisClient *redis.Client, flushInterval, viewDedupWindow time.Duration) *DocumentCounter {
+	return &DocumentCounter{
+		documentRepo:    documentRepo,
+		redisClient:     redisClient,
+		flushInterval:   flushInterval,
+		viewDedupWindow: viewDedupWindow,
+		pending:         make(map[uuid.UUID]*counterDelta),
+		viewsSeen:       make(map[string]time.Time),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+}
+
+// Counts a view of the document by the user unless they viewed it within the dedup window,
+// and reports whether it was counted
+func (c *DocumentCounter) IncrementView(ctx context.Context, documentID, userID uuid.UUID) (bool, error) {
+	if !c.firstViewInWindow(documentID, userID) {
+		return false, nil
+	}
+	return true, c.increment(ctx, documentID, counterView)
+}
+
+// Marks the view as seen and reports whether it should be counted. Views are counted when
+// the mark cannot be checked, an inflated count is better than a lost one.
+func (c *DocumentCounter) firstViewInWindow(documentID, userID uuid.UUID) bool {
+	if c.viewDedupWindow <= 0 {
+		return true
+	}
+	key := counterViewSeenPrefix + documentID.String() + ":" + userID.String()
+
+	if c.redisClient != nil {
+		first, err := c.redisClient.SetNX(key, "1", c.viewDedupWindow)
+		if err == nil {
+			return first
+		}
+		logrus.Warnf("[COUNTER] Failed to check view mark in Redis, using memory: %v", err)
+	}
+
+	now := time.Now()
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+
+	if until, ok := c.viewsSeen[key]; ok && now.Before(until) {
+		return false
+	}
+	if len(c.viewsSeen) >= counterViewSeenPruneSize {
+		for seenKey, until := range c.viewsSeen {
+			if !now.Before(until) {
+				delete(c.viewsSeen, seenKey)
+			}
+		}
+	}
+	c.viewsSeen[key] = now.Add(c.viewDedupWindow)
+	return true
+}
+
+func (c *DocumentCounter) IncrementDownload(ctx context.Context, documentID uuid.UUID) error {
+	return c.increment(ctx, documentID, counterDownload)
+}
+
+func (c *DocumentCounter) increment(ctx context.Context, documentID uuid.UUID, counter string) error {
+	if c.flushInterval <= 0 {
+		return c.writeThrough(ctx, documentID, counter)
+	}
+
+	if c.redisClient != nil {
+		field := documentID.String() + ":" + counter
+		if _, err := c.redisClient.HIncrBy(counterPendingKey, field, 1); err != nil {
+			logrus.Warnf("[COUNTER] Failed to buffer %s count in Redis, writing directly: %v", counter, err)
+			return c.writeThrough(ctx, documentID, counter)
+		}
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delta, ok := c.pending[documentID]
+	if !ok {
+		delta = &counterDelta{}
+		c.pending[documentID] = delta
+	}
+	if counter == counterView {
+		delta.views++
+	} else {
+		delta.downloads++
+	}
+	return nil
+}
+
+func (c *DocumentCounter) writeThrough(ctx context.Context, documentID uuid.UUID, counter string) error {
+	if counter == counterView {
+		return c.documentRepo.IncrementViewCount(ctx, documentID)
+	}
+	return c.documentRepo.IncrementDownloadCount(ctx, documentID)
+}
+
+// Start runs the periodic flush until Close is called
+func (c *DocumentCounter) Start() {
+	if c.flushInterval <= 0 {
+		return
+	}
+
+	c.startOnce.Do(func() {
+		c.started = true
+		go func() {
+			defer close(c.done)
+
+			ticker := time.NewTicker(c.flushInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-c.stop:
+					return
+				case <-ticker.C:
+					if err := c.Flush(context.Background()); err != nil {
+						logrus.Errorf("[COUNTER] Failed to flush document counters: %v", err)
+					}
+				}
+			}
+		}()
+	})
+}
+
+// Close stops the periodic flush and writes any remaining counts
+func (c *DocumentCounter) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		if c.started {
+			<-c.done
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), counterCloseTimeout)
+		defer cancel()
+		err = c.Flush(ctx)
+	})
+	return err
+}
+
+// Flush writes buffered counts to the database
+func (c *DocumentCounter) Flush(ctx context.Context) error {
+	if err := c.flushMemory(ctx); err != nil {
+		return err
+	}
+	if c.redisClient != nil {
+		return c.flushRedis(ctx)
+	}
+	return nil
+}
+
+func (c *DocumentCounter) flushMemory(ctx context.Context) error {
+	c.mu.Lock()
+	deltas := c.pending
+	c.pending = make(map[uuid.UUID]*counterDelta)
+	c.mu.Unlock()
+
+	failed := c.apply(ctx, deltas)
+	if len(failed) == 0 {
+		return nil
+	}
+
+	// Keep failed deltas for the next flush
+	c.mu.Lock()
+	for documentID, delta := range failed {
+		existing, ok := c.pending[documentID]
+		if !ok {
+			c.pending[documentID] = delta
+			continue
+		}
+		existing.views += delta.views
+		existing.downloads += delta.downloads
+	}
+	c.mu.Unlock()
+
+	return fmt.Errorf("failed to write counts for %d documents", len(failed))
+}
+
+func (c *DocumentCounter) flushRedis(ctx context.Context) error {
+	// Only one instance flushes at a time so claimed counts are never applied twice
+	acquired, err := c.redisClient.SetNX(counterFlushLock, "1", counterLockTTL)
+	if err != nil {
+		return fmt.Errorf("failed to acquire flush lock: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+	defer c.redisClient.Delete(counterFlushLock)
+
+	// Counts left behind by an interrupted flush are applied before claiming new ones
+	claimed, err := c.redisClient.Exists(counterFlushingKey)
+	if err != nil {
+		return fmt.Errorf("failed to check claimed counts: %w", err)
+	}
+	if !claimed {
+		hasPending, err := c.redisClient.Exists(counterPendingKey)
+		if err != nil {
+			return fmt.Errorf("failed to check pending counts: %w", err)
+		}
+		if !hasPending {
+			return nil
+		}
+		if err := c.redisClient.Rename(counterPendingKey, counterFlushingKey); err != nil {
+			return fmt.Errorf("failed to claim pending counts: %w", err)
+		}
+	}
+
+	fields, err := c.redisClient.HGetAll(counterFlushingKey)
+	if err != nil {
+		return fmt.Errorf("failed to read claimed counts: %w", err)
+	}
+
+	deltas := make(map[uuid.UUID]*counterDelta)
+	for field, value := range fields {
+		id, counter, ok := strings.Cut(field, ":")
+		documentID, err := uuid.Parse(id)
+		if !ok || err != nil {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		delta, exists := deltas[documentID]
+		if !exists {
+			delta = &counterDelta{}
+			deltas[documentID] = delta
+		}
+		if counter == counterView {
+			delta.views += count
+		} else {
+			delta.downloads += count
+		}
+	}
+
+	failed := c.apply(ctx, deltas)
+
+	// Return failed deltas to the pending hash so they are retried
+	for documentID, delta := range failed {
+		if delta.views > 0 {
+			c.redisClient.HIncrBy(counterPendingKey, documentID.String()+":"+counterView, delta.views)
+		}
+		if delta.downloads > 0 {
+			c.redisClient.HIncrBy(counterPendingKey, documentID.String()+":"+counterDownload, delta.downloads)
+		}
+	}
+
+	if err := c.redisClient.Delete(counterFlushingKey); err != nil {
+		return fmt.Errorf("failed to release claimed counts: %w", err)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to write counts for %d documents", len(failed))
+	}
+	return nil
+}
+
+// Writes deltas and returns the ones that could not be applied
+func (c *DocumentCounter) apply(ctx context.Context, deltas map[uuid.UUID]*counterDelta) map[uuid.UUID]*counterDelta {
+	failed := make(map[uuid.UUID]*counterDelta)
+	for documentID, delta := range deltas {
+		if delta.views == 0 && delta.downloads == 0 {
+			continue
+		}
+		if err := c.documentRepo.AddCounts(ctx, documentID, delta.views, delta.downloads); err != nil {
+			logrus.Warnf("[COUNTER] Failed to write counts for document %s: %v", documentID, err)
+			failed[documentID] = delta
+		}
+	}
+	return failed
+}
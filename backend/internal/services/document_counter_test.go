@@ -0,0 +1,133 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/testutil"
+	"github.com/google/uuid"
+)
+
+const concurrentViews = 100
+
+// Set on buffered counters, which must not fall back to writing each view
+var errWriteThrough = errors.New("unexpected write through")
+
+// Builds a document service around an in-memory repository holding one document of owner
+func newCountedDocument(t *testing.T, counter func(*testutil.MockDocumentRepository) *services.DocumentCounter) (*services.DocumentService, *services.DocumentCounter, *testutil.MockDocumentRepository, *models.Document) {
+	t.Helper()
+
+	repo := testutil.NewMockDocumentRepository()
+	document := &models.Document{
+		Title:       "Report",
+		FileType:    models.DocumentTypeTXT,
+		Status:      models.DocumentStatusReady,
+		StoragePath: "users/owner/documents/report.txt",
+		UserID:      uuid.New(),
+	}
+	repo.Put(document)
+
+	documentCounter := counter(repo)
+	service := services.NewDocumentService(
+		repo, nil, testutil.NewMockUploader(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		documentCounter, nil,
+		config.PreviewConfig{}, config.RevisionConfig{}, config.IntegrityConfig{}, config.ScanConfig{},
+		testutil.NewDryRunDB(t, &models.DocumentActivity{}, &models.DocumentPin{}),
+	)
+	return service, documentCounter, repo, document
+}
+
+// Fires concurrent GetDocument calls and returns the view count once everything is flushed
+func viewConcurrently(t *testing.T, service *services.DocumentService, documentCounter *services.DocumentCounter, repo *testutil.MockDocumentRepository, document *models.Document) int64 {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, concurrentViews)
+	for i := 0; i < concurrentViews; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.GetDocument(context.Background(), document.UserID, document.ID, "127.0.0.1", "test"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("GetDocument failed: %v", err)
+	}
+
+	if err := documentCounter.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	views, _ := repo.Counts(document.ID)
+	return views
+}
+
+func TestGetDocumentCountsConcurrentViews(t *testing.T) {
+	tests := []struct {
+		name    string
+		counter func(*testing.T, *testutil.MockDocumentRepository) *services.DocumentCounter
+	}{
+		{"write through", func(t *testing.T, repo *testutil.MockDocumentRepository) *services.DocumentCounter {
+			return services.NewDocumentCounter(repo, nil, 0, 0)
+		}},
+		{"buffered in memory", func(t *testing.T, repo *testutil.MockDocumentRepository) *services.DocumentCounter {
+			repo.Errors["IncrementViewCount"] = errWriteThrough
+			return services.NewDocumentCounter(repo, nil, time.Hour, 0)
+		}},
+		{"buffered in redis", func(t *testing.T, repo *testutil.MockDocumentRepository) *services.DocumentCounter {
+			// Views only add up when every increment made it into Redis
+			repo.Errors["IncrementViewCount"] = errWriteThrough
+			client, _ := testutil.NewRedisClient(t)
+			return services.NewDocumentCounter(repo, client, time.Hour, 0)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, documentCounter, repo, document := newCountedDocument(t, func(repo *testutil.MockDocumentRepository) *services.DocumentCounter {
+				return tt.counter(t, repo)
+			})
+
+			if views := viewConcurrently(t, service, documentCounter, repo, document); views != concurrentViews {
+				t.Fatalf("view count = %d, want %d", views, concurrentViews)
+			}
+		})
+	}
+}
+
+func TestGetDocumentCountsRepeatedViewsOnce(t *testing.T) {
+	tests := []struct {
+		name  string
+		redis func(*testing.T) *redis.Client
+	}{
+		{"marks in memory", func(*testing.T) *redis.Client { return nil }},
+		{"marks in redis", func(t *testing.T) *redis.Client {
+			client, _ := testutil.NewRedisClient(t)
+			return client
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tt.redis(t)
+			service, documentCounter, repo, document := newCountedDocument(t, func(repo *testutil.MockDocumentRepository) *services.DocumentCounter {
+				return services.NewDocumentCounter(repo, client, time.Hour, time.Minute)
+			})
+
+			// All views come from the owner within the dedup window
+			if views := viewConcurrently(t, service, documentCounter, repo, document); views != 1 {
+				t.Fatalf("view count = %d, want 1", views)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	expiryLock = "document:expiry:lock"
+
+	expiryBatchSize = 100
+
+	// Notification sent to owners before their document expires
+	NotificationTypeDocumentExpiring = "document_expiring"
+)
+
+// Warns owners about documents about to expire and removes the expired ones
+type documentExpirySweeper struct {
+	service     *DocumentService
+	redisClient *redis.Client // nil runs unlocked, which is only safe on a single instance
+	cfg         config.ExpiryConfig
+}
+
+// Periodically trashes or deletes documents past their expiration time, depending on the
+// configured action, after notifying their owners cfg.Notice ahead. Instances take turns
+// through a Redis lock.
+func (s *DocumentService) StartExpirySweeper(ctx context.Context, redisClient *redis.Client, cfg config.ExpiryConfig) {
+	if cfg.SweepInterval <= 0 {
+		return
+	}
+
+	sweeper := &documentExpirySweeper{
+		service:     s,
+		redisClient: redisClient,
+		cfg:         cfg,
+	}
+
+	go func() {
+		ticker := time.NewTicker(cfg.SweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := sweeper.run(ctx); err != nil && ctx.Err() == nil {
+					logrus.Errorf("[EXPIRY] Failed to sweep expired documents: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (e *documentExpirySweeper) run(ctx context.Context) error {
+	if e.redisClient != nil {
+		// The lock expires on its own if this instance dies mid-run
+		acquired, err := e.redisClient.SetNX(expiryLock, "1", e.cfg.SweepInterval)
+		if err != nil {
+			return fmt.Errorf("failed to acquire expiry lock: %w", err)
+		}
+		if !acquired {
+			return nil
+		}
+		defer e.redisClient.Delete(expiryLock)
+	}
+
+	if e.cfg.Notice > 0 {
+		notified, err := e.notifyExpiring(ctx)
+		if err != nil {
+			return err
+		}
+		if notified > 0 {
+			logrus.Infof("[EXPIRY] Notified owners of %d expiring documents", notified)
+		}
+	}
+
+	removed, err := e.removeExpired(ctx)
+	if removed > 0 {
+		logrus.Infof("[EXPIRY] Removed %d expired documents (%s)", removed, e.cfg.Action)
+	}
+	return err
+}
+
+// Notifies owners of documents expiring within the notice period, once per expiration
+func (e *documentExpirySweeper) notifyExpiring(ctx context.Context) (int, error) {
+	notified := 0
+	before := time.Now().Add(e.cfg.Notice)
+
+	for {
+		documents, err := e.service.documentRepo.ListExpiringUnnotified(ctx, before, expiryBatchSize)
+		if err != nil {
+			return notified, err
+		}
+
+		// Marked documents leave the set, so every batch is a new one
+		for i := range documents {
+			document := &documents[i]
+			metadata, _ := json.Marshal(map[string]string{
+				"expiresAt": document.ExpiresAt.UTC().Format(time.RFC3339),
+				"action":    e.cfg.Action,
+			})
+			notification := &models.ShareNotification{
+				Type:       NotificationTypeDocumentExpiring,
+				Title:      "Document expiring soon",
+				Message:    fmt.Sprintf("%q will be removed on %s", document.Title, document.ExpiresAt.UTC().Format(time.RFC1123)),
+				DocumentID: document.ID,
+				FromUserID: document.UserID,
+				ToUserID:   document.UserID,
+				Metadata:   string(metadata),
+			}
+			if err := e.service.db.WithContext(ctx).Create(notification).Error; err != nil {
+				return notified, fmt.Errorf("failed to notify owner of document %s: %w", document.ID, err)
+			}
+			if err := e.service.documentRepo.MarkExpiryNotified(ctx, document.ID); err != nil {
+				return notified, fmt.Errorf("failed to mark document %s notified: %w", document.ID, err)
+			}
+			notified++
+		}
+
+		if len(documents) < expiryBatchSize {
+			return notified, nil
+		}
+	}
+}
+
+// Trashes or permanently deletes every expired document. A document that fails is logged and
+// left for the next sweep, only failing to list the expired documents is an error.
+func (e *documentExpirySweeper) removeExpired(ctx context.Context) (int, error) {
+	now := time.Now()
+	removed := 0
+
+	// Documents that fail keep their expiry, the cursor keeps them from being retried in this sweep
+	lastID := uuid.Nil
+	for {
+		if ctx.Err() != nil {
+			return removed, ctx.Err()
+		}
+
+		documents, err := e.service.documentRepo.ListExpired(ctx, now, lastID, expiryBatchSize)
+		if err != nil {
+			return removed, err
+		}
+
+		for i := range documents {
+			document := &documents[i]
+			if e.cfg.Action == config.ExpiryActionDelete {
+				err = e.service.purgeDocument(ctx, document)
+			} else {
+				err = e.service.documentRepo.Trash(ctx, document.ID)
+			}
+			if err != nil {
+				logrus.Errorf("[EXPIRY] Failed to remove expired document %s: %v", document.ID, err)
+				continue
+			}
+			logrus.WithFields(logrus.Fields{
+				"document_id": document.ID,
+				"user_id":     document.UserID,
+				"expires_at":  document.ExpiresAt,
+			}).Infof("[EXPIRY] Document expired (%s)", e.cfg.Action)
+			removed++
+		}
+
+		if len(documents) < expiryBatchSize {
+			return removed, nil
+		}
+		lastID = documents[len(documents)-1].ID
+	}
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/repositories/interfaces"
+	"github.com/google/uuid"
+)
+
+// Holds expired documents, removing one fails when it is listed in failing
+type expiredDocumentRepository struct {
+	interfaces.DocumentRepository
+
+	documents map[uuid.UUID]models.Document
+	failing   map[uuid.UUID]bool
+}
+
+func (r *expiredDocumentRepository) ListExpired(ctx context.Context, now time.Time, afterID uuid.UUID, limit int) ([]models.Document, error) {
+	var documents []models.Document
+	for id, document := range r.documents {
+		if bytes.Compare(id[:], afterID[:]) > 0 {
+			documents = append(documents, document)
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		return bytes.Compare(documents[i].ID[:], documents[j].ID[:]) < 0
+	})
+	if len(documents) > limit {
+		documents = documents[:limit]
+	}
+	return documents, nil
+}
+
+func (r *expiredDocumentRepository) remove(id uuid.UUID) error {
+	if r.failing[id] {
+		return errors.New("violates foreign key constraint")
+	}
+	delete(r.documents, id)
+	return nil
+}
+
+func (r *expiredDocumentRepository) Trash(ctx context.Context, id uuid.UUID) error {
+	return r.remove(id)
+}
+
+func (r *expiredDocumentRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	return r.remove(id)
+}
+
+func (r *expiredDocumentRepository) GetRevisions(ctx context.Context, documentID uuid.UUID) ([]models.DocumentRevision, error) {
+	return nil, nil
+}
+
+// Storage that accepts every delete
+type discardingUploader struct {
+	Uploader
+}
+
+func (discardingUploader) DeleteFile(ctx context.Context, objectName string) error {
+	return nil
+}
+
+func TestRemoveExpiredSkipsFailedDocuments(t *testing.T) {
+	const expired = 250 // spans several batches
+
+	for _, action := range []string{config.ExpiryActionTrash, config.ExpiryActionDelete} {
+		t.Run(action, func(t *testing.T) {
+			repo := &expiredDocumentRepository{
+				documents: make(map[uuid.UUID]models.Document),
+				failing:   make(map[uuid.UUID]bool),
+			}
+			expiresAt := time.Now().Add(-time.Hour)
+			for i := 0; i < expired; i++ {
+				document := models.Document{ID: uuid.New(), UserID: uuid.New(), StoragePath: "users/owner/documents/report.txt", ExpiresAt: &expiresAt}
+				repo.documents[document.ID] = document
+				if i%100 == 0 {
+					repo.failing[document.ID] = true
+				}
+			}
+
+			sweeper := &documentExpirySweeper{
+				service: &DocumentService{documentRepo: repo, minioService: discardingUploader{}},
+				cfg:     config.ExpiryConfig{Action: action},
+			}
+			removed, err := sweeper.removeExpired(context.Background())
+			if err != nil {
+				t.Fatalf("removeExpired failed: %v", err)
+			}
+			if want := expired - len(repo.failing); removed != want {
+				t.Fatalf("removed = %d, want %d", removed, want)
+			}
+			if len(repo.documents) != len(repo.failing) {
+				t.Fatalf("%d documents left, want only the %d that failed", len(repo.documents), len(repo.failing))
+			}
+		})
+	}
+}
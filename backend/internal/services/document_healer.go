@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/queue"
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	healLock = "document:heal:lock"
+	// Marks a document file as re-enqueued once, the next time it is stuck it is failed
+	healRequeuedPrefix = "document:heal:requeued:"
+
+	healBatchSize = 100
+)
+
+// Finds documents stuck in processing, typically because the worker died mid-message
+type stuckDocumentHealer struct {
+	service     *DocumentService
+	redisClient *redis.Client // nil runs unlocked, which is only safe on a single instance
+	threshold   time.Duration
+
+	// Re-enqueued document files when there is no Redis, by requeue time
+	requeued map[string]time.Time
+}
+
+// Periodically re-enqueues documents processing for longer than threshold, or marks them
+// failed when they were already re-enqueued once. Instances take turns through a Redis lock.
+func (s *DocumentService) StartStuckDocumentHealer(ctx context.Context, redisClient *redis.Client, interval, threshold time.Duration) {
+	if s.previewQueue == nil || interval <= 0 || threshold <= 0 {
+		return
+	}
+
+	healer := &stuckDocumentHealer{
+		service:     s,
+		redisClient: redisClient,
+		threshold:   threshold,
+		requeued:    make(map[string]time.Time),
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := healer.run(ctx, interval); err != nil && ctx.Err() == nil {
+					logrus.Errorf("[HEAL] Failed to heal stuck documents: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (h *stuckDocumentHealer) run(ctx context.Context, interval time.Duration) error {
+	if h.redisClient != nil {
+		// The lock expires on its own if this instance dies mid-run
+		acquired, err := h.redisClient.SetNX(healLock, "1", interval)
+		if err != nil {
+			return fmt.Errorf("failed to acquire heal lock: %w", err)
+		}
+		if !acquired {
+			return nil
+		}
+		defer h.redisClient.Delete(healLock)
+	}
+
+	h.pruneRequeued()
+
+	requeued, failed := 0, 0
+	cutoff := time.Now().Add(-h.threshold)
+	for {
+		documents, err := h.service.documentRepo.ListStuckProcessing(ctx, cutoff, healBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list stuck documents: %w", err)
+		}
+
+		// Every document leaves the stuck set, re-enqueueing touches updated_at
+		for i := range documents {
+			wasRequeued, err := h.heal(ctx, &documents[i])
+			if err != nil {
+				return err
+			}
+			if wasRequeued {
+				requeued++
+			} else {
+				failed++
+			}
+		}
+
+		if len(documents) < healBatchSize {
+			break
+		}
+	}
+
+	if requeued+failed > 0 {
+		logrus.Infof("[HEAL] Healed %d stuck documents, %d re-enqueued and %d marked failed", requeued+failed, requeued, failed)
+	}
+	return nil
+}
+
+// Re-enqueues a stuck document the first time, fails it the second time.
+// Reports whether it was re-enqueued.
+func (h *stuckDocumentHealer) heal(ctx context.Context, document *models.Document) (bool, error) {
+	firstTime, err := h.claimRequeue(document)
+	if err != nil {
+		return false, err
+	}
+
+	if firstTime {
+		err := h.service.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+			"updated_at": time.Now(),
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to touch stuck document %s: %w", document.ID, err)
+		}
+
+		// Fresh key, the lost message may already be recorded as processed
+		key := queue.DocumentMessageKey(document.ID.String(), document.StoragePath) + ":heal"
+		err = h.service.previewQueue.PublishDocumentForPreview(document.ID.String(), document.StoragePath, key)
+		if err == nil {
+			return true, nil
+		}
+		logrus.Warnf("[HEAL] Failed to re-enqueue document %s, marking it failed: %v", document.ID, err)
+	}
+
+	reason := fmt.Sprintf("processing did not finish within %s", h.threshold)
+	if !firstTime {
+		reason = fmt.Sprintf("processing did not finish within %s after being re-enqueued", h.threshold)
+	}
+	if err := h.service.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+		"status":           models.DocumentStatusFailed,
+		"processing_error": reason,
+	}); err != nil {
+		return false, fmt.Errorf("failed to mark stuck document %s as failed: %w", document.ID, err)
+	}
+	return false, nil
+}
+
+// Records that the document file is being re-enqueued, reports false when it already was
+func (h *stuckDocumentHealer) claimRequeue(document *models.Document) (bool, error) {
+	key := healRequeuedPrefix + document.ID.String() + ":" + document.StoragePath
+	// Long enough to see the re-enqueued run get stuck as well
+	ttl := 4 * h.threshold
+
+	if h.redisClient != nil {
+		first, err := h.redisClient.SetNX(key, "1", ttl)
+		if err != nil {
+			return false, fmt.Errorf("failed to record requeue of document %s: %w", document.ID, err)
+		}
+		return first, nil
+	}
+
+	if _, exists := h.requeued[key]; exists {
+		return false, nil
+	}
+	h.requeued[key] = time.Now()
+	return true, nil
+}
+
+func (h *stuckDocumentHealer) pruneRequeued() {
+	cutoff := time.Now().Add(-4 * h.threshold)
+	for key, requeuedAt := range h.requeued {
+		if requeuedAt.Before(cutoff) {
+			delete(h.requeued, key)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/metrics"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Notification sent to owners whose upload was removed as infected
+const NotificationTypeDocumentQuarantined = "document_quarantined"
+
+// Reports whether new files are virus scanned, scans run in the processing worker
+func (s *DocumentService) scansUploads() bool {
+	return s.scanner != nil && s.previewQueue != nil
+}
+
+// HoldsUnscanned reports whether files are withheld until they are scanned clean
+func (s *DocumentService) HoldsUnscanned() bool {
+	return s.holdUnscanned
+}
+
+// CheckScanned returns why the document's file may not be served, nil when it can be
+func (s *DocumentService) CheckScanned(document *models.Document) error {
+	return checkScanned(document, s.holdUnscanned)
+}
+
+// Infected files are never served, files waiting for their scan only when they aren't held
+func checkScanned(document *models.Document, holdUnscanned bool) error {
+	switch {
+	case document.ScanStatus == models.ScanStatusInfected:
+		return fmt.Errorf("document is quarantined")
+	case document.ScanStatus == models.ScanStatusPending && holdUnscanned:
+		return fmt.Errorf("document is awaiting virus scan")
+	default:
+		return nil
+	}
+}
+
+// Resets the scan state of a newly stored file, it is pending until the worker scans it and
+// stays empty while scanning is off
+func (s *DocumentService) resetScan(document *models.Document) {
+	document.ScanStatus = models.ScanStatusNone
+	document.ScanSignature = ""
+	document.ScannedAt = nil
+	if s.scansUploads() {
+		document.ScanStatus = models.ScanStatusPending
+	}
+}
+
+// Scans the downloaded file of the document and records the result. Infected documents are
+// quarantined. Errors are left to the consumer to retry, clamd may just be restarting.
+func (s *DocumentService) scanDocument(ctx context.Context, document *models.Document, localFile string) (bool, error) {
+	result, err := s.scanner.Scan(ctx, localFile)
+	if err != nil {
+		metrics.DocumentScans.Inc("error")
+		return false, fmt.Errorf("failed to scan document: %w", err)
+	}
+
+	if result.Infected {
+		metrics.DocumentScans.Inc("infected")
+		return true, s.quarantineDocument(ctx, document, result.Signature)
+	}
+	metrics.DocumentScans.Inc("clean")
+
+	// Saved right away so the result survives a failure in the later processing steps
+	now := time.Now()
+	if err := s.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+		"scan_status":    models.ScanStatusClean,
+		"scan_signature": "",
+		"scanned_at":     &now,
+	}); err != nil {
+		if strings.Contains(err.Error(), "document not found") {
+			logrus.Infof("[SCAN] Document %s changed while being scanned, skipping", document.ID)
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to save scan result: %w", err)
+	}
+	return false, nil
+}
+
+// Takes an infected file out of circulation. The document is kept as failed so its owner can
+// see what happened, the stored file is deleted and the owner notified.
+func (s *DocumentService) quarantineDocument(ctx context.Context, document *models.Document, signature string) error {
+	logrus.Warnf("[SCAN] Document %s is infected with %s, quarantining", document.ID, signature)
+
+	now := time.Now()
+	err := s.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+		"status":           models.DocumentStatusFailed,
+		"processing_error": fmt.Sprintf("file is infected with %s and was removed", signature),
+		"scan_status":      models.ScanStatusInfected,
+		"scan_signature":   signature,
+		"scanned_at":       &now,
+	})
+	recorded := err == nil
+	if err != nil && !strings.Contains(err.Error(), "document not found") {
+		return fmt.Errorf("failed to record infected document: %w", err)
+	}
+
+	// Deleted even when the document moved on to another file, it may still be kept for a revision
+	if err := s.minioService.DeleteFile(ctx, document.StoragePath); err != nil {
+		return fmt.Errorf("failed to delete infected file %s: %w", document.StoragePath, err)
+	}
+	if !recorded {
+		return nil
+	}
+	s.searchCache.Invalidate(document.UserID)
+
+	metadata, _ := json.Marshal(map[string]string{"signature": signature})
+	notification := &models.ShareNotification{
+		Type:       NotificationTypeDocumentQuarantined,
+		Title:      "Infected file removed",
+		Message:    fmt.Sprintf("%q contained %s and its file was deleted", document.Title, signature),
+		DocumentID: document.ID,
+		FromUserID: document.UserID,
+		ToUserID:   document.UserID,
+		Metadata:   string(metadata),
+	}
+	// The file is gone either way, a lost notification is not worth scanning again for
+	if err := s.db.WithContext(ctx).Create(notification).Error; err != nil {
+		logrus.Errorf("[SCAN] Failed to notify owner of infected document %s: %v", document.ID, err)
+	}
+	return nil
+}
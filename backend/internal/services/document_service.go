@@ -2,39 +2,112 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
 	"github.com/eyuppastirmaci/noesis-forge/internal/fts"
+	"github.com/eyuppastirmaci/noesis-forge/internal/metrics"
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/queue"
 	"github.com/eyuppastirmaci/noesis-forge/internal/repositories/interfaces"
 	"github.com/eyuppastirmaci/noesis-forge/internal/types"
 	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const (
+	// How long trashed documents are kept before being purged
+	TrashRetentionPeriod = 30 * 24 * time.Hour
+	// How often the trash sweeper runs
+	TrashSweepInterval = time.Hour
+
+	trashPurgeBatchSize = 100
+
+	// Attempts and initial backoff for ImageMagick work in the preview worker
+	previewMaxAttempts = 3
+	previewRetryDelay  = 2 * time.Second
+
+	contentBackfillBatchSize = 50
+
+	// Months covered by the upload timeline of the storage breakdown, current month included
+	storageTimelineMonths = 12
+)
+
+// DuplicateDocumentError is returned when the user already has a document with the same content
+type DuplicateDocumentError struct {
+	Existing *models.Document
+}
+
+func (e *DuplicateDocumentError) Error() string {
+	return "duplicate document"
+}
+
 type DocumentService struct {
-	documentRepo     interfaces.DocumentRepository
-	searchRepo       interfaces.DocumentSearchRepository
-	searchStrategies []types.SearchStrategy
-	minioService     *MinIOService
-	userShareService *UserShareService
-	db               *gorm.DB
+	documentRepo      interfaces.DocumentRepository
+	searchRepo        interfaces.DocumentSearchRepository
+	searchStrategies  []types.SearchStrategy
+	previewStrategies []types.PreviewStrategy
+	previewURLExpiry  time.Duration
+	thumbURLExpiry    time.Duration
+	renderMaxBytes    int64
+	maxRevisions      int
+	verifyMaxBytes    int64
+	minioService      Uploader
+	userShareService  *UserShareService
+	imageMagick       *ImageMagick // nil when ImageMagick is not installed
+	libreOffice       *LibreOffice // nil when LibreOffice is not installed
+	scanner           *ClamAV      // nil when virus scanning is off
+	holdUnscanned     bool         // files are withheld until scanned clean
+	textExtractor     *TextExtractor
+	scratch           *ScratchDir
+	fileTypes         *models.FileTypePolicy
+	tags              *models.TagPolicy
+	thumbnailPresets  *ThumbnailPresets
+	previewQueue      *queue.Publisher
+	counter           *DocumentCounter
+	searchCache       *SearchCache // nil when search results are not cached
+	customFields      *CustomFieldService
+	activityService   *ActivityService
+	db                *gorm.DB
 }
 
 func NewDocumentService(
 	documentRepo interfaces.DocumentRepository,
 	searchRepo interfaces.DocumentSearchRepository,
-	minioService *MinIOService,
+	minioService Uploader,
 	userShareService *UserShareService,
+	imageMagick *ImageMagick,
+	libreOffice *LibreOffice,
+	scanner *ClamAV,
+	textExtractor *TextExtractor,
+	scratch *ScratchDir,
+	fileTypes *models.FileTypePolicy,
+	tags *models.TagPolicy,
+	thumbnailPresets *ThumbnailPresets,
+	previewQueue *queue.Publisher,
+	counter *DocumentCounter,
+	searchCache *SearchCache,
+	previewConfig config.PreviewConfig,
+	revisionConfig config.RevisionConfig,
+	integrityConfig config.IntegrityConfig,
+	scanConfig config.ScanConfig,
 	db *gorm.DB,
 ) *DocumentService {
 	searchStrategies := []types.SearchStrategy{
@@ -45,32 +118,85 @@ func NewDocumentService(
 	}
 
 	return &DocumentService{
-		documentRepo:     documentRepo,
-		searchRepo:       searchRepo,
-		searchStrategies: searchStrategies,
-		minioService:     minioService,
-		userShareService: userShareService,
-		db:               db,
+		documentRepo:      documentRepo,
+		searchRepo:        searchRepo,
+		searchStrategies:  searchStrategies,
+		previewStrategies: NewPreviewStrategies(previewConfig.Strategies, minioService),
+		previewURLExpiry:  previewConfig.URLExpiry,
+		thumbURLExpiry:    previewConfig.ThumbnailURLExpiry,
+		renderMaxBytes:    previewConfig.RenderMaxBytes,
+		maxRevisions:      revisionConfig.MaxPerDocument,
+		verifyMaxBytes:    integrityConfig.VerifyDownloadMaxBytes,
+		minioService:      minioService,
+		userShareService:  userShareService,
+		imageMagick:       imageMagick,
+		libreOffice:       libreOffice,
+		scanner:           scanner,
+		holdUnscanned:     scanner != nil && previewQueue != nil && scanConfig.Mode == config.ScanModeBlock,
+		textExtractor:     textExtractor,
+		scratch:           scratch,
+		fileTypes:         fileTypes,
+		tags:              tags,
+		thumbnailPresets:  thumbnailPresets,
+		previewQueue:      previewQueue,
+		counter:           counter,
+		searchCache:       searchCache,
+		customFields:      NewCustomFieldService(db),
+		activityService:   NewActivityService(db),
+		db:                db,
 	}
 }
 
+// Returns the file types accepted for upload, shared with the upload validation
+func (s *DocumentService) FileTypes() *models.FileTypePolicy {
+	return s.fileTypes
+}
+
+// Returns the tag rules, shared with the upload and update validation
+func (s *DocumentService) Tags() *models.TagPolicy {
+	return s.tags
+}
+
+// Drops the user's cached search results, for changes made to their documents outside the service
+func (s *DocumentService) InvalidateSearchCache(userID uuid.UUID) {
+	s.searchCache.Invalidate(userID)
+}
+
+// Returns the tags used across the user's documents for the tag cloud
+func (s *DocumentService) GetUserTags(ctx context.Context, userID uuid.UUID) ([]types.TagCount, error) {
+	return s.documentRepo.ListUserTags(ctx, userID)
+}
+
 // Handles document search with multiple strategies
 func (s *DocumentService) SearchDocuments(ctx context.Context, req *types.DocumentListRequest, userID uuid.UUID) (*types.SearchResult, error) {
 	// Preprocess search query
 	cleanSearch, tokens := utils.PreprocessQuery(req.Search)
 	useSearch := cleanSearch != ""
 
+	// Resolve custom field filters to typed values
+	customMetadata, err := s.customFields.ParseFilters(ctx, req.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+
 	searchReq := &types.SearchRequest{
-		UserID:   userID,
-		Query:    cleanSearch,
-		Tokens:   tokens,
-		Page:     req.Page,
-		Limit:    req.Limit,
-		FileType: req.FileType,
-		Status:   req.Status,
-		Tags:     req.Tags,
-		SortBy:   req.SortBy,
-		SortDir:  req.SortDir,
+		UserID:         userID,
+		Query:          cleanSearch,
+		RawQuery:       strings.TrimSpace(req.Search),
+		Tokens:         tokens,
+		Page:           req.Page,
+		Limit:          req.Limit,
+		FileType:       req.FileType,
+		Status:         req.Status,
+		Tags:           req.Tags,
+		Language:       req.Language,
+		CustomMetadata: customMetadata,
+		SortBy:         req.SortBy,
+		SortDir:        req.SortDir,
+	}
+
+	if err := s.resolveFolderFilter(ctx, userID, req, searchReq); err != nil {
+		return nil, err
 	}
 
 	// Auto-adjust sorting when no search query
@@ -79,6 +205,28 @@ func (s *DocumentService) SearchDocuments(ctx context.Context, req *types.Docume
 		searchReq.SortDir = "desc"
 	}
 
+	// Repeated searches are served from the cache until the user's documents change. Debugging
+	// wants to see the strategies actually run.
+	if !req.NoCache && !req.Debug {
+		if cached := s.searchCache.Get(searchReq); cached != nil {
+			metrics.SearchStrategyHits.Inc("cache")
+			return cached, nil
+		}
+	}
+
+	result, err := s.runSearch(ctx, searchReq, useSearch)
+	if err != nil {
+		return nil, err
+	}
+	s.searchCache.Set(searchReq, result)
+
+	return result, nil
+}
+
+// Runs the search strategies in order, or the plain listing when there is no query
+func (s *DocumentService) runSearch(ctx context.Context, searchReq *types.SearchRequest, useSearch bool) (*types.SearchResult, error) {
+	userID := searchReq.UserID
+
 	// Apply filters function
 	addFilters := func(q *gorm.DB) *gorm.DB {
 		return s.applyFilters(q, searchReq)
@@ -86,18 +234,32 @@ func (s *DocumentService) SearchDocuments(ctx context.Context, req *types.Docume
 
 	if useSearch {
 		// Try search strategies in order
+		var attempts []types.SearchStrategyAttempt
 		for _, strategy := range s.searchStrategies {
 			if strategy.CanHandle(searchReq) {
 				result, err := strategy.Search(ctx, searchReq, addFilters)
+				attempt := types.SearchStrategyAttempt{Strategy: strategy.Name()}
+				if err != nil {
+					attempt.Error = err.Error()
+				} else {
+					attempt.Candidates = result.Total
+				}
+				attempts = append(attempts, attempt)
+
 				if err == nil && result.Total > 0 {
+					metrics.SearchStrategyHits.Inc(strategy.Name())
+					result.Strategy = strategy.Name()
+					result.Attempts = attempts
 					return result, nil
 				}
 			}
 		}
 		// No search results found
 		logrus.Warnf("No strategy could find results for query: '%s'", searchReq.Query)
-		return &types.SearchResult{}, nil
+		metrics.SearchStrategyHits.Inc("none")
+		return &types.SearchResult{Strategy: "none", Attempts: attempts}, nil
 	}
+	metrics.SearchStrategyHits.Inc("listing")
 
 	// Normal listing (no search) - use basic repository
 	baseQuery := s.db.WithContext(ctx).Model(&models.Document{}).Where("user_id = ?", userID)
@@ -125,25 +287,40 @@ func (s *DocumentService) SearchDocuments(ctx context.Context, req *types.Docume
 		Page:       searchReq.Page,
 		Limit:      searchReq.Limit,
 		TotalPages: totalPages,
+		Strategy:   "listing",
 	}, nil
 }
 
 func (s *DocumentService) applyFilters(q *gorm.DB, req *types.SearchRequest) *gorm.DB {
+	// Expired documents are gone for the user even before the sweep removes them
+	q = q.Where("expires_at IS NULL OR expires_at > ?", time.Now())
 	if req.FileType != "" && req.FileType != "all" {
 		q = q.Where("file_type = ?", req.FileType)
 	}
 	if req.Status != "" && req.Status != "all" {
 		q = q.Where("status = ?", req.Status)
 	}
-	if req.Tags != "" {
-		tags := strings.Split(req.Tags, ",")
-		for _, tag := range tags {
-			trimmedTag := strings.TrimSpace(tag)
-			if trimmedTag != "" {
-				q = q.Where("tags ILIKE ?", "%"+trimmedTag+"%")
-			}
+	if req.Language != "" {
+		q = q.Where("language = ?", req.Language)
+	}
+	if len(req.FolderIDs) > 0 {
+		q = q.Where("folder_id IN ?", req.FolderIDs)
+	}
+	if req.Unfiled {
+		q = q.Where("folder_id IS NULL")
+	}
+	if len(req.CustomMetadata) > 0 {
+		if criteria, err := json.Marshal(req.CustomMetadata); err == nil {
+			q = q.Where("custom_metadata @> CAST(? AS jsonb)", string(criteria))
 		}
 	}
+	// Every requested tag must be attached, matched exactly rather than as a substring
+	for _, tag := range models.NormalizeTags(req.Tags) {
+		q = q.Where(`EXISTS (
+			SELECT 1 FROM document_tags dt JOIN tags t ON t.id = dt.tag_id
+			WHERE dt.document_id = documents.id AND t.name = ?
+		)`, tag)
+	}
 	return q
 }
 
@@ -166,7 +343,14 @@ func (s *DocumentService) buildOrderBy(req *types.SearchRequest) string {
 		dir = "ASC"
 	}
 
-	return col + " " + dir
+	// Documents the user pinned come first, each group in the requested order. The user ID
+	// is a parsed UUID, safe to inline.
+	pinnedFirst := fmt.Sprintf(
+		"EXISTS (SELECT 1 FROM document_pins WHERE document_pins.document_id = documents.id AND document_pins.user_id = '%s') DESC",
+		req.UserID,
+	)
+
+	return pinnedFirst + ", " + col + " " + dir
 }
 
 // Handles document upload with business logic
@@ -176,6 +360,24 @@ func (s *DocumentService) UploadDocument(ctx context.Context, userID uuid.UUID,
 		return nil, err
 	}
 
+	// Business rule: Custom metadata must match the configured schema
+	customMetadata, err := s.customFields.ValidateMetadata(ctx, req.CustomMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	// Business rule: OCR only applies to PDFs and needs the language data installed
+	var ocrLanguage string
+	if req.RunOCR && models.DocumentTypeForFile(file.Filename) == models.DocumentTypePDF {
+		ocrLanguage = req.OCRLanguage
+		if ocrLanguage == "" {
+			ocrLanguage = models.DefaultOCRLanguage(strings.ToLower(req.Language))
+		}
+		if !s.textExtractor.CanOCR(ocrLanguage) {
+			return nil, fmt.Errorf("OCR is not available for language %s", ocrLanguage)
+		}
+	}
+
 	// Open the uploaded file
 	src, err := file.Open()
 	if err != nil {
@@ -192,14 +394,45 @@ func (s *DocumentService) UploadDocument(ctx context.Context, userID uuid.UUID,
 	// Get file content type
 	contentType := file.Header.Get("Content-Type")
 
-	// Upload to MinIO (external service)
-	bucketName := s.minioService.config.BucketName
-	if err := s.minioService.UploadFile(ctx, bucketName, objectName, src, file.Size, contentType); err != nil {
+	// Upload to MinIO (external service), hashing the content on the way
+	bucketName := s.minioService.BucketName()
+	hasher := sha256.New()
+	if err := s.minioService.UploadFile(ctx, bucketName, objectName, io.TeeReader(src, hasher), file.Size, contentType); err != nil {
 		return nil, fmt.Errorf("failed to upload file to storage: %w", err)
 	}
+	contentHash := hex.EncodeToString(hasher.Sum(nil))
+
+	// The stored object is only kept once the document row is committed. The removal must not
+	// share the request's context, a client disconnecting is what fails the insert most often.
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+		if cleanupErr := s.minioService.DeleteFile(context.WithoutCancel(ctx), objectName); cleanupErr != nil {
+			logrus.Errorf("Failed to cleanup upload %s: %v", objectName, cleanupErr)
+		}
+	}()
+
+	// Business rule: Warn about identical content unless the user opted in
+	if !req.AllowDuplicate {
+		existing, err := s.documentRepo.GetByContentHash(ctx, userID, contentHash)
+		if err == nil {
+			return nil, &DuplicateDocumentError{Existing: existing}
+		}
+		if err.Error() != "document not found" {
+			logrus.Warnf("Duplicate check failed for user %s: %v", userID, err)
+		}
+	}
 
 	// Determine file type (business logic)
-	fileType := s.getDocumentType(file.Filename)
+	fileType := models.DocumentTypeForFile(file.Filename)
+
+	// Fall back to the default search configuration for unknown languages
+	language := strings.ToLower(req.Language)
+	if !models.IsSupportedDocumentLanguage(language) {
+		language = models.DefaultDocumentLanguage
+	}
 
 	// Create document model
 	document := &models.Document{
@@ -210,52 +443,40 @@ func (s *DocumentService) UploadDocument(ctx context.Context, userID uuid.UUID,
 		FileSize:         file.Size,
 		FileType:         fileType,
 		MimeType:         contentType,
-		Status:           models.DocumentStatusProcessing,
+		Status:           models.DocumentStatusReady,
 		StoragePath:      objectName,
 		StorageBucket:    bucketName,
-		Tags:             req.Tags,
+		ContentHash:      contentHash,
+		Tags:             strings.Join(models.NormalizeTags(req.Tags), ","),
 		IsPublic:         req.IsPublic,
+		Language:         language,
+		CustomMetadata:   customMetadata,
+		OCRLanguage:      ocrLanguage,
+		ExpiresAt:        req.ExpiresAt,
 		UserID:           userID,
 		Version:          1,
 	}
 
-	// Handle PDF-specific processing
-	if fileType == models.DocumentTypePDF {
-		// Extract page count (business logic)
-		pageCount, err := s.extractPDFPageCount(ctx, file)
-		if err != nil {
-			logrus.Warnf("Failed to extract PDF page count for %s: %v", file.Filename, err)
-		} else {
-			document.PageCount = pageCount
-		}
-
-		// Generate thumbnail (business logic)
-		thumbnailPath, err := s.generatePDFThumbnail(ctx, file, objectName)
-		if err != nil {
-			logrus.Warnf("Failed to generate PDF thumbnail for %s: %v", file.Filename, err)
-			document.HasThumbnail = false
-		} else {
-			document.ThumbnailPath = thumbnailPath
-			document.HasThumbnail = true
-		}
+	// Page count, thumbnail and search text come from the preview worker, other types are
+	// inserted ready so no second write is needed
+	now := time.Now()
+	if s.needsProcessing(fileType) {
+		document.Status = models.DocumentStatusProcessing
+	} else {
+		document.ProcessedAt = &now
 	}
+	s.resetScan(document)
 
-	// Save to database via repository
+	// Save to database via repository, the row and its tags are written in one transaction
 	if err := s.documentRepo.Create(ctx, document); err != nil {
-		// Cleanup uploaded file if database save fails
-		if cleanupErr := s.minioService.DeleteFile(ctx, objectName); cleanupErr != nil {
-			logrus.Errorf("Failed to cleanup uploaded file after database error: %v", cleanupErr)
-		}
 		return nil, fmt.Errorf("failed to save document record: %w", err)
 	}
+	committed = true
+	s.searchCache.Invalidate(userID)
+	metrics.DocumentUploadBytes.Add(float64(file.Size))
 
-	// Update status to ready (business rule)
-	document.Status = models.DocumentStatusReady
-	now := time.Now()
-	document.ProcessedAt = &now
-
-	if err := s.documentRepo.Update(ctx, document); err != nil {
-		logrus.Errorf("Failed to update document status: %v", err)
+	if document.Status == models.DocumentStatusProcessing {
+		s.enqueuePreview(ctx, document)
 	}
 
 	return s.toDocumentResponse(document), nil
@@ -269,13 +490,28 @@ func (s *DocumentService) UpdateDocument(ctx context.Context, userID, documentID
 		return nil, err
 	}
 
-	// Backup old storage paths for cleanup
-	oldStoragePath := existingDocument.StoragePath
+	// Backup old rendition paths for cleanup
 	oldThumbnailPath := existingDocument.ThumbnailPath
+	oldPreviewPath := existingDocument.PreviewPath
 
 	// Keep original for change detection
 	origDocument := *existingDocument
-	var newStoragePath, newThumbnailPath string
+	var newStoragePath string
+
+	// Validate custom metadata before touching storage
+	var customMetadata models.CustomMetadata
+	if req.CustomMetadata != nil {
+		customMetadata, err = s.customFields.ValidateMetadata(ctx, req.CustomMetadata)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Only the owner decides how long the document is kept
+	expiryChanged := req.ExpiresAt != nil || (req.ClearExpiresAt && existingDocument.ExpiresAt != nil)
+	if expiryChanged && existingDocument.UserID != userID {
+		return nil, fmt.Errorf("insufficient access: only the owner can change the expiration")
+	}
 
 	// Handle file update if provided
 	if req.HasNewFile && file != nil {
@@ -285,7 +521,7 @@ func (s *DocumentService) UpdateDocument(ctx context.Context, userID, documentID
 		}
 
 		// Process new file upload
-		newStoragePath, newThumbnailPath, err = s.processFileUpdate(ctx, userID, file, existingDocument)
+		newStoragePath, err = s.processFileUpdate(ctx, userID, file, existingDocument)
 		if err != nil {
 			return nil, err
 		}
@@ -294,39 +530,124 @@ func (s *DocumentService) UpdateDocument(ctx context.Context, userID, documentID
 	// Update metadata fields
 	existingDocument.Title = req.Title
 	existingDocument.Description = req.Description
-	existingDocument.Tags = req.Tags
+	existingDocument.Tags = strings.Join(models.NormalizeTags(req.Tags), ",")
 	existingDocument.IsPublic = req.IsPublic
+	if customMetadata != nil {
+		existingDocument.CustomMetadata = customMetadata
+	}
+	if expiryChanged {
+		// A new expiration gets its own notice
+		existingDocument.ExpiresAt = req.ExpiresAt
+		existingDocument.ExpiryNotifiedAt = nil
+	}
 
-	// Update processing status if new file
-	if req.HasNewFile {
-		existingDocument.Status = models.DocumentStatusReady
+	// New files without preview work are ready immediately
+	if req.HasNewFile && existingDocument.Status != models.DocumentStatusProcessing {
 		now := time.Now()
 		existingDocument.ProcessedAt = &now
 	}
 
-	// Detect changes and handle versioning
+	// Detect changes and handle versioning, the previous state is kept as a revision
 	changes := s.detectChanges(&origDocument, existingDocument, req.HasNewFile)
 	if len(changes) > 0 {
 		existingDocument.Version = existingDocument.Version + 1
+		err = s.documentRepo.UpdateWithRevision(ctx, existingDocument, newRevision(&origDocument, userID, changes, summarizeChanges(changes)))
+	} else {
+		err = s.documentRepo.Update(ctx, existingDocument)
 	}
-
-	// Save via repository
-	if err := s.documentRepo.Update(ctx, existingDocument); err != nil {
+	if err != nil {
 		// Cleanup new files if database update fails
-		s.cleanupFailedUpdate(ctx, newStoragePath, newThumbnailPath)
+		s.cleanupFailedUpdate(ctx, newStoragePath, "")
 		return nil, fmt.Errorf("failed to update document record: %w", err)
 	}
+	s.searchCache.Invalidate(existingDocument.UserID)
+
+	if len(changes) > 0 {
+		s.pruneRevisions(ctx, existingDocument)
+	}
 
-	// Cleanup old files after successful update
+	// The replaced file stays with its revision, only its renditions go
 	if req.HasNewFile {
-		s.cleanupOldFiles(ctx, oldStoragePath, oldThumbnailPath)
+		s.cleanupOldFiles(ctx, "", oldThumbnailPath, oldPreviewPath)
+
+		if existingDocument.Status == models.DocumentStatusProcessing {
+			s.enqueuePreview(ctx, existingDocument)
+		}
 	}
 
 	return s.toDocumentResponse(existingDocument), nil
 }
 
+// Applies the tag and visibility changes of a bulk update to a single document. The version
+// is bumped only when something actually changed.
+func (s *DocumentService) BulkUpdateDocument(ctx context.Context, userID, documentID uuid.UUID, req *types.BulkUpdateDocumentsRequest, clientIP, userAgent string) error {
+	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelEdit)
+	if err != nil {
+		return err
+	}
+	origDocument := *document
+
+	oldTags := models.NormalizeTags(document.Tags)
+	removed := make(map[string]bool)
+	for _, tag := range models.NormalizeTags(strings.Join(req.RemoveTags, ",")) {
+		removed[tag] = true
+	}
+	newTags := []string{}
+	for _, tag := range models.NormalizeTags(document.Tags + "," + strings.Join(req.AddTags, ",")) {
+		if !removed[tag] {
+			newTags = append(newTags, tag)
+		}
+	}
+	if len(newTags) > s.tags.MaxCount() {
+		return fmt.Errorf("document would exceed %d tags", s.tags.MaxCount())
+	}
+
+	document.Tags = strings.Join(newTags, ",")
+	if req.IsPublic != nil {
+		document.IsPublic = *req.IsPublic
+	}
+
+	changes := s.detectChanges(&origDocument, document, false)
+	if len(changes) == 0 {
+		return nil
+	}
+	document.Version = document.Version + 1
+
+	if err := s.documentRepo.UpdateWithRevision(ctx, document, newRevision(&origDocument, userID, changes, summarizeChanges(changes))); err != nil {
+		return fmt.Errorf("failed to update document record: %w", err)
+	}
+	s.searchCache.Invalidate(document.UserID)
+	s.pruneRevisions(ctx, document)
+
+	activityCtx := &ActivityContext{
+		UserID:     userID,
+		DocumentID: documentID,
+		IPAddress:  clientIP,
+		UserAgent:  userAgent,
+		Source:     "web",
+	}
+	if _, ok := changes["tags"]; ok {
+		if err := s.activityService.LogTagUpdate(activityCtx, document, oldTags, newTags); err != nil {
+			logrus.Warnf("Failed to log tag update for document %s: %v", documentID, err)
+		}
+	}
+	if _, ok := changes["isPublic"]; ok {
+		oldPermissions := map[string]interface{}{"isPublic": origDocument.IsPublic}
+		newPermissions := map[string]interface{}{"isPublic": document.IsPublic}
+		if err := s.activityService.LogPermissionChange(activityCtx, document, oldPermissions, newPermissions); err != nil {
+			logrus.Warnf("Failed to log permission change for document %s: %v", documentID, err)
+		}
+	}
+
+	return nil
+}
+
 // Delegates to search service
 func (s *DocumentService) GetDocuments(ctx context.Context, userID uuid.UUID, req *types.DocumentListRequest) (*types.DocumentListResponse, error) {
+	if req.UseCursor {
+		return s.listDocumentsByCursor(ctx, userID, req)
+	}
+
 	// Convert to search request format
 	searchReq := &types.DocumentListRequest{
 		Page:     req.Page,
@@ -335,8 +656,15 @@ func (s *DocumentService) GetDocuments(ctx context.Context, userID uuid.UUID, re
 		FileType: req.FileType,
 		Status:   req.Status,
 		Tags:     req.Tags,
+		Language: req.Language,
 		SortBy:   req.SortBy,
 		SortDir:  req.SortDir,
+
+		FolderID:          req.FolderID,
+		Unfiled:           req.Unfiled,
+		IncludeSubfolders: req.IncludeSubfolders,
+		NoCache:           req.NoCache,
+		Debug:             req.Debug,
 	}
 
 	// Delegate to search service
@@ -346,135 +674,1010 @@ func (s *DocumentService) GetDocuments(ctx context.Context, userID uuid.UUID, re
 	}
 
 	// Convert to response format
-	return s.convertSearchResultToDocumentList(result), nil
+	response := s.convertSearchResultToDocumentList(result)
+	s.markPinned(ctx, userID, response.Documents)
+	if req.Debug {
+		attempts := result.Attempts
+		if attempts == nil {
+			attempts = []types.SearchStrategyAttempt{}
+		}
+		response.Debug = &types.SearchDebug{Strategy: result.Strategy, Attempts: attempts}
+	}
+	return response, nil
 }
 
-// Retrieves single document with access control
-func (s *DocumentService) GetDocument(ctx context.Context, userID, documentID uuid.UUID) (*types.DocumentResponse, error) {
-	// Try to get document with access control
-	document, userAccessLevel, err := s.getDocumentWithAccessLevel(ctx, userID, documentID, models.AccessLevelView)
+// Lists a page of documents after the request's cursor. Only the listing without a search
+// query supports cursors, ordered by creation time. Pins don't reorder cursor pages.
+func (s *DocumentService) listDocumentsByCursor(ctx context.Context, userID uuid.UUID, req *types.DocumentListRequest) (*types.DocumentListResponse, error) {
+	customMetadata, err := s.customFields.ParseFilters(ctx, req.CustomFields)
 	if err != nil {
 		return nil, err
 	}
 
-	// Increment view count via repository
-	if err := s.documentRepo.IncrementViewCount(ctx, documentID); err != nil {
-		logrus.Warnf("Failed to increment view count for document %s: %v", documentID, err)
+	searchReq := &types.SearchRequest{
+		UserID:         userID,
+		Limit:          req.Limit,
+		FileType:       req.FileType,
+		Status:         req.Status,
+		Tags:           req.Tags,
+		Language:       req.Language,
+		CustomMetadata: customMetadata,
+		SortBy:         "date",
+		SortDir:        req.SortDir,
+	}
+	if err := s.resolveFolderFilter(ctx, userID, req, searchReq); err != nil {
+		return nil, err
+	}
+
+	// One extra document tells whether there is a next page
+	documents, err := s.searchRepo.ListAfterCursor(ctx, searchReq, req.Cursor, req.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	var nextCursor string
+	if len(documents) > req.Limit {
+		documents = documents[:req.Limit]
+		last := documents[len(documents)-1]
+		nextCursor = types.DocumentCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
 	}
 
-	return s.toDocumentResponseWithAccess(document, userAccessLevel), nil
+	response := s.convertSearchResultToDocumentList(&types.SearchResult{
+		Documents: documents,
+		Limit:     req.Limit,
+	})
+	s.markPinned(ctx, userID, response.Documents)
+	response.NextCursor = nextCursor
+	return response, nil
 }
 
-// Retrieves only document title
-func (s *DocumentService) GetDocumentTitle(ctx context.Context, userID, documentID uuid.UUID) (string, error) {
-	// Verify access first
-	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView)
-	if err != nil {
-		return "", err
+// Narrows a listing to a folder of the user, with its subfolders when asked
+func (s *DocumentService) resolveFolderFilter(ctx context.Context, userID uuid.UUID, req *types.DocumentListRequest, searchReq *types.SearchRequest) error {
+	if req.FolderID == nil {
+		searchReq.Unfiled = req.Unfiled
+		return nil
 	}
 
-	return document.Title, nil
+	if !req.IncludeSubfolders {
+		if _, err := getUserFolder(ctx, s.db, userID, *req.FolderID); err != nil {
+			return err
+		}
+		searchReq.FolderIDs = []uuid.UUID{*req.FolderID}
+		return nil
+	}
+
+	folderIDs, err := folderSubtreeIDs(ctx, s.db, userID, *req.FolderID)
+	if err != nil {
+		return err
+	}
+	if len(folderIDs) == 0 {
+		return fmt.Errorf("folder not found")
+	}
+	searchReq.FolderIDs = folderIDs
+	return nil
 }
 
-// Handles document deletion
-func (s *DocumentService) DeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error {
-	// Verify ownership (only owners can delete)
+// Files a document of the user in one of their folders, or at the root when folderID is nil
+func (s *DocumentService) MoveDocument(ctx context.Context, userID, documentID uuid.UUID, folderID *uuid.UUID, clientIP, userAgent string) (*types.DocumentResponse, error) {
 	document, err := s.documentRepo.GetByIDAndUserID(ctx, documentID, userID)
 	if err != nil {
-		return fmt.Errorf("document not found or access denied")
+		return nil, fmt.Errorf("document not found or access denied")
 	}
 
-	// Delete from storage first
-	if err := s.minioService.DeleteFile(ctx, document.StoragePath); err != nil {
-		logrus.Errorf("Failed to delete file from storage: %v", err)
-		// Continue with database deletion even if storage deletion fails
+	var newFolder string
+	if folderID != nil {
+		folder, err := getUserFolder(ctx, s.db, userID, *folderID)
+		if err != nil {
+			return nil, err
+		}
+		newFolder = folder.Name
 	}
 
-	// Delete thumbnail if exists
-	if document.HasThumbnail && document.ThumbnailPath != "" {
-		if err := s.minioService.DeleteFile(ctx, document.ThumbnailPath); err != nil {
-			logrus.Errorf("Failed to delete thumbnail from storage: %v", err)
+	oldFolderID := document.FolderID
+	if (oldFolderID == nil && folderID == nil) || (oldFolderID != nil && folderID != nil && *oldFolderID == *folderID) {
+		return s.toDocumentResponse(document), nil
+	}
+
+	var oldFolder string
+	if oldFolderID != nil {
+		if folder, err := getUserFolder(ctx, s.db, userID, *oldFolderID); err == nil {
+			oldFolder = folder.Name
 		}
 	}
 
-	// Delete from database via repository
-	if err := s.documentRepo.Delete(ctx, documentID); err != nil {
-		return fmt.Errorf("failed to delete document from database: %w", err)
+	if err := s.documentRepo.MoveToFolder(ctx, documentID, folderID); err != nil {
+		return nil, err
 	}
+	document.FolderID = folderID
+	s.searchCache.Invalidate(userID)
 
-	return nil
+	activityCtx := &ActivityContext{
+		UserID:     userID,
+		DocumentID: documentID,
+		IPAddress:  clientIP,
+		UserAgent:  userAgent,
+		Source:     "web",
+	}
+	if err := s.activityService.LogMove(activityCtx, document, oldFolderID, oldFolder, newFolder); err != nil {
+		logrus.Warnf("Failed to log move of document %s: %v", documentID, err)
+	}
+
+	return s.toDocumentResponse(document), nil
 }
 
-// Prepares document for download
-func (s *DocumentService) DownloadDocument(ctx context.Context, userID, documentID uuid.UUID) (*models.Document, error) {
-	// Get document with download access
-	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelDownload)
+// Copies a document the user can download into a new document they own. The file and its
+// renditions are copied in storage, so the copy shares nothing with the source and starts
+// over at version 1. Counters, sharing and revisions are not copied.
+func (s *DocumentService) CopyDocument(ctx context.Context, userID, documentID uuid.UUID, clientIP, userAgent string) (*types.DocumentResponse, error) {
+	source, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelDownload)
 	if err != nil {
 		return nil, err
 	}
 
-	// Increment download count via repository
-	if err := s.documentRepo.IncrementDownloadCount(ctx, documentID); err != nil {
-		logrus.Warnf("Failed to increment download count for document %s: %v", documentID, err)
+	fileName := uuid.New().String() + filepath.Ext(source.OriginalFileName)
+	objectName := fmt.Sprintf("users/%s/documents/%s", userID, fileName)
+
+	// Every object copied so far, removed again when a later step fails. Removal outlives the
+	// request so a client that disconnects mid-copy doesn't leave the objects behind.
+	var copied []string
+	cleanup := func() {
+		cleanupCtx := context.WithoutCancel(ctx)
+		for _, object := range copied {
+			if err := s.minioService.DeleteFile(cleanupCtx, object); err != nil {
+				logrus.Errorf("Failed to clean up copied object %s: %v", object, err)
+			}
+		}
 	}
 
-	return document, nil
+	if err := s.minioService.CopyFile(ctx, source.StoragePath, objectName); err != nil {
+		return nil, fmt.Errorf("failed to copy document file in storage: %w", err)
+	}
+	copied = append(copied, objectName)
+
+	// Renditions follow the worker's naming so reprocessing the copy overwrites them in place
+	baseName := strings.TrimSuffix(objectName, filepath.Ext(objectName))
+	var thumbnailPath, previewPath string
+	if source.HasThumbnail && source.ThumbnailPath != "" {
+		thumbnailPath = fmt.Sprintf("thumbnails/%s.jpg", baseName)
+		if err := s.minioService.CopyFile(ctx, source.ThumbnailPath, thumbnailPath); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to copy document thumbnail in storage: %w", err)
+		}
+		copied = append(copied, thumbnailPath)
+	}
+	if source.PreviewPath != "" {
+		previewPath = fmt.Sprintf("previews/%s.pdf", baseName)
+		if err := s.minioService.CopyFile(ctx, source.PreviewPath, previewPath); err != nil {
+			cleanup()
+			return nil, fmt.Errorf("failed to copy document preview in storage: %w", err)
+		}
+		copied = append(copied, previewPath)
+	}
+
+	title := utils.TruncateUTF8(source.Title, utils.MaxTitleLength-len(" (copy)")) + " (copy)"
+	now := time.Now()
+	document := &models.Document{
+		Title:            title,
+		Description:      source.Description,
+		FileName:         fileName,
+		OriginalFileName: source.OriginalFileName,
+		FileSize:         source.FileSize,
+		FileType:         source.FileType,
+		MimeType:         source.MimeType,
+		Status:           source.Status,
+		StoragePath:      objectName,
+		StorageBucket:    source.StorageBucket,
+		ThumbnailPath:    thumbnailPath,
+		HasThumbnail:     thumbnailPath != "",
+		PreviewPath:      previewPath,
+		ContentHash:      source.ContentHash,
+		ContentText:      source.ContentText,
+		ContentPages:     source.ContentPages,
+		OCRLanguage:      source.OCRLanguage,
+		OCRApplied:       source.OCRApplied,
+		PageCount:        source.PageCount,
+		Summary:          source.Summary,
+		ProcessedAt:      &now,
+		ProcessingError:  source.ProcessingError,
+		ScanStatus:       source.ScanStatus,
+		ScanSignature:    source.ScanSignature,
+		ScannedAt:        source.ScannedAt,
+		Tags:             source.Tags,
+		Language:         source.Language,
+		CustomMetadata:   source.CustomMetadata,
+		UserID:           userID,
+		Version:          1,
+	}
+	// Folders belong to the owner, a copy of someone else's document starts at the root
+	if source.UserID == userID {
+		document.FolderID = source.FolderID
+	}
+	// A copy of a document still being processed is processed on its own
+	if source.Status == models.DocumentStatusProcessing {
+		document.ProcessedAt = nil
+	}
+
+	if err := s.documentRepo.Create(ctx, document); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to save document record: %w", err)
+	}
+	s.searchCache.Invalidate(document.UserID)
+
+	if document.Status == models.DocumentStatusProcessing {
+		s.enqueuePreview(ctx, document)
+	}
+
+	activityCtx := &ActivityContext{
+		UserID:     userID,
+		DocumentID: source.ID,
+		IPAddress:  clientIP,
+		UserAgent:  userAgent,
+		Source:     "web",
+	}
+	if err := s.activityService.LogCopy(activityCtx, source, document); err != nil {
+		logrus.Warnf("Failed to log copy of document %s: %v", source.ID, err)
+	}
+
+	return s.toDocumentResponse(document), nil
 }
 
-// Retrieves document version history
-func (s *DocumentService) GetDocumentRevisions(ctx context.Context, userID, documentID uuid.UUID) ([]models.DocumentRevision, error) {
-	// Verify access first
-	_, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView)
+// Retrieves single document with access control
+func (s *DocumentService) GetDocument(ctx context.Context, userID, documentID uuid.UUID, clientIP, userAgent string) (*types.DocumentResponse, error) {
+	// Try to get document with access control
+	document, userAccessLevel, err := s.getDocumentWithAccessLevel(ctx, userID, documentID, models.AccessLevelView)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get revisions via repository
-	revisions, err := s.documentRepo.GetRevisions(ctx, documentID)
+	// Increment view count, buffered to avoid row contention on popular documents. Views are
+	// logged as activity under the same dedup window, for the user's recent documents.
+	counted, err := s.counter.IncrementView(ctx, documentID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch revisions: %w", err)
+		logrus.Warnf("Failed to increment view count for document %s: %v", documentID, err)
+	}
+	if counted {
+		activityCtx := &ActivityContext{
+			UserID:     userID,
+			DocumentID: documentID,
+			IPAddress:  clientIP,
+			UserAgent:  userAgent,
+			Source:     "web",
+		}
+		if err := s.activityService.LogDocumentView(activityCtx, document, 0); err != nil {
+			logrus.Warnf("Failed to log view of document %s: %v", documentID, err)
+		}
 	}
 
-	return revisions, nil
+	response := s.toDocumentResponseWithAccess(document, userAccessLevel)
+	response.IsPinned = s.pinnedDocumentIDs(ctx, userID, []uuid.UUID{documentID})[documentID]
+	return response, nil
 }
 
-// Retrieves user document statistics
-func (s *DocumentService) GetUserStats(ctx context.Context, userID uuid.UUID) (*types.UserStatsResponse, error) {
-	stats, err := s.documentRepo.GetUserStats(ctx, userID)
+// Lists the documents the user viewed or downloaded most recently, one entry per document.
+// Documents the user can no longer access are left out.
+func (s *DocumentService) GetRecentDocuments(ctx context.Context, userID uuid.UUID, limit int) (*types.RecentDocumentsResponse, error) {
+	recent, err := s.documentRepo.ListRecentlyAccessed(ctx, userID, limit)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert from fts.UserStatsResponse to services.UserStatsResponse
-	return &types.UserStatsResponse{
-		DocumentsThisMonth: stats.DocumentsThisMonth,
-		TotalStorageUsage:  stats.TotalStorageUsage,
-	}, nil
-}
+	items := make([]types.RecentDocumentItem, 0, len(recent))
+	for i := range recent {
+		accessLevel := "owner"
+		if recent[i].Document.UserID != userID && s.userShareService != nil {
+			level, err := s.userShareService.GetUserAccessLevel(ctx, userID, recent[i].Document.ID)
+			if err != nil || level == "" {
+				continue
+			}
+			accessLevel = level
+		}
 
-// Retrieves document model for internal use
-func (s *DocumentService) GetDocumentModel(ctx context.Context, userID, documentID uuid.UUID, document *models.Document) error {
-	doc, err := s.documentRepo.GetByIDAndUserID(ctx, documentID, userID)
-	if err != nil {
-		return err
+		items = append(items, types.RecentDocumentItem{
+			Document:   *s.toDocumentResponseWithAccess(&recent[i].Document, accessLevel),
+			AccessType: recent[i].AccessType,
+			AccessedAt: recent[i].AccessedAt,
+		})
 	}
-	*document = *doc
-	return nil
+
+	return &types.RecentDocumentsResponse{Documents: items}, nil
 }
 
-// Retrieves document with access control
-func (s *DocumentService) getDocumentWithAccess(ctx context.Context, userID, documentID uuid.UUID, requiredAccess models.AccessLevel) (*models.Document, error) {
-	// Try to get as owner first
-	document, err := s.documentRepo.GetByIDAndUserID(ctx, documentID, userID)
-	if err == nil {
-		return document, nil
+// Retrieves only document title
+func (s *DocumentService) GetDocumentTitle(ctx context.Context, userID, documentID uuid.UUID) (string, error) {
+	// Verify access first
+	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView)
+	if err != nil {
+		return "", err
 	}
 
-	// Check if document exists at all
+	return document.Title, nil
+}
+
+// Returns the extracted text of a document the user can view
+func (s *DocumentService) GetDocumentContent(ctx context.Context, userID, documentID uuid.UUID) (*types.DocumentContentResponse, error) {
+	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView)
+	if err != nil {
+		return nil, err
+	}
+
+	if document.ContentText == nil {
+		// The worker or the backfill fills it in later
+		if document.Status != models.DocumentStatusFailed && s.textExtractor.CanExtract(document.FileType) {
+			return nil, fmt.Errorf("document content is not processed yet")
+		}
+		return nil, fmt.Errorf("document content is not available")
+	}
+
+	content := &types.DocumentContentResponse{
+		DocumentID: document.ID,
+		Content:    *document.ContentText,
+		Length:     utf8.RuneCountInString(*document.ContentText),
+	}
+	for i, offset := range document.ContentPages {
+		content.Pages = append(content.Pages, types.DocumentContentPage{Page: i + 1, Offset: offset})
+	}
+
+	return content, nil
+}
+
+// Moves a document to the trash, stored files are kept until it is purged
+func (s *DocumentService) DeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error {
+	// Verify ownership (only owners can delete)
+	if _, err := s.documentRepo.GetByIDAndUserID(ctx, documentID, userID); err != nil {
+		return fmt.Errorf("document not found or access denied")
+	}
+
+	if err := s.documentRepo.Trash(ctx, documentID); err != nil {
+		return fmt.Errorf("failed to move document to trash: %w", err)
+	}
+	s.searchCache.Invalidate(userID)
+
+	return nil
+}
+
+// Lists the user's trashed documents, most recently deleted first
+func (s *DocumentService) GetTrash(ctx context.Context, userID uuid.UUID, page, limit int) (*types.DocumentListResponse, error) {
+	documents, total, err := s.documentRepo.ListTrashed(ctx, userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.convertSearchResultToDocumentList(&types.SearchResult{
+		Documents:  documents,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	}), nil
+}
+
+// Lists documents the user is responsible for reviewing, oldest unresolved comment first
+func (s *DocumentService) GetReviewQueue(ctx context.Context, userID uuid.UUID, page, limit int) (*types.ReviewQueueResponse, error) {
+	reviews, total, err := s.documentRepo.ListPendingReview(ctx, userID, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]types.ReviewQueueItem, 0, len(reviews))
+	for i := range reviews {
+		accessLevel := "owner"
+		if reviews[i].Document.UserID != userID {
+			accessLevel = string(models.AccessLevelEdit)
+		}
+
+		items = append(items, types.ReviewQueueItem{
+			Document:           *s.toDocumentResponseWithAccess(&reviews[i].Document, accessLevel),
+			UnresolvedComments: reviews[i].UnresolvedCount,
+			OldestUnresolvedAt: reviews[i].OldestUnresolvedAt,
+		})
+	}
+
+	return &types.ReviewQueueResponse{
+		Documents:  items,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	}, nil
+}
+
+// Restores a trashed document
+func (s *DocumentService) RestoreDocument(ctx context.Context, userID, documentID uuid.UUID) (*types.DocumentResponse, error) {
+	if _, err := s.documentRepo.GetTrashedByIDAndUserID(ctx, documentID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.documentRepo.Restore(ctx, documentID); err != nil {
+		return nil, fmt.Errorf("failed to restore document: %w", err)
+	}
+	s.searchCache.Invalidate(userID)
+
+	document, err := s.documentRepo.GetByIDAndUserID(ctx, documentID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toDocumentResponse(document), nil
+}
+
+// Permanently deletes a document and its stored files, trashed or not
+func (s *DocumentService) PermanentlyDeleteDocument(ctx context.Context, userID, documentID uuid.UUID) error {
+	document, err := s.documentRepo.GetByIDAndUserIDWithTrashed(ctx, documentID, userID)
+	if err != nil {
+		return fmt.Errorf("document not found or access denied")
+	}
+
+	return s.purgeDocument(ctx, document)
+}
+
+// Permanently deletes trashed documents older than the retention period. A document that
+// fails is logged and left for the next sweep, only failing to list the trash is an error.
+func (s *DocumentService) PurgeExpiredTrash(ctx context.Context, retention time.Duration) (int, error) {
+	cutoff := time.Now().Add(-retention)
+	purged := 0
+
+	// Documents that fail stay in the trash, the cursor keeps them from being retried in this sweep
+	lastID := uuid.Nil
+	for {
+		if ctx.Err() != nil {
+			return purged, ctx.Err()
+		}
+
+		documents, err := s.documentRepo.ListTrashedBefore(ctx, cutoff, lastID, trashPurgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		if len(documents) == 0 {
+			return purged, nil
+		}
+
+		for i := range documents {
+			if err := s.purgeDocument(ctx, &documents[i]); err != nil {
+				logrus.Errorf("[TRASH] Failed to purge document %s: %v", documents[i].ID, err)
+				continue
+			}
+			purged++
+		}
+		lastID = documents[len(documents)-1].ID
+	}
+}
+
+// Runs PurgeExpiredTrash periodically until the context is cancelled
+func (s *DocumentService) StartTrashSweeper(ctx context.Context, interval, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purged, err := s.PurgeExpiredTrash(ctx, retention)
+				if err != nil {
+					logrus.Errorf("[TRASH] Failed to purge expired trash: %v", err)
+				}
+				if purged > 0 {
+					logrus.Infof("[TRASH] Permanently deleted %d expired documents", purged)
+				}
+			}
+		}
+	}()
+}
+
+// Removes stored files then the database record
+func (s *DocumentService) purgeDocument(ctx context.Context, document *models.Document) error {
+	if err := s.minioService.DeleteFile(ctx, document.StoragePath); err != nil {
+		logrus.Errorf("Failed to delete file from storage: %v", err)
+		// Continue with database deletion even if storage deletion fails
+	}
+
+	// Delete thumbnail if exists
+	if document.HasThumbnail && document.ThumbnailPath != "" {
+		if err := s.minioService.DeleteFile(ctx, document.ThumbnailPath); err != nil {
+			logrus.Errorf("Failed to delete thumbnail from storage: %v", err)
+		}
+		s.deleteThumbnailVariants(ctx, document.ThumbnailPath)
+	}
+
+	if document.PreviewPath != "" {
+		if err := s.minioService.DeleteFile(ctx, document.PreviewPath); err != nil {
+			logrus.Errorf("Failed to delete preview from storage: %v", err)
+		}
+	}
+
+	// Files kept for earlier versions
+	revisions, err := s.documentRepo.GetRevisions(ctx, document.ID)
+	if err != nil {
+		logrus.Errorf("Failed to list revisions of document %s: %v", document.ID, err)
+	}
+	for _, revision := range revisions {
+		if !revision.HasFile() || revision.StoragePath == document.StoragePath {
+			continue
+		}
+		if err := s.minioService.DeleteFile(ctx, revision.StoragePath); err != nil {
+			logrus.Errorf("Failed to delete revision file from storage: %v", err)
+		}
+	}
+
+	if err := s.documentRepo.Purge(ctx, document.ID); err != nil {
+		return fmt.Errorf("failed to delete document from database: %w", err)
+	}
+	s.searchCache.Invalidate(document.UserID)
+
+	return nil
+}
+
+// Prepares document for download
+func (s *DocumentService) DownloadDocument(ctx context.Context, userID, documentID uuid.UUID, clientIP, userAgent string) (*models.Document, error) {
+	// Get document with download access
+	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelDownload)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckScanned(document); err != nil {
+		return nil, err
+	}
+
+	// Increment download count, buffered to avoid row contention on popular documents
+	if err := s.counter.IncrementDownload(ctx, documentID); err != nil {
+		logrus.Warnf("Failed to increment download count for document %s: %v", documentID, err)
+	}
+
+	activityCtx := &ActivityContext{
+		UserID:     userID,
+		DocumentID: documentID,
+		IPAddress:  clientIP,
+		UserAgent:  userAgent,
+		Source:     "web",
+	}
+	if err := s.activityService.LogDocumentDownload(activityCtx, document); err != nil {
+		logrus.Warnf("Failed to log download of document %s: %v", documentID, err)
+	}
+
+	return document, nil
+}
+
+// Counts a download against the limit of the share it goes through, owners download freely.
+// Returns the share charged so the download can be released if the file can't be sent,
+// uuid.Nil when nothing was charged.
+func (s *DocumentService) ChargeShareDownload(ctx context.Context, userID uuid.UUID, document *models.Document) (uuid.UUID, error) {
+	if document.UserID == userID || s.userShareService == nil {
+		return uuid.Nil, nil
+	}
+	return s.userShareService.RecordShareDownload(ctx, userID, document.ID)
+}
+
+// Checks that a share the user downloads through has downloads left without counting one,
+// owners download freely
+func (s *DocumentService) CheckShareDownload(ctx context.Context, userID uuid.UUID, document *models.Document) error {
+	if document.UserID == userID || s.userShareService == nil {
+		return nil
+	}
+	return s.userShareService.CheckShareDownload(ctx, userID, document.ID)
+}
+
+// Gives back a download charged by ChargeShareDownload, failures are only logged
+func (s *DocumentService) ReleaseShareDownload(ctx context.Context, shareID uuid.UUID) {
+	if shareID == uuid.Nil {
+		return
+	}
+	if err := s.userShareService.ReleaseShareDownload(ctx, shareID); err != nil {
+		logrus.Warnf("Failed to release download of share %s: %v", shareID, err)
+	}
+}
+
+// Reports whether a download of the document should be checked against its recorded hash.
+// Small files always are, hashing large ones costs enough that it has to be asked for.
+func (s *DocumentService) ShouldVerifyDownload(document *models.Document, requested bool) bool {
+	if document.ContentHash == "" {
+		return false
+	}
+	return requested || document.FileSize <= s.verifyMaxBytes
+}
+
+// Reads the stored file once and compares it with the hash and size recorded at upload, so a
+// corrupted file is caught before any of it is sent
+func (s *DocumentService) VerifyStoredFile(ctx context.Context, document *models.Document) error {
+	hash, size, err := s.hashStoredFile(ctx, document.StoragePath)
+	if err != nil {
+		return err
+	}
+	if hash != document.ContentHash || size != document.FileSize {
+		logrus.Errorf("[INTEGRITY] Document %s at %s has hash %s and size %d, expected %s and %d",
+			document.ID, document.StoragePath, hash, size, document.ContentHash, document.FileSize)
+		return fmt.Errorf("content hash mismatch")
+	}
+	return nil
+}
+
+func (s *DocumentService) hashStoredFile(ctx context.Context, objectName string) (string, int64, error) {
+	reader, err := s.minioService.DownloadFile(ctx, objectName)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read file from storage: %w", err)
+	}
+	defer reader.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, reader)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to read file from storage: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), size, nil
+}
+
+// Re-reads the stored file and compares its hash and size with the ones recorded at upload
+func (s *DocumentService) VerifyDocument(ctx context.Context, userID, documentID uuid.UUID) (*types.DocumentIntegrityResponse, error) {
+	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelDownload)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &types.DocumentIntegrityResponse{
+		DocumentID:   document.ID,
+		ExpectedHash: document.ContentHash,
+		ExpectedSize: document.FileSize,
+	}
+
+	hash, size, err := s.hashStoredFile(ctx, document.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+	result.ActualHash = hash
+	result.ActualSize = size
+	result.CheckedAt = time.Now()
+
+	switch {
+	case document.ContentHash == "":
+		result.Status = types.IntegrityStatusUnverified
+	case result.ActualHash != document.ContentHash || size != document.FileSize:
+		result.Status = types.IntegrityStatusMismatch
+		logrus.Errorf("[INTEGRITY] Document %s at %s has hash %s and size %d, expected %s and %d",
+			document.ID, document.StoragePath, result.ActualHash, size, document.ContentHash, document.FileSize)
+	default:
+		result.Status = types.IntegrityStatusOK
+	}
+
+	return result, nil
+}
+
+// Snapshots the document as it is before changes are applied
+func newRevision(document *models.Document, changedBy uuid.UUID, changes map[string]interface{}, summary string) *models.DocumentRevision {
+	fields := make(models.ChangedFields, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	return &models.DocumentRevision{
+		DocumentID:       document.ID,
+		Version:          document.Version,
+		ChangedBy:        changedBy,
+		ChangeSummary:    summary,
+		ChangedFields:    fields,
+		StoragePath:      document.StoragePath,
+		FileName:         document.FileName,
+		OriginalFileName: document.OriginalFileName,
+		FileSize:         document.FileSize,
+		FileType:         document.FileType,
+		MimeType:         document.MimeType,
+		ContentHash:      document.ContentHash,
+		Title:            document.Title,
+		Description:      document.Description,
+		Tags:             document.Tags,
+		ContentText:      document.ContentText,
+	}
+}
+
+// Describes the output of detectChanges in a sentence, e.g. "Changed file, tags and title"
+func summarizeChanges(changes map[string]interface{}) string {
+	labels := map[string]string{"isPublic": "visibility", "customMetadata": "custom metadata"}
+
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		if label, ok := labels[field]; ok {
+			field = label
+		}
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	if len(fields) == 1 {
+		return "Changed " + fields[0]
+	}
+	return "Changed " + strings.Join(fields[:len(fields)-1], ", ") + " and " + fields[len(fields)-1]
+}
+
+// Drops revisions beyond the configured limit and deletes their files unless the document or
+// a remaining revision still points at them
+func (s *DocumentService) pruneRevisions(ctx context.Context, document *models.Document) {
+	if s.maxRevisions <= 0 {
+		return
+	}
+
+	pruned, err := s.documentRepo.PruneRevisions(ctx, document.ID, s.maxRevisions)
+	if err != nil {
+		logrus.Warnf("Failed to prune revisions of document %s: %v", document.ID, err)
+		return
+	}
+	if len(pruned) == 0 {
+		return
+	}
+
+	// Keep the files when in doubt, an orphaned object is cheaper than a broken revision
+	remaining, err := s.documentRepo.GetRevisions(ctx, document.ID)
+	if err != nil {
+		logrus.Warnf("Failed to list revisions of document %s, keeping pruned files: %v", document.ID, err)
+		return
+	}
+	referenced := map[string]bool{document.StoragePath: true}
+	for _, revision := range remaining {
+		referenced[revision.StoragePath] = true
+	}
+
+	for _, revision := range pruned {
+		if !revision.HasFile() || referenced[revision.StoragePath] {
+			continue
+		}
+		referenced[revision.StoragePath] = true
+		if err := s.minioService.DeleteFile(ctx, revision.StoragePath); err != nil {
+			logrus.Errorf("Failed to delete pruned revision file from storage: %v", err)
+		}
+	}
+}
+
+// Makes the file of an earlier revision the current version. The current file is kept as a
+// new revision first, so a restore can itself be undone.
+func (s *DocumentService) RestoreRevision(ctx context.Context, userID, documentID, revisionID uuid.UUID, clientIP, userAgent string) (*types.DocumentResponse, error) {
+	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.documentRepo.GetRevision(ctx, documentID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if !revision.HasFile() {
+		return nil, fmt.Errorf("revision has no stored file")
+	}
+
+	exists, err := s.minioService.FileExists(ctx, revision.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check revision file in storage: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("revision file no longer available")
+	}
+
+	// Copy rather than reuse the object so the revision stays restorable on its own
+	fileName := uuid.New().String() + filepath.Ext(revision.OriginalFileName)
+	objectName := fmt.Sprintf("users/%s/documents/%s", document.UserID, fileName)
+	if err := s.minioService.CopyFile(ctx, revision.StoragePath, objectName); err != nil {
+		return nil, fmt.Errorf("failed to copy revision file in storage: %w", err)
+	}
+
+	changes := map[string]interface{}{"file": fmt.Sprintf("restored version %d", revision.Version)}
+	current := newRevision(document, userID, changes, fmt.Sprintf("Replaced by restoring version %d", revision.Version))
+
+	oldThumbnailPath := document.ThumbnailPath
+	oldPreviewPath := document.PreviewPath
+
+	document.StoragePath = objectName
+	document.FileName = fileName
+	document.OriginalFileName = revision.OriginalFileName
+	document.FileSize = revision.FileSize
+	document.FileType = revision.FileType
+	document.MimeType = revision.MimeType
+	document.ContentHash = revision.ContentHash
+	document.Version = document.Version + 1
+
+	// Renditions of the replaced file no longer apply, the worker regenerates them
+	document.PageCount = nil
+	document.ThumbnailPath = ""
+	document.HasThumbnail = false
+	document.PreviewPath = ""
+	document.ContentText = nil
+	document.ContentPages = nil
+	document.ProcessingError = ""
+	document.Status = models.DocumentStatusReady
+	if s.needsProcessing(document.FileType) {
+		document.Status = models.DocumentStatusProcessing
+	}
+	s.resetScan(document)
+
+	if err := s.documentRepo.UpdateWithRevision(ctx, document, current); err != nil {
+		s.cleanupFailedUpdate(ctx, objectName, "")
+		return nil, fmt.Errorf("failed to update document record: %w", err)
+	}
+	s.searchCache.Invalidate(document.UserID)
+
+	s.pruneRevisions(ctx, document)
+	s.cleanupOldFiles(ctx, "", oldThumbnailPath, oldPreviewPath)
+	if document.Status == models.DocumentStatusProcessing {
+		s.enqueuePreview(ctx, document)
+	}
+
+	activityCtx := &ActivityContext{
+		UserID:     userID,
+		DocumentID: documentID,
+		IPAddress:  clientIP,
+		UserAgent:  userAgent,
+		Source:     "web",
+	}
+	if err := s.activityService.LogRevisionRestore(activityCtx, document, revision.Version); err != nil {
+		logrus.Warnf("Failed to log revision restore for document %s: %v", documentID, err)
+	}
+
+	return s.toDocumentResponse(document), nil
+}
+
+// Retrieves document version history
+func (s *DocumentService) GetDocumentRevisions(ctx context.Context, userID, documentID uuid.UUID) ([]models.DocumentRevision, error) {
+	// Verify access first
+	_, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get revisions via repository
+	revisions, err := s.documentRepo.GetRevisions(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// Compares two versions of a document, toID is nil for the current version. The text diff is
+// left out when either version has no extracted text, so binary files compare by metadata only.
+func (s *DocumentService) GetRevisionDiff(ctx context.Context, userID, documentID, fromID uuid.UUID, toID *uuid.UUID) (*types.RevisionDiffResponse, error) {
+	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.documentRepo.GetRevision(ctx, documentID, fromID)
+	if err != nil {
+		return nil, err
+	}
+	fromRef := types.RevisionRef{RevisionID: &from.ID, Version: from.Version}
+
+	// The current version is compared through the revision it would become
+	to := newRevision(document, userID, nil, "")
+	toRef := types.RevisionRef{Version: document.Version}
+	if toID != nil {
+		if to, err = s.documentRepo.GetRevision(ctx, documentID, *toID); err != nil {
+			return nil, err
+		}
+		toRef.RevisionID = &to.ID
+		toRef.Version = to.Version
+	}
+
+	result := &types.RevisionDiffResponse{
+		From:          fromRef,
+		To:            toRef,
+		ChangedFields: []string{},
+		FileChanged:   from.ContentHash != to.ContentHash || from.StoragePath != to.StoragePath,
+	}
+
+	// A revision records the changes that took its version to the next one
+	revisions, err := s.documentRepo.GetRevisions(ctx, documentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch revisions: %w", err)
+	}
+	low, high := min(from.Version, to.Version), max(from.Version, to.Version)
+	for _, revision := range revisions {
+		if revision.Version < low || revision.Version >= high {
+			continue
+		}
+		for _, field := range revision.ChangedFields {
+			if !slices.Contains(result.ChangedFields, field) {
+				result.ChangedFields = append(result.ChangedFields, field)
+			}
+		}
+	}
+	sort.Strings(result.ChangedFields)
+
+	if from.HasSnapshot() && to.HasSnapshot() {
+		result.Metadata = []types.FieldChange{}
+		for _, field := range []struct{ name, old, new string }{
+			{"title", from.Title, to.Title},
+			{"description", from.Description, to.Description},
+			{"tags", from.Tags, to.Tags},
+		} {
+			if field.old != field.new {
+				result.Metadata = append(result.Metadata, types.FieldChange{Field: field.name, Old: field.old, New: field.new})
+			}
+		}
+	}
+
+	if from.ContentText != nil && to.ContentText != nil {
+		result.HasContentDiff = true
+		result.ContentDiff = utils.UnifiedDiff(
+			fmt.Sprintf("version %d", from.Version),
+			fmt.Sprintf("version %d", to.Version),
+			*from.ContentText,
+			*to.ContentText,
+		)
+	}
+
+	return result, nil
+}
+
+// Retrieves user document statistics
+func (s *DocumentService) GetUserStats(ctx context.Context, userID uuid.UUID) (*types.UserStatsResponse, error) {
+	stats, err := s.documentRepo.GetUserStats(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert from fts.UserStatsResponse to services.UserStatsResponse
+	return &types.UserStatsResponse{
+		DocumentsThisMonth: stats.DocumentsThisMonth,
+		TotalStorageUsage:  stats.TotalStorageUsage,
+	}, nil
+}
+
+// Breaks the user's storage down by file type with a monthly upload timeline
+func (s *DocumentService) GetStorageBreakdown(ctx context.Context, userID uuid.UUID) (*types.StorageBreakdownResponse, error) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -(storageTimelineMonths - 1), 0)
+
+	breakdown, err := s.documentRepo.GetStorageBreakdown(ctx, userID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fill in months without uploads so the timeline always has the same length
+	months := make(map[string]types.MonthlyUploads, len(breakdown.Timeline))
+	for _, month := range breakdown.Timeline {
+		months[month.Month] = month
+	}
+	timeline := make([]types.MonthlyUploads, 0, storageTimelineMonths)
+	for i := 0; i < storageTimelineMonths; i++ {
+		key := from.AddDate(0, i, 0).Format("2006-01")
+		month, ok := months[key]
+		if !ok {
+			month = types.MonthlyUploads{Month: key}
+		}
+		timeline = append(timeline, month)
+	}
+	breakdown.Timeline = timeline
+
+	return breakdown, nil
+}
+
+// Retrieves the document model for previews and thumbnails, for the owner and for users with
+// at least view access through a share. Unlike GetDocument it records no activity.
+func (s *DocumentService) GetDocumentModel(ctx context.Context, userID, documentID uuid.UUID, document *models.Document) error {
+	doc, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView)
+	if err != nil {
+		return err
+	}
+	*document = *doc
+	return nil
+}
+
+// Retrieves document with access control
+func (s *DocumentService) getDocumentWithAccess(ctx context.Context, userID, documentID uuid.UUID, requiredAccess models.AccessLevel) (*models.Document, error) {
+	// Try to get as owner first
+	document, err := s.documentRepo.GetByIDAndUserID(ctx, documentID, userID)
+	if err == nil {
+		if document.IsExpired() {
+			return nil, fmt.Errorf("document expired")
+		}
+		return document, nil
+	}
+
+	// Check if document exists at all
 	document, err = s.documentRepo.GetByID(ctx, documentID)
 	if err != nil {
 		return nil, fmt.Errorf("document not found")
 	}
+	if document.IsExpired() {
+		return nil, fmt.Errorf("document expired")
+	}
 
 	// Check shared access if UserShareService is available
 	if s.userShareService != nil {
@@ -497,6 +1700,9 @@ func (s *DocumentService) getDocumentWithAccessLevel(ctx context.Context, userID
 	// Try to get as owner first
 	document, err := s.documentRepo.GetByIDAndUserID(ctx, documentID, userID)
 	if err == nil {
+		if document.IsExpired() {
+			return nil, "", fmt.Errorf("document expired")
+		}
 		return document, "owner", nil
 	}
 
@@ -505,6 +1711,9 @@ func (s *DocumentService) getDocumentWithAccessLevel(ctx context.Context, userID
 	if err != nil {
 		return nil, "", fmt.Errorf("document not found")
 	}
+	if document.IsExpired() {
+		return nil, "", fmt.Errorf("document expired")
+	}
 
 	if s.userShareService != nil {
 		accessLevel, err := s.userShareService.GetUserAccessLevel(ctx, userID, documentID)
@@ -523,33 +1732,22 @@ func (s *DocumentService) getDocumentWithAccessLevel(ctx context.Context, userID
 	return nil, "", fmt.Errorf("document not found or access denied")
 }
 
-// Checks if user access level meets requirement
+// Checks if user access level meets requirement, in the order user shares grant them. Owners
+// have every level, unknown levels grant nothing.
 func (s *DocumentService) hasRequiredAccess(userAccess string, required models.AccessLevel) bool {
-	accessLevels := map[string]int{
-		"download": 1,
-		"view":     2,
-		"edit":     3,
-		"owner":    4,
+	if userAccess == "owner" {
+		return true
 	}
-
-	requiredLevels := map[models.AccessLevel]int{
-		models.AccessLevelDownload: 1,
-		models.AccessLevelView:     2,
-		models.AccessLevelEdit:     3,
-	}
-
-	userLevel := accessLevels[userAccess]
-	requiredLevel := requiredLevels[required]
-
-	return userLevel >= requiredLevel
+	userLevel := models.AccessLevel(userAccess).Rank()
+	return userLevel > 0 && userLevel >= required.Rank()
 }
 
 // Handles new file upload during update
-func (s *DocumentService) processFileUpdate(ctx context.Context, userID uuid.UUID, file *multipart.FileHeader, document *models.Document) (string, string, error) {
+func (s *DocumentService) processFileUpdate(ctx context.Context, userID uuid.UUID, file *multipart.FileHeader, document *models.Document) (string, error) {
 	// Open file
 	src, err := file.Open()
 	if err != nil {
-		return "", "", fmt.Errorf("failed to open new file: %w", err)
+		return "", fmt.Errorf("failed to open new file: %w", err)
 	}
 	defer src.Close()
 
@@ -561,13 +1759,15 @@ func (s *DocumentService) processFileUpdate(ctx context.Context, userID uuid.UUI
 
 	// Upload to MinIO
 	contentType := file.Header.Get("Content-Type")
-	bucketName := s.minioService.config.BucketName
-	if err := s.minioService.UploadFile(ctx, bucketName, objectName, src, file.Size, contentType); err != nil {
-		return "", "", fmt.Errorf("failed to upload new file to storage: %w", err)
+	bucketName := s.minioService.BucketName()
+	hasher := sha256.New()
+	if err := s.minioService.UploadFile(ctx, bucketName, objectName, io.TeeReader(src, hasher), file.Size, contentType); err != nil {
+		return "", fmt.Errorf("failed to upload new file to storage: %w", err)
 	}
+	metrics.DocumentUploadBytes.Add(float64(file.Size))
 
 	// Update document fields
-	fileType := s.getDocumentType(file.Filename)
+	fileType := models.DocumentTypeForFile(file.Filename)
 	document.FileName = uuidFileName
 	document.OriginalFileName = file.Filename
 	document.FileSize = file.Size
@@ -575,228 +1775,674 @@ func (s *DocumentService) processFileUpdate(ctx context.Context, userID uuid.UUI
 	document.MimeType = contentType
 	document.StoragePath = objectName
 	document.StorageBucket = bucketName
-	document.Status = models.DocumentStatusProcessing
-	document.Version = document.Version + 1
+	document.ContentHash = hex.EncodeToString(hasher.Sum(nil))
+
+	// Previews of the old file no longer apply, the worker regenerates them
+	document.PageCount = nil
+	document.ThumbnailPath = ""
+	document.HasThumbnail = false
+	document.PreviewPath = ""
+	document.ContentText = nil
+	document.ContentPages = nil
+	document.ProcessingError = ""
+	document.Status = models.DocumentStatusReady
+	if s.needsProcessing(fileType) {
+		document.Status = models.DocumentStatusProcessing
+	}
+	s.resetScan(document)
+
+	return objectName, nil
+}
+
+// Compares old and new document state
+func (s *DocumentService) detectChanges(orig, updated *models.Document, hasNewFile bool) map[string]interface{} {
+	changes := make(map[string]interface{})
+
+	if orig.Title != updated.Title {
+		changes["title"] = map[string]interface{}{"old": orig.Title, "new": updated.Title}
+	}
+	if orig.Description != updated.Description {
+		changes["description"] = map[string]interface{}{"old": orig.Description, "new": updated.Description}
+	}
+	if orig.Tags != updated.Tags {
+		changes["tags"] = map[string]interface{}{"old": orig.Tags, "new": updated.Tags}
+	}
+	if orig.IsPublic != updated.IsPublic {
+		changes["isPublic"] = map[string]interface{}{"old": orig.IsPublic, "new": updated.IsPublic}
+	}
+	if !reflect.DeepEqual(orig.CustomMetadata, updated.CustomMetadata) {
+		changes["customMetadata"] = map[string]interface{}{"old": orig.CustomMetadata, "new": updated.CustomMetadata}
+	}
+	if hasNewFile {
+		changes["file"] = "updated"
+	}
+
+	return changes
+}
+
+// Removes files if update fails
+func (s *DocumentService) cleanupFailedUpdate(ctx context.Context, storagePath, thumbnailPath string) {
+	if storagePath != "" {
+		if err := s.minioService.DeleteFile(ctx, storagePath); err != nil {
+			logrus.Errorf("Failed to cleanup new uploaded file: %v", err)
+		}
+	}
+	if thumbnailPath != "" {
+		if err := s.minioService.DeleteFile(ctx, thumbnailPath); err != nil {
+			logrus.Errorf("Failed to cleanup new thumbnail: %v", err)
+		}
+	}
+}
+
+// Removes old files after successful update
+func (s *DocumentService) cleanupOldFiles(ctx context.Context, oldStoragePath, oldThumbnailPath, oldPreviewPath string) {
+	if oldStoragePath != "" {
+		if err := s.minioService.DeleteFile(ctx, oldStoragePath); err != nil {
+			logrus.Errorf("Failed to delete old file from storage: %v", err)
+		}
+	}
+	if oldThumbnailPath != "" {
+		if err := s.minioService.DeleteFile(ctx, oldThumbnailPath); err != nil {
+			logrus.Errorf("Failed to delete old thumbnail from storage: %v", err)
+		}
+		s.deleteThumbnailVariants(ctx, oldThumbnailPath)
+	}
+	if oldPreviewPath != "" {
+		if err := s.minioService.DeleteFile(ctx, oldPreviewPath); err != nil {
+			logrus.Errorf("Failed to delete old preview from storage: %v", err)
+		}
+	}
+}
+
+// Pins a document to the top of the user's listing. Any document the user can view can be
+// pinned, including documents shared with them.
+func (s *DocumentService) PinDocument(ctx context.Context, userID, documentID uuid.UUID) error {
+	if _, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView); err != nil {
+		return err
+	}
+
+	pin := &models.DocumentPin{UserID: userID, DocumentID: documentID}
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(pin)
+	if result.Error != nil {
+		return fmt.Errorf("failed to pin document: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("document is already pinned")
+	}
+
+	s.searchCache.Invalidate(userID)
+	return nil
+}
+
+// Removes the user's pin from a document
+func (s *DocumentService) UnpinDocument(ctx context.Context, userID, documentID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Where("user_id = ? AND document_id = ?", userID, documentID).
+		Delete(&models.DocumentPin{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to unpin document: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("document is not pinned")
+	}
+
+	s.searchCache.Invalidate(userID)
+	return nil
+}
+
+// Returns which of the documents the user pinned. A failed lookup only loses the flag.
+func (s *DocumentService) pinnedDocumentIDs(ctx context.Context, userID uuid.UUID, documentIDs []uuid.UUID) map[uuid.UUID]bool {
+	pinned := make(map[uuid.UUID]bool)
+	if len(documentIDs) == 0 {
+		return pinned
+	}
+
+	var ids []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&models.DocumentPin{}).
+		Where("user_id = ? AND document_id IN ?", userID, documentIDs).
+		Pluck("document_id", &ids).Error; err != nil {
+		logrus.Warnf("Failed to load pins of user %s: %v", userID, err)
+		return pinned
+	}
+	for _, id := range ids {
+		pinned[id] = true
+	}
+	return pinned
+}
+
+// Sets IsPinned on the listed documents the user pinned
+func (s *DocumentService) markPinned(ctx context.Context, userID uuid.UUID, documents []types.DocumentResponse) {
+	ids := make([]uuid.UUID, len(documents))
+	for i := range documents {
+		ids[i] = documents[i].ID
+	}
+	pinned := s.pinnedDocumentIDs(ctx, userID, ids)
+	for i := range documents {
+		documents[i].IsPinned = pinned[documents[i].ID]
+	}
+}
+
+// Converts search result to document list response
+func (s *DocumentService) convertSearchResultToDocumentList(result *types.SearchResult) *types.DocumentListResponse {
+	documents := make([]types.DocumentResponse, len(result.Documents))
+	for i, doc := range result.Documents {
+		responseDoc := s.toDocumentResponse(&doc)
+		documents[i] = *responseDoc
+	}
 
-	var thumbnailPath string
-	// Generate thumbnail for PDF
-	if fileType == models.DocumentTypePDF {
-		thumbnailPath, err = s.generatePDFThumbnail(ctx, file, objectName)
+	return &types.DocumentListResponse{
+		Documents:  documents,
+		Total:      result.Total,
+		Page:       result.Page,
+		Limit:      result.Limit,
+		TotalPages: result.TotalPages,
+	}
+}
+
+// Validation and utility methods
+
+// Validates uploaded file
+func (s *DocumentService) validateFile(file *multipart.FileHeader) error {
+	// Check file size (max 100MB)
+	maxSize := int64(100 * 1024 * 1024) // 100MB
+	if file.Size > maxSize {
+		return fmt.Errorf("file size too large: maximum allowed is 100MB")
+	}
+
+	if !s.fileTypes.IsAllowed(file.Filename) {
+		return fmt.Errorf("file type not supported: %s", strings.ToLower(filepath.Ext(file.Filename)))
+	}
+
+	return nil
+}
+
+// Response conversion methods
+
+// Converts model to response
+func (s *DocumentService) toDocumentResponse(doc *models.Document) *types.DocumentResponse {
+	var expiresIn *int64
+	if doc.ExpiresAt != nil {
+		seconds := int64(max(time.Until(*doc.ExpiresAt), 0) / time.Second)
+		expiresIn = &seconds
+	}
+
+	return &types.DocumentResponse{
+		ID:               doc.ID,
+		Title:            doc.Title,
+		Description:      doc.Description,
+		FileName:         doc.FileName,
+		OriginalFileName: doc.OriginalFileName,
+		FileSize:         doc.FileSize,
+		FileType:         doc.FileType,
+		MimeType:         doc.MimeType,
+		Status:           doc.Status,
+		Version:          doc.Version,
+		Tags:             models.NormalizeTags(doc.Tags),
+		IsPublic:         doc.IsPublic,
+		ViewCount:        doc.ViewCount,
+		DownloadCount:    doc.DownloadCount,
+		PageCount:        doc.PageCount,
+		OCRApplied:       doc.OCRApplied,
+		ProcessingError:  doc.ProcessingError,
+		ScanStatus:       doc.ScanStatus,
+		FileWithheld:     s.CheckScanned(doc) != nil,
+		CustomMetadata:   doc.CustomMetadata,
+		Language:         doc.Language,
+		UserID:           doc.UserID,
+		Summary:          doc.Summary,
+		ProcessedAt:      doc.ProcessedAt,
+		CreatedAt:        doc.CreatedAt,
+		UpdatedAt:        doc.UpdatedAt,
+		HasThumbnail:     doc.HasThumbnail,
+		FolderID:         doc.FolderID,
+		ExpiresAt:        doc.ExpiresAt,
+		ExpiresIn:        expiresIn,
+		UserAccessLevel:  "owner",
+		StoragePath:      doc.StoragePath,
+	}
+}
+
+// Converts model to response with access level
+func (s *DocumentService) toDocumentResponseWithAccess(doc *models.Document, userAccessLevel string) *types.DocumentResponse {
+	response := s.toDocumentResponse(doc)
+	response.UserAccessLevel = userAccessLevel
+	return response
+}
+
+// Resolves how the document should be previewed using the first strategy that handles it,
+// falling back to a download URL of the original file. A zero urlExpiry uses the configured default.
+func (s *DocumentService) GetDocumentPreview(ctx context.Context, document *models.Document, urlExpiry time.Duration) (*types.DocumentPreviewResponse, error) {
+	if err := s.CheckScanned(document); err != nil {
+		return nil, err
+	}
+	if urlExpiry <= 0 {
+		urlExpiry = s.previewURLExpiry
+	}
+
+	for _, strategy := range s.previewStrategies {
+		if strategy.CanHandle(document) {
+			return strategy.Preview(ctx, document, urlExpiry)
+		}
+	}
+
+	url, err := s.minioService.GeneratePresignedURL(ctx, document.StoragePath, urlExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate preview URL: %w", err)
+	}
+
+	expiresAt := time.Now().Add(urlExpiry)
+	return &types.DocumentPreviewResponse{
+		PreviewType: types.PreviewTypeDownload,
+		URL:         url,
+		MimeType:    document.MimeType,
+		Strategy:    "download",
+		ExpiresAt:   &expiresAt,
+	}, nil
+}
+
+// Renders text and Markdown documents the user can view to sanitized HTML, reading at most
+// renderMaxBytes of the file. Other documents get their regular preview as a fallback.
+func (s *DocumentService) GetRenderedPreview(ctx context.Context, userID, documentID uuid.UUID) (*types.RenderedPreviewResponse, error) {
+	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsTextDocument(document) {
+		preview, err := s.GetDocumentPreview(ctx, document, 0)
 		if err != nil {
-			logrus.Warnf("Failed to generate PDF thumbnail for %s: %v", file.Filename, err)
-			document.HasThumbnail = false
-			document.ThumbnailPath = ""
-		} else {
-			document.ThumbnailPath = thumbnailPath
-			document.HasThumbnail = true
+			return nil, err
 		}
+		return &types.RenderedPreviewResponse{Fallback: preview}, nil
+	}
+	if err := s.CheckScanned(document); err != nil {
+		return nil, err
+	}
+
+	reader, err := s.minioService.DownloadFile(ctx, document.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document from storage: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, s.renderMaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document from storage: %w", err)
+	}
+	truncated := int64(len(data)) > s.renderMaxBytes
+	if truncated {
+		data = data[:s.renderMaxBytes]
+	}
+	// A character cut in half, or a file that is not UTF-8, shows replacement characters
+	text := strings.ToValidUTF8(string(data), "\uFFFD")
+
+	rendered := &types.RenderedPreviewResponse{
+		Rendered:  true,
+		Truncated: truncated,
+	}
+	if IsMarkdownDocument(document) {
+		rendered.Format = types.RenderedFormatMarkdown
+		rendered.HTML = utils.RenderMarkdown(text)
 	} else {
-		document.HasThumbnail = false
-		document.ThumbnailPath = ""
+		rendered.Format = types.RenderedFormatText
+		rendered.HTML = utils.RenderPlainText(text)
+	}
+	return rendered, nil
+}
+
+// PDF and thumbnail processing methods
+
+// Reports whether page count or thumbnail generation applies to the file type
+func (s *DocumentService) needsPreview(fileType models.DocumentType) bool {
+	if s.previewQueue == nil || s.imageMagick == nil {
+		return false
+	}
+
+	switch fileType {
+	case models.DocumentTypePDF:
+		return true
+	case models.DocumentTypeDOCX, models.DocumentTypePPTX, models.DocumentTypeXLSX:
+		return s.libreOffice != nil
+	default:
+		return false
+	}
+}
+
+// Reports whether the worker has anything to do for the file type, a virus scan, renditions
+// or text for search
+func (s *DocumentService) needsProcessing(fileType models.DocumentType) bool {
+	if s.previewQueue == nil {
+		return false
+	}
+	return s.scansUploads() || s.needsPreview(fileType) || s.textExtractor.CanExtract(fileType)
+}
+
+// Hands preview generation to the worker, the document is marked ready when queueing fails,
+// or failed when its file is held until scanned
+func (s *DocumentService) enqueuePreview(ctx context.Context, document *models.Document) {
+	key := queue.DocumentMessageKey(document.ID.String(), document.StoragePath)
+	err := s.previewQueue.PublishDocumentForPreview(document.ID.String(), document.StoragePath, key)
+	if err == nil {
+		return
+	}
+
+	// A file held for its scan would never be released, it is failed so it can be reprocessed
+	if s.holdUnscanned {
+		logrus.Errorf("[PREVIEW] Failed to queue document %s for its virus scan: %v", document.ID, err)
+		document.Status = models.DocumentStatusFailed
+		document.ProcessingError = "failed to queue document for virus scanning"
+		if err := s.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+			"status":           document.Status,
+			"processing_error": document.ProcessingError,
+		}); err != nil {
+			logrus.Errorf("[PREVIEW] Failed to mark document %s as failed: %v", document.ID, err)
+		}
+		return
+	}
+
+	logrus.Errorf("[PREVIEW] Failed to queue document %s, continuing without preview: %v", document.ID, err)
+
+	now := time.Now()
+	document.Status = models.DocumentStatusReady
+	document.ProcessedAt = &now
+	if err := s.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+		"status":       document.Status,
+		"processed_at": document.ProcessedAt,
+	}); err != nil {
+		logrus.Errorf("[PREVIEW] Failed to mark document %s as ready: %v", document.ID, err)
+	}
+}
+
+// Queue handler for preview messages
+func (s *DocumentService) HandlePreviewMessage(ctx context.Context, body []byte) error {
+	msg, documentID, err := parsePreviewMessage(body)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+
+	return s.ProcessDocumentPreview(ctx, documentID, msg.StoragePath)
+}
+
+// Dead-letter handler for preview messages, marks the document failed once the retries
+// are used up so it does not stay in processing forever
+func (s *DocumentService) HandlePreviewDeadLetter(ctx context.Context, body []byte, cause error) {
+	msg, documentID, err := parsePreviewMessage(body)
+	if err != nil {
+		logrus.Errorf("[PREVIEW] Dead-lettered an unreadable preview message: %v", err)
+		return
+	}
+
+	if err := s.documentRepo.UpdateProcessingResult(ctx, documentID, msg.StoragePath, map[string]interface{}{
+		"status":           models.DocumentStatusFailed,
+		"processing_error": cause.Error(),
+	}); err != nil && !strings.Contains(err.Error(), "document not found") {
+		logrus.Errorf("[PREVIEW] Failed to mark dead-lettered document %s as failed: %v", documentID, err)
+	}
+}
+
+func parsePreviewMessage(body []byte) (*queue.DocumentPreviewMessage, uuid.UUID, error) {
+	var msg queue.DocumentPreviewMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, uuid.Nil, fmt.Errorf("invalid preview message: %w", err)
 	}
 
-	return objectName, thumbnailPath, nil
+	documentID, err := uuid.Parse(msg.DocumentID)
+	if err != nil {
+		return nil, uuid.Nil, fmt.Errorf("invalid document ID in preview message: %w", err)
+	}
+	return &msg, documentID, nil
 }
 
-// Compares old and new document state
-func (s *DocumentService) detectChanges(orig, updated *models.Document, hasNewFile bool) map[string]interface{} {
-	changes := make(map[string]interface{})
+// Queues a failed document for processing again
+func (s *DocumentService) ReprocessDocument(ctx context.Context, userID, documentID uuid.UUID) (*types.DocumentResponse, error) {
+	document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelEdit)
+	if err != nil {
+		return nil, err
+	}
 
-	if orig.Title != updated.Title {
-		changes["title"] = map[string]interface{}{"old": orig.Title, "new": updated.Title}
+	if document.Status != models.DocumentStatusFailed {
+		return nil, fmt.Errorf("document is not in failed state")
 	}
-	if orig.Description != updated.Description {
-		changes["description"] = map[string]interface{}{"old": orig.Description, "new": updated.Description}
+	if document.ScanStatus == models.ScanStatusInfected {
+		return nil, fmt.Errorf("document is quarantined")
 	}
-	if orig.Tags != updated.Tags {
-		changes["tags"] = map[string]interface{}{"old": orig.Tags, "new": updated.Tags}
+	if s.previewQueue == nil {
+		return nil, fmt.Errorf("document processing is not available")
 	}
-	if orig.IsPublic != updated.IsPublic {
-		changes["isPublic"] = map[string]interface{}{"old": orig.IsPublic, "new": updated.IsPublic}
+
+	if err := s.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+		"status":           models.DocumentStatusProcessing,
+		"processing_error": "",
+	}); err != nil {
+		return nil, fmt.Errorf("failed to update document status: %w", err)
 	}
-	if hasNewFile {
-		changes["file"] = "updated"
+
+	// A fresh key, the original run is already recorded as processed
+	key := queue.DocumentMessageKey(document.ID.String(), document.StoragePath) + ":reprocess:" + uuid.NewString()
+	if err := s.previewQueue.PublishDocumentForPreview(document.ID.String(), document.StoragePath, key); err != nil {
+		if revertErr := s.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+			"status":           models.DocumentStatusFailed,
+			"processing_error": document.ProcessingError,
+		}); revertErr != nil {
+			logrus.Errorf("[PREVIEW] Failed to restore failed status of document %s: %v", document.ID, revertErr)
+		}
+		return nil, fmt.Errorf("failed to queue document for processing: %w", err)
 	}
 
-	return changes
+	document.Status = models.DocumentStatusProcessing
+	document.ProcessingError = ""
+	return s.toDocumentResponse(document), nil
 }
 
-// Removes files if update fails
-func (s *DocumentService) cleanupFailedUpdate(ctx context.Context, storagePath, thumbnailPath string) {
-	if storagePath != "" {
-		if err := s.minioService.DeleteFile(ctx, storagePath); err != nil {
-			logrus.Errorf("Failed to cleanup new uploaded file: %v", err)
+// Generates page count and thumbnail from the stored object, then marks the document ready or failed
+func (s *DocumentService) ProcessDocumentPreview(ctx context.Context, documentID uuid.UUID, storagePath string) error {
+	document, err := s.documentRepo.GetByID(ctx, documentID)
+	if err != nil {
+		if strings.Contains(err.Error(), "document not found") {
+			logrus.Infof("[PREVIEW] Document %s no longer exists, skipping", documentID)
+			return nil
 		}
+		return err
 	}
-	if thumbnailPath != "" {
-		if err := s.minioService.DeleteFile(ctx, thumbnailPath); err != nil {
-			logrus.Errorf("Failed to cleanup new thumbnail: %v", err)
-		}
+
+	// A newer file has been uploaded since this message was queued
+	if document.StoragePath != storagePath {
+		logrus.Infof("[PREVIEW] Document %s file was replaced, skipping stale message", documentID)
+		return nil
 	}
-}
 
-// Removes old files after successful update
-func (s *DocumentService) cleanupOldFiles(ctx context.Context, oldStoragePath, oldThumbnailPath string) {
-	if oldStoragePath != "" {
-		if err := s.minioService.DeleteFile(ctx, oldStoragePath); err != nil {
-			logrus.Errorf("Failed to delete old file from storage: %v", err)
-		}
+	// Storage outages are retried by the consumer, the document is failed once they run out
+	localFile, cleanup, err := s.downloadToTempFile(ctx, storagePath, strings.ToLower(filepath.Ext(document.FileName)))
+	defer cleanup()
+	if err != nil {
+		return fmt.Errorf("failed to download document: %w", err)
 	}
-	if oldThumbnailPath != "" {
-		if err := s.minioService.DeleteFile(ctx, oldThumbnailPath); err != nil {
-			logrus.Errorf("Failed to delete old thumbnail from storage: %v", err)
+
+	// Scanned before any tool opens the file, an infected one goes no further
+	if s.scanner != nil {
+		infected, err := s.scanDocument(ctx, document, localFile)
+		if err != nil || infected {
+			return err
 		}
 	}
-}
 
-// Converts search result to document list response
-func (s *DocumentService) convertSearchResultToDocumentList(result *types.SearchResult) *types.DocumentListResponse {
-	documents := make([]types.DocumentResponse, len(result.Documents))
-	for i, doc := range result.Documents {
-		responseDoc := s.toDocumentResponse(&doc)
-		documents[i] = *responseDoc
-	}
+	fields := map[string]interface{}{}
 
-	return &types.DocumentListResponse{
-		Documents:  documents,
-		Total:      result.Total,
-		Page:       result.Page,
-		Limit:      result.Limit,
-		TotalPages: result.TotalPages,
+	// Page count is informational, failing to read it does not fail the document
+	if document.FileType == models.DocumentTypePDF && s.imageMagick.CanIdentify() {
+		var pageCount *int
+		err := retryWithBackoff(ctx, previewMaxAttempts, previewRetryDelay, func() error {
+			var err error
+			pageCount, err = s.extractPDFPageCount(ctx, localFile)
+			return err
+		})
+		if err != nil {
+			logrus.Warnf("[PREVIEW] Failed to extract page count for document %s: %v", documentID, err)
+		} else {
+			fields["page_count"] = *pageCount
+		}
 	}
-}
-
-// Validation and utility methods
 
-// Validates uploaded file
-func (s *DocumentService) validateFile(file *multipart.FileHeader) error {
-	// Check file size (max 100MB)
-	maxSize := int64(100 * 1024 * 1024) // 100MB
-	if file.Size > maxSize {
-		return fmt.Errorf("file size too large: maximum allowed is 100MB")
+	var thumbnailPath, previewPath string
+	if s.needsPreview(document.FileType) {
+		err = retryWithBackoff(ctx, previewMaxAttempts, previewRetryDelay, func() error {
+			var err error
+			thumbnailPath, previewPath, err = s.generateThumbnail(ctx, localFile, storagePath, document.FileType)
+			return err
+		})
+		if err != nil {
+			return s.failPreview(ctx, document, fmt.Sprintf("thumbnail generation failed after %d attempts: %v", previewMaxAttempts, err))
+		}
 	}
 
-	// Check file extension
-	allowedExtensions := map[string]bool{
-		".pdf":  true,
-		".docx": true,
-		".doc":  true,
-		".txt":  true,
-		".xlsx": true,
-		".xls":  true,
-		".pptx": true,
-		".ppt":  true,
-		".md":   true,
+	if s.textExtractor.CanExtract(document.FileType) || s.wantsOCR(document) {
+		content := s.extractContent(ctx, localFile, document)
+		fields["content_text"] = content.Text
+		fields["content_pages"] = models.PageOffsets(content.PageOffsets)
+		fields["ocr_applied"] = content.OCR
 	}
 
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !allowedExtensions[ext] {
-		return fmt.Errorf("file type not supported: %s", ext)
+	now := time.Now()
+	fields["thumbnail_path"] = thumbnailPath
+	fields["has_thumbnail"] = thumbnailPath != ""
+	fields["preview_path"] = previewPath
+	fields["status"] = models.DocumentStatusReady
+	fields["processed_at"] = &now
+	fields["processing_error"] = ""
+
+	if err := s.documentRepo.UpdateProcessingResult(ctx, documentID, storagePath, fields); err != nil {
+		// The file changed while we were working, drop the renditions we just uploaded
+		for _, path := range []string{thumbnailPath, previewPath} {
+			if path == "" {
+				continue
+			}
+			if cleanupErr := s.minioService.DeleteFile(ctx, path); cleanupErr != nil {
+				logrus.Warnf("[PREVIEW] Failed to remove orphaned rendition %s: %v", path, cleanupErr)
+			}
+		}
+		if strings.Contains(err.Error(), "document not found") {
+			return nil
+		}
+		return fmt.Errorf("failed to save preview for document %s: %w", documentID, err)
 	}
 
+	s.searchCache.Invalidate(document.UserID)
+
+	logrus.Infof("[PREVIEW] Document %s processed", documentID)
 	return nil
 }
 
-// Determines document type from filename
-func (s *DocumentService) getDocumentType(filename string) models.DocumentType {
-	ext := strings.ToLower(filepath.Ext(filename))
-
-	switch ext {
-	case ".pdf":
-		return models.DocumentTypePDF
-	case ".docx", ".doc":
-		return models.DocumentTypeDOCX
-	case ".txt", ".md":
-		return models.DocumentTypeTXT
-	case ".xlsx", ".xls":
-		return models.DocumentTypeXLSX
-	case ".pptx", ".ppt":
-		return models.DocumentTypePPTX
-	default:
-		return models.DocumentTypeOther
+// Extracts the text indexed for search, OCR'ing PDFs without a text layer when the upload asked
+// for it. Failures only cost search recall, so they are logged and an empty text is stored to
+// keep the backfill from retrying the document.
+func (s *DocumentService) extractContent(ctx context.Context, localFile string, document *models.Document) *ExtractedContent {
+	content := &ExtractedContent{}
+	if s.textExtractor.CanExtract(document.FileType) {
+		extracted, err := s.textExtractor.Extract(ctx, localFile, document.FileType)
+		if err != nil {
+			logrus.Warnf("[PREVIEW] Failed to extract text for document %s: %v", document.ID, err)
+		} else {
+			content = extracted
+		}
 	}
-}
 
-// Response conversion methods
+	if !s.wantsOCR(document) || !isImageOnly(content) {
+		return content
+	}
 
-// Converts model to response
-func (s *DocumentService) toDocumentResponse(doc *models.Document) *types.DocumentResponse {
-	return &types.DocumentResponse{
-		ID:               doc.ID,
-		Title:            doc.Title,
-		Description:      doc.Description,
-		FileName:         doc.FileName,
-		OriginalFileName: doc.OriginalFileName,
-		FileSize:         doc.FileSize,
-		FileType:         doc.FileType,
-		MimeType:         doc.MimeType,
-		Status:           doc.Status,
-		Version:          doc.Version,
-		Tags:             doc.Tags,
-		IsPublic:         doc.IsPublic,
-		ViewCount:        doc.ViewCount,
-		DownloadCount:    doc.DownloadCount,
-		PageCount:        doc.PageCount,
-		UserID:           doc.UserID,
-		Summary:          doc.Summary,
-		ProcessedAt:      doc.ProcessedAt,
-		CreatedAt:        doc.CreatedAt,
-		UpdatedAt:        doc.UpdatedAt,
-		HasThumbnail:     doc.HasThumbnail,
-		UserAccessLevel:  "owner",
-		StoragePath:      doc.StoragePath,
+	recognized, err := s.textExtractor.OCR(ctx, localFile, document.OCRLanguage)
+	if err != nil {
+		logrus.Warnf("[PREVIEW] Failed to OCR document %s: %v", document.ID, err)
+		return content
 	}
+	logrus.Infof("[PREVIEW] OCR'd %d pages of document %s", len(recognized.PageOffsets), document.ID)
+	return recognized
 }
 
-// Converts model to response with access level
-func (s *DocumentService) toDocumentResponseWithAccess(doc *models.Document, userAccessLevel string) *types.DocumentResponse {
-	response := s.toDocumentResponse(doc)
-	response.UserAccessLevel = userAccessLevel
-	return response
+// Reports whether the document is a PDF uploaded with OCR requested in an installed language
+func (s *DocumentService) wantsOCR(document *models.Document) bool {
+	return document.FileType == models.DocumentTypePDF && document.OCRLanguage != "" && s.textExtractor.CanOCR(document.OCRLanguage)
 }
 
-// PDF processing methods
+// Extracts text for documents stored before extraction was available, in the background
+func (s *DocumentService) StartContentBackfill(ctx context.Context) {
+	fileTypes := s.textExtractor.SupportedTypes()
+	if len(fileTypes) == 0 {
+		return
+	}
 
-// Extracts page count from PDF using ImageMagick
-func (s *DocumentService) extractPDFPageCount(ctx context.Context, file *multipart.FileHeader) (*int, error) {
-	// Create temp directory
-	os.MkdirAll("temp", 0755)
+	go func() {
+		filled, err := s.backfillContentText(ctx, fileTypes)
+		if err != nil && ctx.Err() == nil {
+			logrus.Errorf("[CONTENT] Text backfill stopped: %v", err)
+		}
+		if filled > 0 {
+			logrus.Infof("[CONTENT] Extracted text for %d existing documents", filled)
+		}
+	}()
+}
 
-	// Save file temporarily
-	tempFile := filepath.Join("temp", fmt.Sprintf("%s.pdf", uuid.New().String()))
-	src, err := file.Open()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open uploaded file: %w", err)
-	}
-	defer src.Close()
+func (s *DocumentService) backfillContentText(ctx context.Context, fileTypes []models.DocumentType) (int, error) {
+	filled := 0
+	afterID := uuid.Nil
 
-	dst, err := os.Create(tempFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temp file: %w", err)
-	}
-	defer dst.Close()
+	for {
+		documents, err := s.documentRepo.ListMissingContentText(ctx, fileTypes, afterID, contentBackfillBatchSize)
+		if err != nil {
+			return filled, err
+		}
 
-	_, err = io.Copy(dst, src)
-	if err != nil {
-		return nil, fmt.Errorf("failed to copy file content: %w", err)
+		for i := range documents {
+			document := &documents[i]
+			afterID = document.ID
+
+			content, err := s.extractStoredContent(ctx, document)
+			if err != nil {
+				if ctx.Err() != nil {
+					return filled, ctx.Err()
+				}
+				logrus.Warnf("[CONTENT] Failed to download document %s: %v", document.ID, err)
+				continue
+			}
+
+			// Guarded by the storage path so a file replaced meanwhile keeps the worker's result
+			err = s.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+				"content_text":  content.Text,
+				"content_pages": models.PageOffsets(content.PageOffsets),
+				"ocr_applied":   content.OCR,
+			})
+			if err != nil && !strings.Contains(err.Error(), "document not found") {
+				return filled, fmt.Errorf("failed to save text for document %s: %w", document.ID, err)
+			}
+			if err == nil {
+				filled++
+			}
+		}
+
+		if len(documents) < contentBackfillBatchSize {
+			return filled, nil
+		}
 	}
-	dst.Close()
+}
 
-	defer os.Remove(tempFile)
+// Marks the document as failed and records why
+func (s *DocumentService) failPreview(ctx context.Context, document *models.Document, reason string) error {
+	logrus.Errorf("[PREVIEW] Document %s failed: %s", document.ID, reason)
 
-	// Get ImageMagick command
-	var magickCmd string
-	if _, err := exec.LookPath("magick"); err == nil {
-		magickCmd = "magick"
-	} else if _, err := exec.LookPath("convert"); err == nil {
-		magickCmd = "convert"
-	} else {
-		magickCmd = "C:\\ImageMagick\\magick.exe"
+	if err := s.documentRepo.UpdateProcessingResult(ctx, document.ID, document.StoragePath, map[string]interface{}{
+		"status":           models.DocumentStatusFailed,
+		"processing_error": reason,
+	}); err != nil && !strings.Contains(err.Error(), "document not found") {
+		return fmt.Errorf("failed to record preview failure: %w", err)
 	}
 
-	// Extract page count
-	cmd := exec.Command(magickCmd, "identify", "-format", "%n", tempFile)
-	output, err := cmd.CombinedOutput()
+	// Already retried locally and recorded, nothing for the consumer to retry
+	return queue.Permanent(fmt.Errorf("%s", reason))
+}
+
+// Extracts page count from a local PDF using ImageMagick
+func (s *DocumentService) extractPDFPageCount(ctx context.Context, pdfFile string) (*int, error) {
+	output, err := s.imageMagick.Identify(ctx, "-format", "%n", pdfFile)
 	if err != nil {
 		return nil, fmt.Errorf("ImageMagick identify failed: %s, error: %w", string(output), err)
 	}
@@ -814,82 +2460,295 @@ func (s *DocumentService) extractPDFPageCount(ctx context.Context, file *multipa
 	return &pageCount, nil
 }
 
-// Creates thumbnail from PDF first page
-func (s *DocumentService) generatePDFThumbnail(ctx context.Context, file *multipart.FileHeader, pdfObjectName string) (string, error) {
-	// Create temp directory
-	os.MkdirAll("temp", 0755)
+// Generates a thumbnail for the supported document types, plus a PDF rendition for Office
+// documents. Paths are "" when the type has none.
+func (s *DocumentService) generateThumbnail(ctx context.Context, localFile, objectName string, fileType models.DocumentType) (string, string, error) {
+	switch fileType {
+	case models.DocumentTypePDF:
+		if s.imageMagick == nil {
+			return "", "", fmt.Errorf("imagemagick is not available")
+		}
+		thumbnailPath, err := s.renderPDFThumbnail(ctx, localFile, objectName)
+		return thumbnailPath, "", err
+	case models.DocumentTypeDOCX, models.DocumentTypePPTX, models.DocumentTypeXLSX:
+		return s.generateOfficeThumbnail(ctx, localFile, objectName)
+	default:
+		return "", "", nil
+	}
+}
+
+// Creates thumbnail for Office documents by converting them to PDF first, the PDF is kept for preview
+func (s *DocumentService) generateOfficeThumbnail(ctx context.Context, localFile, objectName string) (string, string, error) {
+	if s.libreOffice == nil {
+		return "", "", fmt.Errorf("libreoffice is not available")
+	}
+	if s.imageMagick == nil {
+		return "", "", fmt.Errorf("imagemagick is not available")
+	}
 
-	// Save uploaded file temporarily
-	tempFile := filepath.Join("temp", fmt.Sprintf("%s.pdf", uuid.New().String()))
-	src, err := file.Open()
+	// The PDF lands next to the download and goes away with the operation's temp dir
+	pdfFile, err := s.libreOffice.ConvertToPDF(ctx, localFile, filepath.Dir(localFile))
 	if err != nil {
-		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+		return "", "", err
 	}
-	defer src.Close()
 
-	dst, err := os.Create(tempFile)
+	previewPath, err := s.uploadPreviewPDF(ctx, pdfFile, objectName)
+	if err != nil {
+		return "", "", err
+	}
+
+	thumbnailPath, err := s.renderPDFThumbnail(ctx, pdfFile, objectName)
 	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
+		if cleanupErr := s.minioService.DeleteFile(ctx, previewPath); cleanupErr != nil {
+			logrus.Warnf("[PREVIEW] Failed to remove preview %s: %v", previewPath, cleanupErr)
+		}
+		return "", "", err
 	}
-	defer dst.Close()
 
-	_, err = io.Copy(dst, src)
+	return thumbnailPath, previewPath, nil
+}
+
+// Uploads the PDF rendition of objectName next to its thumbnail
+func (s *DocumentService) uploadPreviewPDF(ctx context.Context, pdfFile, objectName string) (string, error) {
+	file, err := os.Open(pdfFile)
 	if err != nil {
-		return "", fmt.Errorf("failed to copy file content: %w", err)
+		return "", fmt.Errorf("failed to open converted PDF: %w", err)
 	}
-	dst.Close()
+	defer file.Close()
 
-	thumbnailFile := strings.TrimSuffix(tempFile, ".pdf") + ".jpg"
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to read converted PDF: %w", err)
+	}
 
-	// Get ImageMagick command
-	var magickCmd string
-	if _, err := exec.LookPath("magick"); err == nil {
-		magickCmd = "magick"
-	} else if _, err := exec.LookPath("convert"); err == nil {
-		magickCmd = "convert"
-	} else {
-		magickCmd = "C:\\ImageMagick\\magick.exe"
+	previewName := fmt.Sprintf("previews/%s.pdf", strings.TrimSuffix(objectName, filepath.Ext(objectName)))
+	if err := s.minioService.UploadFile(ctx, s.minioService.BucketName(), previewName, file, info.Size(), "application/pdf"); err != nil {
+		return "", fmt.Errorf("failed to upload preview to MinIO: %w", err)
 	}
 
+	return previewName, nil
+}
+
+// Renders the first page of a local PDF and uploads it as the thumbnail for objectName
+func (s *DocumentService) renderPDFThumbnail(ctx context.Context, pdfFile, objectName string) (string, error) {
+	thumbnailName := fmt.Sprintf("thumbnails/%s.jpg", strings.TrimSuffix(objectName, filepath.Ext(objectName)))
+	if err := s.renderThumbnail(ctx, pdfFile, thumbnailName, ThumbnailSizeMedium, ThumbnailFormatJPEG); err != nil {
+		return "", err
+	}
+
+	// Reprocessing renders to the same path, variants of the previous rendering are stale
+	s.deleteThumbnailVariants(ctx, thumbnailName)
+
+	return thumbnailName, nil
+}
+
+// Renders the first page of a local PDF at a preset size and uploads it to thumbnailName
+func (s *DocumentService) renderThumbnail(ctx context.Context, pdfFile, thumbnailName string, size ThumbnailSize, format ThumbnailFormat) error {
+	thumbnailFile := fmt.Sprintf("%s_%s%s", strings.TrimSuffix(pdfFile, filepath.Ext(pdfFile)), size, format.extension())
+	defer os.Remove(thumbnailFile)
+
 	// Generate thumbnail
-	cmd := exec.Command(
-		magickCmd,
+	output, err := s.imageMagick.Convert(
+		ctx,
 		"-density", "150",
-		tempFile+"[0]",
+		pdfFile+"[0]",
 		"-flatten",
 		"-background", "white",
 		"-alpha", "remove",
-		"-resize", "300x400^",
-		"-quality", "85",
+		"-resize", s.thumbnailPresets.geometries[size]+"^",
+		"-quality", strconv.Itoa(s.thumbnailPresets.quality),
 		thumbnailFile,
 	)
-
-	output, err := cmd.CombinedOutput()
 	if err != nil {
-		os.Remove(tempFile)
-		return "", fmt.Errorf("ImageMagick failed: %s, error: %w", string(output), err)
+		return fmt.Errorf("ImageMagick failed: %s, error: %w", string(output), err)
 	}
 
 	// Read generated thumbnail
 	thumbnailBytes, err := os.ReadFile(thumbnailFile)
 	if err != nil {
-		os.Remove(tempFile)
-		os.Remove(thumbnailFile)
-		return "", fmt.Errorf("failed to read thumbnail: %w", err)
+		return fmt.Errorf("failed to read thumbnail: %w", err)
 	}
 
 	// Upload thumbnail to MinIO
-	thumbnailName := fmt.Sprintf("thumbnails/%s.jpg", strings.TrimSuffix(pdfObjectName, filepath.Ext(pdfObjectName)))
-	_, err = s.minioService.UploadThumbnail(ctx, thumbnailName, thumbnailBytes, "image/jpeg")
+	if _, err := s.minioService.UploadThumbnail(ctx, thumbnailName, thumbnailBytes, format.ContentType()); err != nil {
+		return fmt.Errorf("failed to upload thumbnail to MinIO: %w", err)
+	}
+
+	return nil
+}
+
+// Returns a presigned URL of the thumbnail in the given size and format, so browsers can fetch
+// it from storage instead of through the API
+func (s *DocumentService) GetThumbnailURL(ctx context.Context, document *models.Document, size ThumbnailSize, format ThumbnailFormat) (*types.ThumbnailURLResponse, error) {
+	thumbnailPath, format, err := s.ResolveThumbnail(ctx, document, size, format)
 	if err != nil {
-		os.Remove(tempFile)
-		os.Remove(thumbnailFile)
-		return "", fmt.Errorf("failed to upload thumbnail to MinIO: %w", err)
+		return nil, err
 	}
 
-	// Cleanup temp files
-	os.Remove(tempFile)
-	os.Remove(thumbnailFile)
+	url, err := s.minioService.GeneratePresignedURL(ctx, thumbnailPath, s.thumbURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate thumbnail URL: %w", err)
+	}
 
-	return thumbnailName, nil
+	return &types.ThumbnailURLResponse{
+		URL:         url,
+		ContentType: format.ContentType(),
+		Size:        string(size),
+		ExpiresAt:   time.Now().Add(s.thumbURLExpiry),
+	}, nil
+}
+
+// Returns the presigned thumbnail URLs, and with includePreview the previews, of the documents
+// the user can view. Inaccessible documents are skipped rather than failing the batch, and so
+// are storage errors of a single document so one broken file doesn't blank a whole grid.
+func (s *DocumentService) GetPresignedURLs(ctx context.Context, userID uuid.UUID, documentIDs []uuid.UUID, size ThumbnailSize, format ThumbnailFormat, includePreview bool) (*types.PresignedURLsResponse, error) {
+	response := &types.PresignedURLsResponse{
+		Documents: make([]types.DocumentPresignedURLs, 0, len(documentIDs)),
+		Skipped:   []uuid.UUID{},
+	}
+
+	for _, documentID := range documentIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		document, err := s.getDocumentWithAccess(ctx, userID, documentID, models.AccessLevelView)
+		if err != nil {
+			response.Skipped = append(response.Skipped, documentID)
+			continue
+		}
+
+		urls := types.DocumentPresignedURLs{DocumentID: documentID}
+		if document.HasThumbnail && document.ThumbnailPath != "" {
+			if urls.Thumbnail, err = s.GetThumbnailURL(ctx, document, size, format); err != nil {
+				logrus.Warnf("Failed to generate thumbnail URL of document %s: %v", documentID, err)
+			}
+		}
+		if includePreview && s.CheckScanned(document) == nil {
+			if urls.Preview, err = s.GetDocumentPreview(ctx, document, 0); err != nil {
+				logrus.Warnf("Failed to resolve preview of document %s: %v", documentID, err)
+			}
+		}
+		response.Documents = append(response.Documents, urls)
+	}
+
+	return response, nil
+}
+
+// Returns the stored thumbnail of a document in the given size and format, rendering it from
+// the document's PDF the first time it is asked for. Falls back to the default thumbnail when
+// there is no PDF to render from, and to JPEG when ImageMagick can't write WebP.
+func (s *DocumentService) ResolveThumbnail(ctx context.Context, document *models.Document, size ThumbnailSize, format ThumbnailFormat) (string, ThumbnailFormat, error) {
+	if format == ThumbnailFormatWebP && !s.imageMagick.CanWriteWebP() {
+		format = ThumbnailFormatJPEG
+	}
+
+	path := thumbnailVariantPath(document.ThumbnailPath, size, format)
+	if path == document.ThumbnailPath {
+		return path, format, nil
+	}
+
+	exists, err := s.minioService.FileExists(ctx, path)
+	if err != nil {
+		return "", "", err
+	}
+	if exists {
+		return path, format, nil
+	}
+
+	// Office documents are rendered from their PDF preview
+	source := document.PreviewPath
+	if document.FileType == models.DocumentTypePDF {
+		source = document.StoragePath
+	}
+	if source == "" || s.imageMagick == nil {
+		return document.ThumbnailPath, ThumbnailFormatJPEG, nil
+	}
+
+	localFile, cleanup, err := s.downloadToTempFile(ctx, source, ".pdf")
+	defer cleanup()
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := s.renderThumbnail(ctx, localFile, path, size, format); err != nil {
+		return "", "", fmt.Errorf("failed to render %s thumbnail: %w", size, err)
+	}
+
+	return path, format, nil
+}
+
+// Removes the rendered size and format variants of a thumbnail, missing ones are skipped
+func (s *DocumentService) deleteThumbnailVariants(ctx context.Context, thumbnailPath string) {
+	for _, path := range thumbnailVariantPaths(thumbnailPath) {
+		if err := s.minioService.DeleteFile(ctx, path); err != nil {
+			logrus.Warnf("Failed to delete thumbnail variant %s: %v", path, err)
+		}
+	}
+}
+
+// Downloads a stored object into a temp directory of its own and returns its path. Files
+// derived from it belong in the same directory, cleanup removes all of them and is set even
+// when the download fails, so callers defer it before checking the error.
+func (s *DocumentService) downloadToTempFile(ctx context.Context, objectName, ext string) (string, func(), error) {
+	dir, cleanup, err := s.scratch.Create("doc-")
+	if err != nil {
+		return "", cleanup, err
+	}
+
+	reader, err := s.minioService.DownloadFile(ctx, objectName)
+	if err != nil {
+		return "", cleanup, fmt.Errorf("failed to download file from storage: %w", err)
+	}
+	defer reader.Close()
+
+	tempFile := filepath.Join(dir, "source"+ext)
+	dst, err := os.Create(tempFile)
+	if err != nil {
+		return "", cleanup, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	if _, err := io.Copy(dst, reader); err != nil {
+		dst.Close()
+		return "", cleanup, fmt.Errorf("failed to copy file content: %w", err)
+	}
+
+	if err := dst.Close(); err != nil {
+		return "", cleanup, fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	return tempFile, cleanup, nil
+}
+
+// Downloads a document and extracts its text, the download is removed before returning
+func (s *DocumentService) extractStoredContent(ctx context.Context, document *models.Document) (*ExtractedContent, error) {
+	localFile, cleanup, err := s.downloadToTempFile(ctx, document.StoragePath, strings.ToLower(filepath.Ext(document.FileName)))
+	defer cleanup()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.extractContent(ctx, localFile, document), nil
+}
+
+// Runs fn until it succeeds, doubling the delay between attempts
+func retryWithBackoff(ctx context.Context, attempts int, delay time.Duration, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			break
+		}
+
+		logrus.Warnf("[PREVIEW] Attempt %d/%d failed: %v, retrying in %s", attempt, attempts, err, delay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+	return err
 }
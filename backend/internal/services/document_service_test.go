@@ -0,0 +1,160 @@
+package services_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/testutil"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Builds a document service storing into uploader and repo, accepting the default file types
+func newUploadService(t *testing.T, repo *testutil.MockDocumentRepository, uploader *testutil.MockUploader) *services.DocumentService {
+	t.Helper()
+
+	fileTypes, err := models.NewFileTypePolicy([]string{"txt", "pdf"})
+	if err != nil {
+		t.Fatalf("NewFileTypePolicy failed: %v", err)
+	}
+	return services.NewDocumentService(
+		repo, nil, uploader, nil, nil, nil, nil, nil, nil, fileTypes, nil, nil, nil,
+		services.NewDocumentCounter(repo, nil, 0, 0), nil,
+		config.PreviewConfig{}, config.RevisionConfig{}, config.IntegrityConfig{}, config.ScanConfig{},
+		testutil.NewDryRunDB(t),
+	)
+}
+
+func TestUploadDocumentRemovesStoredFileOnFailure(t *testing.T) {
+	userID := uuid.New()
+	content := []byte("quarterly numbers")
+	contentHash := sha256.Sum256(content)
+
+	tests := []struct {
+		name    string
+		prepare func(*testutil.MockDocumentRepository)
+		req     types.UploadDocumentRequest
+		wantErr string
+	}{
+		{
+			name: "repository fails",
+			prepare: func(repo *testutil.MockDocumentRepository) {
+				repo.Errors["Create"] = errors.New("connection reset")
+			},
+			req:     types.UploadDocumentRequest{Title: "Report", AllowDuplicate: true},
+			wantErr: "failed to save document record: connection reset",
+		},
+		{
+			name: "duplicate content",
+			prepare: func(repo *testutil.MockDocumentRepository) {
+				repo.Put(&models.Document{
+					UserID:      userID,
+					Title:       "Earlier report",
+					ContentHash: hex.EncodeToString(contentHash[:]),
+				})
+			},
+			req:     types.UploadDocumentRequest{Title: "Report"},
+			wantErr: "duplicate document",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := testutil.NewMockDocumentRepository()
+			uploader := testutil.NewMockUploader()
+			tt.prepare(repo)
+			service := newUploadService(t, repo, uploader)
+
+			file := testutil.NewFileHeader(t, "report.txt", "text/plain", content)
+			if _, err := service.UploadDocument(context.Background(), userID, file, &tt.req); err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("UploadDocument error = %v, want %q", err, tt.wantErr)
+			}
+			if names := uploader.ObjectNames(); len(names) != 0 {
+				t.Fatalf("stored objects = %v, want none", names)
+			}
+		})
+	}
+}
+
+func TestUploadDocumentKeepsStoredFile(t *testing.T) {
+	repo := testutil.NewMockDocumentRepository()
+	uploader := testutil.NewMockUploader()
+	service := newUploadService(t, repo, uploader)
+
+	file := testutil.NewFileHeader(t, "report.txt", "text/plain", []byte("quarterly numbers"))
+	response, err := service.UploadDocument(context.Background(), uuid.New(), file, &types.UploadDocumentRequest{Title: "Report"})
+	if err != nil {
+		t.Fatalf("UploadDocument failed: %v", err)
+	}
+	if names := uploader.ObjectNames(); len(names) != 1 {
+		t.Fatalf("stored objects = %v, want the upload", names)
+	}
+	if _, err := repo.GetByID(context.Background(), response.ID); err != nil {
+		t.Fatalf("document was not saved: %v", err)
+	}
+}
+
+func TestPurgeExpiredTrashSkipsFailedDocuments(t *testing.T) {
+	const expired = 150 // more than one batch
+	repo := testutil.NewMockDocumentRepository()
+	uploader := testutil.NewMockUploader()
+	service := newUploadService(t, repo, uploader)
+
+	trash := func(deletedAt time.Time) *models.Document {
+		document := &models.Document{
+			UserID:      uuid.New(),
+			Title:       "Old report",
+			StoragePath: "users/owner/documents/" + uuid.NewString() + ".txt",
+			DeletedAt:   gorm.DeletedAt{Time: deletedAt, Valid: true},
+		}
+		repo.Put(document)
+		uploader.Put(document.StoragePath, []byte("old"), "text/plain")
+		return document
+	}
+
+	var failing []*models.Document
+	for i := 0; i < expired; i++ {
+		document := trash(time.Now().Add(-48 * time.Hour))
+		if i%50 == 0 {
+			repo.PurgeErrors[document.ID] = errors.New("row locked")
+			failing = append(failing, document)
+		}
+	}
+	recent := trash(time.Now().Add(-time.Hour))
+	live := &models.Document{UserID: uuid.New(), Title: "Current report"}
+	repo.Put(live)
+
+	purged, err := service.PurgeExpiredTrash(context.Background(), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("PurgeExpiredTrash failed: %v", err)
+	}
+	if want := expired - len(failing); purged != want {
+		t.Fatalf("purged = %d, want %d", purged, want)
+	}
+	for _, document := range failing {
+		if !repo.Has(document.ID) {
+			t.Fatalf("document %s that failed to purge is gone", document.ID)
+		}
+	}
+	if !repo.Has(recent.ID) || !repo.Has(live.ID) {
+		t.Fatal("documents inside the retention period were purged")
+	}
+}
+
+func TestPurgeExpiredTrashListFailure(t *testing.T) {
+	repo := testutil.NewMockDocumentRepository()
+	repo.Errors["ListTrashedBefore"] = errors.New("connection reset")
+	service := newUploadService(t, repo, testutil.NewMockUploader())
+
+	if _, err := service.PurgeExpiredTrash(context.Background(), 24*time.Hour); err == nil {
+		t.Fatal("PurgeExpiredTrash succeeded although the trash could not be listed")
+	}
+}
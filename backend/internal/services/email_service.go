@@ -0,0 +1,272 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+)
+
+// EmailMessage is a single outgoing email
+type EmailMessage struct {
+	To       string
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// EmailSender delivers rendered messages, replaced in tests to capture the payload
+type EmailSender interface {
+	Send(ctx context.Context, message EmailMessage) error
+}
+
+// ShareEmail describes a document shared with a recipient
+type ShareEmail struct {
+	To            string
+	SharedBy      string
+	DocumentTitle string
+	AccessLevel   models.AccessLevel
+	Message       string
+	Link          string
+	// Set when the recipient has no account yet
+	IsInvitation bool
+	ExpiresAt    *time.Time
+}
+
+// VerificationEmail carries the link confirming a user's email address
+type VerificationEmail struct {
+	To        string
+	Name      string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// PasswordResetEmail carries the link for choosing a new password
+type PasswordResetEmail struct {
+	To        string
+	Name      string
+	Link      string
+	ExpiresAt time.Time
+}
+
+// EmailService sends notification emails
+type EmailService interface {
+	SendShareEmail(ctx context.Context, email ShareEmail) error
+	SendVerificationEmail(ctx context.Context, email VerificationEmail) error
+	SendPasswordResetEmail(ctx context.Context, email PasswordResetEmail) error
+}
+
+type emailService struct {
+	sender EmailSender
+}
+
+func NewEmailService(sender EmailSender) EmailService {
+	return &emailService{sender: sender}
+}
+
+var shareEmailTemplate = template.Must(template.New("share").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #1f2937; background: #f9fafb; padding: 24px;">
+  <div style="max-width: 560px; margin: 0 auto; background: #ffffff; border-radius: 8px; padding: 32px;">
+    <h2 style="margin-top: 0;">{{.SharedBy}} shared a document with you</h2>
+    <p><strong>{{.DocumentTitle}}</strong></p>
+    <p>You have been given <strong>{{.AccessLevel}}</strong> access.</p>
+    {{if .Message}}<blockquote style="border-left: 3px solid #d1d5db; margin: 16px 0; padding-left: 12px; color: #4b5563;">{{.Message}}</blockquote>{{end}}
+    <p style="margin: 24px 0;">
+      <a href="{{.Link}}" style="background: #2563eb; color: #ffffff; padding: 10px 18px; border-radius: 6px; text-decoration: none;">{{if .IsInvitation}}Accept invitation{{else}}Open document{{end}}</a>
+    </p>
+    {{if .IsInvitation}}<p style="color: #6b7280; font-size: 13px;">Create an account with this email address to access the document.</p>{{end}}
+    {{if .ExpiresAt}}<p style="color: #6b7280; font-size: 13px;">Access expires on {{.ExpiresAt.Format "January 2, 2006"}}.</p>{{end}}
+  </div>
+</body>
+</html>`))
+
+func (s *emailService) SendShareEmail(ctx context.Context, email ShareEmail) error {
+	var html bytes.Buffer
+	if err := shareEmailTemplate.Execute(&html, email); err != nil {
+		return fmt.Errorf("failed to render share email: %w", err)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s shared \"%s\" with you (%s access).\n\n", email.SharedBy, email.DocumentTitle, email.AccessLevel)
+	if email.Message != "" {
+		fmt.Fprintf(&text, "%s\n\n", email.Message)
+	}
+	fmt.Fprintf(&text, "%s\n", email.Link)
+
+	return s.sender.Send(ctx, EmailMessage{
+		To:       email.To,
+		Subject:  fmt.Sprintf("%s shared \"%s\" with you", email.SharedBy, email.DocumentTitle),
+		HTMLBody: html.String(),
+		TextBody: text.String(),
+	})
+}
+
+var verificationEmailTemplate = template.Must(template.New("verification").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #1f2937; background: #f9fafb; padding: 24px;">
+  <div style="max-width: 560px; margin: 0 auto; background: #ffffff; border-radius: 8px; padding: 32px;">
+    <h2 style="margin-top: 0;">Confirm your email address</h2>
+    <p>Hi {{.Name}}, please confirm this address to finish setting up your account.</p>
+    <p style="margin: 24px 0;">
+      <a href="{{.Link}}" style="background: #2563eb; color: #ffffff; padding: 10px 18px; border-radius: 6px; text-decoration: none;">Verify email</a>
+    </p>
+    <p style="color: #6b7280; font-size: 13px;">The link expires on {{.ExpiresAt.Format "January 2, 2006 15:04 MST"}}. If you did not create an account you can ignore this email.</p>
+  </div>
+</body>
+</html>`))
+
+func (s *emailService) SendVerificationEmail(ctx context.Context, email VerificationEmail) error {
+	var html bytes.Buffer
+	if err := verificationEmailTemplate.Execute(&html, email); err != nil {
+		return fmt.Errorf("failed to render verification email: %w", err)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Hi %s, please confirm your email address by opening the link below.\n\n", email.Name)
+	fmt.Fprintf(&text, "%s\n\n", email.Link)
+	fmt.Fprintf(&text, "The link expires on %s.\n", email.ExpiresAt.Format("January 2, 2006 15:04 MST"))
+
+	return s.sender.Send(ctx, EmailMessage{
+		To:       email.To,
+		Subject:  "Confirm your email address",
+		HTMLBody: html.String(),
+		TextBody: text.String(),
+	})
+}
+
+var passwordResetEmailTemplate = template.Must(template.New("password-reset").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Helvetica, Arial, sans-serif; color: #1f2937; background: #f9fafb; padding: 24px;">
+  <div style="max-width: 560px; margin: 0 auto; background: #ffffff; border-radius: 8px; padding: 32px;">
+    <h2 style="margin-top: 0;">Reset your password</h2>
+    <p>Hi {{.Name}}, we received a request to reset the password of your account.</p>
+    <p style="margin: 24px 0;">
+      <a href="{{.Link}}" style="background: #2563eb; color: #ffffff; padding: 10px 18px; border-radius: 6px; text-decoration: none;">Choose a new password</a>
+    </p>
+    <p style="color: #6b7280; font-size: 13px;">The link expires on {{.ExpiresAt.Format "January 2, 2006 15:04 MST"}}. If you did not ask for a reset you can ignore this email, your password stays the same.</p>
+  </div>
+</body>
+</html>`))
+
+func (s *emailService) SendPasswordResetEmail(ctx context.Context, email PasswordResetEmail) error {
+	var html bytes.Buffer
+	if err := passwordResetEmailTemplate.Execute(&html, email); err != nil {
+		return fmt.Errorf("failed to render password reset email: %w", err)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Hi %s, open the link below to choose a new password.\n\n", email.Name)
+	fmt.Fprintf(&text, "%s\n\n", email.Link)
+	fmt.Fprintf(&text, "The link expires on %s. If you did not ask for a reset you can ignore this email.\n", email.ExpiresAt.Format("January 2, 2006 15:04 MST"))
+
+	return s.sender.Send(ctx, EmailMessage{
+		To:       email.To,
+		Subject:  "Reset your password",
+		HTMLBody: html.String(),
+		TextBody: text.String(),
+	})
+}
+
+// SMTPSender delivers email through an SMTP server
+type SMTPSender struct {
+	config *config.EmailConfig
+}
+
+func NewSMTPSender(cfg *config.EmailConfig) *SMTPSender {
+	return &SMTPSender{config: cfg}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, message EmailMessage) error {
+	addr := net.JoinHostPort(s.config.SMTPHost, strconv.Itoa(s.config.SMTPPort))
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	var conn net.Conn
+	var err error
+	if s.config.SMTPEncryption == "ssl" {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: s.config.SMTPHost})
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if s.config.SMTPEncryption == "tls" {
+		if err := client.StartTLS(&tls.Config{ServerName: s.config.SMTPHost}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if s.config.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", s.config.SMTPUsername, s.config.SMTPPassword, s.config.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.config.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	if err := client.Rcpt(message.To); err != nil {
+		return fmt.Errorf("failed to set recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to open message body: %w", err)
+	}
+	if _, err := writer.Write(s.buildMessage(message)); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// Builds a multipart/alternative message with plain text and HTML parts
+func (s *SMTPSender) buildMessage(message EmailMessage) []byte {
+	boundary := "noesis-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	from := s.config.From
+	if s.config.FromName != "" {
+		from = fmt.Sprintf("%s <%s>", mime.QEncoding.Encode("utf-8", s.config.FromName), s.config.From)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", message.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n", boundary, message.TextBody)
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/html; charset=utf-8\r\n\r\n%s\r\n", boundary, message.HTMLBody)
+	fmt.Fprintf(&b, "--%s--\r\n", boundary)
+
+	return []byte(b.String())
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// Prefix of embed tokens, tells them apart from user access tokens at a glance
+	embedTokenPrefix = "nfe_"
+
+	// LastUsedAt is refreshed at most this often so busy embeds don't write on every request
+	embedTokenUsageInterval = time.Minute
+)
+
+// Mints, lists, revokes and validates document embed tokens
+type EmbedTokenService struct {
+	db         *gorm.DB
+	defaultTTL time.Duration
+	maxTTL     time.Duration
+}
+
+func NewEmbedTokenService(db *gorm.DB, cfg config.EmbedConfig) *EmbedTokenService {
+	return &EmbedTokenService{db: db, defaultTTL: cfg.DefaultTTL, maxTTL: cfg.MaxTTL}
+}
+
+// Creates an embed token for a document the user owns and returns it with the token, which
+// is not stored and can't be shown again. A zero expiresIn uses the default lifetime.
+func (s *EmbedTokenService) CreateToken(ctx context.Context, ownerID, documentID uuid.UUID, name string, expiresIn time.Duration) (*models.EmbedToken, string, error) {
+	if expiresIn <= 0 {
+		expiresIn = s.defaultTTL
+	}
+	if expiresIn > s.maxTTL {
+		return nil, "", fmt.Errorf("expiry exceeds the maximum of %s", s.maxTTL)
+	}
+
+	var document models.Document
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", documentID, ownerID).First(&document).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", fmt.Errorf("document not found")
+		}
+		return nil, "", fmt.Errorf("failed to get document: %w", err)
+	}
+	if document.IsExpired() {
+		return nil, "", fmt.Errorf("document expired")
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := embedTokenPrefix + hex.EncodeToString(b)
+
+	embedToken := &models.EmbedToken{
+		DocumentID: documentID,
+		OwnerID:    ownerID,
+		TokenHash:  utils.HashToken(token),
+		Name:       strings.TrimSpace(name),
+		ExpiresAt:  time.Now().Add(expiresIn),
+	}
+	if err := s.db.WithContext(ctx).Create(embedToken).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to save embed token: %w", err)
+	}
+
+	return embedToken, token, nil
+}
+
+// Returns the tokens of a document owned by the user that are neither revoked nor expired
+func (s *EmbedTokenService) ListTokens(ctx context.Context, ownerID, documentID uuid.UUID) ([]models.EmbedToken, error) {
+	var tokens []models.EmbedToken
+	err := s.db.WithContext(ctx).
+		Where("document_id = ? AND owner_id = ? AND revoked_at IS NULL AND expires_at > ?", documentID, ownerID, time.Now()).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embed tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revokes a token of a document owned by the user, embeds using it stop working right away
+func (s *EmbedTokenService) RevokeToken(ctx context.Context, ownerID, documentID, tokenID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Model(&models.EmbedToken{}).
+		Where("id = ? AND document_id = ? AND owner_id = ? AND revoked_at IS NULL", tokenID, documentID, ownerID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke embed token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("embed token not found")
+	}
+	return nil
+}
+
+// Returns the embed token matching the presented token when it is still valid
+func (s *EmbedTokenService) ValidateToken(ctx context.Context, token string) (*models.EmbedToken, error) {
+	if !strings.HasPrefix(token, embedTokenPrefix) {
+		return nil, fmt.Errorf("invalid embed token")
+	}
+
+	var embedToken models.EmbedToken
+	if err := s.db.WithContext(ctx).Where("token_hash = ?", utils.HashToken(token)).First(&embedToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("invalid embed token")
+		}
+		return nil, fmt.Errorf("failed to validate embed token: %w", err)
+	}
+	if embedToken.IsRevoked() {
+		return nil, fmt.Errorf("embed token revoked")
+	}
+	if embedToken.IsExpired() {
+		return nil, fmt.Errorf("embed token expired")
+	}
+
+	now := time.Now()
+	if err := s.db.WithContext(ctx).
+		Model(&models.EmbedToken{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", embedToken.ID, now.Add(-embedTokenUsageInterval)).
+		Update("last_used_at", now).Error; err != nil {
+		logrus.Warnf("Failed to record use of embed token %s: %v", embedToken.ID, err)
+	}
+
+	return &embedToken, nil
+}
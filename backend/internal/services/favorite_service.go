@@ -119,8 +119,9 @@ func (s *FavoriteService) GetUserFavorites(ctx context.Context, userID uuid.UUID
 			FileType:         favorite.Document.FileType,
 			MimeType:         favorite.Document.MimeType,
 			Status:           favorite.Document.Status,
+			ScanStatus:       favorite.Document.ScanStatus,
 			Version:          favorite.Document.Version,
-			Tags:             favorite.Document.Tags,
+			Tags:             models.NormalizeTags(favorite.Document.Tags),
 			IsPublic:         favorite.Document.IsPublic,
 			ViewCount:        favorite.Document.ViewCount,
 			DownloadCount:    favorite.Document.DownloadCount,
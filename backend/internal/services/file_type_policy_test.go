@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+	"github.com/eyuppastirmaci/noesis-forge/internal/testutil"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/eyuppastirmaci/noesis-forge/internal/validations"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Posts a single file to the upload route and returns the response status
+func postUpload(t *testing.T, router *gin.Engine, filename string, data []byte) int {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	part.Write(data)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestUploadFileTypesAgreeAcrossLayers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// A deployment that narrowed the defaults, PDF is a known type but not enabled
+	fileTypes, err := models.NewFileTypePolicy([]string{"txt", "md"})
+	if err != nil {
+		t.Fatalf("NewFileTypePolicy failed: %v", err)
+	}
+	tagPolicy, err := models.NewTagPolicy(10, "-_", false)
+	if err != nil {
+		t.Fatalf("NewTagPolicy failed: %v", err)
+	}
+
+	repo := testutil.NewMockDocumentRepository()
+	service := services.NewDocumentService(
+		repo, nil, testutil.NewMockUploader(), nil, nil, nil, nil, nil, nil, fileTypes, tagPolicy, nil, nil,
+		services.NewDocumentCounter(repo, nil, 0, 0), nil,
+		config.PreviewConfig{}, config.RevisionConfig{}, config.IntegrityConfig{}, config.ScanConfig{},
+		testutil.NewDryRunDB(t),
+	)
+	if service.FileTypes() != fileTypes {
+		t.Fatal("service does not expose the policy it was built with")
+	}
+
+	// Wired like the document router, the handler only records that validation passed
+	router := gin.New()
+	router.POST("/upload", validations.ValidateDocumentUpload(service.FileTypes(), service.Tags()), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		filename string
+		data     []byte
+		allowed  bool
+	}{
+		{"notes.txt", []byte("meeting notes"), true},
+		{"README.md", []byte("# Readme"), true},
+		{"NOTES.TXT", []byte("meeting notes"), true},
+		{"report.pdf", []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n1 0 obj\n<<>>\nendobj\n"), false},
+		{"sheet.csv", []byte("a,b\n1,2\n"), false},
+		{"notes", []byte("meeting notes"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			validated := postUpload(t, router, tt.filename, tt.data) == http.StatusNoContent
+
+			file := testutil.NewFileHeader(t, tt.filename, "application/octet-stream", tt.data)
+			_, err := service.UploadDocument(context.Background(), uuid.New(), file, &types.UploadDocumentRequest{Title: tt.filename, AllowDuplicate: true})
+			if err != nil && !strings.HasPrefix(err.Error(), "file type not supported") {
+				t.Fatalf("UploadDocument failed for another reason: %v", err)
+			}
+			stored := err == nil
+
+			if validated != tt.allowed || stored != tt.allowed {
+				t.Fatalf("validation allowed = %v, service allowed = %v, want both %v", validated, stored, tt.allowed)
+			}
+		})
+	}
+}
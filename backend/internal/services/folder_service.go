@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type FolderService struct {
+	db *gorm.DB
+}
+
+func NewFolderService(db *gorm.DB) *FolderService {
+	return &FolderService{db: db}
+}
+
+// Lists the folders directly inside parentID, or at the root when it is nil, in name order
+func (s *FolderService) ListFolders(ctx context.Context, userID uuid.UUID, parentID *uuid.UUID) ([]models.Folder, error) {
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if parentID != nil {
+		if _, err := getUserFolder(ctx, s.db, userID, *parentID); err != nil {
+			return nil, err
+		}
+		query = query.Where("parent_folder_id = ?", *parentID)
+	} else {
+		query = query.Where("parent_folder_id IS NULL")
+	}
+
+	var folders []models.Folder
+	if err := query.Order("LOWER(name) ASC").Find(&folders).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch folders: %w", err)
+	}
+	return folders, nil
+}
+
+func (s *FolderService) GetFolder(ctx context.Context, userID, folderID uuid.UUID) (*models.Folder, error) {
+	return getUserFolder(ctx, s.db, userID, folderID)
+}
+
+// Creates a folder with a name unique within its parent
+func (s *FolderService) CreateFolder(ctx context.Context, userID uuid.UUID, req *types.CreateFolderRequest) (*models.Folder, error) {
+	if req.ParentFolderID != nil {
+		if _, err := getUserFolder(ctx, s.db, userID, *req.ParentFolderID); err != nil {
+			return nil, fmt.Errorf("parent folder not found")
+		}
+	}
+
+	if err := s.checkNameAvailable(ctx, userID, req.ParentFolderID, req.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	folder := &models.Folder{
+		UserID:         userID,
+		ParentFolderID: req.ParentFolderID,
+		Name:           req.Name,
+	}
+	if err := s.db.WithContext(ctx).Create(folder).Error; err != nil {
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	return folder, nil
+}
+
+// Renames a folder or moves it under another parent. A folder can't be moved into itself or
+// one of its subfolders, that would detach the whole branch from the root.
+func (s *FolderService) UpdateFolder(ctx context.Context, userID, folderID uuid.UUID, req *types.UpdateFolderRequest) (*models.Folder, error) {
+	folder, err := getUserFolder(ctx, s.db, userID, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := folder.Name
+	if req.Name != nil {
+		name = *req.Name
+	}
+
+	parentID := folder.ParentFolderID
+	if req.MoveToRoot {
+		parentID = nil
+	} else if req.ParentFolderID != nil {
+		if _, err := getUserFolder(ctx, s.db, userID, *req.ParentFolderID); err != nil {
+			return nil, fmt.Errorf("parent folder not found")
+		}
+
+		subtree, err := folderSubtreeIDs(ctx, s.db, userID, folder.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range subtree {
+			if id == *req.ParentFolderID {
+				return nil, fmt.Errorf("folder can't be moved into itself or its subfolders")
+			}
+		}
+		parentID = req.ParentFolderID
+	}
+
+	if err := s.checkNameAvailable(ctx, userID, parentID, name, folder.ID); err != nil {
+		return nil, err
+	}
+
+	folder.Name = name
+	folder.ParentFolderID = parentID
+	if err := s.db.WithContext(ctx).Model(folder).Select("name", "parent_folder_id").Updates(folder).Error; err != nil {
+		return nil, fmt.Errorf("failed to update folder: %w", err)
+	}
+
+	return folder, nil
+}
+
+// Deletes an empty folder. Trashed documents it held move to the root.
+func (s *FolderService) DeleteFolder(ctx context.Context, userID, folderID uuid.UUID) error {
+	if _, err := getUserFolder(ctx, s.db, userID, folderID); err != nil {
+		return err
+	}
+
+	var subfolders int64
+	if err := s.db.WithContext(ctx).Model(&models.Folder{}).Where("parent_folder_id = ?", folderID).Count(&subfolders).Error; err != nil {
+		return fmt.Errorf("failed to count subfolders: %w", err)
+	}
+	var documents int64
+	if err := s.db.WithContext(ctx).Model(&models.Document{}).Where("folder_id = ?", folderID).Count(&documents).Error; err != nil {
+		return fmt.Errorf("failed to count folder documents: %w", err)
+	}
+	if subfolders > 0 || documents > 0 {
+		return fmt.Errorf("folder is not empty")
+	}
+
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", folderID, userID).Delete(&models.Folder{}).Error; err != nil {
+		return fmt.Errorf("failed to delete folder: %w", err)
+	}
+	return nil
+}
+
+// Compares names ignoring case, like the unique index backing it
+func (s *FolderService) checkNameAvailable(ctx context.Context, userID uuid.UUID, parentID *uuid.UUID, name string, excludeID uuid.UUID) error {
+	query := s.db.WithContext(ctx).Model(&models.Folder{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, name, excludeID)
+	if parentID != nil {
+		query = query.Where("parent_folder_id = ?", *parentID)
+	} else {
+		query = query.Where("parent_folder_id IS NULL")
+	}
+
+	var existing int64
+	if err := query.Count(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check folder name: %w", err)
+	}
+	if existing > 0 {
+		return fmt.Errorf("folder name already exists")
+	}
+	return nil
+}
+
+func getUserFolder(ctx context.Context, db *gorm.DB, userID, folderID uuid.UUID) (*models.Folder, error) {
+	var folder models.Folder
+	if err := db.WithContext(ctx).Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("folder not found")
+		}
+		return nil, fmt.Errorf("failed to fetch folder: %w", err)
+	}
+	return &folder, nil
+}
+
+// Returns the folder and every folder below it
+func folderSubtreeIDs(ctx context.Context, db *gorm.DB, userID, folderID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	// UNION rather than UNION ALL stops the walk even if the tree somehow holds a cycle
+	if err := db.WithContext(ctx).Raw(`
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM folders WHERE id = ? AND user_id = ?
+			UNION
+			SELECT f.id FROM folders f JOIN subtree s ON f.parent_folder_id = s.id
+		)
+		SELECT id FROM subtree
+	`, folderID, userID).Scan(&ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to resolve subfolders: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,360 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type GroupService struct {
+	db *gorm.DB
+}
+
+func NewGroupService(db *gorm.DB) *GroupService {
+	return &GroupService{db: db}
+}
+
+// Lists the groups the user owns or belongs to, in name order
+func (s *GroupService) ListGroups(ctx context.Context, userID uuid.UUID) ([]models.Group, error) {
+	var groups []models.Group
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN group_members ON group_members.group_id = groups.id").
+		Where("group_members.user_id = ?", userID).
+		Preload("Owner").
+		Order("LOWER(groups.name) ASC").
+		Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch groups: %w", err)
+	}
+	return groups, nil
+}
+
+// Returns a group with its members, only members can see it
+func (s *GroupService) GetGroup(ctx context.Context, userID, groupID uuid.UUID) (*models.Group, error) {
+	if _, err := s.getMemberGroup(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	var group models.Group
+	if err := s.db.WithContext(ctx).
+		Preload("Owner").
+		Preload("Members", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		Preload("Members.User").
+		Where("id = ?", groupID).
+		First(&group).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch group: %w", err)
+	}
+	return &group, nil
+}
+
+// Creates a group with the user as its owner and first member
+func (s *GroupService) CreateGroup(ctx context.Context, userID uuid.UUID, req *types.CreateGroupRequest) (*models.Group, error) {
+	if err := s.checkNameAvailable(ctx, userID, req.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	group := &models.Group{
+		OwnerID:     userID,
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.GroupMember{GroupID: group.ID, UserID: userID}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	return group, nil
+}
+
+// Renames a group or changes its description, only the owner can
+func (s *GroupService) UpdateGroup(ctx context.Context, userID, groupID uuid.UUID, req *types.UpdateGroupRequest) (*models.Group, error) {
+	group, err := s.getOwnedGroup(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if err := s.checkNameAvailable(ctx, userID, *req.Name, group.ID); err != nil {
+			return nil, err
+		}
+		group.Name = *req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+
+	if err := s.db.WithContext(ctx).Model(group).Select("name", "description").Updates(group).Error; err != nil {
+		return nil, fmt.Errorf("failed to update group: %w", err)
+	}
+	return group, nil
+}
+
+// Deletes a group, its members lose every access they had through it
+func (s *GroupService) DeleteGroup(ctx context.Context, userID, groupID uuid.UUID) error {
+	if _, err := s.getOwnedGroup(ctx, userID, groupID); err != nil {
+		return err
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", groupID).Delete(&models.GroupShare{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", groupID).Delete(&models.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", groupID).Delete(&models.Group{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+	return nil
+}
+
+// Adds a registered user, found by email or username, to a group. Only the owner can.
+func (s *GroupService) AddMember(ctx context.Context, ownerID, groupID uuid.UUID, identifier string) (*models.GroupMember, error) {
+	if _, err := s.getOwnedGroup(ctx, ownerID, groupID); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).
+		Where("LOWER(email) = LOWER(?) OR LOWER(username) = LOWER(?)", identifier, identifier).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to fetch user: %w", err)
+	}
+
+	var existing int64
+	if err := s.db.WithContext(ctx).Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id = ?", groupID, user.ID).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check group membership: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("user is already a member")
+	}
+
+	member := &models.GroupMember{GroupID: groupID, UserID: user.ID}
+	if err := s.db.WithContext(ctx).Create(member).Error; err != nil {
+		return nil, fmt.Errorf("failed to add group member: %w", err)
+	}
+	member.User = &user
+
+	return member, nil
+}
+
+// Removes a member from a group. The owner can remove anyone but themselves, other members
+// can only leave.
+func (s *GroupService) RemoveMember(ctx context.Context, userID, groupID, memberUserID uuid.UUID) error {
+	group, err := s.getMemberGroup(ctx, userID, groupID)
+	if err != nil {
+		return err
+	}
+	if group.OwnerID != userID && memberUserID != userID {
+		return fmt.Errorf("only the group owner can manage the group")
+	}
+	if memberUserID == group.OwnerID {
+		return fmt.Errorf("group owner can't be removed")
+	}
+
+	result := s.db.WithContext(ctx).Where("group_id = ? AND user_id = ?", groupID, memberUserID).Delete(&models.GroupMember{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove group member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("member not found")
+	}
+	return nil
+}
+
+// Lists the active shares with a group whose documents are still available, newest first
+func (s *GroupService) ListGroupDocuments(ctx context.Context, userID, groupID uuid.UUID) ([]models.GroupShare, error) {
+	if _, err := s.getMemberGroup(ctx, userID, groupID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var shares []models.GroupShare
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN documents ON documents.id = group_shares.document_id AND documents.deleted_at IS NULL").
+		Where("group_shares.group_id = ? AND group_shares.is_revoked = false", groupID).
+		Where("group_shares.expires_at IS NULL OR group_shares.expires_at > ?", now).
+		Where("documents.expires_at IS NULL OR documents.expires_at > ?", now).
+		Preload("Document").
+		Order("group_shares.created_at DESC").
+		Find(&shares).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch group documents: %w", err)
+	}
+	return shares, nil
+}
+
+// Shares a document with every member of a group the owner belongs to, members added later
+// gain access too. An active share with the same group is updated instead.
+func (s *GroupService) ShareDocument(ctx context.Context, ownerID, documentID uuid.UUID, req *types.ShareWithGroupRequest) (*models.GroupShare, error) {
+	if !req.AccessLevel.IsValid() {
+		return nil, fmt.Errorf("invalid access level")
+	}
+
+	var document models.Document
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", documentID, ownerID).First(&document).Error; err != nil {
+		return nil, fmt.Errorf("document not found or not owned by user")
+	}
+
+	group, err := s.getMemberGroup(ctx, ownerID, req.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+		expiresAt = &t
+	}
+
+	var share models.GroupShare
+	err = s.db.WithContext(ctx).
+		Where("document_id = ? AND group_id = ? AND is_revoked = false", documentID, group.ID).
+		First(&share).Error
+	switch {
+	case err == nil:
+		share.AccessLevel = req.AccessLevel
+		share.ExpiresAt = expiresAt
+		if err := s.db.WithContext(ctx).Save(&share).Error; err != nil {
+			return nil, fmt.Errorf("failed to update group share: %w", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		share = models.GroupShare{
+			DocumentID:  documentID,
+			OwnerID:     ownerID,
+			GroupID:     group.ID,
+			AccessLevel: req.AccessLevel,
+			ExpiresAt:   expiresAt,
+		}
+		if err := s.db.WithContext(ctx).Create(&share).Error; err != nil {
+			return nil, fmt.Errorf("failed to create group share: %w", err)
+		}
+		s.notifyMembers(ctx, group, &document, ownerID)
+	default:
+		return nil, fmt.Errorf("failed to fetch group share: %w", err)
+	}
+
+	share.Group = group
+	return &share, nil
+}
+
+// Lists the active shares of a document with groups, only the document owner can
+func (s *GroupService) ListDocumentGroupShares(ctx context.Context, ownerID, documentID uuid.UUID) ([]models.GroupShare, error) {
+	var document models.Document
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", documentID, ownerID).First(&document).Error; err != nil {
+		return nil, fmt.Errorf("document not found or not owned by user")
+	}
+
+	var shares []models.GroupShare
+	if err := s.db.WithContext(ctx).
+		Where("document_id = ? AND is_revoked = false", documentID).
+		Preload("Group").
+		Order("created_at DESC").
+		Find(&shares).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch group shares: %w", err)
+	}
+	return shares, nil
+}
+
+// Revokes the document's share with a group, which removes the access of every member at once
+func (s *GroupService) RevokeGroupShare(ctx context.Context, ownerID, documentID, groupID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Model(&models.GroupShare{}).
+		Where("document_id = ? AND owner_id = ? AND group_id = ? AND is_revoked = false", documentID, ownerID, groupID).
+		Update("is_revoked", true)
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke group share: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("group share not found")
+	}
+	return nil
+}
+
+// Tells every member but the sharer about a new group share, failures are only logged
+func (s *GroupService) notifyMembers(ctx context.Context, group *models.Group, document *models.Document, ownerID uuid.UUID) {
+	var memberIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&models.GroupMember{}).
+		Where("group_id = ? AND user_id <> ?", group.ID, ownerID).
+		Pluck("user_id", &memberIDs).Error; err != nil {
+		logrus.Warnf("Failed to fetch members of group %s to notify: %v", group.ID, err)
+		return
+	}
+
+	metadata, _ := json.Marshal(map[string]string{
+		"groupId":   group.ID.String(),
+		"groupName": group.Name,
+	})
+	for _, memberID := range memberIDs {
+		notification := &models.ShareNotification{
+			Type:       "document_shared",
+			Title:      fmt.Sprintf("Document '%s' has been shared with you", document.Title),
+			Message:    fmt.Sprintf("Shared with your group '%s'", group.Name),
+			DocumentID: document.ID,
+			FromUserID: ownerID,
+			ToUserID:   memberID,
+			Metadata:   string(metadata),
+		}
+		if err := s.db.WithContext(ctx).Create(notification).Error; err != nil {
+			logrus.Warnf("Failed to notify user %s of group share on document %s: %v", memberID, document.ID, err)
+		}
+	}
+}
+
+// Compares names ignoring case, group names only need to be unique per owner
+func (s *GroupService) checkNameAvailable(ctx context.Context, ownerID uuid.UUID, name string, excludeID uuid.UUID) error {
+	var existing int64
+	if err := s.db.WithContext(ctx).Model(&models.Group{}).
+		Where("owner_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", ownerID, strings.TrimSpace(name), excludeID).
+		Count(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check group name: %w", err)
+	}
+	if existing > 0 {
+		return fmt.Errorf("group name already exists")
+	}
+	return nil
+}
+
+// Returns the group if the user is a member, non members get the same error as a missing group
+func (s *GroupService) getMemberGroup(ctx context.Context, userID, groupID uuid.UUID) (*models.Group, error) {
+	var group models.Group
+	if err := s.db.WithContext(ctx).
+		Joins("JOIN group_members ON group_members.group_id = groups.id").
+		Where("groups.id = ? AND group_members.user_id = ?", groupID, userID).
+		First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("group not found")
+		}
+		return nil, fmt.Errorf("failed to fetch group: %w", err)
+	}
+	return &group, nil
+}
+
+func (s *GroupService) getOwnedGroup(ctx context.Context, userID, groupID uuid.UUID) (*models.Group, error) {
+	group, err := s.getMemberGroup(ctx, userID, groupID)
+	if err != nil {
+		return nil, err
+	}
+	if group.OwnerID != userID {
+		return nil, fmt.Errorf("only the group owner can manage the group")
+	}
+	return group, nil
+}
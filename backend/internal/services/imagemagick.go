@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Default install location checked only on Windows hosts
+const windowsImageMagickPath = `C:\ImageMagick\magick.exe`
+
+// Holds the ImageMagick binaries resolved at startup
+type ImageMagick struct {
+	convertCmd  []string
+	identifyCmd []string
+	version     string // empty when the binary did not report one
+	webp        bool   // WebP is an optional delegate, not every build can write it
+}
+
+// Resolves ImageMagick once. Returns nil when it is not installed, in which
+// case PDF page counting and thumbnails are disabled.
+func DetectImageMagick(configuredPath string) *ImageMagick {
+	im := resolveImageMagick(configuredPath)
+	if im == nil {
+		logrus.Warn("ImageMagick not found, PDF page counts and thumbnails are disabled. " +
+			"Install ImageMagick or set IMAGEMAGICK_PATH to enable them")
+		return nil
+	}
+
+	im.version = toolVersion(im.convertCmd[0], append(append([]string{}, im.convertCmd[1:]...), "-version")...)
+	im.webp = detectWebPSupport(im.convertCmd)
+	logrus.Infof("ImageMagick detected: %s (%s, WebP: %t)", strings.Join(im.convertCmd, " "), im.version, im.webp)
+	return im
+}
+
+// Looks for a writable WEBP entry in the format list, e.g. "WEBP* WEBP rw- WebP Image Format"
+func detectWebPSupport(convertCmd []string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), toolVersionTimeout)
+	defer cancel()
+
+	args := append(append([]string{}, convertCmd[1:]...), "-list", "format")
+	output, err := exec.CommandContext(ctx, convertCmd[0], args...).Output()
+	if err != nil {
+		logrus.Debugf("Failed to list ImageMagick formats: %v", err)
+		return false
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && strings.TrimSuffix(fields[0], "*") == "WEBP" {
+			return strings.Contains(fields[2], "w")
+		}
+	}
+	return false
+}
+
+func resolveImageMagick(configuredPath string) *ImageMagick {
+	// Explicit path takes precedence
+	if configuredPath != "" {
+		path, err := exec.LookPath(configuredPath)
+		if err != nil {
+			logrus.Warnf("Configured ImageMagick path %q is not executable: %v", configuredPath, err)
+			return nil
+		}
+		return newImageMagick(path)
+	}
+
+	// ImageMagick 7
+	if path, err := exec.LookPath("magick"); err == nil {
+		return newImageMagick(path)
+	}
+
+	// ImageMagick 6 ships separate convert/identify binaries
+	if convertPath, err := exec.LookPath("convert"); err == nil {
+		identifyPath, err := exec.LookPath("identify")
+		if err != nil {
+			logrus.Warn("ImageMagick convert found without identify, PDF page counts are disabled")
+			return &ImageMagick{convertCmd: []string{convertPath}}
+		}
+		return &ImageMagick{
+			convertCmd:  []string{convertPath},
+			identifyCmd: []string{identifyPath},
+		}
+	}
+
+	if runtime.GOOS == "windows" {
+		if _, err := os.Stat(windowsImageMagickPath); err == nil {
+			return newImageMagick(windowsImageMagickPath)
+		}
+	}
+
+	return nil
+}
+
+// Builds commands for the single-binary ImageMagick 7 layout
+func newImageMagick(path string) *ImageMagick {
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ".exe")
+	if name == "convert" {
+		return &ImageMagick{convertCmd: []string{path}}
+	}
+	return &ImageMagick{
+		convertCmd:  []string{path},
+		identifyCmd: []string{path, "identify"},
+	}
+}
+
+// Returns the command used for conversions
+func (im *ImageMagick) Path() string {
+	if im == nil {
+		return ""
+	}
+	return strings.Join(im.convertCmd, " ")
+}
+
+// Returns the version reported at startup
+func (im *ImageMagick) Version() string {
+	if im == nil {
+		return ""
+	}
+	return im.version
+}
+
+// Reports whether thumbnails can be written as WebP
+func (im *ImageMagick) CanWriteWebP() bool {
+	return im != nil && im.webp
+}
+
+// Reports whether page counting is available
+func (im *ImageMagick) CanIdentify() bool {
+	return im != nil && len(im.identifyCmd) > 0
+}
+
+// Runs an ImageMagick convert command with the given arguments
+func (im *ImageMagick) Convert(ctx context.Context, args ...string) ([]byte, error) {
+	if im == nil {
+		return nil, fmt.Errorf("imagemagick is not available")
+	}
+	return im.run(ctx, im.convertCmd, args)
+}
+
+// Runs an ImageMagick identify command with the given arguments
+func (im *ImageMagick) Identify(ctx context.Context, args ...string) ([]byte, error) {
+	if !im.CanIdentify() {
+		return nil, fmt.Errorf("imagemagick identify is not available")
+	}
+	return im.run(ctx, im.identifyCmd, args)
+}
+
+func (im *ImageMagick) run(ctx context.Context, command []string, args []string) ([]byte, error) {
+	fullArgs := append(append([]string{}, command[1:]...), args...)
+	return exec.CommandContext(ctx, command[0], fullArgs...).CombinedOutput()
+}
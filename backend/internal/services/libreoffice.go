@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Holds the LibreOffice binary resolved at startup
+type LibreOffice struct {
+	path    string
+	version string // empty when the binary did not report one
+}
+
+// Resolves LibreOffice once. Returns nil when it is not installed, in which
+// case Office documents get no thumbnail.
+func DetectLibreOffice(configuredPath string) *LibreOffice {
+	candidates := []string{"soffice", "libreoffice"}
+	if configuredPath != "" {
+		candidates = []string{configuredPath}
+	}
+
+	for _, candidate := range candidates {
+		if path, err := exec.LookPath(candidate); err == nil {
+			version := toolVersion(path, "--version")
+			logrus.Infof("LibreOffice detected: %s (%s)", path, version)
+			return &LibreOffice{path: path, version: version}
+		}
+	}
+
+	logrus.Warn("LibreOffice not found, Office document thumbnails are disabled. " +
+		"Install LibreOffice or set LIBREOFFICE_PATH to enable them")
+	return nil
+}
+
+// Returns the resolved binary path
+func (lo *LibreOffice) Path() string {
+	if lo == nil {
+		return ""
+	}
+	return lo.path
+}
+
+// Returns the version reported at startup
+func (lo *LibreOffice) Version() string {
+	if lo == nil {
+		return ""
+	}
+	return lo.version
+}
+
+// Converts a document to PDF inside outDir and returns the PDF path
+func (lo *LibreOffice) ConvertToPDF(ctx context.Context, inputPath, outDir string) (string, error) {
+	return lo.Convert(ctx, inputPath, outDir, "pdf", ".pdf")
+}
+
+// Converts a document with the given export filter inside outDir and returns the output path
+func (lo *LibreOffice) Convert(ctx context.Context, inputPath, outDir, filter, ext string) (string, error) {
+	if lo == nil {
+		return "", fmt.Errorf("libreoffice is not available")
+	}
+
+	// Separate profile per run so concurrent conversions don't fight over the lock
+	profileDir, err := os.MkdirTemp(outDir, "lo-profile-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create LibreOffice profile dir: %w", err)
+	}
+	defer os.RemoveAll(profileDir)
+
+	absProfileDir, err := filepath.Abs(profileDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve LibreOffice profile dir: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, lo.path,
+		"-env:UserInstallation=file:///"+strings.TrimPrefix(filepath.ToSlash(absProfileDir), "/"),
+		"--headless",
+		"--convert-to", filter,
+		"--outdir", outDir,
+		inputPath,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("LibreOffice conversion failed: %s, error: %w", string(output), err)
+	}
+
+	outputPath := filepath.Join(outDir, strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))+ext)
+	if _, err := os.Stat(outputPath); err != nil {
+		return "", fmt.Errorf("LibreOffice produced no %s output: %s", strings.TrimPrefix(ext, "."), string(output))
+	}
+
+	return outputPath, nil
+}
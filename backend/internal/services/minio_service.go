@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/metrics"
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -32,6 +33,24 @@ const (
 	MIMEApplicationOctetStream = "application/octet-stream" // Default type.
 )
 
+// Uploader defines the interface for file storage operations.
+// This decouples the services and handlers from a specific implementation like Minio,
+// tests inject testutil.MockUploader instead.
+type Uploader interface {
+	BucketName() string
+	UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string) error
+	UploadThumbnail(ctx context.Context, objectName string, data []byte, contentType string) (*UploadResult, error)
+	CopyFile(ctx context.Context, srcObject, dstObject string) error
+	GetFileUrl(bucketName, objectName string) string
+	DownloadFile(ctx context.Context, objectName string) (io.ReadCloser, error)
+	DownloadFileRange(ctx context.Context, objectName string, start, end int64) (io.ReadCloser, error)
+	FileExists(ctx context.Context, objectName string) (bool, error)
+	DeleteFile(ctx context.Context, objectName string) error
+	GeneratePresignedURL(ctx context.Context, objectName string, expiry time.Duration) (string, error)
+}
+
+var _ Uploader = (*MinIOService)(nil)
+
 type MinIOService struct {
 	client *minio.Client
 	config *config.MinIOConfig
@@ -88,10 +107,29 @@ func (s *MinIOService) ensureBucket(ctx context.Context) error {
 	return nil
 }
 
+// Reports whether MinIO is reachable and the configured bucket exists
+func (s *MinIOService) HealthCheck(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.config.BucketName)
+	if err != nil {
+		return fmt.Errorf("failed to reach MinIO: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.config.BucketName)
+	}
+	return nil
+}
+
+// BucketName returns the bucket documents and thumbnails are stored in
+func (s *MinIOService) BucketName() string {
+	return s.config.BucketName
+}
+
 func (s *MinIOService) UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string) error {
+	start := time.Now()
 	_, err := s.client.PutObject(ctx, bucketName, objectName, reader, size, minio.PutObjectOptions{
 		ContentType: contentType,
 	})
+	metrics.ObserveStorageOperation("upload", start, err)
 	if err != nil {
 		return fmt.Errorf("failed to upload file to MinIO: %w", err)
 	}
@@ -99,16 +137,61 @@ func (s *MinIOService) UploadFile(ctx context.Context, bucketName, objectName st
 	return nil
 }
 
+// CopyFile copies an object within the bucket, overwriting the destination
+func (s *MinIOService) CopyFile(ctx context.Context, srcObject, dstObject string) error {
+	start := time.Now()
+	_, err := s.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: s.config.BucketName, Object: dstObject},
+		minio.CopySrcOptions{Bucket: s.config.BucketName, Object: srcObject},
+	)
+	metrics.ObserveStorageOperation("copy", start, err)
+	if err != nil {
+		return fmt.Errorf("failed to copy object %s to %s: %w", srcObject, dstObject, err)
+	}
+	return nil
+}
+
 func (s *MinIOService) GetFileUrl(bucketName, objectName string) string {
 	return fmt.Sprintf("%s/%s/%s", s.client.EndpointURL(), bucketName, objectName)
 }
 
+// Downloads are not timed, GetObject only sends the request once the reader is first used
 func (s *MinIOService) DownloadFile(ctx context.Context, objectName string) (io.ReadCloser, error) {
 	return s.client.GetObject(ctx, s.config.BucketName, objectName, minio.GetObjectOptions{})
 }
 
+// DownloadFileRange reads the bytes from start to end of an object, both inclusive
+func (s *MinIOService) DownloadFileRange(ctx context.Context, objectName string, start, end int64) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(start, end); err != nil {
+		return nil, fmt.Errorf("invalid range %d-%d: %w", start, end, err)
+	}
+	return s.client.GetObject(ctx, s.config.BucketName, objectName, opts)
+}
+
+// FileExists reports whether an object is still present in the bucket
+func (s *MinIOService) FileExists(ctx context.Context, objectName string) (bool, error) {
+	start := time.Now()
+	_, err := s.client.StatObject(ctx, s.config.BucketName, objectName, minio.StatObjectOptions{})
+	// A missing object is an answer, not a failed call
+	outcome := err
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		outcome = nil
+	}
+	metrics.ObserveStorageOperation("stat", start, outcome)
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to stat object %s: %w", objectName, err)
+	}
+	return true, nil
+}
+
 func (s *MinIOService) DeleteFile(ctx context.Context, objectName string) error {
+	start := time.Now()
 	err := s.client.RemoveObject(ctx, s.config.BucketName, objectName, minio.RemoveObjectOptions{})
+	metrics.ObserveStorageOperation("delete", start, err)
 	if err != nil {
 		return fmt.Errorf("failed to delete object from MinIO: %w", err)
 	}
@@ -119,7 +202,9 @@ func (s *MinIOService) DeleteFile(ctx context.Context, objectName string) error
 // GeneratePresignedURL, dışarıdan erişim için bir URL üretir (örn. kullanıcı arayüzü).
 func (s *MinIOService) GeneratePresignedURL(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
 	logrus.Infof("[MINIO] Generating presigned URL for object: %s, bucket: %s, expiry: %s", objectName, s.config.BucketName, expiry)
+	start := time.Now()
 	url, err := s.client.PresignedGetObject(ctx, s.config.BucketName, objectName, expiry, nil)
+	metrics.ObserveStorageOperation("presign", start, err)
 	if err != nil {
 		logrus.Errorf("[MINIO] Failed to generate presigned URL for object %s: %v", objectName, err)
 		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
@@ -139,6 +224,7 @@ func (s *MinIOService) generateObjectName(userID uuid.UUID, originalFileName str
 
 func (s *MinIOService) UploadThumbnail(ctx context.Context, objectName string, data []byte, contentType string) (*UploadResult, error) {
 	reader := bytes.NewReader(data)
+	start := time.Now()
 	uploadInfo, err := s.client.PutObject(ctx, s.config.BucketName, objectName, reader, int64(len(data)), minio.PutObjectOptions{
 		ContentType: contentType,
 		UserMetadata: map[string]string{
@@ -146,6 +232,7 @@ func (s *MinIOService) UploadThumbnail(ctx context.Context, objectName string, d
 			"uploaded-at": time.Now().UTC().Format(time.RFC3339),
 		},
 	})
+	metrics.ObserveStorageOperation("upload", start, err)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload thumbnail to MinIO: %w", err)
 	}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/sirupsen/logrus"
+)
+
+// Builds the preview strategies named in names, in order. Unknown names are skipped.
+func NewPreviewStrategies(names []string, minioService Uploader) []types.PreviewStrategy {
+	available := map[string]types.PreviewStrategy{
+		"pdf":    &pdfPreviewStrategy{minioService: minioService},
+		"office": &officePreviewStrategy{minioService: minioService},
+		"image":  &imagePreviewStrategy{minioService: minioService},
+		"text":   &textPreviewStrategy{},
+	}
+
+	strategies := make([]types.PreviewStrategy, 0, len(names))
+	for _, name := range names {
+		strategy, ok := available[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			logrus.Warnf("[PREVIEW] Unknown preview strategy %q, skipping", name)
+			continue
+		}
+		strategies = append(strategies, strategy)
+	}
+	return strategies
+}
+
+// Presigns objectName and describes it as a preview of the given type
+func presignedPreview(ctx context.Context, minioService Uploader, objectName string, expiry time.Duration, previewType types.PreviewType, mimeType, strategy string) (*types.DocumentPreviewResponse, error) {
+	url, err := minioService.GeneratePresignedURL(ctx, objectName, expiry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate preview URL: %w", err)
+	}
+
+	expiresAt := time.Now().Add(expiry)
+	return &types.DocumentPreviewResponse{
+		PreviewType: previewType,
+		URL:         url,
+		MimeType:    mimeType,
+		Strategy:    strategy,
+		ExpiresAt:   &expiresAt,
+	}, nil
+}
+
+// Serves PDFs directly, browsers render them natively
+type pdfPreviewStrategy struct {
+	minioService Uploader
+}
+
+func (s *pdfPreviewStrategy) Name() string { return "pdf" }
+
+func (s *pdfPreviewStrategy) CanHandle(document *models.Document) bool {
+	return document.FileType == models.DocumentTypePDF || document.MimeType == "application/pdf"
+}
+
+func (s *pdfPreviewStrategy) Preview(ctx context.Context, document *models.Document, urlExpiry time.Duration) (*types.DocumentPreviewResponse, error) {
+	return presignedPreview(ctx, s.minioService, document.StoragePath, urlExpiry, types.PreviewTypePDF, "application/pdf", s.Name())
+}
+
+// Serves the PDF rendition produced by the preview worker for Office documents
+type officePreviewStrategy struct {
+	minioService Uploader
+}
+
+func (s *officePreviewStrategy) Name() string { return "office" }
+
+func (s *officePreviewStrategy) CanHandle(document *models.Document) bool {
+	switch document.FileType {
+	case models.DocumentTypeDOCX, models.DocumentTypeXLSX, models.DocumentTypePPTX:
+		return document.PreviewPath != ""
+	default:
+		return false
+	}
+}
+
+func (s *officePreviewStrategy) Preview(ctx context.Context, document *models.Document, urlExpiry time.Duration) (*types.DocumentPreviewResponse, error) {
+	return presignedPreview(ctx, s.minioService, document.PreviewPath, urlExpiry, types.PreviewTypePDF, "application/pdf", s.Name())
+}
+
+// Serves raster images directly. SVG is excluded since it can carry scripts.
+type imagePreviewStrategy struct {
+	minioService Uploader
+}
+
+func (s *imagePreviewStrategy) Name() string { return "image" }
+
+func (s *imagePreviewStrategy) CanHandle(document *models.Document) bool {
+	return strings.HasPrefix(document.MimeType, "image/") && document.MimeType != "image/svg+xml"
+}
+
+func (s *imagePreviewStrategy) Preview(ctx context.Context, document *models.Document, urlExpiry time.Duration) (*types.DocumentPreviewResponse, error) {
+	return presignedPreview(ctx, s.minioService, document.StoragePath, urlExpiry, types.PreviewTypeImage, document.MimeType, s.Name())
+}
+
+// Points at the inline text endpoint, which serves the content as plain UTF-8
+type textPreviewStrategy struct{}
+
+func (s *textPreviewStrategy) Name() string { return "text" }
+
+func (s *textPreviewStrategy) CanHandle(document *models.Document) bool {
+	return IsTextDocument(document)
+}
+
+func (s *textPreviewStrategy) Preview(ctx context.Context, document *models.Document, urlExpiry time.Duration) (*types.DocumentPreviewResponse, error) {
+	return &types.DocumentPreviewResponse{
+		PreviewType: types.PreviewTypeText,
+		URL:         fmt.Sprintf("/api/v1/documents/%s/text", document.ID),
+		MimeType:    "text/plain; charset=utf-8",
+		Strategy:    s.Name(),
+	}, nil
+}
+
+// IsMarkdownDocument reports whether the text document is written in Markdown
+func IsMarkdownDocument(document *models.Document) bool {
+	if document.MimeType == MIMETextMarkdown || document.MimeType == "text/x-markdown" {
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(document.OriginalFileName)) {
+	case ".md", ".markdown":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTextDocument reports whether the document can be displayed as plain text
+func IsTextDocument(document *models.Document) bool {
+	return document.FileType == models.DocumentTypeTXT || strings.HasPrefix(document.MimeType, "text/")
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type QuotaService struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+	config      *config.QuotaConfig
+}
+
+func NewQuotaService(db *gorm.DB, redisClient *redis.Client, cfg *config.QuotaConfig) *QuotaService {
+	return &QuotaService{
+		db:          db,
+		redisClient: redisClient,
+		config:      cfg,
+	}
+}
+
+// Retrieves current usage against the user's quota, cached briefly in Redis
+func (s *QuotaService) GetUserQuota(ctx context.Context, userID uuid.UUID) (*types.QuotaResponse, error) {
+	cacheKey := s.cacheKey(userID)
+
+	if s.redisClient != nil {
+		if cached, err := s.redisClient.Get(cacheKey); err == nil && cached != "" {
+			var quota types.QuotaResponse
+			if err := json.Unmarshal([]byte(cached), &quota); err == nil {
+				return &quota, nil
+			}
+		}
+	}
+
+	var usage struct {
+		DocumentCount int64
+		StorageUsed   int64
+	}
+	if err := s.db.WithContext(ctx).
+		Model(&models.Document{}).
+		Select("COUNT(*) AS document_count, COALESCE(SUM(file_size), 0) AS storage_used").
+		Where("user_id = ?", userID).
+		Scan(&usage).Error; err != nil {
+		return nil, fmt.Errorf("failed to calculate quota usage: %w", err)
+	}
+
+	storage := buildQuotaUsage(usage.StorageUsed, s.config.MaxStorageBytes)
+	documents := buildQuotaUsage(usage.DocumentCount, s.config.MaxDocuments)
+
+	quota := &types.QuotaResponse{
+		Plan:        s.config.PlanName,
+		Storage:     storage,
+		Documents:   documents,
+		IsOverLimit: storage.IsOver || documents.IsOver,
+	}
+
+	if s.redisClient != nil && s.config.CacheTTL > 0 {
+		if data, err := json.Marshal(quota); err == nil {
+			if err := s.redisClient.SetWithExpiry(cacheKey, data, s.config.CacheTTL); err != nil {
+				logrus.Warnf("Failed to cache quota for user %s: %v", userID, err)
+			}
+		}
+	}
+
+	return quota, nil
+}
+
+func (s *QuotaService) cacheKey(userID uuid.UUID) string {
+	return quotaCacheKey(userID)
+}
+
+func quotaCacheKey(userID uuid.UUID) string {
+	return fmt.Sprintf("quota:%s", userID.String())
+}
+
+// Builds usage figures for a single limit, a non-positive limit means unlimited
+func buildQuotaUsage(used, limit int64) types.QuotaUsage {
+	usage := types.QuotaUsage{
+		Used:  used,
+		Limit: limit,
+	}
+
+	if limit <= 0 {
+		usage.Remaining = -1
+		return usage
+	}
+
+	usage.Remaining = limit - used
+	if usage.Remaining < 0 {
+		usage.Remaining = 0
+	}
+	usage.IsOver = used > limit
+
+	return usage
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// How long role permissions are served from Redis before they are read again
+const rolePermissionsCacheTTL = 10 * time.Minute
+
+func rolePermissionsCacheKey(roleID uuid.UUID) string {
+	return "role:permissions:" + roleID.String()
+}
+
+// Returns the permission names of a role. They are cached in Redis so permission checks don't
+// hit the database on every request, RoleService drops the entry when the role changes.
+func (s *AuthService) GetRolePermissions(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	key := rolePermissionsCacheKey(roleID)
+	if s.redis != nil {
+		if cached, err := s.redis.Get(ctx, key).Result(); err == nil {
+			var permissions []string
+			if err := json.Unmarshal([]byte(cached), &permissions); err == nil {
+				return permissions, nil
+			}
+		}
+	}
+
+	var role models.Role
+	if err := s.db.WithContext(ctx).Preload("Permissions").First(&role, "id = ?", roleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("role not found")
+		}
+		return nil, fmt.Errorf("failed to get role permissions: %w", err)
+	}
+	permissions := role.PermissionNames()
+
+	if s.redis != nil {
+		if data, err := json.Marshal(permissions); err == nil {
+			if err := s.redis.Set(ctx, key, data, rolePermissionsCacheTTL).Err(); err != nil {
+				s.logger.WithError(err).Warn("Failed to cache role permissions")
+			}
+		}
+	}
+
+	return permissions, nil
+}
+
+// Drops the cached permissions of a role so the next check reads them from the database
+func (s *AuthService) InvalidateRolePermissions(ctx context.Context, roleID uuid.UUID) {
+	if s.redis == nil {
+		return
+	}
+	if err := s.redis.Del(ctx, rolePermissionsCacheKey(roleID)).Err(); err != nil {
+		s.logger.WithError(err).WithField("role_id", roleID).Warn("Failed to invalidate cached role permissions")
+	}
+}
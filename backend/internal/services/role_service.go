@@ -10,15 +10,22 @@ import (
 	"gorm.io/gorm"
 )
 
+// Drops cached role permissions once a role changes, implemented by AuthService
+type RolePermissionInvalidator interface {
+	InvalidateRolePermissions(ctx context.Context, roleID uuid.UUID)
+}
+
 type RoleService struct {
-	db     *gorm.DB
-	logger *logrus.Entry
+	db          *gorm.DB
+	permissions RolePermissionInvalidator
+	logger      *logrus.Entry
 }
 
-func NewRoleService(db *gorm.DB) *RoleService {
+func NewRoleService(db *gorm.DB, permissions RolePermissionInvalidator) *RoleService {
 	return &RoleService{
-		db:     db,
-		logger: logrus.WithField("service", "role"),
+		db:          db,
+		permissions: permissions,
+		logger:      logrus.WithField("service", "role"),
 	}
 }
 
@@ -190,6 +197,7 @@ func (s *RoleService) UpdateRole(ctx context.Context, roleID uuid.UUID, req *Upd
 	}
 
 	tx.Commit()
+	s.permissions.InvalidateRolePermissions(ctx, role.ID)
 
 	// Return updated role with permissions
 	if err := s.db.Preload("Permissions").Where("id = ?", role.ID).First(&role).Error; err != nil {
@@ -245,6 +253,7 @@ func (s *RoleService) DeleteRole(ctx context.Context, roleID uuid.UUID) error {
 	}
 
 	tx.Commit()
+	s.permissions.InvalidateRolePermissions(ctx, role.ID)
 
 	s.logger.Infof("Role deleted: %s", role.Name)
 	return nil
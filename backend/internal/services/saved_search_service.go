@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Saved searches per user, enough for a sidebar without turning into a second document list
+const savedSearchMaxPerUser = 25
+
+type SavedSearchService struct {
+	db              *gorm.DB
+	documentService *DocumentService
+}
+
+func NewSavedSearchService(db *gorm.DB, documentService *DocumentService) *SavedSearchService {
+	return &SavedSearchService{
+		db:              db,
+		documentService: documentService,
+	}
+}
+
+// Lists the user's saved searches in name order
+func (s *SavedSearchService) ListSavedSearches(ctx context.Context, userID uuid.UUID) ([]models.SavedSearch, error) {
+	var searches []models.SavedSearch
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("LOWER(name) ASC").Find(&searches).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+// Saves a set of filters under a name unique to the user
+func (s *SavedSearchService) CreateSavedSearch(ctx context.Context, userID uuid.UUID, req *types.CreateSavedSearchRequest) (*models.SavedSearch, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to count saved searches: %w", err)
+	}
+	if count >= savedSearchMaxPerUser {
+		return nil, fmt.Errorf("saved search limit reached")
+	}
+
+	var existing int64
+	if err := s.db.WithContext(ctx).Model(&models.SavedSearch{}).
+		Where("user_id = ? AND name = ?", userID, req.Name).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to check saved search name: %w", err)
+	}
+	if existing > 0 {
+		return nil, fmt.Errorf("saved search name already exists")
+	}
+
+	search := &models.SavedSearch{
+		UserID:   userID,
+		Name:     req.Name,
+		Query:    req.Query,
+		FileType: req.FileType,
+		Tags:     req.Tags,
+		SortBy:   req.SortBy,
+		SortDir:  req.SortDir,
+	}
+	if err := s.db.WithContext(ctx).Create(search).Error; err != nil {
+		return nil, fmt.Errorf("failed to create saved search: %w", err)
+	}
+
+	return search, nil
+}
+
+func (s *SavedSearchService) DeleteSavedSearch(ctx context.Context, userID, searchID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", searchID, userID).Delete(&models.SavedSearch{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete saved search: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("saved search not found")
+	}
+	return nil
+}
+
+// Runs a saved search through the regular document search
+func (s *SavedSearchService) RunSavedSearch(ctx context.Context, userID, searchID uuid.UUID, page, limit int) (*types.DocumentListResponse, error) {
+	var search models.SavedSearch
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", searchID, userID).First(&search).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("saved search not found")
+		}
+		return nil, fmt.Errorf("failed to fetch saved search: %w", err)
+	}
+
+	return s.documentService.GetDocuments(ctx, userID, &types.DocumentListRequest{
+		Page:     page,
+		Limit:    limit,
+		Search:   search.Query,
+		FileType: search.FileType,
+		Tags:     search.Tags,
+		SortBy:   search.SortBy,
+		SortDir:  search.SortDir,
+	})
+}
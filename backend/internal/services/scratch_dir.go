@@ -0,0 +1,82 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Subdirectory of the configured temp dir owned by this app, the sweep never looks outside it
+const scratchDirName = "noesis-forge"
+
+// Where document processing writes its intermediate files. Every operation works in its own
+// directory so concurrent jobs can't collide and a single RemoveAll cleans up after each.
+type ScratchDir struct {
+	root string
+}
+
+// Creates the scratch root below base, os.TempDir() when base is empty
+func NewScratchDir(base string) (*ScratchDir, error) {
+	if base == "" {
+		base = os.TempDir()
+	}
+
+	root := filepath.Join(base, scratchDirName)
+	if err := os.MkdirAll(root, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create temp dir %s: %w", root, err)
+	}
+
+	return &ScratchDir{root: root}, nil
+}
+
+// Returns the scratch root
+func (d *ScratchDir) Path() string {
+	return d.root
+}
+
+// Creates a private directory for one operation. The returned cleanup removes it with
+// everything inside and is safe to defer right away, it is a no-op when creation failed.
+func (d *ScratchDir) Create(pattern string) (string, func(), error) {
+	dir, err := os.MkdirTemp(d.root, pattern)
+	if err != nil {
+		return "", func() {}, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+
+	cleanup := func() {
+		if err := os.RemoveAll(dir); err != nil {
+			logrus.Warnf("Failed to remove temp dir %s: %v", dir, err)
+		}
+	}
+	return dir, cleanup, nil
+}
+
+// Removes entries left behind by operations that never got to clean up, e.g. when the
+// process was killed mid-conversion. Only entries untouched for maxAge are removed so
+// another instance sharing the directory keeps the files it is working on.
+func (d *ScratchDir) Sweep(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(d.root)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read temp dir %s: %w", d.root, err)
+	}
+
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(d.root, entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			logrus.Warnf("Failed to remove stale temp entry %s: %v", path, err)
+			continue
+		}
+		removed++
+	}
+
+	return removed, nil
+}
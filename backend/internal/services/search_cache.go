@@ -0,0 +1,164 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Generation of a user's cached results, bumping it orphans every entry cached before
+	searchCacheGenerationPrefix = "search:generation:"
+	// Cached result, "<userID>:<generation>:<request hash>"
+	searchCacheEntryPrefix = "search:result:"
+)
+
+// Document as cached, carrying the storage paths the listing needs that json leaves out
+type cachedSearchDocument struct {
+	models.Document
+	StoragePath   string `json:"storagePath"`
+	ThumbnailPath string `json:"thumbnailPath"`
+	PreviewPath   string `json:"previewPath"`
+}
+
+type cachedSearchResult struct {
+	Documents  []cachedSearchDocument `json:"documents"`
+	Total      int64                  `json:"total"`
+	Page       int                    `json:"page"`
+	Limit      int                    `json:"limit"`
+	TotalPages int                    `json:"totalPages"`
+}
+
+// SearchCache keeps search results per user and request for a short time so repeated
+// searches skip the strategy pipeline. Any change to a user's documents invalidates all
+// of their entries. A nil SearchCache caches nothing.
+type SearchCache struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+// Returns nil, caching disabled, without Redis or with a zero TTL
+func NewSearchCache(redisClient *redis.Client, ttl time.Duration) *SearchCache {
+	if redisClient == nil || ttl <= 0 {
+		return nil
+	}
+	return &SearchCache{redisClient: redisClient, ttl: ttl}
+}
+
+// Returns the cached result of the request, nil on a miss. Redis errors count as misses.
+func (c *SearchCache) Get(req *types.SearchRequest) *types.SearchResult {
+	if c == nil {
+		return nil
+	}
+
+	key, err := c.entryKey(req)
+	if err != nil {
+		logrus.Warnf("Search cache lookup failed for user %s: %v", req.UserID, err)
+		return nil
+	}
+
+	value, err := c.redisClient.Get(key)
+	if err != nil {
+		if err != goredis.Nil {
+			logrus.Warnf("Search cache lookup failed for user %s: %v", req.UserID, err)
+		}
+		return nil
+	}
+
+	var cached cachedSearchResult
+	if err := json.Unmarshal([]byte(value), &cached); err != nil {
+		logrus.Warnf("Discarding unreadable search cache entry %s: %v", key, err)
+		return nil
+	}
+
+	result := &types.SearchResult{
+		Documents:  make([]models.Document, len(cached.Documents)),
+		Total:      cached.Total,
+		Page:       cached.Page,
+		Limit:      cached.Limit,
+		TotalPages: cached.TotalPages,
+	}
+	for i, doc := range cached.Documents {
+		result.Documents[i] = doc.Document
+		result.Documents[i].StoragePath = doc.StoragePath
+		result.Documents[i].ThumbnailPath = doc.ThumbnailPath
+		result.Documents[i].PreviewPath = doc.PreviewPath
+	}
+	return result
+}
+
+// Caches the result of the request
+func (c *SearchCache) Set(req *types.SearchRequest, result *types.SearchResult) {
+	if c == nil {
+		return
+	}
+
+	key, err := c.entryKey(req)
+	if err != nil {
+		logrus.Warnf("Failed to cache search for user %s: %v", req.UserID, err)
+		return
+	}
+
+	cached := cachedSearchResult{
+		Documents:  make([]cachedSearchDocument, len(result.Documents)),
+		Total:      result.Total,
+		Page:       result.Page,
+		Limit:      result.Limit,
+		TotalPages: result.TotalPages,
+	}
+	for i, doc := range result.Documents {
+		cached.Documents[i] = cachedSearchDocument{
+			Document:      doc,
+			StoragePath:   doc.StoragePath,
+			ThumbnailPath: doc.ThumbnailPath,
+			PreviewPath:   doc.PreviewPath,
+		}
+	}
+
+	value, err := json.Marshal(cached)
+	if err != nil {
+		logrus.Warnf("Failed to cache search for user %s: %v", req.UserID, err)
+		return
+	}
+	if err := c.redisClient.SetWithExpiry(key, value, c.ttl); err != nil {
+		logrus.Warnf("Failed to cache search for user %s: %v", req.UserID, err)
+	}
+}
+
+// Drops every cached result of the user
+func (c *SearchCache) Invalidate(userID uuid.UUID) {
+	if c == nil {
+		return
+	}
+	if _, err := c.redisClient.Increment(searchCacheGenerationPrefix + userID.String()); err != nil {
+		logrus.Warnf("Failed to invalidate search cache of user %s: %v", userID, err)
+	}
+}
+
+// Builds the key from the user's current generation and a hash of the normalized request
+func (c *SearchCache) entryKey(req *types.SearchRequest) (string, error) {
+	generation := int64(0)
+	value, err := c.redisClient.Get(searchCacheGenerationPrefix + req.UserID.String())
+	if err == nil {
+		generation, _ = strconv.ParseInt(value, 10, 64)
+	} else if err != goredis.Nil {
+		return "", err
+	}
+
+	// Map keys of the custom field filters are sorted by encoding/json, equal requests hash equally
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode search request: %w", err)
+	}
+
+	return fmt.Sprintf("%s%s:%d:%x", searchCacheEntryPrefix, req.UserID, generation, sha256.Sum256(encoded)), nil
+}
@@ -2,29 +2,47 @@ package services
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
 	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
+const (
+	// Lifetime of the access token issued after unlocking a password protected link
+	SharedLinkAccessTTL = 30 * time.Minute
+
+	sharedLinkMaxUnlockFailures = 5
+	sharedLinkUnlockWindow      = 15 * time.Minute
+)
+
 // Handles creation & validation of shared links.
 type ShareService struct {
-	db    *gorm.DB
-	redis *redis.Client // optional, may be nil
+	db              *gorm.DB
+	redis           *redis.Client // optional, may be nil
+	activityService *ActivityService
+	accessSecret    []byte // signs the access tokens of unlocked links
+	holdUnscanned   bool   // files waiting for their virus scan are not served
 }
 
-func NewShareService(db *gorm.DB, redisClient *redis.Client) *ShareService {
-	return &ShareService{db: db, redis: redisClient}
+func NewShareService(db *gorm.DB, redisClient *redis.Client, accessSecret string, holdUnscanned bool) *ShareService {
+	return &ShareService{db: db, redis: redisClient, activityService: NewActivityService(db), accessSecret: []byte(accessSecret), holdUnscanned: holdUnscanned}
 }
 
 // Creates a new public share link for a document.
-func (s *ShareService) CreatePublicShare(ctx context.Context, ownerID, documentID uuid.UUID, expiresInDays int, maxDownloads *int) (*models.SharedLink, error) {
+// An empty password leaves the link unprotected.
+func (s *ShareService) CreatePublicShare(ctx context.Context, ownerID, documentID uuid.UUID, expiresInDays int, maxDownloads *int, password string) (*models.SharedLink, error) {
 	// generate random 128-bit token
 	b := make([]byte, 16)
 	if _, err := rand.Read(b); err != nil {
@@ -46,6 +64,14 @@ func (s *ShareService) CreatePublicShare(ctx context.Context, ownerID, documentI
 		MaxDownloads: maxDownloads,
 	}
 
+	if password != "" {
+		hash, err := utils.HashPassword(password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		link.PasswordHash = hash
+	}
+
 	if err := s.db.WithContext(ctx).Create(link).Error; err != nil {
 		return nil, err
 	}
@@ -56,8 +82,73 @@ func (s *ShareService) CreatePublicShare(ctx context.Context, ownerID, documentI
 	return link, nil
 }
 
-// Validates token, increments download count, returns document.
-func (s *ShareService) ValidateToken(ctx context.Context, token string, clientIP, userAgent string) (*models.Document, error) {
+// Checks the password of a protected link and returns an access token that ValidateToken
+// accepts until it expires. Wrong passwords are limited per link.
+func (s *ShareService) UnlockLink(ctx context.Context, token, password, clientIP, userAgent string) (string, time.Time, error) {
+	if s.redis != nil {
+		failures, err := s.redis.GetShareUnlockFailures(token)
+		if err != nil {
+			logrus.Warnf("[SHARE] Failed to read unlock attempts: %v", err)
+		} else if failures >= sharedLinkMaxUnlockFailures {
+			return "", time.Time{}, fmt.Errorf("too many password attempts")
+		}
+	}
+
+	var link models.SharedLink
+	if err := s.db.WithContext(ctx).Preload("Document").Where("token = ?", token).First(&link).Error; err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid or expired link")
+	}
+	if err := checkSharedLink(&link); err != nil {
+		return "", time.Time{}, err
+	}
+	if link.PasswordHash == "" {
+		return "", time.Time{}, fmt.Errorf("link is not password protected")
+	}
+
+	if !utils.CheckPasswordHash(password, link.PasswordHash) {
+		if s.redis != nil {
+			if _, err := s.redis.IncrementShareUnlockFailure(token, sharedLinkUnlockWindow); err != nil {
+				logrus.Warnf("[SHARE] Failed to record unlock attempt: %v", err)
+			}
+		}
+		s.createAuditLog(ctx, link.ID, "unlock_failed", clientIP, userAgent)
+		return "", time.Time{}, fmt.Errorf("invalid password")
+	}
+
+	if s.redis != nil {
+		s.redis.ClearShareUnlockFailures(token)
+	}
+	s.createAuditLog(ctx, link.ID, "unlocked", clientIP, userAgent)
+
+	expiresAt := time.Now().Add(SharedLinkAccessTTL)
+	return s.signLinkAccess(&link, expiresAt), expiresAt, nil
+}
+
+// Access tokens are "<expiry>.<signature>", signed with the server secret. The password hash
+// is part of the signed data so changing the password invalidates every token issued before.
+func (s *ShareService) signLinkAccess(link *models.SharedLink, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	mac := hmac.New(sha256.New, s.accessSecret)
+	mac.Write([]byte(link.ID.String() + ":" + expiry + ":" + link.PasswordHash))
+	return expiry + "." + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *ShareService) verifyLinkAccess(link *models.SharedLink, accessToken string) bool {
+	expiry, _, ok := strings.Cut(accessToken, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(accessToken), []byte(s.signLinkAccess(link, time.Unix(unix, 0))))
+}
+
+// Validates token, increments download count, returns document. Previews count against the
+// download limit as well since the presigned URL gives access to the whole file. Password
+// protected links also need the access token issued by UnlockLink.
+func (s *ShareService) ValidateToken(ctx context.Context, token, accessToken, clientIP, userAgent string, preview bool) (*models.Document, error) {
 	// brute-force protection using Redis
 	if s.redis != nil {
 		const maxAttempts = 20
@@ -73,29 +164,85 @@ func (s *ShareService) ValidateToken(ctx context.Context, token string, clientIP
 	}
 
 	var link models.SharedLink
-	if err := s.db.Preload("Document").Where("token = ? AND is_revoked = false", token).First(&link).Error; err != nil {
+	if err := s.db.WithContext(ctx).Preload("Document").Where("token = ?", token).First(&link).Error; err != nil {
 		return nil, fmt.Errorf("invalid or expired link")
 	}
-
-	// expiry check
-	if link.ExpiresAt != nil && link.ExpiresAt.Before(time.Now()) {
-		return nil, fmt.Errorf("link expired")
+	if err := checkSharedLink(&link); err != nil {
+		return nil, err
+	}
+	if link.PasswordHash != "" && !s.verifyLinkAccess(&link, accessToken) {
+		return nil, fmt.Errorf("password required")
+	}
+	// Checked before the access is counted, a held file is served once its scan is done
+	if err := checkScanned(link.Document, s.holdUnscanned); err != nil {
+		if err.Error() == "document is quarantined" {
+			return nil, fmt.Errorf("document no longer available")
+		}
+		return nil, err
 	}
 
-	// download limit check
-	if link.MaxDownloads != nil && link.DownloadCount >= *link.MaxDownloads {
+	// The conditions are repeated in the update so concurrent requests cannot exceed the limit
+	now := time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&models.SharedLink{}).
+		Where("id = ? AND is_revoked = false", link.ID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Where("max_downloads IS NULL OR download_count < max_downloads").
+		Updates(map[string]interface{}{
+			"download_count":   gorm.Expr("download_count + 1"),
+			"last_accessed_at": now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to record link access: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		// Lost a race against another download or a revocation
+		if err := s.db.WithContext(ctx).Where("id = ?", link.ID).First(&link).Error; err == nil {
+			if err := checkSharedLink(&link); err != nil {
+				return nil, err
+			}
+		}
 		return nil, fmt.Errorf("download limit reached")
 	}
 
-	// increment counter atomically
-	s.db.Model(&link).UpdateColumn("download_count", gorm.Expr("download_count + 1"))
+	action := "downloaded"
+	if preview {
+		action = "previewed"
+	}
+	s.createAuditLog(ctx, link.ID, action, clientIP, userAgent)
 
-	// audit log
-	s.createAuditLog(ctx, link.ID, "downloaded", clientIP, userAgent)
+	activityCtx := &ActivityContext{
+		UserID:     link.OwnerID,
+		DocumentID: link.DocumentID,
+		IPAddress:  clientIP,
+		UserAgent:  userAgent,
+		Source:     "public_link",
+	}
+	if err := s.activityService.LogSharedLinkAccess(activityCtx, link.Document, preview); err != nil {
+		logrus.Warnf("[SHARE] Failed to log access activity for link %s: %v", link.ID, err)
+	}
 
 	return link.Document, nil
 }
 
+// Returns why a link can no longer be used, or nil when it is valid
+func checkSharedLink(link *models.SharedLink) error {
+	if link.IsRevoked {
+		return fmt.Errorf("link revoked")
+	}
+	if link.ExpiresAt != nil && link.ExpiresAt.Before(time.Now()) {
+		return fmt.Errorf("link expired")
+	}
+	if link.MaxDownloads != nil && link.DownloadCount >= *link.MaxDownloads {
+		return fmt.Errorf("download limit reached")
+	}
+	// Documents in trash are hidden by the default scope
+	if link.Document == nil || link.Document.IsExpired() {
+		return fmt.Errorf("document no longer available")
+	}
+	return nil
+}
+
 // Returns all active shares for a document owned by the user
 func (s *ShareService) GetDocumentShares(ctx context.Context, ownerID, documentID uuid.UUID) ([]models.SharedLink, error) {
 	var shares []models.SharedLink
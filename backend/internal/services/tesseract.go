@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Holds the Tesseract binary and the language data installed with it, resolved at startup
+type Tesseract struct {
+	path      string
+	version   string
+	languages map[string]bool
+}
+
+// Resolves Tesseract once. Returns nil when it is not installed, in which case scanned
+// PDFs are stored without searchable text.
+func DetectTesseract(configuredPath string) *Tesseract {
+	candidate := "tesseract"
+	if configuredPath != "" {
+		candidate = configuredPath
+	}
+
+	path, err := exec.LookPath(candidate)
+	if err != nil {
+		logrus.Warn("Tesseract not found, OCR of scanned PDFs is disabled. " +
+			"Install tesseract-ocr or set TESSERACT_PATH to enable it")
+		return nil
+	}
+
+	t := &Tesseract{
+		path:      path,
+		version:   toolVersion(path, "--version"),
+		languages: detectTesseractLanguages(path),
+	}
+	logrus.Infof("Tesseract detected: %s (%s, %d languages)", path, t.version, len(t.languages))
+	return t
+}
+
+// Reads the installed language data, the first line of the listing is a header
+func detectTesseractLanguages(path string) map[string]bool {
+	ctx, cancel := context.WithTimeout(context.Background(), toolVersionTimeout)
+	defer cancel()
+
+	languages := make(map[string]bool)
+	output, err := exec.CommandContext(ctx, path, "--list-langs").Output()
+	if err != nil {
+		logrus.Debugf("Failed to list Tesseract languages: %v", err)
+		return languages
+	}
+
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.Contains(line, " ") {
+			continue
+		}
+		languages[line] = true
+	}
+	return languages
+}
+
+// Reports whether data is installed for every language of a Tesseract language spec, e.g. deu+eng
+func (t *Tesseract) HasLanguages(spec string) bool {
+	if t == nil || spec == "" {
+		return false
+	}
+	for _, language := range strings.Split(spec, "+") {
+		if !t.languages[language] {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns the text recognized in an image
+func (t *Tesseract) Recognize(ctx context.Context, imageFile, languages string) (string, error) {
+	if t == nil {
+		return "", fmt.Errorf("tesseract is not available")
+	}
+
+	output, err := exec.CommandContext(ctx, t.path, imageFile, "stdout", "-l", languages).Output()
+	if err != nil {
+		return "", fmt.Errorf("tesseract failed: %w", err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/sirupsen/logrus"
+)
+
+// LibreOffice export filters used to pull plain text out of Office files
+const (
+	libreOfficeTextFilter = "txt:Text (encoded):UTF8"
+	libreOfficeCSVFilter  = "csv:Text - txt - csv (StarCalc):44,34,76"
+)
+
+const (
+	// Resolution pages are rasterized at for OCR, Tesseract works best around 300 DPI
+	ocrDensity = "300"
+	// PDFs with fewer extracted characters per page than this are treated as scanned images
+	imageOnlyCharsPerPage = 16
+)
+
+// Text pulled out of a document. PageOffsets holds the character offset where each
+// page starts and is only set for paginated formats.
+type ExtractedContent struct {
+	Text        string
+	PageOffsets []int
+	OCR         bool // recognized from page images
+}
+
+// Pulls plain text out of stored documents so it can be indexed for full-text search
+type TextExtractor struct {
+	pdftotextPath string       // empty when poppler-utils is not installed
+	libreOffice   *LibreOffice // nil when LibreOffice is not installed
+	imageMagick   *ImageMagick // rasterizes pages for OCR, nil when not installed
+	tesseract     *Tesseract   // nil when Tesseract is not installed
+	scratch       *ScratchDir
+	maxLength     int
+	ocrMaxPages   int
+}
+
+// Resolves pdftotext once. PDF and presentation text is not extracted when it is missing,
+// the other formats only depend on LibreOffice. Scanned PDFs are OCR'd from at most
+// ocrMaxPages pages when ImageMagick and Tesseract are both installed.
+func NewTextExtractor(configuredPath string, libreOffice *LibreOffice, imageMagick *ImageMagick, tesseract *Tesseract, scratch *ScratchDir, maxLength, ocrMaxPages int) *TextExtractor {
+	candidate := "pdftotext"
+	if configuredPath != "" {
+		candidate = configuredPath
+	}
+
+	extractor := &TextExtractor{
+		libreOffice: libreOffice,
+		imageMagick: imageMagick,
+		tesseract:   tesseract,
+		scratch:     scratch,
+		maxLength:   maxLength,
+		ocrMaxPages: ocrMaxPages,
+	}
+	if path, err := exec.LookPath(candidate); err == nil {
+		logrus.Infof("pdftotext detected: %s", path)
+		extractor.pdftotextPath = path
+	} else {
+		logrus.Warn("pdftotext not found, PDF text is not indexed for search. " +
+			"Install poppler-utils or set PDFTOTEXT_PATH to enable it")
+	}
+
+	return extractor
+}
+
+// Reports whether text can be extracted from the file type with the tools available
+func (e *TextExtractor) CanExtract(fileType models.DocumentType) bool {
+	if e == nil {
+		return false
+	}
+
+	switch fileType {
+	case models.DocumentTypeTXT:
+		return true
+	case models.DocumentTypePDF:
+		return e.pdftotextPath != ""
+	case models.DocumentTypeDOCX, models.DocumentTypeXLSX:
+		return e.libreOffice != nil
+	case models.DocumentTypePPTX:
+		// Impress has no text export, slides go through PDF
+		return e.libreOffice != nil && e.pdftotextPath != ""
+	default:
+		return false
+	}
+}
+
+// Lists the file types CanExtract accepts
+func (e *TextExtractor) SupportedTypes() []models.DocumentType {
+	var supported []models.DocumentType
+	for _, fileType := range []models.DocumentType{
+		models.DocumentTypePDF,
+		models.DocumentTypeDOCX,
+		models.DocumentTypeXLSX,
+		models.DocumentTypePPTX,
+		models.DocumentTypeTXT,
+	} {
+		if e.CanExtract(fileType) {
+			supported = append(supported, fileType)
+		}
+	}
+	return supported
+}
+
+// Returns the normalized text of a local file, capped to the configured length
+func (e *TextExtractor) Extract(ctx context.Context, localFile string, fileType models.DocumentType) (*ExtractedContent, error) {
+	if !e.CanExtract(fileType) {
+		return nil, fmt.Errorf("text extraction is not available for %s files", fileType)
+	}
+
+	var raw string
+	var err error
+	switch fileType {
+	case models.DocumentTypeTXT:
+		raw, err = e.readCapped(localFile)
+	case models.DocumentTypePDF:
+		raw, err = e.pdfToText(ctx, localFile)
+	case models.DocumentTypeDOCX:
+		raw, err = e.officeToText(ctx, localFile, libreOfficeTextFilter, ".txt")
+	case models.DocumentTypeXLSX:
+		raw, err = e.officeToText(ctx, localFile, libreOfficeCSVFilter, ".csv")
+	case models.DocumentTypePPTX:
+		raw, err = e.presentationToText(ctx, localFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// pdftotext ends every page with a form feed
+	if fileType == models.DocumentTypePDF || fileType == models.DocumentTypePPTX {
+		text, offsets := e.normalize(strings.Split(strings.TrimSuffix(raw, "\f"), "\f"))
+		return &ExtractedContent{Text: text, PageOffsets: offsets}, nil
+	}
+
+	text, _ := e.normalize([]string{raw})
+	return &ExtractedContent{Text: text}, nil
+}
+
+// Reports whether scanned PDFs can be OCR'd in the Tesseract languages, e.g. eng or deu+eng
+func (e *TextExtractor) CanOCR(languages string) bool {
+	return e != nil && e.imageMagick != nil && e.ocrMaxPages > 0 && e.tesseract.HasLanguages(languages)
+}
+
+// Reports whether text extracted from a PDF is too sparse to be anything but page images
+func isImageOnly(content *ExtractedContent) bool {
+	pages := max(len(content.PageOffsets), 1)
+	return utf8.RuneCountInString(content.Text) < pages*imageOnlyCharsPerPage
+}
+
+// Rasterizes the first pages of a PDF and returns the text Tesseract recognizes in them
+func (e *TextExtractor) OCR(ctx context.Context, pdfFile, languages string) (*ExtractedContent, error) {
+	if !e.CanOCR(languages) {
+		return nil, fmt.Errorf("OCR is not available for language %s", languages)
+	}
+
+	outDir, cleanup, err := e.scratch.Create("ocr-")
+	defer cleanup()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OCR output dir: %w", err)
+	}
+
+	output, err := e.imageMagick.Convert(
+		ctx,
+		"-density", ocrDensity,
+		fmt.Sprintf("%s[0-%d]", pdfFile, e.ocrMaxPages-1),
+		"-background", "white",
+		"-alpha", "remove",
+		"-colorspace", "Gray",
+		filepath.Join(outDir, "page-%04d.png"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ImageMagick failed: %s, error: %w", string(output), err)
+	}
+
+	// Zero-padded names sort in page order
+	images, err := filepath.Glob(filepath.Join(outDir, "page-*.png"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rasterized pages: %w", err)
+	}
+	sort.Strings(images)
+
+	pages := make([]string, 0, len(images))
+	for _, image := range images {
+		text, err := e.tesseract.Recognize(ctx, image, languages)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, text)
+	}
+
+	text, offsets := e.normalize(pages)
+	return &ExtractedContent{Text: text, PageOffsets: offsets, OCR: true}, nil
+}
+
+func (e *TextExtractor) pdfToText(ctx context.Context, pdfFile string) (string, error) {
+	cmd := exec.CommandContext(ctx, e.pdftotextPath, "-q", "-enc", "UTF-8", pdfFile, "-")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("pdftotext failed: %w", err)
+	}
+	return string(output), nil
+}
+
+func (e *TextExtractor) officeToText(ctx context.Context, localFile, filter, ext string) (string, error) {
+	outDir, cleanup, err := e.scratch.Create("text-")
+	defer cleanup()
+	if err != nil {
+		return "", fmt.Errorf("failed to create text output dir: %w", err)
+	}
+
+	textFile, err := e.libreOffice.Convert(ctx, localFile, outDir, filter, ext)
+	if err != nil {
+		return "", err
+	}
+	return e.readCapped(textFile)
+}
+
+func (e *TextExtractor) presentationToText(ctx context.Context, localFile string) (string, error) {
+	outDir, cleanup, err := e.scratch.Create("text-")
+	defer cleanup()
+	if err != nil {
+		return "", fmt.Errorf("failed to create text output dir: %w", err)
+	}
+
+	pdfFile, err := e.libreOffice.ConvertToPDF(ctx, localFile, outDir)
+	if err != nil {
+		return "", err
+	}
+	return e.pdfToText(ctx, pdfFile)
+}
+
+// Reads at most a few bytes per allowed character, enough to fill the cap with any UTF-8 text
+func (e *TextExtractor) readCapped(path string) (string, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return "", fmt.Errorf("failed to open extracted text: %w", err)
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, int64(e.maxLength)*utf8.UTFMax))
+	if err != nil {
+		return "", fmt.Errorf("failed to read extracted text: %w", err)
+	}
+	return string(data), nil
+}
+
+// Drops invalid bytes and control characters, collapses whitespace and caps the length.
+// Postgres rejects NUL bytes in text columns and tsvector has a hard size limit. Returns
+// the offset each page starts at, pages past the cap are left out.
+func (e *TextExtractor) normalize(pages []string) (string, []int) {
+	var b strings.Builder
+	offsets := make([]int, 0, len(pages))
+	length := 0
+
+	for _, page := range pages {
+		if length >= e.maxLength {
+			break
+		}
+
+		words := strings.FieldsFunc(strings.ToValidUTF8(page, " "), func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.IsControl(r)
+		})
+		if len(words) > 0 && length > 0 {
+			b.WriteByte(' ')
+			length++
+		}
+		offsets = append(offsets, length)
+
+		for i, word := range words {
+			if i > 0 {
+				word = " " + word
+			}
+			remaining := e.maxLength - length
+			if n := utf8.RuneCountInString(word); n > remaining {
+				word = string([]rune(word)[:remaining])
+			}
+			b.WriteString(word)
+			length += utf8.RuneCountInString(word)
+			if length >= e.maxLength {
+				break
+			}
+		}
+	}
+
+	return strings.TrimRight(b.String(), " "), offsets
+}
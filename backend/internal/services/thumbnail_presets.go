@@ -0,0 +1,120 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Named thumbnail size. Medium is the thumbnail rendered while processing, the others are
+// rendered from the document the first time they are asked for.
+type ThumbnailSize string
+
+const (
+	ThumbnailSizeSmall  ThumbnailSize = "small"
+	ThumbnailSizeMedium ThumbnailSize = "medium"
+	ThumbnailSizeLarge  ThumbnailSize = "large"
+)
+
+// Image encoding of a thumbnail
+type ThumbnailFormat string
+
+const (
+	ThumbnailFormatJPEG ThumbnailFormat = "jpeg"
+	ThumbnailFormatWebP ThumbnailFormat = "webp"
+)
+
+var thumbnailGeometryPattern = regexp.MustCompile(`^[1-9][0-9]{0,3}x[1-9][0-9]{0,3}$`)
+
+// Holds the dimensions and quality thumbnails are rendered with
+type ThumbnailPresets struct {
+	geometries map[ThumbnailSize]string
+	quality    int
+}
+
+// Validates the configured WIDTHxHEIGHT geometries, e.g. 300x400
+func NewThumbnailPresets(small, medium, large string, quality int) (*ThumbnailPresets, error) {
+	geometries := map[ThumbnailSize]string{
+		ThumbnailSizeSmall:  small,
+		ThumbnailSizeMedium: medium,
+		ThumbnailSizeLarge:  large,
+	}
+	for size, geometry := range geometries {
+		if !thumbnailGeometryPattern.MatchString(geometry) {
+			return nil, fmt.Errorf("invalid %s thumbnail size %q, expected WIDTHxHEIGHT", size, geometry)
+		}
+	}
+	if quality < 1 || quality > 100 {
+		return nil, fmt.Errorf("invalid thumbnail quality %d, expected 1-100", quality)
+	}
+
+	return &ThumbnailPresets{geometries: geometries, quality: quality}, nil
+}
+
+// Parses a size query value, an empty value is the default size
+func ParseThumbnailSize(value string) (ThumbnailSize, bool) {
+	switch size := ThumbnailSize(strings.ToLower(value)); size {
+	case "":
+		return ThumbnailSizeMedium, true
+	case ThumbnailSizeSmall, ThumbnailSizeMedium, ThumbnailSizeLarge:
+		return size, true
+	default:
+		return "", false
+	}
+}
+
+// Parses a format query value
+func ParseThumbnailFormat(value string) (ThumbnailFormat, bool) {
+	switch format := ThumbnailFormat(strings.ToLower(value)); format {
+	case ThumbnailFormatJPEG, ThumbnailFormatWebP:
+		return format, true
+	case "jpg":
+		return ThumbnailFormatJPEG, true
+	default:
+		return "", false
+	}
+}
+
+// Picks WebP when the Accept header lists it, JPEG otherwise
+func NegotiateThumbnailFormat(accept string) ThumbnailFormat {
+	if strings.Contains(accept, "image/webp") {
+		return ThumbnailFormatWebP
+	}
+	return ThumbnailFormatJPEG
+}
+
+func (f ThumbnailFormat) ContentType() string {
+	if f == ThumbnailFormatWebP {
+		return "image/webp"
+	}
+	return "image/jpeg"
+}
+
+func (f ThumbnailFormat) extension() string {
+	if f == ThumbnailFormatWebP {
+		return ".webp"
+	}
+	return ".jpg"
+}
+
+// Returns where a variant of the default thumbnail is stored, next to it. The default size in
+// JPEG is the default thumbnail itself.
+func thumbnailVariantPath(thumbnailPath string, size ThumbnailSize, format ThumbnailFormat) string {
+	if size == ThumbnailSizeMedium && format == ThumbnailFormatJPEG {
+		return thumbnailPath
+	}
+	return fmt.Sprintf("%s_%s%s", strings.TrimSuffix(thumbnailPath, ".jpg"), size, format.extension())
+}
+
+// Lists every variant that may have been rendered for a thumbnail, the default one excluded
+func thumbnailVariantPaths(thumbnailPath string) []string {
+	var paths []string
+	for _, size := range []ThumbnailSize{ThumbnailSizeSmall, ThumbnailSizeMedium, ThumbnailSizeLarge} {
+		for _, format := range []ThumbnailFormat{ThumbnailFormatJPEG, ThumbnailFormatWebP} {
+			if path := thumbnailVariantPath(thumbnailPath, size, format); path != thumbnailPath {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
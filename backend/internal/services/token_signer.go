@@ -0,0 +1,143 @@
+package services
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// RSA keys shorter than this are rejected for RS256
+const minRSAKeyBits = 2048
+
+// JWK is the public half of an RSA signing key as published in the JWKS (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is the key set other services verify access tokens with
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Signs and verifies access tokens with the algorithm set in JWT_ALGORITHM
+type tokenSigner struct {
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+	publicKey *rsa.PublicKey // RS256 only
+	keyID     string         // RS256 only
+}
+
+func newTokenSigner(cfg config.JWTConfig) (*tokenSigner, error) {
+	switch cfg.Algorithm {
+	case config.JWTAlgorithmHS256:
+		secret := []byte(cfg.Secret)
+		return &tokenSigner{
+			method:    jwt.SigningMethodHS256,
+			signKey:   secret,
+			verifyKey: secret,
+		}, nil
+
+	case config.JWTAlgorithmRS256:
+		privatePEM, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+		}
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+		}
+		if privateKey.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("JWT private key must be at least %d bits, got %d", minRSAKeyBits, privateKey.N.BitLen())
+		}
+
+		publicKey := &privateKey.PublicKey
+		if cfg.PublicKeyFile != "" {
+			publicPEM, err := os.ReadFile(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+			}
+			configured, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+			}
+			// A mismatch would make every issued token fail verification
+			if !configured.Equal(publicKey) {
+				return nil, fmt.Errorf("JWT public key doesn't match the private key")
+			}
+			publicKey = configured
+		}
+
+		keyID := cfg.KeyID
+		if keyID == "" {
+			keyID = rsaThumbprint(publicKey)
+		}
+
+		return &tokenSigner{
+			method:    jwt.SigningMethodRS256,
+			signKey:   privateKey,
+			verifyKey: publicKey,
+			publicKey: publicKey,
+			keyID:     keyID,
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", cfg.Algorithm)
+	}
+}
+
+func (t *tokenSigner) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(t.method, claims)
+	if t.keyID != "" {
+		token.Header["kid"] = t.keyID
+	}
+	return token.SignedString(t.signKey)
+}
+
+// Only the configured algorithm is accepted. Taking the alg header at face value would let an
+// HS256 token signed with the public key pass as RS256.
+func (t *tokenSigner) keyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != t.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return t.verifyKey, nil
+}
+
+// Returns the published key set, nil with HS256 whose secret can't be published
+func (t *tokenSigner) jwks() *JWKS {
+	if t.publicKey == nil {
+		return nil
+	}
+	return &JWKS{Keys: []JWK{{
+		Kty: "RSA",
+		Use: "sig",
+		Alg: t.method.Alg(),
+		Kid: t.keyID,
+		N:   base64.RawURLEncoding.EncodeToString(t.publicKey.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(t.publicKey.E)).Bytes()),
+	}}}
+}
+
+// JWK thumbprint of the key (RFC 7638), stable across restarts as long as the key is
+func rsaThumbprint(key *rsa.PublicKey) string {
+	// The members are required in lexicographic order, which encoding/json keeps for maps
+	canonical, _ := json.Marshal(map[string]string{
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		"kty": "RSA",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+	})
+	sum := sha256.Sum256(canonical)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LibreOffice can take a few seconds to start on a cold host
+const toolVersionTimeout = 10 * time.Second
+
+// Runs a binary's version flag and returns the first line it prints, empty when it fails
+func toolVersion(path string, args ...string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), toolVersionTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, args...).Output()
+	if err != nil {
+		logrus.Debugf("Failed to read version of %s: %v", path, err)
+		return ""
+	}
+
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line)
+}
+
+// Logs a banner at boot when thumbnail tooling is missing, the per-tool warnings are easy
+// to miss among the startup logs and the server otherwise runs without any thumbnails
+func WarnMissingThumbnailTools(imageMagick *ImageMagick, libreOffice *LibreOffice) {
+	var missing []string
+	if imageMagick == nil {
+		missing = append(missing, "ImageMagick (PDF and image thumbnails, PDF page counts)")
+	}
+	if libreOffice == nil {
+		missing = append(missing, "LibreOffice (Office document thumbnails and text extraction)")
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	logrus.Warn("============================================================")
+	logrus.Warn("THUMBNAIL TOOLING MISSING, documents will be stored without previews")
+	for _, tool := range missing {
+		logrus.Warnf("  - %s", tool)
+	}
+	logrus.Warn("See GET /api/v1/health/dependencies for the current status")
+	logrus.Warn("============================================================")
+}
@@ -5,27 +5,52 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// How often the expiry sweeper runs
+	ShareExpirySweepInterval = time.Hour
+	// How long an expired share stays listed as expired before it is revoked
+	ShareExpiryGracePeriod = 7 * 24 * time.Hour
+
+	shareExpiryBatchSize = 100
+
+	// Upper bound for delivering a single share email
+	shareEmailTimeout = 30 * time.Second
 )
 
 // Handles user-based document sharing
 type UserShareService struct {
-	db    *gorm.DB
-	redis *redis.Client
+	db              *gorm.DB
+	redis           *redis.Client
+	activityService *ActivityService
+	emailService    EmailService // nil when email is not configured
+	appURL          string
 }
 
-func NewUserShareService(db *gorm.DB, redisClient *redis.Client) *UserShareService {
-	return &UserShareService{db: db, redis: redisClient}
+func NewUserShareService(db *gorm.DB, redisClient *redis.Client, emailService EmailService, appURL string) *UserShareService {
+	return &UserShareService{
+		db:              db,
+		redis:           redisClient,
+		activityService: NewActivityService(db),
+		emailService:    emailService,
+		appURL:          strings.TrimRight(appURL, "/"),
+	}
 }
 
-// Creates a new user-based share for a document
-func (s *UserShareService) CreateUserShare(ctx context.Context, ownerID, documentID uuid.UUID, email string, accessLevel models.AccessLevel, expiresInDays int, message string) (*models.UserShare, error) {
+// Creates a new user-based share for a document. A nil maxDownloads allows unlimited downloads,
+// re-sharing with the same email changes the limit but keeps the downloads already made.
+func (s *UserShareService) CreateUserShare(ctx context.Context, ownerID, documentID uuid.UUID, email string, accessLevel models.AccessLevel, expiresInDays int, maxDownloads *int, message string) (*models.UserShare, error) {
 	// Check if the document exists and belongs to the owner
 	var document models.Document
 	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", documentID, ownerID).First(&document).Error; err != nil {
@@ -52,6 +77,7 @@ func (s *UserShareService) CreateUserShare(ctx context.Context, ownerID, documen
 		// Update existing share
 		existingShare.AccessLevel = accessLevel
 		existingShare.ExpiresAt = expiresAt
+		existingShare.MaxDownloads = maxDownloads
 		existingShare.Message = message
 		existingShare.SharedWithUserID = sharedWithUserID
 
@@ -73,6 +99,7 @@ func (s *UserShareService) CreateUserShare(ctx context.Context, ownerID, documen
 		SharedWithUserID: sharedWithUserID,
 		AccessLevel:      accessLevel,
 		ExpiresAt:        expiresAt,
+		MaxDownloads:     maxDownloads,
 		Message:          message,
 	}
 
@@ -83,12 +110,25 @@ func (s *UserShareService) CreateUserShare(ctx context.Context, ownerID, documen
 	// Create audit log
 	s.createUserShareAuditLog(ctx, userShare.ID, ownerID, "created", "", "", fmt.Sprintf("Shared with %s with %s access", email, accessLevel))
 
+	shareEmail := ShareEmail{
+		To:            email,
+		DocumentTitle: document.Title,
+		AccessLevel:   accessLevel,
+		Message:       message,
+		Link:          fmt.Sprintf("%s/documents/%s", s.appURL, documentID),
+		ExpiresAt:     expiresAt,
+	}
+
 	// If user doesn't exist, create invitation
 	if sharedWithUserID == nil {
-		if err := s.createShareInvitation(ctx, ownerID, documentID, email, accessLevel, expiresAt, message); err != nil {
+		invitation, err := s.createShareInvitation(ctx, ownerID, documentID, email, accessLevel, expiresAt, message)
+		if err != nil {
 			// Log error but don't fail the share creation
 			fmt.Printf("Failed to create share invitation: %v\n", err)
+			return userShare, nil
 		}
+		shareEmail.IsInvitation = true
+		shareEmail.Link = fmt.Sprintf("%s/auth/register?invitation=%s", s.appURL, invitation.Token)
 	} else {
 		// Create notification for registered user
 		if err := s.createShareNotification(ctx, "document_shared", documentID, ownerID, *sharedWithUserID, fmt.Sprintf("Document '%s' has been shared with you", document.Title), message); err != nil {
@@ -97,48 +137,250 @@ func (s *UserShareService) CreateUserShare(ctx context.Context, ownerID, documen
 		}
 	}
 
+	s.sendShareEmail(ownerID, shareEmail)
+
 	return userShare, nil
 }
 
-// Returns documents shared with the specified user
-func (s *UserShareService) GetSharedWithMe(ctx context.Context, userID uuid.UUID, email string) ([]models.UserShare, error) {
-	var shares []models.UserShare
+// Returns which of the documents exist and belong to the owner, trashed documents included
+// like CreateUserShare does
+func (s *UserShareService) OwnedDocumentIDs(ctx context.Context, ownerID uuid.UUID, documentIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	var ids []uuid.UUID
+	if err := s.db.WithContext(ctx).Model(&models.Document{}).
+		Where("id IN ? AND user_id = ?", documentIDs, ownerID).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to check document ownership: %w", err)
+	}
 
-	query := s.db.WithContext(ctx).
-		Preload("Document").
-		Preload("Owner").
-		Where("(shared_with_user_id = ? OR shared_with_email = ?) AND is_revoked = false", userID, email)
+	owned := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		owned[id] = true
+	}
+	return owned, nil
+}
 
-	if err := query.Find(&shares).Error; err != nil {
-		return nil, fmt.Errorf("failed to get shared documents: %w", err)
+// Sends the share email in the background, delivery failures are only logged
+func (s *UserShareService) sendShareEmail(ownerID uuid.UUID, email ShareEmail) {
+	if s.emailService == nil {
+		return
 	}
 
-	// Filter out expired shares
-	var activeShares []models.UserShare
-	for _, share := range shares {
-		if !share.IsExpired() {
-			activeShares = append(activeShares, share)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shareEmailTimeout)
+		defer cancel()
+
+		var owner models.User
+		if err := s.db.WithContext(ctx).Select("name", "email").Where("id = ?", ownerID).First(&owner).Error; err == nil {
+			email.SharedBy = owner.Name
+		}
+		if email.SharedBy == "" {
+			email.SharedBy = "Someone"
 		}
+
+		if err := s.emailService.SendShareEmail(ctx, email); err != nil {
+			logrus.Warnf("[SHARE_EMAIL] Failed to send share email to %s: %v", email.To, err)
+			return
+		}
+		logrus.Infof("[SHARE_EMAIL] Share email sent to %s", email.To)
+	}()
+}
+
+// Returns a page of the active shares with the specified user, with the total number of matches.
+// Only shares that are neither revoked nor expired of documents that are not trashed are listed.
+func (s *UserShareService) GetSharedWithMe(ctx context.Context, userID uuid.UUID, email string, req *types.SharedWithMeListRequest) ([]models.UserShare, int64, error) {
+	query := s.db.WithContext(ctx).Model(&models.UserShare{}).
+		Joins("JOIN documents ON documents.id = user_shares.document_id AND documents.deleted_at IS NULL").
+		Where("(user_shares.shared_with_user_id = ? OR user_shares.shared_with_email = ?) AND user_shares.is_revoked = false", userID, email).
+		Where("user_shares.expires_at IS NULL OR user_shares.expires_at > ?", time.Now()).
+		Where("documents.expires_at IS NULL OR documents.expires_at > ?", time.Now())
+
+	if req.Search != "" {
+		query = query.Where("documents.title ILIKE ?", "%"+escapeLikePattern(req.Search)+"%")
+	}
+	if req.FileType != "" {
+		query = query.Where("documents.file_type = ?", req.FileType)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count shared documents: %w", err)
 	}
 
-	return activeShares, nil
+	sortableCols := map[string]string{
+		"shared": "user_shares.created_at",
+		"date":   "documents.created_at",
+		"title":  "LOWER(documents.title)",
+		"size":   "documents.file_size",
+	}
+	col, ok := sortableCols[req.SortBy]
+	if !ok {
+		col = sortableCols["shared"]
+	}
+	dir := "DESC"
+	if strings.ToLower(req.SortDir) == "asc" {
+		dir = "ASC"
+	}
+
+	var shares []models.UserShare
+	if err := query.
+		Preload("Document").
+		Preload("Owner").
+		Order(col + " " + dir).
+		Order("user_shares.id " + dir).
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Find(&shares).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to get shared documents: %w", err)
+	}
+
+	return shares, total, nil
 }
 
-// Returns documents shared by the specified user
-func (s *UserShareService) GetSharedByMe(ctx context.Context, ownerID uuid.UUID) ([]models.UserShare, error) {
+// Returns documents shared by the specified user, optionally only active or only expired shares
+func (s *UserShareService) GetSharedByMe(ctx context.Context, ownerID uuid.UUID, status models.ShareStatus) ([]models.UserShare, error) {
 	var shares []models.UserShare
 
-	if err := s.db.WithContext(ctx).
+	query := s.db.WithContext(ctx).
 		Preload("Document").
 		Preload("SharedWithUser").
-		Where("owner_id = ? AND is_revoked = false", ownerID).
-		Find(&shares).Error; err != nil {
+		Where("owner_id = ? AND is_revoked = false", ownerID)
+
+	switch status {
+	case models.ShareStatusActive:
+		query = query.Where("expires_at IS NULL OR expires_at > ?", time.Now())
+	case models.ShareStatusExpired:
+		query = query.Where("expires_at IS NOT NULL AND expires_at <= ?", time.Now())
+	}
+
+	if err := query.Find(&shares).Error; err != nil {
 		return nil, fmt.Errorf("failed to get shared documents: %w", err)
 	}
 
 	return shares, nil
 }
 
+// Returns a share with its audit history in chronological order. Only the owner and the
+// recipient may read it, anyone else gets "share not found".
+func (s *UserShareService) GetShareByID(ctx context.Context, userID, shareID uuid.UUID) (*models.UserShare, []models.UserShareAuditLog, error) {
+	var share models.UserShare
+	err := s.db.WithContext(ctx).
+		Preload("Document").
+		Preload("Owner").
+		Preload("SharedWithUser").
+		Where("id = ?", shareID).
+		First(&share).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, fmt.Errorf("share not found")
+		}
+		return nil, nil, fmt.Errorf("failed to get share: %w", err)
+	}
+
+	if share.OwnerID != userID && !s.isShareRecipient(ctx, &share, userID) {
+		return nil, nil, fmt.Errorf("share not found")
+	}
+
+	var auditLogs []models.UserShareAuditLog
+	if err := s.db.WithContext(ctx).
+		Preload("User").
+		Where("user_share_id = ?", shareID).
+		Order("created_at ASC").
+		Find(&auditLogs).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get share audit log: %w", err)
+	}
+
+	return &share, auditLogs, nil
+}
+
+// Reports whether the user is who the share was sent to, matching by email for shares not yet linked to an account
+func (s *UserShareService) isShareRecipient(ctx context.Context, share *models.UserShare, userID uuid.UUID) bool {
+	if share.SharedWithUserID != nil {
+		return *share.SharedWithUserID == userID
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("email").Where("id = ?", userID).First(&user).Error; err != nil {
+		return false
+	}
+	return strings.EqualFold(user.Email, share.SharedWithEmail)
+}
+
+// Periodically revokes shares that expired longer ago than the grace period
+func (s *UserShareService) StartExpirySweeper(ctx context.Context, interval, gracePeriod time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				revoked, err := s.RevokeExpiredShares(ctx, gracePeriod)
+				if err != nil {
+					logrus.Errorf("[SHARE_EXPIRY] Failed to revoke expired shares: %v", err)
+				}
+				if revoked > 0 {
+					logrus.Infof("[SHARE_EXPIRY] Revoked %d expired shares", revoked)
+				}
+			}
+		}
+	}()
+}
+
+// Revokes shares whose expiry passed before the grace period and records an unshare activity for each
+func (s *UserShareService) RevokeExpiredShares(ctx context.Context, gracePeriod time.Duration) (int, error) {
+	cutoff := time.Now().Add(-gracePeriod)
+	revoked := 0
+
+	for {
+		var shares []models.UserShare
+		if err := s.db.WithContext(ctx).
+			Preload("Document", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			Where("is_revoked = false AND expires_at IS NOT NULL AND expires_at < ?", cutoff).
+			Order("expires_at ASC").
+			Limit(shareExpiryBatchSize).
+			Find(&shares).Error; err != nil {
+			return revoked, fmt.Errorf("failed to fetch expired shares: %w", err)
+		}
+
+		if len(shares) == 0 {
+			return revoked, nil
+		}
+
+		for _, share := range shares {
+			result := s.db.WithContext(ctx).
+				Model(&models.UserShare{}).
+				Where("id = ? AND is_revoked = false", share.ID).
+				Update("is_revoked", true)
+			if result.Error != nil {
+				return revoked, fmt.Errorf("failed to revoke share %s: %w", share.ID, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+			revoked++
+
+			s.createUserShareAuditLog(ctx, share.ID, share.OwnerID, "revoked", "", "", "Share expired")
+
+			if share.Document != nil {
+				activityCtx := &ActivityContext{
+					UserID:     share.OwnerID,
+					DocumentID: share.DocumentID,
+					Source:     "system",
+				}
+				if err := s.activityService.LogDocumentUnshare(activityCtx, share.Document, "user", share.SharedWithEmail); err != nil {
+					logrus.Warnf("[SHARE_EXPIRY] Failed to log unshare activity for share %s: %v", share.ID, err)
+				}
+			}
+		}
+
+		if len(shares) < shareExpiryBatchSize {
+			return revoked, nil
+		}
+	}
+}
+
 // Revokes a user share
 func (s *UserShareService) RevokeUserShare(ctx context.Context, ownerID, shareID uuid.UUID) error {
 	result := s.db.WithContext(ctx).
@@ -160,75 +402,219 @@ func (s *UserShareService) RevokeUserShare(ctx context.Context, ownerID, shareID
 	return nil
 }
 
-// Updates the access level of a user share
-func (s *UserShareService) UpdateUserShareAccess(ctx context.Context, ownerID, shareID uuid.UUID, accessLevel models.AccessLevel) error {
+// Changes the access level of a user share, recording the previous and new level with the
+// owner's IP address and user agent. With notify the recipient is told about the change.
+func (s *UserShareService) UpdateUserShareAccess(ctx context.Context, ownerID, shareID uuid.UUID, accessLevel models.AccessLevel, notify bool, ipAddress, userAgent string) error {
+	if !accessLevel.IsValid() {
+		return fmt.Errorf("invalid access level")
+	}
+
+	var share models.UserShare
+	if err := s.db.WithContext(ctx).
+		Preload("Document").
+		Where("id = ? AND owner_id = ?", shareID, ownerID).
+		First(&share).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("share not found or not owned by user")
+		}
+		return fmt.Errorf("failed to fetch share: %w", err)
+	}
+
+	if share.IsRevoked {
+		return fmt.Errorf("share is revoked")
+	}
+	if share.AccessLevel == accessLevel {
+		return fmt.Errorf("access level is unchanged")
+	}
+
+	// Only changes the level that was read, so a concurrent change is not overwritten unaudited
 	result := s.db.WithContext(ctx).
 		Model(&models.UserShare{}).
-		Where("id = ? AND owner_id = ?", shareID, ownerID).
+		Where("id = ? AND access_level = ?", shareID, share.AccessLevel).
 		Update("access_level", accessLevel)
-
 	if result.Error != nil {
 		return result.Error
 	}
-
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("share not found or not owned by user")
+		return fmt.Errorf("share was changed concurrently")
 	}
 
-	// Create audit log
-	s.createUserShareAuditLog(ctx, shareID, ownerID, "updated", "", "", fmt.Sprintf("Access level updated to %s", accessLevel))
+	s.createUserShareAuditLog(ctx, shareID, ownerID, "updated", ipAddress, userAgent,
+		fmt.Sprintf("Access level changed from %s to %s", share.AccessLevel, accessLevel))
+
+	// Invitees without an account have nobody to notify yet
+	if notify && share.SharedWithUserID != nil && share.Document != nil {
+		title := fmt.Sprintf("Your access to '%s' has changed", share.Document.Title)
+		message := fmt.Sprintf("Your access level changed from %s to %s", share.AccessLevel, accessLevel)
+		if err := s.createShareNotification(ctx, "access_changed", share.DocumentID, ownerID, *share.SharedWithUserID, title, message); err != nil {
+			logrus.Warnf("Failed to notify user %s of access change on share %s: %v", *share.SharedWithUserID, shareID, err)
+		}
+	}
 
 	return nil
 }
 
-// Validates if a user has access to a document
+// Validates if a user has access to a document, through a direct share or one of their groups
 func (s *UserShareService) ValidateUserAccess(ctx context.Context, userID uuid.UUID, documentID uuid.UUID, requiredAccess models.AccessLevel) (bool, error) {
 	logrus.Infof("[VALIDATE_ACCESS] Checking access for user %s to document %s, required level: %s", userID, documentID, requiredAccess)
 
-	var share models.UserShare
+	if !requiredAccess.IsValid() {
+		logrus.Errorf("[VALIDATE_ACCESS] Invalid access level: %s", requiredAccess)
+		return false, fmt.Errorf("invalid access level")
+	}
+
+	level, err := s.effectiveAccessLevel(ctx, userID, documentID)
+	if err != nil {
+		return false, err
+	}
+
+	hasAccess := level.Rank() >= requiredAccess.Rank()
+	logrus.Infof("[VALIDATE_ACCESS] Access check: level=%s, required=%s, granted=%v", level, requiredAccess, hasAccess)
+	return hasAccess, nil
+}
 
+// Returns the highest level granted to the user on the document by their active direct
+// shares, by user ID or email, and the active shares with groups they belong to. Empty when
+// none applies.
+func (s *UserShareService) effectiveAccessLevel(ctx context.Context, userID, documentID uuid.UUID) (models.AccessLevel, error) {
 	// Get user email to check both user ID and email-based shares
 	var user models.User
 	if err := s.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
-		logrus.Errorf("[VALIDATE_ACCESS] User not found: %v", err)
-		return false, fmt.Errorf("user not found")
+		logrus.Errorf("[ACCESS_LEVEL] User not found: %v", err)
+		return "", fmt.Errorf("user not found")
 	}
 
-	logrus.Infof("[VALIDATE_ACCESS] User found: %s (%s)", user.Email, userID)
+	now := time.Now()
 
-	// Check if user has access either by user ID or email
+	var levels []models.AccessLevel
 	if err := s.db.WithContext(ctx).
+		Model(&models.UserShare{}).
 		Where("document_id = ? AND (shared_with_user_id = ? OR shared_with_email = ?) AND is_revoked = false", documentID, userID, user.Email).
-		First(&share).Error; err != nil {
-		logrus.Infof("[VALIDATE_ACCESS] No share found for user %s (email: %s) to document %s: %v", userID, user.Email, documentID, err)
-		return false, nil // Not found is not an error, just no access
-	}
-
-	logrus.Infof("[VALIDATE_ACCESS] Share found: ID=%s, AccessLevel=%s, IsRevoked=%v, ExpiresAt=%v", share.ID, share.AccessLevel, share.IsRevoked, share.ExpiresAt)
-
-	// Check if expired
-	if share.IsExpired() {
-		logrus.Infof("[VALIDATE_ACCESS] Share is expired")
-		return false, nil
-	}
-
-	// Check access level
-	switch requiredAccess {
-	case models.AccessLevelView:
-		logrus.Infof("[VALIDATE_ACCESS] View access granted")
-		return true, nil // All access levels include view
-	case models.AccessLevelDownload:
-		hasDownload := share.AccessLevel == models.AccessLevelDownload || share.AccessLevel == models.AccessLevelEdit
-		logrus.Infof("[VALIDATE_ACCESS] Download access check: share.AccessLevel=%s, hasDownload=%v", share.AccessLevel, hasDownload)
-		return hasDownload, nil
-	case models.AccessLevelEdit:
-		hasEdit := share.AccessLevel == models.AccessLevelEdit
-		logrus.Infof("[VALIDATE_ACCESS] Edit access check: share.AccessLevel=%s, hasEdit=%v", share.AccessLevel, hasEdit)
-		return hasEdit, nil
-	default:
-		logrus.Errorf("[VALIDATE_ACCESS] Invalid access level: %s", requiredAccess)
-		return false, fmt.Errorf("invalid access level")
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Pluck("access_level", &levels).Error; err != nil {
+		return "", fmt.Errorf("failed to fetch user shares: %w", err)
+	}
+
+	var groupLevels []models.AccessLevel
+	if err := s.db.WithContext(ctx).
+		Model(&models.GroupShare{}).
+		Joins("JOIN group_members ON group_members.group_id = group_shares.group_id").
+		Where("group_shares.document_id = ? AND group_members.user_id = ? AND group_shares.is_revoked = false", documentID, userID).
+		Where("group_shares.expires_at IS NULL OR group_shares.expires_at > ?", now).
+		Pluck("group_shares.access_level", &groupLevels).Error; err != nil {
+		return "", fmt.Errorf("failed to fetch group shares: %w", err)
+	}
+
+	var highest models.AccessLevel
+	for _, level := range append(levels, groupLevels...) {
+		if level.Rank() > highest.Rank() {
+			highest = level
+		}
+	}
+
+	logrus.Infof("[ACCESS_LEVEL] User %s (%s) has %d direct and %d group shares on document %s, highest level: %q",
+		userID, user.Email, len(levels), len(groupLevels), documentID, highest)
+	return highest, nil
+}
+
+// Counts a download of a document the user can download through a share. Direct shares with a
+// limit are used up one download at a time, unlimited ones first. A group share granting
+// download access lets the download through once the direct shares are used up, group shares
+// have no limit. The count is raised in the same statement that checks the limit, so concurrent
+// downloads can't exceed it. Returns the share charged, uuid.Nil when a group share let it through.
+func (s *UserShareService) RecordShareDownload(ctx context.Context, userID, documentID uuid.UUID) (uuid.UUID, error) {
+	shares, err := s.downloadShares(ctx, userID, documentID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	for _, share := range shares {
+		result := s.db.WithContext(ctx).
+			Model(&models.UserShare{}).
+			Where("id = ? AND (max_downloads IS NULL OR download_count < max_downloads)", share.ID).
+			Update("download_count", gorm.Expr("download_count + 1"))
+		if result.Error != nil {
+			return uuid.Nil, fmt.Errorf("failed to record share download: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			return share.ID, nil
+		}
+	}
+
+	if ok, err := s.hasGroupDownloadShare(ctx, userID, documentID); err != nil || ok {
+		return uuid.Nil, err
+	}
+
+	return uuid.Nil, fmt.Errorf("share download limit reached")
+}
+
+// Checks that the user has a download left on the document through a share without counting
+// one, for ranged requests that continue a download counted before
+func (s *UserShareService) CheckShareDownload(ctx context.Context, userID, documentID uuid.UUID) error {
+	shares, err := s.downloadShares(ctx, userID, documentID)
+	if err != nil {
+		return err
+	}
+	for _, share := range shares {
+		if share.MaxDownloads == nil || share.DownloadCount < *share.MaxDownloads {
+			return nil
+		}
+	}
+
+	if ok, err := s.hasGroupDownloadShare(ctx, userID, documentID); err != nil || ok {
+		return err
+	}
+
+	return fmt.Errorf("share download limit reached")
+}
+
+// Access levels that allow downloading a shared document
+var shareDownloadLevels = []models.AccessLevel{models.AccessLevelDownload, models.AccessLevelEdit}
+
+// Lists the live direct shares that let the user download the document, unlimited ones first
+// and then by downloads left
+func (s *UserShareService) downloadShares(ctx context.Context, userID, documentID uuid.UUID) ([]models.UserShare, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	var shares []models.UserShare
+	if err := s.db.WithContext(ctx).
+		Where("document_id = ? AND (shared_with_user_id = ? OR shared_with_email = ?) AND is_revoked = false", documentID, userID, user.Email).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Where("access_level IN ?", shareDownloadLevels).
+		Order("max_downloads IS NOT NULL, max_downloads - download_count DESC").
+		Find(&shares).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch user shares: %w", err)
+	}
+	return shares, nil
+}
+
+// Reports whether one of the user's groups has a live share letting them download the document
+func (s *UserShareService) hasGroupDownloadShare(ctx context.Context, userID, documentID uuid.UUID) (bool, error) {
+	var groupShares int64
+	if err := s.db.WithContext(ctx).
+		Model(&models.GroupShare{}).
+		Joins("JOIN group_members ON group_members.group_id = group_shares.group_id").
+		Where("group_shares.document_id = ? AND group_members.user_id = ? AND group_shares.is_revoked = false", documentID, userID).
+		Where("group_shares.expires_at IS NULL OR group_shares.expires_at > ?", time.Now()).
+		Where("group_shares.access_level IN ?", shareDownloadLevels).
+		Count(&groupShares).Error; err != nil {
+		return false, fmt.Errorf("failed to fetch group shares: %w", err)
+	}
+	return groupShares > 0, nil
+}
+
+// Gives back a download recorded on a share when the file could not be sent after all
+func (s *UserShareService) ReleaseShareDownload(ctx context.Context, shareID uuid.UUID) error {
+	if err := s.db.WithContext(ctx).
+		Model(&models.UserShare{}).
+		Where("id = ? AND download_count > 0", shareID).
+		Update("download_count", gorm.Expr("download_count - 1")).Error; err != nil {
+		return fmt.Errorf("failed to release share download: %w", err)
 	}
+	return nil
 }
 
 // Records when a user accesses a shared document
@@ -293,11 +679,126 @@ func (s *UserShareService) MarkNotificationAsRead(ctx context.Context, userID, n
 	return nil
 }
 
-func (s *UserShareService) createShareInvitation(ctx context.Context, ownerID, documentID uuid.UUID, email string, accessLevel models.AccessLevel, expiresAt *time.Time, message string) error {
+// Returns the invitation for a token along with its document and owner
+func (s *UserShareService) GetInvitation(ctx context.Context, token string) (*models.ShareInvitation, error) {
+	var invitation models.ShareInvitation
+	if err := s.db.WithContext(ctx).
+		Preload("Document").
+		Preload("Owner").
+		Where("token = ?", token).
+		First(&invitation).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("invitation not found")
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+// Converts an invitation into an active share for the user it was sent to
+func (s *UserShareService) AcceptInvitation(ctx context.Context, userID uuid.UUID, token string) (*models.UserShare, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+
+	var invitation models.ShareInvitation
+	var share models.UserShare
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the invitation so concurrent accepts cannot both succeed
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token = ?", token).
+			First(&invitation).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("invitation not found")
+			}
+			return fmt.Errorf("failed to get invitation: %w", err)
+		}
+
+		if invitation.IsAccepted {
+			return fmt.Errorf("invitation already used")
+		}
+		if invitation.IsExpired() {
+			return fmt.Errorf("invitation expired")
+		}
+		if !strings.EqualFold(invitation.Email, user.Email) {
+			return fmt.Errorf("invitation was sent to a different email")
+		}
+
+		var document models.Document
+		if err := tx.Where("id = ?", invitation.DocumentID).First(&document).Error; err != nil {
+			return fmt.Errorf("document not found")
+		}
+
+		now := time.Now()
+
+		// The share is created alongside the invitation, it only needs to be linked to the user
+		err := tx.Where("document_id = ? AND owner_id = ? AND shared_with_email = ?", invitation.DocumentID, invitation.OwnerID, invitation.Email).
+			Order("created_at DESC").
+			First(&share).Error
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			share = models.UserShare{
+				DocumentID:       invitation.DocumentID,
+				OwnerID:          invitation.OwnerID,
+				SharedWithEmail:  invitation.Email,
+				SharedWithUserID: &userID,
+				AccessLevel:      invitation.AccessLevel,
+				ExpiresAt:        invitation.ExpiresAt,
+				AcceptedAt:       &now,
+				Message:          invitation.Message,
+			}
+			if err := tx.Create(&share).Error; err != nil {
+				return fmt.Errorf("failed to create user share: %w", err)
+			}
+		case err != nil:
+			return fmt.Errorf("failed to get user share: %w", err)
+		case share.IsRevoked:
+			return fmt.Errorf("share has been revoked")
+		default:
+			share.SharedWithUserID = &userID
+			share.AcceptedAt = &now
+			if err := tx.Model(&share).Updates(map[string]interface{}{
+				"shared_with_user_id": userID,
+				"accepted_at":         now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update user share: %w", err)
+			}
+		}
+
+		invitation.IsAccepted = true
+		invitation.AcceptedAt = &now
+		if err := tx.Model(&invitation).Updates(map[string]interface{}{
+			"is_accepted": true,
+			"accepted_at": now,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update invitation: %w", err)
+		}
+
+		share.Document = &document
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.createUserShareAuditLog(ctx, share.ID, userID, "accepted", "", "", fmt.Sprintf("Invitation accepted by %s", user.Email))
+
+	title := fmt.Sprintf("%s accepted your invitation to '%s'", user.Email, share.Document.Title)
+	if err := s.createShareNotification(ctx, "invitation_accepted", share.DocumentID, userID, share.OwnerID, title, ""); err != nil {
+		logrus.Warnf("Failed to create invitation accepted notification: %v", err)
+	}
+
+	return &share, nil
+}
+
+func (s *UserShareService) createShareInvitation(ctx context.Context, ownerID, documentID uuid.UUID, email string, accessLevel models.AccessLevel, expiresAt *time.Time, message string) (*models.ShareInvitation, error) {
 	// Generate random token
 	b := make([]byte, 16)
 	if _, err := rand.Read(b); err != nil {
-		return fmt.Errorf("failed to generate token: %w", err)
+		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 	token := hex.EncodeToString(b)
 
@@ -311,7 +812,10 @@ func (s *UserShareService) createShareInvitation(ctx context.Context, ownerID, d
 		Message:     message,
 	}
 
-	return s.db.WithContext(ctx).Create(invitation).Error
+	if err := s.db.WithContext(ctx).Create(invitation).Error; err != nil {
+		return nil, err
+	}
+	return invitation, nil
 }
 
 func (s *UserShareService) createShareNotification(ctx context.Context, notificationType string, documentID, fromUserID, toUserID uuid.UUID, title, message string) error {
@@ -339,41 +843,17 @@ func (s *UserShareService) createUserShareAuditLog(ctx context.Context, shareID,
 	s.db.WithContext(ctx).Create(&log)
 }
 
-// Returns the user's access level for a document (empty string if no access)
+// Returns the user's access level for a document (empty string if no access), the highest one
+// when direct and group shares both apply
 func (s *UserShareService) GetUserAccessLevel(ctx context.Context, userID uuid.UUID, documentID uuid.UUID) (string, error) {
 	logrus.Infof("[GET_ACCESS_LEVEL] Getting access level for user %s to document %s", userID, documentID)
 
-	var share models.UserShare
-
-	// Get user email to check both user ID and email-based shares
-	var user models.User
-	if err := s.db.WithContext(ctx).Where("id = ?", userID).First(&user).Error; err != nil {
-		logrus.Errorf("[GET_ACCESS_LEVEL] User not found: %v", err)
-		return "", fmt.Errorf("user not found")
-	}
-
-	logrus.Infof("[GET_ACCESS_LEVEL] User found: %s (%s)", user.Email, userID)
-
-	// Check if user has access either by user ID or email
-	if err := s.db.WithContext(ctx).
-		Where("document_id = ? AND (shared_with_user_id = ? OR shared_with_email = ?) AND is_revoked = false", documentID, userID, user.Email).
-		First(&share).Error; err != nil {
-		logrus.Infof("[GET_ACCESS_LEVEL] No share found for user %s (email: %s) to document %s: %v", userID, user.Email, documentID, err)
-		return "", nil // Not found is not an error, just no access
-	}
-
-	logrus.Infof("[GET_ACCESS_LEVEL] Share found: ID=%s, AccessLevel=%s, IsRevoked=%v, ExpiresAt=%v", share.ID, share.AccessLevel, share.IsRevoked, share.ExpiresAt)
-
-	// Check if expired
-	if share.IsExpired() {
-		logrus.Infof("[GET_ACCESS_LEVEL] Share is expired")
-		return "", nil
+	level, err := s.effectiveAccessLevel(ctx, userID, documentID)
+	if err != nil {
+		return "", err
 	}
 
-	// Convert access level to string
-	accessLevel := string(share.AccessLevel)
-	logrus.Infof("[GET_ACCESS_LEVEL] User access level: %s", accessLevel)
-	return accessLevel, nil
+	return string(level), nil
 }
 
 // GetDB returns the database instance
@@ -0,0 +1,353 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookQueueSize   = 256
+	webhookWorkers     = 4
+	webhookTimeout     = 10 * time.Second
+	webhookMaxAttempts = 5
+	// Doubled after every failed attempt
+	webhookRetryDelay = 2 * time.Second
+	// Webhooks per user, each one is called for every matching activity
+	webhookMaxPerUser = 10
+)
+
+// WebhookService manages webhooks and delivers activities to them in the background
+type WebhookService struct {
+	db     *gorm.DB
+	client *http.Client
+	events chan *models.DocumentActivity
+	logger *logrus.Entry
+}
+
+func NewWebhookService(db *gorm.DB) *WebhookService {
+	return &WebhookService{
+		db:     db,
+		client: &http.Client{Timeout: webhookTimeout},
+		events: make(chan *models.DocumentActivity, webhookQueueSize),
+		logger: logrus.WithField("service", "webhook"),
+	}
+}
+
+// Request types
+type CreateWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,max=2048"`
+	EventTypes []string `json:"eventTypes"`
+}
+
+type UpdateWebhookRequest struct {
+	URL        *string   `json:"url,omitempty" binding:"omitempty,max=2048"`
+	EventTypes *[]string `json:"eventTypes,omitempty"`
+	Active     *bool     `json:"active,omitempty"`
+}
+
+// Body posted to webhook URLs
+type WebhookPayload struct {
+	ID          uuid.UUID               `json:"id"`
+	Event       models.ActivityType     `json:"event"`
+	OccurredAt  time.Time               `json:"occurredAt"`
+	DocumentID  uuid.UUID               `json:"documentId"`
+	ActorID     uuid.UUID               `json:"actorId"`
+	Description string                  `json:"description"`
+	Metadata    models.ActivityMetadata `json:"metadata"`
+}
+
+func (s *WebhookService) ListWebhooks(ctx context.Context, userID uuid.UUID) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at ASC").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch webhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+// Creates a webhook and returns it with its signing secret, which is not shown again
+func (s *WebhookService) CreateWebhook(ctx context.Context, userID uuid.UUID, req *CreateWebhookRequest) (*models.Webhook, string, error) {
+	webhookURL, err := normalizeWebhookURL(req.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	eventTypes, err := normalizeWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.Webhook{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to count webhooks: %w", err)
+	}
+	if count >= webhookMaxPerUser {
+		return nil, "", fmt.Errorf("webhook limit reached")
+	}
+
+	secret := utils.GenerateSecureToken(32)
+	webhook := &models.Webhook{
+		UserID:     userID,
+		URL:        webhookURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+		Active:     true,
+	}
+	if err := s.db.WithContext(ctx).Create(webhook).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	s.logger.Infof("Webhook %s created for user %s", webhook.ID, userID)
+	return webhook, secret, nil
+}
+
+func (s *WebhookService) UpdateWebhook(ctx context.Context, userID, webhookID uuid.UUID, req *UpdateWebhookRequest) (*models.Webhook, error) {
+	webhook, err := s.getWebhook(ctx, userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.URL != nil {
+		webhookURL, err := normalizeWebhookURL(*req.URL)
+		if err != nil {
+			return nil, err
+		}
+		updates["url"] = webhookURL
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := normalizeWebhookEventTypes(*req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		updates["event_types"] = eventTypes
+	}
+	if req.Active != nil {
+		updates["active"] = *req.Active
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.WithContext(ctx).Model(webhook).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update webhook: %w", err)
+		}
+	}
+
+	return webhook, nil
+}
+
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID, webhookID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", webhookID, userID).Delete(&models.Webhook{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete webhook: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("webhook not found")
+		}
+		return tx.Where("webhook_id = ?", webhookID).Delete(&models.WebhookDeadLetter{}).Error
+	})
+}
+
+// Returns the deliveries of a webhook that failed after all retries, newest first
+func (s *WebhookService) GetDeadLetters(ctx context.Context, userID, webhookID uuid.UUID, limit int) ([]models.WebhookDeadLetter, error) {
+	if _, err := s.getWebhook(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	var deadLetters []models.WebhookDeadLetter
+	if err := s.db.WithContext(ctx).Where("webhook_id = ?", webhookID).
+		Order("created_at DESC").Limit(limit).Find(&deadLetters).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch failed deliveries: %w", err)
+	}
+	return deadLetters, nil
+}
+
+func (s *WebhookService) getWebhook(ctx context.Context, userID, webhookID uuid.UUID) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", webhookID, userID).First(&webhook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("webhook not found")
+		}
+		return nil, fmt.Errorf("failed to fetch webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+func normalizeWebhookURL(raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return "", fmt.Errorf("invalid webhook URL: must be an absolute http or https URL")
+	}
+	return parsed.String(), nil
+}
+
+func normalizeWebhookEventTypes(raw []string) (models.WebhookEventTypes, error) {
+	eventTypes := models.WebhookEventTypes{}
+	seen := make(map[string]bool)
+	for _, eventType := range raw {
+		eventType = strings.TrimSpace(eventType)
+		if !models.ActivityType(eventType).IsValid() {
+			return nil, fmt.Errorf("invalid event type: %s", eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	return eventTypes, nil
+}
+
+// OnActivity queues the activity for delivery, dropping it when the queue is full so
+// request handling never waits on webhook endpoints
+func (s *WebhookService) OnActivity(activity *models.DocumentActivity) {
+	select {
+	case s.events <- activity:
+	default:
+		s.logger.Warnf("[WEBHOOK] Delivery queue full, dropping %s activity %s", activity.ActivityType, activity.ID)
+	}
+}
+
+// Start subscribes to activities and delivers them until the context is cancelled
+func (s *WebhookService) Start(ctx context.Context) {
+	AddActivityListener(s)
+
+	for i := 0; i < webhookWorkers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case activity := <-s.events:
+					s.dispatch(ctx, activity)
+				}
+			}
+		}()
+	}
+}
+
+// Sends the activity to the matching webhooks of the document owner
+func (s *WebhookService) dispatch(ctx context.Context, activity *models.DocumentActivity) {
+	// Deleted documents still resolve to their owner
+	var ownerID uuid.UUID
+	if err := s.db.WithContext(ctx).Unscoped().Model(&models.Document{}).
+		Where("id = ?", activity.DocumentID).Select("user_id").Scan(&ownerID).Error; err != nil || ownerID == uuid.Nil {
+		return
+	}
+
+	var webhooks []models.Webhook
+	if err := s.db.WithContext(ctx).Where("user_id = ? AND active = true", ownerID).Find(&webhooks).Error; err != nil {
+		s.logger.Errorf("[WEBHOOK] Failed to fetch webhooks for user %s: %v", ownerID, err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		ID:          activity.ID,
+		Event:       activity.ActivityType,
+		OccurredAt:  activity.CreatedAt,
+		DocumentID:  activity.DocumentID,
+		ActorID:     activity.UserID,
+		Description: activity.Description,
+		Metadata:    activity.Metadata,
+	})
+	if err != nil {
+		s.logger.Errorf("[WEBHOOK] Failed to encode activity %s: %v", activity.ID, err)
+		return
+	}
+
+	for i := range webhooks {
+		if webhooks[i].Subscribes(activity.ActivityType) {
+			s.deliver(ctx, &webhooks[i], activity.ActivityType, body)
+		}
+	}
+}
+
+// Posts the payload, retrying with exponential backoff. Deliveries that keep failing
+// are stored as dead letters.
+func (s *WebhookService) deliver(ctx context.Context, webhook *models.Webhook, eventType models.ActivityType, body []byte) {
+	signature := signWebhookPayload(webhook.Secret, body)
+	delay := webhookRetryDelay
+
+	var statusCode int
+	var lastErr error
+	attempts := 0
+retry:
+	for attempts < webhookMaxAttempts {
+		attempts++
+		statusCode, lastErr = s.post(ctx, webhook.URL, eventType, signature, body)
+		if lastErr == nil {
+			return
+		}
+		if attempts == webhookMaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			break retry
+		case <-time.After(delay):
+			delay *= 2
+		}
+	}
+
+	s.logger.Warnf("[WEBHOOK] Giving up on %s delivery to webhook %s: %v", eventType, webhook.ID, lastErr)
+
+	deadLetter := &models.WebhookDeadLetter{
+		WebhookID:  webhook.ID,
+		EventType:  eventType,
+		Payload:    string(body),
+		Attempts:   attempts,
+		StatusCode: statusCode,
+		LastError:  lastErr.Error(),
+	}
+	// Recorded even during shutdown so the failure is not lost
+	if err := s.db.WithContext(context.Background()).Create(deadLetter).Error; err != nil {
+		s.logger.Errorf("[WEBHOOK] Failed to store dead letter for webhook %s: %v", webhook.ID, err)
+	}
+}
+
+func (s *WebhookService) post(ctx context.Context, webhookURL string, eventType models.ActivityType, signature string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "NoesisForge-Webhook/1.0")
+	req.Header.Set("X-Webhook-Event", string(eventType))
+	req.Header.Set("X-Signature", signature)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Receivers recompute the HMAC-SHA256 of the raw body with their secret and compare
+func signWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,194 @@
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/repositories/interfaces"
+	"github.com/google/uuid"
+)
+
+// MockDocumentRepository keeps documents and their view and download counts in memory. Only
+// the lookups, Create, the count methods and the trash sweep are implemented, the embedded
+// interface is nil so any other method panics. Setting Errors[method] makes that method fail
+// with the given error, PurgeErrors[id] makes Purge fail for that document only.
+type MockDocumentRepository struct {
+	interfaces.DocumentRepository
+
+	mu          sync.Mutex
+	documents   map[uuid.UUID]*models.Document
+	Errors      map[string]error
+	PurgeErrors map[uuid.UUID]error
+}
+
+var _ interfaces.DocumentRepository = (*MockDocumentRepository)(nil)
+
+func NewMockDocumentRepository() *MockDocumentRepository {
+	return &MockDocumentRepository{
+		documents:   make(map[uuid.UUID]*models.Document),
+		Errors:      make(map[string]error),
+		PurgeErrors: make(map[uuid.UUID]error),
+	}
+}
+
+// Stores a document directly, bypassing Errors. A missing ID is generated.
+func (m *MockDocumentRepository) Put(document *models.Document) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if document.ID == uuid.Nil {
+		document.ID = uuid.New()
+	}
+	stored := *document
+	m.documents[document.ID] = &stored
+}
+
+// Reports whether a document is stored, trashed or not
+func (m *MockDocumentRepository) Has(id uuid.UUID) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.documents[id]
+	return ok
+}
+
+// Returns the view and download counts of a document
+func (m *MockDocumentRepository) Counts(id uuid.UUID) (int64, int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	document, ok := m.documents[id]
+	if !ok {
+		return 0, 0
+	}
+	return document.ViewCount, document.DownloadCount
+}
+
+func (m *MockDocumentRepository) failure(method string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Errors[method]
+}
+
+func (m *MockDocumentRepository) get(id uuid.UUID, match func(*models.Document) bool) (*models.Document, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	document, ok := m.documents[id]
+	if !ok || !match(document) {
+		return nil, fmt.Errorf("document not found")
+	}
+	copied := *document
+	return &copied, nil
+}
+
+func (m *MockDocumentRepository) Create(ctx context.Context, document *models.Document) error {
+	if err := m.failure("Create"); err != nil {
+		return err
+	}
+	m.Put(document)
+	return nil
+}
+
+func (m *MockDocumentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Document, error) {
+	if err := m.failure("GetByID"); err != nil {
+		return nil, err
+	}
+	return m.get(id, func(*models.Document) bool { return true })
+}
+
+func (m *MockDocumentRepository) GetByIDAndUserID(ctx context.Context, id, userID uuid.UUID) (*models.Document, error) {
+	if err := m.failure("GetByIDAndUserID"); err != nil {
+		return nil, err
+	}
+	return m.get(id, func(document *models.Document) bool { return document.UserID == userID })
+}
+
+func (m *MockDocumentRepository) GetByContentHash(ctx context.Context, userID uuid.UUID, contentHash string) (*models.Document, error) {
+	if err := m.failure("GetByContentHash"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, document := range m.documents {
+		if document.UserID == userID && document.ContentHash == contentHash {
+			copied := *document
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("document not found")
+}
+
+func (m *MockDocumentRepository) IncrementViewCount(ctx context.Context, id uuid.UUID) error {
+	if err := m.failure("IncrementViewCount"); err != nil {
+		return err
+	}
+	return m.AddCounts(ctx, id, 1, 0)
+}
+
+func (m *MockDocumentRepository) IncrementDownloadCount(ctx context.Context, id uuid.UUID) error {
+	if err := m.failure("IncrementDownloadCount"); err != nil {
+		return err
+	}
+	return m.AddCounts(ctx, id, 0, 1)
+}
+
+func (m *MockDocumentRepository) AddCounts(ctx context.Context, id uuid.UUID, views, downloads int64) error {
+	if err := m.failure("AddCounts"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	document, ok := m.documents[id]
+	if !ok {
+		return fmt.Errorf("document not found")
+	}
+	document.ViewCount += views
+	document.DownloadCount += downloads
+	return nil
+}
+
+func (m *MockDocumentRepository) ListTrashedBefore(ctx context.Context, cutoff time.Time, afterID uuid.UUID, limit int) ([]models.Document, error) {
+	if err := m.failure("ListTrashedBefore"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Same order as Postgres sorts uuids
+	var documents []models.Document
+	for id, document := range m.documents {
+		if document.DeletedAt.Valid && document.DeletedAt.Time.Before(cutoff) && bytes.Compare(id[:], afterID[:]) > 0 {
+			documents = append(documents, *document)
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		return bytes.Compare(documents[i].ID[:], documents[j].ID[:]) < 0
+	})
+	if len(documents) > limit {
+		documents = documents[:limit]
+	}
+	return documents, nil
+}
+
+// Documents have no revisions in the mock
+func (m *MockDocumentRepository) GetRevisions(ctx context.Context, documentID uuid.UUID) ([]models.DocumentRevision, error) {
+	if err := m.failure("GetRevisions"); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func (m *MockDocumentRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	if err := m.failure("Purge"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.PurgeErrors[id]; err != nil {
+		return err
+	}
+	delete(m.documents, id)
+	return nil
+}
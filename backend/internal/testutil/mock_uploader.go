@@ -0,0 +1,168 @@
+// Package testutil holds test doubles for the external services the backend depends on
+package testutil
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/services"
+)
+
+const mockBucketName = "mock-bucket"
+
+// Object kept in memory by MockUploader
+type MockObject struct {
+	Data        []byte
+	ContentType string
+}
+
+// MockUploader is an in-memory services.Uploader. Objects can be seeded with Put and
+// inspected with Get, setting Errors[method] makes that method fail with the given error.
+type MockUploader struct {
+	mu      sync.Mutex
+	objects map[string]MockObject
+	Errors  map[string]error
+}
+
+var _ services.Uploader = (*MockUploader)(nil)
+
+func NewMockUploader() *MockUploader {
+	return &MockUploader{
+		objects: make(map[string]MockObject),
+		Errors:  make(map[string]error),
+	}
+}
+
+// Stores an object directly, bypassing Errors
+func (m *MockUploader) Put(objectName string, data []byte, contentType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[objectName] = MockObject{Data: append([]byte(nil), data...), ContentType: contentType}
+}
+
+// Returns a stored object, false when it doesn't exist
+func (m *MockUploader) Get(objectName string) (MockObject, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	object, ok := m.objects[objectName]
+	return object, ok
+}
+
+// Returns the names of all stored objects
+func (m *MockUploader) ObjectNames() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.objects))
+	for name := range m.objects {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (m *MockUploader) failure(method string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Errors[method]
+}
+
+func (m *MockUploader) BucketName() string {
+	return mockBucketName
+}
+
+func (m *MockUploader) UploadFile(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, contentType string) error {
+	if err := m.failure("UploadFile"); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to upload file to MinIO: %w", err)
+	}
+	m.Put(objectName, data, contentType)
+	return nil
+}
+
+func (m *MockUploader) UploadThumbnail(ctx context.Context, objectName string, data []byte, contentType string) (*services.UploadResult, error) {
+	if err := m.failure("UploadThumbnail"); err != nil {
+		return nil, err
+	}
+	m.Put(objectName, data, contentType)
+	return &services.UploadResult{
+		ObjectName: objectName,
+		FileName:   filepath.Base(objectName),
+		Size:       int64(len(data)),
+		URL:        m.GetFileUrl(mockBucketName, objectName),
+	}, nil
+}
+
+func (m *MockUploader) CopyFile(ctx context.Context, srcObject, dstObject string) error {
+	if err := m.failure("CopyFile"); err != nil {
+		return err
+	}
+	object, ok := m.Get(srcObject)
+	if !ok {
+		return fmt.Errorf("failed to copy object %s to %s: object not found", srcObject, dstObject)
+	}
+	m.Put(dstObject, object.Data, object.ContentType)
+	return nil
+}
+
+func (m *MockUploader) GetFileUrl(bucketName, objectName string) string {
+	return fmt.Sprintf("mock://%s/%s", bucketName, objectName)
+}
+
+func (m *MockUploader) DownloadFile(ctx context.Context, objectName string) (io.ReadCloser, error) {
+	if err := m.failure("DownloadFile"); err != nil {
+		return nil, err
+	}
+	object, ok := m.Get(objectName)
+	if !ok {
+		return nil, fmt.Errorf("object %s not found", objectName)
+	}
+	return io.NopCloser(bytes.NewReader(object.Data)), nil
+}
+
+func (m *MockUploader) DownloadFileRange(ctx context.Context, objectName string, start, end int64) (io.ReadCloser, error) {
+	if err := m.failure("DownloadFileRange"); err != nil {
+		return nil, err
+	}
+	object, ok := m.Get(objectName)
+	if !ok {
+		return nil, fmt.Errorf("object %s not found", objectName)
+	}
+	if start < 0 || end < start || start >= int64(len(object.Data)) {
+		return nil, fmt.Errorf("invalid range %d-%d", start, end)
+	}
+	end = min(end, int64(len(object.Data))-1)
+	return io.NopCloser(bytes.NewReader(object.Data[start : end+1])), nil
+}
+
+func (m *MockUploader) FileExists(ctx context.Context, objectName string) (bool, error) {
+	if err := m.failure("FileExists"); err != nil {
+		return false, err
+	}
+	_, ok := m.Get(objectName)
+	return ok, nil
+}
+
+func (m *MockUploader) DeleteFile(ctx context.Context, objectName string) error {
+	if err := m.failure("DeleteFile"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, objectName)
+	return nil
+}
+
+func (m *MockUploader) GeneratePresignedURL(ctx context.Context, objectName string, expiry time.Duration) (string, error) {
+	if err := m.failure("GeneratePresignedURL"); err != nil {
+		return "", err
+	}
+	return m.GetFileUrl(mockBucketName, objectName) + "?expiry=" + url.QueryEscape(expiry.String()), nil
+}
@@ -0,0 +1,36 @@
+package testutil
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/textproto"
+	"testing"
+)
+
+// NewFileHeader returns an uploaded file as the handlers receive it from a multipart form
+func NewFileHeader(tb testing.TB, filename, contentType string, data []byte) *multipart.FileHeader {
+	tb.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+filename+`"`)
+	header.Set("Content-Type", contentType)
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		tb.Fatalf("failed to create form file: %v", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		tb.Fatalf("failed to write form file: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		tb.Fatalf("failed to close form: %v", err)
+	}
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(int64(len(data)) + 1024)
+	if err != nil {
+		tb.Fatalf("failed to read form: %v", err)
+	}
+	tb.Cleanup(func() { form.RemoveAll() })
+	return form.File["file"][0]
+}
@@ -0,0 +1,288 @@
+package testutil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+	"github.com/eyuppastirmaci/noesis-forge/internal/redis"
+)
+
+// RedisServer is an in-memory stand-in for Redis speaking enough of its protocol for the
+// counters, locks and caches: GET, SET with NX, DEL, EXISTS, RENAME, INCR, HINCRBY and
+// HGETALL. Every command runs under one lock so they are atomic like in Redis. Expiry is
+// accepted but ignored, tests finish well before any TTL the services use.
+type RedisServer struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	strings map[string]string
+	hashes  map[string]map[string]string
+}
+
+// NewRedisClient starts a RedisServer and returns a client connected to it, both are closed
+// when the test ends
+func NewRedisClient(tb testing.TB) (*redis.Client, *RedisServer) {
+	tb.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatalf("failed to start fake Redis: %v", err)
+	}
+	server := &RedisServer{
+		listener: listener,
+		strings:  make(map[string]string),
+		hashes:   make(map[string]map[string]string),
+	}
+	go server.serve()
+	tb.Cleanup(func() { listener.Close() })
+
+	client, err := redis.NewClient(config.RedisConfig{
+		URL:          "redis://" + listener.Addr().String(),
+		PoolSize:     32,
+		DialTimeout:  time.Second,
+		ReadTimeout:  time.Second,
+		WriteTimeout: time.Second,
+	})
+	if err != nil {
+		tb.Fatalf("failed to connect to fake Redis: %v", err)
+	}
+	tb.Cleanup(func() { client.Close() })
+
+	return client, server
+}
+
+// Returns a hash as it is stored, nil when it doesn't exist
+func (s *RedisServer) Hash(key string) map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	hash, ok := s.hashes[key]
+	if !ok {
+		return nil
+	}
+	copied := make(map[string]string, len(hash))
+	for field, value := range hash {
+		copied[field] = value
+	}
+	return copied
+}
+
+func (s *RedisServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *RedisServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+
+	for {
+		args, err := readRedisCommand(reader)
+		if err != nil {
+			return
+		}
+		s.execute(writer, args)
+		// Pipelined commands are answered together
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Reads one command sent as an array of bulk strings
+func readRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readRedisLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	count, err := strconv.Atoi(line[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		header, err := readRedisLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(header, "$") {
+			return nil, fmt.Errorf("unexpected argument %q", header)
+		}
+		size, err := strconv.Atoi(header[1:])
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args = append(args, string(data[:size]))
+	}
+	return args, nil
+}
+
+func readRedisLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+func (s *RedisServer) execute(w *bufio.Writer, args []string) {
+	if len(args) == 0 {
+		writeRedisError(w, "ERR empty command")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch name := strings.ToUpper(args[0]); {
+	case name == "PING":
+		w.WriteString("+PONG\r\n")
+	case name == "GET" && len(args) == 2:
+		if value, ok := s.strings[args[1]]; ok {
+			writeRedisBulk(w, value)
+		} else {
+			w.WriteString("$-1\r\n")
+		}
+	case name == "SET" && len(args) >= 3:
+		onlyNew := false
+		for _, option := range args[3:] {
+			if strings.EqualFold(option, "NX") {
+				onlyNew = true
+			}
+		}
+		if onlyNew && s.exists(args[1]) {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		s.delete(args[1])
+		s.strings[args[1]] = args[2]
+		w.WriteString("+OK\r\n")
+	case name == "DEL" && len(args) >= 2:
+		deleted := 0
+		for _, key := range args[1:] {
+			if s.delete(key) {
+				deleted++
+			}
+		}
+		writeRedisInt(w, int64(deleted))
+	case name == "EXISTS" && len(args) >= 2:
+		found := 0
+		for _, key := range args[1:] {
+			if s.exists(key) {
+				found++
+			}
+		}
+		writeRedisInt(w, int64(found))
+	case name == "RENAME" && len(args) == 3:
+		if !s.exists(args[1]) {
+			writeRedisError(w, "ERR no such key")
+			return
+		}
+		value, isString := s.strings[args[1]]
+		hash := s.hashes[args[1]]
+		s.delete(args[1])
+		s.delete(args[2])
+		if isString {
+			s.strings[args[2]] = value
+		} else {
+			s.hashes[args[2]] = hash
+		}
+		w.WriteString("+OK\r\n")
+	case name == "INCR" && len(args) == 2:
+		value, err := incrementRedisValue(s.strings[args[1]], 1)
+		if err != nil {
+			writeRedisError(w, err.Error())
+			return
+		}
+		s.strings[args[1]] = strconv.FormatInt(value, 10)
+		writeRedisInt(w, value)
+	case name == "HINCRBY" && len(args) == 4:
+		by, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil {
+			writeRedisError(w, "ERR value is not an integer or out of range")
+			return
+		}
+		hash, ok := s.hashes[args[1]]
+		if !ok {
+			hash = make(map[string]string)
+			s.hashes[args[1]] = hash
+		}
+		value, err := incrementRedisValue(hash[args[2]], by)
+		if err != nil {
+			writeRedisError(w, err.Error())
+			return
+		}
+		hash[args[2]] = strconv.FormatInt(value, 10)
+		writeRedisInt(w, value)
+	case name == "HGETALL" && len(args) == 2:
+		hash := s.hashes[args[1]]
+		fmt.Fprintf(w, "*%d\r\n", len(hash)*2)
+		for field, value := range hash {
+			writeRedisBulk(w, field)
+			writeRedisBulk(w, value)
+		}
+	default:
+		// Includes HELLO, the client falls back to RESP2 and skips what it was setting up
+		writeRedisError(w, "ERR unknown command '"+args[0]+"'")
+	}
+}
+
+func (s *RedisServer) exists(key string) bool {
+	_, isString := s.strings[key]
+	_, isHash := s.hashes[key]
+	return isString || isHash
+}
+
+func (s *RedisServer) delete(key string) bool {
+	existed := s.exists(key)
+	delete(s.strings, key)
+	delete(s.hashes, key)
+	return existed
+}
+
+func incrementRedisValue(current string, by int64) (int64, error) {
+	if current == "" {
+		return by, nil
+	}
+	value, err := strconv.ParseInt(current, 10, 64)
+	if err != nil {
+		return 0, errors.New("ERR value is not an integer or out of range")
+	}
+	return value + by, nil
+}
+
+func writeRedisBulk(w *bufio.Writer, value string) {
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(value), value)
+}
+
+func writeRedisInt(w *bufio.Writer, value int64) {
+	fmt.Fprintf(w, ":%d\r\n", value)
+}
+
+func writeRedisError(w *bufio.Writer, message string) {
+	w.WriteString("-" + message + "\r\n")
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Activity Response Types
+
+// Represents the views and downloads of a document on a single day
+type DailyAccessCount struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	Views     int64  `json:"views"`
+	Downloads int64  `json:"downloads"`
+}
+
+// Represents a single access of a document
+type DocumentAccessEntry struct {
+	Action     string     `json:"action"`           // view, preview, download or the action recorded through a share
+	Channel    string     `json:"channel"`          // app, public_link or share
+	UserID     *uuid.UUID `json:"userID,omitempty"` // nil for public link visitors
+	Username   string     `json:"username,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Email      string     `json:"email,omitempty"`
+	IPAddress  string     `json:"ipAddress,omitempty"`
+	UserAgent  string     `json:"userAgent,omitempty"`
+	AccessedAt time.Time  `json:"accessedAt"`
+}
+
+// Represents who viewed and downloaded a document over the last given number of days
+type DocumentAnalyticsResponse struct {
+	DocumentID     uuid.UUID             `json:"documentID"`
+	TotalViews     int64                 `json:"totalViews"` // All time, the owner's own views included
+	TotalDownloads int64                 `json:"totalDownloads"`
+	Views          int64                 `json:"views"` // Within the range
+	Downloads      int64                 `json:"downloads"`
+	UniqueViewers  int64                 `json:"uniqueViewers"` // Signed-in users other than the owner
+	PerDay         []DailyAccessCount    `json:"perDay"`
+	RecentAccesses []DocumentAccessEntry `json:"recentAccesses"`
+	Days           int                   `json:"days"`
+	From           time.Time             `json:"from"`
+}
@@ -0,0 +1,125 @@
+package types
+
+import (
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/google/uuid"
+)
+
+// Admin Response Types
+
+// Represents the number of uploads on a single day
+type DailyCount struct {
+	Date  string `json:"date"` // YYYY-MM-DD
+	Count int64  `json:"count"`
+}
+
+// Represents document count and size for a file type
+type FileTypeStat struct {
+	FileType   string `json:"fileType"`
+	Count      int64  `json:"count"`
+	TotalBytes int64  `json:"totalBytes"`
+}
+
+// Represents platform-wide statistics for the admin dashboard
+type PlatformStatsResponse struct {
+	TotalUsers        int64          `json:"totalUsers"`
+	ActiveUsers       int64          `json:"activeUsers"` // Users who logged in within the range
+	TotalDocuments    int64          `json:"totalDocuments"`
+	TotalStorageBytes int64          `json:"totalStorageBytes"` // Includes documents in trash
+	UploadsPerDay     []DailyCount   `json:"uploadsPerDay"`
+	TopFileTypes      []FileTypeStat `json:"topFileTypes"`
+	Days              int            `json:"days"`
+	From              time.Time      `json:"from"`
+	GeneratedAt       time.Time      `json:"generatedAt"`
+}
+
+// Represents the search vector of a single document before and after it was recomputed
+type DocumentReindexResponse struct {
+	DocumentID     string    `json:"documentId"`
+	Language       string    `json:"language"`
+	SearchConfig   string    `json:"searchConfig"` // Text search configuration the language resolved to
+	PreviousVector string    `json:"previousVector"`
+	SearchVector   string    `json:"searchVector"`
+	LexemeCount    int       `json:"lexemeCount"`
+	Changed        bool      `json:"changed"`
+	ReindexedAt    time.Time `json:"reindexedAt"`
+}
+
+// Represents the filters of the admin document listing
+type AdminDocumentListRequest struct {
+	Page     int
+	Limit    int
+	Search   string // Matches title or original file name
+	OwnerID  *uuid.UUID
+	FileType string
+	Status   string
+}
+
+// Represents the owner shown next to a document in admin listings
+type AdminDocumentOwner struct {
+	ID       uuid.UUID         `json:"id"`
+	Username string            `json:"username"`
+	Name     string            `json:"name"`
+	Email    string            `json:"email"`
+	Status   models.UserStatus `json:"status"`
+}
+
+// Represents a document of any user in the admin listing
+type AdminDocument struct {
+	ID               uuid.UUID             `json:"id"`
+	Title            string                `json:"title"`
+	OriginalFileName string                `json:"originalFileName"`
+	FileType         models.DocumentType   `json:"fileType"`
+	FileSize         int64                 `json:"fileSize"`
+	Status           models.DocumentStatus `json:"status"`
+	IsPublic         bool                  `json:"isPublic"`
+	ViewCount        int64                 `json:"viewCount"`
+	DownloadCount    int64                 `json:"downloadCount"`
+	Owner            AdminDocumentOwner    `json:"owner"`
+	CreatedAt        time.Time             `json:"createdAt"`
+	UpdatedAt        time.Time             `json:"updatedAt"`
+}
+
+// Represents a page of the admin document listing
+type AdminDocumentListResponse struct {
+	Documents  []AdminDocument `json:"documents"`
+	Total      int64           `json:"total"`
+	Page       int             `json:"page"`
+	Limit      int             `json:"limit"`
+	TotalPages int             `json:"totalPages"`
+}
+
+// Represents the filters of the admin user listing
+type AdminUserListRequest struct {
+	Page   int
+	Limit  int
+	Search string // Matches email, username or name
+	Status string
+}
+
+// Represents a user with account state and usage in the admin listing
+type AdminUser struct {
+	ID            uuid.UUID         `json:"id"`
+	Email         string            `json:"email"`
+	Username      string            `json:"username"`
+	Name          string            `json:"name"`
+	Status        models.UserStatus `json:"status"`
+	EmailVerified bool              `json:"emailVerified"`
+	Locked        bool              `json:"locked"` // Temporarily locked after failed logins
+	Role          string            `json:"role"`
+	LastLogin     *time.Time        `json:"lastLogin,omitempty"`
+	DocumentCount int64             `json:"documentCount"`
+	StorageBytes  int64             `json:"storageBytes"`
+	CreatedAt     time.Time         `json:"createdAt"`
+}
+
+// Represents a page of the admin user listing
+type AdminUserListResponse struct {
+	Users      []AdminUser `json:"users"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page"`
+	Limit      int         `json:"limit"`
+	TotalPages int         `json:"totalPages"`
+}
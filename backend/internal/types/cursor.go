@@ -0,0 +1,38 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Position in a cursor paginated document listing, the last document of the previous page.
+// Documents are ordered by creation time with the ID breaking ties, so a page stays stable
+// while documents are added or removed.
+type DocumentCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// Encodes the cursor as an opaque URL safe string
+func (c DocumentCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decodes a cursor produced by Encode
+func DecodeDocumentCursor(value string) (*DocumentCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var cursor DocumentCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == uuid.Nil || cursor.CreatedAt.IsZero() {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return &cursor, nil
+}
@@ -11,31 +11,61 @@ import (
 
 // Represents the request for uploading a document
 type UploadDocumentRequest struct {
-	Title       string `json:"title" validate:"required,min=1,max=255"`
-	Description string `json:"description" validate:"max=1000"`
-	Tags        string `json:"tags" validate:"max=500"`
-	IsPublic    bool   `json:"isPublic"`
+	Title          string                 `json:"title" validate:"required,min=1,max=255"`
+	Description    string                 `json:"description" validate:"max=1000"`
+	Tags           string                 `json:"tags" validate:"max=500"`
+	IsPublic       bool                   `json:"isPublic"`
+	Language       string                 `json:"language"`       // Optional, defaults to english
+	CustomMetadata map[string]interface{} `json:"customMetadata"` // Values for admin-defined custom fields
+	AllowDuplicate bool                   `json:"allowDuplicate"` // Store the file even if the user already has identical content
+	RunOCR         bool                   `json:"runOcr"`         // OCR the pages of a PDF that turns out to be scanned images
+	OCRLanguage    string                 `json:"ocrLanguage"`    // Tesseract languages to OCR in, e.g. deu+eng
+	ExpiresAt      *time.Time             `json:"expiresAt"`      // Optional, the document is removed once it passes
 }
 
 // Represents the request for updating a document
 type UpdateDocumentRequest struct {
-	Title       string `json:"title" validate:"required,min=1,max=255"`
-	Description string `json:"description" validate:"max=1000"`
-	Tags        string `json:"tags" validate:"max=500"`
-	IsPublic    bool   `json:"isPublic"`
-	HasNewFile  bool   `json:"hasNewFile"`
+	Title          string                 `json:"title" validate:"required,min=1,max=255"`
+	Description    string                 `json:"description" validate:"max=1000"`
+	Tags           string                 `json:"tags" validate:"max=500"`
+	IsPublic       bool                   `json:"isPublic"`
+	HasNewFile     bool                   `json:"hasNewFile"`
+	CustomMetadata map[string]interface{} `json:"customMetadata"` // nil keeps the current values
+	ExpiresAt      *time.Time             `json:"expiresAt"`      // nil keeps the current expiration
+	ClearExpiresAt bool                   `json:"clearExpiresAt"` // Sent as an empty expiresAt, keeps the document indefinitely
+}
+
+// Represents the request for changing tags and visibility of many documents at once
+type BulkUpdateDocumentsRequest struct {
+	DocumentIDs []string `json:"documentIds"`
+	AddTags     []string `json:"addTags"`
+	RemoveTags  []string `json:"removeTags"`
+	IsPublic    *bool    `json:"isPublic"` // nil keeps the current visibility
 }
 
 // Represents the request for listing documents
 type DocumentListRequest struct {
-	Page     int    `json:"page" validate:"min=1"`
-	Limit    int    `json:"limit" validate:"min=1,max=100"`
-	Search   string `json:"search"`
-	FileType string `json:"fileType"`
-	Status   string `json:"status"`
-	Tags     string `json:"tags"`
-	SortBy   string `json:"sortBy"`  // name, date, size, views, relevance
-	SortDir  string `json:"sortDir"` // asc, desc
+	Page         int               `json:"page" validate:"min=1"`
+	Limit        int               `json:"limit" validate:"min=1,max=100"`
+	Search       string            `json:"search"`
+	FileType     string            `json:"fileType"`
+	Status       string            `json:"status"`
+	Tags         string            `json:"tags"`
+	Language     string            `json:"language"`
+	CustomFields map[string]string `json:"customFields"` // Exact-match filters on custom metadata
+	SortBy       string            `json:"sortBy"`       // name, date, size, views, relevance
+	SortDir      string            `json:"sortDir"`      // asc, desc
+	// Folder filter, nil lists every folder unless Unfiled asks for documents at the root
+	FolderID          *uuid.UUID `json:"folderId"`
+	Unfiled           bool       `json:"unfiled"`
+	IncludeSubfolders bool       `json:"includeSubfolders"` // Also list documents in folders below FolderID
+	// Cursor mode replaces Page, Cursor is nil on the first page
+	UseCursor bool            `json:"-"`
+	Cursor    *DocumentCursor `json:"-"`
+	// Skips the search cache, set by a "Cache-Control: no-cache" request header
+	NoCache bool `json:"-"`
+	// Reports which search strategy answered, only honored for admins and outside production
+	Debug bool `json:"-"`
 }
 
 // Document Response Types
@@ -52,28 +82,111 @@ type DocumentResponse struct {
 	MimeType         string                `json:"mimeType"`
 	Status           models.DocumentStatus `json:"status"`
 	Version          int                   `json:"version"`
-	Tags             string                `json:"tags"`
+	Tags             []string              `json:"tags"`
 	IsPublic         bool                  `json:"isPublic"`
 	ViewCount        int64                 `json:"viewCount"`
 	DownloadCount    int64                 `json:"downloadCount"`
 	PageCount        *int                  `json:"pageCount,omitempty"`
+	OCRApplied       bool                  `json:"ocrApplied"`
+	Language         string                `json:"language"`
 	UserID           uuid.UUID             `json:"userID"`
 	Summary          string                `json:"summary"`
 	ProcessedAt      *time.Time            `json:"processedAt,omitempty"`
+	ProcessingError  string                `json:"processingError,omitempty"`
+	ScanStatus       models.ScanStatus     `json:"scanStatus,omitempty"` // Empty when the file was stored unscanned
+	FileWithheld     bool                  `json:"fileWithheld"`         // The virus scan keeps the file from being downloaded or previewed
+	CustomMetadata   models.CustomMetadata `json:"customMetadata"`
 	CreatedAt        time.Time             `json:"createdAt"`
 	UpdatedAt        time.Time             `json:"updatedAt"`
 	HasThumbnail     bool                  `json:"hasThumbnail"`
+	FolderID         *uuid.UUID            `json:"folderID,omitempty"`
+	ExpiresAt        *time.Time            `json:"expiresAt,omitempty"`
+	ExpiresIn        *int64                `json:"expiresIn,omitempty"` // Seconds left until ExpiresAt
 	UserAccessLevel  string                `json:"userAccessLevel"`
+	IsPinned         bool                  `json:"isPinned"` // Pinned by the requesting user
 	StoragePath      string                `json:"storagePath"`
 }
 
-// Represents the response for document listing
+// Represents the response for document listing. In cursor mode Total, Page and TotalPages
+// are not computed and NextCursor is set while there are more documents.
 type DocumentListResponse struct {
 	Documents  []DocumentResponse `json:"documents"`
 	Total      int64              `json:"total"`
 	Page       int                `json:"page"`
 	Limit      int                `json:"limit"`
 	TotalPages int                `json:"totalPages"`
+	NextCursor string             `json:"nextCursor,omitempty"`
+	Debug      *SearchDebug       `json:"debug,omitempty"` // Set when debug=true was requested
+}
+
+// Represents a document awaiting review with its unresolved comment summary
+type ReviewQueueItem struct {
+	Document           DocumentResponse `json:"document"`
+	UnresolvedComments int64            `json:"unresolvedComments"`
+	OldestUnresolvedAt time.Time        `json:"oldestUnresolvedAt"`
+}
+
+// Represents a paginated list of documents awaiting review
+type ReviewQueueResponse struct {
+	Documents  []ReviewQueueItem `json:"documents"`
+	Total      int64             `json:"total"`
+	Page       int               `json:"page"`
+	Limit      int               `json:"limit"`
+	TotalPages int               `json:"totalPages"`
+}
+
+// Document with its unresolved comment summary, as returned by the repository
+type PendingReview struct {
+	Document           models.Document
+	UnresolvedCount    int64
+	OldestUnresolvedAt time.Time
+}
+
+// Represents a document the user recently viewed or downloaded
+type RecentDocumentItem struct {
+	Document   DocumentResponse    `json:"document"`
+	AccessType models.ActivityType `json:"accessType"` // view or download
+	AccessedAt time.Time           `json:"accessedAt"`
+}
+
+// Represents the user's recently accessed documents, most recent first
+type RecentDocumentsResponse struct {
+	Documents []RecentDocumentItem `json:"documents"`
+}
+
+// Document with the user's latest view or download of it, as returned by the repository
+type RecentAccess struct {
+	Document   models.Document
+	AccessType models.ActivityType
+	AccessedAt time.Time
+}
+
+// Represents a tag and the number of documents using it
+type TagCount struct {
+	Name          string `json:"name"`
+	DocumentCount int64  `json:"documentCount"`
+}
+
+// Represents the extracted text of a document
+type DocumentContentResponse struct {
+	DocumentID uuid.UUID `json:"documentId"`
+	Content    string    `json:"content"`
+	Length     int       `json:"length"`
+	// Character offset where each page starts, only set for paginated formats
+	Pages []DocumentContentPage `json:"pages,omitempty"`
+}
+
+// Represents where a page starts in the extracted text
+type DocumentContentPage struct {
+	Page   int `json:"page"`
+	Offset int `json:"offset"`
+}
+
+// Represents the existing document an upload duplicates
+type DuplicateDocument struct {
+	DocumentID uuid.UUID `json:"documentId"`
+	Title      string    `json:"title"`
+	CreatedAt  time.Time `json:"createdAt"`
 }
 
 // Rrepresents user document statistics
@@ -81,3 +194,75 @@ type UserStatsResponse struct {
 	DocumentsThisMonth int64 `json:"documentsThisMonth"`
 	TotalStorageUsage  int64 `json:"totalStorageUsage"`
 }
+
+// Represents the storage used by one file type
+type StorageByType struct {
+	FileType    string `json:"fileType"`
+	Count       int64  `json:"count"`
+	TotalBytes  int64  `json:"totalBytes"`
+	AverageSize int64  `json:"averageSize"`
+}
+
+// Represents the uploads of one calendar month
+type MonthlyUploads struct {
+	Month      string `json:"month"` // YYYY-MM
+	Count      int64  `json:"count"`
+	TotalBytes int64  `json:"totalBytes"`
+}
+
+// Represents where a user's storage goes, documents in trash are not included
+type StorageBreakdownResponse struct {
+	TotalDocuments int64            `json:"totalDocuments"`
+	TotalBytes     int64            `json:"totalBytes"`
+	AverageSize    int64            `json:"averageSize"`
+	ByFileType     []StorageByType  `json:"byFileType"`
+	Timeline       []MonthlyUploads `json:"timeline"` // Oldest month first, months without uploads included
+}
+
+// Outcome of comparing a stored file with the hash recorded at upload
+type IntegrityStatus string
+
+const (
+	IntegrityStatusOK       IntegrityStatus = "ok"
+	IntegrityStatusMismatch IntegrityStatus = "mismatch"
+	// Documents uploaded before hashes were recorded can't be checked
+	IntegrityStatusUnverified IntegrityStatus = "unverified"
+)
+
+// Represents the result of re-reading a document's file from storage
+type DocumentIntegrityResponse struct {
+	DocumentID   uuid.UUID       `json:"documentId"`
+	Status       IntegrityStatus `json:"status"`
+	ExpectedHash string          `json:"expectedHash,omitempty"`
+	ActualHash   string          `json:"actualHash"`
+	ExpectedSize int64           `json:"expectedSize"`
+	ActualSize   int64           `json:"actualSize"`
+	CheckedAt    time.Time       `json:"checkedAt"`
+}
+
+// Represents a metadata field that differs between two versions
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Identifies one side of a revision comparison, the current version has no revision ID
+type RevisionRef struct {
+	RevisionID *uuid.UUID `json:"revisionId,omitempty"`
+	Version    int        `json:"version"`
+}
+
+// Represents the differences between two versions of a document
+type RevisionDiffResponse struct {
+	From RevisionRef `json:"from"`
+	To   RevisionRef `json:"to"`
+	// Fields the revisions in between recorded as changed
+	ChangedFields []string `json:"changedFields"`
+	FileChanged   bool     `json:"fileChanged"`
+	// Unset when either version predates metadata snapshots
+	Metadata []FieldChange `json:"metadata,omitempty"`
+	// False when either version has no extracted text, the diff is then metadata only
+	HasContentDiff bool   `json:"hasContentDiff"`
+	ContentDiff    string `json:"contentDiff,omitempty"` // Unified diff of the extracted text
+}
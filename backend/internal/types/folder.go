@@ -0,0 +1,22 @@
+package types
+
+import "github.com/google/uuid"
+
+// Represents the request for creating a folder
+type CreateFolderRequest struct {
+	Name           string     `json:"name" binding:"required,max=255"`
+	ParentFolderID *uuid.UUID `json:"parentFolderId"` // Omitted creates the folder at the root
+}
+
+// Represents the request for renaming or moving a folder, omitted fields are kept
+type UpdateFolderRequest struct {
+	Name *string `json:"name" binding:"omitempty,max=255"`
+	// Destination parent when MoveToRoot is false, nil keeps the current parent
+	ParentFolderID *uuid.UUID `json:"parentFolderId"`
+	MoveToRoot     bool       `json:"moveToRoot"`
+}
+
+// Represents the request for filing a document in a folder
+type MoveDocumentRequest struct {
+	FolderID *uuid.UUID `json:"folderId"` // nil moves the document to the root
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/google/uuid"
+)
+
+// Represents the request for creating a group
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Description string `json:"description" binding:"max=1000"`
+}
+
+// Represents the request for renaming a group or changing its description, omitted fields are kept
+type UpdateGroupRequest struct {
+	Name        *string `json:"name" binding:"omitempty,max=255"`
+	Description *string `json:"description" binding:"omitempty,max=1000"`
+}
+
+// Represents the request for adding a user to a group
+type AddGroupMemberRequest struct {
+	Identifier string `json:"identifier" binding:"required"` // Email or username of a registered user
+}
+
+// Represents the request for sharing a document with every member of a group
+type ShareWithGroupRequest struct {
+	GroupID       uuid.UUID          `json:"groupId" binding:"required"`
+	AccessLevel   models.AccessLevel `json:"accessLevel" binding:"required"`
+	ExpiresInDays int                `json:"expiresInDays" binding:"min=0"` // 0 never expires
+}
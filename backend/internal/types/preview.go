@@ -0,0 +1,84 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/google/uuid"
+)
+
+// Tells the frontend how a preview URL should be rendered
+type PreviewType string
+
+const (
+	PreviewTypePDF   PreviewType = "pdf"
+	PreviewTypeImage PreviewType = "image"
+	PreviewTypeText  PreviewType = "text"
+	// No inline preview is available, the URL downloads the original file
+	PreviewTypeDownload PreviewType = "download"
+)
+
+// Represents a resolved document preview
+type DocumentPreviewResponse struct {
+	PreviewType PreviewType `json:"previewType"`
+	URL         string      `json:"url"`
+	MimeType    string      `json:"mimeType"`
+	Strategy    string      `json:"strategy"`
+	// Unset for URLs served by the API, which do not expire
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Source formats of a rendered preview
+const (
+	RenderedFormatMarkdown = "markdown"
+	RenderedFormatText     = "text"
+)
+
+// Represents a document rendered to sanitized HTML for inline display. Documents that cannot
+// be rendered come back with Rendered false and their regular preview in Fallback.
+type RenderedPreviewResponse struct {
+	Rendered  bool                     `json:"rendered"`
+	Format    string                   `json:"format,omitempty"` // markdown or text
+	HTML      string                   `json:"html,omitempty"`
+	Truncated bool                     `json:"truncated"` // Only the beginning of the document was rendered
+	Fallback  *DocumentPreviewResponse `json:"fallback,omitempty"`
+}
+
+// Represents a presigned URL of a stored thumbnail
+type ThumbnailURLResponse struct {
+	URL         string    `json:"url"`
+	ContentType string    `json:"contentType"`
+	Size        string    `json:"size"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
+
+// Represents asking for the presigned URLs of several documents at once. Size and format
+// select the thumbnail variant as on the single thumbnail URL endpoint.
+type PresignedURLsRequest struct {
+	DocumentIDs    []string `json:"documentIds"`
+	Size           string   `json:"size"`           // small, medium or large, medium when empty
+	Format         string   `json:"format"`         // jpeg or webp, negotiated from Accept when empty
+	IncludePreview bool     `json:"includePreview"` // Also resolve each document's preview
+}
+
+// Represents the presigned URLs of one document. Thumbnail is unset for documents without one.
+type DocumentPresignedURLs struct {
+	DocumentID uuid.UUID                `json:"documentID"`
+	Thumbnail  *ThumbnailURLResponse    `json:"thumbnail,omitempty"`
+	Preview    *DocumentPreviewResponse `json:"preview,omitempty"`
+}
+
+// Represents the presigned URLs of the accessible documents of a batch, in request order.
+// Documents that don't exist, aren't accessible or have expired are listed in Skipped.
+type PresignedURLsResponse struct {
+	Documents []DocumentPresignedURLs `json:"documents"`
+	Skipped   []uuid.UUID             `json:"skipped"`
+}
+
+// Interface for content type specific preview strategies. Presigned URLs are valid for urlExpiry.
+type PreviewStrategy interface {
+	Name() string
+	CanHandle(document *models.Document) bool
+	Preview(ctx context.Context, document *models.Document, urlExpiry time.Duration) (*DocumentPreviewResponse, error)
+}
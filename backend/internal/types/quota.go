@@ -0,0 +1,19 @@
+package types
+
+// Quota Response Types
+
+// Represents usage against a single quota limit
+type QuotaUsage struct {
+	Used      int64 `json:"used"`
+	Limit     int64 `json:"limit"`
+	Remaining int64 `json:"remaining"`
+	IsOver    bool  `json:"isOver"`
+}
+
+// Represents the user's quota and plan status
+type QuotaResponse struct {
+	Plan        string     `json:"plan"`
+	Storage     QuotaUsage `json:"storage"`   // Bytes
+	Documents   QuotaUsage `json:"documents"` // Document count
+	IsOverLimit bool       `json:"isOverLimit"`
+}
@@ -0,0 +1,11 @@
+package types
+
+// Represents the request for saving the current document list filters
+type CreateSavedSearchRequest struct {
+	Name     string `json:"name" binding:"required,max=100"`
+	Query    string `json:"query"`
+	FileType string `json:"fileType"`
+	Tags     string `json:"tags"`
+	SortBy   string `json:"sortBy"`  // Defaults to relevance with a query and date without
+	SortDir  string `json:"sortDir"` // Defaults to desc
+}
@@ -10,16 +10,21 @@ import (
 
 // Represents a search request
 type SearchRequest struct {
-	UserID   uuid.UUID
-	Query    string
-	Tokens   []string
-	Page     int
-	Limit    int
-	FileType string
-	Status   string
-	Tags     string
-	SortBy   string
-	SortDir  string
+	UserID         uuid.UUID
+	Query          string
+	RawQuery       string // Search text as typed, parsed for operators by the exact strategy
+	Tokens         []string
+	Page           int
+	Limit          int
+	FileType       string
+	Status         string
+	Tags           string
+	Language       string                // Optional text search configuration, empty uses each document's own
+	CustomMetadata models.CustomMetadata // Typed custom field values documents must contain
+	FolderIDs      []uuid.UUID           // Documents must be filed in one of these folders
+	Unfiled        bool                  // Documents must not be filed in a folder
+	SortBy         string
+	SortDir        string
 }
 
 // Represents a search result
@@ -29,6 +34,23 @@ type SearchResult struct {
 	Page       int
 	Limit      int
 	TotalPages int
+	// Strategy that produced the result, "listing" without a query and "none" when no
+	// strategy found anything. Attempts lists every strategy that ran, in order.
+	Strategy string
+	Attempts []SearchStrategyAttempt
+}
+
+// One strategy's run for a query, reported by search debugging
+type SearchStrategyAttempt struct {
+	Strategy   string `json:"strategy"`
+	Candidates int64  `json:"candidates"`      // Documents it matched before pagination
+	Error      string `json:"error,omitempty"` // Why it failed, the next strategy was tried
+}
+
+// Explains how a listing was answered, included when debug=true
+type SearchDebug struct {
+	Strategy string                  `json:"strategy"`
+	Attempts []SearchStrategyAttempt `json:"attempts"`
 }
 
 // Interface for different search strategies
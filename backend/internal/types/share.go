@@ -0,0 +1,35 @@
+package types
+
+import "github.com/eyuppastirmaci/noesis-forge/internal/models"
+
+// Represents the search, filters and page of the documents shared with a user
+type SharedWithMeListRequest struct {
+	Page     int
+	Limit    int
+	Search   string // Matches the document title
+	FileType string
+	SortBy   string // shared, date, title, size
+	SortDir  string // asc, desc
+}
+
+// Represents sharing several documents with several people at once
+type BulkShareRequest struct {
+	DocumentIDs   []string `json:"documentIds"`
+	Emails        []string `json:"emails"`
+	AccessLevel   string   `json:"accessLevel"`
+	ExpiresInDays int      `json:"expiresInDays"`
+	MaxDownloads  *int     `json:"maxDownloads"` // Per share, unlimited when omitted
+	Message       string   `json:"message"`
+}
+
+// Represents minting an embed token. A zero ExpiresIn uses the configured default lifetime.
+type CreateEmbedTokenRequest struct {
+	Name      string `json:"name" binding:"max=100"`
+	ExpiresIn int    `json:"expiresIn" binding:"min=0"` // Seconds
+}
+
+// Represents a newly minted embed token, the only response that includes the token itself
+type EmbedTokenCreatedResponse struct {
+	Token      string            `json:"token"`
+	EmbedToken models.EmbedToken `json:"embedToken"`
+}
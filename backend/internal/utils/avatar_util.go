@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	AvatarStyleInitials  = "initials"
+	AvatarStyleIdenticon = "identicon"
+
+	avatarSize          = 128
+	identiconGrid       = 5
+	identiconBackground = "#F0F0F0"
+)
+
+// Background colors for initials avatars, chosen to keep white text readable
+var avatarPalette = []string{
+	"#E57373", "#F06292", "#BA68C8", "#9575CD", "#7986CB", "#64B5F6",
+	"#4FC3F7", "#4DD0E1", "#4DB6AC", "#81C784", "#AED581", "#FF8A65",
+	"#A1887F", "#90A4AE", "#F4A261", "#2A9D8F",
+}
+
+// GeneratedAvatarVersion changes whenever the rendered avatar would change, used for cache busting
+func GeneratedAvatarVersion(style, name string) string {
+	sum := sha256.Sum256([]byte(style + ":" + name))
+	return hex.EncodeToString(sum[:8])
+}
+
+// GeneratedAvatarURL returns the public URL serving the generated avatar of a user
+func GeneratedAvatarURL(baseURL, userID, style, name string) string {
+	return fmt.Sprintf("%s/api/v1/auth/users/%s/avatar?v=%s", strings.TrimRight(baseURL, "/"), userID, GeneratedAvatarVersion(style, name))
+}
+
+// NormalizeAvatarStyle falls back to initials for unknown styles
+func NormalizeAvatarStyle(style string) string {
+	if style == AvatarStyleIdenticon {
+		return AvatarStyleIdenticon
+	}
+	return AvatarStyleInitials
+}
+
+// GenerateAvatarSVG renders a deterministic SVG avatar, the same seed always yields the same image
+func GenerateAvatarSVG(style, seed, name string) []byte {
+	if style == AvatarStyleIdenticon {
+		return generateIdenticonSVG(seed)
+	}
+	return generateInitialsSVG(seed, name)
+}
+
+func generateInitialsSVG(seed, name string) []byte {
+	sum := sha256.Sum256([]byte(seed))
+	background := avatarPalette[int(sum[0])%len(avatarPalette)]
+
+	return []byte(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+
+			`<rect width="100%%" height="100%%" fill="%s"/>`+
+			`<text x="50%%" y="50%%" dy=".35em" text-anchor="middle" fill="#FFFFFF" `+
+			`font-family="Helvetica, Arial, sans-serif" font-size="52" font-weight="600">%s</text></svg>`,
+		avatarSize, avatarSize, avatarSize, avatarSize, background, html.EscapeString(Initials(name)),
+	))
+}
+
+// Mirrors the left half of a 5x5 grid, GitHub style
+func generateIdenticonSVG(seed string) []byte {
+	sum := sha256.Sum256([]byte(seed))
+	color := fmt.Sprintf("#%02X%02X%02X", sum[0], sum[1], sum[2])
+	cell := avatarSize / (identiconGrid + 1)
+	offset := (avatarSize - cell*identiconGrid) / 2
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		avatarSize, avatarSize, avatarSize, avatarSize)
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`, identiconBackground)
+
+	half := (identiconGrid + 1) / 2
+	for row := 0; row < identiconGrid; row++ {
+		for col := 0; col < half; col++ {
+			// Skip the bytes used for the color
+			if sum[3+row*half+col]%2 == 0 {
+				continue
+			}
+			y := offset + row*cell
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, offset+col*cell, y, cell, cell, color)
+			if mirror := identiconGrid - 1 - col; mirror != col {
+				fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`, offset+mirror*cell, y, cell, cell, color)
+			}
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	return []byte(b.String())
+}
+
+// Initials returns up to two uppercase initials from the first and last words of name
+func Initials(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "?"
+	}
+
+	first := []rune(words[0])[0]
+	if len(words) == 1 {
+		return strings.ToUpper(string(first))
+	}
+	last := []rune(words[len(words)-1])[0]
+	return strings.ToUpper(string(first) + string(last))
+}
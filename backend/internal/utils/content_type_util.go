@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// Number of leading bytes used for signature based sniffing
+const ContentSniffLength = 512
+
+const (
+	MIMETypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MIMETypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	MIMETypePPTX = "application/vnd.openxmlformats-officedocument.presentationml.presentation"
+	MIMETypeDOC  = "application/msword"
+	MIMETypeXLS  = "application/vnd.ms-excel"
+	MIMETypePPT  = "application/vnd.ms-powerpoint"
+	MIMETypeRTF  = "application/rtf"
+
+	// OLE compound file whose concrete Office format could not be determined
+	MIMETypeOLEStorage = "application/x-ole-storage"
+)
+
+// Part names that identify each OOXML format inside its ZIP container
+var ooxmlMainParts = map[string]string{
+	"word/document.xml":    MIMETypeDOCX,
+	"xl/workbook.xml":      MIMETypeXLSX,
+	"ppt/presentation.xml": MIMETypePPTX,
+}
+
+// Legacy Office formats share the OLE container, so the extension picks the concrete type
+var oleTypesByExtension = map[string]string{
+	".doc": MIMETypeDOC,
+	".xls": MIMETypeXLS,
+	".ppt": MIMETypePPT,
+}
+
+var oleSignature = []byte{0xD0, 0xCF, 0x11, 0xE0, 0xA1, 0xB1, 0x1A, 0xE1}
+
+// ContentSource is satisfied by multipart.File and os.File
+type ContentSource interface {
+	io.Reader
+	io.ReaderAt
+	io.Seeker
+}
+
+// DetectContentType identifies the content type of src beyond http.DetectContentType.
+// ZIP containers are opened to tell DOCX/XLSX/PPTX and OpenDocument files apart, and OLE
+// containers are mapped to legacy Office types. The leading bytes are returned for further
+// checks and src is rewound to the start before returning.
+func DetectContentType(src ContentSource, size int64, filename string) (string, []byte, error) {
+	defer src.Seek(0, io.SeekStart)
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", nil, fmt.Errorf("failed to rewind file: %w", err)
+	}
+
+	header := make([]byte, ContentSniffLength)
+	n, err := io.ReadFull(src, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", nil, fmt.Errorf("failed to read file header: %w", err)
+	}
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, oleSignature):
+		if contentType, ok := oleTypesByExtension[strings.ToLower(filepath.Ext(filename))]; ok {
+			return contentType, header, nil
+		}
+		return MIMETypeOLEStorage, header, nil
+	case bytes.HasPrefix(header, []byte(`{\rtf`)):
+		return MIMETypeRTF, header, nil
+	}
+
+	// Ignore parameters such as charset
+	contentType := strings.Split(http.DetectContentType(header), ";")[0]
+	if contentType == "application/zip" {
+		return detectZipContentType(src, size), header, nil
+	}
+
+	return contentType, header, nil
+}
+
+// Inspects the ZIP central directory for known Office and OpenDocument entries
+func detectZipContentType(src io.ReaderAt, size int64) string {
+	archive, err := zip.NewReader(src, size)
+	if err != nil {
+		return "application/zip"
+	}
+
+	hasContentTypes := false
+	ooxmlType := ""
+	for _, entry := range archive.File {
+		switch {
+		case entry.Name == "[Content_Types].xml":
+			hasContentTypes = true
+		case ooxmlMainParts[entry.Name] != "":
+			ooxmlType = ooxmlMainParts[entry.Name]
+		case entry.Name == "mimetype":
+			// OpenDocument stores its content type as the first, uncompressed entry
+			if odfType := readOpenDocumentMIMEType(entry); odfType != "" {
+				return odfType
+			}
+		}
+	}
+
+	if hasContentTypes && ooxmlType != "" {
+		return ooxmlType
+	}
+	return "application/zip"
+}
+
+func readOpenDocumentMIMEType(entry *zip.File) string {
+	if entry.UncompressedSize64 > 128 {
+		return ""
+	}
+
+	rc, err := entry.Open()
+	if err != nil {
+		return ""
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, 128))
+	if err != nil {
+		return ""
+	}
+
+	mimeType := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(mimeType, "application/vnd.oasis.opendocument.") {
+		return ""
+	}
+	return mimeType
+}
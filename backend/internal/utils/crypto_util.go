@@ -1,8 +1,13 @@
 package utils
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"unicode"
 )
 
 // GenerateEncryptionSalt generates a random salt for E2EE key derivation
@@ -13,3 +18,26 @@ func GenerateEncryptionSalt() (string, error) {
 	}
 	return base64.StdEncoding.EncodeToString(salt), nil
 }
+
+// BlindIndex returns a keyed HMAC-SHA256 of a normalized value. Equal values give equal
+// indexes, so a column of them can be searched without storing the value itself.
+func BlindIndex(key, normalized string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NormalizeEmail trims and lowercases an email address before it is indexed or compared
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizePhone keeps only the digits of a phone number so formatting does not matter
+func NormalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phone)
+}
@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Lines of unchanged text shown around each change
+	diffContextLines = 3
+	// Beyond this many line edits the changed region is shown as replaced wholesale, the
+	// search would otherwise take quadratic memory on completely rewritten documents
+	maxDiffEdits = 2000
+)
+
+type diffOpKind byte
+
+const (
+	diffEqual  diffOpKind = ' '
+	diffDelete diffOpKind = '-'
+	diffInsert diffOpKind = '+'
+)
+
+type diffOp struct {
+	kind diffOpKind
+	line string
+}
+
+// UnifiedDiff returns the line differences between two texts in unified diff format, labelling
+// the sides fromName and toName. Identical texts give an empty string.
+func UnifiedDiff(fromName, toName, from, to string) string {
+	if from == to {
+		return ""
+	}
+
+	ops := diffLines(splitLines(from), splitLines(to))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+
+	// Line numbers before the op at the same index, on each side
+	fromLine := make([]int, len(ops)+1)
+	toLine := make([]int, len(ops)+1)
+	for i, op := range ops {
+		fromLine[i+1], toLine[i+1] = fromLine[i], toLine[i]
+		if op.kind != diffInsert {
+			fromLine[i+1]++
+		}
+		if op.kind != diffDelete {
+			toLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == diffEqual {
+			i++
+			continue
+		}
+
+		// Grow the hunk while changes are close enough for their context to touch
+		start := max(i-diffContextLines, 0)
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != diffEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == diffEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContextLines {
+				end = min(end+diffContextLines, len(ops))
+				break
+			}
+			end = run
+		}
+
+		fromCount := fromLine[end] - fromLine[start]
+		toCount := toLine[end] - toLine[start]
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(fromLine[start], fromCount), hunkRange(toLine[start], toCount))
+		for _, op := range ops[start:end] {
+			sb.WriteByte(byte(op.kind))
+			sb.WriteString(op.line)
+			sb.WriteByte('\n')
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+// Formats the start and length of a hunk side, empty sides point at the line before them
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+}
+
+// Finds the shortest edit script between two line slices with Myers' algorithm
+func diffLines(a, b []string) []diffOp {
+	// Common lines at both ends never need searching
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, diffOp{diffEqual, line})
+	}
+	ops = append(ops, myersDiff(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{diffEqual, line})
+	}
+	return ops
+}
+
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	limit := min(n+m, maxDiffEdits)
+	offset := limit + 1
+	v := make([]int, 2*limit+3)
+
+	// trace[d] holds the furthest x on diagonals -d..d before round d
+	var trace [][]int
+	found := false
+	for d := 0; d <= limit && !found; d++ {
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+	}
+
+	if !found {
+		ops := make([]diffOp, 0, n+m)
+		for _, line := range a {
+			ops = append(ops, diffOp{diffDelete, line})
+		}
+		for _, line := range b {
+			ops = append(ops, diffOp{diffInsert, line})
+		}
+		return ops
+	}
+
+	// Walk back from the end, collecting ops in reverse
+	var reversed []diffOp
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		at := func(k int) int { return trace[d][k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffOp{diffEqual, a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			reversed = append(reversed, diffOp{diffInsert, b[y-1]})
+		} else {
+			reversed = append(reversed, diffOp{diffDelete, a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, diffOp{diffEqual, a[x-1]})
+		x--
+		y--
+	}
+
+	ops := make([]diffOp, len(reversed))
+	for i, op := range reversed {
+		ops[len(reversed)-1-i] = op
+	}
+	return ops
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Longest title a document can have, in bytes
+const MaxTitleLength = 255
+
+// TitleFromFilename derives a document title from an uploaded file's name. Path components
+// from either separator style and the extension are dropped, control characters removed,
+// runs of whitespace and repeated separators collapsed and the result cut to MaxTitleLength
+// bytes without splitting a character. Returns "" when nothing usable is left.
+func TitleFromFilename(filename string) string {
+	// Browsers on Windows may send the full client path
+	name := path.Base(strings.ReplaceAll(filename, "\\", "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+
+	// A leading dot marks a hidden file rather than an extension
+	if ext := path.Ext(name); ext != name {
+		name = strings.TrimSuffix(name, ext)
+	}
+
+	var builder strings.Builder
+	var previous rune
+	for _, r := range name {
+		switch {
+		case unicode.IsSpace(r):
+			r = ' '
+		case unicode.IsControl(r) || r == utf8.RuneError:
+			continue
+		}
+		if r == previous && isTitleSeparator(r) {
+			continue
+		}
+		builder.WriteRune(r)
+		previous = r
+	}
+
+	title := strings.TrimFunc(builder.String(), isTitleSeparator)
+	// Trimmed again so the cut can't leave a trailing separator
+	return strings.TrimRightFunc(TruncateUTF8(title, MaxTitleLength), isTitleSeparator)
+}
+
+func isTitleSeparator(r rune) bool {
+	return r == ' ' || r == '_' || r == '-' || r == '.'
+}
+
+// TruncateUTF8 cuts s to at most max bytes on a character boundary
+func TruncateUTF8(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	s = s[:max]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
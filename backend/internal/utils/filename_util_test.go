@@ -0,0 +1,101 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTitleFromFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"plain", "report.pdf", "report"},
+		{"no extension", "README", "README"},
+		{"multiple dots", "report.final.v2.pdf", "report.final.v2"},
+		{"double extension", "archive.tar.gz", "archive.tar"},
+		{"trailing dot", "report.", "report"},
+		{"hidden file", ".env", "env"},
+		{"hidden file with extension", ".config.json", "config"},
+		{"unix path", "/home/jane/docs/report.pdf", "report"},
+		{"windows path", `C:\Users\Jane\report.pdf`, "report"},
+		{"unicode", "Ärger über Café.docx", "Ärger über Café"},
+		{"cjk", "年度報告.pdf", "年度報告"},
+		{"emoji", "plans 🚀.md", "plans 🚀"},
+		{"control characters", "re\x00po\x1brt.txt", "report"},
+		{"invalid utf8", "re\xffport.txt", "report"},
+		{"whitespace collapsed", "my\t\tquarterly   report.txt", "my quarterly report"},
+		{"separators collapsed", "my__report--final.txt", "my_report-final"},
+		{"separators trimmed", "__report__.txt", "report"},
+		{"only extension", ".pdf", "pdf"},
+		{"only separators", "___.txt", ""},
+		{"empty", "", ""},
+		{"directory", "docs/", "docs"},
+		{"root", "/", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TitleFromFilename(tt.filename); got != tt.want {
+				t.Fatalf("TitleFromFilename(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTitleFromFilenameTruncates(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"ascii", strings.Repeat("a", 300) + ".pdf", strings.Repeat("a", MaxTitleLength)},
+		{"exact length", strings.Repeat("a", MaxTitleLength) + ".pdf", strings.Repeat("a", MaxTitleLength)},
+		// 127 two byte characters fill 254 bytes, the 128th would end past the limit
+		{"two byte boundary", strings.Repeat("é", 200) + ".pdf", strings.Repeat("é", 127)},
+		// 85 three byte characters fill 255 bytes exactly
+		{"three byte boundary", strings.Repeat("語", 100) + ".pdf", strings.Repeat("語", 85)},
+		// One byte offset so the limit falls inside a four byte character
+		{"four byte boundary", "a" + strings.Repeat("🚀", 70) + ".pdf", "a" + strings.Repeat("🚀", 63)},
+		{"separator at cut", strings.Repeat("a", 254) + " b.pdf", strings.Repeat("a", 254)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TitleFromFilename(tt.filename)
+			if got != tt.want {
+				t.Fatalf("TitleFromFilename = %q (%d bytes), want %q (%d bytes)", got, len(got), tt.want, len(tt.want))
+			}
+			if len(got) > MaxTitleLength || !utf8.ValidString(got) {
+				t.Fatalf("title of %d bytes is too long or not valid UTF-8", len(got))
+			}
+		})
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel"},
+		{"hello", 0, ""},
+		{"héllo", 2, "h"},
+		{"héllo", 3, "hé"},
+		{"語語", 4, "語"},
+		{"語語", 5, "語"},
+		{"🚀🚀", 7, "🚀"},
+		{"🚀", 3, ""},
+	}
+
+	for _, tt := range tests {
+		if got := TruncateUTF8(tt.s, tt.max); got != tt.want {
+			t.Errorf("TruncateUTF8(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+}
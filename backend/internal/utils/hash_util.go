@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 
 	"golang.org/x/crypto/bcrypt"
@@ -30,3 +31,9 @@ func GenerateSecureToken(length int) string {
 	}
 	return hex.EncodeToString(bytes)
 }
+
+// HashToken returns the SHA-256 of a token for storage, tokens are long random strings so no salt is needed
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type loggerContextKey struct{}
+
+// ContextWithLogger returns a copy of ctx carrying the request scoped log entry
+func ContextWithLogger(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerContextKey{}, entry)
+}
+
+// LoggerFromContext returns the log entry stored in ctx, or the standard logger when there
+// is none, so code running outside a request can use it too
+func LoggerFromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if entry, ok := ctx.Value(loggerContextKey{}).(*logrus.Entry); ok {
+			return entry
+		}
+	}
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// RequestLogger returns the log entry of the request, tagged with the request ID and,
+// once authentication has run, the user ID
+func RequestLogger(c *gin.Context) *logrus.Entry {
+	entry := LoggerFromContext(c.Request.Context())
+	if userID, exists := c.Get("userID"); exists {
+		entry = entry.WithField("user_id", userID)
+	}
+	return entry
+}
@@ -0,0 +1,588 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The renderer below covers the common subset of Markdown: headings, paragraphs, emphasis,
+// code, links, block quotes, lists, tables and rules. Every piece of source text is HTML
+// escaped and only the tags written here are emitted, so raw HTML in the source shows up as
+// text and the output is safe to insert into a page.
+
+const (
+	// Block quotes and lists nested deeper are rendered as plain paragraphs
+	maxMarkdownDepth = 16
+	// How far ahead the closing delimiter of an inline span is looked for, keeps unmatched
+	// delimiters in long paragraphs from making rendering quadratic
+	maxInlineSpan = 4096
+)
+
+var (
+	markdownHeadingRegex   = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	markdownRuleRegex      = regexp.MustCompile(`^ {0,3}(?:(?:\*[ \t]*){3,}|(?:-[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	markdownFenceRegex     = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*([^`\\s]*)")
+	markdownListItemRegex  = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])(?:[ \t]+(.*))?$`)
+	markdownTableSepRegex  = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	markdownCodeLangRegex  = regexp.MustCompile(`^[a-zA-Z0-9_+#.-]{1,32}$`)
+	markdownAutolinkRegex  = regexp.MustCompile(`^<((?:https?://|mailto:)[^<>\s]+)>`)
+	markdownURLSchemeRegex = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*):`)
+)
+
+// RenderMarkdown converts Markdown source into sanitized HTML
+func RenderMarkdown(source string) string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+
+	var b strings.Builder
+	renderMarkdownBlocks(&b, strings.Split(source, "\n"), 0)
+	return b.String()
+}
+
+// RenderPlainText wraps text in a preformatted block, escaped
+func RenderPlainText(text string) string {
+	var b strings.Builder
+	b.WriteString("<pre>")
+	writeEscaped(&b, text)
+	b.WriteString("</pre>")
+	return b.String()
+}
+
+func renderMarkdownBlocks(b *strings.Builder, lines []string, depth int) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			i++
+			continue
+		}
+
+		if depth < maxMarkdownDepth {
+			if fence := markdownFenceRegex.FindStringSubmatch(line); fence != nil {
+				i = renderMarkdownFence(b, lines, i, fence[1], fence[2])
+				continue
+			}
+			if quoted, next := collectMarkdownQuote(lines, i); next > i {
+				b.WriteString("<blockquote>\n")
+				renderMarkdownBlocks(b, quoted, depth+1)
+				b.WriteString("</blockquote>\n")
+				i = next
+				continue
+			}
+			if item := markdownListItemRegex.FindStringSubmatch(line); item != nil && !markdownRuleRegex.MatchString(line) {
+				i = renderMarkdownList(b, lines, i, depth)
+				continue
+			}
+		}
+
+		if heading := markdownHeadingRegex.FindStringSubmatch(line); heading != nil {
+			level := strconv.Itoa(len(heading[1]))
+			b.WriteString("<h" + level + ">")
+			renderMarkdownInline(b, heading[2])
+			b.WriteString("</h" + level + ">\n")
+			i++
+			continue
+		}
+		if markdownRuleRegex.MatchString(line) {
+			b.WriteString("<hr>\n")
+			i++
+			continue
+		}
+		if i+1 < len(lines) && strings.Contains(line, "|") && strings.Contains(lines[i+1], "|") && markdownTableSepRegex.MatchString(lines[i+1]) {
+			i = renderMarkdownTable(b, lines, i)
+			continue
+		}
+
+		i = renderMarkdownParagraph(b, lines, i, depth)
+	}
+}
+
+// Renders a fenced code block starting at lines[start], returns the line after it. An
+// unclosed fence runs to the end of the document.
+func renderMarkdownFence(b *strings.Builder, lines []string, start int, fence, lang string) int {
+	b.WriteString("<pre><code")
+	if markdownCodeLangRegex.MatchString(lang) {
+		b.WriteString(` class="language-`)
+		writeEscaped(b, lang)
+		b.WriteString(`"`)
+	}
+	b.WriteString(">")
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		writeEscaped(b, lines[i])
+		b.WriteString("\n")
+	}
+
+	b.WriteString("</code></pre>\n")
+	return i
+}
+
+// Returns the lines of the block quote starting at lines[start] without their markers, and
+// the line after it. next equals start when the line does not open a block quote.
+func collectMarkdownQuote(lines []string, start int) ([]string, int) {
+	var quoted []string
+	i := start
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if !strings.HasPrefix(trimmed, ">") || len(lines[i])-len(trimmed) > 3 {
+			break
+		}
+		trimmed = strings.TrimPrefix(trimmed, ">")
+		quoted = append(quoted, strings.TrimPrefix(trimmed, " "))
+	}
+	return quoted, i
+}
+
+// Renders the list starting at lines[start], returns the line after it. Items continue on
+// lines indented past the marker, which may hold nested blocks.
+func renderMarkdownList(b *strings.Builder, lines []string, start, depth int) int {
+	first := markdownListItemRegex.FindStringSubmatch(lines[start])
+	ordered := first[2][0] >= '0' && first[2][0] <= '9'
+
+	if ordered {
+		number, _ := strconv.Atoi(first[2][:len(first[2])-1])
+		if number != 1 {
+			b.WriteString(`<ol start="` + strconv.Itoa(number) + `">` + "\n")
+		} else {
+			b.WriteString("<ol>\n")
+		}
+	} else {
+		b.WriteString("<ul>\n")
+	}
+
+	i := start
+	for i < len(lines) {
+		item := markdownListItemRegex.FindStringSubmatch(lines[i])
+		if item == nil || markdownRuleRegex.MatchString(lines[i]) {
+			break
+		}
+		isOrdered := item[2][0] >= '0' && item[2][0] <= '9'
+		if isOrdered != ordered {
+			break
+		}
+
+		// Continuation lines, a blank line ends the item unless indented content follows
+		indent := len(item[1]) + len(item[2]) + 1
+		var body []string
+		i++
+		for i < len(lines) {
+			line := lines[i]
+			if strings.TrimSpace(line) == "" {
+				if i+1 < len(lines) && leadingSpaces(lines[i+1]) >= indent && strings.TrimSpace(lines[i+1]) != "" {
+					body = append(body, "")
+					i++
+					continue
+				}
+				break
+			}
+			// Unindented lines continue the item's text unless they start a block of their own
+			if leadingSpaces(line) < indent && (len(body) > 0 || startsMarkdownBlock(lines, i, depth)) {
+				break
+			}
+			body = append(body, line[min(leadingSpaces(line), indent):])
+			i++
+		}
+
+		b.WriteString("<li>")
+		renderMarkdownInline(b, item[3])
+		if len(body) > 0 {
+			b.WriteString("\n")
+			renderMarkdownBlocks(b, body, depth+1)
+		}
+		b.WriteString("</li>\n")
+
+		// A blank line between items keeps the list going
+		if i+1 < len(lines) && strings.TrimSpace(lines[i]) == "" && markdownListItemRegex.MatchString(lines[i+1]) {
+			i++
+		}
+	}
+
+	if ordered {
+		b.WriteString("</ol>\n")
+	} else {
+		b.WriteString("</ul>\n")
+	}
+	return i
+}
+
+// Renders the table whose header is lines[start], returns the line after it
+func renderMarkdownTable(b *strings.Builder, lines []string, start int) int {
+	header := splitMarkdownTableRow(lines[start])
+	separators := splitMarkdownTableRow(lines[start+1])
+
+	aligns := make([]string, len(header))
+	for col := range aligns {
+		if col >= len(separators) {
+			break
+		}
+		sep := strings.TrimSpace(separators[col])
+		switch {
+		case strings.HasPrefix(sep, ":") && strings.HasSuffix(sep, ":"):
+			aligns[col] = "center"
+		case strings.HasSuffix(sep, ":"):
+			aligns[col] = "right"
+		case strings.HasPrefix(sep, ":"):
+			aligns[col] = "left"
+		}
+	}
+
+	writeRow := func(cells []string, tag string) {
+		b.WriteString("<tr>")
+		for col := range header {
+			b.WriteString("<" + tag)
+			if aligns[col] != "" {
+				b.WriteString(` style="text-align:` + aligns[col] + `"`)
+			}
+			b.WriteString(">")
+			if col < len(cells) {
+				renderMarkdownInline(b, strings.TrimSpace(cells[col]))
+			}
+			b.WriteString("</" + tag + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	writeRow(header, "th")
+	b.WriteString("</thead>\n<tbody>\n")
+
+	i := start + 2
+	for ; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || !strings.Contains(lines[i], "|") {
+			break
+		}
+		writeRow(splitMarkdownTableRow(lines[i]), "td")
+	}
+
+	b.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+func splitMarkdownTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, cell.String())
+}
+
+// Renders the paragraph starting at lines[start], returns the line after it. Lines ending in
+// two spaces or a backslash break the line.
+func renderMarkdownParagraph(b *strings.Builder, lines []string, start, depth int) int {
+	b.WriteString("<p>")
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if i > start && startsMarkdownBlock(lines, i, depth) {
+			break
+		}
+
+		hardBreak := strings.HasSuffix(line, "  ") || strings.HasSuffix(line, `\`)
+		line = strings.TrimSpace(line)
+		if hardBreak {
+			line = strings.TrimSuffix(line, `\`)
+		}
+
+		if i > start {
+			b.WriteString("\n")
+		}
+		renderMarkdownInline(b, line)
+		if hardBreak && i+1 < len(lines) && !startsMarkdownBlock(lines, i+1, depth) {
+			b.WriteString("<br>")
+		}
+	}
+	b.WriteString("</p>\n")
+	return i
+}
+
+// Reports whether lines[i] ends a paragraph
+func startsMarkdownBlock(lines []string, i, depth int) bool {
+	line := lines[i]
+	if strings.TrimSpace(line) == "" {
+		return true
+	}
+	if markdownHeadingRegex.MatchString(line) || markdownRuleRegex.MatchString(line) {
+		return true
+	}
+	if depth >= maxMarkdownDepth {
+		return false
+	}
+	if markdownFenceRegex.MatchString(line) || strings.HasPrefix(strings.TrimLeft(line, " "), ">") {
+		return true
+	}
+	return markdownListItemRegex.MatchString(line)
+}
+
+// Renders emphasis, code spans, links and line content of a single block
+func renderMarkdownInline(b *strings.Builder, text string) {
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("\\`*_{}[]()#+-.!|~<>\"'", text[i+1]) >= 0:
+			writeEscaped(b, text[i+1:i+2])
+			i += 2
+
+		case c == '`':
+			run := 1
+			for i+run < len(text) && text[i+run] == '`' {
+				run++
+			}
+			end := indexWithin(text[i+run:], strings.Repeat("`", run))
+			if end < 0 {
+				b.WriteString(text[i : i+run])
+				i += run
+				continue
+			}
+			b.WriteString("<code>")
+			writeEscaped(b, strings.TrimSpace(text[i+run:i+run+end]))
+			b.WriteString("</code>")
+			i += run + end + run
+
+		case c == '!' && i+1 < len(text) && text[i+1] == '[':
+			// Images are linked rather than embedded, so previews never load remote content
+			label, target, n, ok := parseMarkdownLink(text[i+1:])
+			if !ok {
+				b.WriteString("!")
+				i++
+				continue
+			}
+			if label == "" {
+				label = target
+			}
+			writeMarkdownLink(b, label, target)
+			i += 1 + n
+
+		case c == '[':
+			label, target, n, ok := parseMarkdownLink(text[i:])
+			if !ok {
+				b.WriteString("[")
+				i++
+				continue
+			}
+			writeMarkdownLink(b, label, target)
+			i += n
+
+		case c == '<':
+			if match := markdownAutolinkRegex.FindStringSubmatch(text[i:]); match != nil {
+				writeMarkdownLink(b, match[1], match[1])
+				i += len(match[0])
+				continue
+			}
+			b.WriteString("&lt;")
+			i++
+
+		case (c == '*' || c == '_') && i+1 < len(text) && text[i+1] == c:
+			if n, ok := renderMarkdownSpan(b, text[i:], text[i:i+2], "strong"); ok {
+				i += n
+				continue
+			}
+			b.WriteString(text[i : i+2])
+			i += 2
+
+		case c == '~' && i+1 < len(text) && text[i+1] == '~':
+			if n, ok := renderMarkdownSpan(b, text[i:], "~~", "del"); ok {
+				i += n
+				continue
+			}
+			b.WriteString("~~")
+			i += 2
+
+		case c == '*' || (c == '_' && (i == 0 || !isWordByte(text[i-1]))):
+			if n, ok := renderMarkdownSpan(b, text[i:], text[i:i+1], "em"); ok {
+				i += n
+				continue
+			}
+			b.WriteByte(c)
+			i++
+
+		default:
+			writeEscaped(b, text[i:i+1])
+			i++
+		}
+	}
+}
+
+// Renders text wrapped in delim as tag when the closing delimiter is found. The content must
+// not start or end with a space. Returns how much of text was consumed.
+func renderMarkdownSpan(b *strings.Builder, text, delim, tag string) (int, bool) {
+	rest := text[len(delim):]
+	if rest == "" || rest[0] == ' ' {
+		return 0, false
+	}
+
+	window := rest
+	if len(window) > maxInlineSpan {
+		window = window[:maxInlineSpan]
+	}
+
+	offset := 0
+	for {
+		end := strings.Index(window[offset:], delim)
+		if end < 0 {
+			return 0, false
+		}
+		end += offset
+		closing := end + len(delim)
+		// Closing underscores must not be inside a word, a longer run belongs to an outer span
+		valid := end > 0 && rest[end-1] != ' '
+		if delim[0] == '_' && closing < len(rest) && isWordByte(rest[closing]) {
+			valid = false
+		}
+		if len(delim) == 1 && closing < len(rest) && rest[closing] == delim[0] {
+			valid = false
+		}
+		if valid {
+			b.WriteString("<" + tag + ">")
+			renderMarkdownInline(b, rest[:end])
+			b.WriteString("</" + tag + ">")
+			return len(delim) + closing, true
+		}
+		offset = end + 1
+	}
+}
+
+// Parses [label](target "title") at the start of text, returns the label, the target and the
+// length of the link
+func parseMarkdownLink(text string) (string, string, int, bool) {
+	closeLabel := -1
+	nesting := 0
+	for i := 1; i < len(text) && i < maxInlineSpan; i++ {
+		if text[i] == '\\' {
+			i++
+			continue
+		}
+		if text[i] == '[' {
+			nesting++
+		} else if text[i] == ']' {
+			if nesting == 0 {
+				closeLabel = i
+				break
+			}
+			nesting--
+		}
+	}
+	if closeLabel < 0 || closeLabel+1 >= len(text) || text[closeLabel+1] != '(' {
+		return "", "", 0, false
+	}
+
+	// Parentheses in the target are balanced, as in URLs like /wiki/Go_(language)
+	closeTarget := -1
+	nesting = 0
+	for i, rest := 0, text[closeLabel+2:]; i < len(rest) && i < maxInlineSpan; i++ {
+		if rest[i] == '(' {
+			nesting++
+		} else if rest[i] == ')' {
+			if nesting == 0 {
+				closeTarget = i
+				break
+			}
+			nesting--
+		}
+	}
+	if closeTarget < 0 {
+		return "", "", 0, false
+	}
+	target := strings.TrimSpace(text[closeLabel+2 : closeLabel+2+closeTarget])
+	// Drop an optional title
+	if space := strings.IndexAny(target, " \t"); space >= 0 {
+		target = target[:space]
+	}
+	target = strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+
+	return text[1:closeLabel], target, closeLabel + 2 + closeTarget + 1, true
+}
+
+// Writes a link opening in a new tab, or only its label when the target could run script
+func writeMarkdownLink(b *strings.Builder, label, target string) {
+	if !isSafeMarkdownURL(target) {
+		renderMarkdownInline(b, label)
+		return
+	}
+	b.WriteString(`<a href="`)
+	writeEscaped(b, target)
+	b.WriteString(`" rel="nofollow noopener noreferrer" target="_blank">`)
+	renderMarkdownInline(b, label)
+	b.WriteString("</a>")
+}
+
+// Allows web and mail links and relative ones, anything else such as javascript: is dropped
+func isSafeMarkdownURL(target string) bool {
+	if target == "" {
+		return false
+	}
+	// Browsers drop control characters and spaces while parsing a URL, which can reveal a
+	// scheme the check below doesn't see, so targets with any of them aren't linked at all
+	for i := 0; i < len(target); i++ {
+		if target[i] <= 0x20 || target[i] == 0x7f {
+			return false
+		}
+	}
+	match := markdownURLSchemeRegex.FindStringSubmatch(target)
+	if match == nil {
+		return true
+	}
+	switch strings.ToLower(match[1]) {
+	case "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
+
+// Index of sub in text within the inline span limit, -1 when not found
+func indexWithin(text, sub string) int {
+	if len(text) > maxInlineSpan {
+		text = text[:maxInlineSpan]
+	}
+	return strings.Index(text, sub)
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c >= 0x80
+}
+
+// Writes text with the HTML special characters escaped
+func writeEscaped(b *strings.Builder, text string) {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '&':
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		case '>':
+			b.WriteString("&gt;")
+		case '"':
+			b.WriteString("&#34;")
+		case '\'':
+			b.WriteString("&#39;")
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+}
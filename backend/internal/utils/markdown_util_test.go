@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdownLinks(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string // substring of the output, empty when nothing must be linked
+	}{
+		{"https", "[x](https://example.com/a)", `<a href="https://example.com/a"`},
+		{"http", "[x](http://example.com)", `<a href="http://example.com"`},
+		{"mailto", "[x](mailto:a@example.com)", `<a href="mailto:a@example.com"`},
+		{"relative", "[x](/documents/1)", `<a href="/documents/1"`},
+		{"fragment", "[x](#section)", `<a href="#section"`},
+		{"autolink", "<https://example.com>", `<a href="https://example.com"`},
+		{"title dropped", `[x](https://example.com "Example")`, `<a href="https://example.com"`},
+		{"javascript", "[x](javascript:alert(1))", ""},
+		{"mixed case", "[x](JaVaScRiPt:alert(1))", ""},
+		{"vbscript", "[x](vbscript:msgbox(1))", ""},
+		{"data", "[x](data:text/html;base64,PHNjcmlwdD4=)", ""},
+		{"image", "![x](javascript:alert(1))", ""},
+		{"leading control byte", "[x](\x01javascript:alert(1))", ""},
+		{"leading escape", "[x](\x1bjavascript:alert(1))", ""},
+		{"embedded vertical tab", "[x](java\vscript:alert(1))", ""},
+		{"embedded null", "[x](java\x00script:alert(1))", ""},
+		{"embedded delete", "[x](java\x7fscript:alert(1))", ""},
+		{"angle brackets", "[x](<javascript:alert(1)>)", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderMarkdown(tt.source)
+			if tt.want == "" {
+				if strings.Contains(got, "<a ") {
+					t.Fatalf("RenderMarkdown(%q) = %q, want no link", tt.source, got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Fatalf("RenderMarkdown(%q) = %q, want it to contain %q", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownEntityTricks(t *testing.T) {
+	// Entities in the target are escaped, the browser sees a relative URL and not a scheme
+	for _, source := range []string{
+		"[x](javascript&#58;alert(1))",
+		"[x](javascript&colon;alert(1))",
+		"[x](&#106;avascript:alert(1))",
+	} {
+		got := RenderMarkdown(source)
+		if strings.Contains(got, "<a ") && !strings.Contains(got, "&amp;") {
+			t.Errorf("RenderMarkdown(%q) = %q, entity left unescaped", source, got)
+		}
+	}
+}
+
+func TestRenderMarkdownEscapesHTML(t *testing.T) {
+	tests := []string{
+		"<script>alert(1)</script>",
+		`<img src=x onerror="alert(1)">`,
+		"# <b>heading</b>",
+		"- <iframe src=//evil>",
+		"| <a> | b |\n|---|---|\n| <svg onload=alert(1)> | c |",
+		"```\n<script>alert(1)</script>\n```",
+		`[<img src=x onerror=alert(1)>](https://example.com)`,
+		`[x](https://example.com/"onmouseover="alert(1))`,
+	}
+
+	for _, source := range tests {
+		got := RenderMarkdown(source)
+		for _, tag := range []string{"<script", "<img", "<iframe", "<svg", "<b>", `"onmouseover`} {
+			if strings.Contains(got, tag) {
+				t.Errorf("RenderMarkdown(%q) = %q, contains %s", source, got, tag)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import "regexp"
+
+// @username preceded by the start of the text or a character that can't be part of a
+// username or email address, so "me@example.com" is not a mention
+var mentionRegex = regexp.MustCompile(`(?:^|[^a-zA-Z0-9_@.-])@([a-zA-Z0-9_-]{3,50})`)
+
+// ExtractMentions returns the usernames mentioned in text, in order of first appearance
+func ExtractMentions(text string) []string {
+	matches := mentionRegex.FindAllStringSubmatch(text, -1)
+
+	seen := make(map[string]bool, len(matches))
+	usernames := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		usernames = append(usernames, match[1])
+	}
+	return usernames
+}
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+
+// ParseByteRange parses a Range header against a resource of size bytes and returns the first
+// and last byte offsets of the range, both inclusive. ok is false when the header should be
+// ignored and the whole resource served: it is empty, malformed, not in bytes or lists several
+// ranges. ErrRangeNotSatisfiable is returned for a range that starts past the end.
+func ParseByteRange(header string, size int64) (start, end int64, ok bool, err error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, 0, false, nil
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, nil
+	}
+
+	if first == "" {
+		// Suffix range, the last n bytes
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, false, nil
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, false, ErrRangeNotSatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, true, nil
+	}
+
+	start, err = strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, nil
+	}
+
+	end = size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, nil
+		}
+		if end > size-1 {
+			end = size - 1
+		}
+	}
+
+	if start >= size {
+		return 0, 0, false, ErrRangeNotSatisfiable
+	}
+	return start, end, true, nil
+}
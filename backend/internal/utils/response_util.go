@@ -127,6 +127,20 @@ func ConflictResponse(c *gin.Context, code string, message string) {
 	ErrorResponse(c, http.StatusConflict, code, message)
 }
 
+// ErrorResponseWithData sends an error response with a payload the client can act on
+func ErrorResponseWithData(c *gin.Context, statusCode int, code string, message string, data interface{}) {
+	c.JSON(statusCode, ApiResponse{
+		Success:    false,
+		StatusCode: statusCode,
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Data:       data,
+		Error: &ApiError{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
 // InternalServerErrorResponse sends an internal server error response
 func InternalServerErrorResponse(c *gin.Context, message string, details ...string) {
 	ErrorResponse(c, http.StatusInternalServerError, "INTERNAL_ERROR", message, details...)
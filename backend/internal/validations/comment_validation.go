@@ -3,7 +3,6 @@ package validations
 import (
 	"fmt"
 	"net/http"
-	"regexp"
 	"strings"
 	"time"
 
@@ -20,6 +19,8 @@ const (
 	ValidatedCommentUpdateKey = "validatedCommentUpdate"
 	ValidatedCommentIDKey     = "validatedCommentID"
 	ValidatedCommentListKey   = "validatedCommentList"
+	ValidatedCommentBulkKey   = "validatedCommentBulk"
+	ValidatedReactionKey      = "validatedReaction"
 )
 
 // Comment validation constants
@@ -29,13 +30,10 @@ const (
 	CommentRateLimit         = 10 // comments per minute
 	CommentUpdateRateLimit   = 30 // updates per minute (more lenient)
 	CommentSpamCheckInterval = 60 // seconds
+	CommentBulkMaxItems      = 100
+	CommentMaxMentions       = 10 // distinct @username mentions per comment
 )
 
-// Profanity filter - basic word list (can be extended)
-var profanityWords = []string{
-	"spam", "fake", "scam", // Add more words as needed
-}
-
 // Comment request structures
 type CreateCommentRequest struct {
 	Content         string                  `json:"content" binding:"required"`
@@ -54,8 +52,16 @@ type CommentListRequest struct {
 	Resolved *bool `json:"resolved,omitempty"`
 }
 
+type BulkCommentRequest struct {
+	CommentIDs []string `json:"commentIds" binding:"required"`
+}
+
+type CommentReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required"`
+}
+
 // ValidateCommentCreate validates comment creation requests
-func ValidateCommentCreate() gin.HandlerFunc {
+func ValidateCommentCreate(moderator ContentModerator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req CreateCommentRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -67,12 +73,17 @@ func ValidateCommentCreate() gin.HandlerFunc {
 		fieldErrors := make(map[string]string)
 
 		// Validate content
-		if contentErrors := validateCommentContent(req.Content); len(contentErrors) > 0 {
+		if contentErrors := validateCommentContent(req.Content, moderator); len(contentErrors) > 0 {
 			for field, message := range contentErrors {
 				fieldErrors[field] = message
 			}
 		}
 
+		// Every mention can notify someone, keep a single comment from paging everybody
+		if _, exists := fieldErrors["content"]; !exists && len(utils.ExtractMentions(req.Content)) > CommentMaxMentions {
+			fieldErrors["content"] = fmt.Sprintf("Comment must not mention more than %d users", CommentMaxMentions)
+		}
+
 		// Validate comment type
 		if !isValidCommentType(req.CommentType) {
 			fieldErrors["commentType"] = "Invalid comment type"
@@ -118,7 +129,7 @@ func ValidateCommentCreate() gin.HandlerFunc {
 }
 
 // ValidateCommentUpdate validates comment update requests
-func ValidateCommentUpdate() gin.HandlerFunc {
+func ValidateCommentUpdate(moderator ContentModerator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req UpdateCommentRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -130,7 +141,7 @@ func ValidateCommentUpdate() gin.HandlerFunc {
 		fieldErrors := make(map[string]string)
 
 		// Validate content
-		if contentErrors := validateCommentContent(req.Content); len(contentErrors) > 0 {
+		if contentErrors := validateCommentContent(req.Content, moderator); len(contentErrors) > 0 {
 			for field, message := range contentErrors {
 				fieldErrors[field] = message
 			}
@@ -212,10 +223,68 @@ func ValidateCommentID() gin.HandlerFunc {
 	}
 }
 
+// ValidateBulkCommentRequest validates bulk resolve/unresolve requests
+func ValidateBulkCommentRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req BulkCommentRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fieldErrors := map[string]string{
+				"commentIds": "Invalid comment IDs provided",
+			}
+			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		if len(req.CommentIDs) == 0 {
+			fieldErrors := map[string]string{
+				"commentIds": "At least one comment ID is required",
+			}
+			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		if len(req.CommentIDs) > CommentBulkMaxItems {
+			fieldErrors := map[string]string{
+				"commentIds": fmt.Sprintf("Maximum %d comments can be processed at once", CommentBulkMaxItems),
+			}
+			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		// Validate each comment ID format and drop duplicates
+		seen := make(map[string]bool, len(req.CommentIDs))
+		commentIDs := make([]string, 0, len(req.CommentIDs))
+		for i, id := range req.CommentIDs {
+			parsed, err := uuid.Parse(id)
+			if err != nil {
+				fieldErrors := map[string]string{
+					fmt.Sprintf("commentIds[%d]", i): "Invalid comment ID format",
+				}
+				utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+				c.Abort()
+				return
+			}
+			if seen[parsed.String()] {
+				continue
+			}
+			seen[parsed.String()] = true
+			commentIDs = append(commentIDs, parsed.String())
+		}
+		req.CommentIDs = commentIDs
+
+		// Store validated request in context
+		c.Set(ValidatedCommentBulkKey, &req)
+		c.Next()
+	}
+}
+
 // Helper functions
 
 // validateCommentContent validates comment content
-func validateCommentContent(content string) map[string]string {
+func validateCommentContent(content string, moderator ContentModerator) map[string]string {
 	errors := make(map[string]string)
 
 	// Trim whitespace
@@ -232,21 +301,9 @@ func validateCommentContent(content string) map[string]string {
 		return errors
 	}
 
-	// Check for spam patterns
-	if isSpamContent(content) {
-		errors["content"] = "Comment contains inappropriate content"
-		return errors
-	}
-
-	// Check for profanity
-	if containsProfanity(content) {
-		errors["content"] = "Comment contains inappropriate language"
-		return errors
-	}
-
-	// Check for excessive repetition
-	if hasExcessiveRepetition(content) {
-		errors["content"] = "Comment contains excessive repetition"
+	// Spam, language and link rules of the deployment
+	if err := moderator.Check(content); err != nil {
+		errors["content"] = err.Error()
 		return errors
 	}
 
@@ -319,50 +376,7 @@ func isValidCommentType(commentType models.CommentType) bool {
 	return false
 }
 
-// Spam detection functions
-func isSpamContent(content string) bool {
-	content = strings.ToLower(content)
-
-	// Check for repeated characters
-	if hasRepeatedCharacters(content, 10) {
-		return true
-	}
-
-	// Check for URLs (basic check)
-	if hasURLs(content) {
-		return true
-	}
-
-	// Check for email addresses
-	if hasEmailAddresses(content) {
-		return true
-	}
-
-	// Check for promotional keywords
-	promotionalKeywords := []string{
-		"buy now", "click here", "free", "winner", "congratulations",
-		"offer", "discount", "sale", "limited time", "act now",
-	}
-
-	for _, keyword := range promotionalKeywords {
-		if strings.Contains(content, keyword) {
-			return true
-		}
-	}
-
-	return false
-}
-
-func containsProfanity(content string) bool {
-	content = strings.ToLower(content)
-	for _, word := range profanityWords {
-		if strings.Contains(content, word) {
-			return true
-		}
-	}
-	return false
-}
-
+// Spam detection helpers used by RuleModerator
 func hasExcessiveRepetition(content string) bool {
 	words := strings.Fields(content)
 	if len(words) < 3 {
@@ -404,18 +418,6 @@ func hasRepeatedCharacters(content string, threshold int) bool {
 	return false
 }
 
-func hasURLs(content string) bool {
-	// Basic URL detection regex
-	urlRegex := regexp.MustCompile(`https?://[^\s]+`)
-	return urlRegex.MatchString(content)
-}
-
-func hasEmailAddresses(content string) bool {
-	// Basic email detection regex
-	emailRegex := regexp.MustCompile(`[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}`)
-	return emailRegex.MatchString(content)
-}
-
 // Rate limiting functions
 func checkCommentRateLimit(c *gin.Context) error {
 	// Get user ID from context
@@ -519,6 +521,37 @@ func parseBool(s string) (bool, error) {
 	return s == "true" || s == "1", nil
 }
 
+// ValidateCommentReaction validates the emoji of a reaction, taken from the emoji path
+// parameter when the route has one and from the body otherwise
+func ValidateCommentReaction() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		emoji := c.Param("emoji")
+		if emoji == "" {
+			var req CommentReactionRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+					"emoji": "Emoji is required",
+				})
+				c.Abort()
+				return
+			}
+			emoji = req.Emoji
+		}
+
+		emoji = strings.TrimSpace(emoji)
+		if !models.IsCommentReactionEmoji(emoji) {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"emoji": fmt.Sprintf("Emoji must be one of %s", strings.Join(models.CommentReactionEmojis, " ")),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedReactionKey, emoji)
+		c.Next()
+	}
+}
+
 // Getter functions for validated requests
 func GetValidatedCommentCreate(c *gin.Context) (*CreateCommentRequest, bool) {
 	value, exists := c.Get(ValidatedCommentCreateKey)
@@ -559,3 +592,23 @@ func GetValidatedCommentID(c *gin.Context) (uuid.UUID, bool) {
 	id, ok := value.(uuid.UUID)
 	return id, ok
 }
+
+func GetValidatedReaction(c *gin.Context) (string, bool) {
+	value, exists := c.Get(ValidatedReactionKey)
+	if !exists {
+		return "", false
+	}
+
+	emoji, ok := value.(string)
+	return emoji, ok
+}
+
+func GetValidatedBulkComment(c *gin.Context) (*BulkCommentRequest, bool) {
+	value, exists := c.Get(ValidatedCommentBulkKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*BulkCommentRequest)
+	return req, ok
+}
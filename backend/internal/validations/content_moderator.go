@@ -0,0 +1,140 @@
+package validations
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/config"
+)
+
+// ContentModerator decides whether user written text such as a comment can be published.
+// Check returns an error with a message that can be shown to the user when it can't.
+type ContentModerator interface {
+	Check(content string) error
+}
+
+var (
+	errInappropriateContent  = errors.New("Comment contains inappropriate content")
+	errInappropriateLanguage = errors.New("Comment contains inappropriate language")
+	errExcessiveRepetition   = errors.New("Comment contains excessive repetition")
+	errLinkNotAllowed        = errors.New("Comment contains a link to a domain that is not allowed")
+)
+
+var (
+	linkRegex      = regexp.MustCompile(`(?i)https?://[^\s]+`)
+	linkEmailRegex = regexp.MustCompile(`[a-zA-Z0-9._%+-]+@([a-zA-Z0-9.-]+\.[a-zA-Z]{2,})`)
+)
+
+// Phrases that only show up in promotional spam
+var promotionalPhrases = []string{
+	"buy now", "click here", "limited time", "act now", "you are a winner",
+}
+
+// Builds the moderator configured for the deployment
+func NewContentModerator(cfg config.ModerationConfig) ContentModerator {
+	if !cfg.Enabled {
+		return NoopModerator{}
+	}
+	return NewRuleModerator(cfg.BlockLinks, cfg.AllowedDomains, cfg.BlockedWords)
+}
+
+// NoopModerator accepts everything, for deployments that moderate elsewhere or not at all
+type NoopModerator struct{}
+
+func (NoopModerator) Check(string) error {
+	return nil
+}
+
+// RuleModerator rejects blocked words, promotional phrases, character and word repetition
+// and, when link blocking is on, links and email addresses outside the allowed domains
+type RuleModerator struct {
+	blockLinks     bool
+	allowedDomains []string
+	blockedWords   *regexp.Regexp // nil when no words are blocked
+}
+
+func NewRuleModerator(blockLinks bool, allowedDomains, blockedWords []string) *RuleModerator {
+	moderator := &RuleModerator{blockLinks: blockLinks}
+
+	for _, domain := range allowedDomains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			moderator.allowedDomains = append(moderator.allowedDomains, domain)
+		}
+	}
+
+	// Whole words only, so blocking "scam" does not reject "scampi"
+	var words []string
+	for _, word := range blockedWords {
+		if word = strings.TrimSpace(word); word != "" {
+			words = append(words, regexp.QuoteMeta(strings.ToLower(word)))
+		}
+	}
+	if len(words) > 0 {
+		moderator.blockedWords = regexp.MustCompile(`\b(?:` + strings.Join(words, "|") + `)\b`)
+	}
+
+	return moderator
+}
+
+func (m *RuleModerator) Check(content string) error {
+	lower := strings.ToLower(content)
+
+	if hasRepeatedCharacters(lower, 10) {
+		return errInappropriateContent
+	}
+
+	for _, phrase := range promotionalPhrases {
+		if strings.Contains(lower, phrase) {
+			return errInappropriateContent
+		}
+	}
+
+	if m.blockLinks && m.hasBlockedLink(content) {
+		return errLinkNotAllowed
+	}
+
+	if m.blockedWords != nil && m.blockedWords.MatchString(lower) {
+		return errInappropriateLanguage
+	}
+
+	if hasExcessiveRepetition(content) {
+		return errExcessiveRepetition
+	}
+
+	return nil
+}
+
+// Reports whether the content links or gives an email address outside the allowed domains
+func (m *RuleModerator) hasBlockedLink(content string) bool {
+	for _, link := range linkRegex.FindAllString(content, -1) {
+		parsed, err := url.Parse(strings.TrimRight(link, ".,;:!?)\"'"))
+		if err != nil || !m.isAllowedDomain(parsed.Hostname()) {
+			return true
+		}
+	}
+
+	for _, match := range linkEmailRegex.FindAllStringSubmatch(content, -1) {
+		if !m.isAllowedDomain(match[1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Matches the domain itself and its subdomains
+func (m *RuleModerator) isAllowedDomain(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" {
+		return false
+	}
+	for _, domain := range m.allowedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
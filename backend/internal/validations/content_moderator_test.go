@@ -0,0 +1,60 @@
+package validations
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRuleModeratorLinks(t *testing.T) {
+	moderator := NewRuleModerator(true, []string{"example.com", " Docs.Noesis.IO. "}, nil)
+
+	tests := []struct {
+		name    string
+		content string
+		allowed bool
+	}{
+		{"no link", "See the second chapter", true},
+		{"allowed host", "See https://example.com/page", true},
+		{"allowed host over http", "See http://example.com", true},
+		{"allowed host with port", "See https://example.com:8443/page", true},
+		{"upper case host", "See HTTPS://EXAMPLE.COM/page", true},
+		{"trailing dot", "See https://example.com./page", true},
+		{"trailing punctuation", "See (https://example.com/page).", true},
+		{"subdomain", "See https://www.example.com/page", true},
+		{"nested subdomain", "See https://a.b.example.com", true},
+		{"normalized allowlist entry", "See https://docs.noesis.io/guide", true},
+		{"allowed email", "Mail jane@example.com", true},
+		{"allowed subdomain email", "Mail jane@mail.example.com", true},
+		{"other host", "See https://evil.com", false},
+		{"lookalike prefix", "See https://evilexample.com", false},
+		{"lookalike hyphen", "See https://example-com.evil.com", false},
+		{"allowed name as subdomain", "See https://example.com.evil.com/page", false},
+		{"different tld", "See https://example.co", false},
+		{"parent of allowed domain", "See https://noesis.io", false},
+		{"userinfo", "See https://example.com@evil.com/page", false},
+		{"one bad link among good", "See https://example.com and https://evil.com", false},
+		{"lookalike email", "Mail jane@example.com.evil.com", false},
+		{"other email", "Mail jane@evil.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := moderator.Check(tt.content)
+			if tt.allowed && err != nil {
+				t.Fatalf("Check(%q) = %v, want allowed", tt.content, err)
+			}
+			if !tt.allowed && !errors.Is(err, errLinkNotAllowed) {
+				t.Fatalf("Check(%q) = %v, want %v", tt.content, err, errLinkNotAllowed)
+			}
+		})
+	}
+}
+
+func TestRuleModeratorLinksNotBlocked(t *testing.T) {
+	moderator := NewRuleModerator(false, []string{"example.com"}, nil)
+	for _, content := range []string{"See https://evil.com", "Mail jane@evil.com"} {
+		if err := moderator.Check(content); err != nil {
+			t.Errorf("Check(%q) = %v, want allowed with link blocking off", content, err)
+		}
+	}
+}
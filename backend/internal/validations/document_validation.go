@@ -1,14 +1,17 @@
 package validations
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
-	"path/filepath"
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
 	"github.com/eyuppastirmaci/noesis-forge/internal/types"
 	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -24,14 +27,22 @@ const (
 	ValidatedDocumentIDKey         = "validatedDocumentID"
 	ValidatedBulkDeleteKey         = "validatedBulkDelete"
 	ValidatedBulkDownloadKey       = "validatedBulkDownload"
+	ValidatedBulkUpdateKey         = "validatedBulkUpdate"
+	ValidatedPresignedURLsKey      = "validatedPresignedURLs"
 )
 
 // FileMetadata represents individual file metadata
 type FileMetadata struct {
-	Title       string
-	Description string
-	Tags        string
-	IsPublic    bool
+	Title          string
+	Description    string
+	Tags           string
+	IsPublic       bool
+	Language       string
+	CustomMetadata map[string]interface{}
+	AllowDuplicate bool
+	RunOCR         bool
+	OCRLanguage    string
+	ExpiresAt      *time.Time
 }
 
 // BulkUploadDocumentRequest represents the validated bulk upload request
@@ -42,11 +53,11 @@ type BulkUploadDocumentRequest struct {
 
 // BulkOperationRequest represents a bulk operation request with document IDs
 type BulkOperationRequest struct {
-	DocumentIDs []string `json:"documentIds" binding:"required,min=1,max=100,dive,uuid"`
+	DocumentIDs []string `json:"documentIds" binding:"required,min=1,dive,uuid"`
 }
 
 // ValidateDocumentUpload validates document upload requests (multipart form)
-func ValidateDocumentUpload() gin.HandlerFunc {
+func ValidateDocumentUpload(fileTypes *models.FileTypePolicy, tagPolicy *models.TagPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Parse multipart form
 		err := c.Request.ParseMultipartForm(100 << 20) // 100MB max
@@ -64,7 +75,7 @@ func ValidateDocumentUpload() gin.HandlerFunc {
 			fieldErrors["file"] = "File is required"
 		} else {
 			// Validate file
-			if fileErrors := validateUploadedFile(file); len(fileErrors) > 0 {
+			if fileErrors := validateUploadedFile(file, fileTypes); len(fileErrors) > 0 {
 				for field, message := range fileErrors {
 					fieldErrors[field] = message
 				}
@@ -76,10 +87,14 @@ func ValidateDocumentUpload() gin.HandlerFunc {
 		description := strings.TrimSpace(c.PostForm("description"))
 		tags := strings.TrimSpace(c.PostForm("tags"))
 		isPublicStr := c.PostForm("isPublic")
+		language := strings.ToLower(strings.TrimSpace(c.PostForm("language")))
+		allowDuplicate := parseFormBool(c.PostForm("allowDuplicate"))
+		runOCR := parseFormBool(c.PostForm("runOcr"))
+		ocrLanguage := strings.TrimSpace(c.PostForm("ocrLanguage"))
 
 		// Use filename as title if title is empty
 		if title == "" && file != nil {
-			title = getFilenameWithoutExtension(file.Filename)
+			title = utils.TitleFromFilename(file.Filename)
 		}
 
 		// Validate title
@@ -101,7 +116,7 @@ func ValidateDocumentUpload() gin.HandlerFunc {
 			fieldErrors["tags"] = "Tags must be at most 500 characters"
 		}
 		if tags != "" {
-			if valid, msg := validateTags(tags); !valid {
+			if msg := tagPolicy.Validate(tags); msg != "" {
 				fieldErrors["tags"] = msg
 			}
 		}
@@ -112,6 +127,30 @@ func ValidateDocumentUpload() gin.HandlerFunc {
 			isPublic = isPublicStr == "true" || isPublicStr == "1"
 		}
 
+		// Validate language (optional, defaults to english)
+		if language == "" {
+			language = models.DefaultDocumentLanguage
+		} else if !models.IsSupportedDocumentLanguage(language) {
+			fieldErrors["language"] = "Unsupported document language"
+		}
+
+		// Validate OCR language (optional, derived from the document language by the service)
+		if ocrLanguage != "" && !ocrLanguageRegex.MatchString(ocrLanguage) {
+			fieldErrors["ocrLanguage"] = "OCR language must be Tesseract language codes joined with +, e.g. deu+eng"
+		}
+
+		// Validate custom metadata shape, values are checked against the schema by the service
+		customMetadata, msg := parseCustomMetadata(c.PostForm("customMetadata"))
+		if msg != "" {
+			fieldErrors["customMetadata"] = msg
+		}
+
+		// Validate expiration (optional)
+		expiresAt, msg := parseExpiresAt(c.PostForm("expiresAt"))
+		if msg != "" {
+			fieldErrors["expiresAt"] = msg
+		}
+
 		// If there are validation errors, return them
 		if len(fieldErrors) > 0 {
 			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
@@ -121,10 +160,16 @@ func ValidateDocumentUpload() gin.HandlerFunc {
 
 		// Create validated request
 		req := &types.UploadDocumentRequest{
-			Title:       title,
-			Description: description,
-			Tags:        tags,
-			IsPublic:    isPublic,
+			Title:          title,
+			Description:    description,
+			Tags:           tags,
+			IsPublic:       isPublic,
+			Language:       language,
+			CustomMetadata: customMetadata,
+			AllowDuplicate: allowDuplicate,
+			RunOCR:         runOCR,
+			OCRLanguage:    ocrLanguage,
+			ExpiresAt:      expiresAt,
 		}
 
 		// Store validated request in context
@@ -134,7 +179,7 @@ func ValidateDocumentUpload() gin.HandlerFunc {
 }
 
 // ValidateDocumentUpdate validates document update requests (multipart form with optional file)
-func ValidateDocumentUpdate() gin.HandlerFunc {
+func ValidateDocumentUpdate(fileTypes *models.FileTypePolicy, tagPolicy *models.TagPolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Parse multipart form
 		err := c.Request.ParseMultipartForm(100 << 20) // 100MB max
@@ -152,7 +197,7 @@ func ValidateDocumentUpdate() gin.HandlerFunc {
 			fieldErrors["file"] = "Invalid file"
 		} else if file != nil {
 			// Validate file if provided
-			if fileErrors := validateUploadedFile(file); len(fileErrors) > 0 {
+			if fileErrors := validateUploadedFile(file, fileTypes); len(fileErrors) > 0 {
 				for field, message := range fileErrors {
 					fieldErrors[field] = message
 				}
@@ -167,7 +212,7 @@ func ValidateDocumentUpdate() gin.HandlerFunc {
 
 		// For update, if no title provided and there's a new file, use new filename
 		if title == "" && file != nil {
-			title = getFilenameWithoutExtension(file.Filename)
+			title = utils.TitleFromFilename(file.Filename)
 		}
 
 		// Validate title (required for update)
@@ -189,7 +234,7 @@ func ValidateDocumentUpdate() gin.HandlerFunc {
 			fieldErrors["tags"] = "Tags must be at most 500 characters"
 		}
 		if tags != "" {
-			if valid, msg := validateTags(tags); !valid {
+			if msg := tagPolicy.Validate(tags); msg != "" {
 				fieldErrors["tags"] = msg
 			}
 		}
@@ -200,6 +245,19 @@ func ValidateDocumentUpdate() gin.HandlerFunc {
 			isPublic = isPublicStr == "true" || isPublicStr == "1"
 		}
 
+		// Validate custom metadata (optional, omitted keeps the current values)
+		customMetadata, msg := parseCustomMetadata(c.PostForm("customMetadata"))
+		if msg != "" {
+			fieldErrors["customMetadata"] = msg
+		}
+
+		// Validate expiration (omitted keeps the current one, empty removes it)
+		rawExpiresAt, hasExpiresAt := c.GetPostForm("expiresAt")
+		expiresAt, msg := parseExpiresAt(rawExpiresAt)
+		if msg != "" {
+			fieldErrors["expiresAt"] = msg
+		}
+
 		// If there are validation errors, return them
 		if len(fieldErrors) > 0 {
 			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
@@ -209,11 +267,14 @@ func ValidateDocumentUpdate() gin.HandlerFunc {
 
 		// Create validated request
 		req := &types.UpdateDocumentRequest{
-			Title:       title,
-			Description: description,
-			Tags:        tags,
-			IsPublic:    isPublic,
-			HasNewFile:  file != nil,
+			Title:          title,
+			Description:    description,
+			Tags:           tags,
+			IsPublic:       isPublic,
+			HasNewFile:     file != nil,
+			CustomMetadata: customMetadata,
+			ExpiresAt:      expiresAt,
+			ClearExpiresAt: hasExpiresAt && expiresAt == nil,
 		}
 
 		// Store validated request in context
@@ -227,39 +288,54 @@ func ValidateDocumentList() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		fieldErrors := make(map[string]string)
 
-		// Parse and validate page
-		pageStr := c.DefaultQuery("page", "1")
-		page, err := strconv.Atoi(pageStr)
-		if err != nil || page < 1 {
-			fieldErrors["page"] = "Page must be a positive integer"
-			page = 1
-		}
-
-		// Parse and validate limit
-		limitStr := c.DefaultQuery("limit", "20")
-		limit, err := strconv.Atoi(limitStr)
-		if err != nil || limit < 1 {
-			fieldErrors["limit"] = "Limit must be a positive integer"
-			limit = 20
-		} else if limit > 100 {
-			fieldErrors["limit"] = "Limit must be at most 100"
-			limit = 100
-		}
+		// Parse and validate page and limit
+		page, limit := parseListPagination(c, fieldErrors)
 
-		// Validate search
+		// Validate search, file type, tags and sorting
 		search := strings.TrimSpace(c.Query("search"))
-		if len(search) > 255 {
-			fieldErrors["search"] = "Search query must be at most 255 characters"
-			search = search[:255]
+		fileType := c.Query("fileType")
+		tags := strings.TrimSpace(c.Query("tags"))
+		sortBy := c.DefaultQuery("sortBy", "date")
+		sortDir := c.DefaultQuery("sortDir", "desc")
+		validateListFilters(fieldErrors, search, fileType, tags, sortBy, sortDir)
+
+		// Validate the folder, "root" lists documents that are not filed in a folder
+		var folderID *uuid.UUID
+		unfiled := false
+		includeSubfolders := parseFormBool(c.Query("includeSubfolders"))
+		switch folder := c.Query("folderId"); folder {
+		case "":
+		case "root":
+			// Everything is below the root
+			unfiled = !includeSubfolders
+		default:
+			parsed, err := uuid.Parse(folder)
+			if err != nil {
+				fieldErrors["folderId"] = "Folder ID must be a UUID or 'root'"
+			} else {
+				folderID = &parsed
+			}
 		}
 
-		// Validate fileType
-		fileType := c.Query("fileType")
-		if fileType != "" {
-			validFileTypes := []string{"pdf", "docx", "txt", "xlsx", "pptx", "other"}
-			if !slices.Contains(validFileTypes, fileType) {
-				fieldErrors["fileType"] = "Invalid file type"
-				fileType = ""
+		// Validate the cursor, passing the parameter at all selects cursor mode
+		var cursor *types.DocumentCursor
+		encodedCursor, useCursor := c.GetQuery("cursor")
+		if useCursor {
+			if encodedCursor != "" {
+				decoded, err := types.DecodeDocumentCursor(encodedCursor)
+				if err != nil {
+					fieldErrors["cursor"] = "Cursor is invalid"
+				}
+				cursor = decoded
+			}
+			if _, hasPage := c.GetQuery("page"); hasPage {
+				fieldErrors["page"] = "Page can't be combined with a cursor"
+			}
+			if search != "" {
+				fieldErrors["search"] = "Search can't be combined with a cursor"
+			}
+			if sortBy != "date" {
+				fieldErrors["sortBy"] = "Cursor listings are sorted by date"
 			}
 		}
 
@@ -273,26 +349,21 @@ func ValidateDocumentList() gin.HandlerFunc {
 			}
 		}
 
-		// Validate tags
-		tags := strings.TrimSpace(c.Query("tags"))
-		if len(tags) > 255 {
-			fieldErrors["tags"] = "Tags filter must be at most 255 characters"
-			tags = tags[:255]
+		// Validate language
+		language := strings.ToLower(strings.TrimSpace(c.Query("language")))
+		if language != "" && !models.IsSupportedDocumentLanguage(language) {
+			fieldErrors["language"] = "Unsupported document language"
+			language = ""
 		}
 
-		// Validate sortBy
-		sortBy := c.DefaultQuery("sortBy", "date")
-		validSortFields := []string{"relevance", "date", "size", "views", "downloads", "title"}
-		if !slices.Contains(validSortFields, sortBy) {
-			fieldErrors["sortBy"] = "Invalid sort field"
-			sortBy = "date"
-		}
-
-		// Validate sortDir
-		sortDir := c.DefaultQuery("sortDir", "desc")
-		if sortDir != "asc" && sortDir != "desc" {
-			fieldErrors["sortDir"] = "Sort direction must be 'asc' or 'desc'"
-			sortDir = "desc"
+		// Validate custom field filters, passed as metadata[<field>]=<value>
+		customFields := c.QueryMap("metadata")
+		for name, value := range customFields {
+			if len(name) > 50 || len(value) > 255 {
+				fieldErrors["metadata"] = "Custom field filters must use names up to 50 and values up to 255 characters"
+				customFields = nil
+				break
+			}
 		}
 
 		// If there are validation errors, return them (but don't abort for query params)
@@ -304,14 +375,23 @@ func ValidateDocumentList() gin.HandlerFunc {
 
 		// Create validated request
 		req := &types.DocumentListRequest{
-			Page:     page,
-			Limit:    limit,
-			Search:   search,
-			FileType: fileType,
-			Status:   status,
-			Tags:     tags,
-			SortBy:   sortBy,
-			SortDir:  sortDir,
+			Page:         page,
+			Limit:        limit,
+			Search:       search,
+			FileType:     fileType,
+			Status:       status,
+			Tags:         tags,
+			Language:     language,
+			CustomFields: customFields,
+			SortBy:       sortBy,
+			SortDir:      sortDir,
+			UseCursor:    useCursor,
+			Cursor:       cursor,
+
+			FolderID:          folderID,
+			Unfiled:           unfiled,
+			IncludeSubfolders: includeSubfolders,
+			Debug:             parseFormBool(c.Query("debug")),
 		}
 
 		// Store validated request in context
@@ -320,6 +400,53 @@ func ValidateDocumentList() gin.HandlerFunc {
 	}
 }
 
+// Parses the page and limit query parameters shared by document listings
+func parseListPagination(c *gin.Context, fieldErrors map[string]string) (int, int) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		fieldErrors["page"] = "Page must be a positive integer"
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		fieldErrors["limit"] = "Limit must be a positive integer"
+		limit = 20
+	} else if limit > 100 {
+		fieldErrors["limit"] = "Limit must be at most 100"
+		limit = 100
+	}
+
+	return page, limit
+}
+
+// Checks the filters shared by document listing and saved searches
+func validateListFilters(fieldErrors map[string]string, search, fileType, tags, sortBy, sortDir string) {
+	if len(search) > 255 {
+		fieldErrors["search"] = "Search query must be at most 255 characters"
+	}
+
+	if fileType != "" {
+		validFileTypes := []string{"pdf", "docx", "txt", "xlsx", "pptx", "other"}
+		if !slices.Contains(validFileTypes, fileType) {
+			fieldErrors["fileType"] = "Invalid file type"
+		}
+	}
+
+	if len(tags) > 255 {
+		fieldErrors["tags"] = "Tags filter must be at most 255 characters"
+	}
+
+	validSortFields := []string{"relevance", "date", "size", "views", "downloads", "title"}
+	if !slices.Contains(validSortFields, sortBy) {
+		fieldErrors["sortBy"] = "Invalid sort field"
+	}
+
+	if sortDir != "asc" && sortDir != "desc" {
+		fieldErrors["sortDir"] = "Sort direction must be 'asc' or 'desc'"
+	}
+}
+
 // ValidateDocumentID validates document ID from URL parameter
 func ValidateDocumentID() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -347,12 +474,21 @@ func ValidateDocumentID() gin.HandlerFunc {
 	}
 }
 
-// ValidateBulkDocumentUpload validates bulk document upload requests
-func ValidateBulkDocumentUpload() gin.HandlerFunc {
+// ValidateBulkDocumentUpload validates bulk document upload requests of at most maxFiles files
+// and maxBytes in total
+func ValidateBulkDocumentUpload(fileTypes *models.FileTypePolicy, tagPolicy *models.TagPolicy, maxFiles int, maxBytes int64) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Parse multipart form
-		err := c.Request.ParseMultipartForm(500 << 20) // 500MB max total
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		err := c.Request.ParseMultipartForm(maxBytes)
 		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				utils.ErrorResponse(c, http.StatusRequestEntityTooLarge, "REQUEST_TOO_LARGE",
+					fmt.Sprintf("Bulk uploads are limited to %dMB in total", maxBytes>>20))
+				c.Abort()
+				return
+			}
 			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_FORM", "Failed to parse multipart form")
 			c.Abort()
 			return
@@ -367,19 +503,35 @@ func ValidateBulkDocumentUpload() gin.HandlerFunc {
 			return
 		}
 
+		if len(files) > maxFiles {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"files": fmt.Sprintf("Maximum %d files can be uploaded at once", maxFiles),
+			})
+			c.Abort()
+			return
+		}
+
 		fieldErrors := make(map[string]string)
 
 		// Validate each file
 		for i, file := range files {
-			if fileErrors := validateUploadedFile(file); len(fileErrors) > 0 {
+			if fileErrors := validateUploadedFile(file, fileTypes); len(fileErrors) > 0 {
 				for field, message := range fileErrors {
 					fieldErrors[fmt.Sprintf("files[%d].%s", i, field)] = message
 				}
 			}
 		}
 
-		// Parse individual metadata for each file
+		// Parse individual metadata for each file, allowDuplicate, runOcr, ocrLanguage and expiresAt
+		// apply to all files unless set per file
 		metadata := make([]FileMetadata, len(files))
+		allowDuplicates := parseFormBool(c.PostForm("allowDuplicate"))
+		runOCRs := parseFormBool(c.PostForm("runOcr"))
+		ocrLanguages := strings.TrimSpace(c.PostForm("ocrLanguage"))
+		expiresAts, msg := parseExpiresAt(c.PostForm("expiresAt"))
+		if msg != "" {
+			fieldErrors["expiresAt"] = msg
+		}
 
 		for i := range files {
 			// Get individual file metadata
@@ -388,10 +540,30 @@ func ValidateBulkDocumentUpload() gin.HandlerFunc {
 			tags := strings.TrimSpace(c.PostForm(fmt.Sprintf("files[%d].tags", i)))
 			isPublicStr := c.PostForm(fmt.Sprintf("files[%d].isPublic", i))
 			isPublic := isPublicStr == "true" || isPublicStr == "1"
+			language := strings.ToLower(strings.TrimSpace(c.PostForm(fmt.Sprintf("files[%d].language", i))))
+			allowDuplicate := allowDuplicates
+			if value, ok := c.GetPostForm(fmt.Sprintf("files[%d].allowDuplicate", i)); ok {
+				allowDuplicate = parseFormBool(value)
+			}
+			runOCR := runOCRs
+			if value, ok := c.GetPostForm(fmt.Sprintf("files[%d].runOcr", i)); ok {
+				runOCR = parseFormBool(value)
+			}
+			ocrLanguage := ocrLanguages
+			if value, ok := c.GetPostForm(fmt.Sprintf("files[%d].ocrLanguage", i)); ok {
+				ocrLanguage = strings.TrimSpace(value)
+			}
+			expiresAt := expiresAts
+			if value, ok := c.GetPostForm(fmt.Sprintf("files[%d].expiresAt", i)); ok {
+				var msg string
+				if expiresAt, msg = parseExpiresAt(value); msg != "" {
+					fieldErrors[fmt.Sprintf("files[%d].expiresAt", i)] = msg
+				}
+			}
 
 			// Use filename as title if title is empty
 			if title == "" {
-				title = getFilenameWithoutExtension(files[i].Filename)
+				title = utils.TitleFromFilename(files[i].Filename)
 			}
 
 			// Validate individual file metadata
@@ -409,16 +581,37 @@ func ValidateBulkDocumentUpload() gin.HandlerFunc {
 				fieldErrors[fmt.Sprintf("files[%d].tags", i)] = "Tags must be at most 500 characters"
 			}
 			if tags != "" {
-				if valid, msg := validateTags(tags); !valid {
+				if msg := tagPolicy.Validate(tags); msg != "" {
 					fieldErrors[fmt.Sprintf("files[%d].tags", i)] = msg
 				}
 			}
 
+			if language == "" {
+				language = models.DefaultDocumentLanguage
+			} else if !models.IsSupportedDocumentLanguage(language) {
+				fieldErrors[fmt.Sprintf("files[%d].language", i)] = "Unsupported document language"
+			}
+
+			if ocrLanguage != "" && !ocrLanguageRegex.MatchString(ocrLanguage) {
+				fieldErrors[fmt.Sprintf("files[%d].ocrLanguage", i)] = "OCR language must be Tesseract language codes joined with +, e.g. deu+eng"
+			}
+
+			customMetadata, msg := parseCustomMetadata(c.PostForm(fmt.Sprintf("files[%d].customMetadata", i)))
+			if msg != "" {
+				fieldErrors[fmt.Sprintf("files[%d].customMetadata", i)] = msg
+			}
+
 			metadata[i] = FileMetadata{
-				Title:       title,
-				Description: description,
-				Tags:        tags,
-				IsPublic:    isPublic,
+				Title:          title,
+				Description:    description,
+				Tags:           tags,
+				IsPublic:       isPublic,
+				Language:       language,
+				CustomMetadata: customMetadata,
+				AllowDuplicate: allowDuplicate,
+				RunOCR:         runOCR,
+				OCRLanguage:    ocrLanguage,
+				ExpiresAt:      expiresAt,
 			}
 		}
 
@@ -441,8 +634,8 @@ func ValidateBulkDocumentUpload() gin.HandlerFunc {
 	}
 }
 
-// ValidateBulkDelete validates bulk delete requests
-func ValidateBulkDelete() gin.HandlerFunc {
+// ValidateBulkDelete validates bulk delete requests of at most maxDocuments documents
+func ValidateBulkDelete(maxDocuments int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req BulkOperationRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -463,9 +656,9 @@ func ValidateBulkDelete() gin.HandlerFunc {
 			return
 		}
 
-		if len(req.DocumentIDs) > 100 {
+		if len(req.DocumentIDs) > maxDocuments {
 			fieldErrors := map[string]string{
-				"documentIds": "Maximum 100 documents can be deleted at once",
+				"documentIds": fmt.Sprintf("Maximum %d documents can be deleted at once", maxDocuments),
 			}
 			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
 			c.Abort()
@@ -490,8 +683,60 @@ func ValidateBulkDelete() gin.HandlerFunc {
 	}
 }
 
-// ValidateBulkDownload validates bulk download requests
-func ValidateBulkDownload() gin.HandlerFunc {
+// ValidateBulkUpdate validates bulk tag and visibility update requests of at most maxDocuments documents
+func ValidateBulkUpdate(tagPolicy *models.TagPolicy, maxDocuments int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.BulkUpdateDocumentsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+			c.Abort()
+			return
+		}
+
+		fieldErrors := make(map[string]string)
+
+		if len(req.DocumentIDs) == 0 {
+			fieldErrors["documentIds"] = "At least one document ID is required"
+		} else if len(req.DocumentIDs) > maxDocuments {
+			fieldErrors["documentIds"] = fmt.Sprintf("Maximum %d documents can be updated at once", maxDocuments)
+		} else {
+			for i, id := range req.DocumentIDs {
+				if _, err := uuid.Parse(id); err != nil {
+					fieldErrors[fmt.Sprintf("documentIds[%d]", i)] = "Invalid document ID format"
+					break
+				}
+			}
+		}
+
+		if len(req.AddTags) > 0 {
+			if msg := tagPolicy.Validate(strings.Join(req.AddTags, ",")); msg != "" {
+				fieldErrors["addTags"] = msg
+			}
+		}
+		if len(req.RemoveTags) > 0 {
+			if msg := tagPolicy.Validate(strings.Join(req.RemoveTags, ",")); msg != "" {
+				fieldErrors["removeTags"] = msg
+			}
+		}
+
+		if len(req.AddTags) == 0 && len(req.RemoveTags) == 0 && req.IsPublic == nil {
+			fieldErrors["request"] = "At least one of addTags, removeTags or isPublic is required"
+		}
+
+		if len(fieldErrors) > 0 {
+			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		// Store validated request in context
+		c.Set(ValidatedBulkUpdateKey, &req)
+		c.Next()
+	}
+}
+
+// ValidateBulkDownload validates bulk download requests of at most maxDocuments documents
+func ValidateBulkDownload(maxDocuments int) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var req BulkOperationRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -512,9 +757,9 @@ func ValidateBulkDownload() gin.HandlerFunc {
 			return
 		}
 
-		if len(req.DocumentIDs) > 50 {
+		if len(req.DocumentIDs) > maxDocuments {
 			fieldErrors := map[string]string{
-				"documentIds": "Maximum 50 documents can be downloaded at once",
+				"documentIds": fmt.Sprintf("Maximum %d documents can be downloaded at once", maxDocuments),
 			}
 			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
 			c.Abort()
@@ -539,7 +784,7 @@ func ValidateBulkDownload() gin.HandlerFunc {
 	}
 }
 
-func validateUploadedFile(file *multipart.FileHeader) map[string]string {
+func validateUploadedFile(file *multipart.FileHeader, fileTypes *models.FileTypePolicy) map[string]string {
 	errors := make(map[string]string)
 
 	// Check file size (100MB limit)
@@ -566,27 +811,8 @@ func validateUploadedFile(file *multipart.FileHeader) map[string]string {
 		return errors
 	}
 
-	// Get file extension
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-
-	// Allowed extensions
-	allowedExtensions := map[string]bool{
-		".pdf":  true,
-		".doc":  true,
-		".docx": true,
-		".txt":  true,
-		".rtf":  true,
-		".odt":  true,
-		".xls":  true,
-		".xlsx": true,
-		".ppt":  true,
-		".pptx": true,
-		".odp":  true,
-		".ods":  true,
-	}
-
-	if !allowedExtensions[ext] {
-		errors["file"] = "File type not allowed. Supported formats: PDF, DOC, DOCX, TXT, RTF, ODT, XLS, XLSX, PPT, PPTX, ODP, ODS"
+	if !fileTypes.IsAllowed(file.Filename) {
+		errors["file"] = "File type not allowed. Supported formats: " + fileTypes.Describe()
 		return errors
 	}
 
@@ -598,38 +824,15 @@ func validateUploadedFile(file *multipart.FileHeader) map[string]string {
 	}
 	defer src.Close()
 
-	// Read first 512 bytes for MIME type detection
-	buffer := make([]byte, 512)
-	_, err = src.Read(buffer)
+	// Office formats are identified from their container structure, not just the first bytes
+	contentType, buffer, err := utils.DetectContentType(src, file.Size, file.Filename)
 	if err != nil {
 		errors["file"] = "Cannot read file content"
 		return errors
 	}
 
-	// Detect content type and ignore parameters (e.g., charset)
-	detectedContentType := http.DetectContentType(buffer)
-	contentType := strings.Split(detectedContentType, ";")[0]
-
-	// Allowed MIME types
-	allowedMIMETypes := map[string]bool{
-		"application/pdf":    true,
-		"application/msword": true,
-		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": true,
-		"text/plain":      true,
-		"application/rtf": true,
-		"application/vnd.oasis.opendocument.text":                                   true,
-		"application/vnd.ms-excel":                                                  true,
-		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":         true,
-		"application/vnd.ms-powerpoint":                                             true,
-		"application/vnd.openxmlformats-officedocument.presentationml.presentation": true,
-		"application/vnd.oasis.opendocument.presentation":                           true,
-		"application/vnd.oasis.opendocument.spreadsheet":                            true,
-		"application/zip":          true,
-		"application/octet-stream": true, // Fallback for some office documents
-	}
-
-	if !allowedMIMETypes[contentType] {
-		errors["file"] = fmt.Sprintf("File content type not allowed: %s", detectedContentType)
+	if !fileTypes.AllowsContentType(file.Filename, contentType) {
+		errors["file"] = fmt.Sprintf("File content type not allowed: %s", contentType)
 		return errors
 	}
 
@@ -679,40 +882,44 @@ func validateUploadedFile(file *multipart.FileHeader) map[string]string {
 	return errors
 }
 
-func validateTags(tags string) (bool, string) {
-	// Split tags by comma and validate each
-	tagList := strings.Split(tags, ",")
-
-	if len(tagList) > 10 {
-		return false, "Maximum 10 tags allowed"
+// Decodes the customMetadata form value, which must be a JSON object when present
+func parseCustomMetadata(raw string) (map[string]interface{}, string) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, ""
 	}
 
-	for _, tag := range tagList {
-		tag = strings.TrimSpace(tag)
-		if len(tag) == 0 {
-			continue
-		}
-
-		if len(tag) < 2 {
-			return false, "Each tag must be at least 2 characters"
-		}
-
-		if len(tag) > 50 {
-			return false, "Each tag must be at most 50 characters"
-		}
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &values); err != nil || values == nil {
+		return nil, "Custom metadata must be a JSON object"
+	}
+	if len(values) > 50 {
+		return nil, "Custom metadata must have at most 50 fields"
+	}
+	return values, ""
+}
 
-		// Check for invalid characters in tags
-		if !isValidTagName(tag) {
-			return false, "Tags can only contain letters, numbers, hyphens, and underscores"
-		}
+// Decodes the expiresAt form value, an RFC 3339 time in the future when present
+func parseExpiresAt(raw string) (*time.Time, string) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, ""
 	}
 
-	return true, ""
+	expiresAt, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, "Expiration must be an RFC 3339 time, e.g. 2025-01-31T18:00:00Z"
+	}
+	if !expiresAt.After(time.Now()) {
+		return nil, "Expiration must be in the future"
+	}
+	expiresAt = expiresAt.UTC()
+	return &expiresAt, ""
 }
 
-func getFilenameWithoutExtension(filename string) string {
-	ext := filepath.Ext(filename)
-	return strings.TrimSuffix(filename, ext)
+// Reads a checkbox style form value
+func parseFormBool(value string) bool {
+	return value == "true" || value == "1"
 }
 
 func containsMaliciousCharacters(filename string) bool {
@@ -725,16 +932,41 @@ func containsMaliciousCharacters(filename string) bool {
 	return false
 }
 
-func isValidTagName(tag string) bool {
-	for _, char := range tag {
-		if !((char >= 'a' && char <= 'z') ||
-			(char >= 'A' && char <= 'Z') ||
-			(char >= '0' && char <= '9') ||
-			char == '-' || char == '_' || char == ' ') {
-			return false
+// Validates requests for the presigned URLs of at most maxDocuments documents
+func ValidatePresignedURLs(maxDocuments int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.PresignedURLsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"documentIds": "Invalid document IDs provided",
+			})
+			c.Abort()
+			return
 		}
+
+		fieldErrors := make(map[string]string)
+		if len(req.DocumentIDs) == 0 {
+			fieldErrors["documentIds"] = "At least one document ID is required"
+		} else if len(req.DocumentIDs) > maxDocuments {
+			fieldErrors["documentIds"] = fmt.Sprintf("Maximum %d documents can be requested at once", maxDocuments)
+		} else {
+			for i, id := range req.DocumentIDs {
+				if _, err := uuid.Parse(id); err != nil {
+					fieldErrors[fmt.Sprintf("documentIds[%d]", i)] = "Invalid document ID format"
+					break
+				}
+			}
+		}
+
+		if len(fieldErrors) > 0 {
+			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedPresignedURLsKey, &req)
+		c.Next()
 	}
-	return true
 }
 
 // GetValidatedDocumentUpload retrieves the validated upload request from context
@@ -792,6 +1024,17 @@ func GetValidatedBulkDelete(c *gin.Context) (*BulkOperationRequest, bool) {
 	return req, ok
 }
 
+// Retrieves the validated bulk update request from context
+func GetValidatedBulkUpdate(c *gin.Context) (*types.BulkUpdateDocumentsRequest, bool) {
+	value, exists := c.Get(ValidatedBulkUpdateKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.BulkUpdateDocumentsRequest)
+	return req, ok
+}
+
 // Retrieves the validated bulk download request from context
 func GetValidatedBulkDownload(c *gin.Context) (*BulkOperationRequest, bool) {
 	value, exists := c.Get(ValidatedBulkDownloadKey)
@@ -813,3 +1056,14 @@ func GetValidatedDocumentUpdate(c *gin.Context) (*types.UpdateDocumentRequest, b
 	req, ok := value.(*types.UpdateDocumentRequest)
 	return req, ok
 }
+
+// Retrieves the validated presigned URLs request from context
+func GetValidatedPresignedURLs(c *gin.Context) (*types.PresignedURLsRequest, bool) {
+	value, exists := c.Get(ValidatedPresignedURLsKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.PresignedURLsRequest)
+	return req, ok
+}
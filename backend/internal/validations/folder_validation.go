@@ -0,0 +1,174 @@
+package validations
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Context keys for folder validations
+const (
+	ValidatedFolderCreateKey = "validatedFolderCreate"
+	ValidatedFolderUpdateKey = "validatedFolderUpdate"
+	ValidatedFolderIDKey     = "validatedFolderID"
+	ValidatedFolderParentKey = "validatedFolderParent"
+)
+
+// ValidateFolderCreate validates the name and parent of a new folder
+func ValidateFolderCreate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.CreateFolderRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"name": "Name is required and must be at most 255 characters",
+			})
+			c.Abort()
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if msg := validateFolderName(req.Name); msg != "" {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{"name": msg})
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedFolderCreateKey, &req)
+		c.Next()
+	}
+}
+
+// ValidateFolderUpdate validates a rename or move of a folder
+func ValidateFolderUpdate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.UpdateFolderRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"name": "Name must be at most 255 characters",
+			})
+			c.Abort()
+			return
+		}
+
+		fieldErrors := make(map[string]string)
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			req.Name = &name
+			if msg := validateFolderName(name); msg != "" {
+				fieldErrors["name"] = msg
+			}
+		}
+		if req.MoveToRoot && req.ParentFolderID != nil {
+			fieldErrors["parentFolderId"] = "A parent folder can't be combined with moveToRoot"
+		}
+		if req.Name == nil && req.ParentFolderID == nil && !req.MoveToRoot {
+			fieldErrors["name"] = "Nothing to update, pass a name, a parentFolderId or moveToRoot"
+		}
+
+		if len(fieldErrors) > 0 {
+			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedFolderUpdateKey, &req)
+		c.Next()
+	}
+}
+
+// ValidateFolderID validates the folder ID parameter
+func ValidateFolderID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		folderID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_FOLDER_ID", "Invalid folder ID format")
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedFolderIDKey, folderID)
+		c.Next()
+	}
+}
+
+// ValidateFolderParent validates the optional parentId query parameter of folder listings
+func ValidateFolderParent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var parentID *uuid.UUID
+		if raw := c.Query("parentId"); raw != "" && raw != "root" {
+			parsed, err := uuid.Parse(raw)
+			if err != nil {
+				utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_FOLDER_ID", "Invalid parent folder ID format")
+				c.Abort()
+				return
+			}
+			parentID = &parsed
+		}
+
+		c.Set(ValidatedFolderParentKey, parentID)
+		c.Next()
+	}
+}
+
+// Folder names show up as path segments, so separators and control characters are rejected
+func validateFolderName(name string) string {
+	if name == "" {
+		return "Name is required"
+	}
+	if len(name) > 255 {
+		return "Name must be at most 255 characters"
+	}
+	if strings.ContainsAny(name, `/\`) || strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "Name can't contain slashes or control characters"
+	}
+	return ""
+}
+
+// Retrieves the validated new folder from context
+func GetValidatedFolderCreate(c *gin.Context) (*types.CreateFolderRequest, bool) {
+	value, exists := c.Get(ValidatedFolderCreateKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.CreateFolderRequest)
+	return req, ok
+}
+
+// Retrieves the validated folder update from context
+func GetValidatedFolderUpdate(c *gin.Context) (*types.UpdateFolderRequest, bool) {
+	value, exists := c.Get(ValidatedFolderUpdateKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.UpdateFolderRequest)
+	return req, ok
+}
+
+// Retrieves the validated folder ID from context
+func GetValidatedFolderID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get(ValidatedFolderIDKey)
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	id, ok := value.(uuid.UUID)
+	return id, ok
+}
+
+// Retrieves the validated parent folder from context, nil for the root
+func GetValidatedFolderParent(c *gin.Context) (*uuid.UUID, bool) {
+	value, exists := c.Get(ValidatedFolderParentKey)
+	if !exists {
+		return nil, false
+	}
+
+	parentID, ok := value.(*uuid.UUID)
+	return parentID, ok
+}
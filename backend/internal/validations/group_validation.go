@@ -0,0 +1,253 @@
+package validations
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Context keys for group validations
+const (
+	ValidatedGroupCreateKey   = "validatedGroupCreate"
+	ValidatedGroupUpdateKey   = "validatedGroupUpdate"
+	ValidatedGroupIDKey       = "validatedGroupID"
+	ValidatedGroupMemberKey   = "validatedGroupMember"
+	ValidatedGroupMemberIDKey = "validatedGroupMemberID"
+	ValidatedGroupShareKey    = "validatedGroupShare"
+	ValidatedSharedGroupIDKey = "validatedSharedGroupID"
+)
+
+// ValidateGroupCreate validates the name and description of a new group
+func ValidateGroupCreate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.CreateGroupRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"name": "Name is required and must be at most 255 characters, description at most 1000",
+			})
+			c.Abort()
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		req.Description = strings.TrimSpace(req.Description)
+		if req.Name == "" {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{"name": "Name is required"})
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedGroupCreateKey, &req)
+		c.Next()
+	}
+}
+
+// ValidateGroupUpdate validates a rename or description change of a group
+func ValidateGroupUpdate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.UpdateGroupRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"name": "Name must be at most 255 characters, description at most 1000",
+			})
+			c.Abort()
+			return
+		}
+
+		fieldErrors := make(map[string]string)
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			req.Name = &name
+			if name == "" {
+				fieldErrors["name"] = "Name can't be empty"
+			}
+		}
+		if req.Description != nil {
+			description := strings.TrimSpace(*req.Description)
+			req.Description = &description
+		}
+		if req.Name == nil && req.Description == nil {
+			fieldErrors["name"] = "Nothing to update, pass a name or a description"
+		}
+
+		if len(fieldErrors) > 0 {
+			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedGroupUpdateKey, &req)
+		c.Next()
+	}
+}
+
+// ValidateGroupID validates the group ID parameter
+func ValidateGroupID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_GROUP_ID", "Invalid group ID format")
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedGroupIDKey, groupID)
+		c.Next()
+	}
+}
+
+// ValidateGroupMemberAdd validates the email or username of a user joining a group
+func ValidateGroupMemberAdd() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.AddGroupMemberRequest
+		if err := c.ShouldBindJSON(&req); err == nil {
+			req.Identifier = strings.TrimSpace(req.Identifier)
+		}
+		if req.Identifier == "" {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"identifier": "Email or username is required",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedGroupMemberKey, &req)
+		c.Next()
+	}
+}
+
+// ValidateGroupMemberID validates the user ID parameter of a group member
+func ValidateGroupMemberID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := uuid.Parse(c.Param("userId"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_USER_ID", "Invalid user ID format")
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedGroupMemberIDKey, userID)
+		c.Next()
+	}
+}
+
+// ValidateGroupShare validates sharing a document with a group
+func ValidateGroupShare() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.ShareWithGroupRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"groupId": "Group ID and access level are required, expiresInDays can't be negative",
+			})
+			c.Abort()
+			return
+		}
+
+		if !req.AccessLevel.IsValid() {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"accessLevel": "Access level must be 'view', 'download', or 'edit'",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedGroupShareKey, &req)
+		c.Next()
+	}
+}
+
+// ValidateSharedGroupID validates the group ID parameter of a document's group share
+func ValidateSharedGroupID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groupID, err := uuid.Parse(c.Param("groupId"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_GROUP_ID", "Invalid group ID format")
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedSharedGroupIDKey, groupID)
+		c.Next()
+	}
+}
+
+// Retrieves the validated new group from context
+func GetValidatedGroupCreate(c *gin.Context) (*types.CreateGroupRequest, bool) {
+	value, exists := c.Get(ValidatedGroupCreateKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.CreateGroupRequest)
+	return req, ok
+}
+
+// Retrieves the validated group update from context
+func GetValidatedGroupUpdate(c *gin.Context) (*types.UpdateGroupRequest, bool) {
+	value, exists := c.Get(ValidatedGroupUpdateKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.UpdateGroupRequest)
+	return req, ok
+}
+
+// Retrieves the validated group ID from context
+func GetValidatedGroupID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get(ValidatedGroupIDKey)
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	id, ok := value.(uuid.UUID)
+	return id, ok
+}
+
+// Retrieves the validated new group member from context
+func GetValidatedGroupMember(c *gin.Context) (*types.AddGroupMemberRequest, bool) {
+	value, exists := c.Get(ValidatedGroupMemberKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.AddGroupMemberRequest)
+	return req, ok
+}
+
+// Retrieves the validated group member user ID from context
+func GetValidatedGroupMemberID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get(ValidatedGroupMemberIDKey)
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	id, ok := value.(uuid.UUID)
+	return id, ok
+}
+
+// Retrieves the validated group share from context
+func GetValidatedGroupShare(c *gin.Context) (*types.ShareWithGroupRequest, bool) {
+	value, exists := c.Get(ValidatedGroupShareKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.ShareWithGroupRequest)
+	return req, ok
+}
+
+// Retrieves the validated shared group ID from context
+func GetValidatedSharedGroupID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get(ValidatedSharedGroupIDKey)
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	id, ok := value.(uuid.UUID)
+	return id, ok
+}
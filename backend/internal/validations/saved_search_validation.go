@@ -0,0 +1,129 @@
+package validations
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Context keys for saved search validations
+const (
+	ValidatedSavedSearchCreateKey = "validatedSavedSearchCreate"
+	ValidatedSavedSearchIDKey     = "validatedSavedSearchID"
+	ValidatedSavedSearchRunKey    = "validatedSavedSearchRun"
+)
+
+// Paging for running a saved search
+type SavedSearchRunRequest struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+// ValidateSavedSearchCreate validates a new saved search with the document list filter rules
+func ValidateSavedSearchCreate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.CreateSavedSearchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{
+				"name": "Name is required and must be at most 100 characters",
+			})
+			c.Abort()
+			return
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		req.Query = strings.TrimSpace(req.Query)
+		req.Tags = strings.TrimSpace(req.Tags)
+		if req.SortBy == "" {
+			req.SortBy = "date"
+			if req.Query != "" {
+				req.SortBy = "relevance"
+			}
+		}
+		if req.SortDir == "" {
+			req.SortDir = "desc"
+		}
+
+		fieldErrors := make(map[string]string)
+		if req.Name == "" {
+			fieldErrors["name"] = "Name is required"
+		}
+		validateListFilters(fieldErrors, req.Query, req.FileType, req.Tags, req.SortBy, req.SortDir)
+		if len(fieldErrors) > 0 {
+			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedSavedSearchCreateKey, &req)
+		c.Next()
+	}
+}
+
+// ValidateSavedSearchID validates the saved search ID parameter
+func ValidateSavedSearchID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		searchID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_SEARCH_ID", "Invalid saved search ID format")
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedSavedSearchIDKey, searchID)
+		c.Next()
+	}
+}
+
+// ValidateSavedSearchRun validates the paging of a saved search run
+func ValidateSavedSearchRun() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fieldErrors := make(map[string]string)
+		page, limit := parseListPagination(c, fieldErrors)
+		if len(fieldErrors) > 0 {
+			utils.FieldValidationErrorResponse(c, "Invalid query parameters", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedSavedSearchRunKey, &SavedSearchRunRequest{Page: page, Limit: limit})
+		c.Next()
+	}
+}
+
+// Retrieves the validated saved search from context
+func GetValidatedSavedSearchCreate(c *gin.Context) (*types.CreateSavedSearchRequest, bool) {
+	value, exists := c.Get(ValidatedSavedSearchCreateKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.CreateSavedSearchRequest)
+	return req, ok
+}
+
+// Retrieves the validated saved search ID from context
+func GetValidatedSavedSearchID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get(ValidatedSavedSearchIDKey)
+	if !exists {
+		return uuid.Nil, false
+	}
+
+	id, ok := value.(uuid.UUID)
+	return id, ok
+}
+
+// Retrieves the validated saved search paging from context
+func GetValidatedSavedSearchRun(c *gin.Context) (*SavedSearchRunRequest, bool) {
+	value, exists := c.Get(ValidatedSavedSearchRunKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*SavedSearchRunRequest)
+	return req, ok
+}
@@ -12,6 +12,8 @@ import (
 var (
 	emailRegex    = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	// Tesseract language spec, up to five languages joined with +, e.g. deu+eng
+	ocrLanguageRegex = regexp.MustCompile(`^[a-zA-Z0-9_]{2,32}(\+[a-zA-Z0-9_]{2,32}){0,4}$`)
 )
 
 func init() {
@@ -0,0 +1,157 @@
+package validations
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/eyuppastirmaci/noesis-forge/internal/models"
+	"github.com/eyuppastirmaci/noesis-forge/internal/types"
+	"github.com/eyuppastirmaci/noesis-forge/internal/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	ValidatedSharedWithMeListKey = "validatedSharedWithMeList"
+	ValidatedBulkShareKey        = "validatedBulkShare"
+)
+
+const (
+	maxBulkShareDocuments = 50
+	maxBulkShareEmails    = 20
+)
+
+// ValidateSharedWithMeList validates the search, filter and paging of documents shared with the user
+func ValidateSharedWithMeList() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fieldErrors := make(map[string]string)
+
+		page, limit := parseListPagination(c, fieldErrors)
+
+		// Search, file type and direction follow the document list rules
+		search := strings.TrimSpace(c.Query("search"))
+		fileType := c.Query("fileType")
+		sortDir := c.DefaultQuery("sortDir", "desc")
+		validateListFilters(fieldErrors, search, fileType, "", "date", sortDir)
+
+		// Shares sort by when they were shared unless asked otherwise
+		sortBy := c.DefaultQuery("sortBy", "shared")
+		if !slices.Contains([]string{"shared", "date", "title", "size"}, sortBy) {
+			fieldErrors["sortBy"] = "Invalid sort field"
+		}
+
+		if len(fieldErrors) > 0 {
+			utils.FieldValidationErrorResponse(c, "Invalid query parameters", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		c.Set(ValidatedSharedWithMeListKey, &types.SharedWithMeListRequest{
+			Page:     page,
+			Limit:    limit,
+			Search:   search,
+			FileType: fileType,
+			SortBy:   sortBy,
+			SortDir:  sortDir,
+		})
+		c.Next()
+	}
+}
+
+// Retrieves the validated shared with me listing from context
+func GetValidatedSharedWithMeList(c *gin.Context) (*types.SharedWithMeListRequest, bool) {
+	value, exists := c.Get(ValidatedSharedWithMeListKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.SharedWithMeListRequest)
+	return req, ok
+}
+
+// ValidateBulkShare validates sharing several documents with several emails. Duplicate
+// documents and emails are dropped so every (document, email) pair is shared once.
+func ValidateBulkShare() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req types.BulkShareRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body")
+			c.Abort()
+			return
+		}
+
+		fieldErrors := make(map[string]string)
+
+		var documentIDs []string
+		if len(req.DocumentIDs) == 0 {
+			fieldErrors["documentIds"] = "At least one document ID is required"
+		} else if len(req.DocumentIDs) > maxBulkShareDocuments {
+			fieldErrors["documentIds"] = fmt.Sprintf("Maximum %d documents can be shared at once", maxBulkShareDocuments)
+		} else {
+			for i, id := range req.DocumentIDs {
+				parsed, err := uuid.Parse(id)
+				if err != nil {
+					fieldErrors[fmt.Sprintf("documentIds[%d]", i)] = "Invalid document ID format"
+					break
+				}
+				if !slices.Contains(documentIDs, parsed.String()) {
+					documentIDs = append(documentIDs, parsed.String())
+				}
+			}
+		}
+
+		var emails []string
+		if len(req.Emails) == 0 {
+			fieldErrors["emails"] = "At least one email is required"
+		} else if len(req.Emails) > maxBulkShareEmails {
+			fieldErrors["emails"] = fmt.Sprintf("Maximum %d emails can be shared with at once", maxBulkShareEmails)
+		} else {
+			for i, email := range req.Emails {
+				email = strings.TrimSpace(email)
+				if !emailRegex.MatchString(email) {
+					fieldErrors[fmt.Sprintf("emails[%d]", i)] = "Invalid email address"
+					break
+				}
+				if !slices.ContainsFunc(emails, func(e string) bool { return strings.EqualFold(e, email) }) {
+					emails = append(emails, email)
+				}
+			}
+		}
+
+		if !models.AccessLevel(req.AccessLevel).IsValid() {
+			fieldErrors["accessLevel"] = "Access level must be 'view', 'download', or 'edit'"
+		}
+
+		if req.ExpiresInDays < 0 {
+			fieldErrors["expiresInDays"] = "Expiration can't be negative"
+		}
+
+		if req.MaxDownloads != nil && *req.MaxDownloads < 1 {
+			fieldErrors["maxDownloads"] = "Download limit must be at least 1"
+		}
+
+		if len(fieldErrors) > 0 {
+			utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+			c.Abort()
+			return
+		}
+
+		req.DocumentIDs = documentIDs
+		req.Emails = emails
+		c.Set(ValidatedBulkShareKey, &req)
+		c.Next()
+	}
+}
+
+// Retrieves the validated bulk share request from context
+func GetValidatedBulkShare(c *gin.Context) (*types.BulkShareRequest, bool) {
+	value, exists := c.Get(ValidatedBulkShareKey)
+	if !exists {
+		return nil, false
+	}
+
+	req, ok := value.(*types.BulkShareRequest)
+	return req, ok
+}
@@ -185,6 +185,35 @@ func ValidateChangePassword() gin.HandlerFunc {
 	}
 }
 
+// Validates password reset requests
+func ValidateResetPassword() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req services.ResetPasswordRequest
+
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fieldErrors := ParseValidationErrors(err)
+			if len(fieldErrors) > 0 {
+				utils.FieldValidationErrorResponse(c, "Validation failed", fieldErrors)
+				c.Abort()
+				return
+			}
+			utils.ErrorResponse(c, http.StatusBadRequest, "VALIDATION_ERROR", "Invalid request data", err.Error())
+			c.Abort()
+			return
+		}
+
+		// Check new password strength
+		if valid, msg := CheckPasswordStrength(req.NewPassword); !valid {
+			utils.FieldValidationErrorResponse(c, "Validation failed", map[string]string{"newPassword": msg})
+			c.Abort()
+			return
+		}
+
+		c.Set("validatedPasswordReset", &req)
+		c.Next()
+	}
+}
+
 // Validates login requests
 func ValidateLogin() gin.HandlerFunc {
 	return func(c *gin.Context) {
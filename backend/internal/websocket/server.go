@@ -107,6 +107,11 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.socketServer.ServeHTTP(w, r)
 }
 
+// Close disconnects all socket clients
+func (s *Server) Close() error {
+	return s.socketServer.Close()
+}
+
 // GetServer returns the underlying socket.io server instance
 func (s *Server) GetServer() *socketio.Server {
 	return s.socketServer
@@ -592,16 +592,16 @@ const DocumentDetailPage: React.FC = () => {
                       </p>
                     </div>
 
-                    {document.tags && (
+                    {document.tags?.length > 0 && (
                       <div>
                         <h3 className="text-sm font-medium text-foreground-secondary mb-2">
                           Tags
                         </h3>
                         <div className="flex flex-wrap gap-2">
-                          {document.tags.split(",").map((tag, index) => (
+                          {document.tags.map((tag, index) => (
                             <Badge key={index} color="blue" size="sm">
                               <Tag className="w-3 h-3 mr-1" />
-                              {tag.trim()}
+                              {tag}
                             </Badge>
                           ))}
                         </div>
@@ -977,16 +977,16 @@ const DocumentDetailPage: React.FC = () => {
                               </p>
                             </div>
 
-                            {document.tags && (
+                            {document.tags?.length > 0 && (
                               <div>
                                 <h4 className="text-sm font-medium text-foreground-secondary mb-2">
                                   Tags
                                 </h4>
                                 <div className="flex flex-wrap gap-2">
-                                  {document.tags.split(",").map((tag, index) => (
+                                  {document.tags.map((tag, index) => (
                                     <Badge key={index} color="blue" size="sm">
                                       <Tag className="w-3 h-3 mr-1" />
-                                      {tag.trim()}
+                                      {tag}
                                     </Badge>
                                   ))}
                                 </div>
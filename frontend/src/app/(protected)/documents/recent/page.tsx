@@ -421,9 +421,9 @@ const DocumentRecentPage: React.FC = () => {
             case "pdf":
               return doc.fileType === "pdf";
             case "scanned":
-              return doc.tags?.toLowerCase().includes("scanned");
+              return doc.tags?.some((tag) => tag.includes("scanned"));
             case "contracts":
-              return doc.tags?.toLowerCase().includes("contract");
+              return doc.tags?.some((tag) => tag.includes("contract"));
             case "images":
               return ["jpg", "jpeg", "png", "gif", "bmp", "svg"].includes(doc.fileType);
             default:
@@ -907,16 +907,16 @@ const DocumentRow: React.FC<DocumentRowProps> = ({
         </div>
       </td>
       <td className="px-6 py-4 w-32">
-        {document.tags ? (
+        {document.tags?.length > 0 ? (
           <div className="flex flex-wrap gap-1">
-            {document.tags.split(",").slice(0, 2).map((tag, index) => (
+            {document.tags.slice(0, 2).map((tag, index) => (
               <Badge key={index} color="blue" size="sm">
-                {tag.trim()}
+                {tag}
               </Badge>
             ))}
-            {document.tags.split(",").length > 2 && (
+            {document.tags.length > 2 && (
               <Badge color="gray" size="sm">
-                +{document.tags.split(",").length - 2} more
+                +{document.tags.length - 2} more
               </Badge>
             )}
           </div>
@@ -30,7 +30,8 @@ import {
   Shield, 
   CheckCircle,
   XCircle,
-  BarChart3
+  BarChart3,
+  History
 } from 'lucide-react';
 
 interface ActivityTimelineProps {
@@ -70,6 +71,7 @@ const getActivityStyle = (type: ActivityType) => {
     move: { color: 'bg-teal-500', icon: FolderOpen, bgColor: 'bg-teal-50', textColor: 'text-teal-700' },
     tag_update: { color: 'bg-indigo-400', icon: Tag, bgColor: 'bg-indigo-50', textColor: 'text-indigo-600' },
     permission_change: { color: 'bg-orange-400', icon: Shield, bgColor: 'bg-orange-50', textColor: 'text-orange-600' },
+    revision_restore: { color: 'bg-amber-500', icon: History, bgColor: 'bg-amber-50', textColor: 'text-amber-700' },
   };
   
   return styles[type] || styles.view;
@@ -118,6 +120,8 @@ const formatActivityDescription = (activity: ActivityResponse): string => {
       return `${user} updated tags`;
     case 'permission_change':
       return `${user} changed permissions`;
+    case 'revision_restore':
+      return `${user} restored an earlier version`;
     default:
       return activity.description || `${user} performed an action`;
   }
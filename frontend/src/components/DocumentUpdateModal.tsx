@@ -37,7 +37,7 @@ const DocumentUpdateModal: React.FC<DocumentUpdateModalProps> = ({
     if (document && isOpen) {
       setTitle(document.title);
       setDescription(document.description || "");
-      setTags(document.tags || "");
+      setTags((document.tags || []).join(", "));
       setIsPublic(document.isPublic);
       setSelectedFile(null);
       setFileError(null);
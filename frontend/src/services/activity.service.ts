@@ -452,6 +452,8 @@ export class ActivityService {
         return `${userName} updated tags on ${documentTitle}`;
       case "permission_change":
         return `${userName} changed permissions on ${documentTitle}`;
+      case "revision_restore":
+        return `${userName} restored an earlier version of ${documentTitle}`;
       default:
         return `${userName} performed an action on ${documentTitle}`;
     }
@@ -21,7 +21,8 @@ export type ActivityType =
   | "rename"
   | "move"
   | "tag_update"
-  | "permission_change";
+  | "permission_change"
+  | "revision_restore";
 
 // Activity Metadata
 export interface ActivityMetadata {
@@ -360,6 +361,7 @@ export const ACTIVITY_ICONS: Record<ActivityType, string> = {
   move: "folder",
   tag_update: "tag",
   permission_change: "shield",
+  revision_restore: "history",
 };
 
 export const ACTIVITY_COLORS: Record<ActivityType, string> = {
@@ -382,6 +384,7 @@ export const ACTIVITY_COLORS: Record<ActivityType, string> = {
   move: "teal",
   tag_update: "cyan",
   permission_change: "amber",
+  revision_restore: "yellow",
 };
 
 // Activity Messages
@@ -405,6 +408,7 @@ export const ACTIVITY_MESSAGES: Record<ActivityType, string> = {
   move: "moved",
   tag_update: "updated tags on",
   permission_change: "changed permissions on",
+  revision_restore: "restored an earlier version of",
 };
 
 // API Endpoints
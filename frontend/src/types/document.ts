@@ -28,7 +28,7 @@ export interface Document {
   mimeType: string;
   status: DocumentStatus;
   version: number;
-  tags: string;
+  tags: string[];
   isPublic: boolean;
   viewCount: number;
   downloadCount: number;
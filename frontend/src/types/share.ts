@@ -143,6 +143,7 @@ export interface PublicLinkItem {
   maxDownloads?: number;
   currentDownloads: number;
   isRevoked: boolean;
+  isPasswordProtected: boolean;
   status: 'active' | 'expired' | 'revoked';
 }
 